@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/mitsuhiko/gh-issue-sync/internal/app"
@@ -16,20 +19,61 @@ import (
 
 var version = "dev"
 
+// rootCtx is the context of the command, cancelled on the first ctrl-C
+// or SIGTERM.
+var rootCtx = context.Background()
+
 type Options struct {
 	Version    bool              `long:"version" short:"v" description:"Show version"`
+	ReadOnly   bool              `long:"read-only" description:"Refuse anything that writes to the tracker (as read_only in the config)"`
+	Stats      bool              `long:"stats" description:"Report the batched GraphQL queries made and their rate limit cost"`
+	Timeout    time.Duration     `long:"timeout" default:"5m" description:"Give up on a gh or git call that takes longer than this (0 to wait forever)"`
 	Init       InitCommand       `command:"init" description:"Initialize issue sync" long-description:"Create the .issues layout and config. If --owner/--repo are omitted, the git remote is used."`
 	Pull       PullCommand       `command:"pull" description:"Pull issues from GitHub" long-description:"Fetch issues from GitHub and write/update local issue files."`
 	Push       PushCommand       `command:"push" description:"Push local changes to GitHub" long-description:"Create or update GitHub issues based on local changes."`
+	Lint       LintCommand       `command:"lint" description:"Check issue bodies" long-description:"Run the body checkers configured as checkers in .issues/.sync/config.json (external commands such as vale or markdownlint) and the required template sections on new and changed issues, the given issues, or all open issues with --all. Fails if anything was found. A <!-- lint-ignore --> comment in a body skips it, <!-- lint-ignore: vale --> only the named checkers. push runs the same checks and warns. Lint also reports \"Depends on #N\" and \"Blocked by #N\" phrases in bodies whose issues are missing from blocked_by; --fix adds them."`
+	Translate  TranslateCommand  `command:"translate" description:"Translate an issue into its local notes" long-description:"Pipe the title, body and pulled comments of an issue through the translation command configured as translate.command in .issues/.sync/config.json and store the result in a local-notes block, which is never pushed. Translating again into the same language replaces the previous translation."`
+	Summarize  SummarizeCommand  `command:"summarize" description:"Summarize an issue thread" long-description:"Send the title, body and pulled comments of an issue as Markdown to the command configured as summarize.command in .issues/.sync/config.json, for example a script calling a language model, and store what it prints as info.summary. view shows the summary first. It is local only and kept across pulls."`
 	Sync       SyncCommand       `command:"sync" description:"Pull and push issues" long-description:"Push local changes first, then pull updates from GitHub."`
-	Status     StatusCommand     `command:"status" description:"Show sync status" long-description:"Show local changes and last full pull time."`
+	CISync     CISyncCommand     `command:"ci-sync" description:"Sync from a GitHub Actions workflow" long-description:"Pull issues inside a GitHub Actions job using GITHUB_TOKEN. Output is uncolored, and warnings and conflicts are reported as workflow annotations. With --open-pr the updated issues directory is committed to a branch and proposed as a pull request."`
+	Status     StatusCommand     `command:"status" description:"Show sync status" long-description:"Show local changes and last full pull time, and the issues breaching or about to breach an SLA rule (sla in the config)."`
+	WhatsNew   WhatsNewCommand   `command:"whatsnew" description:"Show what the last pull changed" long-description:"Show the new issues, state, label and other field changes, and new comments of the last pull that changed anything. The record is kept in .issues/.sync/last_pull.json."`
 	List       ListCommand       `command:"list" alias:"ls" description:"List local issues" long-description:"Display a formatted list of local issues with filtering options."`
 	New        NewCommand        `command:"new" description:"Create a new local issue" long-description:"Create a new local issue file. Use --edit to open an editor for the initial content."`
 	Edit       EditCommand       `command:"edit" description:"Open an issue in your editor" long-description:"Open an issue file in your preferred editor ($VISUAL, $EDITOR, or git core.editor)."`
 	View       ViewCommand       `command:"view" description:"View an issue" long-description:"Display an issue with nice formatting, showing metadata and body."`
 	Close      CloseCommand      `command:"close" description:"Mark an issue for closing" long-description:"Mark an issue as closed locally (use push to sync)." `
 	Reopen     ReopenCommand     `command:"reopen" description:"Reopen a closed issue" long-description:"Mark an issue as open locally (use push to sync)."`
-	Diff       DiffCommand       `command:"diff" description:"Show diff between local and original/remote" long-description:"Show what changed in a local issue compared to the last synced version or current remote state."`
+	Diff       DiffCommand       `command:"diff" description:"Show diff between local and original/remote" long-description:"Show what changed in a local issue compared to the last synced version or current remote state. With sync.snapshots set, pull keeps past remote versions that --at diffs against and --snapshots lists."`
+	Blame      BlameCommand      `command:"blame" description:"Show when each field of an issue last changed" long-description:"For each front matter field, show when it last changed and whether that came from a pull, a push of a local edit (with the pushing user from the audit log), or a local edit that is not pushed yet. Remote history comes from the snapshots pull keeps when sync.snapshots is set."`
+	Log        LogCommand        `command:"log" description:"Show the activity feed of an issue" long-description:"Show label, assignment, milestone, title and state changes, references, and comments of an issue. The timeline is cached and refetched when the issue was updated since."`
+	Conflicts  ConflictsCommand  `command:"conflicts" description:"List recorded conflicts" long-description:"List issues whose local and remote changes conflicted on pull or push, with the conflicting fields. Snapshots are kept in .issues/.sync/conflicts until resolved."`
+	Resolve    ResolveCommand    `command:"resolve" description:"Resolve a recorded conflict" long-description:"Step through the conflicting fields of an issue and keep the local value (ours), the remote value (theirs), or a merge of both. Remote changes that did not conflict are applied too (use push to sync). Given a local ID like T1a2b3c, print the issue push created from it instead."`
+	Split      SplitCommand      `command:"split" description:"Turn task-list items into sub-issues" long-description:"Create a local child issue for every unchecked task-list item in an issue. Children get the issue as parent and inherit its labels. Use --replace to swap the items for references to the new issues."`
+	Tasks      TasksCommand      `command:"tasks" description:"Show or toggle task-list items" long-description:"List the task-list items of an issue with their numbers. Pass item numbers to toggle them (use push to sync)."`
+	LinkCode   LinkCodeCommand   `command:"link-code" description:"Link an issue to a code location" long-description:"Add a path, path:line, or path:start-end (or a permalink) to the code_refs of an issue, pinned to the current commit. view renders the references as permalinks. Code refs are local and never pushed."`
+	ScanTodos  ScanTodosCommand  `command:"scan-todos" description:"Turn TODO/FIXME comments into drafts" long-description:"Walk the source tree (tracked files in a git checkout) for TODO and FIXME comments. New comments become drafts with the todo label and a code_refs entry, moved comments update the reference, and issues whose comment disappeared are listed as candidates for closing."`
+	Grep       GrepCommand       `command:"grep" description:"Search issue bodies and comments" long-description:"Print the lines of issue bodies and pulled comments that match a regular expression, with surrounding context (-C) and the issue file and line of every match."`
+	Watch      WatchCommand      `command:"watch" description:"Pull periodically and notify" long-description:"Pull every few minutes (watch.interval, default 5m) until interrupted. New or updated issues that match one of the notify queries (watch.notify or --notify, e.g. assignee:@me or label:incident) raise a desktop notification, as do issues about to breach an SLA rule."`
+	Inbox      InboxCommand      `command:"inbox" description:"Show notifications for this repository" long-description:"List your GitHub notifications for the configured repository, matched against local issue files, and offer to pull the affected issues."`
+	Todo       TodoCommand       `command:"todo" description:"Capture a quick draft" long-description:"Create a draft with the todo label (configurable as todo.label) from the arguments, without opening an editor. The default triage queue includes these drafts and can promote them."`
+	Promote    PromoteCommand    `command:"promote" description:"Turn a draft into a local issue" long-description:"Clear the draft flag of an issue and move it from .issues/drafts to .issues/open so the next push creates it."`
+	Tick       TickCommand       `command:"tick" description:"Create due recurring issues" long-description:"Create a local issue for every definition in .issues/recurring whose current period (daily, weekly, or monthly) has no issue yet. Safe to run repeatedly, e.g. from cron."`
+	Triage     TriageCommand     `command:"triage" description:"Triage issues interactively" long-description:"Step through untriaged issues (default: no:label no:milestone) and label, assign, or close them with single-key actions. Changes are written locally (use push to sync)."`
+	Plan       PlanCommand       `command:"plan" description:"Estimate the issues of a milestone" long-description:"Step through the open issues of a milestone that have no estimate and type one for each (Enter skips). Estimates are written to the estimate front matter field (--field), a local field that is never pushed. Afterwards the milestone's total and the load per assignee are printed."`
+	Report     ReportCommand     `command:"report" description:"Print a swimlane report" long-description:"Print every assignee (or label with --by label) with their issues grouped by milestone, the total of their estimates (the estimate front matter field) and their oldest issue. Use --markdown for a version to paste into a standup document."`
+	Audit      AuditCommand      `command:"audit" description:"Show the log of remote mutations" long-description:"Show every change made to the tracker from this checkout (issues created, edited, closed or reopened, comments, labels, milestones, relationships and projects) with the time, actor and changed fields, as recorded in .issues/.sync/audit.jsonl. Failed mutations are recorded too."`
+	Suggest    SuggestCommand    `command:"suggest-assignee" description:"Suggest assignees for an issue" long-description:"Rank collaborators for an issue by how many issues with the same labels they worked on and how many open issues they hold, using the local mirror. Use --apply to add the top suggestion to the issue (use push to sync)."`
+	Comment    CommentCommand    `command:"comment" description:"Work with issue comments" long-description:"Prepare pending comments that are posted on the next push."`
+	Auth       AuthCommand       `command:"auth" description:"Check authentication" long-description:"Check that gh (or glab for GitLab) is installed, logged in, and has the scopes sync needs."`
+	Label      LabelCommand      `command:"label" description:"Audit and merge labels" long-description:"Show label usage across the local mirror and merge near-duplicate labels. Merges relabel local issues and change the remote label on the next push."`
+	Notes      NotesCommand      `command:"notes" description:"Manage private local notes" long-description:"Encrypt, decrypt, or show the <!-- local-notes --> blocks of issues. Encryption uses age or gpg as configured in .issues/.sync/config.json."`
+	Cache      CacheCommand      `command:"cache" description:"Manage the issue index" long-description:"The parsed issue files are cached in .issues/.sync/index.json so list, status, and search don't re-parse unchanged files. The cache updates itself; rebuild it if it ever looks stale."`
+	Index      IndexCommand      `command:"index" description:"Maintain the link indexes" long-description:"Keep directories of links to issue files by label, assignee or milestone, e.g. .issues/index/by-label/bug/, for browsing the mirror with a file manager or editor. Configure them with indexes in .issues/.sync/config.json; they are updated whenever a command changes issue files."`
+	Board      BoardCommand      `command:"board" description:"Write the kanban board" long-description:"Write BOARD.md, the open issues grouped into columns by their board label (board.label_prefix in .issues/.sync/config.json, e.g. status/), with checkboxes and links. Pull writes it again. Move an issue under another heading to change its column label, or tick its box to close it, and run board --apply to make the edits local changes for the next push."`
+	SyncState  SyncStateCommand  `command:"sync-state" description:"Maintain the sync state" long-description:"Per-machine state (last full pull, capabilities) lives in .issues/.sync/state.json and the caches pull rebuilds are ignored by git, so a mirror committed from several machines merges cleanly."`
+	GC         GCCommand         `command:"gc" description:"Clean up the sync directory" long-description:"Remove the originals of issues that were deleted or transferred (checked with the tracker unless --no-remote), pending comment files whose issue is gone, editor recovery buffers older than --older-than days and timeline cache entries larger than --max-cache-size. Also rewrite the originals in .issues/.sync/originals in the format selected by storage.originals (full copies, or hashed: bodies as deduplicated blobs in .issues/.sync/blobs) and delete blobs that no original references. Reports the reclaimed space."`
+	Bench      BenchCommand      `command:"bench" hidden:"yes" description:"Benchmark the local sync path" long-description:"Generate a synthetic mirror and time loading, comparing, and searching it against a performance budget. Fails if a phase is over budget."`
 	WriteSkill WriteSkillCommand `command:"write-skill" description:"Write agent skill file" long-description:"Write the gh-issue-sync skill file for coding agents to the specified location."`
 }
 
@@ -39,17 +83,29 @@ type BaseCommand struct {
 
 type InitCommand struct {
 	BaseCommand
-	Owner string `long:"owner" value-name:"OWNER" description:"GitHub owner (user or org)"`
-	Repo  string `long:"repo" value-name:"REPO" description:"GitHub repository name"`
+	Owner    string   `long:"owner" value-name:"OWNER" description:"Repository owner (user, org, or GitLab group path)"`
+	Repo     string   `long:"repo" value-name:"REPO" description:"Repository name"`
+	Provider string   `long:"provider" choice:"github" choice:"gitlab" description:"Issue tracker backend (detected from the origin remote if omitted)"`
+	Host     string   `long:"host" value-name:"HOST" description:"Hostname of a self-managed GitLab instance"`
+	LocalIDs string   `long:"local-ids" choice:"random" choice:"sequential" description:"Number new local issues T1a2b3c4d (random, default) or T1, T2, ... (sequential)"`
+	Org      string   `long:"org" value-name:"ORG" description:"Mirror all repositories of a GitHub organization under .issues/<repo>/"`
+	Include  []string `long:"include" value-name:"GLOB" description:"Only mirror repositories matching the glob (repeatable, with --org)"`
+	Exclude  []string `long:"exclude" value-name:"GLOB" description:"Skip repositories matching the glob (repeatable, with --org)"`
 }
 
 type PullCommand struct {
 	BaseCommand
-	All   bool     `long:"all" description:"Pull all issues (including closed)"`
-	Force bool     `long:"force" description:"Overwrite local changes"`
-	Full  bool     `long:"full" description:"Force full sync (bypass incremental)"`
-	Label []string `long:"label" value-name:"LABEL" description:"Filter by label (repeatable)"`
-	Args  struct {
+	All       bool     `long:"all" description:"Pull all issues (including closed)"`
+	Force     bool     `long:"force" description:"Overwrite local changes"`
+	Full      bool     `long:"full" description:"Force full sync (bypass incremental)"`
+	Label     []string `long:"label" value-name:"LABEL" description:"Filter by label (repeatable)"`
+	Milestone string   `long:"milestone" value-name:"TITLE" description:"Filter by milestone"`
+	Assignee  string   `long:"assignee" value-name:"LOGIN" description:"Filter by assignee"`
+	DryRun    bool     `long:"dry-run" description:"Show what would change without writing files"`
+	JSON      bool     `long:"json" description:"Print the dry-run plan as JSON (requires --dry-run)"`
+	Review    bool     `long:"review" description:"Accept, skip, or defer each incoming change"`
+	Export    string   `long:"from-export" value-name:"FILE" description:"Seed the mirror from a bulk dump of the issues (gh api --paginate or a migration archive)"`
+	Args      struct {
 		Issues []string `positional-arg-name:"issue" description:"Issue numbers, local IDs, or paths to pull"`
 	} `positional-args:"yes"`
 }
@@ -57,8 +113,14 @@ type PullCommand struct {
 type PushCommand struct {
 	BaseCommand
 	DryRun     bool `long:"dry-run" description:"Show what would happen without pushing"`
+	JSON       bool `long:"json" description:"Print the dry-run plan as JSON (requires --dry-run)"`
 	NoComments bool `long:"no-comments" description:"Skip posting pending comments"`
 	Force      bool `long:"force" description:"Skip conflict detection and push anyway"`
+	AllowMass  bool `long:"allow-mass-changes" description:"Allow closing or retitling more issues than the configured threshold"`
+	CreateAll  bool `long:"create-missing-labels" description:"Create all missing labels, even ones that look like typos"`
+	NoCreate   bool `long:"no-create-labels" description:"Never create labels; stop if an issue uses an unknown label"`
+	NoLint     bool `long:"no-lint" description:"Skip checking required template sections, references and body checkers"`
+	Strict     bool `long:"strict" description:"Refuse to close issues whose blocked_by issues or sub-issues are still open"`
 	Args       struct {
 		Issues []string `positional-arg-name:"issue" description:"Issue numbers, local IDs, or paths to push"`
 	} `positional-args:"yes"`
@@ -66,13 +128,22 @@ type PushCommand struct {
 
 type SyncCommand struct {
 	BaseCommand
-	All   bool     `long:"all" description:"Pull all issues (including closed)"`
-	Full  bool     `long:"full" description:"Force full sync (bypass incremental)"`
-	Label []string `long:"label" value-name:"LABEL" description:"Filter by label (repeatable)"`
+	All       bool     `long:"all" description:"Pull all issues (including closed)"`
+	Full      bool     `long:"full" description:"Force full sync (bypass incremental)"`
+	Label     []string `long:"label" value-name:"LABEL" description:"Filter by label (repeatable)"`
+	Milestone string   `long:"milestone" value-name:"TITLE" description:"Filter by milestone"`
+	Assignee  string   `long:"assignee" value-name:"LOGIN" description:"Filter by assignee"`
+	AllowMass bool     `long:"allow-mass-changes" description:"Allow closing or retitling more issues than the configured threshold"`
 }
 
 type StatusCommand struct {
 	BaseCommand
+	Remote bool `long:"remote" description:"Check which issues are ahead of, behind, or diverged from the remote"`
+}
+
+type WhatsNewCommand struct {
+	BaseCommand
+	JSON bool `long:"json" description:"Print the recorded changes as JSON"`
 }
 
 type ListCommand struct {
@@ -87,21 +158,31 @@ type ListCommand struct {
 	Limit     int      `long:"limit" short:"L" value-name:"N" description:"Maximum number of issues to show"`
 	Local     bool     `long:"local" description:"Show only local (unpushed) issues"`
 	Modified  bool     `long:"modified" short:"m" description:"Show only modified issues"`
+	Epics     bool     `long:"epics" description:"Show only parent issues with sub-issue progress"`
+	PRs       bool     `long:"prs" description:"Show linked pull requests with their state and checks"`
 	Search    string   `long:"search" short:"S" value-name:"QUERY" description:"Search with GitHub-style query (e.g. 'error no:assignee sort:created-asc')"`
+	Sort      string   `long:"sort" value-name:"EXPR" description:"Sort by comma separated fields, each with asc or desc (e.g. 'priority desc, updated asc'); custom front matter fields work too"`
+	Facets    string   `long:"facets" value-name:"FIELDS" description:"Print counts of the matches per value of comma separated fields (e.g. 'labels,milestone')"`
 }
 
 type NewCommand struct {
 	BaseCommand
-	Edit   bool     `long:"edit" description:"Open in $EDITOR before creating the file"`
-	Labels []string `long:"label" value-name:"LABEL" description:"Add label (repeatable)"`
-	Args   struct {
-		Title string `positional-arg-name:"title" description:"Issue title (optional with --edit)"`
+	Edit       bool     `long:"edit" description:"Open in $EDITOR before creating the file"`
+	NoEdit     bool     `long:"no-edit" description:"Create the issue from the buffer saved by a failed --edit"`
+	Draft      bool     `long:"draft" description:"Create a draft in .issues/drafts that is never pushed"`
+	Labels     []string `long:"label" value-name:"LABEL" description:"Add label (repeatable)"`
+	Type       string   `long:"type" value-name:"TYPE" description:"Issue type; the body starts from .issues/templates/<type>.md"`
+	FromPR     string   `long:"from-pr" value-name:"NUMBER" description:"Prefill from a pull request: title, link, diff stat, and labels from label_rules"`
+	FromCommit string   `long:"from-commit" value-name:"REF" description:"Prefill from a commit in the local repository"`
+	Args       struct {
+		Title string `positional-arg-name:"title" description:"Issue title (optional with --edit, --from-pr, or --from-commit)"`
 	} `positional-args:"yes"`
 }
 
 type EditCommand struct {
 	BaseCommand
-	Args struct {
+	WithComments bool `long:"with-comments" description:"Show the recent comments below the body for context (removed when the editor closes)"`
+	Args         struct {
 		Number string `positional-arg-name:"issue" description:"Issue number or local ID" required:"yes"`
 	} `positional-args:"yes"`
 }
@@ -109,6 +190,7 @@ type EditCommand struct {
 type CloseCommand struct {
 	BaseCommand
 	Reason string `long:"reason" choice:"completed" choice:"not_planned" value-name:"REASON" description:"Close reason (completed or not_planned)"`
+	Strict bool   `long:"strict" description:"Fail if blocked_by issues or sub-issues are still open"`
 	Args   struct {
 		Number string `positional-arg-name:"issue" description:"Issue number or local ID" required:"yes"`
 	} `positional-args:"yes"`
@@ -123,20 +205,310 @@ type ReopenCommand struct {
 
 type ViewCommand struct {
 	BaseCommand
-	Raw  bool `long:"raw" description:"Show raw file content"`
-	Args struct {
+	Raw   bool `long:"raw" description:"Show raw file content"`
+	Teams bool `long:"teams" description:"List the members of mentioned @org/team teams"`
+	Args  struct {
 		Issue string `positional-arg-name:"issue" description:"Issue number, local ID, or path" required:"yes"`
 	} `positional-args:"yes"`
 }
 
 type DiffCommand struct {
 	BaseCommand
-	Remote bool `long:"remote" description:"Diff against current remote state instead of last synced original"`
-	Args   struct {
+	Remote    bool   `long:"remote" description:"Diff against current remote state instead of last synced original"`
+	Stat      bool   `long:"stat" description:"Show a one-line summary per changed issue"`
+	At        string `long:"at" value-name:"DATE" description:"Diff against the remote version as of DATE, kept as a snapshot by pull"`
+	Snapshots bool   `long:"snapshots" description:"List the kept snapshots of the issue"`
+	Args      struct {
 		Number string `positional-arg-name:"issue" description:"Issue number or local ID (omit to diff all)"`
 	} `positional-args:"yes"`
 }
 
+type LogCommand struct {
+	BaseCommand
+	Refresh bool `long:"refresh" description:"Refetch the timeline instead of using the cache"`
+	Args    struct {
+		Issue string `positional-arg-name:"issue" description:"Issue number or path" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type SplitCommand struct {
+	BaseCommand
+	Replace bool `long:"replace" description:"Replace task-list items with references to the new issues"`
+	DryRun  bool `long:"dry-run" description:"Show which issues would be created"`
+	Args    struct {
+		Number string `positional-arg-name:"issue" description:"Issue number or local ID" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type TasksCommand struct {
+	BaseCommand
+	Args struct {
+		Number string `positional-arg-name:"issue" description:"Issue number or local ID" required:"yes"`
+		Items  []int  `positional-arg-name:"item" description:"Task numbers to toggle"`
+	} `positional-args:"yes"`
+}
+
+type GrepCommand struct {
+	BaseCommand
+	Context    int  `short:"C" long:"context" value-name:"N" description:"Show N lines of context around each match"`
+	IgnoreCase bool `short:"i" long:"ignore-case" description:"Match case-insensitively"`
+	Fixed      bool `short:"F" long:"fixed-strings" description:"Treat the pattern as a literal string"`
+	Args       struct {
+		Pattern string `positional-arg-name:"pattern" description:"Regular expression to search for" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type PlanCommand struct {
+	BaseCommand
+	Milestone string `long:"milestone" short:"M" value-name:"NAME" required:"yes" description:"Milestone to plan"`
+	Field     string `long:"field" value-name:"FIELD" description:"Front matter field for the estimates (default: estimate)"`
+	All       bool   `long:"all" description:"Also ask for issues that already have an estimate"`
+	Summary   bool   `long:"summary" description:"Only print the total and per-assignee load"`
+}
+
+type ReportCommand struct {
+	BaseCommand
+	By       string `long:"by" choice:"assignee" choice:"label" default:"assignee" description:"Swimlanes by assignee or label"`
+	State    string `long:"state" choice:"open" choice:"closed" choice:"all" default:"open" description:"Issue state to report on"`
+	Field    string `long:"field" value-name:"FIELD" description:"Front matter field for the estimates (default: estimate)"`
+	Markdown bool   `long:"markdown" description:"Print Markdown with issue links"`
+}
+
+type AuditCommand struct {
+	BaseCommand
+	Issue string        `long:"issue" value-name:"NUMBER" description:"Only show mutations of this issue"`
+	Since time.Duration `long:"since" value-name:"DURATION" description:"Only show mutations this recent (e.g. 24h)"`
+	Limit int           `long:"limit" value-name:"N" description:"Only show the last N mutations"`
+	JSON  bool          `long:"json" description:"Print the entries as JSON lines"`
+}
+
+type LintCommand struct {
+	BaseCommand
+	All  bool `long:"all" description:"Check all open issues, not only new and changed ones"`
+	Fix  bool `long:"fix" description:"Add issues named by \"Depends on #N\" or \"Blocked by #N\" in bodies to blocked_by"`
+	Args struct {
+		Issues []string `positional-arg-name:"issue" description:"Issue numbers, local IDs, or paths to check"`
+	} `positional-args:"yes"`
+}
+
+type TranslateCommand struct {
+	BaseCommand
+	To         string `long:"to" default:"en" description:"Language to translate into"`
+	NoComments bool   `long:"no-comments" description:"Only translate the title and body"`
+	Args       struct {
+		Number string `positional-arg-name:"issue" description:"Issue number, local ID, or path" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type SummarizeCommand struct {
+	BaseCommand
+	Clear bool `long:"clear" description:"Remove the stored summary"`
+	Args  struct {
+		Number string `positional-arg-name:"issue" description:"Issue number, local ID, or path" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type BlameCommand struct {
+	BaseCommand
+	Args struct {
+		Number string `positional-arg-name:"issue" description:"Issue number, local ID, or path" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type WatchCommand struct {
+	BaseCommand
+	Interval time.Duration `long:"interval" value-name:"DURATION" description:"Time between pulls (default: watch.interval or 5m)"`
+	Notify   []string      `long:"notify" value-name:"QUERY" description:"Notify for issues matching the search query (repeatable)"`
+}
+
+type InboxCommand struct {
+	BaseCommand
+	All      bool `long:"all" description:"Include notifications that were already read"`
+	Pull     bool `long:"pull" description:"Pull the affected issues without asking"`
+	MarkRead bool `long:"mark-read" description:"Mark the shown notifications as read"`
+}
+
+type TodoCommand struct {
+	BaseCommand
+	Args struct {
+		Text []string `positional-arg-name:"text" description:"Title of the draft" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type LinkCodeCommand struct {
+	BaseCommand
+	Args struct {
+		Ref      string `positional-arg-name:"issue" description:"Issue number, local ID, or path" required:"yes"`
+		Location string `positional-arg-name:"path:line" description:"File path with optional line or line range, or a permalink" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type ScanTodosCommand struct {
+	BaseCommand
+	DryRun bool `long:"dry-run" description:"Show what would change without writing files"`
+}
+
+type PromoteCommand struct {
+	BaseCommand
+	Args struct {
+		Ref string `positional-arg-name:"draft" description:"Draft ID or path" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type SuggestCommand struct {
+	BaseCommand
+	Apply bool `long:"apply" description:"Add the top suggestion to the issue's assignees"`
+	Limit int  `long:"limit" value-name:"N" default:"5" description:"Number of suggestions to show"`
+	Args  struct {
+		Ref string `positional-arg-name:"issue" description:"Issue number, local ID, or path" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type ConflictsCommand struct {
+	BaseCommand
+}
+
+type ResolveCommand struct {
+	BaseCommand
+	Ours   bool `long:"ours" description:"Keep the local value of every conflicting field"`
+	Theirs bool `long:"theirs" description:"Take the remote value of every conflicting field"`
+	Args   struct {
+		Number string `positional-arg-name:"issue" description:"Issue number, or a local ID to look up" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type CISyncCommand struct {
+	BaseCommand
+	All    bool   `long:"all" description:"Pull all issues (including closed)"`
+	Push   bool   `long:"push" description:"Push local changes after pulling"`
+	OpenPR bool   `long:"open-pr" description:"Commit the updated issues directory and open a pull request"`
+	Branch string `long:"branch" value-name:"BRANCH" description:"Branch for the pull request (default: gh-issue-sync/update)"`
+	Base   string `long:"base" value-name:"BRANCH" description:"Base branch for the pull request (default: the repository's default branch)"`
+}
+
+type TickCommand struct {
+	BaseCommand
+	DryRun bool `long:"dry-run" description:"Show which issues would be created"`
+}
+
+type TriageCommand struct {
+	BaseCommand
+	Search string `long:"search" short:"S" value-name:"QUERY" description:"Search query selecting the issues to triage (default: 'no:label no:milestone')"`
+}
+
+type CommentCommand struct {
+	Reply  CommentReplyCommand  `command:"reply" description:"Reply to a comment" long-description:"Open the editor on a pending comment that quotes the given comment and records it as the reply target (use push to post)."`
+	Review CommentReviewCommand `command:"review" description:"Review pending comments before push" long-description:"Open all pending comments in a single editor buffer, separated by headers. Edit them in place, or clear a section to discard that comment."`
+}
+
+type CommentReplyCommand struct {
+	BaseCommand
+	Args struct {
+		Number    string `positional-arg-name:"issue" description:"Issue number" required:"yes"`
+		CommentID string `positional-arg-name:"comment-id" description:"ID of the comment to reply to" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type CommentReviewCommand struct {
+	BaseCommand
+}
+
+type AuthCommand struct {
+	Status AuthStatusCommand `command:"status" description:"Check the CLI login and token scopes" long-description:"Verify that the CLI is installed and logged in and list the token's scopes. Missing scopes are reported with the command that grants them. pull and push run the same check before syncing. Inside an initialized repository this also re-probes which optional features (issue types, projects, sub-issues) are available and caches the result."`
+}
+
+type AuthStatusCommand struct {
+	BaseCommand
+}
+
+type LabelCommand struct {
+	Audit LabelAuditCommand `command:"audit" description:"Show label usage and near-duplicates" long-description:"List every label with its open and closed issue counts and the date it was last used, followed by labels whose names differ only in case, separators, or a typo. Use --merge to merge the pairs interactively."`
+	Merge LabelMergeCommand `command:"merge" description:"Merge one label into another" long-description:"Replace a label with another on every local issue. On the next push the remote label is renamed, or deleted if the target label already exists."`
+}
+
+type LabelAuditCommand struct {
+	BaseCommand
+	Merge bool `long:"merge" description:"Ask to merge each near-duplicate pair"`
+}
+
+type LabelMergeCommand struct {
+	BaseCommand
+	Args struct {
+		From string `positional-arg-name:"from" description:"Label to merge away" required:"yes"`
+		To   string `positional-arg-name:"to" description:"Label to keep" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type NotesCommand struct {
+	Encrypt NotesEncryptCommand `command:"encrypt" description:"Encrypt local notes in place" long-description:"Encrypt plaintext local-notes blocks for the configured recipients so .issues can be committed safely."`
+	Decrypt NotesDecryptCommand `command:"decrypt" description:"Decrypt local notes in place"`
+	Show    NotesShowCommand    `command:"show" description:"Print the local notes of an issue, decrypting them if needed"`
+}
+
+type NotesEncryptCommand struct {
+	BaseCommand
+	Args struct {
+		Issues []string `positional-arg-name:"issue" description:"Issue numbers, local IDs, or paths (default: all)"`
+	} `positional-args:"yes"`
+}
+
+type NotesDecryptCommand struct {
+	BaseCommand
+	Args struct {
+		Issues []string `positional-arg-name:"issue" description:"Issue numbers, local IDs, or paths (default: all)"`
+	} `positional-args:"yes"`
+}
+
+type NotesShowCommand struct {
+	BaseCommand
+	Args struct {
+		Number string `positional-arg-name:"issue" description:"Issue number, local ID, or path" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type CacheCommand struct {
+	Rebuild CacheRebuildCommand `command:"rebuild" description:"Re-parse every issue file into the cache"`
+}
+
+type CacheRebuildCommand struct {
+	BaseCommand
+}
+
+type IndexCommand struct {
+	Rebuild IndexRebuildCommand `command:"rebuild" description:"Rebuild the configured link indexes"`
+}
+
+type IndexRebuildCommand struct {
+	BaseCommand
+}
+
+type BoardCommand struct {
+	BaseCommand
+	Apply bool `long:"apply" description:"Apply column moves and ticked boxes on the board as local changes"`
+}
+
+type SyncStateCommand struct {
+	Repair SyncStateRepairCommand `command:"repair" description:"Repair the sync state after a git merge" long-description:"Write .issues/.sync/.gitignore, move the last full pull and capabilities out of config.json, resolve git conflicts in originals by keeping the more recently updated side, and delete conflicted caches. Conflicts that need a decision are reported."`
+}
+
+type SyncStateRepairCommand struct {
+	BaseCommand
+}
+
+type GCCommand struct {
+	BaseCommand
+	DryRun       bool  `long:"dry-run" description:"Show what would be migrated and removed"`
+	NoRemote     bool  `long:"no-remote" description:"Keep orphaned originals instead of checking whether their issue still exists"`
+	OlderThan    int   `long:"older-than" value-name:"DAYS" default:"30" description:"Remove recovery buffers older than DAYS days (0 keeps them)"`
+	MaxCacheSize int64 `long:"max-cache-size" value-name:"KIB" default:"512" description:"Remove timeline cache entries larger than KIB kibibytes (0 keeps them)"`
+}
+
+type BenchCommand struct {
+	BaseCommand
+	Issues int    `long:"issues" value-name:"N" default:"10000" description:"Number of synthetic issues"`
+	Dir    string `long:"dir" value-name:"DIR" description:"Generate the mirror in DIR and keep it"`
+}
+
 type WriteSkillCommand struct {
 	Output string `long:"output" short:"o" value-name:"DIR" description:"Output directory (overrides --agent)"`
 	Agent  string `long:"agent" short:"a" value-name:"AGENT" description:"Target agent (codex, pi, claude, amp, opencode, generic)"`
@@ -163,6 +535,10 @@ func (c *StatusCommand) Usage() string {
 	return "[OPTIONS]"
 }
 
+func (c *WhatsNewCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
 func (c *ListCommand) Usage() string {
 	return "[OPTIONS]"
 }
@@ -191,40 +567,201 @@ func (c *DiffCommand) Usage() string {
 	return "[OPTIONS] <issue>"
 }
 
+func (c *LogCommand) Usage() string {
+	return "[OPTIONS] <issue>"
+}
+
+func (c *SplitCommand) Usage() string {
+	return "[OPTIONS] <issue>"
+}
+
+func (c *TasksCommand) Usage() string {
+	return "[OPTIONS] <issue> [item...]"
+}
+
+func (c *GrepCommand) Usage() string {
+	return "[OPTIONS] <pattern>"
+}
+
+func (c *PlanCommand) Usage() string {
+	return "[OPTIONS] --milestone NAME"
+}
+
+func (c *ReportCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *AuditCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *LintCommand) Usage() string {
+	return "[OPTIONS] [issue...]"
+}
+
+func (c *TranslateCommand) Usage() string {
+	return "[OPTIONS] <issue>"
+}
+
+func (c *SummarizeCommand) Usage() string {
+	return "[OPTIONS] <issue>"
+}
+
+func (c *BlameCommand) Usage() string {
+	return "<issue>"
+}
+
+func (c *WatchCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *InboxCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *TodoCommand) Usage() string {
+	return "<text...>"
+}
+
+func (c *LinkCodeCommand) Usage() string {
+	return "<issue> <path:line>"
+}
+
+func (c *ScanTodosCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *PromoteCommand) Usage() string {
+	return "<draft>"
+}
+
+func (c *SuggestCommand) Usage() string {
+	return "[OPTIONS] <issue>"
+}
+
+func (c *ResolveCommand) Usage() string {
+	return "[OPTIONS] <issue>"
+}
+
+func (c *CISyncCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *TickCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *TriageCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *CommentReplyCommand) Usage() string {
+	return "<issue> <comment-id>"
+}
+
+func (c *CommentReviewCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *LabelAuditCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *LabelMergeCommand) Usage() string {
+	return "<from> <to>"
+}
+
+func (c *NotesEncryptCommand) Usage() string {
+	return "[issue...]"
+}
+
+func (c *NotesDecryptCommand) Usage() string {
+	return "[issue...]"
+}
+
+func (c *BenchCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *CacheRebuildCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *IndexRebuildCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *BoardCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *SyncStateRepairCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *GCCommand) Usage() string {
+	return "[OPTIONS]"
+}
+
+func (c *NotesShowCommand) Usage() string {
+	return "<issue>"
+}
+
 func (c *WriteSkillCommand) Usage() string {
 	return "[OPTIONS]"
 }
 
 func (c *InitCommand) Execute(_ []string) error {
-	return c.App.Init(context.Background(), c.Owner, c.Repo)
+	return c.App.Init(rootCtx, app.InitOptions{
+		Owner:    c.Owner,
+		Repo:     c.Repo,
+		Provider: c.Provider,
+		Host:     c.Host,
+		LocalIDs: c.LocalIDs,
+		Org:      c.Org,
+		Include:  c.Include,
+		Exclude:  c.Exclude,
+	})
 }
 
 func (c *PullCommand) Execute(args []string) error {
-	opts := app.PullOptions{All: c.All, Force: c.Force, Full: c.Full, Label: c.Label}
+	opts := app.PullOptions{All: c.All, Force: c.Force, Full: c.Full, Label: c.Label, Milestone: c.Milestone, Assignee: c.Assignee, DryRun: c.DryRun, JSON: c.JSON, Review: c.Review, FromExport: c.Export}
 	if len(c.Args.Issues) > 0 {
-		return c.App.Pull(context.Background(), opts, c.Args.Issues)
+		return c.App.Pull(rootCtx, opts, c.Args.Issues)
 	}
-	return c.App.Pull(context.Background(), opts, args)
+	return c.App.Pull(rootCtx, opts, args)
 }
 
 func (c *PushCommand) Execute(args []string) error {
-	opts := app.PushOptions{DryRun: c.DryRun, NoComments: c.NoComments, Force: c.Force}
+	if c.CreateAll && c.NoCreate {
+		return fmt.Errorf("--create-missing-labels and --no-create-labels are mutually exclusive")
+	}
+	opts := app.PushOptions{DryRun: c.DryRun, JSON: c.JSON, NoComments: c.NoComments, Force: c.Force, AllowMassChanges: c.AllowMass, NoLint: c.NoLint, Strict: c.Strict}
+	if c.CreateAll {
+		opts.LabelPolicy = app.LabelPolicyCreate
+	} else if c.NoCreate {
+		opts.LabelPolicy = app.LabelPolicyNone
+	}
 	if len(c.Args.Issues) > 0 {
-		return c.App.Push(context.Background(), opts, c.Args.Issues)
+		return c.App.Push(rootCtx, opts, c.Args.Issues)
 	}
-	return c.App.Push(context.Background(), opts, args)
+	return c.App.Push(rootCtx, opts, args)
 }
 
 func (c *SyncCommand) Execute(_ []string) error {
-	ctx := context.Background()
-	if err := c.App.Push(ctx, app.PushOptions{}, nil); err != nil {
+	ctx := rootCtx
+	if err := c.App.Push(ctx, app.PushOptions{AllowMassChanges: c.AllowMass}, nil); err != nil {
 		return err
 	}
-	return c.App.Pull(ctx, app.PullOptions{All: c.All, Force: true, Full: c.Full, Label: c.Label}, nil)
+	return c.App.Pull(ctx, app.PullOptions{All: c.All, Force: true, Full: c.Full, Label: c.Label, Milestone: c.Milestone, Assignee: c.Assignee}, nil)
 }
 
 func (c *StatusCommand) Execute(_ []string) error {
-	return c.App.Status(context.Background())
+	return c.App.Status(rootCtx, app.StatusOptions{Remote: c.Remote})
+}
+
+func (c *WhatsNewCommand) Execute(_ []string) error {
+	return c.App.WhatsNew(app.WhatsNewOptions{JSON: c.JSON})
 }
 
 func (c *ListCommand) Execute(_ []string) error {
@@ -239,9 +776,13 @@ func (c *ListCommand) Execute(_ []string) error {
 		Limit:     c.Limit,
 		Local:     c.Local,
 		Modified:  c.Modified,
+		Epics:     c.Epics,
+		PRs:       c.PRs,
 		Search:    c.Search,
+		Sort:      c.Sort,
+		Facets:    c.Facets,
 	}
-	return c.App.List(context.Background(), opts)
+	return c.App.List(rootCtx, opts)
 }
 
 func (c *NewCommand) Execute(args []string) error {
@@ -249,7 +790,15 @@ func (c *NewCommand) Execute(args []string) error {
 	if title == "" && len(args) > 0 {
 		title = args[0]
 	}
-	return c.App.NewIssue(context.Background(), title, app.NewOptions{Edit: c.Edit, Labels: c.Labels})
+	return c.App.NewIssue(rootCtx, title, app.NewOptions{
+		Edit:       c.Edit,
+		NoEdit:     c.NoEdit,
+		Draft:      c.Draft,
+		Labels:     c.Labels,
+		Type:       c.Type,
+		FromPR:     c.FromPR,
+		FromCommit: c.FromCommit,
+	})
 }
 
 func (c *EditCommand) Execute(args []string) error {
@@ -260,7 +809,7 @@ func (c *EditCommand) Execute(args []string) error {
 	if strings.TrimSpace(number) == "" {
 		return fmt.Errorf("issue number is required")
 	}
-	return c.App.Edit(context.Background(), number)
+	return c.App.Edit(rootCtx, number, app.EditOptions{WithComments: c.WithComments})
 }
 
 func (c *CloseCommand) Execute(args []string) error {
@@ -271,7 +820,7 @@ func (c *CloseCommand) Execute(args []string) error {
 	if strings.TrimSpace(number) == "" {
 		return fmt.Errorf("issue number is required")
 	}
-	return c.App.Close(context.Background(), number, app.CloseOptions{Reason: c.Reason})
+	return c.App.Close(rootCtx, number, app.CloseOptions{Reason: c.Reason, Strict: c.Strict})
 }
 
 func (c *ReopenCommand) Execute(args []string) error {
@@ -282,7 +831,7 @@ func (c *ReopenCommand) Execute(args []string) error {
 	if strings.TrimSpace(number) == "" {
 		return fmt.Errorf("issue number is required")
 	}
-	return c.App.Reopen(context.Background(), number)
+	return c.App.Reopen(rootCtx, number)
 }
 
 func (c *ViewCommand) Execute(args []string) error {
@@ -293,7 +842,7 @@ func (c *ViewCommand) Execute(args []string) error {
 	if strings.TrimSpace(issue) == "" {
 		return fmt.Errorf("issue is required")
 	}
-	return c.App.View(context.Background(), issue, app.ViewOptions{Raw: c.Raw})
+	return c.App.View(rootCtx, issue, app.ViewOptions{Raw: c.Raw, Teams: c.Teams})
 }
 
 func (c *DiffCommand) Execute(args []string) error {
@@ -301,10 +850,197 @@ func (c *DiffCommand) Execute(args []string) error {
 	if number == "" && len(args) > 0 {
 		number = args[0]
 	}
+	opts := app.DiffOptions{Remote: c.Remote, At: c.At}
+	if c.Snapshots || c.At != "" {
+		if strings.TrimSpace(number) == "" {
+			return fmt.Errorf("--at and --snapshots need an issue")
+		}
+		if c.Remote || c.Stat {
+			return fmt.Errorf("--at and --snapshots cannot be combined with --remote or --stat")
+		}
+		if c.Snapshots {
+			return c.App.Snapshots(number)
+		}
+	}
+	if c.Stat {
+		return c.App.DiffStat(rootCtx, number, opts)
+	}
 	if strings.TrimSpace(number) == "" {
-		return c.App.DiffAll(context.Background(), app.DiffOptions{Remote: c.Remote})
+		return c.App.DiffAll(rootCtx, opts)
 	}
-	return c.App.Diff(context.Background(), number, app.DiffOptions{Remote: c.Remote})
+	return c.App.Diff(rootCtx, number, opts)
+}
+
+func (c *LogCommand) Execute(_ []string) error {
+	return c.App.Log(rootCtx, c.Args.Issue, app.LogOptions{Refresh: c.Refresh})
+}
+
+func (c *SplitCommand) Execute(_ []string) error {
+	return c.App.Split(rootCtx, c.Args.Number, app.SplitOptions{Replace: c.Replace, DryRun: c.DryRun})
+}
+
+func (c *TasksCommand) Execute(_ []string) error {
+	return c.App.Tasks(rootCtx, c.Args.Number, app.TasksOptions{Toggle: c.Args.Items})
+}
+
+func (c *GrepCommand) Execute(_ []string) error {
+	return c.App.Grep(rootCtx, c.Args.Pattern, app.GrepOptions{
+		Context:    c.Context,
+		IgnoreCase: c.IgnoreCase,
+		Fixed:      c.Fixed,
+	})
+}
+
+func (c *PlanCommand) Execute(_ []string) error {
+	return c.App.Estimate(rootCtx, app.EstimateOptions{
+		Milestone: c.Milestone,
+		Field:     c.Field,
+		All:       c.All,
+		Summary:   c.Summary,
+	})
+}
+
+func (c *ReportCommand) Execute(_ []string) error {
+	return c.App.Report(rootCtx, app.ReportOptions{
+		By:       c.By,
+		State:    c.State,
+		Field:    c.Field,
+		Markdown: c.Markdown,
+	})
+}
+
+func (c *AuditCommand) Execute(_ []string) error {
+	return c.App.Audit(rootCtx, app.AuditOptions{
+		Issue: c.Issue,
+		Since: c.Since,
+		Limit: c.Limit,
+		JSON:  c.JSON,
+	})
+}
+
+func (c *LintCommand) Execute(_ []string) error {
+	return c.App.Lint(rootCtx, app.LintOptions{All: c.All, Fix: c.Fix}, c.Args.Issues)
+}
+
+func (c *TranslateCommand) Execute(_ []string) error {
+	return c.App.Translate(rootCtx, c.Args.Number, app.TranslateOptions{To: c.To, NoComments: c.NoComments})
+}
+
+func (c *SummarizeCommand) Execute(_ []string) error {
+	return c.App.Summarize(rootCtx, c.Args.Number, app.SummarizeOptions{Clear: c.Clear})
+}
+
+func (c *BlameCommand) Execute(_ []string) error {
+	return c.App.Blame(rootCtx, c.Args.Number)
+}
+
+func (c *WatchCommand) Execute(_ []string) error {
+	return c.App.Watch(rootCtx, app.WatchOptions{Interval: c.Interval, Notify: c.Notify})
+}
+
+func (c *InboxCommand) Execute(_ []string) error {
+	return c.App.Inbox(rootCtx, app.InboxOptions{All: c.All, Pull: c.Pull, MarkRead: c.MarkRead})
+}
+
+func (c *TodoCommand) Execute(_ []string) error {
+	return c.App.Todo(rootCtx, strings.Join(c.Args.Text, " "))
+}
+
+func (c *LinkCodeCommand) Execute(_ []string) error {
+	return c.App.LinkCode(rootCtx, c.Args.Ref, c.Args.Location)
+}
+
+func (c *ScanTodosCommand) Execute(_ []string) error {
+	return c.App.ScanTodos(rootCtx, app.ScanTodosOptions{DryRun: c.DryRun})
+}
+
+func (c *PromoteCommand) Execute(_ []string) error {
+	return c.App.Promote(rootCtx, c.Args.Ref)
+}
+
+func (c *SuggestCommand) Execute(_ []string) error {
+	return c.App.SuggestAssignee(rootCtx, c.Args.Ref, app.SuggestAssigneeOptions{Apply: c.Apply, Limit: c.Limit})
+}
+
+func (c *ConflictsCommand) Execute(_ []string) error {
+	return c.App.Conflicts(rootCtx)
+}
+
+func (c *ResolveCommand) Execute(_ []string) error {
+	return c.App.Resolve(rootCtx, c.Args.Number, app.ResolveOptions{Ours: c.Ours, Theirs: c.Theirs})
+}
+
+func (c *CISyncCommand) Execute(_ []string) error {
+	return c.App.CISync(rootCtx, app.CISyncOptions{All: c.All, Push: c.Push, OpenPR: c.OpenPR, Branch: c.Branch, Base: c.Base})
+}
+
+func (c *TickCommand) Execute(_ []string) error {
+	return c.App.Tick(rootCtx, app.TickOptions{DryRun: c.DryRun})
+}
+
+func (c *TriageCommand) Execute(_ []string) error {
+	return c.App.Triage(rootCtx, app.TriageOptions{Query: c.Search})
+}
+
+func (c *CommentReplyCommand) Execute(_ []string) error {
+	return c.App.CommentReply(rootCtx, c.Args.Number, c.Args.CommentID)
+}
+
+func (c *CommentReviewCommand) Execute(_ []string) error {
+	return c.App.CommentReview(rootCtx)
+}
+
+func (c *AuthStatusCommand) Execute(_ []string) error {
+	return c.App.AuthStatus(rootCtx)
+}
+
+func (c *LabelAuditCommand) Execute(_ []string) error {
+	return c.App.LabelAudit(rootCtx, app.LabelAuditOptions{Merge: c.Merge})
+}
+
+func (c *LabelMergeCommand) Execute(_ []string) error {
+	return c.App.LabelMerge(rootCtx, c.Args.From, c.Args.To)
+}
+
+func (c *NotesEncryptCommand) Execute(_ []string) error {
+	return c.App.EncryptNotes(rootCtx, c.Args.Issues)
+}
+
+func (c *NotesDecryptCommand) Execute(_ []string) error {
+	return c.App.DecryptNotes(rootCtx, c.Args.Issues)
+}
+
+func (c *NotesShowCommand) Execute(_ []string) error {
+	return c.App.ShowNotes(rootCtx, c.Args.Number)
+}
+
+func (c *CacheRebuildCommand) Execute(_ []string) error {
+	return c.App.RebuildCache(rootCtx)
+}
+
+func (c *IndexRebuildCommand) Execute(_ []string) error {
+	return c.App.RebuildIndexes(rootCtx)
+}
+
+func (c *BoardCommand) Execute(_ []string) error {
+	return c.App.Board(rootCtx, app.BoardOptions{Apply: c.Apply})
+}
+
+func (c *SyncStateRepairCommand) Execute(_ []string) error {
+	return c.App.RepairSyncState(rootCtx)
+}
+
+func (c *GCCommand) Execute(_ []string) error {
+	return c.App.GC(rootCtx, app.GCOptions{
+		DryRun:       c.DryRun,
+		NoRemote:     c.NoRemote,
+		MaxAge:       time.Duration(c.OlderThan) * 24 * time.Hour,
+		MaxCacheSize: c.MaxCacheSize << 10,
+	})
+}
+
+func (c *BenchCommand) Execute(_ []string) error {
+	return c.App.Bench(rootCtx, app.BenchOptions{Issues: c.Issues, Dir: c.Dir})
 }
 
 func (c *WriteSkillCommand) Execute(args []string) error {
@@ -389,12 +1125,22 @@ func main() {
 	}
 
 	application := app.New(root, ghcli.ExecRunner{}, os.Stdout, os.Stderr)
+	// The env var names the issues directory itself, which in org mode is
+	// a per-repository directory such as .issues/<repo>.
+	if envDir := os.Getenv(paths.EnvIssuesDir); envDir != "" {
+		if !filepath.IsAbs(envDir) {
+			envDir = filepath.Join(cwd, envDir)
+		}
+		application.IssuesDir = envDir
+	}
 	opts := Options{}
 	opts.Init.App = application
 	opts.Pull.App = application
 	opts.Push.App = application
 	opts.Sync.App = application
+	opts.CISync.App = application
 	opts.Status.App = application
+	opts.WhatsNew.App = application
 	opts.List.App = application
 	opts.New.App = application
 	opts.Edit.App = application
@@ -402,8 +1148,55 @@ func main() {
 	opts.Close.App = application
 	opts.Reopen.App = application
 	opts.Diff.App = application
+	opts.Blame.App = application
+	opts.Log.App = application
+	opts.Conflicts.App = application
+	opts.Resolve.App = application
+	opts.Split.App = application
+	opts.Tasks.App = application
+	opts.Grep.App = application
+	opts.Watch.App = application
+	opts.Inbox.App = application
+	opts.LinkCode.App = application
+	opts.ScanTodos.App = application
+	opts.Todo.App = application
+	opts.Promote.App = application
+	opts.Tick.App = application
+	opts.Plan.App = application
+	opts.Report.App = application
+	opts.Audit.App = application
+	opts.Lint.App = application
+	opts.Translate.App = application
+	opts.Summarize.App = application
+	opts.Suggest.App = application
+	opts.Triage.App = application
+	opts.Comment.Reply.App = application
+	opts.Comment.Review.App = application
+	opts.Auth.Status.App = application
+	opts.Label.Audit.App = application
+	opts.Label.Merge.App = application
+	opts.Notes.Encrypt.App = application
+	opts.Notes.Decrypt.App = application
+	opts.Notes.Show.App = application
+	opts.Cache.Rebuild.App = application
+	opts.Index.Rebuild.App = application
+	opts.Board.App = application
+	opts.SyncState.Repair.App = application
+	opts.GC.App = application
+	opts.Bench.App = application
 
 	parser := flags.NewParser(&opts, flags.HelpFlag|flags.PassDoubleDash)
+	parser.CommandHandler = func(cmd flags.Commander, args []string) error {
+		application.ReadOnly = opts.ReadOnly
+		application.Runner = ghcli.ExecRunner{Timeout: opts.Timeout}
+		if opts.Stats {
+			application.Stats = &ghcli.QueryStats{}
+		}
+		if cmd == nil {
+			return nil
+		}
+		return cmd.Execute(args)
+	}
 	parser.ShortDescription = "Sync GitHub issues to local Markdown files."
 	parser.LongDescription = "gh-issue-sync mirrors GitHub issues into a local .issues directory.\n\nUse init to create the layout, pull to fetch issues, edit files locally, and push to sync changes.\n\nExamples:\n  gh-issue-sync init --owner acme --repo roadmap\n  gh-issue-sync pull\n  gh-issue-sync new --edit\n  gh-issue-sync push"
 
@@ -420,7 +1213,29 @@ func main() {
 		}
 	}
 
-	if _, err := parser.Parse(); err != nil {
+	// The first interrupt cancels the running command, which stops its gh
+	// calls and unwinds so that the lock is released; a second one exits
+	// right away
+	ctx, cancel := context.WithCancel(context.Background())
+	rootCtx = ctx
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		app.RestoreCursor()
+		cancel()
+		<-sigs
+		app.RestoreCursor()
+		os.Exit(130)
+	}()
+
+	_, err = parser.Parse()
+	// Changes made before a failure are kept, as with plain files
+	if flushErr := application.Flush(context.Background()); err == nil {
+		err = flushErr
+	}
+	application.PrintStats()
+	if err != nil {
 		if flagsErr, ok := err.(*flags.Error); ok {
 			if flagsErr.Type == flags.ErrHelp {
 				fmt.Fprint(os.Stdout, flagsErr.Message)
@@ -430,6 +1245,10 @@ func main() {
 			parser.WriteHelp(os.Stderr)
 			os.Exit(1)
 		}
+		if ctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", app.InterruptError(err))
+			os.Exit(130)
+		}
 		fmt.Fprintf(os.Stderr, "error: %s\n", err)
 		fmt.Fprintln(os.Stderr, "hint: run `gh-issue-sync --help` for usage")
 		os.Exit(1)
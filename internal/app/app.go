@@ -10,47 +10,103 @@ import (
 
 	"github.com/mitsuhiko/gh-issue-sync/internal/config"
 	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/gitlab"
 	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
 	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
 )
 
 type App struct {
-	Root   string
-	Runner ghcli.Runner
-	Now    func() time.Time
-	Out    io.Writer
-	Err    io.Writer
-	Theme  *theme.Theme
+	Root string
+	// IssuesDir overrides Root/.issues, e.g. for a repository in org mode.
+	IssuesDir string
+	Runner    ghcli.Runner
+	Now       func() time.Time
+	In        io.Reader
+	Out       io.Writer
+	Err       io.Writer
+	Theme     *theme.Theme
+	// ReadOnly makes the mirror read-only regardless of the config.
+	ReadOnly bool
+	// Stats collects the cost of batched GraphQL calls when set.
+	Stats *ghcli.QueryStats
+	// stores are the issue stores opened by the commands, shared with the
+	// Apps of the repositories in org mode.
+	stores *storeSet
 }
 
 type PullOptions struct {
 	All   bool
 	Force bool
 	Full  bool // Force full sync, bypassing incremental
-	Label []string
+	// Label, Milestone and Assignee scope the pull to matching issues.
+	Label     []string
+	Milestone string
+	Assignee  string
+	// DryRun reports what would be written without touching any files.
+	DryRun bool
+	JSON   bool // Print the dry-run plan as JSON
+	// Review asks before applying each incoming change.
+	Review bool
+	// FromExport seeds the mirror from a bulk dump of the issues instead
+	// of listing them through the API.
+	FromExport string
 }
 
 type PushOptions struct {
-	DryRun     bool
-	NoComments bool
-	Force      bool
+	DryRun           bool
+	JSON             bool // Print the dry-run plan as JSON
+	NoComments       bool
+	Force            bool
+	AllowMassChanges bool        // Allow closing/retitling more issues than the configured threshold
+	LabelPolicy      LabelPolicy // How to handle labels that do not exist on the remote
+	NoLint           bool        // Skip checking required template sections, references and body checkers
+	Strict           bool        // Refuse to close issues with open dependencies
 }
 
+// LabelPolicy controls whether push creates labels missing on the remote.
+type LabelPolicy string
+
+const (
+	// LabelPolicySuggest creates new labels unless they look like a typo of
+	// an existing label, in which case the push stops with a suggestion.
+	LabelPolicySuggest LabelPolicy = ""
+	// LabelPolicyCreate creates every missing label.
+	LabelPolicyCreate LabelPolicy = "create"
+	// LabelPolicyNone never creates labels and stops on unknown ones.
+	LabelPolicyNone LabelPolicy = "none"
+)
+
 type NewOptions struct {
-	Labels []string
-	Edit   bool
+	Labels     []string
+	Edit       bool
+	NoEdit     bool   // Create the issue from the buffer saved by a failed --edit
+	Draft      bool   // Create in .issues/drafts instead of open
+	Type       string // Issue type; seeds the file from its template
+	FromPR     string // Seed from a pull request: title, link, diff stat, inferred labels
+	FromCommit string // Seed from a commit in the local repository
 }
 
 type CloseOptions struct {
 	Reason string
+	Strict bool // Fail instead of warning when dependencies are still open
+}
+
+type StatusOptions struct {
+	Remote bool // Compare tracked issues against the remote
 }
 
 type DiffOptions struct {
 	Remote bool
+	At     string // Diff against the snapshot kept at this time
+}
+
+type EditOptions struct {
+	WithComments bool // Show recent comments below the body while editing
 }
 
 type ViewOptions struct {
-	Raw bool
+	Raw   bool
+	Teams bool // Fetch the members of mentioned teams
 }
 
 type ListOptions struct {
@@ -64,7 +120,11 @@ type ListOptions struct {
 	Limit     int
 	Local     bool
 	Modified  bool
+	Epics     bool // Only issues with sub-issues, shown with their rollup
+	PRs       bool // Show linked pull requests with their state and checks
 	Search    string
+	Sort      string // Sort expression, e.g. "priority desc, updated asc"; overrides sort: in Search
+	Facets    string // Comma separated fields to count the matches by, e.g. "labels,milestone"
 }
 
 func New(root string, runner ghcli.Runner, out io.Writer, errOut io.Writer) *App {
@@ -72,24 +132,52 @@ func New(root string, runner ghcli.Runner, out io.Writer, errOut io.Writer) *App
 		Root:   root,
 		Runner: runner,
 		Now:    time.Now,
+		In:     os.Stdin,
 		Out:    out,
 		Err:    errOut,
 		Theme:  theme.Default(),
+		stores: newStoreSet(),
 	}
 }
 
-func (a *App) Init(ctx context.Context, owner, repo string) error {
+type InitOptions struct {
+	Owner    string
+	Repo     string
+	Provider string // "github" or "gitlab"; detected from the remote if empty
+	Host     string // forge hostname for self-managed instances
+	LocalIDs string // "random" (default) or "sequential" local issue IDs
+	// Org mirrors all repositories of an organization instead of one
+	// repository, filtered by the Include and Exclude globs.
+	Org     string
+	Include []string
+	Exclude []string
+}
+
+func (a *App) Init(ctx context.Context, opts InitOptions) error {
+	if opts.Org != "" {
+		return a.initOrg(ctx, opts)
+	}
+	owner, repo, provider, host := opts.Owner, opts.Repo, opts.Provider, opts.Host
 	if owner == "" || repo == "" {
-		ownerGuess, repoGuess, err := a.detectRepoFromGit(ctx)
+		remote, err := a.detectRepoFromGit(ctx)
 		if err != nil {
 			return fmt.Errorf("unable to detect repo from git: %w (use --owner and --repo)", err)
 		}
 		if owner == "" {
-			owner = ownerGuess
+			owner = remote.Owner
 		}
 		if repo == "" {
-			repo = repoGuess
+			repo = remote.Repo
+		}
+		if provider == "" {
+			provider = remote.Provider
 		}
+		if host == "" {
+			host = remote.Host
+		}
+	}
+	if provider == config.ProviderGitHub {
+		provider = ""
 	}
 
 	// Default to placing .issues next to .git
@@ -108,10 +196,114 @@ func (a *App) Init(ctx context.Context, owner, repo string) error {
 		return err
 	}
 	cfg := config.Default(owner, repo)
+	cfg.Repository.Provider = provider
+	cfg.Repository.Host = host
+	if opts.LocalIDs != config.LocalIDsRandom {
+		cfg.Local.IDs = opts.LocalIDs
+	}
 	if err := config.Save(p.ConfigPath, cfg); err != nil {
 		return err
 	}
+	if _, err := writeSyncGitignore(p); err != nil {
+		return err
+	}
 	t := a.Theme
 	fmt.Fprintf(a.Out, "%s %s %s %s\n", t.SuccessText("Initialized"), t.AccentText(owner+"/"+repo), t.MutedText("in"), p.IssuesDir)
 	return nil
 }
+
+// issuePaths returns the layout of the issues directory the app works on.
+func (a *App) issuePaths() paths.Paths {
+	p := paths.New(a.Root)
+	if a.IssuesDir != "" {
+		p = paths.NewAt(a.Root, a.IssuesDir)
+	}
+	// The layout and label views decide where issue files live, so every
+	// command sees them
+	if cfg, err := config.Load(p.ConfigPath); err == nil {
+		p = a.withStore(p, cfg.Storage)
+		p = p.WithLayout(cfg.Layout)
+		p.Format = issueFormat(cfg)
+		if len(cfg.Views) > 0 {
+			views := make([]paths.View, 0, len(cfg.Views))
+			for _, view := range cfg.Views {
+				views = append(views, paths.View{Name: view.Name, Label: view.Label})
+			}
+			p = p.WithViews(views)
+		}
+	}
+	return p
+}
+
+// newProvider returns the issue tracker client configured for the
+// repository, authenticated with the read token.
+func (a *App) newProvider(cfg config.Config) (ghcli.Provider, error) {
+	return a.newScopedProvider(cfg, false)
+}
+
+// newWriteProvider returns the client for commands that change the
+// tracker, authenticated with the write token.
+func (a *App) newWriteProvider(cfg config.Config) (ghcli.Provider, error) {
+	return a.newScopedProvider(cfg, true)
+}
+
+func (a *App) newScopedProvider(cfg config.Config, write bool) (ghcli.Provider, error) {
+	runner, err := a.tokenRunner(cfg, write)
+	if err != nil {
+		return nil, err
+	}
+	var client ghcli.Provider
+	switch cfg.Repository.Provider {
+	case "", config.ProviderGitHub:
+		github := ghcli.NewClient(runner, repoSlug(cfg))
+		github.SetStats(a.Stats)
+		client = github
+	case config.ProviderGitLab:
+		client = gitlab.NewClient(runner, repoSlug(cfg), cfg.Repository.Host)
+	default:
+		return nil, fmt.Errorf("unknown provider %q in config", cfg.Repository.Provider)
+	}
+	client = a.newAuditProvider(a.issuePaths(), repoSlug(cfg), client)
+	if a.readOnly(cfg) {
+		client = readOnlyProvider{client}
+	}
+	return client, nil
+}
+
+// PrintStats reports the batched GraphQL calls of the command and what
+// they cost.
+func (a *App) PrintStats() {
+	if a.Stats == nil {
+		return
+	}
+	t := a.Theme
+	total := a.Stats.Total()
+	fmt.Fprintf(a.Err, "%s %s\n", t.MutedText("GraphQL:"), t.MutedText(fmt.Sprintf("%d batched queries, %d rate limit points, ~%d nodes, %d batches split",
+		total.Queries, total.Points, total.Nodes, total.Splits)))
+}
+
+// tokenRunner returns the runner with the token configured for reads or
+// writes in the environment. A configured variable that is not set is an
+// error rather than a silent fallback to the logged in account.
+func (a *App) tokenRunner(cfg config.Config, write bool) (ghcli.Runner, error) {
+	name, key := cfg.Auth.ReadTokenEnv, "auth.read_token_env"
+	if write {
+		name, key = cfg.Auth.WriteTokenEnv, "auth.write_token_env"
+	}
+	if name == "" {
+		return a.Runner, nil
+	}
+	token := os.Getenv(name)
+	if token == "" {
+		return nil, fmt.Errorf("$%s is not set (%s in the config)", name, key)
+	}
+	runner, ok := a.Runner.(ghcli.EnvRunner)
+	if !ok {
+		return a.Runner, nil
+	}
+	tokenVar := "GH_TOKEN"
+	if cfg.Repository.Provider == config.ProviderGitLab {
+		tokenVar = "GITLAB_TOKEN"
+	}
+	return runner.WithEnv(tokenVar + "=" + token), nil
+}
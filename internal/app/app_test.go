@@ -1,11 +1,14 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -14,6 +17,7 @@ import (
 	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
 	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
 )
 
 func TestApplyMapping(t *testing.T) {
@@ -82,6 +86,20 @@ func TestApplyMappingHexIDs(t *testing.T) {
 	}
 }
 
+func TestApplyMappingWikiLinks(t *testing.T) {
+	item := issue.Issue{
+		Number: issue.IssueNumber("T1"),
+		Title:  "Wiki",
+		Body:   "See [[Tabc]] and [[#Tabc]], not [[Tother]].\n",
+	}
+	if !applyMapping(&item, map[string]string{"Tabc": "42"}) {
+		t.Fatalf("expected mapping to report change")
+	}
+	if item.Body != "See [[#42]] and [[#42]], not [[Tother]].\n" {
+		t.Fatalf("unexpected body: %q", item.Body)
+	}
+}
+
 func TestApplyMappingNoChange(t *testing.T) {
 	item := issue.Issue{
 		Number: issue.IssueNumber("T1"),
@@ -159,6 +177,224 @@ func TestNewIssueFromEditor(t *testing.T) {
 	}
 }
 
+func TestNewIssueEditorConfigAndHelp(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Editor = config.EditorConfig{Command: "code --wait", Commands: map[string]string{"new": "vim -f"}}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := saveLabelCache(p, LabelCache{Labels: []LabelEntry{{Name: "bug"}, {Name: "docs"}}}); err != nil {
+		t.Fatalf("labels: %v", err)
+	}
+	if err := saveMilestoneCache(p, MilestoneCache{Milestones: []MilestoneEntry{{Title: "v1.0", State: "open"}, {Title: "v0.9", State: "closed"}}}); err != nil {
+		t.Fatalf("milestones: %v", err)
+	}
+
+	var buffer, editor string
+	previousInteractive := runInteractiveCommand
+	runInteractiveCommand = func(ctx context.Context, command string, args ...string) error {
+		editor = command
+		data, err := os.ReadFile(args[len(args)-1])
+		if err != nil {
+			return err
+		}
+		buffer = string(data)
+		return os.WriteFile(args[len(args)-1], append(data, "It crashes.\n"...), 0o644)
+	}
+	t.Cleanup(func() { runInteractiveCommand = previousInteractive })
+	t.Setenv("EDITOR", "nano")
+
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+	if err := application.NewIssue(context.Background(), "Crash", NewOptions{Edit: true}); err != nil {
+		t.Fatalf("new issue: %v", err)
+	}
+	if editor != "vim -f" {
+		t.Fatalf("expected the editor configured for new, got %q", editor)
+	}
+	if !strings.HasPrefix(buffer, "---\n# ") || !strings.Contains(buffer, "# Labels: bug, docs\n") ||
+		!strings.Contains(buffer, "# Milestones: v1.0\n") {
+		t.Fatalf("expected a help header, got %q", buffer)
+	}
+
+	entries, err := os.ReadDir(p.OpenDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one issue file, got %v (%v)", entries, err)
+	}
+	data, err := os.ReadFile(filepath.Join(p.OpenDir, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	if strings.Contains(string(data), "#") || !strings.Contains(string(data), "title: Crash") ||
+		!strings.Contains(string(data), "It crashes.") {
+		t.Fatalf("expected the help to be stripped, got %q", data)
+	}
+}
+
+func TestNewIssueEditorAbortAndResume(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var edit func(data string) string
+	var seen string
+	previousInteractive := runInteractiveCommand
+	runInteractiveCommand = func(ctx context.Context, command string, args ...string) error {
+		data, err := os.ReadFile(args[len(args)-1])
+		if err != nil {
+			return err
+		}
+		seen = string(data)
+		return os.WriteFile(args[len(args)-1], []byte(edit(seen)), 0o644)
+	}
+	t.Cleanup(func() { runInteractiveCommand = previousInteractive })
+	t.Setenv("EDITOR", "true")
+
+	ctx := context.Background()
+	var out bytes.Buffer
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	application.In = strings.NewReader("")
+	issueFiles := func() int {
+		entries, err := os.ReadDir(p.OpenDir)
+		if err != nil {
+			t.Fatalf("read open dir: %v", err)
+		}
+		return len(entries)
+	}
+
+	// Unchanged and emptied buffers abort without creating anything
+	for _, fn := range []func(string) string{
+		func(data string) string { return data },
+		func(string) string { return "" },
+	} {
+		edit = fn
+		if err := application.NewIssue(ctx, "Crash", NewOptions{Edit: true}); err != nil {
+			t.Fatalf("new issue: %v", err)
+		}
+	}
+	if issueFiles() != 0 || !strings.Contains(out.String(), "Aborting issue creation") {
+		t.Fatalf("expected aborts, got %d files and %q", issueFiles(), out.String())
+	}
+
+	// A buffer without a title is kept
+	edit = func(string) string { return "---\ntitle: \"\"\n---\n\nSteps to reproduce\n" }
+	if err := application.NewIssue(ctx, "", NewOptions{Edit: true}); err == nil || !strings.Contains(err.Error(), "new --edit") {
+		t.Fatalf("expected an error pointing at the saved buffer, got %v", err)
+	}
+	if _, err := os.Stat(p.NewIssuePath); err != nil {
+		t.Fatalf("expected the buffer to be saved: %v", err)
+	}
+
+	// new --edit resumes it
+	edit = func(data string) string { return strings.Replace(data, `title: ""`, "title: Crash", 1) }
+	if err := application.NewIssue(ctx, "", NewOptions{Edit: true}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if !strings.Contains(seen, "Steps to reproduce") || issueFiles() != 1 {
+		t.Fatalf("expected the saved buffer to be resumed, saw %q", seen)
+	}
+	if _, err := os.Stat(p.NewIssuePath); !os.IsNotExist(err) {
+		t.Fatalf("expected the saved buffer to be removed: %v", err)
+	}
+
+	// new --no-edit creates the issue from a saved buffer directly
+	if err := application.NewIssue(ctx, "", NewOptions{NoEdit: true}); err == nil {
+		t.Fatal("expected an error without a saved buffer")
+	}
+	if err := os.WriteFile(p.NewIssuePath, []byte("---\ntitle: Hang\n---\n\nIt hangs.\n"), 0o644); err != nil {
+		t.Fatalf("write buffer: %v", err)
+	}
+	if err := application.NewIssue(ctx, "", NewOptions{NoEdit: true}); err != nil {
+		t.Fatalf("no-edit: %v", err)
+	}
+	if issueFiles() != 2 {
+		t.Fatalf("expected two issues, got %d", issueFiles())
+	}
+}
+
+func TestNewIssueRecoversCrashedEditor(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var seen string
+	crash := true
+	previousInteractive := runInteractiveCommand
+	runInteractiveCommand = func(ctx context.Context, command string, args ...string) error {
+		path := args[len(args)-1]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		seen = string(data)
+		if crash {
+			// The editor saved a long text and was then killed
+			if err := os.WriteFile(path, []byte("---\ntitle: Long story\n---\n\nLots of text\n"), 0o644); err != nil {
+				return err
+			}
+			return fmt.Errorf("signal: killed")
+		}
+		return nil
+	}
+	t.Cleanup(func() { runInteractiveCommand = previousInteractive })
+	t.Setenv("EDITOR", "true")
+
+	ctx := context.Background()
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+	if err := application.NewIssue(ctx, "", NewOptions{Edit: true}); err == nil || !strings.Contains(err.Error(), "killed") {
+		t.Fatalf("expected the editor error, got %v", err)
+	}
+	if !strings.HasPrefix(p.NewIssuePath, p.RecoveryDir) {
+		t.Fatalf("expected the buffer in %s, got %s", p.RecoveryDir, p.NewIssuePath)
+	}
+	if _, err := os.Stat(p.NewIssuePath); err != nil {
+		t.Fatalf("expected the buffer to survive: %v", err)
+	}
+
+	// Declining the recovery starts from scratch
+	crash = false
+	application.In = strings.NewReader("n\n")
+	if err := application.NewIssue(ctx, "", NewOptions{Edit: true}); err != nil {
+		t.Fatalf("new issue: %v", err)
+	}
+	if strings.Contains(seen, "Lots of text") {
+		t.Fatalf("expected a fresh buffer, got %q", seen)
+	}
+
+	// Accepting it edits the recovered buffer
+	crash = true
+	_ = application.NewIssue(ctx, "", NewOptions{Edit: true})
+	crash = false
+	application.In = strings.NewReader("y\n")
+	if err := application.NewIssue(ctx, "", NewOptions{Edit: true}); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if !strings.Contains(seen, "Lots of text") {
+		t.Fatalf("expected the recovered buffer, got %q", seen)
+	}
+	entries, err := os.ReadDir(p.OpenDir)
+	if err != nil || len(entries) != 1 || !strings.Contains(entries[0].Name(), "long-story") {
+		t.Fatalf("expected the recovered issue, got %v (%v)", entries, err)
+	}
+	if _, err := os.Stat(p.NewIssuePath); !os.IsNotExist(err) {
+		t.Fatalf("expected the buffer to be removed: %v", err)
+	}
+}
+
 func TestOrphanedOriginalsDetection(t *testing.T) {
 	root := t.TempDir()
 	p := paths.New(root)
@@ -542,6 +778,19 @@ func TestList(t *testing.T) {
 	if strings.Contains(output, "#2") {
 		t.Fatalf("issue not mentioning charlie should not be in output: %s", output)
 	}
+
+	// Test: facets count all matches, not only the shown ones
+	out.Reset()
+	application.Theme = theme.Plain()
+	if err := application.List(context.Background(), ListOptions{All: true, Limit: 1, Facets: "milestone,state"}); err != nil {
+		t.Fatalf("list --facets: %v", err)
+	}
+	output = out.String()
+	for _, want := range []string{"milestone\n", "  v1.0    2  ", "  (none)  2  ", "  open    3  ", "  closed  1  "} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in facets: %s", want, output)
+		}
+	}
 }
 
 func TestLocalIssuesNotOrphaned(t *testing.T) {
@@ -702,3 +951,121 @@ func testParseInteractiveCommand(command string, extraArgs []string, name *strin
 	*args = append(parts[1:], extraArgs...)
 	return nil
 }
+
+func TestParseGitLabRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		owner  string
+		repo   string
+		host   string
+	}{
+		{"git@gitlab.com:group/project.git", "group", "project", ""},
+		{"https://gitlab.com/group/sub/project.git", "group/sub", "project", ""},
+		{"ssh://git@gitlab.example.com:2222/team/tool.git", "team", "tool", "gitlab.example.com"},
+	}
+	for _, tt := range tests {
+		owner, repo, host, err := parseGitLabRemote(tt.remote)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.remote, err)
+		}
+		if owner != tt.owner || repo != tt.repo || host != tt.host {
+			t.Fatalf("%s: got %q %q %q", tt.remote, owner, repo, host)
+		}
+	}
+	if _, _, _, err := parseGitLabRemote("git@github.com:octo/repo.git"); err == nil {
+		t.Fatalf("expected github remote to be rejected")
+	}
+}
+
+func TestSuggestLabel(t *testing.T) {
+	known := []string{"bug", "enhancement", "documentation"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"bgu", "bug"},
+		{"enhancment", "enhancement"},
+		{"docs", ""},
+		{"performance", ""},
+	}
+	for _, tt := range tests {
+		if got := suggestLabel(tt.name, known); got != tt.want {
+			t.Fatalf("suggestLabel(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSubIssueRollups(t *testing.T) {
+	parent := issue.IssueRef("1")
+	issues := []IssueFile{
+		{Issue: issue.Issue{Number: "1", Title: "Epic"}, State: "open"},
+		{Issue: issue.Issue{Number: "2", Parent: &parent}, State: "closed"},
+		{Issue: issue.Issue{Number: "T1", Parent: &parent}, State: "open"},
+		{Issue: issue.Issue{Number: "5", SubIssues: &issue.SubIssueSummary{Total: 7, Completed: 3}}, State: "open"},
+	}
+	rollups := subIssueRollups(issues)
+	if got := rollups["1"]; got.Total != 2 || got.Completed != 1 {
+		t.Fatalf("expected local rollup 1/2 for #1, got %+v", got)
+	}
+	if got := formatRollup(rollups["5"]); got != "3/7 sub-issues done" {
+		t.Fatalf("unexpected rollup for #5: %q", got)
+	}
+	if _, ok := rollups["2"]; ok {
+		t.Fatalf("issue without children should have no rollup")
+	}
+}
+
+// envRunner records the environment its commands run with.
+type envRunner struct {
+	env  []string
+	runs *[][]string
+}
+
+func (r envRunner) WithEnv(env ...string) ghcli.Runner {
+	return envRunner{env: append(append([]string(nil), r.env...), env...), runs: r.runs}
+}
+
+func (r envRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	*r.runs = append(*r.runs, r.env)
+	return "", errors.New("offline")
+}
+
+func TestScopedTokens(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Auth = config.AuthConfig{ReadTokenEnv: "TEST_READ_TOKEN", WriteTokenEnv: "TEST_WRITE_TOKEN"}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	var runs [][]string
+	application := New(root, envRunner{runs: &runs}, io.Discard, io.Discard)
+	t.Setenv("TEST_READ_TOKEN", "read")
+	t.Setenv("TEST_WRITE_TOKEN", "")
+
+	err := application.Push(context.Background(), PushOptions{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "$TEST_WRITE_TOKEN is not set") {
+		t.Fatalf("expected push to require the write token, got %v", err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("expected nothing run without the write token, got %v", runs)
+	}
+
+	client, err := application.newProvider(cfg)
+	if err != nil {
+		t.Fatalf("read provider: %v", err)
+	}
+	client.ListLabels(context.Background())
+	t.Setenv("TEST_WRITE_TOKEN", "write")
+	client, err = application.newWriteProvider(cfg)
+	if err != nil {
+		t.Fatalf("write provider: %v", err)
+	}
+	client.ListLabels(context.Background())
+	if len(runs) != 2 || !slices.Equal(runs[0], []string{"GH_TOKEN=read"}) || !slices.Equal(runs[1], []string{"GH_TOKEN=write"}) {
+		t.Fatalf("unexpected environments: %v", runs)
+	}
+}
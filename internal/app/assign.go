@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+type SuggestAssigneeOptions struct {
+	Apply bool // Add the top suggestion to the issue's assignees
+	Limit int  // Number of suggestions to show (default 5)
+}
+
+// assigneeSuggestion scores a collaborator for an issue. Expertise counts
+// the labels they share with the issue across every issue they were
+// assigned to; Load is the number of open issues they hold right now.
+type assigneeSuggestion struct {
+	Login     string
+	Expertise int
+	Load      int
+}
+
+// Score favors expertise and discounts busy people, so an expert with ten
+// open issues ranks below one with a comparable record and nothing on
+// their plate.
+func (s assigneeSuggestion) Score() float64 {
+	return float64(s.Expertise+1) / float64(s.Load+1)
+}
+
+// suggestAssignees ranks everyone assigned to any local issue, except the
+// current assignees of target.
+func suggestAssignees(target issue.Issue, issues []IssueFile) []assigneeSuggestion {
+	labels := make(map[string]struct{}, len(target.Labels))
+	for _, label := range target.Labels {
+		labels[strings.ToLower(label)] = struct{}{}
+	}
+	byLogin := map[string]*assigneeSuggestion{}
+	for _, item := range issues {
+		if item.Issue.Number == target.Number {
+			continue
+		}
+		shared := 0
+		for _, label := range item.Issue.Labels {
+			if _, ok := labels[strings.ToLower(label)]; ok {
+				shared++
+			}
+		}
+		for _, login := range item.Issue.Assignees {
+			key := strings.ToLower(login)
+			s, ok := byLogin[key]
+			if !ok {
+				s = &assigneeSuggestion{Login: login}
+				byLogin[key] = s
+			}
+			s.Expertise += shared
+			if item.State != "closed" {
+				s.Load++
+			}
+		}
+	}
+
+	var suggestions []assigneeSuggestion
+	for key, s := range byLogin {
+		if containsFold(target.Assignees, key) {
+			continue
+		}
+		suggestions = append(suggestions, *s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		si, sj := suggestions[i].Score(), suggestions[j].Score()
+		if si != sj {
+			return si > sj
+		}
+		return strings.ToLower(suggestions[i].Login) < strings.ToLower(suggestions[j].Login)
+	})
+	return suggestions
+}
+
+// SuggestAssignee ranks collaborators for an issue by label expertise and
+// current open-issue load, using only the local mirror.
+func (a *App) SuggestAssignee(ctx context.Context, ref string, opts SuggestAssigneeOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	file, err := findIssueByRef(a.Root, p, ref)
+	if err != nil {
+		return err
+	}
+	issues, err := loadLocalIssues(p)
+	if err != nil {
+		return err
+	}
+	suggestions := suggestAssignees(file.Issue, issues)
+	if len(suggestions) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No candidates: nobody is assigned to any other local issue"))
+		return nil
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	fmt.Fprintln(a.Out, t.FormatIssueHeader(file.State, file.Issue.Number.String(), file.Issue.Title))
+	for i, s := range suggestions {
+		if i >= limit {
+			break
+		}
+		fmt.Fprintf(a.Out, "  %-20s %s\n", s.Login,
+			t.MutedText(fmt.Sprintf("%d open, %d matching label(s)", s.Load, s.Expertise)))
+	}
+
+	if !opts.Apply {
+		return nil
+	}
+	top := suggestions[0].Login
+	if err := a.updateLocalIssue(ctx, p, file.Issue.Number.String(), func(iss *issue.Issue) {
+		iss.Assignees = applyListEdit(iss.Assignees, top)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%s %s to #%s %s\n", t.SuccessText("Assigned"), top, file.Issue.Number, t.MutedText("(run push to sync)"))
+	return nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestSuggestAssignee(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	write := func(dir string, iss issue.Issue) {
+		t.Helper()
+		if err := issue.WriteFile(issue.PathFor(dir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	write(p.OpenDir, issue.Issue{Number: "1", Title: "Target", State: "open", Labels: []string{"parser"}})
+	// alice knows the parser but is swamped, bob knows it and is free
+	write(p.ClosedDir, issue.Issue{Number: "2", Title: "A", State: "closed", Labels: []string{"parser"}, Assignees: []string{"alice", "bob"}})
+	write(p.ClosedDir, issue.Issue{Number: "3", Title: "B", State: "closed", Labels: []string{"parser"}, Assignees: []string{"alice"}})
+	write(p.OpenDir, issue.Issue{Number: "4", Title: "C", State: "open", Labels: []string{"ui"}, Assignees: []string{"alice"}})
+	write(p.OpenDir, issue.Issue{Number: "5", Title: "D", State: "open", Labels: []string{"ui"}, Assignees: []string{"alice"}})
+	write(p.OpenDir, issue.Issue{Number: "6", Title: "E", State: "open", Labels: []string{"ui"}, Assignees: []string{"carol"}})
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	if err := application.SuggestAssignee(context.Background(), "1", SuggestAssigneeOptions{Apply: true}); err != nil {
+		t.Fatalf("suggest: %v", err)
+	}
+	output := out.String()
+	bob, alice, carol := strings.Index(output, "bob"), strings.Index(output, "alice"), strings.Index(output, "carol")
+	if bob < 0 || alice < 0 || carol < 0 || bob > alice || alice > carol {
+		t.Fatalf("unexpected ranking:\n%s", output)
+	}
+	file, err := findIssueByNumber(p, "1")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if strings.Join(file.Issue.Assignees, ",") != "bob" {
+		t.Fatalf("expected bob to be assigned, got %v", file.Issue.Assignees)
+	}
+}
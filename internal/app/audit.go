@@ -0,0 +1,360 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// AuditEntry is one remote mutation in .issues/.sync/audit.jsonl. The file
+// is only ever appended to.
+type AuditEntry struct {
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor,omitempty"`
+	Repository string    `json:"repository"`
+	// Endpoint is the tracker operation, e.g. CreateIssue or CloseIssue.
+	Endpoint string `json:"endpoint"`
+	Issue    string `json:"issue,omitempty"`
+	// Target is the label, milestone or notification a mutation changed.
+	Target  string         `json:"target,omitempty"`
+	Changes map[string]any `json:"changes,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// auditProvider records every mutation of the wrapped provider, failed
+// ones included. The actor is the login the credentials check reports,
+// or the local git identity if the check did not run.
+type auditProvider struct {
+	ghcli.Provider
+	app        *App
+	path       string
+	repository string
+
+	mu    sync.Mutex
+	actor string
+}
+
+func (a *App) newAuditProvider(p paths.Paths, repository string, client ghcli.Provider) *auditProvider {
+	return &auditProvider{Provider: client, app: a, path: p.AuditPath, repository: repository}
+}
+
+func (ap *auditProvider) CheckAuth(ctx context.Context) (ghcli.AuthStatus, error) {
+	status, err := ap.Provider.CheckAuth(ctx)
+	if err == nil && status.User != "" {
+		ap.mu.Lock()
+		ap.actor = status.User
+		ap.mu.Unlock()
+	}
+	return status, err
+}
+
+func (ap *auditProvider) record(ctx context.Context, entry AuditEntry, err error) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	if ap.actor == "" {
+		ap.actor = ap.app.localEditor(ctx)
+	}
+	entry.Time = ap.app.Now().UTC()
+	entry.Actor = ap.actor
+	entry.Repository = ap.repository
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if writeErr := appendAuditEntry(ap.path, entry); writeErr != nil {
+		fmt.Fprintf(ap.app.Err, "%s writing audit log: %v\n", ap.app.Theme.WarningText("Warning:"), writeErr)
+	}
+}
+
+func appendAuditEntry(path string, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// bodyChange describes a body in the audit log by its length instead of
+// repeating it.
+func bodyChange(body string) string {
+	return strconv.Itoa(len(body)) + " bytes"
+}
+
+func (ap *auditProvider) CreateIssue(ctx context.Context, iss issue.Issue) (string, error) {
+	number, err := ap.Provider.CreateIssue(ctx, iss)
+	changes := map[string]any{"title": iss.Title, "body": bodyChange(iss.Body)}
+	if len(iss.Labels) > 0 {
+		changes["labels"] = iss.Labels
+	}
+	if len(iss.Assignees) > 0 {
+		changes["assignees"] = iss.Assignees
+	}
+	if iss.Milestone != "" {
+		changes["milestone"] = iss.Milestone
+	}
+	if iss.IssueType != "" {
+		changes["type"] = iss.IssueType
+	}
+	if len(iss.Projects) > 0 {
+		changes["projects"] = iss.Projects
+	}
+	ap.record(ctx, AuditEntry{Endpoint: "CreateIssue", Issue: number, Changes: changes}, err)
+	return number, err
+}
+
+func (ap *auditProvider) BatchEditIssues(ctx context.Context, updates []ghcli.BatchIssueUpdate) (ghcli.BatchUpdateResult, error) {
+	result, err := ap.Provider.BatchEditIssues(ctx, updates)
+	for _, update := range updates {
+		changes := map[string]any{}
+		if update.Title != nil {
+			changes["title"] = *update.Title
+		}
+		if update.Body != nil {
+			changes["body"] = bodyChange(*update.Body)
+		}
+		if update.Milestone != nil || update.ClearMilestone {
+			milestone := ""
+			if update.Milestone != nil && !update.ClearMilestone {
+				milestone = *update.Milestone
+			}
+			changes["milestone"] = milestone
+		}
+		if update.Labels != nil || update.ClearLabels {
+			changes["labels"] = nonNil(update.Labels)
+		}
+		if update.Assignees != nil || update.ClearAssignees {
+			changes["assignees"] = nonNil(update.Assignees)
+		}
+		updateErr := err
+		if message, ok := result.Errors[update.Number]; ok {
+			updateErr = errors.New(message)
+		}
+		ap.record(ctx, AuditEntry{Endpoint: "BatchEditIssues", Issue: update.Number, Changes: changes}, updateErr)
+	}
+	return result, err
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func (ap *auditProvider) CloseIssue(ctx context.Context, number string, reason string) (time.Time, error) {
+	updatedAt, err := ap.Provider.CloseIssue(ctx, number, reason)
+	var changes map[string]any
+	if reason != "" {
+		changes = map[string]any{"state_reason": reason}
+	}
+	ap.record(ctx, AuditEntry{Endpoint: "CloseIssue", Issue: number, Changes: changes}, err)
+	return updatedAt, err
+}
+
+func (ap *auditProvider) ReopenIssue(ctx context.Context, number string) (time.Time, error) {
+	updatedAt, err := ap.Provider.ReopenIssue(ctx, number)
+	ap.record(ctx, AuditEntry{Endpoint: "ReopenIssue", Issue: number}, err)
+	return updatedAt, err
+}
+
+func (ap *auditProvider) CreateComment(ctx context.Context, issueNumber string, body string) error {
+	err := ap.Provider.CreateComment(ctx, issueNumber, body)
+	ap.record(ctx, AuditEntry{Endpoint: "CreateComment", Issue: issueNumber, Changes: map[string]any{"body": bodyChange(body)}}, err)
+	return err
+}
+
+func (ap *auditProvider) SyncRelationships(ctx context.Context, issueNumber string, local issue.Issue) (bool, error) {
+	changed, err := ap.Provider.SyncRelationships(ctx, issueNumber, local)
+	changes := map[string]any{"blocked_by": refStrings(local.BlockedBy), "blocks": refStrings(local.Blocks)}
+	if local.Parent != nil {
+		changes["parent"] = local.Parent.String()
+	}
+	ap.record(ctx, AuditEntry{Endpoint: "SyncRelationships", Issue: issueNumber, Changes: changes}, err)
+	return changed, err
+}
+
+func (ap *auditProvider) SetIssueType(ctx context.Context, issueNumber string, issueTypeID string) (time.Time, error) {
+	updatedAt, err := ap.Provider.SetIssueType(ctx, issueNumber, issueTypeID)
+	ap.record(ctx, AuditEntry{Endpoint: "SetIssueType", Issue: issueNumber, Changes: map[string]any{"type_id": issueTypeID}}, err)
+	return updatedAt, err
+}
+
+func (ap *auditProvider) SyncProjects(ctx context.Context, issueNumber string, localProjects []string, knownProjects map[string]string) error {
+	err := ap.Provider.SyncProjects(ctx, issueNumber, localProjects, knownProjects)
+	ap.record(ctx, AuditEntry{Endpoint: "SyncProjects", Issue: issueNumber, Changes: map[string]any{"projects": nonNil(localProjects)}}, err)
+	return err
+}
+
+func (ap *auditProvider) CreateLabel(ctx context.Context, name, color string) error {
+	err := ap.Provider.CreateLabel(ctx, name, color)
+	ap.record(ctx, AuditEntry{Endpoint: "CreateLabel", Target: name, Changes: map[string]any{"color": color}}, err)
+	return err
+}
+
+func (ap *auditProvider) RenameLabel(ctx context.Context, oldName, newName string) error {
+	err := ap.Provider.RenameLabel(ctx, oldName, newName)
+	ap.record(ctx, AuditEntry{Endpoint: "RenameLabel", Target: oldName, Changes: map[string]any{"name": newName}}, err)
+	return err
+}
+
+func (ap *auditProvider) DeleteLabel(ctx context.Context, name string) error {
+	err := ap.Provider.DeleteLabel(ctx, name)
+	ap.record(ctx, AuditEntry{Endpoint: "DeleteLabel", Target: name}, err)
+	return err
+}
+
+func (ap *auditProvider) CreateMilestone(ctx context.Context, m ghcli.Milestone) error {
+	err := ap.Provider.CreateMilestone(ctx, m)
+	ap.record(ctx, AuditEntry{Endpoint: "CreateMilestone", Target: m.Title, Changes: milestoneChanges(m)}, err)
+	return err
+}
+
+func (ap *auditProvider) UpdateMilestone(ctx context.Context, number int, m ghcli.Milestone) error {
+	err := ap.Provider.UpdateMilestone(ctx, number, m)
+	ap.record(ctx, AuditEntry{Endpoint: "UpdateMilestone", Target: m.Title, Changes: milestoneChanges(m)}, err)
+	return err
+}
+
+func milestoneChanges(m ghcli.Milestone) map[string]any {
+	changes := map[string]any{"description": bodyChange(m.Description)}
+	if m.DueOn != nil {
+		changes["due_on"] = *m.DueOn
+	}
+	if m.State != "" {
+		changes["state"] = m.State
+	}
+	return changes
+}
+
+func (ap *auditProvider) MarkNotificationRead(ctx context.Context, id string) error {
+	err := ap.Provider.MarkNotificationRead(ctx, id)
+	ap.record(ctx, AuditEntry{Endpoint: "MarkNotificationRead", Target: id}, err)
+	return err
+}
+
+type AuditOptions struct {
+	Issue string        // only entries for this issue
+	Since time.Duration // only entries this recent
+	Limit int           // only the last N entries
+	JSON  bool          // print the entries as JSON lines
+}
+
+// Audit prints the recorded remote mutations, oldest first.
+func (a *App) Audit(ctx context.Context, opts AuditOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+	entries, err := loadAuditEntries(p)
+	if err != nil {
+		return err
+	}
+	issueNumber := strings.TrimPrefix(opts.Issue, "#")
+	var selected []AuditEntry
+	for _, entry := range entries {
+		if issueNumber != "" && entry.Issue != issueNumber {
+			continue
+		}
+		if opts.Since > 0 && entry.Time.Before(a.Now().Add(-opts.Since)) {
+			continue
+		}
+		selected = append(selected, entry)
+	}
+	if opts.Limit > 0 && len(selected) > opts.Limit {
+		selected = selected[len(selected)-opts.Limit:]
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(a.Out)
+		for _, entry := range selected {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(selected) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No recorded mutations"))
+		return nil
+	}
+	for _, entry := range selected {
+		subject := entry.Target
+		if entry.Issue != "" {
+			subject = "#" + entry.Issue
+		}
+		line := fmt.Sprintf("%s  %s  %s %s", t.MutedText(entry.Time.Local().Format("2006-01-02 15:04:05")),
+			padRight(entry.Actor, 12), t.AccentText(entry.Endpoint), subject)
+		if changes := formatAuditChanges(entry.Changes); changes != "" {
+			line += "  " + t.MutedText(changes)
+		}
+		if entry.Error != "" {
+			line += "  " + t.ErrorText("failed: "+entry.Error)
+		}
+		fmt.Fprintln(a.Out, line)
+	}
+	return nil
+}
+
+func loadAuditEntries(p paths.Paths) ([]AuditEntry, error) {
+	f, err := os.Open(p.AuditPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", relPath(p.Root, p.AuditPath), line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// formatAuditChanges prints changes as field=value pairs in field order.
+func formatAuditChanges(changes map[string]any) string {
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := changes[field]
+		if list, ok := value.([]any); ok {
+			items := make([]string, 0, len(list))
+			for _, item := range list {
+				items = append(items, fmt.Sprint(item))
+			}
+			value = "[" + strings.Join(items, ", ") + "]"
+		}
+		parts = append(parts, fmt.Sprintf("%s=%v", field, value))
+	}
+	return strings.Join(parts, " ")
+}
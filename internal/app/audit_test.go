@@ -0,0 +1,105 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+// auditStubProvider accepts closing issues and fails creating labels.
+type auditStubProvider struct {
+	ghcli.Provider
+}
+
+func (auditStubProvider) CheckAuth(ctx context.Context) (ghcli.AuthStatus, error) {
+	return ghcli.AuthStatus{User: "octocat"}, nil
+}
+
+func (auditStubProvider) CloseIssue(ctx context.Context, number string, reason string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (auditStubProvider) CreateLabel(ctx context.Context, name, color string) error {
+	return errors.New("label exists")
+}
+
+func TestAudit(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	application.Now = func() time.Time { return now }
+	ctx := context.Background()
+
+	client := application.newAuditProvider(p, "owner/repo", auditStubProvider{})
+	if _, err := client.CheckAuth(ctx); err != nil {
+		t.Fatalf("auth: %v", err)
+	}
+	if _, err := client.CloseIssue(ctx, "7", "not_planned"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if err := client.CreateLabel(ctx, "bug", "d73a4a"); err == nil {
+		t.Fatalf("expected label creation to fail")
+	}
+
+	entries, err := loadAuditEntries(p)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	if e := entries[0]; e.Endpoint != "CloseIssue" || e.Issue != "7" || e.Actor != "octocat" ||
+		e.Repository != "owner/repo" || e.Changes["state_reason"] != "not_planned" || e.Error != "" {
+		t.Fatalf("unexpected close entry: %+v", e)
+	}
+	if e := entries[1]; e.Endpoint != "CreateLabel" || e.Target != "bug" || e.Error != "label exists" {
+		t.Fatalf("unexpected label entry: %+v", e)
+	}
+
+	if err := application.Audit(ctx, AuditOptions{}); err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	text := out.String()
+	if !strings.Contains(text, "CloseIssue #7  state_reason=not_planned") {
+		t.Fatalf("expected the close in the log, got:\n%s", text)
+	}
+	if !strings.Contains(text, "CreateLabel bug  color=d73a4a  failed: label exists") {
+		t.Fatalf("expected the failed label creation in the log, got:\n%s", text)
+	}
+
+	out.Reset()
+	if err := application.Audit(ctx, AuditOptions{Issue: "#7"}); err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	if !strings.Contains(out.String(), "CloseIssue") || strings.Contains(out.String(), "CreateLabel") {
+		t.Fatalf("expected only the issue's entries, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := application.Audit(ctx, AuditOptions{Since: 30 * time.Minute}); err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	if strings.Contains(out.String(), "CloseIssue") || !strings.Contains(out.String(), "CreateLabel") {
+		t.Fatalf("expected only recent entries, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+)
+
+// AuthStatus checks that the forge CLI is installed, logged in, and has
+// the scopes sync needs. It works before init, assuming GitHub.
+func (a *App) AuthStatus(ctx context.Context) error {
+	t := a.Theme
+	p := a.issuePaths()
+	var client ghcli.Provider = ghcli.NewClient(a.Runner, "")
+	cfg, cfgErr := loadConfig(p.ConfigPath)
+	if cfgErr == nil {
+		var err error
+		if client, err = a.newProvider(cfg); err != nil {
+			return err
+		}
+	}
+
+	status, err := client.CheckAuth(ctx)
+	if err != nil {
+		return authError(status.CLI, err)
+	}
+	user := status.User
+	if user == "" {
+		user = "unknown user"
+	}
+	fmt.Fprintf(a.Out, "%s %s %s\n", t.SuccessText("Logged in"), t.MutedText("to "+status.CLI+" as"), t.AccentText(user))
+	if status.Scopes == nil {
+		fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Token scopes:"), t.MutedText("not reported by this token type"))
+	} else {
+		fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Token scopes:"), strings.Join(status.Scopes, ", "))
+	}
+	for _, scope := range status.Recommended {
+		fmt.Fprintf(a.Out, "%s %s scope, needed to sync projects %s\n", t.WarningText("Missing"), scope,
+			t.MutedText("(run: "+status.RefreshHint([]string{scope})+")"))
+	}
+	if len(status.Missing) > 0 {
+		return missingScopesError(status)
+	}
+
+	// Re-probe the optional features for the repository and cache the
+	// result, so a newly granted scope takes effect right away.
+	if cfgErr == nil {
+		caps := a.capabilities(ctx, p, &cfg, client, true)
+		for _, feature := range []struct {
+			name      string
+			supported bool
+		}{
+			{"Issue types", caps.IssueTypes},
+			{"Projects", caps.Projects},
+			{"Sub-issues", caps.SubIssues},
+		} {
+			state := t.SuccessText("available")
+			if !feature.supported {
+				state = t.MutedText("unavailable")
+			}
+			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText(feature.name+":"), state)
+		}
+	}
+	return nil
+}
+
+// preflight checks the credentials before a sync, so a missing login or
+// scope fails with a hint up front instead of with an API error midway.
+// Other failures are left to the sync itself to report.
+func (a *App) preflight(ctx context.Context, client ghcli.Provider) error {
+	status, err := client.CheckAuth(ctx)
+	if err != nil {
+		if errors.Is(err, ghcli.ErrCLINotFound) || errors.Is(err, ghcli.ErrNotLoggedIn) {
+			return authError(status.CLI, err)
+		}
+		return nil
+	}
+	if len(status.Missing) > 0 {
+		return missingScopesError(status)
+	}
+	return nil
+}
+
+// authError adds the remediation for a failed credentials check.
+func authError(cli string, err error) error {
+	switch {
+	case errors.Is(err, ghcli.ErrCLINotFound):
+		url := "https://cli.github.com"
+		if cli == "glab" {
+			url = "https://gitlab.com/gitlab-org/cli"
+		}
+		return fmt.Errorf("%s is not installed; install it from %s", cli, url)
+	case errors.Is(err, ghcli.ErrNotLoggedIn):
+		return fmt.Errorf("%s is not logged in; run: %s auth login", cli, cli)
+	}
+	return err
+}
+
+func missingScopesError(status ghcli.AuthStatus) error {
+	return fmt.Errorf("token is missing the %s scope; run: %s",
+		strings.Join(status.Missing, ", "), status.RefreshHint(status.Missing))
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// AutolinkCache stores the repository's autolink references synced on
+// pull.
+type AutolinkCache struct {
+	Autolinks []AutolinkEntry `json:"autolinks"`
+	SyncedAt  time.Time       `json:"synced_at"`
+}
+
+// AutolinkEntry is a key prefix such as "JIRA-" and the URL its references
+// point to, with <num> standing for the rest of the reference.
+type AutolinkEntry struct {
+	KeyPrefix      string `json:"key_prefix"`
+	URLTemplate    string `json:"url_template"`
+	IsAlphanumeric bool   `json:"is_alphanumeric,omitempty"`
+}
+
+func loadAutolinkCache(p paths.Paths) (AutolinkCache, error) {
+	var cache AutolinkCache
+	data, err := os.ReadFile(p.AutolinksPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return cache, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, err
+	}
+	return cache, nil
+}
+
+func saveAutolinkCache(p paths.Paths, cache AutolinkCache) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(p.AutolinksPath, data, 0o644)
+}
+
+// autolinkPattern matches the references of autolinks, longest prefix
+// first so JIRA-WEB- wins over JIRA-. The first group is the character
+// before the reference, which must not make it part of a word, path or
+// existing link.
+func autolinkPattern(autolinks []AutolinkEntry) *regexp.Regexp {
+	if len(autolinks) == 0 {
+		return nil
+	}
+	sorted := append([]AutolinkEntry(nil), autolinks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].KeyPrefix) > len(sorted[j].KeyPrefix) })
+	alternatives := make([]string, 0, len(sorted))
+	for _, autolink := range sorted {
+		suffix := `[0-9]+`
+		if autolink.IsAlphanumeric {
+			suffix = `[A-Za-z0-9]+`
+		}
+		alternatives = append(alternatives, regexp.QuoteMeta(autolink.KeyPrefix)+suffix)
+	}
+	return regexp.MustCompile(`(?i)(^|[^\w/\[#-])(` + strings.Join(alternatives, "|") + `)\b`)
+}
+
+// linkAutolinks turns the autolink references in body into Markdown links
+// for display. Code and existing links are left alone; the issue file is
+// never changed, so the references stay as written through sync.
+func linkAutolinks(body string, autolinks []AutolinkEntry) string {
+	pattern := autolinkPattern(autolinks)
+	if pattern == nil {
+		return body
+	}
+	return mapProse(body, func(text string) string {
+		return pattern.ReplaceAllStringFunc(text, func(match string) string {
+			m := pattern.FindStringSubmatch(match)
+			ref := m[2]
+			url := autolinkURL(autolinks, ref)
+			if url == "" {
+				return match
+			}
+			return m[1] + "[" + ref + "](" + url + ")"
+		})
+	})
+}
+
+// autolinkURL returns the URL of ref, using the longest matching prefix.
+func autolinkURL(autolinks []AutolinkEntry, ref string) string {
+	var best AutolinkEntry
+	for _, autolink := range autolinks {
+		if len(ref) > len(autolink.KeyPrefix) && strings.EqualFold(ref[:len(autolink.KeyPrefix)], autolink.KeyPrefix) &&
+			len(autolink.KeyPrefix) > len(best.KeyPrefix) {
+			best = autolink
+		}
+	}
+	if best.KeyPrefix == "" {
+		return ""
+	}
+	return strings.ReplaceAll(best.URLTemplate, "<num>", ref[len(best.KeyPrefix):])
+}
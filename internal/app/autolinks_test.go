@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+func TestLinkAutolinks(t *testing.T) {
+	autolinks := []AutolinkEntry{
+		{KeyPrefix: "JIRA-", URLTemplate: "https://jira.example.com/browse/JIRA-<num>"},
+		{KeyPrefix: "JIRA-WEB-", URLTemplate: "https://web.example.com/<num>", IsAlphanumeric: true},
+	}
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"Tracked in JIRA-123.", "Tracked in [JIRA-123](https://jira.example.com/browse/JIRA-123)."},
+		{"See jira-7 and JIRA-WEB-a1b", "See [jira-7](https://jira.example.com/browse/JIRA-7) and [JIRA-WEB-a1b](https://web.example.com/a1b)"},
+		// Not numeric, part of a word or path, code, or already a link
+		{"JIRA-abc XJIRA-1 /browse/JIRA-1 `JIRA-1` [JIRA-1](u)", "JIRA-abc XJIRA-1 /browse/JIRA-1 `JIRA-1` [JIRA-1](u)"},
+		{"```\nJIRA-1\n```", "```\nJIRA-1\n```"},
+	}
+	for _, tt := range tests {
+		if got := linkAutolinks(tt.body, autolinks); got != tt.want {
+			t.Errorf("linkAutolinks(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+	if got := linkAutolinks("JIRA-1", nil); got != "JIRA-1" {
+		t.Errorf("expected no change without autolinks, got %q", got)
+	}
+
+	// References stay as written in the issue file
+	iss := issue.Issue{Number: "1", Title: "Keep", State: "open", Body: "Blocked on JIRA-123 and JIRA-WEB-x9"}
+	content, err := issue.Render(iss)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	parsed, err := issue.ParseNamed("1-keep.md", []byte(content))
+	if err != nil || strings.TrimSpace(parsed.Body) != iss.Body {
+		t.Fatalf("expected the body unchanged, got %q %v", parsed.Body, err)
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// bodyRefPattern matches same-repository issue references like #42 or #T1a2b.
+var bodyRefPattern = regexp.MustCompile(`(?:^|[^\w/&])#(\d+|T[a-zA-Z0-9]+)\b`)
+
+// backlinks returns the references to number: the synced
+// info.referenced_by list plus local issues whose body mentions it, which
+// also covers issues that have not been pushed yet.
+func backlinks(target issue.Issue, issues []IssueFile) []string {
+	number := target.Number.String()
+	refs := append([]string(nil), target.ReferencedBy...)
+	seen := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		seen[ref] = struct{}{}
+	}
+	for _, item := range issues {
+		source := item.Issue.Number.String()
+		if source == number {
+			continue
+		}
+		if _, ok := seen[source]; ok {
+			continue
+		}
+		for _, m := range bodyRefPattern.FindAllStringSubmatch(issue.StripLocalNotes(item.Issue.Body), -1) {
+			if m[1] == number {
+				seen[source] = struct{}{}
+				refs = append(refs, source)
+				break
+			}
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		ni, errI := strconv.Atoi(refs[i])
+		nj, errJ := strconv.Atoi(refs[j])
+		if errI == nil && errJ == nil {
+			return ni < nj
+		}
+		// Same-repository numbers first, then local IDs and other repositories
+		if (errI == nil) != (errJ == nil) {
+			return errI == nil
+		}
+		return refs[i] < refs[j]
+	})
+	return refs
+}
+
+// wikiLink is a [[#123]] link in an issue body resolved to the file it
+// points at. Path is empty when there is no local file for the issue.
+type wikiLink struct {
+	Ref  string
+	Path string
+}
+
+// wikiLinkPaths resolves the wiki links in body against the local issues so
+// editors can jump between files.
+func wikiLinkPaths(body string, issues []IssueFile) []wikiLink {
+	refs := issue.WikiLinks(issue.StripLocalNotes(body))
+	if len(refs) == 0 {
+		return nil
+	}
+	files := make(map[string]string, len(issues))
+	for _, item := range issues {
+		files[item.Issue.Number.String()] = item.Path
+	}
+	links := make([]wikiLink, 0, len(refs))
+	for _, ref := range refs {
+		links = append(links, wikiLink{Ref: ref, Path: files[ref]})
+	}
+	return links
+}
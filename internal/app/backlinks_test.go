@@ -0,0 +1,41 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+func TestBacklinks(t *testing.T) {
+	target := issue.Issue{Number: "5", ReferencedBy: []string{"other/repo#3", "12"}}
+	issues := []IssueFile{
+		{Issue: issue.Issue{Number: "12", Body: "See #5"}},
+		{Issue: issue.Issue{Number: "7", Body: "Related to #5."}},
+		{Issue: issue.Issue{Number: "Tabc", Body: "Follow-up for #5"}},
+		{Issue: issue.Issue{Number: "8", Body: "Not #50 and not https://x/#5"}},
+		{Issue: issue.Issue{Number: "9", Body: "<!-- local-notes\n#5\n-->"}},
+	}
+	got := strings.Join(backlinks(target, issues), ",")
+	if got != "7,12,Tabc,other/repo#3" {
+		t.Fatalf("unexpected backlinks: %s", got)
+	}
+}
+
+func TestReferencedByRoundTrip(t *testing.T) {
+	iss := issue.Issue{Title: "A", State: "open", ReferencedBy: []string{"4", "acme/other#9"}}
+	rendered, err := issue.Render(iss)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(rendered, "referenced_by:") {
+		t.Fatalf("expected referenced_by in info:\n%s", rendered)
+	}
+	parsed, err := issue.Parse([]byte(rendered))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if strings.Join(parsed.ReferencedBy, ",") != "4,acme/other#9" {
+		t.Fatalf("unexpected referenced_by: %v", parsed.ReferencedBy)
+	}
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/search"
+)
+
+// DefaultBenchIssues is the size of the synthetic mirror bench generates.
+const DefaultBenchIssues = 10000
+
+type BenchOptions struct {
+	Issues int    // Number of synthetic issues; DefaultBenchIssues if zero
+	Dir    string // Generate the mirror here and keep it; a temporary directory if empty
+}
+
+// Bench generates a synthetic mirror and times the local phases of the
+// sync path against a performance budget.
+func (a *App) Bench(ctx context.Context, opts BenchOptions) error {
+	t := a.Theme
+	n := opts.Issues
+	if n <= 0 {
+		n = DefaultBenchIssues
+	}
+	root := opts.Dir
+	if root != "" && !filepath.IsAbs(root) {
+		root = filepath.Join(a.Root, root)
+	}
+	if root == "" {
+		tmp, err := os.MkdirTemp("", "gh-issue-sync-bench-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		root = tmp
+	}
+	p := paths.New(root)
+
+	start := time.Now()
+	if err := generateMirror(p, n); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%s %d issues in %s\n", t.MutedText("Generated"), n, time.Since(start).Round(time.Millisecond))
+
+	var loaded []IssueFile
+	query := search.Parse("crash label:bug")
+	// Budgets are per DefaultBenchIssues issues and generous, so that only
+	// real regressions trip them.
+	phases := []struct {
+		name   string
+		budget time.Duration
+		run    func() error
+	}{
+		{"load", 10 * time.Second, func() error {
+			result := loadLocalIssuesWithErrors(p)
+			if len(result.Errors) > 0 {
+				return result.Errors[0]
+			}
+			loaded = result.Issues
+			return saveIndexes()
+		}},
+		{"load indexed", 3 * time.Second, func() error {
+			_, err := loadLocalIssues(p)
+			return err
+		}},
+		{"headers", 3 * time.Second, func() error {
+			var err error
+			walkLocalIssues(p, true, func(IssueFile) {}, func(parseErr ParseError) { err = parseErr })
+			return err
+		}},
+		{"compare", 3 * time.Second, func() error {
+			for _, item := range loaded {
+				original, _ := readOriginalIssue(p, item.Issue.Number.String())
+				issue.EqualIgnoringSyncedAt(item.Issue, original)
+			}
+			return nil
+		}},
+		{"search", 2 * time.Second, func() error {
+			for _, item := range loaded {
+				query.Match(searchDataFor(p.Format, item))
+			}
+			return nil
+		}},
+	}
+
+	var over []string
+	for _, phase := range phases {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		start := time.Now()
+		if err := phase.run(); err != nil {
+			return fmt.Errorf("%s: %w", phase.name, err)
+		}
+		elapsed := time.Since(start)
+		// Small mirrors are dominated by fixed costs
+		budget := phase.budget * time.Duration(n) / DefaultBenchIssues
+		if budget < 250*time.Millisecond {
+			budget = 250 * time.Millisecond
+		}
+		status := t.SuccessText("ok")
+		if elapsed > budget {
+			status = t.ErrorText("over budget")
+			over = append(over, phase.name)
+		}
+		fmt.Fprintf(a.Out, "%-14s %10s  %s %s  %s\n", phase.name, elapsed.Round(time.Microsecond),
+			t.MutedText("budget"), budget, status)
+	}
+	if len(over) > 0 {
+		return fmt.Errorf("over budget: %s", strings.Join(over, ", "))
+	}
+	return nil
+}
+
+var benchWords = strings.Fields(`the a crash when login fails on startup after update with large
+	files panic timeout network request response error user session cache sync issue label milestone
+	project config parser render diff window linux macos windows build test release fix regression`)
+
+// generateMirror writes a synthetic mirror of n issues with originals. The
+// content is deterministic so runs are comparable.
+func generateMirror(p paths.Paths, n int) error {
+	if err := p.EnsureLayout(); err != nil {
+		return err
+	}
+	if err := config.Save(p.ConfigPath, config.Default("bench", "bench")); err != nil {
+		return err
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	words := func(count int) string {
+		parts := make([]string, count)
+		for i := range parts {
+			parts[i] = benchWords[rng.IntN(len(benchWords))]
+		}
+		return strings.Join(parts, " ")
+	}
+	labels := []string{"bug", "enhancement", "docs", "ui", "backend", "p1", "p2"}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		var body strings.Builder
+		for range 1 + rng.IntN(6) {
+			body.WriteString(words(20 + rng.IntN(60)))
+			body.WriteString("\n\n")
+		}
+		createdAt := created.Add(time.Duration(i) * time.Hour)
+		item := issue.Issue{
+			Number:    issue.IssueNumber(fmt.Sprint(i)),
+			Title:     words(3 + rng.IntN(6)),
+			Labels:    []string{labels[rng.IntN(len(labels))]},
+			State:     "open",
+			Author:    fmt.Sprintf("user%d", rng.IntN(50)),
+			CreatedAt: &createdAt,
+			UpdatedAt: &createdAt,
+			Body:      body.String(),
+		}
+		if rng.IntN(10) == 0 {
+			parent := issue.IssueRef(fmt.Sprint(1 + rng.IntN(i)))
+			item.Parent = &parent
+		}
+		dir := p.OpenDir
+		if rng.IntN(3) == 0 {
+			item.State = "closed"
+			dir = p.ClosedDir
+		}
+		// Written directly, so the first load starts without an index
+		if err := issue.WriteFile(filepath.Join(p.OriginalsDir, item.Number.String()+".md"), item); err != nil {
+			return err
+		}
+		// Every tenth issue has a local edit
+		if i%10 == 0 {
+			item.Body += "Edited locally.\n"
+		}
+		if err := issue.WriteFile(issue.PathFor(dir, item.Number, item.Title), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestBench(t *testing.T) {
+	root := t.TempDir()
+	var out bytes.Buffer
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	if err := application.Bench(context.Background(), BenchOptions{Issues: 50, Dir: "mirror"}); err != nil {
+		t.Fatalf("bench: %v\n%s", err, out.String())
+	}
+	for _, phase := range []string{"Generated", "load indexed", "headers", "compare", "search"} {
+		if !strings.Contains(out.String(), phase) {
+			t.Fatalf("missing %q in output:\n%s", phase, out.String())
+		}
+	}
+	local, err := loadLocalIssues(paths.New(root + "/mirror"))
+	if err != nil || len(local) != 50 {
+		t.Fatalf("expected the generated mirror to be kept, got %d issues (%v)", len(local), err)
+	}
+}
+
+func BenchmarkLoadLocalIssues(b *testing.B) {
+	p := paths.New(b.TempDir())
+	if err := generateMirror(p, 1000); err != nil {
+		b.Fatalf("generate: %v", err)
+	}
+	b.ResetTimer()
+	for b.Loop() {
+		if _, err := loadLocalIssues(p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWalkHeaders(b *testing.B) {
+	p := paths.New(b.TempDir())
+	if err := generateMirror(p, 1000); err != nil {
+		b.Fatalf("generate: %v", err)
+	}
+	b.ResetTimer()
+	for b.Loop() {
+		walkLocalIssues(p, true, func(IssueFile) {}, func(err ParseError) { b.Fatal(err) })
+	}
+}
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// blameFields are the front matter fields blame reports, in display order.
+var blameFields = []string{"title", "state", "labels", "assignees", "milestone", "type", "projects", "parent", "blocked_by", "blocks", "body"}
+
+// blameUnknown stands for a value the audit log only describes, such as a
+// pushed body. The next snapshot supplies the actual value.
+const blameUnknown = "\x00"
+
+// blameOrigin is where the current value of a field came from.
+type blameOrigin struct {
+	At     time.Time
+	Source string // pull, push, local or snapshot
+	Actor  string
+}
+
+// blameEvent is a recorded version of some fields: a snapshot kept by pull
+// or a push in the audit log.
+type blameEvent struct {
+	At     time.Time
+	Source string
+	Actor  string
+	Values map[string]string
+}
+
+// blameValues renders the fields of iss as comparable strings.
+func blameValues(iss issue.Issue) map[string]string {
+	values := map[string]string{
+		"title":      iss.Title,
+		"state":      iss.State,
+		"labels":     joinSorted(iss.Labels),
+		"assignees":  joinSorted(iss.Assignees),
+		"milestone":  iss.Milestone,
+		"type":       iss.IssueType,
+		"projects":   joinSorted(iss.Projects),
+		"blocked_by": joinSorted(refStrings(iss.BlockedBy)),
+		"blocks":     joinSorted(refStrings(iss.Blocks)),
+		"body":       strings.TrimSpace(issue.PublicBody(iss.Body)),
+	}
+	if iss.Parent != nil {
+		values["parent"] = "#" + iss.Parent.String()
+	}
+	return values
+}
+
+func joinSorted(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+// auditBlameValues maps the changes of a successful audit entry to blame
+// fields.
+func auditBlameValues(entry AuditEntry) map[string]string {
+	values := map[string]string{}
+	switch entry.Endpoint {
+	case "CloseIssue":
+		values["state"] = "closed"
+	case "ReopenIssue":
+		values["state"] = "open"
+	case "SetIssueType":
+		values["type"] = blameUnknown
+	}
+	for field, value := range entry.Changes {
+		switch field {
+		case "title", "milestone", "type":
+			if s, ok := value.(string); ok {
+				values[field] = s
+			}
+		case "parent":
+			if s, ok := value.(string); ok {
+				values[field] = "#" + strings.TrimPrefix(s, "#")
+			}
+		case "labels", "assignees", "projects", "blocked_by", "blocks":
+			var items []string
+			if list, ok := value.([]any); ok {
+				for _, item := range list {
+					items = append(items, fmt.Sprint(item))
+				}
+			}
+			values[field] = joinSorted(items)
+		case "body":
+			values[field] = blameUnknown
+		}
+	}
+	if entry.Endpoint == "SyncRelationships" {
+		if _, ok := entry.Changes["parent"]; !ok {
+			values["parent"] = ""
+		}
+	}
+	return values
+}
+
+// blameIssue replays the snapshots and pushes of an issue and returns where
+// the current value of each field came from. A change seen by pull that a
+// recorded push already made is attributed to the push.
+func blameIssue(snapshots []blameEvent, pushes []blameEvent) map[string]blameOrigin {
+	events := append(append([]blameEvent(nil), snapshots...), pushes...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	last := map[string]string{}
+	origins := map[string]blameOrigin{}
+	seen := false
+	for _, event := range events {
+		if event.Source != "pull" {
+			for field, value := range event.Values {
+				if current, ok := last[field]; !ok || current != value || value == blameUnknown {
+					last[field] = value
+					origins[field] = blameOrigin{At: event.At, Source: event.Source, Actor: event.Actor}
+				}
+			}
+			continue
+		}
+		for _, field := range blameFields {
+			value := event.Values[field]
+			current, known := last[field]
+			switch {
+			case !seen && !known:
+				// Set at or before the oldest version we have
+				origins[field] = blameOrigin{At: event.At, Source: "snapshot"}
+			case current == blameUnknown:
+				// The pull shows what the push wrote
+			case !known || current != value:
+				origins[field] = blameOrigin{At: event.At, Source: "pull"}
+			}
+			last[field] = value
+		}
+		seen = true
+	}
+	return origins
+}
+
+// Blame shows for each front matter field of an issue when it last
+// changed and whether that was a pull, a push of a local edit, or a local
+// edit not pushed yet. It draws on the snapshots pull keeps, the audit log
+// and the last synced original.
+func (a *App) Blame(ctx context.Context, ref string) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	file, err := findIssueByRef(a.Root, p, ref)
+	if err != nil {
+		return err
+	}
+	number := file.Issue.Number.String()
+	current := blameValues(file.Issue)
+
+	var snapshotEvents, pushEvents []blameEvent
+	snapshots, err := listSnapshots(p, number)
+	if err != nil {
+		return err
+	}
+	for _, snap := range snapshots {
+		parsed, err := issue.ParseFile(snap.Path)
+		if err != nil {
+			return err
+		}
+		snapshotEvents = append(snapshotEvents, blameEvent{At: snap.At, Source: "pull", Values: blameValues(parsed)})
+	}
+	entries, err := loadAuditEntries(p)
+	if err != nil {
+		return err
+	}
+	repository := repoSlug(cfg)
+	for _, entry := range entries {
+		if entry.Issue != number || entry.Error != "" || (entry.Repository != "" && entry.Repository != repository) {
+			continue
+		}
+		pushEvents = append(pushEvents, blameEvent{At: entry.Time, Source: "push", Actor: entry.Actor, Values: auditBlameValues(entry)})
+	}
+	origins := blameIssue(snapshotEvents, pushEvents)
+
+	// Unpushed local edits are the newest change of all
+	original, hasOriginal := readOriginalIssue(p, number)
+	if file.Issue.Number.IsLocal() {
+		original, hasOriginal = issue.Issue{}, false
+	}
+	if hasOriginal || file.Issue.Number.IsLocal() {
+		synced := blameValues(original)
+		var editedAt time.Time
+		if info, err := os.Stat(file.Path); err == nil {
+			editedAt = info.ModTime()
+		}
+		for _, field := range blameFields {
+			if current[field] != synced[field] {
+				origins[field] = blameOrigin{At: editedAt, Source: "local", Actor: file.Issue.LastLocalEditBy}
+			}
+		}
+	}
+
+	fmt.Fprintln(a.Out, t.FormatIssueHeader(file.State, number, file.Issue.Title))
+	for _, field := range blameFields {
+		value := current[field]
+		if field == "body" {
+			value = fmt.Sprintf("(%d chars)", len(value))
+		}
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(a.Out, "  %s %s %s\n", t.MutedText(padRight(field+":", 12)), padRight(truncateAnsi(value, 32, ""), 34),
+			a.formatBlameOrigin(origins[field], hasOriginal))
+	}
+	return nil
+}
+
+// formatBlameOrigin describes where a field's value came from.
+func (a *App) formatBlameOrigin(origin blameOrigin, hasOriginal bool) string {
+	t := a.Theme
+	when := ""
+	if !origin.At.IsZero() {
+		when = origin.At.Local().Format("2006-01-02 15:04") + " "
+	}
+	by := ""
+	if origin.Actor != "" {
+		by = " by " + origin.Actor
+	}
+	switch origin.Source {
+	case "local":
+		return when + t.WarningText("local edit"+by+", not pushed")
+	case "push":
+		return when + t.SuccessText("pushed"+by)
+	case "pull":
+		return when + "pulled"
+	case "snapshot":
+		return t.MutedText("on or before " + strings.TrimSpace(when) + " (oldest snapshot)")
+	}
+	if !hasOriginal {
+		return t.MutedText("not synced yet")
+	}
+	return t.MutedText("no recorded change (enable sync.snapshots)")
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestBlame(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC)
+		return &at
+	}
+	// Pulled on the 1st; on the 3rd someone else set the milestone, on the
+	// 5th alice pushed the labels, which the pull on the 6th saw
+	for _, remote := range []issue.Issue{
+		{Number: "4", Title: "Crash", State: "open", Labels: []string{"bug"}, Body: "Boom", UpdatedAt: day(1)},
+		{Number: "4", Title: "Crash", State: "open", Labels: []string{"bug"}, Milestone: "v1", Body: "Boom", UpdatedAt: day(3)},
+		{Number: "4", Title: "Crash", State: "open", Labels: []string{"bug", "p1"}, Milestone: "v1", Body: "Boom", UpdatedAt: day(6)},
+	} {
+		if err := saveSnapshot(p, remote, 10); err != nil {
+			t.Fatalf("snapshot: %v", err)
+		}
+	}
+	if err := appendAuditEntry(p.AuditPath, AuditEntry{Time: *day(5), Actor: "alice", Repository: "owner/repo",
+		Endpoint: "BatchEditIssues", Issue: "4", Changes: map[string]any{"labels": []string{"bug", "p1"}}}); err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	// A failed push changes nothing
+	if err := appendAuditEntry(p.AuditPath, AuditEntry{Time: *day(5), Actor: "alice", Repository: "owner/repo",
+		Endpoint: "BatchEditIssues", Issue: "4", Changes: map[string]any{"milestone": "v2"}, Error: "boom"}); err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+
+	original := issue.Issue{Number: "4", Title: "Crash", State: "open", Labels: []string{"bug", "p1"}, Milestone: "v1", Body: "Boom"}
+	if err := issue.WriteFile(filepath.Join(p.OriginalsDir, "4.md"), original); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+	local := original
+	local.Title = "Crash on start"
+	local.LastLocalEditBy = "Bob"
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, local.Number, local.Title), local); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	if err := application.Blame(context.Background(), "4"); err != nil {
+		t.Fatalf("blame: %v", err)
+	}
+	lines := map[string]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if field, rest, ok := strings.Cut(strings.TrimSpace(line), ":"); ok {
+			lines[field] = strings.Join(strings.Fields(rest), " ")
+		}
+	}
+	checks := map[string]string{
+		"title":     "Crash on start",
+		"milestone": "v1 " + day(3).Local().Format("2006-01-02 15:04") + " pulled",
+		"labels":    "bug, p1 " + day(5).Local().Format("2006-01-02 15:04") + " pushed by alice",
+		"body":      "(4 chars) on or before " + day(1).Local().Format("2006-01-02 15:04") + " (oldest snapshot)",
+	}
+	for field, want := range checks {
+		if !strings.HasPrefix(lines[field], want) {
+			t.Errorf("%s: got %q, want prefix %q", field, lines[field], want)
+		}
+	}
+	if !strings.HasSuffix(lines["title"], "local edit by Bob, not pushed") {
+		t.Errorf("expected the title as an unpushed local edit, got %q", lines["title"])
+	}
+}
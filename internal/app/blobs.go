@@ -0,0 +1,85 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// Hashed originals replace their body with a reference to a blob named
+// after the SHA-256 of the body, so identical bodies are stored once and
+// the original itself stays small.
+const (
+	blobRefPrefix = "<!-- gh-issue-sync blob sha256:"
+	blobRefSuffix = " -->"
+)
+
+// originalsHashed reports whether p keeps original bodies as blobs.
+func originalsHashed(p paths.Paths) bool {
+	return p.HashedOriginals
+}
+
+// blobName is the store name of the blob with hash sum.
+func blobName(sum string) string {
+	return path.Join(paths.SyncDirName, paths.BlobsDirName, sum)
+}
+
+// blobRef returns the hash referenced by a hashed original's body.
+func blobRef(body string) (string, bool) {
+	body = strings.TrimSpace(body)
+	sum, ok := strings.CutPrefix(body, blobRefPrefix)
+	if !ok {
+		return "", false
+	}
+	sum, ok = strings.CutSuffix(sum, blobRefSuffix)
+	if !ok || len(sum) != sha256.Size*2 {
+		return "", false
+	}
+	return sum, true
+}
+
+// blobSum is the hex SHA-256 a blob is named after.
+func blobSum(body string) string {
+	digest := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(digest[:])
+}
+
+// writeBlob stores body as a blob unless it exists and returns the
+// reference that replaces the body.
+func writeBlob(p paths.Paths, body string) (string, error) {
+	s, err := storeFor(p)
+	if err != nil {
+		return "", err
+	}
+	sum := blobSum(body)
+	if _, err := s.ReadFile(blobName(sum)); err != nil {
+		if err := s.WriteFile(blobName(sum), []byte(body)); err != nil {
+			return "", err
+		}
+	}
+	return blobRefPrefix + sum + blobRefSuffix, nil
+}
+
+// withBlobBody replaces the blob reference of a hashed original with the
+// body it references. Full originals are returned unchanged.
+func withBlobBody(p paths.Paths, original issue.Issue) (issue.Issue, error) {
+	sum, ok := blobRef(original.Body)
+	if !ok {
+		return original, nil
+	}
+	s, err := storeFor(p)
+	if err != nil {
+		return original, err
+	}
+	data, err := s.ReadFile(blobName(sum))
+	if err != nil {
+		return original, fmt.Errorf("original of #%s: missing blob %s: %w", original.Number, sum, err)
+	}
+	original.Body = string(data)
+	return original, nil
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestHashedOriginals(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Storage.Originals = config.OriginalsHashed
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, &out)
+	application.Theme = theme.Plain()
+	t.Cleanup(func() { application.Flush(context.Background()) })
+	p = application.issuePaths()
+
+	body := "Steps to reproduce:\n\n1. Log in\n2. Wait an hour\n"
+	for _, number := range []issue.IssueNumber{"1", "2"} {
+		if err := writeOriginalIssue(p, issue.Issue{Number: number, Title: "Duplicate", State: "open", Body: body}); err != nil {
+			t.Fatalf("write original: %v", err)
+		}
+	}
+	blobs, err := os.ReadDir(p.BlobsDir)
+	if err != nil || len(blobs) != 1 {
+		t.Fatalf("expected one shared blob, got %v (%v)", blobs, err)
+	}
+	data, err := os.ReadFile(filepath.Join(p.OriginalsDir, "1.md"))
+	if err != nil {
+		t.Fatalf("read original: %v", err)
+	}
+	if strings.Contains(string(data), "Steps to reproduce") || !strings.Contains(string(data), blobs[0].Name()) {
+		t.Fatalf("expected the body replaced by a blob reference:\n%s", data)
+	}
+	original, ok := readOriginalIssue(p, "1")
+	if !ok || original.Body != body {
+		t.Fatalf("expected the body from the blob, got %q (%v)", original.Body, ok)
+	}
+
+	unreferenced := filepath.Join(p.BlobsDir, strings.Repeat("0", 64))
+	if err := os.WriteFile(unreferenced, []byte("stale"), 0o644); err != nil {
+		t.Fatalf("write blob: %v", err)
+	}
+	if err := application.GC(context.Background(), GCOptions{}); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if !strings.Contains(out.String(), "Migrated 0 original(s) to hashed, removed 1 unreferenced blob(s)") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if _, err := os.Stat(unreferenced); !os.IsNotExist(err) {
+		t.Fatalf("expected the unreferenced blob to be removed, got %v", err)
+	}
+
+	// Switching back to full originals migrates them and frees the blobs
+	cfg.Storage.Originals = config.OriginalsFull
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := application.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	out.Reset()
+	if err := application.GC(context.Background(), GCOptions{}); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if !strings.Contains(out.String(), "Migrated 2 original(s) to full, removed 1 unreferenced blob(s)") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	data, err = os.ReadFile(filepath.Join(p.OriginalsDir, "2.md"))
+	if err != nil || !strings.Contains(string(data), "Steps to reproduce") {
+		t.Fatalf("expected a full original, got %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,339 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type BoardOptions struct {
+	Apply bool // apply edits to the board as local changes first
+}
+
+// boardNoStatus is the column of issues without a column label.
+const boardNoStatus = "No status"
+
+const boardNotice = "<!-- Written by gh-issue-sync. Move an issue to another column or tick it to close it, then run gh-issue-sync board --apply. -->"
+
+// boardHeadingPattern matches a column heading, boardLinePattern an issue
+// line, capturing the checkbox and the issue number.
+var (
+	boardHeadingPattern = regexp.MustCompile(`^##\s+(.+?)\s*$`)
+	boardLinePattern    = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+\[?#([A-Za-z0-9]+)\]?`)
+)
+
+// boardEntry is an issue line of the board file.
+type boardEntry struct {
+	Column  string
+	Checked bool
+}
+
+// boardEdit is a change to an issue made on the board.
+type boardEdit struct {
+	Item   IssueFile
+	Column string // the new column, if it moved
+	Moved  bool
+	Close  bool
+}
+
+// boardFile returns where the board of p is written.
+func boardFile(p paths.Paths, cfg config.Config) string {
+	path := cfg.Board.Path
+	if path == "" {
+		path = "BOARD.md"
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(p.IssuesDir), path)
+}
+
+// boardColumn returns the column of iss: the rest of its first label with
+// the prefix, or "" when it has none.
+func boardColumn(iss issue.Issue, prefix string) string {
+	for _, label := range iss.Labels {
+		if len(label) > len(prefix) && strings.EqualFold(label[:len(prefix)], prefix) {
+			return label[len(prefix):]
+		}
+	}
+	return ""
+}
+
+// boardIssues returns the open issues on the board.
+func boardIssues(p paths.Paths, cfg config.Config) ([]IssueFile, error) {
+	items, err := loadLocalIssues(p)
+	if err != nil {
+		return nil, err
+	}
+	var result []IssueFile
+	for _, item := range withoutDrafts(items) {
+		if item.State != "open" {
+			continue
+		}
+		if project := cfg.Board.Project; project != "" && !slices.ContainsFunc(item.Issue.Projects, func(p string) bool { return strings.EqualFold(p, project) }) {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// boardColumns returns the columns of the board: the configured ones, then
+// those of other labels by name, with the issues without a column label
+// first.
+func boardColumns(cfg config.Config, items []IssueFile) []string {
+	columns := slices.Clone(cfg.Board.Columns)
+	var extra []string
+	noStatus := false
+	for _, item := range items {
+		column := boardColumn(item.Issue, cfg.Board.LabelPrefix)
+		if column == "" {
+			noStatus = true
+			continue
+		}
+		if !slices.ContainsFunc(columns, func(c string) bool { return strings.EqualFold(c, column) }) &&
+			!slices.ContainsFunc(extra, func(c string) bool { return strings.EqualFold(c, column) }) {
+			extra = append(extra, column)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return strings.ToLower(extra[i]) < strings.ToLower(extra[j]) })
+	columns = append(columns, extra...)
+	if noStatus {
+		columns = append([]string{boardNoStatus}, columns...)
+	}
+	return columns
+}
+
+// renderBoard renders the board of items, linking remote issues to the
+// tracker and local ones to their file.
+func renderBoard(cfg config.Config, file string, items []IssueFile) string {
+	var b strings.Builder
+	title := "Board"
+	if cfg.Board.Project != "" {
+		title = cfg.Board.Project
+	}
+	fmt.Fprintf(&b, "# %s\n\n%s\n", title, boardNotice)
+	for _, column := range boardColumns(cfg, items) {
+		fmt.Fprintf(&b, "\n## %s\n\n", column)
+		var lane []IssueFile
+		for _, item := range items {
+			current := boardColumn(item.Issue, cfg.Board.LabelPrefix)
+			if strings.EqualFold(current, column) || (current == "" && column == boardNoStatus) {
+				lane = append(lane, item)
+			}
+		}
+		sort.SliceStable(lane, func(i, j int) bool {
+			return lessIssueNumber(lane[i].Issue.Number.String(), lane[j].Issue.Number.String())
+		})
+		for _, item := range lane {
+			number := item.Issue.Number.String()
+			link := issueURL(cfg, number)
+			if item.Issue.Number.IsLocal() {
+				if rel, err := filepath.Rel(filepath.Dir(file), item.Path); err == nil {
+					link = filepath.ToSlash(rel)
+				}
+			}
+			line := fmt.Sprintf("- [ ] [#%s](%s) %s", number, link, item.Issue.Title)
+			for _, assignee := range item.Issue.Assignees {
+				line += " @" + assignee
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// parseBoard returns the issue lines of a board file by issue number.
+func parseBoard(data string) map[string]boardEntry {
+	entries := map[string]boardEntry{}
+	column := ""
+	for _, line := range strings.Split(data, "\n") {
+		if m := boardHeadingPattern.FindStringSubmatch(line); m != nil {
+			column = m[1]
+			continue
+		}
+		if m := boardLinePattern.FindStringSubmatch(line); m != nil {
+			entries[m[2]] = boardEntry{Column: column, Checked: m[1] != " "}
+		}
+	}
+	return entries
+}
+
+// boardEdits compares the board file with the issues on it and returns the
+// changes made on the board. Lines of issues no longer on the board, and
+// removed lines, are ignored.
+func boardEdits(cfg config.Config, data string, items []IssueFile) []boardEdit {
+	entries := parseBoard(data)
+	var edits []boardEdit
+	for _, item := range items {
+		entry, ok := entries[item.Issue.Number.String()]
+		if !ok {
+			continue
+		}
+		edit := boardEdit{Item: item, Close: entry.Checked}
+		column := entry.Column
+		if strings.EqualFold(column, boardNoStatus) {
+			column = ""
+		}
+		if !strings.EqualFold(column, boardColumn(item.Issue, cfg.Board.LabelPrefix)) {
+			edit.Column, edit.Moved = column, true
+		}
+		if edit.Moved || edit.Close {
+			edits = append(edits, edit)
+		}
+	}
+	return edits
+}
+
+// pendingBoardEdits reads the board file of p and returns the edits made
+// on it. A missing board has none.
+func pendingBoardEdits(p paths.Paths, cfg config.Config) ([]boardEdit, error) {
+	data, err := os.ReadFile(boardFile(p, cfg))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	items, err := boardIssues(p, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return boardEdits(cfg, string(data), items), nil
+}
+
+// applyBoardEdits makes the edits as local changes: a move replaces the
+// column label, a ticked box closes the issue.
+func (a *App) applyBoardEdits(ctx context.Context, p paths.Paths, cfg config.Config, edits []boardEdit) error {
+	t := a.Theme
+	prefix := cfg.Board.LabelPrefix
+	for _, edit := range edits {
+		file := edit.Item
+		iss := file.Issue
+		state := file.State
+		if edit.Moved {
+			iss.Labels = slices.DeleteFunc(slices.Clone(iss.Labels), func(label string) bool {
+				return len(label) > len(prefix) && strings.EqualFold(label[:len(prefix)], prefix)
+			})
+			if edit.Column != "" {
+				iss.Labels = append(iss.Labels, prefix+edit.Column)
+			}
+			column := edit.Column
+			if column == "" {
+				column = boardNoStatus
+			}
+			fmt.Fprintf(a.Out, "%s %s\n", t.FormatIssueHeader(state, iss.Number.String(), iss.Title), t.MutedText("-> "+column))
+		}
+		if edit.Close {
+			state = "closed"
+			iss.State = state
+			fmt.Fprintf(a.Out, "%s %s\n", t.FormatIssueHeader(state, iss.Number.String(), iss.Title), t.MutedText("closed"))
+		}
+		iss.LastLocalEditBy = a.localEditor(ctx)
+		if err := writeIssue(p, file.Path, iss); err != nil {
+			return err
+		}
+		if _, err := routeIssue(p, file.Path, state, iss); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBoard writes the board of p, leaving the file alone when it is up
+// to date.
+func writeBoard(p paths.Paths, cfg config.Config) (bool, error) {
+	items, err := boardIssues(p, cfg)
+	if err != nil {
+		return false, err
+	}
+	file := boardFile(p, cfg)
+	content := renderBoard(cfg, file, items)
+	if data, err := os.ReadFile(file); err == nil && string(data) == content {
+		return false, nil
+	}
+	return true, os.WriteFile(file, []byte(content), 0o644)
+}
+
+// Board writes BOARD.md, a kanban board of the open issues grouped into
+// columns by their board.label_prefix label. With opts.Apply, moves and
+// ticked boxes on the board are made local changes first; without it an
+// edited board is not overwritten.
+func (a *App) Board(ctx context.Context, opts BoardOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	if cfg.Board.LabelPrefix == "" {
+		return errors.New(`no board configured; set "board": {"label_prefix": "status/"} in .issues/.sync/config.json`)
+	}
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	edits, err := pendingBoardEdits(p, cfg)
+	if err != nil {
+		return err
+	}
+	if len(edits) > 0 && !opts.Apply {
+		return fmt.Errorf("%s has %d edit(s) not applied; run board --apply to make them local changes, or delete it", relPath(a.Root, boardFile(p, cfg)), len(edits))
+	}
+	if err := a.applyBoardEdits(ctx, p, cfg, edits); err != nil {
+		return err
+	}
+	written, err := writeBoard(p, cfg)
+	if err != nil {
+		return err
+	}
+	if written {
+		fmt.Fprintf(a.Out, "%s %s\n", t.SuccessText("Wrote"), relPath(a.Root, boardFile(p, cfg)))
+	}
+	return nil
+}
+
+// pullBoardEdits handles edits to the board before a pull: with
+// board.apply they become local changes, otherwise the edited board is
+// kept rather than overwritten. It reports whether the board was kept.
+func (a *App) pullBoardEdits(ctx context.Context, p paths.Paths, cfg config.Config) bool {
+	if cfg.Board.LabelPrefix == "" {
+		return false
+	}
+	t := a.Theme
+	edits, err := pendingBoardEdits(p, cfg)
+	if err == nil && len(edits) > 0 && !cfg.Board.Apply {
+		fmt.Fprintf(a.Err, "%s %s has %d edit(s) not applied, not updating it; run board --apply\n", t.WarningText("Warning:"), relPath(a.Root, boardFile(p, cfg)), len(edits))
+		return true
+	}
+	if err == nil {
+		err = a.applyBoardEdits(ctx, p, cfg, edits)
+	}
+	if err != nil {
+		fmt.Fprintf(a.Err, "%s reading the board: %v\n", t.WarningText("Warning:"), err)
+		return true
+	}
+	return false
+}
+
+// refreshBoard writes the board after a pull unless it was kept.
+func (a *App) refreshBoard(p paths.Paths, cfg config.Config, kept bool) {
+	if cfg.Board.LabelPrefix == "" || kept {
+		return
+	}
+	if _, err := writeBoard(p, cfg); err != nil {
+		fmt.Fprintf(a.Err, "%s writing the board: %v\n", a.Theme.WarningText("Warning:"), err)
+	}
+}
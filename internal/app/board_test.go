@@ -0,0 +1,94 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestBoard(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Board = config.BoardConfig{LabelPrefix: "status/", Columns: []string{"Todo", "Doing", "Done"}}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	crash := issue.Issue{Number: "1", Title: "Crash", State: "open", Labels: []string{"bug", "status/Todo"}, Assignees: []string{"alice"}}
+	typo := issue.Issue{Number: "2", Title: "Typo", State: "open", Labels: []string{"status/Review"}}
+	docs := issue.Issue{Number: "3", Title: "Docs", State: "open"}
+	old := issue.Issue{Number: "4", Title: "Old", State: "closed", Labels: []string{"status/Done"}}
+	for _, iss := range []issue.Issue{crash, typo, docs, old} {
+		dir := p.OpenDir
+		if iss.State == "closed" {
+			dir = p.ClosedDir
+		}
+		if err := issue.WriteFile(issue.PathFor(dir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	if err := application.Board(ctx, BoardOptions{}); err != nil {
+		t.Fatalf("board: %v", err)
+	}
+	boardPath := filepath.Join(root, "BOARD.md")
+	data, err := os.ReadFile(boardPath)
+	if err != nil {
+		t.Fatalf("read board: %v", err)
+	}
+	want := "## No status\n\n- [ ] [#3](https://github.com/owner/repo/issues/3) Docs\n\n" +
+		"## Todo\n\n- [ ] [#1](https://github.com/owner/repo/issues/1) Crash @alice\n\n" +
+		"## Doing\n\n\n## Done\n\n\n## Review\n\n- [ ] [#2](https://github.com/owner/repo/issues/2) Typo\n"
+	if !strings.HasSuffix(string(data), want) {
+		t.Fatalf("unexpected board:\n%s", data)
+	}
+
+	// Moving an issue and ticking a box are refused without --apply
+	edited := strings.Replace(string(data), "## Doing\n\n", "## Doing\n\n- [ ] [#1](https://github.com/owner/repo/issues/1) Crash @alice\n", 1)
+	edited = strings.Replace(edited, "## Todo\n\n- [ ] [#1](https://github.com/owner/repo/issues/1) Crash @alice\n", "## Todo\n", 1)
+	edited = strings.Replace(edited, "- [ ] [#2]", "- [x] [#2]", 1)
+	if err := os.WriteFile(boardPath, []byte(edited), 0o644); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := application.Board(ctx, BoardOptions{}); err == nil || !strings.Contains(err.Error(), "2 edit(s) not applied") {
+		t.Fatalf("expected unapplied edits refused, got %v", err)
+	}
+	if err := application.Board(ctx, BoardOptions{Apply: true}); err != nil {
+		t.Fatalf("board --apply: %v", err)
+	}
+	moved, err := readIssue(p, issue.PathFor(p.OpenDir, crash.Number, crash.Title))
+	if err != nil {
+		t.Fatalf("read moved issue: %v", err)
+	}
+	if !slices.Equal(moved.Labels, []string{"bug", "status/Doing"}) {
+		t.Fatalf("expected the column label replaced, got %v", moved.Labels)
+	}
+	closed, err := readIssue(p, issue.PathFor(p.ClosedDir, typo.Number, typo.Title))
+	if err != nil || closed.State != "closed" {
+		t.Fatalf("expected #2 closed, got %q (%v)", closed.State, err)
+	}
+	data, err = os.ReadFile(boardPath)
+	if err != nil {
+		t.Fatalf("read board: %v", err)
+	}
+	if strings.Contains(string(data), "#2") || !strings.Contains(string(data), "## Doing\n\n- [ ] [#1]") {
+		t.Fatalf("expected the board written again, got:\n%s", data)
+	}
+}
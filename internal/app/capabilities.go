@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// capabilitiesTTL is how long a capabilities probe is trusted before it is
+// repeated.
+const capabilitiesTTL = 7 * 24 * time.Hour
+
+// capabilities returns which optional features the forge supports, using
+// the probe cached in the config unless it is missing, stale, or refresh
+// is set. A new probe is saved back to the config. If probing fails every
+// feature is assumed available so the sync reports the actual error.
+func (a *App) capabilities(ctx context.Context, p paths.Paths, cfg *config.Config, client ghcli.Provider, refresh bool) ghcli.Capabilities {
+	now := a.Now().UTC()
+	if c := cfg.Capabilities; c != nil && !refresh && now.Sub(c.ProbedAt) < capabilitiesTTL {
+		return ghcli.Capabilities{IssueTypes: c.IssueTypes, Projects: c.Projects, SubIssues: c.SubIssues}
+	}
+
+	t := a.Theme
+	caps, err := client.ProbeCapabilities(ctx)
+	if err != nil {
+		fmt.Fprintf(a.Err, "%s probing features: %v\n", t.WarningText("Warning:"), err)
+		return ghcli.Capabilities{IssueTypes: true, Projects: true, SubIssues: true}
+	}
+	cfg.Capabilities = &config.Capabilities{
+		IssueTypes: caps.IssueTypes,
+		Projects:   caps.Projects,
+		SubIssues:  caps.SubIssues,
+		ProbedAt:   now,
+	}
+	if err := config.Save(p.ConfigPath, *cfg); err != nil {
+		fmt.Fprintf(a.Err, "%s saving config: %v\n", t.WarningText("Warning:"), err)
+	}
+	return caps
+}
+
+// unsupportedFeatures returns one warning per optional feature that the
+// given issues use but the forge does not support. The fields are kept in
+// the local files; they are just not synced.
+func unsupportedFeatures(cfg config.Config, caps ghcli.Capabilities, issues []IssueFile) []string {
+	var typed, inProjects, withParent int
+	for _, item := range issues {
+		if item.Issue.IssueType != "" {
+			typed++
+		}
+		if len(item.Issue.Projects) > 0 {
+			inProjects++
+		}
+		if item.Issue.Parent != nil {
+			withParent++
+		}
+	}
+
+	gitlab := cfg.Repository.Provider == config.ProviderGitLab
+	var warnings []string
+	if typed > 0 && !caps.IssueTypes {
+		reason := "issue types are not enabled for this repository"
+		if gitlab {
+			reason = "GitLab has no configurable issue types"
+		}
+		warnings = append(warnings, fmt.Sprintf("%s set a type, but %s", countIssues(typed), reason))
+	}
+	if inProjects > 0 && !caps.Projects {
+		reason := ghcli.ErrMissingProjectScope.Error()
+		if gitlab {
+			reason = "GitLab has no projects"
+		}
+		warnings = append(warnings, fmt.Sprintf("%s set projects, but %s", countIssues(inProjects), reason))
+	}
+	if withParent > 0 && !caps.SubIssues {
+		reason := "sub-issues are not available for this repository"
+		if gitlab {
+			reason = "GitLab child issues are not supported"
+		}
+		warnings = append(warnings, fmt.Sprintf("%s set a parent, but %s", countIssues(withParent), reason))
+	}
+	return warnings
+}
+
+// supportedRelationships drops the parent when sub-issues are unavailable,
+// so syncing the blocking relationships doesn't fail on it.
+func supportedRelationships(iss issue.Issue, caps ghcli.Capabilities) issue.Issue {
+	if !caps.SubIssues {
+		iss.Parent = nil
+	}
+	return iss
+}
+
+// warnUnsupported prints the warnings from unsupportedFeatures.
+func (a *App) warnUnsupported(cfg config.Config, caps ghcli.Capabilities, issues []IssueFile) {
+	for _, warning := range unsupportedFeatures(cfg, caps, issues) {
+		fmt.Fprintf(a.Err, "%s %s\n", a.Theme.WarningText("Warning:"), warning)
+	}
+}
+
+func countIssues(n int) string {
+	if n == 1 {
+		return "1 issue"
+	}
+	return fmt.Sprintf("%d issues", n)
+}
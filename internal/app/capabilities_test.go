@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// probeCountRunner answers every capability probe successfully and counts
+// the calls.
+type probeCountRunner struct {
+	calls int
+}
+
+func (r *probeCountRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	r.calls++
+	return `{"data":{"repository":{"issueTypes":{"totalCount":2}}}}`, nil
+}
+
+func TestCapabilitiesCachedInConfig(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	runner := &probeCountRunner{}
+	application := New(root, runner, io.Discard, io.Discard)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	application.Now = func() time.Time { return now }
+	client := ghcli.NewClient(runner, "owner/repo")
+
+	caps := application.capabilities(context.Background(), p, &cfg, client, false)
+	if caps != (ghcli.Capabilities{IssueTypes: true, Projects: true, SubIssues: true}) {
+		t.Fatalf("unexpected capabilities: %+v", caps)
+	}
+	probes := runner.calls
+	saved, err := config.Load(p.ConfigPath)
+	if err != nil || saved.Capabilities == nil || !saved.Capabilities.ProbedAt.Equal(now) {
+		t.Fatalf("expected capabilities saved to config, got %+v (%v)", saved.Capabilities, err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	application.capabilities(context.Background(), p, &saved, client, false)
+	if runner.calls != probes {
+		t.Fatalf("expected cached capabilities, got %d new calls", runner.calls-probes)
+	}
+
+	now = now.Add(capabilitiesTTL)
+	application.capabilities(context.Background(), p, &saved, client, false)
+	if runner.calls == probes {
+		t.Fatalf("expected stale capabilities to be probed again")
+	}
+}
+
+func TestUnsupportedFeatures(t *testing.T) {
+	parent := issue.IssueRef("1")
+	issues := []IssueFile{
+		{Issue: issue.Issue{Number: "2", IssueType: "Bug", Projects: []string{"Roadmap"}}},
+		{Issue: issue.Issue{Number: "3", Parent: &parent, Projects: []string{"Roadmap"}}},
+	}
+	cfg := config.Default("owner", "repo")
+
+	warnings := unsupportedFeatures(cfg, ghcli.Capabilities{IssueTypes: true, SubIssues: true}, issues)
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "2 issues set projects") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	cfg.Repository.Provider = config.ProviderGitLab
+	warnings = unsupportedFeatures(cfg, ghcli.Capabilities{}, issues)
+	if len(warnings) != 3 || !strings.Contains(warnings[0], "GitLab") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	var errOut bytes.Buffer
+	application := New(t.TempDir(), &offlineRunner{}, io.Discard, &errOut)
+	application.warnUnsupported(config.Default("owner", "repo"), ghcli.Capabilities{IssueTypes: true, Projects: true, SubIssues: true}, issues)
+	if errOut.Len() != 0 {
+		t.Fatalf("expected no warnings when everything is supported, got %q", errOut.String())
+	}
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// pullCheckpoint lets an interrupted pull continue its issue listing where
+// it stopped instead of starting over, which matters for the initial pull
+// of repositories with tens of thousands of issues. The checkpoint file
+// starts with a header line describing the listing; every page appends a
+// line with its issues and the cursor to resume after it. A page that was
+// only partially written is fetched again.
+type pullCheckpoint struct {
+	State     string    `json:"state"`
+	Labels    []string  `json:"labels,omitempty"`
+	Milestone string    `json:"milestone,omitempty"`
+	Assignee  string    `json:"assignee,omitempty"`
+	Since     time.Time `json:"since"`
+	StartedAt time.Time `json:"started_at"`
+
+	path   string
+	cursor string
+	issues []issue.Issue
+	// size is the length of the checkpoint up to the last complete page
+	size int64
+}
+
+type pullCheckpointPage struct {
+	Cursor string        `json:"cursor"`
+	Issues []issue.Issue `json:"issues"`
+}
+
+// openPullCheckpoint continues the checkpoint of an interrupted listing
+// with the same options or starts a new one at now.
+func openPullCheckpoint(p paths.Paths, opts ghcli.ListIssuesOptions, now time.Time) (*pullCheckpoint, error) {
+	if cp, err := loadPullCheckpoint(p.CheckpointPath); err == nil && cp.matches(opts) {
+		// Drop a partially written page so new pages start on a new line
+		return cp, os.Truncate(cp.path, cp.size)
+	}
+	cp := &pullCheckpoint{
+		State:     opts.State,
+		Labels:    opts.Labels,
+		Milestone: opts.Milestone,
+		Assignee:  opts.Assignee,
+		Since:     opts.Since,
+		StartedAt: now,
+		path:      p.CheckpointPath,
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return nil, err
+	}
+	return cp, os.WriteFile(cp.path, append(data, '\n'), 0o644)
+}
+
+func loadPullCheckpoint(path string) (*pullCheckpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 256<<20)
+	if !scanner.Scan() {
+		return nil, errors.New("empty pull checkpoint")
+	}
+	cp := &pullCheckpoint{path: path, size: int64(len(scanner.Bytes())) + 1}
+	if err := json.Unmarshal(scanner.Bytes(), cp); err != nil {
+		return nil, err
+	}
+	for scanner.Scan() {
+		var page pullCheckpointPage
+		if err := json.Unmarshal(scanner.Bytes(), &page); err != nil {
+			break
+		}
+		cp.cursor = page.Cursor
+		cp.issues = append(cp.issues, page.Issues...)
+		cp.size += int64(len(scanner.Bytes())) + 1
+	}
+	return cp, nil
+}
+
+func (cp *pullCheckpoint) matches(opts ghcli.ListIssuesOptions) bool {
+	return cp.State == opts.State && slices.Equal(cp.Labels, opts.Labels) && cp.Milestone == opts.Milestone &&
+		cp.Assignee == opts.Assignee && cp.Since.Equal(opts.Since)
+}
+
+// page records a listed page. It is the OnPage callback of the listing.
+func (cp *pullCheckpoint) page(cursor string, issues []issue.Issue) error {
+	data, err := json.Marshal(pullCheckpointPage{Cursor: cursor, Issues: issues})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(cp.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// removePullCheckpoint forgets the checkpoint once a pull has applied the
+// complete listing.
+func removePullCheckpoint(p paths.Paths) error {
+	if err := os.Remove(p.CheckpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
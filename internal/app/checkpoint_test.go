@@ -0,0 +1,68 @@
+package app
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestPullCheckpoint(t *testing.T) {
+	p := paths.New(t.TempDir())
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	started := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	opts := ghcli.ListIssuesOptions{State: "open"}
+
+	cp, err := openPullCheckpoint(p, opts, started)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := cp.page("c1", []issue.Issue{{Number: "1", Title: "First"}, {Number: "2", Title: "Second"}}); err != nil {
+		t.Fatalf("page: %v", err)
+	}
+	// An interruption while writing the next page leaves half a line
+	f, err := os.OpenFile(p.CheckpointPath, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("open checkpoint: %v", err)
+	}
+	f.WriteString(`{"cursor":"c2","issues":[{"Number":"3"`)
+	f.Close()
+
+	resumed, err := openPullCheckpoint(p, opts, started.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if resumed.cursor != "c1" || len(resumed.issues) != 2 || resumed.issues[1].Title != "Second" {
+		t.Fatalf("expected to resume after c1 with two issues, got %q %+v", resumed.cursor, resumed.issues)
+	}
+	if !resumed.StartedAt.Equal(started) {
+		t.Fatalf("expected the original start time, got %v", resumed.StartedAt)
+	}
+	if err := resumed.page("c2", []issue.Issue{{Number: "3", Title: "Third"}}); err != nil {
+		t.Fatalf("page: %v", err)
+	}
+	if resumed, err = openPullCheckpoint(p, opts, started); err != nil || resumed.cursor != "c2" || len(resumed.issues) != 3 {
+		t.Fatalf("expected to resume after c2 with three issues, got %+v (%v)", resumed, err)
+	}
+
+	// A listing with other options starts over
+	fresh, err := openPullCheckpoint(p, ghcli.ListIssuesOptions{State: "all"}, started.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if fresh.cursor != "" || len(fresh.issues) != 0 {
+		t.Fatalf("expected a new checkpoint, got %q %+v", fresh.cursor, fresh.issues)
+	}
+
+	if err := removePullCheckpoint(p); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := os.Stat(p.CheckpointPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the checkpoint removed, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+// DefaultCIBranch is the branch ci-sync commits the updated mirror to.
+const DefaultCIBranch = "gh-issue-sync/update"
+
+type CISyncOptions struct {
+	All    bool   // Pull closed issues too
+	Push   bool   // Push local changes after pulling
+	OpenPR bool   // Commit the updated issues directory and open a pull request
+	Branch string // Branch for the pull request; DefaultCIBranch if empty
+	Base   string // Base branch for the pull request; the default branch if empty
+}
+
+// CISync pulls (and optionally pushes) from inside a GitHub Actions job.
+// Output is uncolored, warnings and conflicts become workflow annotations,
+// and with OpenPR the updated mirror is proposed as a pull request.
+func (a *App) CISync(ctx context.Context, opts CISyncOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Repository.Provider == config.ProviderGitLab {
+		return errors.New("ci-sync only supports GitHub")
+	}
+	if os.Getenv("GH_TOKEN") == "" && os.Getenv("GITHUB_TOKEN") == "" {
+		return errors.New("ci-sync needs a token; set GITHUB_TOKEN: ${{ github.token }} in the workflow step's env")
+	}
+
+	a.Theme = theme.Plain()
+	annotations := &annotationWriter{w: a.Out}
+	a.Err = annotations
+	defer annotations.Flush()
+
+	if err := a.Pull(ctx, PullOptions{All: opts.All}, nil); err != nil {
+		return err
+	}
+	if opts.Push {
+		if err := a.Push(ctx, PushOptions{}, nil); err != nil {
+			return err
+		}
+	}
+	if err := a.annotateConflicts(p); err != nil {
+		return err
+	}
+	if opts.OpenPR {
+		return a.openMirrorPR(ctx, p, opts)
+	}
+	return nil
+}
+
+// annotateConflicts emits a warning annotation on the file of every issue
+// with a recorded conflict.
+func (a *App) annotateConflicts(p paths.Paths) error {
+	records, err := loadConflicts(p)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	localIssues, err := loadLocalIssues(p)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]string, len(localIssues))
+	for _, item := range localIssues {
+		files[item.Issue.Number.String()] = item.Path
+	}
+	root := a.Root
+	if gitRoot := paths.FindGitRoot(root); gitRoot != "" {
+		root = gitRoot
+	}
+	for _, record := range records {
+		props := "title=" + escapeAnnotationProperty("Conflict on #"+record.Number)
+		if path, ok := files[record.Number]; ok {
+			props = "file=" + escapeAnnotationProperty(relPath(root, path)) + "," + props
+		}
+		message := fmt.Sprintf("%s: %s changed both locally and remotely (found on %s); run gh-issue-sync resolve %s",
+			record.Title, strings.Join(record.Fields, ", "), record.Source, record.Number)
+		fmt.Fprintf(a.Out, "::warning %s::%s\n", props, escapeAnnotation(message))
+	}
+	return nil
+}
+
+// openMirrorPR commits the issues directory to a branch and opens a pull
+// request for it, or updates the branch of one that is already open.
+func (a *App) openMirrorPR(ctx context.Context, p paths.Paths, opts CISyncOptions) error {
+	branch := opts.Branch
+	if branch == "" {
+		branch = DefaultCIBranch
+	}
+	git := func(args ...string) (string, error) {
+		return a.Runner.Run(ctx, "git", append([]string{"-C", a.Root}, args...)...)
+	}
+
+	status, err := git("status", "--porcelain", "--", p.IssuesDir)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(status) == "" {
+		fmt.Fprintln(a.Out, "No changes to the issue mirror")
+		return nil
+	}
+
+	steps := [][]string{
+		{"checkout", "-B", branch},
+		{"add", "--all", "--", p.IssuesDir},
+		{"-c", "user.name=github-actions[bot]", "-c", "user.email=41898282+github-actions[bot]@users.noreply.github.com",
+			"commit", "-m", "Update issue mirror"},
+		{"push", "--force", "origin", branch},
+	}
+	for _, args := range steps {
+		if _, err := git(args...); err != nil {
+			return err
+		}
+	}
+
+	existing, err := a.Runner.Run(ctx, "gh", "pr", "list", "--head", branch, "--state", "open", "--json", "url", "-q", ".[0].url")
+	if err != nil {
+		return err
+	}
+	if url := strings.TrimSpace(existing); url != "" {
+		fmt.Fprintf(a.Out, "Updated pull request %s\n", url)
+		return nil
+	}
+	args := []string{"pr", "create", "--head", branch, "--title", "Update issue mirror",
+		"--body", "Issues pulled by `gh-issue-sync ci-sync`."}
+	if opts.Base != "" {
+		args = append(args, "--base", opts.Base)
+	}
+	out, err := a.Runner.Run(ctx, "gh", args...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "Opened pull request %s\n", strings.TrimSpace(out))
+	return nil
+}
+
+// annotationWriter turns "Warning: ..." lines into workflow warning
+// annotations and passes everything else through.
+type annotationWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (w *annotationWriter) Write(data []byte) (int, error) {
+	w.buf = append(w.buf, data...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(data), nil
+		}
+		line := string(w.buf[:i])
+		w.buf = w.buf[i+1:]
+		if err := w.writeLine(line); err != nil {
+			return len(data), err
+		}
+	}
+}
+
+// Flush writes a trailing line without a newline.
+func (w *annotationWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *annotationWriter) writeLine(line string) error {
+	if message, ok := strings.CutPrefix(line, "Warning: "); ok {
+		line = "::warning::" + escapeAnnotation(message)
+	}
+	_, err := fmt.Fprintln(w.w, line)
+	return err
+}
+
+// escapeAnnotation escapes an annotation message as workflow commands
+// require.
+func escapeAnnotation(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty additionally escapes the property separators.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeAnnotation(s))
+}
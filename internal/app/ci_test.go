@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// prRunner records git and gh calls and answers the ones openMirrorPR
+// inspects.
+type prRunner struct {
+	calls []string
+}
+
+func (r *prRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	call := name + " " + strings.Join(args, " ")
+	r.calls = append(r.calls, call)
+	switch {
+	case strings.Contains(call, "status --porcelain"):
+		return " M .issues/open/1-crash.md\n", nil
+	case strings.Contains(call, "pr create"):
+		return "https://github.com/owner/repo/pull/7\n", nil
+	}
+	return "", nil
+}
+
+func TestAnnotationWriter(t *testing.T) {
+	var out strings.Builder
+	w := &annotationWriter{w: &out}
+	io.WriteString(w, "Warning: 50% done\nFetched 3 issues")
+	io.WriteString(w, " (page 1)\n")
+	io.WriteString(w, "Warning: trailing")
+	w.Flush()
+
+	want := "::warning::50%25 done\nFetched 3 issues (page 1)\n::warning::trailing\n"
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
+
+func TestCISyncRequiresToken(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	runner := &offlineRunner{}
+	err := New(root, runner, io.Discard, io.Discard).CISync(context.Background(), CISyncOptions{})
+	if err == nil || !strings.Contains(err.Error(), "GITHUB_TOKEN") {
+		t.Fatalf("expected token error, got %v", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Fatalf("expected no calls, got %v", runner.calls)
+	}
+}
+
+func TestCISyncAnnotatesConflicts(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	base := issue.Issue{Number: "1", Title: "Crash", State: "open"}
+	local := base
+	local.Title = "Crash on start"
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, local.Number, local.Title), local); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	remote := base
+	remote.Title = "Crash at startup"
+	if err := recordConflict(p, "pull", base, local, remote, []string{"title"}, time.Now()); err != nil {
+		t.Fatalf("record conflict: %v", err)
+	}
+
+	var out strings.Builder
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	if err := application.annotateConflicts(p); err != nil {
+		t.Fatalf("annotate: %v", err)
+	}
+	want := "::warning file=.issues/open/1-crash-on-start.md,title=Conflict on #1::"
+	if !strings.HasPrefix(out.String(), want) || !strings.Contains(out.String(), "resolve 1") {
+		t.Fatalf("unexpected annotation: %q", out.String())
+	}
+}
+
+func TestOpenMirrorPR(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	runner := &prRunner{}
+	var out strings.Builder
+	application := New(root, runner, &out, io.Discard)
+
+	if err := application.openMirrorPR(context.Background(), p, CISyncOptions{Base: "main"}); err != nil {
+		t.Fatalf("open pr: %v", err)
+	}
+	var commands []string
+	for _, call := range runner.calls {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimPrefix(call, "git -C "+root+" "), "gh "))
+		commands = append(commands, strings.Join(fields[:2], " "))
+	}
+	want := "status --porcelain,checkout -B,add --all,-c user.name=github-actions[bot],push --force,pr list,pr create"
+	if strings.Join(commands, ",") != want {
+		t.Fatalf("unexpected calls: %v", runner.calls)
+	}
+	if !strings.Contains(runner.calls[len(runner.calls)-1], "--base main") {
+		t.Fatalf("expected base branch, got %s", runner.calls[len(runner.calls)-1])
+	}
+	if !strings.Contains(out.String(), "pull/7") {
+		t.Fatalf("expected pull request URL, got %q", out.String())
+	}
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+)
+
+// codeRef is one entry of the code_refs front matter list.
+type codeRef struct {
+	URL     string // Set for permalinks, which are kept as they are
+	Path    string
+	Line    int
+	EndLine int
+	Commit  string
+}
+
+// parseCodeRef parses "path", "path:line", or "path:start-end", each
+// optionally followed by "@sha", or a permalink.
+func parseCodeRef(s string) (codeRef, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://") {
+		return codeRef{URL: s}, nil
+	}
+	var ref codeRef
+	if idx := strings.LastIndex(s, "@"); idx != -1 {
+		s, ref.Commit = s[:idx], s[idx+1:]
+	}
+	if idx := strings.LastIndex(s, ":"); idx != -1 {
+		start, end, isRange := strings.Cut(s[idx+1:], "-")
+		line, err := strconv.Atoi(start)
+		if err != nil || line < 1 {
+			return codeRef{}, fmt.Errorf("invalid line in code reference %q", s)
+		}
+		ref.Line = line
+		if isRange {
+			endLine, err := strconv.Atoi(end)
+			if err != nil || endLine < line {
+				return codeRef{}, fmt.Errorf("invalid line range in code reference %q", s)
+			}
+			ref.EndLine = endLine
+		}
+		s = s[:idx]
+	}
+	if s == "" {
+		return codeRef{}, fmt.Errorf("code reference without a path")
+	}
+	ref.Path = s
+	return ref, nil
+}
+
+// location is the path and lines of ref without the commit.
+func (r codeRef) location() string {
+	if r.URL != "" {
+		return r.URL
+	}
+	switch {
+	case r.EndLine > 0:
+		return fmt.Sprintf("%s:%d-%d", r.Path, r.Line, r.EndLine)
+	case r.Line > 0:
+		return fmt.Sprintf("%s:%d", r.Path, r.Line)
+	}
+	return r.Path
+}
+
+func (r codeRef) String() string {
+	if r.URL == "" && r.Commit != "" {
+		return r.location() + "@" + r.Commit
+	}
+	return r.location()
+}
+
+// permalink returns the web URL of ref. Without a commit it points at
+// HEAD of the default branch.
+func (r codeRef) permalink(cfg config.Config) string {
+	if r.URL != "" {
+		return r.URL
+	}
+	rev := r.Commit
+	if rev == "" {
+		rev = "HEAD"
+	}
+	if cfg.Repository.Provider == config.ProviderGitLab {
+		host := cfg.Repository.Host
+		if host == "" {
+			host = "gitlab.com"
+		}
+		url := fmt.Sprintf("https://%s/%s/-/blob/%s/%s", host, repoSlug(cfg), rev, r.Path)
+		switch {
+		case r.EndLine > 0:
+			url += fmt.Sprintf("#L%d-%d", r.Line, r.EndLine)
+		case r.Line > 0:
+			url += fmt.Sprintf("#L%d", r.Line)
+		}
+		return url
+	}
+	url := fmt.Sprintf("https://github.com/%s/blob/%s/%s", repoSlug(cfg), rev, r.Path)
+	switch {
+	case r.EndLine > 0:
+		url += fmt.Sprintf("#L%d-L%d", r.Line, r.EndLine)
+	case r.Line > 0:
+		url += fmt.Sprintf("#L%d", r.Line)
+	}
+	return url
+}
+
+// LinkCode records a code location on an issue. Paths are taken relative
+// to the working directory and stored relative to the git checkout along
+// with the current commit, so the permalink keeps pointing at the code the
+// issue was about.
+func (a *App) LinkCode(ctx context.Context, ref, location string) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	code, err := parseCodeRef(location)
+	if err != nil {
+		return err
+	}
+	if code.URL == "" {
+		if err := a.resolveCodeRef(ctx, &code); err != nil {
+			return err
+		}
+	}
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	file, err := findIssueByRef(a.Root, p, ref)
+	if err != nil {
+		return err
+	}
+	// Linking the same location again moves it to the current commit
+	refs := make([]string, 0, len(file.Issue.CodeRefs)+1)
+	for _, existing := range file.Issue.CodeRefs {
+		if parsed, err := parseCodeRef(existing); err == nil && parsed.location() == code.location() {
+			continue
+		}
+		refs = append(refs, existing)
+	}
+	file.Issue.CodeRefs = append(refs, code.String())
+	if err := writeIssue(p, file.Path, file.Issue); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%s #%s to %s\n", t.SuccessText("Linked"), file.Issue.Number, code)
+	return nil
+}
+
+// resolveCodeRef makes the path of ref relative to the git checkout and
+// pins it to HEAD. Outside a checkout the path stays relative to the issue
+// root and the reference has no commit.
+func (a *App) resolveCodeRef(ctx context.Context, ref *codeRef) error {
+	path := ref.Path
+	if !filepath.IsAbs(path) {
+		if cwd, err := os.Getwd(); err == nil {
+			path = filepath.Join(cwd, path)
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot link %s: %w", ref.Path, err)
+	}
+
+	top, head, ok := gitCheckout(ctx, a.Root)
+	if ok {
+		ref.Commit = head
+	} else {
+		top = a.Root
+		fmt.Fprintf(a.Err, "%s not a git checkout, linking %s without a commit\n", a.Theme.WarningText("Warning:"), ref.Path)
+	}
+	if resolved, err := filepath.EvalSymlinks(top); err == nil {
+		top = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(top, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("cannot link %s: outside of %s", ref.Path, top)
+	}
+	ref.Path = filepath.ToSlash(rel)
+	return nil
+}
+
+// gitCheckout returns the top-level directory of the git checkout holding
+// dir and its HEAD commit.
+func gitCheckout(ctx context.Context, dir string) (top, head string, ok bool) {
+	out, err := execCommand(ctx, "git", "-C", dir, "rev-parse", "--show-toplevel", "HEAD")
+	if err != nil {
+		return "", "", false
+	}
+	lines := strings.Fields(out)
+	if len(lines) != 2 {
+		return "", "", false
+	}
+	return lines[0], lines[1], true
+}
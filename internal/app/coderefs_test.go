@@ -0,0 +1,110 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestCodeRefPermalink(t *testing.T) {
+	github := config.Default("owner", "repo")
+	gitlab := config.Default("group", "repo")
+	gitlab.Repository.Provider = config.ProviderGitLab
+	tests := []struct {
+		ref  string
+		cfg  config.Config
+		want string
+	}{
+		{"src/auth.go:42@abc123", github, "https://github.com/owner/repo/blob/abc123/src/auth.go#L42"},
+		{"src/auth.go:42-50@abc123", github, "https://github.com/owner/repo/blob/abc123/src/auth.go#L42-L50"},
+		{"src/auth.go", github, "https://github.com/owner/repo/blob/HEAD/src/auth.go"},
+		{"src/auth.go:42-50@abc123", gitlab, "https://gitlab.com/group/repo/-/blob/abc123/src/auth.go#L42-50"},
+		{"https://example.com/x.go#L1", github, "https://example.com/x.go#L1"},
+	}
+	for _, tt := range tests {
+		ref, err := parseCodeRef(tt.ref)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.ref, err)
+		}
+		if got := ref.permalink(tt.cfg); got != tt.want {
+			t.Errorf("permalink(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+		if got := ref.String(); got != tt.ref {
+			t.Errorf("String(%q) = %q", tt.ref, got)
+		}
+	}
+	for _, bad := range []string{"", "x.go:abc", "x.go:9-3", ":4"} {
+		if _, err := parseCodeRef(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLinkCode(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "7", Title: "Token expiry", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	source := filepath.Join(root, "src", "auth.go")
+	if err := os.MkdirAll(filepath.Dir(source), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(source, []byte("package auth\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	commit := "1111111"
+	previous := execCommand
+	execCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		return root + "\n" + commit + "\n", nil
+	}
+	t.Cleanup(func() { execCommand = previous })
+
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	if err := application.LinkCode(context.Background(), "7", source+":12"); err != nil {
+		t.Fatalf("link-code: %v", err)
+	}
+	if err := application.LinkCode(context.Background(), "7", "https://example.com/x.go#L1"); err != nil {
+		t.Fatalf("link-code: %v", err)
+	}
+	// Linking the same location again replaces the commit
+	commit = "2222222"
+	if err := application.LinkCode(context.Background(), "7", source+":12"); err != nil {
+		t.Fatalf("link-code: %v", err)
+	}
+	file, err := findIssueByNumber(p, "7")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	want := []string{"https://example.com/x.go#L1", "src/auth.go:12@2222222"}
+	if !reflect.DeepEqual(file.Issue.CodeRefs, want) {
+		t.Fatalf("code_refs = %v, want %v", file.Issue.CodeRefs, want)
+	}
+
+	var out bytes.Buffer
+	application.Out = &out
+	application.Theme = theme.Plain()
+	if err := application.View(context.Background(), "7", ViewOptions{}); err != nil {
+		t.Fatalf("view: %v", err)
+	}
+	if !strings.Contains(out.String(), "src/auth.go:12@2222222 https://github.com/owner/repo/blob/2222222/src/auth.go#L12") {
+		t.Fatalf("view should render the permalink:\n%s", out.String())
+	}
+}
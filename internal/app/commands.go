@@ -1,12 +1,16 @@
 package app
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,14 +18,12 @@ import (
 	"github.com/google/shlex"
 	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
-	"github.com/mitsuhiko/gh-issue-sync/internal/localid"
-	"github.com/mitsuhiko/gh-issue-sync/internal/lock"
 	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
 	"github.com/mitsuhiko/gh-issue-sync/internal/search"
 )
 
-func (a *App) Status(ctx context.Context) error {
-	p := paths.New(a.Root)
+func (a *App) Status(ctx context.Context, opts StatusOptions) error {
+	p := a.issuePaths()
 	cfg, err := loadConfig(p.ConfigPath)
 	if err != nil {
 		return err
@@ -29,6 +31,20 @@ func (a *App) Status(ctx context.Context) error {
 	t := a.Theme
 
 	fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Repository:"), t.AccentText(cfg.Repository.Owner+"/"+cfg.Repository.Repo))
+	if repo, err := loadRepoCache(p); err == nil {
+		if repo.Description != "" {
+			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Description:"), repo.Description)
+		}
+		if len(repo.Topics) > 0 {
+			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Topics:"), strings.Join(repo.Topics, ", "))
+		}
+		if repo.DefaultBranch != "" {
+			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Default branch:"), repo.DefaultBranch)
+		}
+		if repo.Homepage != "" {
+			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Homepage:"), repo.Homepage)
+		}
+	}
 	if cfg.Sync.LastFullPull != nil {
 		fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Last full pull:"), cfg.Sync.LastFullPull.Format(time.RFC3339))
 	} else {
@@ -39,12 +55,6 @@ func (a *App) Status(ctx context.Context) error {
 	labelCache, _ := loadLabelCache(p)
 	labelColors := labelCacheToColorMap(labelCache)
 
-	result := loadLocalIssuesWithErrors(p)
-	for _, parseErr := range result.Errors {
-		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
-	}
-	localIssues := result.Issues
-
 	type modifiedIssue struct {
 		item     IssueFile
 		original issue.Issue
@@ -52,22 +62,40 @@ func (a *App) Status(ctx context.Context) error {
 
 	var modified []modifiedIssue
 	var newLocal []IssueFile
+	// localIssues keeps every issue for --remote, and otherwise just the
+	// fields the feature check needs
+	var localIssues []IssueFile
 
-	for _, item := range localIssues {
+	// Compare while streaming so only changed issues are kept in memory
+	walkLocalIssues(p, false, func(item IssueFile) {
+		if opts.Remote {
+			localIssues = append(localIssues, item)
+		} else {
+			localIssues = append(localIssues, IssueFile{Issue: issue.Issue{
+				IssueType: item.Issue.IssueType,
+				Projects:  item.Issue.Projects,
+				Parent:    item.Issue.Parent,
+			}})
+		}
+		if item.Issue.Draft {
+			return
+		}
 		if item.Issue.Number.IsLocal() {
 			newLocal = append(newLocal, item)
-			continue
+			return
 		}
 		original, hasOriginal := readOriginalIssue(p, item.Issue.Number.String())
 		if !hasOriginal {
 			// No original means we can't compare - treat as modified without baseline
 			modified = append(modified, modifiedIssue{item: item, original: issue.Issue{}})
-			continue
+			return
 		}
 		if !issue.EqualIgnoringSyncedAt(item.Issue, original) {
 			modified = append(modified, modifiedIssue{item: item, original: original})
 		}
-	}
+	}, func(parseErr ParseError) {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
+	})
 
 	// Sort by issue number
 	sort.Slice(modified, func(i, j int) bool {
@@ -82,7 +110,7 @@ func (a *App) Status(ctx context.Context) error {
 		fmt.Fprintln(a.Out)
 		fmt.Fprintln(a.Out, t.Bold("Modified locally:"))
 		for _, m := range modified {
-			fmt.Fprintln(a.Out, t.FormatIssueHeader("M", m.item.Issue.Number.String(), m.item.Issue.Title))
+			fmt.Fprintln(a.Out, t.FormatIssueHeader("M", m.item.Issue.Number.String(), m.item.Issue.Title)+a.formatEditedBy(m.item.Issue))
 			for _, line := range a.formatChangeLines(m.original, m.item.Issue, labelColors) {
 				fmt.Fprintln(a.Out, line)
 			}
@@ -94,7 +122,7 @@ func (a *App) Status(ctx context.Context) error {
 		fmt.Fprintln(a.Out)
 		fmt.Fprintln(a.Out, t.Bold("New local issues:"))
 		for _, item := range newLocal {
-			fmt.Fprintln(a.Out, t.FormatIssueHeader("A", item.Issue.Number.String(), item.Issue.Title))
+			fmt.Fprintln(a.Out, t.FormatIssueHeader("A", item.Issue.Number.String(), item.Issue.Title)+a.formatEditedBy(item.Issue))
 		}
 	}
 
@@ -127,24 +155,20 @@ func (a *App) Status(ctx context.Context) error {
 		fmt.Fprintf(a.Out, "\n%s\n", t.MutedText("No local changes"))
 	}
 
-	// Check if projects are used and warn about missing scope
-	projectsUsed := false
-	for _, item := range localIssues {
-		if len(item.Issue.Projects) > 0 {
-			projectsUsed = true
-			break
-		}
+	if err := a.printSLA(p, cfg); err != nil {
+		return err
 	}
-	if !projectsUsed {
-		// Check if projects.json has entries
-		if cache, err := loadProjectCache(p); err == nil && len(cache.Projects) > 0 {
-			projectsUsed = true
+
+	if opts.Remote {
+		if err := a.printRemoteStatus(ctx, p, cfg, localIssues); err != nil {
+			return err
 		}
 	}
-	if projectsUsed {
-		client := ghcli.NewClient(a.Runner, repoSlug(cfg))
-		if hasScope, err := client.HasProjectScope(ctx); err == nil && !hasScope {
-			fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), ghcli.ErrMissingProjectScope)
+
+	// Only probe the forge when an issue uses an optional feature at all.
+	if len(unsupportedFeatures(cfg, ghcli.Capabilities{}, localIssues)) > 0 {
+		if client, err := a.newProvider(cfg); err == nil {
+			a.warnUnsupported(cfg, a.capabilities(ctx, p, &cfg, client, false), localIssues)
 		}
 	}
 
@@ -152,48 +176,80 @@ func (a *App) Status(ctx context.Context) error {
 }
 
 func (a *App) List(ctx context.Context, opts ListOptions) error {
-	p := paths.New(a.Root)
-	if _, err := loadConfig(p.ConfigPath); err != nil {
+	if org, ok := a.orgConfig(); ok {
+		return a.listOrg(ctx, org, opts)
+	}
+	count, err := a.listIssues(ctx, opts)
+	if err != nil {
 		return err
 	}
+	if count == 0 {
+		fmt.Fprintln(a.Out, a.Theme.MutedText("No issues found"))
+	}
+	return nil
+}
+
+// listIssues prints the issues matching opts and returns how many it printed.
+func (a *App) listIssues(ctx context.Context, opts ListOptions) (int, error) {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return 0, err
+	}
 	t := a.Theme
 
 	// Load label colors for display
 	labelCache, _ := loadLabelCache(p)
 	labelColors := labelCacheToColorMap(labelCache)
 
-	result := loadLocalIssuesWithErrors(p)
-	for _, parseErr := range result.Errors {
-		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
-	}
-	localIssues := result.Issues
-
 	// Parse search query if provided
 	var searchQuery *search.Query
 	if opts.Search != "" {
 		q := search.Parse(opts.Search)
+		if q.Err != nil {
+			return 0, q.Err
+		}
 		searchQuery = &q
 	}
+	// --sort takes precedence over a sort: in the search query
+	sortQuery := searchQuery
+	if opts.Sort != "" {
+		keys, err := search.ParseSortExpr(opts.Sort)
+		if err != nil {
+			return 0, err
+		}
+		var q search.Query
+		if searchQuery != nil {
+			q = *searchQuery
+		}
+		q.SortKeys = keys
+		sortQuery = &q
+	}
+	var facetFields []string
+	if opts.Facets != "" {
+		fields, err := search.ParseFacets(opts.Facets)
+		if err != nil {
+			return 0, err
+		}
+		facetFields = fields
+	}
 
-	// Apply filters
-	var filtered []IssueFile
-	for _, item := range localIssues {
+	matches := func(item IssueFile) bool {
 		// State filter from opts (takes precedence)
 		if opts.State != "" && item.State != opts.State {
-			continue
+			return false
 		}
 		// State filter from search query
 		if searchQuery != nil && searchQuery.State != "" && !strings.EqualFold(item.State, searchQuery.State) {
-			continue
+			return false
 		}
 		// Default to open if neither --all nor explicit state
 		if !opts.All && opts.State == "" && (searchQuery == nil || searchQuery.State == "") && item.State != "open" {
-			continue
+			return false
 		}
 
 		// Local-only filter
 		if opts.Local && !item.Issue.Number.IsLocal() {
-			continue
+			return false
 		}
 
 		// Modified filter
@@ -203,7 +259,7 @@ func (a *App) List(ctx context.Context, opts ListOptions) error {
 			} else {
 				original, hasOriginal := readOriginalIssue(p, item.Issue.Number.String())
 				if hasOriginal && issue.EqualIgnoringSyncedAt(item.Issue, original) {
-					continue
+					return false
 				}
 			}
 		}
@@ -223,7 +279,7 @@ func (a *App) List(ctx context.Context, opts ListOptions) error {
 				}
 			}
 			if !hasLabel {
-				continue
+				return false
 			}
 		}
 
@@ -237,21 +293,21 @@ func (a *App) List(ctx context.Context, opts ListOptions) error {
 				}
 			}
 			if !hasAssignee {
-				continue
+				return false
 			}
 		}
 
 		// Author filter from opts
 		if opts.Author != "" {
 			if !strings.EqualFold(opts.Author, item.Issue.Author) {
-				continue
+				return false
 			}
 		}
 
 		// Milestone filter from opts
 		if opts.Milestone != "" {
 			if !strings.EqualFold(opts.Milestone, item.Issue.Milestone) {
-				continue
+				return false
 			}
 		}
 
@@ -259,72 +315,64 @@ func (a *App) List(ctx context.Context, opts ListOptions) error {
 		if opts.Mention != "" {
 			mention := "@" + opts.Mention
 			if !strings.Contains(strings.ToLower(item.Issue.Body), strings.ToLower(mention)) {
-				continue
+				return false
 			}
 		}
 
 		// Apply search query filters
 		if searchQuery != nil {
-			var syncedAt, createdAt, updatedAt *int64
-			if item.Issue.SyncedAt != nil {
-				ts := item.Issue.SyncedAt.Unix()
-				syncedAt = &ts
-			}
-			if item.Issue.CreatedAt != nil {
-				ts := item.Issue.CreatedAt.Unix()
-				createdAt = &ts
-			}
-			if item.Issue.UpdatedAt != nil {
-				ts := item.Issue.UpdatedAt.Unix()
-				updatedAt = &ts
-			}
-			issueData := search.IssueData{
-				Number:    item.Issue.Number,
-				Title:     item.Issue.Title,
-				Body:      item.Issue.Body,
-				State:     item.State,
-				Labels:    item.Issue.Labels,
-				Assignees: item.Issue.Assignees,
-				Author:    item.Issue.Author,
-				Milestone: item.Issue.Milestone,
-				IssueType: item.Issue.IssueType,
-				Projects:  item.Issue.Projects,
-				SyncedAt:  syncedAt,
-				CreatedAt: createdAt,
-				UpdatedAt: updatedAt,
-			}
+			issueData := searchDataFor(p.Format, item)
 			// Skip state check in Match since we already handled it above
 			queryForMatch := *searchQuery
 			queryForMatch.State = ""
 			if !queryForMatch.Match(issueData) {
-				continue
+				return false
 			}
 		}
 
-		filtered = append(filtered, item)
+		return true
+	}
+
+	// Stream the mirror and keep only the matching issues, plus what the
+	// sub-issue rollups need. Bodies are only read if a filter looks at them.
+	needsBody := opts.Mention != "" || opts.Modified || (searchQuery != nil && searchQuery.NeedsBody())
+	var filtered, rollupItems []IssueFile
+	walkLocalIssues(p, !needsBody, func(item IssueFile) {
+		rollupItems = append(rollupItems, IssueFile{State: item.State, Issue: issue.Issue{
+			Number:    item.Issue.Number,
+			Parent:    item.Issue.Parent,
+			SubIssues: item.Issue.SubIssues,
+		}})
+		if matches(item) {
+			filtered = append(filtered, item)
+		}
+	}, func(parseErr ParseError) {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
+	})
+	if searchQuery != nil && searchQuery.RegexTimedOut() {
+		fmt.Fprintf(a.Err, "%s regex search stopped after %s, results are incomplete\n", t.WarningText("Warning:"), search.RegexTimeout)
+	}
+	rollups := subIssueRollups(rollupItems)
+
+	// Epics filter: only issues with sub-issues
+	if opts.Epics {
+		epics := filtered[:0]
+		for _, item := range filtered {
+			if _, ok := rollups[item.Issue.Number.String()]; ok {
+				epics = append(epics, item)
+			}
+		}
+		filtered = epics
 	}
 
 	// Sort based on search query or default
-	if searchQuery != nil && searchQuery.SortField != "" {
+	if sortQuery != nil && (sortQuery.SortField != "" || len(sortQuery.SortKeys) > 0) {
 		// Convert to IssueData for sorting
 		issueDataList := make([]search.IssueData, len(filtered))
 		for i, item := range filtered {
-			var createdAt, updatedAt *int64
-			if item.Issue.CreatedAt != nil {
-				ts := item.Issue.CreatedAt.Unix()
-				createdAt = &ts
-			}
-			if item.Issue.UpdatedAt != nil {
-				ts := item.Issue.UpdatedAt.Unix()
-				updatedAt = &ts
-			}
-			issueDataList[i] = search.IssueData{
-				Number:    item.Issue.Number,
-				CreatedAt: createdAt,
-				UpdatedAt: updatedAt,
-			}
+			issueDataList[i] = searchDataFor(p.Format, item)
 		}
-		searchQuery.Sort(issueDataList)
+		sortQuery.Sort(issueDataList)
 
 		// Reorder filtered based on sorted issueDataList
 		numberToIndex := make(map[string]int)
@@ -348,14 +396,23 @@ func (a *App) List(ctx context.Context, opts ListOptions) error {
 		})
 	}
 
+	// Facets count every match, not only the ones within the limit
+	var facets []search.Facet
+	if len(facetFields) > 0 {
+		issueDataList := make([]search.IssueData, len(filtered))
+		for i, item := range filtered {
+			issueDataList[i] = searchDataFor(p.Format, item)
+		}
+		facets = search.Facets(issueDataList, facetFields)
+	}
+
 	// Apply limit
 	if opts.Limit > 0 && len(filtered) > opts.Limit {
 		filtered = filtered[:opts.Limit]
 	}
 
 	if len(filtered) == 0 {
-		fmt.Fprintln(a.Out, t.MutedText("No issues found"))
-		return nil
+		return 0, nil
 	}
 
 	// Load pending comments for display
@@ -363,13 +420,51 @@ func (a *App) List(ctx context.Context, opts ListOptions) error {
 
 	// Format and print
 	for _, item := range filtered {
-		a.printIssueLine(item, labelColors, pendingComments)
+		if !needsBody {
+			// The task progress needs the body, read now one issue at a time
+			if full, err := readIssue(p, item.Path); err == nil {
+				item.Issue.Body = full.Body
+			}
+		}
+		a.printIssueLine(p.Format, item, labelColors, pendingComments, rollups, opts.PRs)
+	}
+	for _, facet := range facets {
+		fmt.Fprintln(a.Out)
+		a.printFacet(facet)
 	}
 
-	return nil
+	return len(filtered), nil
 }
 
-func (a *App) printIssueLine(item IssueFile, labelColors map[string]string, pendingComments map[string]PendingComment) {
+// facetBarWidth is the length of the bar of the most frequent facet value.
+const facetBarWidth = 30
+
+// printFacet prints the counts of a facet as a histogram, with the issues
+// without a value last.
+func (a *App) printFacet(facet search.Facet) {
+	t := a.Theme
+	fmt.Fprintln(a.Out, t.Bold(facet.Field))
+	rows := facet.Values
+	if facet.None > 0 {
+		rows = append(slices.Clone(rows), search.FacetValue{Value: "(none)", Count: facet.None})
+	}
+	nameWidth, countWidth, most := 0, 0, 0
+	for _, row := range rows {
+		nameWidth = max(nameWidth, len(row.Value))
+		countWidth = max(countWidth, len(strconv.Itoa(row.Count)))
+		most = max(most, row.Count)
+	}
+	for i, row := range rows {
+		name := padRight(row.Value, nameWidth)
+		if i == len(facet.Values) {
+			name = t.MutedText(name)
+		}
+		bar := strings.Repeat("█", max(1, row.Count*facetBarWidth/most))
+		fmt.Fprintf(a.Out, "  %s  %*d  %s\n", name, countWidth, row.Count, t.AccentText(bar))
+	}
+}
+
+func (a *App) printIssueLine(format issue.Format, item IssueFile, labelColors map[string]string, pendingComments map[string]PendingComment, rollups map[string]issue.SubIssueSummary, showPRs bool) {
 	t := a.Theme
 	iss := item.Issue
 	termWidth := getTerminalWidth(a.Out)
@@ -427,9 +522,12 @@ func (a *App) printIssueLine(item IssueFile, labelColors map[string]string, pend
 		line2Parts = append(line2Parts, t.MutedText(relTime))
 	}
 
-	// Labels
+	// Priority and labels
+	if priority := format.Priority(iss); priority != "" {
+		line2Parts = append(line2Parts, a.formatPriority(format, priority))
+	}
 	var labelStrs []string
-	for _, label := range iss.Labels {
+	for _, label := range withoutPriorityLabels(format, iss.Labels) {
 		color := labelColors[strings.ToLower(label)]
 		if color != "" {
 			labelStrs = append(labelStrs, t.FormatLabel(label, color))
@@ -441,6 +539,32 @@ func (a *App) printIssueLine(item IssueFile, labelColors map[string]string, pend
 		line2Parts = append(line2Parts, strings.Join(labelStrs, " "))
 	}
 
+	// Checklist progress
+	if done, total := issue.TaskProgress(iss.Body); total > 0 {
+		line2Parts = append(line2Parts, t.MutedText(fmt.Sprintf("tasks %d/%d", done, total)))
+	}
+
+	// Sub-issue progress
+	if rollup, ok := rollups[iss.Number.String()]; ok {
+		if rollup.Completed == rollup.Total {
+			line2Parts = append(line2Parts, t.SuccessText(formatRollup(rollup)))
+		} else {
+			line2Parts = append(line2Parts, t.AccentText(formatRollup(rollup)))
+		}
+	}
+
+	// Local workflow state
+	if iss.LocalState != "" {
+		line2Parts = append(line2Parts, t.WarningText(iss.LocalState))
+	}
+
+	// Linked pull requests
+	if showPRs {
+		for _, pr := range iss.PullRequests {
+			line2Parts = append(line2Parts, a.formatLinkedPR(pr, false))
+		}
+	}
+
 	// Check for pending comment
 	if pendingComments != nil {
 		if _, hasComment := pendingComments[iss.Number.String()]; hasComment {
@@ -459,43 +583,94 @@ func (a *App) printIssueLine(item IssueFile, labelColors map[string]string, pend
 }
 
 func (a *App) NewIssue(ctx context.Context, title string, opts NewOptions) error {
-	p := paths.New(a.Root)
-	if _, err := loadConfig(p.ConfigPath); err != nil {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
 		return err
 	}
 
-	if strings.TrimSpace(title) == "" && !opts.Edit {
+	if opts.Edit && opts.NoEdit {
+		return fmt.Errorf("--edit and --no-edit are mutually exclusive")
+	}
+	if opts.FromPR != "" && opts.FromCommit != "" {
+		return fmt.Errorf("--from-pr and --from-commit are mutually exclusive")
+	}
+	fromChange := opts.FromPR != "" || opts.FromCommit != ""
+	if strings.TrimSpace(title) == "" && !opts.Edit && !opts.NoEdit && !fromChange {
 		return fmt.Errorf("title is required (provide a title or use --edit)")
 	}
 
+	var seed issue.Issue
+	switch {
+	case opts.FromPR != "":
+		seed, err = a.issueFromPR(ctx, cfg, opts.FromPR)
+	case opts.FromCommit != "":
+		seed, err = a.issueFromCommit(ctx, cfg, opts.FromCommit)
+	}
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(title) == "" {
+		title = seed.Title
+	}
+
 	// Acquire lock
-	lck, err := lock.Acquire(p.SyncDir, lock.DefaultTimeout)
+	lck, err := a.lockMirror(p)
 	if err != nil {
 		return err
 	}
 	defer lck.Release()
 
-	// Generate a random local ID
-	id, err := localid.Generate()
+	// Allocate a local ID
+	localNumber, err := a.newLocalNumber(p)
 	if err != nil {
-		return fmt.Errorf("failed to generate local ID: %w", err)
+		return err
 	}
 
-	localNumber := issue.IssueNumber(fmt.Sprintf("T%s", id))
-	var newIssue issue.Issue
-	if strings.TrimSpace(title) == "" && opts.Edit {
-		edited, err := issueFromEditor(ctx, localNumber, opts.Labels)
+	newIssue := issue.Issue{
+		Number:    localNumber,
+		Title:     strings.TrimSpace(title),
+		Labels:    opts.Labels,
+		IssueType: opts.Type,
+		State:     "open",
+		Body:      seed.Body,
+	}
+	for _, label := range seed.Labels {
+		if !containsFold(newIssue.Labels, label) {
+			newIssue.Labels = append(newIssue.Labels, label)
+		}
+	}
+	if err := applyTemplate(p, &newIssue, a.templateVars(ctx, cfg)); err != nil {
+		return err
+	}
+	switch {
+	case opts.Edit:
+		if err := a.offerRecovery(p.NewIssuePath); err != nil {
+			return err
+		}
+		edited, err := issueFromEditor(ctx, p, newIssue)
+		if errors.Is(err, errIssueAborted) {
+			fmt.Fprintf(a.Out, "%s\n", a.Theme.MutedText("Aborting issue creation due to an empty or unchanged buffer"))
+			return nil
+		}
 		if err != nil {
 			return err
 		}
 		newIssue = edited
-	} else {
-		newIssue = issue.Issue{
-			Number: localNumber,
-			Title:  strings.TrimSpace(title),
-			Labels: opts.Labels,
-			State:  "open",
-			Body:   "",
+	case opts.NoEdit:
+		saved, err := os.ReadFile(p.NewIssuePath)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no buffer to create the issue from (it is kept when new --edit fails)")
+		}
+		if err != nil {
+			return err
+		}
+		newIssue, err = parseIssueBuffer(p.Format, saved, localNumber)
+		if errors.Is(err, errIssueAborted) {
+			return fmt.Errorf("the saved buffer is empty")
+		}
+		if err != nil {
+			return err
 		}
 	}
 	newIssue.Number = localNumber
@@ -506,53 +681,84 @@ func (a *App) NewIssue(ctx context.Context, title string, opts NewOptions) error
 		newIssue.State = "open"
 	}
 
-	path := issue.PathFor(p.OpenDir, localNumber, newIssue.Title)
-	if err := issue.WriteFile(path, newIssue); err != nil {
-		return err
-	}
-	if opts.Edit && strings.TrimSpace(title) != "" {
-		if err := openEditor(ctx, path); err != nil {
+	dir := issueDir(p, "open", newIssue)
+	if opts.Draft {
+		newIssue.Draft = true
+		dir = p.DraftsDir
+		if err := os.MkdirAll(dir, 0o755); err != nil {
 			return err
 		}
-		updatedPath, err := finalizeEditedIssue(path, localNumber)
-		if err != nil {
+	}
+	path := issue.PathFor(dir, localNumber, newIssue.Title)
+	newIssue.LastLocalEditBy = a.localEditor(ctx)
+	if err := writeIssue(p, path, newIssue); err != nil {
+		return err
+	}
+	if opts.Edit || opts.NoEdit {
+		if err := os.Remove(p.NewIssuePath); err != nil && !errors.Is(err, os.ErrNotExist) {
 			return err
 		}
-		path = updatedPath
 	}
 	fmt.Fprintf(a.Out, "%s %s\n", a.Theme.SuccessText("Created"), relPath(a.Root, path))
 	return nil
 }
 
-func issueFromEditor(ctx context.Context, number issue.IssueNumber, labels []string) (issue.Issue, error) {
-	tempFile, err := os.CreateTemp("", "gh-issue-sync-issue-*.md")
+// errIssueAborted is returned by issueFromEditor when the buffer was left
+// unchanged or emptied, like git aborts a commit with an empty message.
+var errIssueAborted = errors.New("issue creation aborted")
+
+// issueFromEditor lets the user write a new issue starting from template.
+// The buffer starts with a commented help header, which the YAML parser
+// ignores and so is dropped when the issue is written. The buffer lives in
+// p.NewIssuePath until the issue is created, so neither a failed parse nor
+// a crash loses it; an existing buffer is edited instead of the template.
+func issueFromEditor(ctx context.Context, p paths.Paths, template issue.Issue) (issue.Issue, error) {
+	rendered, err := p.Format.Render(template)
 	if err != nil {
 		return issue.Issue{}, err
 	}
-	tempPath := tempFile.Name()
-	if err := tempFile.Close(); err != nil {
+	initial := injectEditorHelp(rendered, editorHelp(p))
+	if _, err := os.Stat(p.NewIssuePath); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(p.RecoveryDir, 0o755); err != nil {
+			return issue.Issue{}, err
+		}
+		if err := os.WriteFile(p.NewIssuePath, []byte(initial), 0o644); err != nil {
+			return issue.Issue{}, err
+		}
+	}
+	editorErr := openEditor(ctx, p, "new", p.NewIssuePath)
+	edited, err := os.ReadFile(p.NewIssuePath)
+	if err != nil {
 		return issue.Issue{}, err
 	}
-	defer os.Remove(tempPath)
-
-	template := issue.Issue{
-		Number: number,
-		Title:  "",
-		Labels: labels,
-		State:  "open",
-		Body:   "",
+	if string(edited) == initial || strings.TrimSpace(string(edited)) == "" {
+		os.Remove(p.NewIssuePath)
+		return issue.Issue{}, errIssueAborted
 	}
-	if err := issue.WriteFile(tempPath, template); err != nil {
-		return issue.Issue{}, err
+	if editorErr != nil {
+		return issue.Issue{}, keptBufferError(p, p.NewIssuePath, editorErr)
 	}
-	if err := openEditor(ctx, tempPath); err != nil {
+	result, err := parseIssueBuffer(p.Format, edited, template.Number)
+	if errors.Is(err, errIssueAborted) {
+		os.Remove(p.NewIssuePath)
 		return issue.Issue{}, err
 	}
-	edited, err := issue.ParseFile(tempPath)
+	if err != nil {
+		return issue.Issue{}, keptBufferError(p, p.NewIssuePath, err)
+	}
+	return result, nil
+}
+
+// parseIssueBuffer turns an edited buffer into a new issue.
+func parseIssueBuffer(format issue.Format, data []byte, number issue.IssueNumber) (issue.Issue, error) {
+	edited, err := format.Parse(data)
 	if err != nil {
 		return issue.Issue{}, err
 	}
 	edited.Title = strings.TrimSpace(edited.Title)
+	if edited.Title == "" && strings.TrimSpace(edited.Body) == "" {
+		return issue.Issue{}, errIssueAborted
+	}
 	if edited.Title == "" {
 		return issue.Issue{}, fmt.Errorf("title is required (set it in the editor)")
 	}
@@ -563,8 +769,44 @@ func issueFromEditor(ctx context.Context, number issue.IssueNumber, labels []str
 	return edited, nil
 }
 
-func finalizeEditedIssue(path string, number issue.IssueNumber) (string, error) {
-	edited, err := issue.ParseFile(path)
+// keptBufferError explains where an editor buffer that could not be used
+// was kept.
+func keptBufferError(p paths.Paths, buffer string, cause error) error {
+	hint := "run the command again to recover it"
+	if buffer == p.NewIssuePath {
+		hint = "run new --edit to fix it or new --no-edit to retry"
+	}
+	return fmt.Errorf("%w (buffer kept in %s; %s)", cause, relPath(p.Root, buffer), hint)
+}
+
+// offerRecovery asks whether to recover an editor buffer left behind by an
+// earlier run, and discards it if not. It defaults to recovering.
+func (a *App) offerRecovery(buffer string) error {
+	info, err := os.Stat(buffer)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	in := a.In
+	if in == nil {
+		in = os.Stdin
+	}
+	fmt.Fprintf(a.Out, "%s %s %s ", t.WarningText("Found an unsaved buffer from"),
+		info.ModTime().Format("2006-01-02 15:04"), t.AccentText("Recover it? [Y/n]"))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	fmt.Fprintln(a.Out)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "n" || answer == "no" {
+		return os.Remove(buffer)
+	}
+	return nil
+}
+
+func finalizeEditedIssue(p paths.Paths, path string, number issue.IssueNumber) (string, error) {
+	edited, err := readIssue(p, path)
 	if err != nil {
 		return path, err
 	}
@@ -577,13 +819,13 @@ func finalizeEditedIssue(path string, number issue.IssueNumber) (string, error)
 	}
 	if edited.Number != number {
 		edited.Number = number
-		if err := issue.WriteFile(path, edited); err != nil {
+		if err := writeIssue(p, path, edited); err != nil {
 			return path, err
 		}
 	}
 	newPath := issue.PathFor(filepath.Dir(path), number, edited.Title)
 	if path != newPath {
-		if err := os.Rename(path, newPath); err != nil {
+		if err := moveIssue(p, path, newPath); err != nil {
 			return path, err
 		}
 		return newPath, nil
@@ -592,10 +834,17 @@ func finalizeEditedIssue(path string, number issue.IssueNumber) (string, error)
 }
 
 func (a *App) Close(ctx context.Context, number string, opts CloseOptions) error {
-	p := paths.New(a.Root)
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if err := a.checkWritable(cfg, "close"); err != nil {
+		return err
+	}
 
 	// Acquire lock
-	lck, err := lock.Acquire(p.SyncDir, lock.DefaultTimeout)
+	lck, err := a.lockMirror(p)
 	if err != nil {
 		return err
 	}
@@ -608,6 +857,15 @@ func (a *App) Close(ctx context.Context, number string, opts CloseOptions) error
 	if file.State == "closed" {
 		return nil
 	}
+	if allIssues, err := loadLocalIssues(p); err == nil {
+		reasons := openDependencies(file.Issue, allIssues)
+		for _, r := range reasons {
+			fmt.Fprintf(a.Err, "%s #%s %s\n", a.Theme.WarningText("Warning:"), file.Issue.Number, r)
+		}
+		if len(reasons) > 0 && opts.Strict {
+			return fmt.Errorf("refusing to close #%s with open dependencies", file.Issue.Number)
+		}
+	}
 	reason := strings.TrimSpace(opts.Reason)
 	var reasonPtr *string
 	if reason != "" {
@@ -615,22 +873,30 @@ func (a *App) Close(ctx context.Context, number string, opts CloseOptions) error
 	}
 	file.Issue.State = "closed"
 	file.Issue.StateReason = reasonPtr
-	newPath := issue.PathFor(p.ClosedDir, file.Issue.Number, file.Issue.Title)
-	if err := os.Rename(file.Path, newPath); err != nil {
+	newPath := issue.PathFor(issueDir(p, "closed", file.Issue), file.Issue.Number, file.Issue.Title)
+	if err := moveIssue(p, file.Path, newPath); err != nil {
 		return err
 	}
 	file.Path = newPath
-	if err := issue.WriteFile(file.Path, file.Issue); err != nil {
+	file.Issue.LastLocalEditBy = a.localEditor(ctx)
+	if err := writeIssue(p, file.Path, file.Issue); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (a *App) Reopen(ctx context.Context, number string) error {
-	p := paths.New(a.Root)
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if err := a.checkWritable(cfg, "reopen"); err != nil {
+		return err
+	}
 
 	// Acquire lock
-	lck, err := lock.Acquire(p.SyncDir, lock.DefaultTimeout)
+	lck, err := a.lockMirror(p)
 	if err != nil {
 		return err
 	}
@@ -645,33 +911,54 @@ func (a *App) Reopen(ctx context.Context, number string) error {
 	}
 	file.Issue.State = "open"
 	file.Issue.StateReason = nil
-	newPath := issue.PathFor(p.OpenDir, file.Issue.Number, file.Issue.Title)
-	if err := os.Rename(file.Path, newPath); err != nil {
+	newPath := issue.PathFor(issueDir(p, "open", file.Issue), file.Issue.Number, file.Issue.Title)
+	if err := moveIssue(p, file.Path, newPath); err != nil {
 		return err
 	}
 	file.Path = newPath
-	if err := issue.WriteFile(file.Path, file.Issue); err != nil {
+	file.Issue.LastLocalEditBy = a.localEditor(ctx)
+	if err := writeIssue(p, file.Path, file.Issue); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (a *App) Edit(ctx context.Context, number string) error {
-	p := paths.New(a.Root)
+func (a *App) Edit(ctx context.Context, number string, opts EditOptions) error {
+	p := a.issuePaths()
 	file, err := findIssueByNumber(p, number)
 	if err != nil {
 		return err
 	}
 
-	if err := openEditor(ctx, file.Path); err != nil {
+	if err := a.offerRecovery(recoveryBufferFor(p, file.Path)); err != nil {
+		return err
+	}
+	if opts.WithComments && !file.Issue.Number.IsLocal() {
+		// The comments are appended for reading and stripped again below
+		block := a.commentContext(a.editComments(ctx, p, file.Issue.Number.String()), editContextComments)
+		if block != "" {
+			withComments := file.Issue
+			withComments.Body = withCommentContext(withComments.Body, block)
+			if err := writeIssue(p, file.Path, withComments); err != nil {
+				return err
+			}
+		}
+	}
+	if err := editIssue(ctx, p, "edit", file.Path); err != nil {
+		if opts.WithComments {
+			if restoreErr := writeIssue(p, file.Path, file.Issue); restoreErr != nil {
+				return errors.Join(err, restoreErr)
+			}
+		}
 		return err
 	}
 
 	// After editing, re-read and handle title changes (file may need renaming)
-	edited, err := issue.ParseFile(file.Path)
+	edited, err := readIssue(p, file.Path)
 	if err != nil {
 		return err
 	}
+	edited.Body = issue.StripCommentContext(edited.Body)
 
 	// Validate the issue number wasn't changed
 	if edited.Number != "" && edited.Number != file.Issue.Number {
@@ -684,9 +971,21 @@ func (a *App) Edit(ctx context.Context, number string) error {
 		return fmt.Errorf("title is required")
 	}
 
-	newPath := issue.PathFor(dirForState(p, file.State), file.Issue.Number, edited.Title)
+	if !issue.EqualIgnoringSyncedAt(edited, file.Issue) {
+		edited.LastLocalEditBy = a.localEditor(ctx)
+		if err := writeIssue(p, file.Path, edited); err != nil {
+			return err
+		}
+	} else if opts.WithComments {
+		// Unchanged apart from the comments, which must not stay behind
+		if err := writeIssue(p, file.Path, file.Issue); err != nil {
+			return err
+		}
+	}
+
+	newPath := issue.PathFor(issueDir(p, file.State, edited), file.Issue.Number, edited.Title)
 	if file.Path != newPath {
-		if err := os.Rename(file.Path, newPath); err != nil {
+		if err := moveIssue(p, file.Path, newPath); err != nil {
 			return err
 		}
 	}
@@ -695,7 +994,7 @@ func (a *App) Edit(ctx context.Context, number string) error {
 }
 
 func (a *App) View(ctx context.Context, ref string, opts ViewOptions) error {
-	p := paths.New(a.Root)
+	p := a.issuePaths()
 
 	file, err := findIssueByRef(a.Root, p, ref)
 	if err != nil {
@@ -703,7 +1002,7 @@ func (a *App) View(ctx context.Context, ref string, opts ViewOptions) error {
 	}
 
 	if opts.Raw {
-		content, err := os.ReadFile(file.Path)
+		content, err := readIssueData(p, file.Path)
 		if err != nil {
 			return err
 		}
@@ -717,19 +1016,36 @@ func (a *App) View(ctx context.Context, ref string, opts ViewOptions) error {
 	// Title
 	fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("title:"), t.Bold(iss.Title))
 
+	// Thread summary from summarize
+	if iss.Summary != "" {
+		fmt.Fprintln(a.Out, t.MutedText("summary:"))
+		for _, line := range strings.Split(iss.Summary, "\n") {
+			fmt.Fprintf(a.Out, "\t%s\n", line)
+		}
+	}
+
 	// State
 	stateText := strings.ToUpper(iss.State)
 	if iss.StateReason != nil && *iss.StateReason != "" {
 		stateText = fmt.Sprintf("%s (%s)", stateText, *iss.StateReason)
 	}
 	fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("state:"), stateText)
+	if iss.LocalState != "" {
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("local state:"), t.WarningText(iss.LocalState))
+	}
+	if iss.ClosedBy != 0 {
+		fmt.Fprintf(a.Out, "%s\t#%d\n", t.MutedText("closed by:"), iss.ClosedBy)
+	}
 
 	// Number
 	fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("number:"), iss.Number.String())
 
-	// Labels
-	if len(iss.Labels) > 0 {
-		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("labels:"), strings.Join(iss.Labels, ", "))
+	// Priority and labels
+	if priority := p.Format.Priority(iss); priority != "" {
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("priority:"), a.formatPriority(p.Format, priority))
+	}
+	if labels := withoutPriorityLabels(p.Format, iss.Labels); len(labels) > 0 {
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("labels:"), strings.Join(labels, ", "))
 	}
 
 	// Assignees
@@ -775,24 +1091,111 @@ func (a *App) View(ctx context.Context, ref string, opts ViewOptions) error {
 		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("blocks:"), strings.Join(refs, ", "))
 	}
 
+	// Development: linked branches and pull requests
+	if len(iss.Branches) > 0 {
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("branches:"), strings.Join(iss.Branches, ", "))
+	}
+	if len(iss.PullRequests) > 0 {
+		fmt.Fprintln(a.Out, t.MutedText("pull requests:"))
+		for _, pr := range iss.PullRequests {
+			fmt.Fprintf(a.Out, "\t%s\n", a.formatLinkedPR(pr, true))
+		}
+	}
+
+	// Code references
+	if len(iss.CodeRefs) > 0 {
+		fmt.Fprintln(a.Out, t.MutedText("code:"))
+		cfg, cfgErr := loadConfig(p.ConfigPath)
+		for _, raw := range iss.CodeRefs {
+			ref, err := parseCodeRef(raw)
+			if err != nil || cfgErr != nil || ref.URL != "" {
+				fmt.Fprintf(a.Out, "\t%s\n", raw)
+				continue
+			}
+			label := ref.location()
+			if ref.Commit != "" {
+				label += "@" + shortSHA(ref.Commit)
+			}
+			url := ref.permalink(cfg)
+			fmt.Fprintf(a.Out, "\t%s %s\n", t.Link(url, label), t.MutedText(url))
+		}
+	}
+
+	// Checklist progress
+	if done, total := issue.TaskProgress(iss.Body); total > 0 {
+		fmt.Fprintf(a.Out, "%s\t%d/%d\n", t.MutedText("tasks:"), done, total)
+	}
+
+	// Sub-issue progress and backlinks
+	allIssues, allErr := loadLocalIssues(p)
+	if allErr == nil {
+		if rollup, ok := subIssueRollups(allIssues)[iss.Number.String()]; ok {
+			fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("sub-issues:"), formatRollup(rollup))
+		}
+		if refs := backlinks(iss, allIssues); len(refs) > 0 {
+			titles := make(map[string]string, len(allIssues))
+			for _, item := range allIssues {
+				titles[item.Issue.Number.String()] = item.Issue.Title
+			}
+			fmt.Fprintln(a.Out, t.MutedText("referenced by:"))
+			for _, ref := range refs {
+				if title, ok := titles[ref]; ok {
+					fmt.Fprintf(a.Out, "\t#%s %s\n", ref, title)
+				} else if strings.Contains(ref, "#") {
+					fmt.Fprintf(a.Out, "\t%s\n", ref)
+				} else {
+					fmt.Fprintf(a.Out, "\t#%s\n", ref)
+				}
+			}
+		}
+		if links := wikiLinkPaths(iss.Body, allIssues); len(links) > 0 {
+			fmt.Fprintln(a.Out, t.MutedText("links:"))
+			for _, link := range links {
+				if link.Path == "" {
+					fmt.Fprintf(a.Out, "\t[[#%s]] %s\n", link.Ref, t.MutedText("(no local file)"))
+					continue
+				}
+				fmt.Fprintf(a.Out, "\t[[#%s]] %s\n", link.Ref, relPath(p.Root, link.Path))
+			}
+		}
+	}
+
+	// Mentioned teams
+	a.printTeamMentions(ctx, p, iss.Body, opts.Teams)
+
 	// Synced at with relative time
 	if iss.SyncedAt != nil {
 		relTime := formatRelativeTime(a.Now(), *iss.SyncedAt)
 		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("synced:"), relTime)
 	}
 
-	// Separator and body
+	// Separator and body, with the titles of referenced issues
 	fmt.Fprintln(a.Out, "--")
 	if strings.TrimSpace(iss.Body) != "" {
-		rendered, err := renderMarkdown(iss.Body)
+		body := iss.Body
+		if allErr == nil {
+			titles := make(map[string]string, len(allIssues))
+			for _, item := range allIssues {
+				titles[item.Issue.Number.String()] = item.Issue.Title
+			}
+			body = annotateReferences(body, titles)
+		}
+		if cache, err := loadAutolinkCache(p); err == nil {
+			body = linkAutolinks(body, cache.Autolinks)
+		}
+		rendered, err := renderMarkdown(body)
 		if err != nil {
 			// Fall back to plain text on error
-			fmt.Fprintln(a.Out, iss.Body)
+			fmt.Fprintln(a.Out, body)
 		} else {
 			fmt.Fprint(a.Out, rendered)
 		}
 	}
 
+	if !iss.Number.IsLocal() {
+		a.printComments(p, iss.Number.String())
+	}
+
 	// Check for pending comment
 	if comment, found := findPendingCommentForIssue(p, iss.Number, file.State); found {
 		fmt.Fprintln(a.Out)
@@ -821,7 +1224,7 @@ func renderMarkdown(text string) (string, error) {
 }
 
 func (a *App) DiffAll(ctx context.Context, opts DiffOptions) error {
-	p := paths.New(a.Root)
+	p := a.issuePaths()
 	cfg, err := loadConfig(p.ConfigPath)
 	if err != nil {
 		return err
@@ -843,9 +1246,12 @@ func (a *App) DiffAll(ctx context.Context, opts DiffOptions) error {
 		return nil
 	}
 
-	var client *ghcli.Client
+	var client ghcli.Provider
 	if opts.Remote {
-		client = ghcli.NewClient(a.Runner, repoSlug(cfg))
+		client, err = a.newProvider(cfg)
+		if err != nil {
+			return err
+		}
 	}
 
 	count := 0
@@ -924,7 +1330,7 @@ func (a *App) DiffAll(ctx context.Context, opts DiffOptions) error {
 }
 
 func (a *App) Diff(ctx context.Context, number string, opts DiffOptions) error {
-	p := paths.New(a.Root)
+	p := a.issuePaths()
 	cfg, err := loadConfig(p.ConfigPath)
 	if err != nil {
 		return err
@@ -944,11 +1350,28 @@ func (a *App) Diff(ctx context.Context, number string, opts DiffOptions) error {
 	var base issue.Issue
 	var baseLabel string
 
-	if opts.Remote {
+	if opts.At != "" {
+		if local.Number.IsLocal() {
+			return fmt.Errorf("local issue %s has no snapshots (not yet pushed)", local.Number)
+		}
+		at, err := parseSnapshotTime(opts.At)
+		if err != nil {
+			return err
+		}
+		snap, taken, err := snapshotAt(p, local.Number.String(), at)
+		if err != nil {
+			return err
+		}
+		base = snap
+		baseLabel = "snapshot " + taken.At.Format(snapshotTimeFormat)
+	} else if opts.Remote {
 		if local.Number.IsLocal() {
 			return fmt.Errorf("cannot diff local issue %s against remote (not yet pushed)", local.Number)
 		}
-		client := ghcli.NewClient(a.Runner, repoSlug(cfg))
+		client, err := a.newProvider(cfg)
+		if err != nil {
+			return err
+		}
 		remote, err := client.GetIssue(ctx, local.Number.String())
 		if err != nil {
 			return err
@@ -984,7 +1407,7 @@ func (a *App) Diff(ctx context.Context, number string, opts DiffOptions) error {
 	}
 
 	status := "M"
-	if local.Number.IsLocal() && !opts.Remote {
+	if local.Number.IsLocal() && !opts.Remote && opts.At == "" {
 		status = "A"
 	}
 
@@ -1019,8 +1442,15 @@ func (a *App) Diff(ctx context.Context, number string, opts DiffOptions) error {
 	return nil
 }
 
-func openEditor(ctx context.Context, path string) error {
-	editor := getEditor(ctx)
+// openEditor opens path in the editor configured for command, see getEditor.
+func openEditor(ctx context.Context, p paths.Paths, command, path string) error {
+	editor := ""
+	if cfg, err := loadConfig(p.ConfigPath); err == nil {
+		editor = cfg.Editor.For(command)
+	}
+	if editor == "" {
+		editor = getEditor(ctx)
+	}
 	if editor == "" {
 		return fmt.Errorf("no editor configured (set $VISUAL, $EDITOR, or git core.editor)")
 	}
@@ -1028,7 +1458,8 @@ func openEditor(ctx context.Context, path string) error {
 }
 
 // getEditor returns the preferred editor command following the precedence:
-// $VISUAL > $EDITOR > git config core.editor > "vi"
+// $VISUAL > $EDITOR > git config core.editor > "vi". The editor in the config
+// takes precedence over all of them.
 func getEditor(ctx context.Context) string {
 	if v := os.Getenv("VISUAL"); v != "" {
 		return v
@@ -1077,3 +1508,10 @@ var execCommand = func(ctx context.Context, name string, args ...string) (string
 	runner := ghcli.ExecRunner{}
 	return runner.Run(ctx, name, args...)
 }
+
+// execCommandEnv is execCommand with additional KEY=value environment
+// variables.
+var execCommandEnv = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+	runner := ghcli.ExecRunner{Env: env}
+	return runner.Run(ctx, name, args...)
+}
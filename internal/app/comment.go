@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
@@ -18,6 +19,7 @@ type PendingComment struct {
 	IssueNumber issue.IssueNumber
 	Body        string
 	Path        string
+	ReplyTo     string // ID of the comment this replies to, if any
 }
 
 // findPendingComment looks for a pending comment file for the given issue number in the given directory.
@@ -32,6 +34,7 @@ func findPendingComment(dir string, number issue.IssueNumber) (PendingComment, b
 			IssueNumber: number,
 			Body:        strings.TrimSpace(string(content)),
 			Path:        preferredPath,
+			ReplyTo:     replyTo(string(content)),
 		}, true
 	}
 
@@ -52,35 +55,32 @@ func findPendingComment(dir string, number issue.IssueNumber) (PendingComment, b
 		IssueNumber: number,
 		Body:        strings.TrimSpace(string(content)),
 		Path:        matches[0],
+		ReplyTo:     replyTo(string(content)),
 	}, true
 }
 
-// findPendingCommentForIssue finds a pending comment for an issue, checking both open and closed directories.
+// findPendingCommentForIssue finds a pending comment for an issue, checking the
+// directories of its state first and then the others.
 func findPendingCommentForIssue(p paths.Paths, number issue.IssueNumber, state string) (PendingComment, bool) {
-	// Check the directory matching the issue's state first
-	dir := p.OpenDir
-	if state == "closed" {
-		dir = p.ClosedDir
-	}
-
-	if comment, found := findPendingComment(dir, number); found {
-		return comment, true
-	}
-
-	// Also check the other directory in case state changed
-	otherDir := p.ClosedDir
-	if state == "closed" {
-		otherDir = p.OpenDir
+	dirs, _ := issueDirs(p, p.StateDirs())
+	// Check the directories matching the issue's state first, in case the
+	// state changed since the comment was written
+	sort.SliceStable(dirs, func(i, j int) bool { return dirs[i].State == state && dirs[j].State != state })
+	for _, dir := range dirs {
+		if comment, found := findPendingComment(dir.Path, number); found {
+			return comment, true
+		}
 	}
-
-	return findPendingComment(otherDir, number)
+	return PendingComment{}, false
 }
 
-// loadAllPendingComments scans both open and closed directories for pending comment files.
+// loadAllPendingComments scans the open and closed directories for pending comment files.
 func loadAllPendingComments(p paths.Paths) map[string]PendingComment {
 	comments := make(map[string]PendingComment)
 
-	for _, dir := range []string{p.OpenDir, p.ClosedDir} {
+	dirs, _ := issueDirs(p, p.StateDirs())
+	for _, stateDir := range dirs {
+		dir := stateDir.Path
 		entries, err := os.ReadDir(dir)
 		if err != nil {
 			continue
@@ -120,6 +120,7 @@ func loadAllPendingComments(p paths.Paths) map[string]PendingComment {
 					IssueNumber: number,
 					Body:        body,
 					Path:        path,
+					ReplyTo:     replyTo(body),
 				}
 			}
 		}
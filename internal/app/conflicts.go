@@ -0,0 +1,419 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// ConflictRecord is a conflict detected on pull or push, stored in
+// .issues/.sync/conflicts/<number>.json until it is resolved. The
+// snapshots are rendered issue files.
+type ConflictRecord struct {
+	Number     string    `json:"number"`
+	Title      string    `json:"title"`
+	Fields     []string  `json:"fields"`
+	Source     string    `json:"source"` // "pull" or "push"
+	DetectedAt time.Time `json:"detected_at"`
+	Base       string    `json:"base"`
+	Local      string    `json:"local"`
+	Remote     string    `json:"remote"`
+}
+
+type ResolveOptions struct {
+	Ours   bool // Keep the local value of every conflicting field
+	Theirs bool // Take the remote value of every conflicting field
+}
+
+func conflictPath(p paths.Paths, number string) string {
+	return filepath.Join(p.ConflictsDir, number+".json")
+}
+
+// recordConflict stores the snapshots of a conflict, replacing an earlier
+// record for the same issue.
+func recordConflict(p paths.Paths, source string, base, local, remote issue.Issue, fields []string, now time.Time) error {
+	record := ConflictRecord{
+		Number:     local.Number.String(),
+		Title:      local.Title,
+		Fields:     fields,
+		Source:     source,
+		DetectedAt: now,
+	}
+	for _, snap := range []struct {
+		dst *string
+		iss issue.Issue
+	}{{&record.Base, base}, {&record.Local, local}, {&record.Remote, remote}} {
+		rendered, err := issue.Render(snap.iss)
+		if err != nil {
+			return err
+		}
+		*snap.dst = rendered
+	}
+	if err := os.MkdirAll(p.ConflictsDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(conflictPath(p, record.Number), data, 0o644)
+}
+
+func loadConflict(p paths.Paths, number string) (ConflictRecord, error) {
+	var record ConflictRecord
+	data, err := os.ReadFile(conflictPath(p, number))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return record, fmt.Errorf("no recorded conflict for #%s", number)
+		}
+		return record, err
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+func loadConflicts(p paths.Paths) ([]ConflictRecord, error) {
+	entries, err := os.ReadDir(p.ConflictsDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []ConflictRecord
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		record, err := loadConflict(p, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Number < records[j].Number
+	})
+	return records, nil
+}
+
+// clearConflict drops the record for an issue that synced cleanly.
+func clearConflict(p paths.Paths, number string) {
+	_ = os.Remove(conflictPath(p, number))
+}
+
+// snapshots parses the base, local and remote versions of the record.
+func (r ConflictRecord) snapshots() (base, local, remote issue.Issue, err error) {
+	result := make([]issue.Issue, 3)
+	for i, text := range []string{r.Base, r.Local, r.Remote} {
+		parsed, err := issue.Parse([]byte(text))
+		if err != nil {
+			return base, local, remote, fmt.Errorf("conflict record for #%s: %w", r.Number, err)
+		}
+		parsed.Number = issue.IssueNumber(r.Number)
+		result[i] = parsed
+	}
+	return result[0], result[1], result[2], nil
+}
+
+// Conflicts lists the recorded conflicts.
+func (a *App) Conflicts(ctx context.Context) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	records, err := loadConflicts(p)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No conflicts"))
+		return nil
+	}
+	for _, r := range records {
+		fmt.Fprintf(a.Out, "%s %s %s\n", t.FormatIssueHeader("C", r.Number, r.Title),
+			t.MutedText("("+strings.Join(r.Fields, ", ")+")"),
+			t.MutedText(fmt.Sprintf("on %s, %s", r.Source, formatRelativeTime(a.Now(), r.DetectedAt))))
+	}
+	fmt.Fprintf(a.Out, "%s\n", t.MutedText("Run resolve <issue> to pick a side per field"))
+	return nil
+}
+
+// Resolve settles a recorded conflict field by field. Conflicting fields
+// take the local value, the remote value, or a merge of both; remote
+// changes that did not conflict are applied as well. The original is moved
+// to the remote version, so the next push sends the resolution.
+func (a *App) Resolve(ctx context.Context, number string, opts ResolveOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+	if opts.Ours && opts.Theirs {
+		return fmt.Errorf("--ours and --theirs are mutually exclusive")
+	}
+	number = strings.TrimPrefix(number, "#")
+	// Local issues have no remote side to conflict with, so a local ID
+	// asks for the issue it became
+	if issue.IssueNumber(number).IsLocal() {
+		if opts.Ours || opts.Theirs {
+			return fmt.Errorf("%s is a local issue and has no conflicts", number)
+		}
+		return a.ResolveLocalID(ctx, number)
+	}
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	record, err := loadConflict(p, number)
+	if err != nil {
+		return err
+	}
+	base, local, remote, err := record.snapshots()
+	if err != nil {
+		return err
+	}
+	file, err := findIssueByNumber(p, number)
+	if err != nil {
+		return err
+	}
+
+	resolved := file.Issue
+	for _, field := range issue.ComputeChanges(base, remote).Fields() {
+		if !slices.Contains(record.Fields, field) {
+			copyIssueField(&resolved, remote, field)
+		}
+	}
+
+	in := a.In
+	if in == nil {
+		in = os.Stdin
+	}
+	reader := bufio.NewReader(in)
+	fmt.Fprintln(a.Out, t.FormatIssueHeader("C", number, record.Title))
+	for _, field := range record.Fields {
+		choice := 'o'
+		switch {
+		case opts.Theirs:
+			choice = 't'
+		case !opts.Ours:
+			choice, err = a.promptConflictChoice(in, reader, field, local, remote)
+			if err != nil {
+				return err
+			}
+		}
+		switch choice {
+		case 'o':
+			copyIssueField(&resolved, local, field)
+		case 't':
+			copyIssueField(&resolved, remote, field)
+		case 'm':
+			if !mergeIssueField(&resolved, base, local, remote, field) {
+				fmt.Fprintf(a.Err, "%s %s changed on both sides in the same place, conflict markers left in the body\n",
+					t.WarningText("Warning:"), field)
+			}
+		}
+	}
+
+	newPath := issue.PathFor(issueDir(p, resolved.State, resolved), resolved.Number, resolved.Title)
+	if newPath != file.Path {
+		if err := moveIssue(p, file.Path, newPath); err != nil {
+			return err
+		}
+	}
+	resolved.LastLocalEditBy = a.localEditor(ctx)
+	if err := writeIssue(p, newPath, resolved); err != nil {
+		return err
+	}
+	if err := writeOriginalIssue(p, remote); err != nil {
+		return err
+	}
+	clearConflict(p, number)
+	fmt.Fprintf(a.Out, "%s #%s %s\n", t.SuccessText("Resolved"), number, t.MutedText("(run push to sync)"))
+	return nil
+}
+
+// promptConflictChoice shows both sides of a field and asks which to keep.
+// Merging is offered for the body and for list fields.
+func (a *App) promptConflictChoice(in io.Reader, reader *bufio.Reader, field string, local, remote issue.Issue) (rune, error) {
+	t := a.Theme
+	fmt.Fprintln(a.Out)
+	fmt.Fprintln(a.Out, t.Bold(field+":"))
+	if field == "body" {
+		fmt.Fprintf(a.Out, "  %s\n", t.MutedText("local -> remote:"))
+		a.printWordDiff(local.Body, remote.Body)
+	} else {
+		fmt.Fprintf(a.Out, "  %s %s\n", t.MutedText("local: "), issueFieldValue(local, field))
+		fmt.Fprintf(a.Out, "  %s %s\n", t.MutedText("remote:"), issueFieldValue(remote, field))
+	}
+	mergeable := isMergeableField(field)
+	prompt := "[o]urs [t]heirs?"
+	if mergeable {
+		prompt = "[o]urs [t]heirs [m]erge?"
+	}
+	for {
+		fmt.Fprintf(a.Out, "%s ", t.AccentText(prompt))
+		key, err := a.readKey(in, reader)
+		fmt.Fprintln(a.Out)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, fmt.Errorf("resolve aborted")
+			}
+			return 0, err
+		}
+		switch key {
+		case 'o', 't':
+			return key, nil
+		case 'm':
+			if mergeable {
+				return key, nil
+			}
+		case 'q', 3, 4:
+			return 0, fmt.Errorf("resolve aborted")
+		}
+		fmt.Fprintln(a.Out, t.MutedText("Unknown choice"))
+	}
+}
+
+func isMergeableField(field string) bool {
+	switch field {
+	case "body", "labels", "assignees", "projects", "blocked_by", "blocks":
+		return true
+	}
+	return false
+}
+
+// issueFieldValue formats a field as named by issue.FieldSet.Fields.
+func issueFieldValue(iss issue.Issue, field string) string {
+	switch field {
+	case "title":
+		return formatOptionalString(iss.Title)
+	case "labels":
+		return formatStringList(iss.Labels)
+	case "assignees":
+		return formatStringList(iss.Assignees)
+	case "milestone":
+		return formatOptionalString(iss.Milestone)
+	case "issue_type":
+		return formatOptionalString(iss.IssueType)
+	case "projects":
+		return formatStringList(iss.Projects)
+	case "state":
+		if iss.StateReason != nil {
+			return fmt.Sprintf("%s (%s)", formatOptionalString(iss.State), *iss.StateReason)
+		}
+		return formatOptionalString(iss.State)
+	case "parent":
+		if iss.Parent == nil {
+			return "<none>"
+		}
+		return "#" + iss.Parent.String()
+	case "blocked_by":
+		return formatStringList(refStrings(iss.BlockedBy))
+	case "blocks":
+		return formatStringList(refStrings(iss.Blocks))
+	case "body":
+		return formatBodySummary(iss.Body)
+	}
+	return ""
+}
+
+// copyIssueField sets field of dst to its value in src.
+func copyIssueField(dst *issue.Issue, src issue.Issue, field string) {
+	switch field {
+	case "title":
+		dst.Title = src.Title
+	case "labels":
+		dst.Labels = src.Labels
+	case "assignees":
+		dst.Assignees = src.Assignees
+	case "milestone":
+		dst.Milestone = src.Milestone
+	case "issue_type":
+		dst.IssueType = src.IssueType
+	case "projects":
+		dst.Projects = src.Projects
+	case "state":
+		dst.State = src.State
+		dst.StateReason = src.StateReason
+	case "parent":
+		dst.Parent = src.Parent
+	case "blocked_by":
+		dst.BlockedBy = src.BlockedBy
+	case "blocks":
+		dst.Blocks = src.Blocks
+	case "body":
+		dst.Body = issue.KeepLocalSyntax(src.Body, dst.Body)
+	}
+}
+
+// mergeIssueField combines the local and remote value of a field against
+// base. Lists keep additions and removals from both sides; the body is
+// merged line by line. It reports false if the body merge left conflict
+// markers behind.
+func mergeIssueField(dst *issue.Issue, base, local, remote issue.Issue, field string) bool {
+	switch field {
+	case "labels":
+		dst.Labels = mergeSet(base.Labels, local.Labels, remote.Labels)
+	case "assignees":
+		dst.Assignees = mergeSet(base.Assignees, local.Assignees, remote.Assignees)
+	case "projects":
+		dst.Projects = mergeSet(base.Projects, local.Projects, remote.Projects)
+	case "blocked_by":
+		dst.BlockedBy = mergeSet(base.BlockedBy, local.BlockedBy, remote.BlockedBy)
+	case "blocks":
+		dst.Blocks = mergeSet(base.Blocks, local.Blocks, remote.Blocks)
+	case "body":
+		merged, ok := issue.MergeText(issue.PublicBody(base.Body), issue.PublicBody(local.Body), issue.PublicBody(remote.Body))
+		dst.Body = issue.KeepLocalSyntax(merged, local.Body)
+		return ok
+	default:
+		copyIssueField(dst, local, field)
+	}
+	return true
+}
+
+// mergeSet applies the additions and removals both sides made to base.
+func mergeSet[T comparable](base, local, remote []T) []T {
+	var result []T
+	for _, v := range local {
+		if slices.Contains(base, v) && !slices.Contains(remote, v) {
+			continue
+		}
+		result = append(result, v)
+	}
+	for _, v := range remote {
+		if !slices.Contains(base, v) && !slices.Contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func refStrings(refs []issue.IssueRef) []string {
+	result := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		result = append(result, "#"+ref.String())
+	}
+	return result
+}
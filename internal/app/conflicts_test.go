@@ -0,0 +1,87 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestResolveConflict(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	base := issue.Issue{Number: "5", Title: "Crash on start", State: "open", Labels: []string{"bug"}, Body: "Body\n"}
+	local := base
+	local.Title = "Crash on startup"
+	local.Labels = []string{"bug", "p1"}
+	remote := base
+	remote.Title = "Crash when starting"
+	remote.Labels = []string{"bug", "regression"}
+	remote.Milestone = "v2"
+
+	if err := writeOriginalIssue(p, base); err != nil {
+		t.Fatalf("original: %v", err)
+	}
+	localPath := issue.PathFor(p.OpenDir, local.Number, local.Title)
+	if err := issue.WriteFile(localPath, local); err != nil {
+		t.Fatalf("write local: %v", err)
+	}
+	fields := issue.ComputeChanges(base, local).Overlaps(issue.ComputeChanges(base, remote)).Fields()
+	if err := recordConflict(p, "push", base, local, remote, fields, time.Now()); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	if err := application.Conflicts(context.Background()); err != nil {
+		t.Fatalf("conflicts: %v", err)
+	}
+	if !strings.Contains(out.String(), "title, labels") {
+		t.Fatalf("expected conflicting fields in listing, got:\n%s", out.String())
+	}
+
+	// Take the remote title, merge the labels
+	application.In = strings.NewReader("t\nm\n")
+	if err := application.Resolve(context.Background(), "5", ResolveOptions{}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	file, err := findIssueByNumber(p, "5")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if file.Issue.Title != "Crash when starting" {
+		t.Fatalf("expected remote title, got %q", file.Issue.Title)
+	}
+	if !slices.Equal(file.Issue.Labels, []string{"bug", "p1", "regression"}) {
+		t.Fatalf("expected merged labels, got %v", file.Issue.Labels)
+	}
+	if file.Issue.Milestone != "v2" {
+		t.Fatalf("expected non-conflicting remote milestone, got %q", file.Issue.Milestone)
+	}
+	if _, err := os.Stat(localPath); !os.IsNotExist(err) {
+		t.Fatalf("expected file to be renamed for the new title")
+	}
+	original, _ := readOriginalIssue(p, "5")
+	if original.Title != remote.Title {
+		t.Fatalf("expected original to move to remote, got %q", original.Title)
+	}
+	if records, _ := loadConflicts(p); len(records) != 0 {
+		t.Fatalf("expected conflict to be cleared, got %d", len(records))
+	}
+}
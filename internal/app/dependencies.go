@@ -0,0 +1,52 @@
+package app
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// dependencyPhrasePattern matches "Depends on #12" and "Blocked by #3, #4
+// and #T1a2b" phrases, also in task list items, capturing the references.
+var dependencyPhrasePattern = regexp.MustCompile(`(?i)\b(?:depends\s+on|blocked\s+by)\s*:?\s*((?:#(?:\d+|T[a-zA-Z0-9]+)\b(?:\s*(?:,|&|\band\b)\s*)?)+)`)
+
+// dependencyRefPattern matches one reference of a dependency phrase.
+var dependencyRefPattern = regexp.MustCompile(`#(\d+|T[a-zA-Z0-9]+)\b`)
+
+// bodyDependencies returns the issues the public body of iss says it
+// depends on, in order of appearance.
+func bodyDependencies(iss issue.Issue) []issue.IssueRef {
+	var refs []issue.IssueRef
+	for _, m := range dependencyPhrasePattern.FindAllStringSubmatch(issue.PublicBody(iss.Body), -1) {
+		for _, ref := range dependencyRefPattern.FindAllStringSubmatch(m[1], -1) {
+			r := issue.IssueRef(ref[1])
+			if r.String() != iss.Number.String() && !slices.Contains(refs, r) {
+				refs = append(refs, r)
+			}
+		}
+	}
+	return refs
+}
+
+// missingDependencies returns the dependencies in the body of iss that
+// are not in its blocked_by list yet.
+func missingDependencies(iss issue.Issue) []issue.IssueRef {
+	var missing []issue.IssueRef
+	for _, ref := range bodyDependencies(iss) {
+		if !slices.Contains(iss.BlockedBy, ref) {
+			missing = append(missing, ref)
+		}
+	}
+	return missing
+}
+
+// formatRefs formats refs as "#1, #2".
+func formatRefs(refs []issue.IssueRef) string {
+	parts := make([]string, len(refs))
+	for i, ref := range refs {
+		parts[i] = "#" + ref.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// formatLinkedPR renders a linked pull request like "#12 open (fix-login)
+// checks: success", colored by state and check status. The branch is left
+// out in the compact list view.
+func (a *App) formatLinkedPR(pr issue.LinkedPullRequest, withBranch bool) string {
+	t := a.Theme
+	text := fmt.Sprintf("#%d ", pr.Number)
+	switch pr.State {
+	case "open":
+		text += t.SuccessText(pr.State)
+	case "merged":
+		text += t.AccentText(pr.State)
+	case "closed":
+		text += t.ErrorText(pr.State)
+	default:
+		text += t.MutedText(pr.State)
+	}
+	if withBranch && pr.Branch != "" {
+		text += " " + t.MutedText("("+pr.Branch+")")
+	}
+	switch checks := "checks: " + pr.Checks; pr.Checks {
+	case "":
+	case "success":
+		text += " " + t.SuccessText(checks)
+	case "failure", "error":
+		text += " " + t.ErrorText(checks)
+	default:
+		text += " " + t.WarningText(checks)
+	}
+	return text
+}
+
+// closingPullRequest returns the merged pull request that closed iss, or 0
+// if it is open or was closed by hand.
+func closingPullRequest(iss issue.Issue) int {
+	if iss.State != "closed" {
+		return 0
+	}
+	for _, pr := range iss.PullRequests {
+		if pr.State == "merged" {
+			return pr.Number
+		}
+	}
+	return 0
+}
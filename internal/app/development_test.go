@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestLinkedPullRequestsInViewAndList(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{
+		Number:   "7",
+		Title:    "Token expiry",
+		State:    "open",
+		Branches: []string{"7-token-expiry"},
+		PullRequests: []issue.LinkedPullRequest{
+			{Number: 12, State: "open", Branch: "fix-7", Checks: "failure"},
+		},
+	}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	var out strings.Builder
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	if err := application.View(context.Background(), "7", ViewOptions{}); err != nil {
+		t.Fatalf("view: %v", err)
+	}
+	for _, want := range []string{"branches:\t7-token-expiry", "#12 open (fix-7) checks: failure"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("view should contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := application.List(context.Background(), ListOptions{}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if strings.Contains(out.String(), "#12") {
+		t.Fatalf("list should only show pull requests with --prs:\n%s", out.String())
+	}
+	out.Reset()
+	if err := application.List(context.Background(), ListOptions{PRs: true}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out.String(), "#12 open checks: failure") {
+		t.Fatalf("list --prs should show the pull request:\n%s", out.String())
+	}
+}
+
+func TestClosingPullRequest(t *testing.T) {
+	prs := []issue.LinkedPullRequest{{Number: 12, State: "closed"}, {Number: 15, State: "merged"}}
+	if got := closingPullRequest(issue.Issue{State: "closed", PullRequests: prs}); got != 15 {
+		t.Fatalf("expected #15, got %d", got)
+	}
+	if got := closingPullRequest(issue.Issue{State: "open", PullRequests: prs}); got != 0 {
+		t.Fatalf("open issues have no closing pull request, got %d", got)
+	}
+	if got := closingPullRequest(issue.Issue{State: "closed", PullRequests: prs[:1]}); got != 0 {
+		t.Fatalf("unmerged pull requests don't close issues, got %d", got)
+	}
+}
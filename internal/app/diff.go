@@ -6,6 +6,8 @@ import (
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/textdiff"
 )
 
 // printWordDiff prints a colorized word-level diff that preserves line structure.
@@ -131,41 +133,73 @@ func splitIntoWords(text string) []string {
 	return words
 }
 
-// refineWordDiff looks for adjacent delete+insert pairs and refines them
-// into character-level diffs if the words are similar enough.
+// refineWordDiff refines changed words into character-level diffs. Within
+// each run of deletions and insertions, the n-th deleted word is paired with
+// the n-th inserted one, and pairs that are similar enough become a single
+// diffChange, so a fixed typo highlights only the characters that changed.
+// Dissimilar words stay grouped as deletions followed by insertions.
 // Newline tokens are never refined.
 func refineWordDiff(ops []diffOp) []diffOp {
 	var result []diffOp
 	i := 0
 	for i < len(ops) {
-		// Look for delete followed by insert (or vice versa)
-		if i+1 < len(ops) {
-			op1, op2 := ops[i], ops[i+1]
-			var oldWord, newWord string
-
-			if op1.Type == diffDelete && op2.Type == diffInsert {
-				oldWord, newWord = op1.Text, op2.Text
-			} else if op1.Type == diffInsert && op2.Type == diffDelete {
-				oldWord, newWord = op2.Text, op1.Text
-			}
+		if ops[i].Type != diffDelete && ops[i].Type != diffInsert {
+			result = append(result, ops[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && (ops[j].Type == diffDelete || ops[j].Type == diffInsert) {
+			j++
+		}
+		result = append(result, refineChangeRun(ops[i:j])...)
+		i = j
+	}
+	return result
+}
 
-			// Don't refine newline tokens
-			if oldWord != "" && newWord != "" && oldWord != "\n" && newWord != "\n" && wordsSimilar(oldWord, newWord) {
-				// Refine into character-level diff
-				charOps := computeCharDiff(oldWord, newWord)
+// refineChangeRun refines one run of deletions and insertions.
+func refineChangeRun(run []diffOp) []diffOp {
+	var deleted, inserted []diffOp
+	for _, op := range run {
+		if op.Type == diffDelete {
+			deleted = append(deleted, op)
+		} else {
+			inserted = append(inserted, op)
+		}
+	}
+	if len(deleted) == 0 || len(inserted) == 0 {
+		return run
+	}
+
+	var result, pendingDeleted, pendingInserted []diffOp
+	flush := func() {
+		result = append(result, pendingDeleted...)
+		result = append(result, pendingInserted...)
+		pendingDeleted, pendingInserted = nil, nil
+	}
+	for k := 0; k < len(deleted) || k < len(inserted); k++ {
+		if k < len(deleted) && k < len(inserted) {
+			oldWord, newWord := deleted[k].Text, inserted[k].Text
+			if oldWord != "\n" && newWord != "\n" && wordsSimilar(oldWord, newWord) {
+				flush()
 				result = append(result, diffOp{
 					Type:    diffChange,
 					Text:    oldWord,
 					NewText: newWord,
-					CharOps: charOps,
+					CharOps: computeCharDiff(oldWord, newWord),
 				})
-				i += 2
 				continue
 			}
 		}
-		result = append(result, ops[i])
-		i++
+		if k < len(deleted) {
+			pendingDeleted = append(pendingDeleted, deleted[k])
+		}
+		if k < len(inserted) {
+			pendingInserted = append(pendingInserted, inserted[k])
+		}
 	}
+	flush()
 	return result
 }
 
@@ -325,54 +359,88 @@ func mergeConsecutiveOps(ops []diffOp) []diffOp {
 	return result
 }
 
-// computeWordDiff computes word-level diff using LCS algorithm
+// maxWordDiffEdits bounds the edit distance the word diff searches for.
+// Bodies that differ by more are diffed line by line instead, which is
+// coarser but keeps diff responsive on huge or rewritten issues.
+const maxWordDiffEdits = 1000
+
+// computeWordDiff computes a word-level diff of two token lists.
 func computeWordDiff(oldWords, newWords []string) []diffOp {
-	m, n := len(oldWords), len(newWords)
-	if m == 0 && n == 0 {
+	return diffTokens(oldWords, newWords, maxWordDiffEdits)
+}
+
+// diffTokens diffs tokens with Myers' algorithm. Past maxEdits it degrades
+// to a diff of whole lines, and past that to replacing everything.
+func diffTokens(oldWords, newWords []string, maxEdits int) []diffOp {
+	if len(oldWords) == 0 && len(newWords) == 0 {
 		return nil
 	}
-
-	// Build LCS table
-	lcs := make([][]int, m+1)
-	for i := range lcs {
-		lcs[i] = make([]int, n+1)
+	if ops, ok := myersDiff(oldWords, newWords, maxEdits); ok {
+		return ops
 	}
 
-	for i := 1; i <= m; i++ {
-		for j := 1; j <= n; j++ {
-			if oldWords[i-1] == newWords[j-1] {
-				lcs[i][j] = lcs[i-1][j-1] + 1
-			} else if lcs[i-1][j] >= lcs[i][j-1] {
-				lcs[i][j] = lcs[i-1][j]
-			} else {
-				lcs[i][j] = lcs[i][j-1]
-			}
+	oldLines, newLines := splitTokenLines(oldWords), splitTokenLines(newWords)
+	oldKeys, newKeys := make([]string, len(oldLines)), make([]string, len(newLines))
+	for i, line := range oldLines {
+		oldKeys[i] = strings.Join(line, "\x00")
+	}
+	for i, line := range newLines {
+		newKeys[i] = strings.Join(line, "\x00")
+	}
+	lineOps, ok := myersDiff(oldKeys, newKeys, maxEdits)
+	if !ok {
+		lineOps = nil
+		for _, key := range oldKeys {
+			lineOps = append(lineOps, diffOp{Type: diffDelete, Text: key})
+		}
+		for _, key := range newKeys {
+			lineOps = append(lineOps, diffOp{Type: diffInsert, Text: key})
 		}
 	}
-
-	// Backtrack to build diff
 	var ops []diffOp
-	i, j := m, n
-	for i > 0 || j > 0 {
-		if i > 0 && j > 0 && oldWords[i-1] == newWords[j-1] {
-			ops = append(ops, diffOp{Type: diffEqual, Text: oldWords[i-1]})
-			i--
-			j--
-		} else if j > 0 && (i == 0 || lcs[i][j-1] >= lcs[i-1][j]) {
-			ops = append(ops, diffOp{Type: diffInsert, Text: newWords[j-1]})
-			j--
-		} else {
-			ops = append(ops, diffOp{Type: diffDelete, Text: oldWords[i-1]})
-			i--
+	for _, op := range lineOps {
+		for _, word := range strings.Split(op.Text, "\x00") {
+			ops = append(ops, diffOp{Type: op.Type, Text: word})
 		}
 	}
+	return ops
+}
 
-	// Reverse to get correct order
-	for left, right := 0, len(ops)-1; left < right; left, right = left+1, right-1 {
-		ops[left], ops[right] = ops[right], ops[left]
+// splitTokenLines groups tokens into lines, each ending with its "\n" token.
+func splitTokenLines(tokens []string) [][]string {
+	var lines [][]string
+	start := 0
+	for i, token := range tokens {
+		if token == "\n" {
+			lines = append(lines, tokens[start:i+1])
+			start = i + 1
+		}
 	}
+	if start < len(tokens) {
+		lines = append(lines, tokens[start:])
+	}
+	return lines
+}
 
-	return ops
+// myersDiff returns the shortest edit script turning a into b, or false if
+// it needs more than maxEdits insertions and deletions, see textdiff.Myers.
+func myersDiff(a, b []string, maxEdits int) ([]diffOp, bool) {
+	script, ok := textdiff.Myers(a, b, maxEdits)
+	if !ok {
+		return nil, false
+	}
+	ops := make([]diffOp, 0, len(script))
+	for _, op := range script {
+		switch op.Kind {
+		case textdiff.Equal:
+			ops = append(ops, diffOp{Type: diffEqual, Text: op.Text})
+		case textdiff.Delete:
+			ops = append(ops, diffOp{Type: diffDelete, Text: op.Text})
+		case textdiff.Insert:
+			ops = append(ops, diffOp{Type: diffInsert, Text: op.Text})
+		}
+	}
+	return ops, true
 }
 
 // renderWordDiff renders a word diff with inline coloring, preserving newlines
@@ -2,9 +2,20 @@ package app
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
 	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
 )
 
@@ -118,6 +129,27 @@ func TestRefineWordDiffDissimilar(t *testing.T) {
 	}
 }
 
+func TestRefineWordDiffPairsWithinRun(t *testing.T) {
+	// Myers groups a multi-word change as all deletions then all insertions
+	ops := computeWordDiff(splitIntoTokens("helo quick brwon fox"), splitIntoTokens("hello fast brown fox"))
+	refined := refineWordDiff(ops)
+
+	var changes []string
+	for _, op := range refined {
+		switch op.Type {
+		case diffChange:
+			changes = append(changes, op.Text+">"+op.NewText)
+		case diffDelete:
+			changes = append(changes, "-"+op.Text)
+		case diffInsert:
+			changes = append(changes, "+"+op.Text)
+		}
+	}
+	if got := strings.Join(changes, " "); got != "helo>hello -quick +fast brwon>brown" {
+		t.Fatalf("unexpected refinement: %s", got)
+	}
+}
+
 func TestFormatInlineWordDiff(t *testing.T) {
 	var buf bytes.Buffer
 	app := &App{
@@ -179,3 +211,171 @@ func TestPrintWordDiffPreservesNewlines(t *testing.T) {
 		t.Errorf("expected multiple lines in output, got %d: %q", len(lines), output)
 	}
 }
+
+func TestDiffStat(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	original := issue.Issue{Number: "1", Title: "Crash on start", State: "open", Body: "It crashes on start.\n"}
+	changed := original
+	changed.Labels = []string{"bug"}
+	changed.Body = "It crashes on every start.\n"
+	unchanged := issue.Issue{Number: "2", Title: "Docs", State: "open"}
+	for _, item := range []struct {
+		dir string
+		iss issue.Issue
+	}{
+		{p.OriginalsDir, original},
+		{p.OpenDir, changed},
+		{p.OriginalsDir, unchanged},
+		{p.OpenDir, unchanged},
+	} {
+		path := issue.PathFor(item.dir, item.iss.Number, item.iss.Title)
+		if item.dir == p.OriginalsDir {
+			path = filepath.Join(p.OriginalsDir, item.iss.Number.String()+".md")
+		}
+		if err := issue.WriteFile(path, item.iss); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(p.OpenDir, "2.comment.md"), []byte("Looks good\n"), 0o644); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	if err := application.DiffStat(context.Background(), "", DiffOptions{}); err != nil {
+		t.Fatalf("diff stat: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected two issues and a summary, got %q", out.String())
+	}
+	if !strings.HasPrefix(lines[0], "M #1") || !strings.HasSuffix(lines[0], "| labels  +1 -0 words") {
+		t.Errorf("unexpected line for #1: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "M #2") || !strings.HasSuffix(lines[1], "| +comment") {
+		t.Errorf("unexpected line for #2: %q", lines[1])
+	}
+	if lines[2] != "2 issues changed, 1 fields, +1 -0 words, 1 pending comments" {
+		t.Errorf("unexpected summary: %q", lines[2])
+	}
+}
+
+func BenchmarkWordDiff(b *testing.B) {
+	paragraph := "The editor crashes when a file is larger than the buffer limit and the user scrolls.\n"
+	oldText := strings.Repeat(paragraph, 60)
+	newText := strings.Replace(oldText, "buffer limit", "configured buffer limit", 5) + "Fixed in the next release.\n"
+	oldTokens, newTokens := splitIntoTokens(oldText), splitIntoTokens(newText)
+	for b.Loop() {
+		refineWordDiff(computeWordDiff(oldTokens, newTokens))
+	}
+}
+
+// applyOps rebuilds both sides of a diff.
+func applyOps(ops []diffOp) (string, string) {
+	var oldText, newText []string
+	for _, op := range ops {
+		if op.Type != diffInsert {
+			oldText = append(oldText, op.Text)
+		}
+		if op.Type != diffDelete {
+			newText = append(newText, op.Text)
+		}
+	}
+	return strings.Join(oldText, " "), strings.Join(newText, " ")
+}
+
+func TestDiffTokensIsMinimal(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 7))
+	words := []string{"a", "b", "c", "\n"}
+	random := func() []string {
+		tokens := make([]string, rng.IntN(12))
+		for i := range tokens {
+			tokens[i] = words[rng.IntN(len(words))]
+		}
+		return tokens
+	}
+	for range 500 {
+		a, b := random(), random()
+		ops := computeWordDiff(a, b)
+		oldText, newText := applyOps(ops)
+		if oldText != strings.Join(a, " ") || newText != strings.Join(b, " ") {
+			t.Fatalf("diff of %q and %q does not rebuild them: %+v", a, b, ops)
+		}
+		// The edit script is as short as the longest common subsequence allows
+		lcs := make([][]int, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(b)+1)
+		}
+		for i := 1; i <= len(a); i++ {
+			for j := 1; j <= len(b); j++ {
+				if a[i-1] == b[j-1] {
+					lcs[i][j] = lcs[i-1][j-1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i-1][j], lcs[i][j-1])
+				}
+			}
+		}
+		edits := 0
+		for _, op := range ops {
+			if op.Type != diffEqual {
+				edits++
+			}
+		}
+		if want := len(a) + len(b) - 2*lcs[len(a)][len(b)]; edits != want {
+			t.Fatalf("diff of %q and %q has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestDiffTokensFallsBackToLines(t *testing.T) {
+	// Ten word edits exceed the limit, two line edits don't
+	oldTokens := splitIntoTokens("one two three four five\nfive six\n")
+	newTokens := splitIntoTokens("1 2 3 4 5\nfive six\n")
+	ops := diffTokens(oldTokens, newTokens, 3)
+	oldText, newText := applyOps(ops)
+	if oldText != strings.Join(oldTokens, " ") || newText != strings.Join(newTokens, " ") {
+		t.Fatalf("line diff does not rebuild the texts: %+v", ops)
+	}
+	for _, op := range ops {
+		if op.Text == "six" && op.Type != diffEqual {
+			t.Fatalf("unchanged line should stay equal: %+v", ops)
+		}
+		if op.Text == "one" && op.Type == diffEqual {
+			t.Fatalf("changed line should be replaced whole: %+v", ops)
+		}
+	}
+}
+
+func TestWordDiffHugeBody(t *testing.T) {
+	var oldText, newText strings.Builder
+	for i := range 20000 {
+		fmt.Fprintf(&oldText, "word%d ", i)
+		if i%7 == 0 {
+			fmt.Fprintf(&newText, "changed%d ", i)
+		} else {
+			fmt.Fprintf(&newText, "word%d ", i)
+		}
+		if i%20 == 19 {
+			oldText.WriteString("\n")
+			newText.WriteString("\n")
+		}
+	}
+	oldTokens, newTokens := splitIntoTokens(oldText.String()), splitIntoTokens(newText.String())
+	start := time.Now()
+	ops := computeWordDiff(oldTokens, newTokens)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("diff took %s", elapsed)
+	}
+	gotOld, gotNew := applyOps(ops)
+	if gotOld != strings.Join(oldTokens, " ") || gotNew != strings.Join(newTokens, " ") {
+		t.Fatalf("diff does not rebuild the texts")
+	}
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// issueStat summarizes the local changes of one issue.
+type issueStat struct {
+	Status   string
+	Number   string
+	Title    string
+	Fields   []string // Changed fields other than the body
+	Added    int      // Words added to the body
+	Removed  int      // Words removed from the body
+	Comments int      // Pending comments
+}
+
+// DiffStat prints a one-line summary per changed issue, like git diff --stat.
+// With an empty number it covers the whole mirror.
+func (a *App) DiffStat(ctx context.Context, number string, opts DiffOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+
+	var files []IssueFile
+	if strings.TrimSpace(number) != "" {
+		file, err := findIssueByNumber(p, number)
+		if err != nil {
+			return err
+		}
+		files = []IssueFile{file}
+	} else {
+		files, err = loadLocalIssues(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	var client ghcli.Provider
+	if opts.Remote {
+		client, err = a.newProvider(cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	comments := loadAllPendingComments(p)
+	var stats []issueStat
+	for _, file := range files {
+		local := issue.Normalize(file.Issue)
+		var base issue.Issue
+		status := "M"
+		switch {
+		case opts.Remote && local.Number.IsLocal():
+			continue // skip local-only issues for remote diff
+		case opts.Remote:
+			remote, err := client.GetIssue(ctx, local.Number.String())
+			if err != nil {
+				fmt.Fprintf(a.Out, "%s %s: %v\n", t.ErrorText("!"), local.Number, err)
+				continue
+			}
+			base = issue.Normalize(remote)
+		case local.Number.IsLocal():
+			status = "A"
+		default:
+			original, hasOriginal := readOriginalIssue(p, local.Number.String())
+			if !hasOriginal {
+				continue
+			}
+			base = issue.Normalize(original)
+		}
+
+		stat := issueStat{Status: status, Number: local.Number.String(), Title: local.Title}
+		if _, ok := comments[local.Number.String()]; ok {
+			stat.Comments = 1
+		}
+		if !issue.EqualIgnoringSyncedAt(base, local) {
+			stat.Fields = changedFieldNames(base, local)
+			stat.Added, stat.Removed = countWordChanges(base.Body, local.Body)
+		}
+		if len(stat.Fields) == 0 && stat.Added == 0 && stat.Removed == 0 && stat.Comments == 0 {
+			continue
+		}
+		stats = append(stats, stat)
+	}
+
+	if len(stats) == 0 {
+		baseLabel := "original"
+		if opts.Remote {
+			baseLabel = "remote"
+		}
+		fmt.Fprintln(a.Out, t.MutedText(fmt.Sprintf("No differences between local and %s", baseLabel)))
+		return nil
+	}
+
+	width := 0
+	for _, stat := range stats {
+		if len(stat.Number)+1 > width {
+			width = len(stat.Number) + 1
+		}
+	}
+	var fields, added, removed, pending int
+	for _, stat := range stats {
+		var parts []string
+		if len(stat.Fields) > 0 {
+			parts = append(parts, strings.Join(stat.Fields, ", "))
+		}
+		if stat.Added > 0 || stat.Removed > 0 {
+			parts = append(parts, t.SuccessText(fmt.Sprintf("+%d", stat.Added))+" "+
+				t.ErrorText(fmt.Sprintf("-%d", stat.Removed))+" words")
+		}
+		if stat.Comments > 0 {
+			parts = append(parts, t.Styler().Fg(t.FieldName, "+comment"))
+		}
+		title := truncateAnsi(stat.Title, 40, "")
+		fmt.Fprintf(a.Out, "%s %s  %s %s %s\n", t.FormatStatus(stat.Status),
+			padRight(t.Styler().Fg(t.IssueNumber, "#"+stat.Number), width), padRight(title, 40),
+			t.MutedText("|"), strings.Join(parts, "  "))
+		fields += len(stat.Fields)
+		added += stat.Added
+		removed += stat.Removed
+		pending += stat.Comments
+	}
+	fmt.Fprintf(a.Out, "%s\n", t.MutedText(fmt.Sprintf("%d issues changed, %d fields, +%d -%d words, %d pending comments",
+		len(stats), fields, added, removed, pending)))
+	return nil
+}
+
+// changedFieldNames lists the changed fields in the order formatChangeLines
+// shows them, leaving out the body.
+func changedFieldNames(oldIssue, newIssue issue.Issue) []string {
+	var fields []string
+	if oldIssue.Title != newIssue.Title {
+		fields = append(fields, "title")
+	}
+	if !stringSlicesEqual(oldIssue.Labels, newIssue.Labels) {
+		fields = append(fields, "labels")
+	}
+	if !stringSlicesEqual(oldIssue.Assignees, newIssue.Assignees) {
+		fields = append(fields, "assignees")
+	}
+	if oldIssue.Milestone != newIssue.Milestone {
+		fields = append(fields, "milestone")
+	}
+	if oldIssue.IssueType != newIssue.IssueType {
+		fields = append(fields, "type")
+	}
+	if !stringSlicesEqual(oldIssue.Projects, newIssue.Projects) {
+		fields = append(fields, "projects")
+	}
+	if oldIssue.State != newIssue.State {
+		fields = append(fields, "state")
+	}
+	if normalizeOptional(oldIssue.StateReason) != normalizeOptional(newIssue.StateReason) {
+		fields = append(fields, "state_reason")
+	}
+	return fields
+}
+
+// countWordChanges counts the words added to and removed from a body.
+func countWordChanges(oldBody, newBody string) (added, removed int) {
+	if oldBody == newBody {
+		return 0, 0
+	}
+	for _, op := range computeWordDiff(splitIntoTokens(oldBody), splitIntoTokens(newBody)) {
+		if op.Text == "\n" {
+			continue
+		}
+		switch op.Type {
+		case diffInsert:
+			added++
+		case diffDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
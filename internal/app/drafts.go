@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// Todo captures a thought as a draft with the todo label, without opening
+// an editor. A later triage pass promotes it.
+func (a *App) Todo(ctx context.Context, text string) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	title := strings.Join(strings.Fields(text), " ")
+	if title == "" {
+		return fmt.Errorf("nothing to capture")
+	}
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	localNumber, err := a.newLocalNumber(p)
+	if err != nil {
+		return err
+	}
+	todo := issue.Issue{
+		Number: localNumber,
+		Title:  title,
+		Labels: []string{cfg.Todo.EffectiveLabel()},
+		State:  "open",
+		Draft:  true,
+	}
+	if err := os.MkdirAll(p.DraftsDir, 0o755); err != nil {
+		return err
+	}
+	path := issue.PathFor(p.DraftsDir, todo.Number, todo.Title)
+	if err := writeIssue(p, path, todo); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%s %s\n", a.Theme.SuccessText("Captured"), todo.Number)
+	return nil
+}
+
+// Promote turns a draft into a regular local issue that the next push
+// creates. The draft flag and the todo label are cleared and the file is
+// moved from .issues/drafts into .issues/open.
+func (a *App) Promote(ctx context.Context, ref string) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	file, err := findIssueByRef(a.Root, p, ref)
+	if err != nil {
+		return err
+	}
+	if !file.Issue.Draft {
+		return fmt.Errorf("issue %s is not a draft", file.Issue.Number)
+	}
+
+	promoted := file.Issue
+	promoted.Draft = false
+	promoted.State = "open"
+	promoted.Labels = applyListEdit(promoted.Labels, "-"+cfg.Todo.EffectiveLabel())
+	if !promoted.Number.IsLocal() {
+		// Hand-named draft files get a proper local ID
+		localNumber, err := a.newLocalNumber(p)
+		if err != nil {
+			return err
+		}
+		promoted.Number = localNumber
+	}
+
+	newPath := issue.PathFor(issueDir(p, "open", promoted), promoted.Number, promoted.Title)
+	promoted.LastLocalEditBy = a.localEditor(ctx)
+	if err := writeIssue(p, newPath, promoted); err != nil {
+		return err
+	}
+	if newPath != file.Path {
+		if err := removeIssue(p, file.Path); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(a.Out, "%s %s %s\n", t.SuccessText("Promoted"), relPath(a.Root, newPath), t.MutedText("(run push to create it)"))
+	return nil
+}
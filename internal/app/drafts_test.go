@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestDraftsAreIgnoredUntilPromoted(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+	if err := application.NewIssue(context.Background(), "Rough idea", NewOptions{Draft: true}); err != nil {
+		t.Fatalf("new draft: %v", err)
+	}
+	drafts := loadDraftIssues(p).Issues
+	if len(drafts) != 1 || !drafts[0].Issue.Draft {
+		t.Fatalf("expected one draft, got %+v", drafts)
+	}
+	local, err := loadLocalIssues(p)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(local) != 0 {
+		t.Fatalf("draft should not be a local issue: %+v", local)
+	}
+
+	// A draft flag in open/ keeps the issue out of push as well
+	flagged := issue.Issue{Number: "Tflagged", Title: "Flagged", State: "open", Draft: true}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, flagged.Number, flagged.Title), flagged); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	runner := &offlineRunner{}
+	pushApp := New(root, runner, io.Discard, io.Discard)
+	_ = pushApp.Push(context.Background(), PushOptions{}, nil)
+	for _, call := range runner.calls {
+		if strings.Contains(call, "Flagged") || strings.Contains(call, "Rough idea") {
+			t.Fatalf("draft was pushed: %v", call)
+		}
+	}
+
+	number := drafts[0].Issue.Number.String()
+	if err := application.Promote(context.Background(), number); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	promoted, err := findIssueByNumber(p, number)
+	if err != nil {
+		t.Fatalf("find promoted: %v", err)
+	}
+	if promoted.Issue.Draft || filepath.Dir(promoted.Path) != p.OpenDir {
+		t.Fatalf("expected promoted issue in open/, got %+v", promoted)
+	}
+	if _, err := os.Stat(drafts[0].Path); !os.IsNotExist(err) {
+		t.Fatalf("expected draft file to be removed")
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// editContextComments is how many of the most recent comments edit
+// --with-comments shows.
+const editContextComments = 10
+
+// commentContext renders the last limit comments as a quoted block to
+// append to a body while it is edited.
+func (a *App) commentContext(comments []ghcli.Comment, limit int) string {
+	if len(comments) == 0 {
+		return ""
+	}
+	skipped := 0
+	if len(comments) > limit {
+		skipped = len(comments) - limit
+		comments = comments[skipped:]
+	}
+	var b strings.Builder
+	b.WriteString(issue.CommentContextStart + "\n\n")
+	if skipped > 0 {
+		fmt.Fprintf(&b, "> _%d older comment(s) not shown_\n\n", skipped)
+	}
+	for _, comment := range comments {
+		author := comment.Author
+		if author == "" {
+			author = "ghost"
+		}
+		header := "**@" + author + "**"
+		if comment.CreatedAt != nil {
+			header += " " + comment.CreatedAt.Local().Format("2006-01-02 15:04") + " (" + formatRelativeTime(a.Now(), *comment.CreatedAt) + ")"
+		}
+		fmt.Fprintf(&b, "> %s\n>\n", header)
+		for _, line := range strings.Split(strings.TrimRight(comment.Body, "\n"), "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(issue.CommentContextEnd + "\n")
+	return b.String()
+}
+
+// withCommentContext appends the comment block to body.
+func withCommentContext(body, block string) string {
+	if block == "" {
+		return body
+	}
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return block
+	}
+	return body + "\n\n" + block
+}
+
+// editComments returns the comments of an issue for editing. They are
+// fetched so the context is current, falling back to the comments pulled
+// before when the tracker can't be reached.
+func (a *App) editComments(ctx context.Context, p paths.Paths, number string) []ghcli.Comment {
+	t := a.Theme
+	cache, cached := loadCommentCache(p, number)
+	cfg, err := loadConfig(p.ConfigPath)
+	if err == nil {
+		var client ghcli.Provider
+		if client, err = a.newProvider(cfg); err == nil {
+			var fetched []ghcli.Comment
+			if fetched, err = client.ListComments(ctx, number, nil); err == nil {
+				cache.Comments = fetched
+				if err := saveCommentCache(p, number, cache); err != nil {
+					fmt.Fprintf(a.Err, "%s caching comments of #%s: %v\n", t.WarningText("Warning:"), number, err)
+				}
+				return cache.Comments
+			}
+		}
+	}
+	if cached {
+		fmt.Fprintf(a.Err, "%s fetching comments of #%s: %v (showing the pulled comments)\n", t.WarningText("Warning:"), number, err)
+	} else {
+		fmt.Fprintf(a.Err, "%s fetching comments of #%s: %v\n", t.WarningText("Warning:"), number, err)
+	}
+	return cache.Comments
+}
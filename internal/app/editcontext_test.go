@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestEditWithComments(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "8", Title: "Flaky test", State: "open", Body: "The test fails sometimes."}
+	path := issue.PathFor(p.OpenDir, iss.Number, iss.Title)
+	if err := issue.WriteFile(path, iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := saveCommentCache(p, "8", CommentCache{Comments: []ghcli.Comment{
+		{ID: "c1", Author: "bo", Body: "Happens on CI only.\nNever locally.", CreatedAt: &createdAt},
+	}}); err != nil {
+		t.Fatalf("comments: %v", err)
+	}
+
+	var buffer string
+	reply := true
+	previousInteractive := runInteractiveCommand
+	runInteractiveCommand = func(ctx context.Context, command string, args ...string) error {
+		data, err := os.ReadFile(args[len(args)-1])
+		if err != nil {
+			return err
+		}
+		buffer = string(data)
+		if !reply {
+			return nil
+		}
+		edited := strings.Replace(buffer, "The test fails sometimes.", "The test fails sometimes on CI.", 1)
+		return os.WriteFile(args[len(args)-1], []byte(edited), 0o644)
+	}
+	t.Cleanup(func() { runInteractiveCommand = previousInteractive })
+	t.Setenv("EDITOR", "vi")
+
+	// Offline, so the pulled comments are shown
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	if err := application.Edit(ctx, "8", EditOptions{WithComments: true}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if !strings.Contains(buffer, "> **@bo** ") || !strings.Contains(buffer, "> Happens on CI only.\n> Never locally.\n") {
+		t.Fatalf("expected the comments quoted in the editor, got:\n%s", buffer)
+	}
+	edited, err := issue.ParseFile(path)
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	if strings.TrimSpace(edited.Body) != "The test fails sometimes on CI." {
+		t.Fatalf("expected the comments stripped on save, got %q", edited.Body)
+	}
+
+	// Without changes the file is left as it was
+	reply = false
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if err := application.Edit(ctx, "8", EditOptions{WithComments: true}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(after) != string(before) || !strings.Contains(buffer, "gh-issue-sync:comments") {
+		t.Fatalf("expected the file unchanged, got:\n%s", after)
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+// gitUserRunner answers git's user.name and fails everything else.
+type gitUserRunner struct {
+	name string
+}
+
+func (r *gitUserRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	if name == "git" && strings.HasSuffix(strings.Join(args, " "), "config user.name") {
+		return r.name + "\n", nil
+	}
+	return "", errors.New("offline")
+}
+
+func TestLastLocalEditBy(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "5", Title: "Flaky test", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := writeOriginalIssue(p, iss); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &gitUserRunner{name: "Jane Doe"}, &out, &out)
+	application.Theme = theme.Plain()
+	if err := application.Close(context.Background(), "5", CloseOptions{}); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	closedPath := issue.PathFor(p.ClosedDir, iss.Number, iss.Title)
+	data, err := os.ReadFile(closedPath)
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	if !strings.Contains(string(data), "last_local_edit_by: Jane Doe") {
+		t.Fatalf("expected the editor in the front matter:\n%s", data)
+	}
+
+	out.Reset()
+	if err := application.Status(context.Background(), StatusOptions{}); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(out.String(), "M Issue #5: Flaky test (by Jane Doe)") {
+		t.Fatalf("expected the editor in status:\n%s", out.String())
+	}
+
+	// The attribution alone is not a change to push
+	closed, err := readIssue(p, closedPath)
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	original := closed
+	original.LastLocalEditBy = ""
+	if !issue.EqualIgnoringSyncedAt(closed, original) {
+		t.Fatal("expected last_local_edit_by to be ignored when comparing")
+	}
+}
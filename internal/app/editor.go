@@ -0,0 +1,74 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// editorHelpWidth is where the value lists of the help header wrap.
+const editorHelpWidth = 78
+
+// editorHelp returns the commented help header for new issue buffers: the
+// front matter fields and the labels, milestones, and types from the caches.
+func editorHelp(p paths.Paths) string {
+	var b strings.Builder
+	b.WriteString("# This commented header is help and is removed on save.\n")
+	b.WriteString("# Fields: title, labels, assignees, milestone, type, projects, state,\n")
+	b.WriteString("#   parent, blocked_by, blocks. Leave a field out if it doesn't apply.\n")
+
+	if cache, err := loadLabelCache(p); err == nil && len(cache.Labels) > 0 {
+		names := make([]string, 0, len(cache.Labels))
+		for _, label := range cache.Labels {
+			names = append(names, label.Name)
+		}
+		writeHelpList(&b, "Labels", names)
+	}
+	if cache, err := loadMilestoneCache(p); err == nil {
+		var titles []string
+		for _, milestone := range cache.Milestones {
+			if milestone.State != "closed" {
+				titles = append(titles, milestone.Title)
+			}
+		}
+		writeHelpList(&b, "Milestones", titles)
+	}
+	if cache, err := loadIssueTypeCache(p); err == nil {
+		var names []string
+		for _, issueType := range cache.IssueTypes {
+			names = append(names, issueType.Name)
+		}
+		writeHelpList(&b, "Types", names)
+	}
+	return b.String()
+}
+
+// writeHelpList writes a comma separated list of values as comment lines
+// wrapped at editorHelpWidth.
+func writeHelpList(b *strings.Builder, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	line := "# " + name + ":"
+	for i, value := range values {
+		if i < len(values)-1 {
+			value += ","
+		}
+		if len(line)+1+len(value) > editorHelpWidth && line != "# "+name+":" {
+			b.WriteString(line + "\n")
+			line = "#  "
+		}
+		line += " " + value
+	}
+	b.WriteString(line + "\n")
+}
+
+// injectEditorHelp puts help at the top of the front matter of a rendered
+// issue. It is plain YAML comments, so parsing the file drops it again.
+func injectEditorHelp(content, help string) string {
+	const delimiter = "---\n"
+	if help == "" || !strings.HasPrefix(content, delimiter) {
+		return content
+	}
+	return delimiter + help + strings.TrimPrefix(content, delimiter)
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// subIssueRollups returns the sub-issue completion for every issue that has
+// children. The summary synced from GitHub is used when present; otherwise it
+// is computed from local files that reference the issue as their parent.
+func subIssueRollups(issues []IssueFile) map[string]issue.SubIssueSummary {
+	rollups := make(map[string]issue.SubIssueSummary)
+	local := make(map[string]issue.SubIssueSummary)
+	for _, item := range issues {
+		if item.Issue.Parent == nil {
+			continue
+		}
+		parent := item.Issue.Parent.String()
+		summary := local[parent]
+		summary.Total++
+		if item.State == "closed" {
+			summary.Completed++
+		}
+		local[parent] = summary
+	}
+	for _, item := range issues {
+		num := item.Issue.Number.String()
+		if item.Issue.SubIssues != nil && item.Issue.SubIssues.Total > 0 {
+			rollups[num] = *item.Issue.SubIssues
+		} else if summary, ok := local[num]; ok {
+			rollups[num] = summary
+		}
+	}
+	return rollups
+}
+
+// formatRollup renders a rollup like "3/7 sub-issues done".
+func formatRollup(s issue.SubIssueSummary) string {
+	noun := "sub-issues"
+	if s.Total == 1 {
+		noun = "sub-issue"
+	}
+	return fmt.Sprintf("%d/%d %s done", s.Completed, s.Total, noun)
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// defaultEstimateField is the custom front matter field estimates are
+// written to.
+const defaultEstimateField = "estimate"
+
+type EstimateOptions struct {
+	Milestone string
+	Field     string // front matter field holding the estimate (default: estimate)
+	All       bool   // also ask for issues that already have an estimate
+	Summary   bool   // only print the totals, without asking
+}
+
+// Estimate asks for an estimate of every open issue in a milestone that has
+// none yet and writes the answers to a custom front matter field, then
+// prints the milestone's total and the load per assignee. Estimates are
+// local fields and are never pushed.
+func (a *App) Estimate(ctx context.Context, opts EstimateOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+	field := opts.Field
+	if field == "" {
+		field = defaultEstimateField
+	}
+
+	result := loadLocalIssuesWithErrors(p)
+	for _, parseErr := range result.Errors {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
+	}
+	var items []IssueFile
+	for _, item := range result.Issues {
+		if item.State == "open" && strings.EqualFold(item.Issue.Milestone, opts.Milestone) {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return lessIssueNumber(items[i].Issue.Number.String(), items[j].Issue.Number.String())
+	})
+	if len(items) == 0 {
+		fmt.Fprintf(a.Out, "%s\n", t.MutedText("No open issues in milestone "+opts.Milestone))
+		return nil
+	}
+
+	if !opts.Summary {
+		in := a.In
+		if in == nil {
+			in = os.Stdin
+		}
+		reader := bufio.NewReader(in)
+		changed, err := a.promptEstimates(ctx, reader, items, field, opts.All)
+		if err != nil {
+			return err
+		}
+		if changed > 0 {
+			fmt.Fprintf(a.Out, "\n%s %d estimate(s) %s\n", t.SuccessText("Wrote"), changed, t.MutedText("(local field "+field+", not pushed)"))
+		}
+	}
+
+	a.printEstimateLoad(items, field, opts.Milestone)
+	return nil
+}
+
+// promptEstimates asks for the estimates one issue at a time and updates
+// items with the answers. An empty answer skips the issue, "-" removes the
+// estimate and "q" or the end of the input stops.
+func (a *App) promptEstimates(ctx context.Context, reader *bufio.Reader, items []IssueFile, field string, all bool) (int, error) {
+	t := a.Theme
+	p := a.issuePaths()
+	var queue []int
+	for i, item := range items {
+		if _, ok := item.Issue.FieldValue(field); all || !ok {
+			queue = append(queue, i)
+		}
+	}
+	if len(queue) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("All issues are estimated (use --all to revisit)"))
+		return 0, nil
+	}
+
+	changed := 0
+	for pos, i := range queue {
+		iss := &items[i].Issue
+		fmt.Fprintln(a.Out)
+		fmt.Fprintf(a.Out, "%s %s\n", t.MutedText(fmt.Sprintf("[%d/%d]", pos+1, len(queue))),
+			t.FormatIssueHeader(items[i].State, iss.Number.String(), iss.Title))
+		var details []string
+		if len(iss.Labels) > 0 {
+			details = append(details, strings.Join(iss.Labels, ", "))
+		}
+		if len(iss.Assignees) > 0 {
+			details = append(details, "@"+strings.Join(iss.Assignees, ", @"))
+		}
+		if len(details) > 0 {
+			fmt.Fprintln(a.Out, t.MutedText(strings.Join(details, "  ")))
+		}
+
+		for {
+			prompt := "Estimate (Enter to skip, - to clear, q to quit): "
+			if current, ok := iss.FieldValue(field); ok {
+				prompt = fmt.Sprintf("Estimate [%s] (Enter to keep, - to clear, q to quit): ", current)
+			}
+			fmt.Fprint(a.Out, t.MutedText(prompt))
+			line, err := reader.ReadString('\n')
+			input := strings.TrimSpace(line)
+			if err != nil && input == "" {
+				// End of input, e.g. a scripted session ran out of answers
+				fmt.Fprintln(a.Out)
+				return changed, nil
+			}
+			if input == "" {
+				break
+			}
+			if strings.EqualFold(input, "q") {
+				return changed, nil
+			}
+			value := ""
+			if input != "-" {
+				estimate, err := strconv.ParseFloat(input, 64)
+				if err != nil || estimate < 0 {
+					fmt.Fprintln(a.Out, t.MutedText("Expected a number like 1, 3 or 0.5"))
+					continue
+				}
+				value = strconv.FormatFloat(estimate, 'f', -1, 64)
+			}
+			if err := a.updateLocalIssue(ctx, p, iss.Number.String(), func(local *issue.Issue) {
+				local.SetField(field, value)
+			}); err != nil {
+				return changed, err
+			}
+			iss.SetField(field, value)
+			changed++
+			break
+		}
+	}
+	return changed, nil
+}
+
+// printEstimateLoad prints the total estimate of items and how it splits
+// across assignees. Issues with several assignees count evenly for each.
+func (a *App) printEstimateLoad(items []IssueFile, field, milestone string) {
+	t := a.Theme
+	var total float64
+	estimated := 0
+	load := map[string]float64{}
+	counts := map[string]int{}
+	for _, item := range items {
+		estimate, ok := issueEstimate(item.Issue, field)
+		if !ok {
+			continue
+		}
+		total += estimate
+		estimated++
+		assignees := item.Issue.Assignees
+		if len(assignees) == 0 {
+			assignees = []string{""}
+		}
+		for _, assignee := range assignees {
+			load[assignee] += estimate / float64(len(assignees))
+			counts[assignee]++
+		}
+	}
+
+	fmt.Fprintln(a.Out)
+	fmt.Fprintf(a.Out, "%s %s %s\n", t.Bold(milestone+":"), formatEstimate(total),
+		t.MutedText(fmt.Sprintf("(%d of %d open issues estimated)", estimated, len(items))))
+	names := make([]string, 0, len(load))
+	for name := range load {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if load[names[i]] != load[names[j]] {
+			return load[names[i]] > load[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	width := 0
+	for _, name := range names {
+		width = max(width, len(assigneeLabel(name)))
+	}
+	for _, name := range names {
+		label := padRight(assigneeLabel(name), width)
+		if name == "" {
+			label = t.MutedText(label)
+		}
+		fmt.Fprintf(a.Out, "  %s  %s %s\n", label, formatEstimate(load[name]),
+			t.MutedText(fmt.Sprintf("(%d issue(s))", counts[name])))
+	}
+}
+
+// issueEstimate returns the estimate of iss in field, if it has a numeric
+// one.
+func issueEstimate(iss issue.Issue, field string) (float64, bool) {
+	value, ok := iss.FieldValue(field)
+	if !ok {
+		return 0, false
+	}
+	estimate, err := strconv.ParseFloat(value, 64)
+	return estimate, err == nil
+}
+
+func assigneeLabel(name string) string {
+	if name == "" {
+		return "(unassigned)"
+	}
+	return "@" + name
+}
+
+// formatEstimate prints an estimate with at most one decimal.
+func formatEstimate(value float64) string {
+	return strconv.FormatFloat(float64(int64(value*10+0.5))/10, 'f', -1, 64)
+}
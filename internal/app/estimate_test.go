@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestEstimate(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	estimated := issue.Issue{Number: "4", Title: "Estimated", State: "open", Milestone: "v2.0", Assignees: []string{"bob"}}
+	estimated.SetField("estimate", "2")
+	for _, iss := range []issue.Issue{
+		{Number: "1", Title: "Login", State: "open", Milestone: "v2.0", Assignees: []string{"alice"}},
+		{Number: "2", Title: "Pairing", State: "open", Milestone: "V2.0", Assignees: []string{"alice", "bob"}},
+		{Number: "3", Title: "Docs", State: "open", Milestone: "v2.0"},
+		estimated,
+		{Number: "5", Title: "Other milestone", State: "open", Milestone: "v1.0"},
+	} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+
+	var out strings.Builder
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	// #1 gets 3, #2 a retry after an invalid answer, #3 is skipped
+	application.In = strings.NewReader("3\nlots\n4\n\n")
+	if err := application.Estimate(context.Background(), EstimateOptions{Milestone: "v2.0"}); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+
+	for number, want := range map[string]string{"1": "3", "2": "4", "3": "", "4": "2", "5": ""} {
+		file, err := findIssueByNumber(p, number)
+		if err != nil {
+			t.Fatalf("find #%s: %v", number, err)
+		}
+		if got, _ := file.Issue.FieldValue("estimate"); got != want {
+			t.Fatalf("expected estimate %q for #%s, got %q", want, number, got)
+		}
+	}
+	output := out.String()
+	if strings.Contains(output, "Other milestone") || strings.Contains(output, "[4/") {
+		t.Fatalf("expected only unestimated v2.0 issues asked for:\n%s", output)
+	}
+	for _, want := range []string{
+		"Expected a number",
+		"Wrote 2 estimate(s)",
+		"v2.0: 9 (3 of 4 open issues estimated)",
+		"  @alice  5 (2 issue(s))",
+		"  @bob    4 (2 issue(s))",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output:\n%s", want, output)
+		}
+	}
+
+	out.Reset()
+	application.In = strings.NewReader("")
+	if err := application.Estimate(context.Background(), EstimateOptions{Milestone: "v2.0", Summary: true}); err != nil {
+		t.Fatalf("plan --summary: %v", err)
+	}
+	if strings.Contains(out.String(), "Estimate") || !strings.Contains(out.String(), "v2.0: 9") {
+		t.Fatalf("unexpected summary:\n%s", out.String())
+	}
+}
@@ -136,6 +136,32 @@ func formatOptionalStringPtr(value *string) string {
 	return fmt.Sprintf("%q", trimmed)
 }
 
+// formatPriority colors the most urgent priority red and the next one
+// yellow.
+func (a *App) formatPriority(format issue.Format, priority string) string {
+	t := a.Theme
+	switch format.PriorityRank(priority) {
+	case -1:
+		return t.MutedText(priority)
+	case 0:
+		return t.ErrorText(priority)
+	case 1:
+		return t.WarningText(priority)
+	}
+	return t.AccentText(priority)
+}
+
+// withoutPriorityLabels leaves out the labels shown as the priority.
+func withoutPriorityLabels(format issue.Format, labels []string) []string {
+	var rest []string
+	for _, label := range labels {
+		if !format.IsPriorityLabel(label) {
+			rest = append(rest, label)
+		}
+	}
+	return rest
+}
+
 func formatStringList(items []string) string {
 	if len(items) == 0 {
 		return "[]"
@@ -281,3 +307,11 @@ func (a *App) formatConflictLines(local, remote issue.Issue, fields []string, la
 	}
 	return lines
 }
+
+// formatEditedBy notes who made an unpushed local change, if recorded.
+func (a *App) formatEditedBy(iss issue.Issue) string {
+	if iss.LastLocalEditBy == "" {
+		return ""
+	}
+	return " " + a.Theme.MutedText("(by "+iss.LastLocalEditBy+")")
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// issueFromPR seeds a new issue from a pull request: its title, a link, the
+// diff stat, and the labels the label rules infer from the changed files.
+func (a *App) issueFromPR(ctx context.Context, cfg config.Config, number string) (issue.Issue, error) {
+	number = strings.TrimPrefix(strings.TrimSpace(number), "#")
+	client, err := a.newProvider(cfg)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	pr, err := client.GetPullRequest(ctx, number)
+	if err != nil {
+		return issue.Issue{}, fmt.Errorf("failed to fetch pull request %s: %w", number, err)
+	}
+	kind := "pull request"
+	if cfg.Repository.Provider == config.ProviderGitLab {
+		kind = "merge request"
+	}
+	body := fmt.Sprintf("Follow-up to %s #%s: %s\n", kind, pr.Number, pr.URL)
+	return issue.Issue{
+		Title:  pr.Title,
+		Labels: inferLabels(cfg.LabelRules, pr.Files),
+		Body:   body + formatDiffStat(pr.Files),
+	}, nil
+}
+
+// issueFromCommit seeds a new issue from a commit in the local git
+// repository, so it works offline.
+func (a *App) issueFromCommit(ctx context.Context, cfg config.Config, ref string) (issue.Issue, error) {
+	out, err := execCommand(ctx, "git", "-C", a.Root, "log", "-1", "--format=%H%n%s", ref, "--")
+	if err != nil {
+		return issue.Issue{}, fmt.Errorf("failed to read commit %s: %w", ref, err)
+	}
+	sha, subject, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	out, err = execCommand(ctx, "git", "-C", a.Root, "show", "--numstat", "--format=", sha, "--")
+	if err != nil {
+		return issue.Issue{}, fmt.Errorf("failed to read commit %s: %w", ref, err)
+	}
+	files := parseNumstat(out)
+	body := fmt.Sprintf("Follow-up to commit %s: %s\n", shortSHA(sha), commitURL(cfg, sha))
+	return issue.Issue{
+		Title:  strings.TrimSpace(subject),
+		Labels: inferLabels(cfg.LabelRules, files),
+		Body:   body + formatDiffStat(files),
+	}, nil
+}
+
+// parseNumstat parses the output of git show --numstat. Binary files have
+// no line counts.
+func parseNumstat(out string) []ghcli.ChangedFile {
+	var files []ghcli.ChangedFile
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		additions, _ := strconv.Atoi(fields[0])
+		deletions, _ := strconv.Atoi(fields[1])
+		files = append(files, ghcli.ChangedFile{Path: fields[2], Additions: additions, Deletions: deletions})
+	}
+	return files
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// commitURL returns the web URL of a commit.
+func commitURL(cfg config.Config, sha string) string {
+	if cfg.Repository.Provider == config.ProviderGitLab {
+		host := cfg.Repository.Host
+		if host == "" {
+			host = "gitlab.com"
+		}
+		return fmt.Sprintf("https://%s/%s/-/commit/%s", host, repoSlug(cfg), sha)
+	}
+	return fmt.Sprintf("https://github.com/%s/commit/%s", repoSlug(cfg), sha)
+}
+
+// diffStatWidth is the width of the +/- bar in formatDiffStat.
+const diffStatWidth = 40
+
+// formatDiffStat renders files like git diff --stat, in a code block.
+func formatDiffStat(files []ghcli.ChangedFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+	nameWidth, countWidth, most := 0, 0, 0
+	additions, deletions := 0, 0
+	for _, f := range files {
+		if len(f.Path) > nameWidth {
+			nameWidth = len(f.Path)
+		}
+		changes := f.Additions + f.Deletions
+		if n := len(strconv.Itoa(changes)); n > countWidth {
+			countWidth = n
+		}
+		if changes > most {
+			most = changes
+		}
+		additions += f.Additions
+		deletions += f.Deletions
+	}
+	var b strings.Builder
+	b.WriteString("\n```\n")
+	for _, f := range files {
+		plus, minus := f.Additions, f.Deletions
+		if most > diffStatWidth {
+			plus = (plus*diffStatWidth + most - 1) / most
+			minus = (minus*diffStatWidth + most - 1) / most
+		}
+		fmt.Fprintf(&b, " %-*s | %*d %s%s\n", nameWidth, f.Path, countWidth, f.Additions+f.Deletions,
+			strings.Repeat("+", plus), strings.Repeat("-", minus))
+	}
+	fmt.Fprintf(&b, " %d files changed, %d insertions(+), %d deletions(-)\n```\n", len(files), additions, deletions)
+	return b.String()
+}
+
+// inferLabels returns the labels of every rule matching one of the files.
+func inferLabels(rules []config.LabelRule, files []ghcli.ChangedFile) []string {
+	var labels []string
+	for _, rule := range rules {
+		if !ruleMatches(rule, files) {
+			continue
+		}
+		for _, label := range rule.Labels {
+			if !containsFold(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	return labels
+}
+
+func ruleMatches(rule config.LabelRule, files []ghcli.ChangedFile) bool {
+	for _, pattern := range rule.Paths {
+		for _, f := range files {
+			if matchPathGlob(pattern, f.Path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchPathGlob matches a slash separated path against a glob pattern in
+// which ** matches any number of directories. Patterns without a slash
+// match the file name.
+func matchPathGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
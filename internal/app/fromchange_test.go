@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestMatchPathGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"docs/**", "docs/guide/intro.md", true},
+		{"docs/**", "src/docs.go", false},
+		{"**/*_test.go", "internal/app/app_test.go", true},
+		{"**/*_test.go", "app_test.go", true},
+		{"*.md", "docs/guide/intro.md", true},
+		{"src/*.go", "src/sub/main.go", false},
+		{"/src/*.go", "src/main.go", true},
+	}
+	for _, tc := range tests {
+		if got := matchPathGlob(tc.pattern, tc.path); got != tc.want {
+			t.Errorf("matchPathGlob(%q, %q) = %v, want %v", tc.pattern, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestNewFromCommit(t *testing.T) {
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "login.md"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	git("add", "docs")
+	git("commit", "-q", "-m", "Document the login flow")
+
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.LabelRules = []config.LabelRule{
+		{Paths: []string{"docs/**"}, Labels: []string{"docs"}},
+		{Paths: []string{"*.go"}, Labels: []string{"code"}},
+	}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	if err := application.NewIssue(context.Background(), "", NewOptions{FromCommit: "HEAD", Labels: []string{"followup"}}); err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	issues, err := loadLocalIssues(p)
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("expected one issue, got %d (%v)", len(issues), err)
+	}
+	created := issues[0].Issue
+	if created.Title != "Document the login flow" {
+		t.Fatalf("unexpected title: %q", created.Title)
+	}
+	if strings.Join(created.Labels, ",") != "docs,followup" {
+		t.Fatalf("unexpected labels: %v", created.Labels)
+	}
+	if !strings.Contains(created.Body, "https://github.com/owner/repo/commit/") ||
+		!strings.Contains(created.Body, " docs/login.md | 2 ++\n") ||
+		!strings.Contains(created.Body, "1 files changed, 2 insertions(+), 0 deletions(-)") {
+		t.Fatalf("unexpected body: %q", created.Body)
+	}
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// openDependencies describes what still stands in the way of closing target:
+// blocked_by issues and sub-issues that are open in the local files. Issues
+// without a local file are not known to be open and are skipped.
+func openDependencies(target issue.Issue, issues []IssueFile) []string {
+	number := target.Number.String()
+	states := make(map[string]string, len(issues))
+	for _, item := range issues {
+		states[item.Issue.Number.String()] = item.State
+	}
+
+	var reasons []string
+	for _, ref := range target.BlockedBy {
+		if state, ok := states[ref.String()]; ok && state != "closed" {
+			reasons = append(reasons, fmt.Sprintf("blocked by open #%s", ref))
+		}
+	}
+
+	var children []string
+	for _, item := range issues {
+		if item.Issue.Parent == nil || item.Issue.Parent.String() != number || item.State == "closed" {
+			continue
+		}
+		children = append(children, item.Issue.Number.String())
+	}
+	sort.Strings(children)
+	for _, child := range children {
+		reasons = append(reasons, fmt.Sprintf("sub-issue #%s is still open", child))
+	}
+	if len(children) == 0 && target.SubIssues != nil && target.SubIssues.Completed < target.SubIssues.Total {
+		// The children are not pulled, but the synced rollup knows about them
+		open := target.SubIssues.Total - target.SubIssues.Completed
+		reasons = append(reasons, fmt.Sprintf("%d sub-issue(s) still open", open))
+	}
+	return reasons
+}
+
+// closeGateWarnings returns the open dependencies of every issue that a push
+// would close, keyed by issue number.
+func closeGateWarnings(changes []massChange, issues []IssueFile) map[string][]string {
+	byNumber := make(map[string]issue.Issue, len(issues))
+	for _, item := range issues {
+		byNumber[item.Issue.Number.String()] = item.Issue
+	}
+	warnings := map[string][]string{}
+	for _, change := range changes {
+		if !change.Close {
+			continue
+		}
+		target, ok := byNumber[change.Number]
+		if !ok {
+			continue
+		}
+		if reasons := openDependencies(target, issues); len(reasons) > 0 {
+			warnings[change.Number] = reasons
+		}
+	}
+	return warnings
+}
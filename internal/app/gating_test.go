@@ -0,0 +1,68 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestCloseWarnsAboutOpenDependencies(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	parent := issue.IssueRef("1")
+	for _, iss := range []issue.Issue{
+		{Number: "1", Title: "Epic", State: "open", BlockedBy: []issue.IssueRef{"2", "3"}},
+		{Number: "2", Title: "Blocker", State: "open"},
+		{Number: "4", Title: "Child", State: "open", Parent: &parent},
+	} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	closed := issue.Issue{Number: "3", Title: "Done", State: "closed"}
+	if err := issue.WriteFile(issue.PathFor(p.ClosedDir, closed.Number, closed.Title), closed); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var errOut bytes.Buffer
+	application := New(root, &offlineRunner{}, io.Discard, &errOut)
+	err := application.Close(context.Background(), "1", CloseOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "open dependencies") {
+		t.Fatalf("expected strict close to fail, got %v", err)
+	}
+	for _, want := range []string{"blocked by open #2", "sub-issue #4 is still open"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in warnings: %s", want, errOut.String())
+		}
+	}
+	if strings.Contains(errOut.String(), "#3") {
+		t.Fatalf("closed blocker should not be reported: %s", errOut.String())
+	}
+	if _, err := os.Stat(issue.PathFor(p.OpenDir, "1", "Epic")); err != nil {
+		t.Fatalf("strict close should leave the issue open: %v", err)
+	}
+
+	errOut.Reset()
+	if err := application.Close(context.Background(), "1", CloseOptions{}); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "blocked by open #2") {
+		t.Fatalf("expected warning on close: %s", errOut.String())
+	}
+	if _, err := os.Stat(issue.PathFor(p.ClosedDir, "1", "Epic")); err != nil {
+		t.Fatalf("expected issue to be closed: %v", err)
+	}
+}
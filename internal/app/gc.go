@@ -0,0 +1,294 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type GCOptions struct {
+	DryRun bool
+	// NoRemote keeps orphaned originals instead of asking the tracker
+	// whether their issue still exists.
+	NoRemote bool
+	// MaxAge is how long editor recovery buffers are kept. Zero keeps them.
+	MaxAge time.Duration
+	// MaxCacheSize is the size in bytes above which timeline cache entries
+	// are dropped. Zero keeps them.
+	MaxCacheSize int64
+}
+
+// GC cleans up the sync directory: it removes the originals of issues that
+// were deleted or transferred, pending comment files without an issue,
+// stale editor recovery buffers and oversized timeline cache entries. It
+// also rewrites the originals in the configured format (storage.originals)
+// and removes the blobs no original references anymore.
+func (a *App) GC(ctx context.Context, opts GCOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	s, err := storeFor(p)
+	if err != nil {
+		return err
+	}
+	localIssues, err := loadLocalIssues(p)
+	if err != nil {
+		return err
+	}
+	local := make(map[string]bool, len(localIssues))
+	for _, item := range localIssues {
+		local[item.Issue.Number.String()] = true
+	}
+
+	var reclaimed int64
+	remove := func(file string, size int64, reason string, rm func() error) error {
+		reclaimed += size
+		if opts.DryRun {
+			fmt.Fprintf(a.Out, "%s %s (%s)\n", t.MutedText("Would remove"), relPath(a.Root, file), reason)
+			return nil
+		}
+		if err := rm(); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.Out, "%s %s (%s)\n", t.SuccessText("Removed"), relPath(a.Root, file), reason)
+		return nil
+	}
+
+	originals, err := listIssueFiles(p, p.OriginalsDir)
+	if err != nil {
+		return err
+	}
+	var orphaned []string
+	for _, file := range originals {
+		number := strings.TrimSuffix(filepath.Base(file), ".md")
+		if filepath.Ext(file) == ".md" && !strings.HasPrefix(number, "T") && !local[number] {
+			orphaned = append(orphaned, number)
+		}
+	}
+	gone := map[string]bool{}
+	if len(orphaned) > 0 && !opts.NoRemote {
+		gone = a.goneIssues(ctx, cfg, orphaned)
+	}
+	for _, number := range orphaned {
+		if !gone[number] {
+			continue
+		}
+		file := filepath.Join(p.OriginalsDir, number+".md")
+		data, err := readIssueData(p, file)
+		if err != nil {
+			return err
+		}
+		if err := remove(file, int64(len(data)), fmt.Sprintf("#%s was deleted or transferred", number), func() error {
+			return removeIssue(p, file)
+		}); err != nil {
+			return err
+		}
+	}
+
+	hashed := originalsHashed(p)
+	migrated := 0
+	referenced := map[string]bool{}
+	for _, file := range originals {
+		if filepath.Ext(file) != ".md" || gone[strings.TrimSuffix(filepath.Base(file), ".md")] {
+			continue
+		}
+		stored, err := readIssue(p, file)
+		if err != nil {
+			fmt.Fprintf(a.Err, "%s %s: %v\n", t.WarningText("Warning:"), relPath(a.Root, file), err)
+			continue
+		}
+		sum, isHashed := blobRef(stored.Body)
+		if isHashed == hashed || (hashed && stored.Body == "") {
+			if isHashed {
+				referenced[sum] = true
+			}
+			continue
+		}
+		original, err := withBlobBody(p, stored)
+		if err != nil {
+			fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), err)
+			referenced[sum] = true
+			continue
+		}
+		migrated++
+		if opts.DryRun {
+			if hashed {
+				referenced[blobSum(issue.PublicBody(original.Body))] = true
+			}
+			continue
+		}
+		if err := writeOriginalIssue(p, original); err != nil {
+			return err
+		}
+		if rewritten, err := readIssue(p, file); err == nil {
+			if sum, ok := blobRef(rewritten.Body); ok {
+				referenced[sum] = true
+			}
+		}
+	}
+
+	blobs, err := s.List(path.Join(paths.SyncDirName, paths.BlobsDirName))
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, name := range blobs {
+		if referenced[path.Base(name)] {
+			continue
+		}
+		removed++
+		if data, err := s.ReadFile(name); err == nil {
+			reclaimed += int64(len(data))
+		}
+		if opts.DryRun {
+			continue
+		}
+		if err := s.Remove(name); err != nil {
+			return err
+		}
+	}
+
+	// Pending comments for an issue that no longer exists are never pushed
+	dirs, err := issueDirs(p, p.StateDirs())
+	if err != nil {
+		return err
+	}
+	for _, stateDir := range dirs {
+		dir := stateDir.Path
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			matches := commentFilePattern.FindStringSubmatch(entry.Name())
+			if entry.IsDir() || matches == nil || local[matches[1]] {
+				continue
+			}
+			file := filepath.Join(dir, entry.Name())
+			if err := remove(file, fileSize(entry), fmt.Sprintf("no issue #%s", matches[1]), func() error {
+				return os.Remove(file)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if entries, err := os.ReadDir(p.RecoveryDir); err == nil && opts.MaxAge > 0 {
+		cutoff := a.Now().Add(-opts.MaxAge)
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || entry.IsDir() || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			file := filepath.Join(p.RecoveryDir, entry.Name())
+			reason := "recovery buffer from " + info.ModTime().Format("2006-01-02")
+			if err := remove(file, info.Size(), reason, func() error {
+				return os.Remove(file)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if entries, err := os.ReadDir(p.TimelineDir); err == nil {
+		for _, entry := range entries {
+			number := strings.TrimSuffix(entry.Name(), ".json")
+			size := fileSize(entry)
+			var reason string
+			switch {
+			case gone[number]:
+				reason = fmt.Sprintf("#%s was deleted or transferred", number)
+			case opts.MaxCacheSize > 0 && size > opts.MaxCacheSize:
+				reason = formatSize(size) + ", over the cache limit"
+			default:
+				continue
+			}
+			file := filepath.Join(p.TimelineDir, entry.Name())
+			if err := remove(file, size, reason, func() error {
+				return os.Remove(file)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	format := config.OriginalsFull
+	if hashed {
+		format = config.OriginalsHashed
+	}
+	if opts.DryRun {
+		fmt.Fprintf(a.Out, "%s %d original(s) to %s and remove %d unreferenced blob(s)\n",
+			t.MutedText("Would migrate"), migrated, format, removed)
+		fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Would reclaim"), formatSize(reclaimed))
+		return nil
+	}
+	fmt.Fprintf(a.Out, "%s %d original(s) to %s, removed %d unreferenced blob(s)\n",
+		t.SuccessText("Migrated"), migrated, format, removed)
+	fmt.Fprintf(a.Out, "%s %s\n", t.SuccessText("Reclaimed"), formatSize(reclaimed))
+	return nil
+}
+
+// goneIssues asks the tracker about the orphaned originals in numbers and
+// returns the ones whose issue no longer exists in the repository. The
+// first error that is not a missing issue stops the check, so nothing is
+// removed when offline.
+func (a *App) goneIssues(ctx context.Context, cfg config.Config, numbers []string) map[string]bool {
+	t := a.Theme
+	gone := map[string]bool{}
+	client, err := a.newProvider(cfg)
+	if err != nil {
+		fmt.Fprintf(a.Err, "%s keeping orphaned originals: %v\n", t.WarningText("Warning:"), err)
+		return gone
+	}
+	for _, number := range numbers {
+		_, err := client.GetIssue(ctx, number)
+		switch {
+		case err == nil:
+		case ghcli.IsIssueNotFound(err, number):
+			gone[number] = true
+		default:
+			fmt.Fprintf(a.Err, "%s keeping orphaned originals: %v\n", t.WarningText("Warning:"), err)
+			return gone
+		}
+	}
+	return gone
+}
+
+// fileSize is the size of a directory entry, zero if it cannot be read.
+func fileSize(entry os.DirEntry) int64 {
+	info, err := entry.Info()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatSize formats a byte count for humans.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+// missingIssueRunner answers every gh call like for an issue that does not
+// exist.
+type missingIssueRunner struct{}
+
+func (r *missingIssueRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	if name == "gh" {
+		return "", errors.New("GraphQL: Could not resolve to an issue or pull request with the number of 7. (repository.issue)")
+	}
+	return "", errors.New("offline")
+}
+
+func TestGCSyncDir(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	kept := issue.Issue{Number: "1", Title: "Still here", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, kept.Number, kept.Title), kept); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	for _, original := range []issue.Issue{kept, {Number: "7", Title: "Transferred", State: "open"}} {
+		if err := writeOriginalIssue(p, original); err != nil {
+			t.Fatalf("write original: %v", err)
+		}
+	}
+	write := func(path string, size int, mtime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+	write(filepath.Join(p.OpenDir, "1.comment.md"), 10, now)
+	write(filepath.Join(p.OpenDir, "9-gone.comment.md"), 10, now)
+	write(filepath.Join(p.RecoveryDir, "old.md"), 10, now.AddDate(0, 0, -40))
+	write(filepath.Join(p.RecoveryDir, "new.md"), 10, now.AddDate(0, 0, -1))
+	write(filepath.Join(p.TimelineDir, "1.json"), 4096, now)
+	write(filepath.Join(p.TimelineDir, "7.json"), 10, now)
+
+	var out bytes.Buffer
+	application := New(root, &missingIssueRunner{}, &out, &out)
+	application.Theme = theme.Plain()
+	application.Now = func() time.Time { return now }
+	opts := GCOptions{MaxAge: 30 * 24 * time.Hour, MaxCacheSize: 1024}
+
+	if err := application.GC(context.Background(), GCOptions{DryRun: true, MaxAge: opts.MaxAge, MaxCacheSize: opts.MaxCacheSize}); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if !strings.Contains(out.String(), "Would remove .issues/.sync/originals/7.md (#7 was deleted or transferred)") {
+		t.Fatalf("expected the orphaned original in the dry run:\n%s", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(p.OriginalsDir, "7.md")); err != nil {
+		t.Fatalf("expected the dry run to keep the original: %v", err)
+	}
+
+	out.Reset()
+	if err := application.GC(context.Background(), opts); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	for _, want := range []string{
+		"Removed .issues/open/9-gone.comment.md (no issue #9)",
+		"Removed .issues/.sync/drafts/old.md (recovery buffer from 2026-09-06)",
+		"Removed .issues/.sync/timeline/1.json (4.0 KiB, over the cache limit)",
+		"Reclaimed ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in:\n%s", want, out.String())
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(p.OriginalsDir, "7.md"),
+		filepath.Join(p.OpenDir, "9-gone.comment.md"),
+		filepath.Join(p.RecoveryDir, "old.md"),
+		filepath.Join(p.TimelineDir, "1.json"),
+		filepath.Join(p.TimelineDir, "7.json"),
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got %v", path, err)
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(p.OriginalsDir, "1.md"),
+		filepath.Join(p.OpenDir, "1.comment.md"),
+		filepath.Join(p.RecoveryDir, "new.md"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s to be kept: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type GrepOptions struct {
+	Context    int  // lines of context around each match
+	IgnoreCase bool // match case-insensitively
+	Fixed      bool // treat the pattern as a literal string
+}
+
+// Grep prints the lines of issue bodies and pulled comments that match
+// pattern, with surrounding context. Body lines are numbered as in the
+// issue file so the references can be opened in an editor.
+func (a *App) Grep(ctx context.Context, pattern string, opts GrepOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	if opts.Fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	var items []IssueFile
+	walkLocalIssues(p, true, func(item IssueFile) {
+		items = append(items, item)
+	}, func(parseErr ParseError) {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
+	})
+	sort.SliceStable(items, func(i, j int) bool {
+		return lessIssueNumber(items[i].Issue.Number.String(), items[j].Issue.Number.String())
+	})
+
+	matched := 0
+	for _, item := range items {
+		data, err := os.ReadFile(item.Path)
+		if err != nil {
+			fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), err)
+			continue
+		}
+		lines, offset := issueBodyLines(string(data))
+		bodyGroups := grepLines(re, lines, opts.Context)
+
+		var comments []grepComment
+		if !item.Issue.Number.IsLocal() {
+			comments = grepComments(p, re, item.Issue.Number.String(), opts.Context)
+		}
+		if len(bodyGroups) == 0 && len(comments) == 0 {
+			continue
+		}
+
+		if matched > 0 {
+			fmt.Fprintln(a.Out)
+		}
+		matched++
+		fmt.Fprintf(a.Out, "%s %s\n", t.FormatIssueHeader(item.State, item.Issue.Number.String(), item.Issue.Title),
+			t.MutedText(relPath(a.Root, item.Path)))
+		a.printGrepGroups(re, lines, offset, bodyGroups)
+		for _, comment := range comments {
+			header := "comment by " + comment.author
+			if comment.url != "" {
+				header += " " + t.Link(comment.url, comment.url)
+			}
+			fmt.Fprintf(a.Out, "  %s\n", t.MutedText(header))
+			a.printGrepGroups(re, comment.lines, 0, comment.groups)
+		}
+	}
+
+	if matched == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No matches"))
+	}
+	return nil
+}
+
+// grepGroup is a run of lines to print: matches and their context.
+type grepGroup struct {
+	start, end int // half-open range of line indices
+}
+
+type grepComment struct {
+	author string
+	url    string
+	lines  []string
+	groups []grepGroup
+}
+
+// issueBodyLines splits an issue file into the lines after its front
+// matter and returns how many lines precede them.
+func issueBodyLines(data string) ([]string, int) {
+	data = strings.TrimPrefix(data, "\xef\xbb\xbf")
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 || lines[0] != "---" {
+		return lines, 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "---" {
+			return lines[i+1:], i + 1
+		}
+	}
+	return nil, len(lines)
+}
+
+// grepLines finds the lines matching re and merges each with context
+// lines around it into groups, joining groups that touch.
+func grepLines(re *regexp.Regexp, lines []string, context int) []grepGroup {
+	var groups []grepGroup
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+		start, end := max(i-context, 0), min(i+context+1, len(lines))
+		if n := len(groups); n > 0 && start <= groups[n-1].end {
+			groups[n-1].end = end
+			continue
+		}
+		groups = append(groups, grepGroup{start, end})
+	}
+	return groups
+}
+
+func grepComments(p paths.Paths, re *regexp.Regexp, number string, context int) []grepComment {
+	cache, ok := loadCommentCache(p, number)
+	if !ok {
+		return nil
+	}
+	var result []grepComment
+	for _, comment := range cache.Comments {
+		lines := strings.Split(strings.ReplaceAll(comment.Body, "\r\n", "\n"), "\n")
+		groups := grepLines(re, lines, context)
+		if len(groups) == 0 {
+			continue
+		}
+		author := comment.Author
+		if author == "" {
+			author = "ghost"
+		}
+		result = append(result, grepComment{author: author, url: comment.URL, lines: lines, groups: groups})
+	}
+	return result
+}
+
+// printGrepGroups prints matching lines as "N: text" with the matches
+// highlighted and context lines as "N- text", separating groups with --.
+func (a *App) printGrepGroups(re *regexp.Regexp, lines []string, offset int, groups []grepGroup) {
+	t := a.Theme
+	for gi, group := range groups {
+		if gi > 0 {
+			fmt.Fprintln(a.Out, t.MutedText("  --"))
+		}
+		for i := group.start; i < group.end; i++ {
+			number := strconv.Itoa(offset + i + 1)
+			line := lines[i]
+			matches := re.FindAllStringIndex(line, -1)
+			if len(matches) == 0 {
+				fmt.Fprintf(a.Out, "  %s %s\n", t.MutedText(number+"-"), t.MutedText(line))
+				continue
+			}
+			var b strings.Builder
+			last := 0
+			for _, m := range matches {
+				b.WriteString(line[last:m[0]])
+				b.WriteString(t.AccentText(line[m[0]:m[1]]))
+				last = m[1]
+			}
+			b.WriteString(line[last:])
+			fmt.Fprintf(a.Out, "  %s %s\n", t.AccentText(number+":"), b.String())
+		}
+	}
+}
+
+// lessIssueNumber orders remote issues numerically before local ones.
+func lessIssueNumber(a, b string) bool {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return an < bn
+	case aErr == nil:
+		return true
+	case bErr == nil:
+		return false
+	}
+	return a < b
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestGrep(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "12", Title: "Crash on save", State: "open",
+		Body: "Steps:\n1. open\n2. save\nthrows NullPointerException\nat Foo.bar\nat Foo.baz\n\nlater\nmore\nNullPointerException again\n"}
+	path := issue.PathFor(p.OpenDir, iss.Number, iss.Title)
+	if err := issue.WriteFile(path, iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	other := issue.Issue{Number: "3", Title: "Unrelated", State: "open", Body: "Nothing here\n"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, other.Number, other.Title), other); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := saveCommentCache(p, "3", CommentCache{Comments: []ghcli.Comment{
+		{ID: "1", Author: "alice", Body: "Seen a nullpointerexception too", URL: "https://github.com/owner/repo/issues/3#issuecomment-1"},
+	}}); err != nil {
+		t.Fatalf("comments: %v", err)
+	}
+
+	var out strings.Builder
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	if err := application.Grep(context.Background(), "NullPointerException", GrepOptions{Context: 1}); err != nil {
+		t.Fatalf("grep: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	lines, offset := issueBodyLines(string(data))
+	// Line numbers refer to the file, which separates the body with a blank line
+	offset += slices.Index(lines, "Steps:")
+	if offset < 1 {
+		t.Fatalf("unexpected body lines: %q", lines)
+	}
+	got := out.String()
+	for _, want := range []string{
+		relPath(root, path),
+		"  " + strconv.Itoa(offset+3) + "- 2. save\n",
+		"  " + strconv.Itoa(offset+4) + ": throws NullPointerException\n",
+		"  " + strconv.Itoa(offset+5) + "- at Foo.bar\n",
+		"  --\n",
+		"  " + strconv.Itoa(offset+10) + ": NullPointerException again\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "#3") {
+		t.Fatalf("expected the case-sensitive search to skip the comment:\n%s", got)
+	}
+
+	out.Reset()
+	if err := application.Grep(context.Background(), "nullpointer", GrepOptions{IgnoreCase: true, Fixed: true}); err != nil {
+		t.Fatalf("grep: %v", err)
+	}
+	got = out.String()
+	if !strings.Contains(got, "comment by alice") || !strings.Contains(got, "1: Seen a nullpointerexception too") {
+		t.Fatalf("expected the comment match:\n%s", got)
+	}
+	if strings.Index(got, "#3") > strings.Index(got, "#12") {
+		t.Fatalf("expected issues in number order:\n%s", got)
+	}
+
+	out.Reset()
+	if err := application.Grep(context.Background(), "no such text", GrepOptions{}); err != nil {
+		t.Fatalf("grep: %v", err)
+	}
+	if !strings.Contains(out.String(), "No matches") {
+		t.Fatalf("expected no matches: %s", out.String())
+	}
+	if err := application.Grep(context.Background(), "(", GrepOptions{}); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
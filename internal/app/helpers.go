@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -16,10 +17,12 @@ import (
 	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
 	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/search"
 )
 
 // localRefPattern matches local issue references like #T1, #T42, #Tabc123 (T followed by alphanumerics)
-var localRefPattern = regexp.MustCompile(`#(T[a-zA-Z0-9]+)`)
+// as well as wiki links like [[T1]]
+var localRefPattern = regexp.MustCompile(`(?:#|\[\[)(T[a-zA-Z0-9]+)`)
 
 // diffIssue computes the change for a local vs original issue.
 func diffIssue(original issue.Issue, local issue.Issue) ghcli.IssueChange {
@@ -27,8 +30,8 @@ func diffIssue(original issue.Issue, local issue.Issue) ghcli.IssueChange {
 	if original.Title != local.Title {
 		change.Title = &local.Title
 	}
-	if original.Body != local.Body {
-		change.Body = &local.Body
+	if body := issue.PublicBody(local.Body); issue.PublicBody(original.Body) != body {
+		change.Body = &body
 	}
 	change.AddLabels, change.RemoveLabels = diffStringSet(original.Labels, local.Labels)
 	change.AddAssignees, change.RemoveAssignees = diffStringSet(original.Assignees, local.Assignees)
@@ -97,12 +100,7 @@ func applyMapping(issueItem *issue.Issue, mapping map[string]string) bool {
 
 	// Apply mapping to body
 	body := localRefPattern.ReplaceAllStringFunc(issueItem.Body, func(match string) string {
-		id := strings.TrimPrefix(match, "#")
-		if real, ok := mapping[id]; ok {
-			changed = true
-			return "#" + real
-		}
-		return match
+		return mapLocalRef(match, mapping)
 	})
 	if body != issueItem.Body {
 		issueItem.Body = body
@@ -111,12 +109,7 @@ func applyMapping(issueItem *issue.Issue, mapping map[string]string) bool {
 
 	// Apply mapping to title
 	title := localRefPattern.ReplaceAllStringFunc(issueItem.Title, func(match string) string {
-		id := strings.TrimPrefix(match, "#")
-		if real, ok := mapping[id]; ok {
-			changed = true
-			return "#" + real
-		}
-		return match
+		return mapLocalRef(match, mapping)
 	})
 	if title != issueItem.Title {
 		issueItem.Title = title
@@ -136,6 +129,20 @@ func applyMapping(issueItem *issue.Issue, mapping map[string]string) bool {
 	return changed
 }
 
+// mapLocalRef rewrites a localRefPattern match to the real issue number.
+func mapLocalRef(match string, mapping map[string]string) string {
+	if id, ok := strings.CutPrefix(match, "[["); ok {
+		if real, ok := mapping[id]; ok {
+			return "[[#" + real
+		}
+		return match
+	}
+	if real, ok := mapping[strings.TrimPrefix(match, "#")]; ok {
+		return "#" + real
+	}
+	return match
+}
+
 func applyMappingToRefs(refs []issue.IssueRef, mapping map[string]string, changed bool) ([]issue.IssueRef, bool) {
 	if len(refs) == 0 {
 		return refs, changed
@@ -196,11 +203,51 @@ func filterIssuesByArgs(root string, issues []IssueFile, args []string) ([]Issue
 	return filtered, nil
 }
 
-func dirForState(p paths.Paths, state string) string {
+// routeIssue moves the issue file at path into the directory its state,
+// labels and milestone call for, keeping its name, and returns the new
+// path. Files outside the issue directories, such as drafts, stay put.
+func routeIssue(p paths.Paths, path, state string, iss issue.Issue) (string, error) {
+	dir := filepath.Dir(path)
+	if !slices.ContainsFunc(p.StateDirs(), func(stateDir paths.StateDir) bool {
+		return stateDir.Path == dir || (stateDir.Nested && filepath.Dir(dir) == stateDir.Path)
+	}) {
+		return path, nil
+	}
+	newPath := filepath.Join(issueDir(p, state, iss), filepath.Base(path))
+	if err := moveIssue(p, path, newPath); err != nil {
+		return path, err
+	}
+	return newPath, nil
+}
+
+// issueDir returns the directory an issue in state belongs in. The first
+// view matching one of its labels holds it, or else the issues directory,
+// arranged by the configured layout.
+func issueDir(p paths.Paths, state string, iss issue.Issue) string {
+	base := p.IssuesDir
+	for _, view := range p.Views {
+		if slices.ContainsFunc(iss.Labels, func(label string) bool { return strings.EqualFold(label, view.Label) }) {
+			base = view.Dir
+			break
+		}
+	}
+	switch p.Layout {
+	case paths.LayoutFlat:
+		return filepath.Join(base, paths.AllDirName)
+	case paths.LayoutMilestone:
+		dir := paths.NoMilestoneDirName
+		if iss.Milestone != "" {
+			dir = "milestone"
+			if slug := issue.Slugify(iss.Milestone); slug != "" && slug != paths.NoMilestoneDirName {
+				dir = slug
+			}
+		}
+		return filepath.Join(base, paths.ByMilestoneDirName, dir)
+	}
 	if state == "closed" {
-		return p.ClosedDir
+		return filepath.Join(base, paths.ClosedDirName)
 	}
-	return p.OpenDir
+	return filepath.Join(base, paths.OpenDirName)
 }
 
 func stringSlicesEqual(a, b []string) bool {
@@ -236,12 +283,38 @@ func randomLabelColor() string {
 	return colors[rand.Intn(len(colors))]
 }
 
-func (a *App) detectRepoFromGit(ctx context.Context) (string, string, error) {
+// localEditor returns who is editing this checkout: git's user.name, or
+// user.email when no name is set. It is "" outside of git.
+func (a *App) localEditor(ctx context.Context) string {
+	for _, key := range []string{"user.name", "user.email"} {
+		if out, err := a.Runner.Run(ctx, "git", "-C", a.Root, "config", key); err == nil && strings.TrimSpace(out) != "" {
+			return strings.TrimSpace(out)
+		}
+	}
+	return ""
+}
+
+// detectedRemote describes the repository the origin remote points at.
+type detectedRemote struct {
+	Owner    string
+	Repo     string
+	Provider string
+	Host     string
+}
+
+func (a *App) detectRepoFromGit(ctx context.Context) (detectedRemote, error) {
 	out, err := a.Runner.Run(ctx, "git", "config", "--get", "remote.origin.url")
 	if err != nil {
-		return "", "", err
+		return detectedRemote{}, err
+	}
+	if owner, repo, err := parseRemote(out); err == nil {
+		return detectedRemote{Owner: owner, Repo: repo, Provider: config.ProviderGitHub}, nil
+	}
+	owner, repo, host, err := parseGitLabRemote(out)
+	if err != nil {
+		return detectedRemote{}, err
 	}
-	return parseRemote(out)
+	return detectedRemote{Owner: owner, Repo: repo, Provider: config.ProviderGitLab, Host: host}, nil
 }
 
 var remotePattern = regexp.MustCompile(`(?i)(?:github\.com[:/])([^/]+)/([^/\s]+?)(?:\.git)?$`)
@@ -255,6 +328,23 @@ func parseRemote(remote string) (string, string, error) {
 	return match[1], match[2], nil
 }
 
+// gitLabRemotePattern matches remotes on hosts with "gitlab" in their name.
+// GitLab projects can live in nested groups, so the owner may contain slashes.
+var gitLabRemotePattern = regexp.MustCompile(`(?i)^(?:[a-z+]+://)?(?:[^@/]+@)?([^:/]*gitlab[^:/]*)(?::\d+)?[:/](.+)/([^/\s]+?)(?:\.git)?/?$`)
+
+func parseGitLabRemote(remote string) (string, string, string, error) {
+	remote = strings.TrimSpace(remote)
+	match := gitLabRemotePattern.FindStringSubmatch(remote)
+	if len(match) < 4 {
+		return "", "", "", fmt.Errorf("unsupported remote: %s", remote)
+	}
+	host := strings.ToLower(match[1])
+	if host == "gitlab.com" {
+		host = ""
+	}
+	return strings.TrimPrefix(match[2], "/"), match[3], host, nil
+}
+
 func relPath(root, path string) string {
 	if root == "" {
 		return filepath.ToSlash(path)
@@ -277,6 +367,15 @@ func loadConfig(path string) (config.Config, error) {
 	return cfg, nil
 }
 
+// issueFormat returns the format of the issue files cfg asks for.
+func issueFormat(cfg config.Config) issue.Format {
+	return issue.Format{
+		PriorityPrefix: cfg.Priority.LabelPrefix,
+		PriorityLevels: cfg.Priority.Levels,
+		Vault:          cfg.Vault,
+	}
+}
+
 func repoSlug(cfg config.Config) string {
 	owner := strings.TrimSpace(cfg.Repository.Owner)
 	repo := strings.TrimSpace(cfg.Repository.Repo)
@@ -285,3 +384,131 @@ func repoSlug(cfg config.Config) string {
 	}
 	return owner + "/" + repo
 }
+
+// searchDataFor converts a local issue file into the form used by the search
+// package for matching and sorting.
+func searchDataFor(format issue.Format, item IssueFile) search.IssueData {
+	var syncedAt, createdAt, updatedAt *int64
+	if item.Issue.SyncedAt != nil {
+		ts := item.Issue.SyncedAt.Unix()
+		syncedAt = &ts
+	}
+	if item.Issue.CreatedAt != nil {
+		ts := item.Issue.CreatedAt.Unix()
+		createdAt = &ts
+	}
+	if item.Issue.UpdatedAt != nil {
+		ts := item.Issue.UpdatedAt.Unix()
+		updatedAt = &ts
+	}
+	var fields map[string]string
+	for name := range item.Issue.Fields {
+		if value, ok := item.Issue.FieldValue(name); ok {
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			fields[name] = value
+		}
+	}
+	var comments, reactions *int64
+	if !item.Issue.Number.IsLocal() {
+		c, r := int64(item.Issue.CommentCount), int64(item.Issue.Reactions)
+		comments, reactions = &c, &r
+	}
+	return search.IssueData{
+		Number:       item.Issue.Number,
+		Title:        item.Issue.Title,
+		Body:         item.Issue.Body,
+		State:        item.State,
+		Labels:       item.Issue.Labels,
+		Assignees:    item.Issue.Assignees,
+		Author:       item.Issue.Author,
+		Milestone:    item.Issue.Milestone,
+		IssueType:    item.Issue.IssueType,
+		Projects:     item.Issue.Projects,
+		Priority:     format.Priority(item.Issue),
+		SyncedAt:     syncedAt,
+		CreatedAt:    createdAt,
+		UpdatedAt:    updatedAt,
+		PriorityRank: priorityRank(format, item.Issue),
+		Comments:     comments,
+		Reactions:    reactions,
+		Fields:       fields,
+	}
+}
+
+// priorityRank orders issues by priority for sort:priority, higher being
+// more urgent. Unknown priorities rank below the configured levels; issues
+// without one have none.
+func priorityRank(format issue.Format, iss issue.Issue) *int64 {
+	priority := format.Priority(iss)
+	if priority == "" {
+		return nil
+	}
+	rank := int64(0)
+	if idx := format.PriorityRank(priority); idx >= 0 {
+		rank = int64(len(format.Levels()) - idx)
+	}
+	return &rank
+}
+
+// removeStrings returns values without any entry in remove.
+func removeStrings(values, remove []string) []string {
+	drop := make(map[string]struct{}, len(remove))
+	for _, r := range remove {
+		drop[r] = struct{}{}
+	}
+	var result []string
+	for _, v := range values {
+		if _, ok := drop[v]; !ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// suggestLabel returns the known label closest to name, or "" if none is
+// close enough to plausibly be what was meant.
+func suggestLabel(name string, known []string) string {
+	target := []rune(strings.ToLower(name))
+	maxDist := len(target) / 3
+	if maxDist < 1 {
+		maxDist = 1
+	}
+	best := ""
+	bestDist := maxDist + 1
+	for _, candidate := range known {
+		dist := editDistance(target, []rune(strings.ToLower(candidate)))
+		if dist < bestDist {
+			best = candidate
+			bestDist = dist
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance extended to count a swap of two
+// adjacent characters as a single edit, which catches the most common typos.
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+type InboxOptions struct {
+	All      bool // Include notifications that were already read
+	Pull     bool // Pull the affected issues without asking
+	MarkRead bool // Mark the shown notifications as read
+}
+
+// Inbox shows the user's notifications for the configured repository,
+// correlated with local issue files, and offers to pull the affected issues.
+func (a *App) Inbox(ctx context.Context, opts InboxOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if opts.MarkRead {
+		if err := a.checkWritable(cfg, "--mark-read"); err != nil {
+			return err
+		}
+	}
+	t := a.Theme
+
+	newProvider := a.newProvider
+	if opts.MarkRead {
+		newProvider = a.newWriteProvider
+	}
+	client, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	notifications, err := client.ListNotifications(ctx, opts.All)
+	if err != nil {
+		return fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+
+	localByNumber := map[string]IssueFile{}
+	if localIssues, err := loadLocalIssues(p); err == nil {
+		for _, item := range localIssues {
+			localByNumber[item.Issue.Number.String()] = item
+		}
+	}
+
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].UpdatedAt.After(notifications[j].UpdatedAt)
+	})
+
+	var toPull []string
+	seen := map[string]struct{}{}
+	other := 0
+	shown := 0
+	for _, n := range notifications {
+		if n.SubjectType != "Issue" || n.Number == "" {
+			other++
+			continue
+		}
+		shown++
+
+		status := t.AccentText("new")
+		state := "open"
+		if local, ok := localByNumber[n.Number]; ok {
+			state = local.State
+			status = t.MutedText("local")
+			if original, hasOriginal := readOriginalIssue(p, n.Number); !hasOriginal || !issue.EqualIgnoringSyncedAt(local.Issue, original) {
+				status = t.WarningText("modified locally")
+			}
+		}
+		marker := " "
+		if n.Unread {
+			marker = t.AccentText("*")
+		}
+		fmt.Fprintf(a.Out, "%s %s %s\n", marker, t.FormatIssueHeader(state, n.Number, n.Title),
+			t.MutedText(fmt.Sprintf("(%s, %s)", n.Reason, formatRelativeTime(a.Now(), n.UpdatedAt))))
+		fmt.Fprintf(a.Out, "    %s\n", status)
+
+		if _, ok := seen[n.Number]; !ok {
+			seen[n.Number] = struct{}{}
+			toPull = append(toPull, n.Number)
+		}
+	}
+
+	if shown == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("Inbox is empty"))
+	}
+	if other > 0 {
+		fmt.Fprintf(a.Out, "%s\n", t.MutedText(fmt.Sprintf("%d notification(s) for pull requests or other activity not shown", other)))
+	}
+
+	if opts.MarkRead {
+		for _, n := range notifications {
+			if !n.Unread || n.SubjectType != "Issue" {
+				continue
+			}
+			if err := client.MarkNotificationRead(ctx, n.ID); err != nil {
+				fmt.Fprintf(a.Err, "%s marking notification read: %v\n", t.WarningText("Warning:"), err)
+			}
+		}
+	}
+
+	if len(toPull) == 0 {
+		return nil
+	}
+	if !opts.Pull {
+		in := a.In
+		if in == nil {
+			in = os.Stdin
+		}
+		fmt.Fprintf(a.Out, "%s ", t.AccentText(fmt.Sprintf("Pull %d issue(s)? [y/N]", len(toPull))))
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			return nil
+		}
+	}
+	return a.Pull(ctx, PullOptions{}, toPull)
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type inboxRunner struct{}
+
+func (inboxRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	if len(args) > 1 && args[1] == "repos/owner/repo/notifications" {
+		return `{"id":"1","reason":"mention","unread":true,"updated_at":"2026-01-02T10:00:00Z","subject":{"title":"Crash on start","type":"Issue","url":"https://api.github.com/repos/owner/repo/issues/12"}}
+{"id":"2","reason":"review_requested","unread":true,"updated_at":"2026-01-02T09:00:00Z","subject":{"title":"Fix crash","type":"PullRequest","url":"https://api.github.com/repos/owner/repo/pulls/13"}}
+{"id":"3","reason":"subscribed","unread":false,"updated_at":"2026-01-01T09:00:00Z","subject":{"title":"Docs","type":"Issue","url":"https://api.github.com/repos/owner/repo/issues/4"}}
+`, nil
+	}
+	return "", errors.New("unexpected call")
+}
+
+func TestInboxCorrelatesLocalIssues(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	local := issue.Issue{Number: "4", Title: "Docs", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, local.Number, local.Title), local); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := writeOriginalIssue(p, local); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+
+	var out strings.Builder
+	application := New(root, inboxRunner{}, &out, io.Discard)
+	application.Now = func() time.Time { return time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC) }
+	application.In = strings.NewReader("n\n")
+	if err := application.Inbox(context.Background(), InboxOptions{}); err != nil {
+		t.Fatalf("inbox: %v", err)
+	}
+	output := out.String()
+	for _, want := range []string{"Crash on start", "new", "Docs", "local", "1 notification(s) for pull requests", "Pull 2 issue(s)?"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Index(output, "Crash on start") > strings.Index(output, "Docs") {
+		t.Fatalf("expected newest notification first:\n%s", output)
+	}
+}
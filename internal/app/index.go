@@ -0,0 +1,222 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/store"
+)
+
+// issueIndexVersion is bumped whenever the parsed representation of an
+// issue changes, which discards existing indexes.
+const issueIndexVersion = 2
+
+// issueIndex caches the parsed issue files of a plain directory mirror in
+// .issues/.sync/index.json, so list, status, and search over thousands of
+// issues don't re-parse every file. An entry is used as long as the size
+// and modification time of its file are unchanged.
+type issueIndex struct {
+	Version int                   `json:"version"`
+	Entries map[string]indexEntry `json:"entries"`
+
+	path  string
+	dirty bool
+}
+
+type indexEntry struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mtime"`
+	// Issue is kept encoded so every lookup returns a fresh copy.
+	Issue json.RawMessage `json:"issue"`
+}
+
+var (
+	indexesMu sync.Mutex
+	indexes   = map[string]*issueIndex{}
+)
+
+// indexFor returns the index of p, loading it on first use. A missing,
+// unreadable, or outdated index starts out empty.
+func indexFor(p paths.Paths) *issueIndex {
+	if idx, ok := indexes[p.IssuesDir]; ok {
+		return idx
+	}
+	idx := &issueIndex{path: p.IndexPath}
+	if data, err := os.ReadFile(p.IndexPath); err == nil {
+		if json.Unmarshal(data, idx) != nil || idx.Version != issueIndexVersion {
+			idx.Entries = nil
+		}
+	}
+	idx.Version = issueIndexVersion
+	if idx.Entries == nil {
+		idx.Entries = make(map[string]indexEntry)
+	}
+	indexes[p.IssuesDir] = idx
+	return idx
+}
+
+// indexedIssue parses the issue file at path, using the index if the file
+// didn't change since it was indexed.
+func indexedIssue(p paths.Paths, name, path string) (issue.Issue, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	if cached, ok := lookupIndex(p, name, info); ok {
+		return cached, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	parsed, err := p.Format.ParseNamed(path, data)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	updateIndex(p, name, info, parsed)
+	return parsed, nil
+}
+
+// indexedHeader is indexedIssue for callers that don't need the body. An
+// index miss reads only the front matter and leaves the index as is.
+func indexedHeader(p paths.Paths, name, path string) (issue.Issue, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	if cached, ok := lookupIndex(p, name, info); ok {
+		cached.Body = ""
+		return cached, nil
+	}
+	return p.Format.ReadHeaderFile(path)
+}
+
+// lookupIndex returns the indexed issue of a file if it is still current.
+func lookupIndex(p paths.Paths, name string, info os.FileInfo) (issue.Issue, bool) {
+	indexesMu.Lock()
+	entry, ok := indexFor(p).Entries[name]
+	indexesMu.Unlock()
+	if !ok || entry.Size != info.Size() || entry.ModTime != info.ModTime().UnixNano() {
+		return issue.Issue{}, false
+	}
+	var cached issue.Issue
+	if err := json.Unmarshal(entry.Issue, &cached); err != nil {
+		return issue.Issue{}, false
+	}
+	return cached, true
+}
+
+// updateIndex records the parsed content of a file as of info.
+func updateIndex(p paths.Paths, name string, info os.FileInfo, parsed issue.Issue) {
+	encoded, err := json.Marshal(parsed)
+	if err != nil {
+		return
+	}
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+	idx := indexFor(p)
+	idx.Entries[name] = indexEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Issue: encoded}
+	idx.dirty = true
+}
+
+// forgetIndexed drops the entry of a file that was moved or removed.
+func forgetIndexed(p paths.Paths, name string) {
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+	idx := indexFor(p)
+	if _, ok := idx.Entries[name]; ok {
+		delete(idx.Entries, name)
+		idx.dirty = true
+	}
+}
+
+// saveIndexes writes every index that changed. Writing goes through a
+// temporary file so a concurrent reader never sees half an index.
+func saveIndexes() error {
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+	var errs []error
+	for dir, idx := range indexes {
+		delete(indexes, dir)
+		if !idx.dirty {
+			continue
+		}
+		// The directory may be gone, e.g. after a failed init
+		if err := idx.save(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("saving issue index: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (idx *issueIndex) save() error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(idx.path), ".index-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), idx.path)
+}
+
+// RebuildCache discards the issue index and parses every issue file again.
+func (a *App) RebuildCache(ctx context.Context) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if cfg.Storage.Backend != store.BackendDir {
+		fmt.Fprintf(a.Out, "The %s storage backend needs no cache\n", cfg.Storage.Backend)
+		return nil
+	}
+	if err := os.Remove(p.IndexPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	indexesMu.Lock()
+	indexes[p.IssuesDir] = &issueIndex{Version: issueIndexVersion, Entries: make(map[string]indexEntry), path: p.IndexPath}
+	indexesMu.Unlock()
+
+	result := loadLocalIssuesWithErrors(p)
+	drafts := loadDraftIssues(p)
+	count := len(result.Issues) + len(drafts.Issues)
+	result.Errors = append(result.Errors, drafts.Errors...)
+	originals, err := listIssueFiles(p, p.OriginalsDir)
+	if err != nil {
+		return err
+	}
+	for _, path := range originals {
+		if filepath.Ext(path) != ".md" {
+			continue
+		}
+		if _, err := readIssue(p, path); err != nil {
+			result.Errors = append(result.Errors, ParseError{Path: relPath(a.Root, path), Err: err})
+		}
+	}
+	for _, parseErr := range result.Errors {
+		fmt.Fprintf(a.Err, "%s %s: %v\n", a.Theme.WarningText("Warning:"), parseErr.Path, parseErr.Err)
+	}
+	if err := saveIndexes(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%s %d issues\n", a.Theme.SuccessText("Indexed"), count)
+	return nil
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestIssueIndex(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	path := issue.PathFor(p.OpenDir, "1", "Crash on start")
+	if err := issue.WriteFile(path, issue.Issue{Number: "1", Title: "Crash on start", State: "open"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	ctx := context.Background()
+	var out bytes.Buffer
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	if err := application.RebuildCache(ctx); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if !strings.Contains(out.String(), "1 issues") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+
+	// Tamper with the index to tell cached results from parsed ones
+	var idx issueIndex
+	data, err := os.ReadFile(p.IndexPath)
+	if err != nil {
+		t.Fatalf("read index: %v", err)
+	}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatalf("decode index: %v", err)
+	}
+	entry := idx.Entries["open/1-crash-on-start.md"]
+	entry.Issue, _ = json.Marshal(issue.Issue{Number: "1", Title: "From the index", State: "open"})
+	idx.Entries["open/1-crash-on-start.md"] = entry
+	data, _ = json.Marshal(idx)
+	if err := os.WriteFile(p.IndexPath, data, 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+
+	local, err := loadLocalIssues(p)
+	if err != nil || len(local) != 1 || local[0].Issue.Title != "From the index" {
+		t.Fatalf("expected the indexed issue, got %+v (%v)", local, err)
+	}
+
+	// A changed file is parsed again
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	local, err = loadLocalIssues(p)
+	if err != nil || len(local) != 1 || local[0].Issue.Title != "Crash on start" {
+		t.Fatalf("expected the file to be parsed again, got %+v (%v)", local, err)
+	}
+	if err := application.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var phaseMu sync.Mutex
+var currentPhase string
+
+// setPhase records what the running command is doing, so an interrupt
+// can say where it stopped. Progress reporters record their phases too.
+func setPhase(phase string) {
+	phaseMu.Lock()
+	defer phaseMu.Unlock()
+	currentPhase = phase
+}
+
+// InterruptError turns an error caused by cancelling the command, such as
+// on ctrl-C, into one naming the phase it interrupted. Other errors are
+// returned as they are.
+func InterruptError(err error) error {
+	if !errors.Is(err, context.Canceled) {
+		return err
+	}
+	phaseMu.Lock()
+	phase := currentPhase
+	phaseMu.Unlock()
+	if phase == "" {
+		return errors.New("interrupted")
+	}
+	return fmt.Errorf("interrupted while %s", strings.ToLower(phase[:1])+phase[1:])
+}
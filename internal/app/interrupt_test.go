@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/lock"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+// interruptingRunner cancels the command when it is asked to list issues,
+// the way ctrl-C does while gh runs.
+type interruptingRunner struct {
+	cancel context.CancelFunc
+}
+
+func (r *interruptingRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	if strings.Contains(strings.Join(args, " "), "issues(") {
+		r.cancel()
+		return "", fmt.Errorf("gh api graphql: %w", ctx.Err())
+	}
+	return "", errors.New("offline")
+}
+
+func TestPullReportsInterruptedPhase(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	application := New(root, &interruptingRunner{cancel: cancel}, io.Discard, io.Discard)
+	application.Theme = theme.Plain()
+	err := application.Pull(ctx, PullOptions{}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the pull to be cancelled, got %v", err)
+	}
+	if got := InterruptError(err).Error(); got != "interrupted while listing issues" {
+		t.Fatalf("unexpected interrupt error %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(p.SyncDir, lock.LockFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected the lock to be released, got %v", err)
+	}
+	if err := errors.New("offline"); InterruptError(err) != err {
+		t.Fatal("expected other errors to be kept")
+	}
+}
@@ -0,0 +1,453 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type LabelAuditOptions struct {
+	Merge bool // Offer to merge each near-duplicate pair
+}
+
+// LabelMerge is a label merge done locally that push still has to apply to
+// the remote label itself: From is renamed to To, or deleted if To exists.
+type LabelMerge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func loadLabelMerges(p paths.Paths) ([]LabelMerge, error) {
+	var merges []LabelMerge
+	data, err := os.ReadFile(p.LabelMergesPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &merges); err != nil {
+		return nil, err
+	}
+	return merges, nil
+}
+
+func saveLabelMerges(p paths.Paths, merges []LabelMerge) error {
+	if len(merges) == 0 {
+		if err := os.Remove(p.LabelMergesPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(merges, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(p.LabelMergesPath, data, 0o644)
+}
+
+// queueLabelMerge adds from -> to to the queue. Earlier merges into from are
+// redirected to to, and a merge back into a queued source cancels out.
+func queueLabelMerge(merges []LabelMerge, from, to string) []LabelMerge {
+	result := make([]LabelMerge, 0, len(merges)+1)
+	for _, m := range merges {
+		if strings.EqualFold(m.To, from) {
+			m.To = to
+		}
+		if m.From == m.To {
+			continue
+		}
+		result = append(result, m)
+	}
+	return append(result, LabelMerge{From: from, To: to})
+}
+
+// labelUsage is the audit line for a single label.
+type labelUsage struct {
+	Name     string
+	Open     int
+	Closed   int
+	LastUsed *time.Time
+	Known    bool // Exists in the label cache
+}
+
+func (u labelUsage) Total() int {
+	return u.Open + u.Closed
+}
+
+// auditLabels counts the use of every cached or used label. Last use is the
+// most recent update of an issue carrying the label.
+func auditLabels(cache LabelCache, issues []IssueFile) []labelUsage {
+	byName := map[string]*labelUsage{}
+	get := func(name string) *labelUsage {
+		key := strings.ToLower(name)
+		u, ok := byName[key]
+		if !ok {
+			u = &labelUsage{Name: name}
+			byName[key] = u
+		}
+		return u
+	}
+	for _, l := range cache.Labels {
+		get(l.Name).Known = true
+	}
+	for _, item := range issues {
+		when := item.Issue.UpdatedAt
+		if when == nil {
+			when = item.Issue.CreatedAt
+		}
+		for _, label := range item.Issue.Labels {
+			u := get(label)
+			if item.State == "closed" {
+				u.Closed++
+			} else {
+				u.Open++
+			}
+			if when != nil && (u.LastUsed == nil || when.After(*u.LastUsed)) {
+				u.LastUsed = when
+			}
+		}
+	}
+	usages := make([]labelUsage, 0, len(byName))
+	for _, u := range byName {
+		usages = append(usages, *u)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Total() != usages[j].Total() {
+			return usages[i].Total() > usages[j].Total()
+		}
+		return strings.ToLower(usages[i].Name) < strings.ToLower(usages[j].Name)
+	})
+	return usages
+}
+
+// labelKey reduces a label to lowercase letters and digits, so that
+// "good first issue" and "Good-First-Issue" compare equal.
+func labelKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// nearDuplicateLabels pairs labels that differ only in case and separators
+// or by a typo. Each pair points from the less used label to the more used
+// one, which is the suggested merge direction.
+func nearDuplicateLabels(usages []labelUsage) []LabelMerge {
+	var pairs []LabelMerge
+	for i := 0; i < len(usages); i++ {
+		for j := i + 1; j < len(usages); j++ {
+			a, b := usages[i], usages[j]
+			ka, kb := labelKey(a.Name), labelKey(b.Name)
+			similar := ka == kb
+			// Short names like p1/p2 are too close to tell typos apart
+			if !similar && len(ka) >= 4 && len(kb) >= 4 {
+				similar = editDistance([]rune(ka), []rune(kb)) <= 1
+			}
+			if !similar {
+				continue
+			}
+			// usages is sorted by use, so b is used at most as much as a
+			pairs = append(pairs, LabelMerge{From: b.Name, To: a.Name})
+		}
+	}
+	return pairs
+}
+
+// LabelAudit prints label usage across the local mirror and lists
+// near-duplicate names. With Merge set, each pair can be merged in turn.
+func (a *App) LabelAudit(ctx context.Context, opts LabelAuditOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	cache, err := loadLabelCache(p)
+	if err != nil {
+		return err
+	}
+	issues, err := loadLocalIssues(p)
+	if err != nil {
+		return err
+	}
+	usages := auditLabels(cache, issues)
+	if len(usages) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No labels"))
+		return nil
+	}
+
+	width := len("LABEL")
+	for _, u := range usages {
+		width = max(width, len(u.Name))
+	}
+	fmt.Fprintf(a.Out, "%s  %6s  %6s  %s\n", t.Bold(padRight("LABEL", width)), "OPEN", "CLOSED", "LAST USED")
+	for _, u := range usages {
+		lastUsed := t.MutedText("never")
+		if u.LastUsed != nil {
+			lastUsed = u.LastUsed.Local().Format("2006-01-02")
+		}
+		name := padRight(u.Name, width)
+		if !u.Known {
+			name = t.WarningText(name)
+		}
+		fmt.Fprintf(a.Out, "%s  %6d  %6d  %s\n", name, u.Open, u.Closed, lastUsed)
+	}
+
+	pairs := nearDuplicateLabels(usages)
+	if len(pairs) == 0 {
+		return nil
+	}
+	fmt.Fprintln(a.Out)
+	fmt.Fprintln(a.Out, t.Bold("Near-duplicates:"))
+	for _, pair := range pairs {
+		fmt.Fprintf(a.Out, "  %q -> %q\n", pair.From, pair.To)
+	}
+	if !opts.Merge {
+		return nil
+	}
+
+	in := a.In
+	if in == nil {
+		in = os.Stdin
+	}
+	reader := bufio.NewReader(in)
+	merged := map[string]struct{}{}
+	for _, pair := range pairs {
+		if _, ok := merged[strings.ToLower(pair.From)]; ok {
+			continue
+		}
+		if _, ok := merged[strings.ToLower(pair.To)]; ok {
+			continue
+		}
+		fmt.Fprintf(a.Out, "%s ", t.AccentText(fmt.Sprintf("Merge %q into %q? [y/N/r(everse)]", pair.From, pair.To)))
+		answer, _ := reader.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+		case "r", "reverse":
+			pair.From, pair.To = pair.To, pair.From
+		default:
+			continue
+		}
+		if err := a.LabelMerge(ctx, pair.From, pair.To); err != nil {
+			return err
+		}
+		merged[strings.ToLower(pair.From)] = struct{}{}
+	}
+	return nil
+}
+
+// LabelMerge replaces from with to on every local issue and queues the
+// change of the remote label for the next push.
+func (a *App) LabelMerge(ctx context.Context, from, to string) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	// A merge that only changes the case is a rename of the remote label
+	if from == "" || to == "" || from == to {
+		return fmt.Errorf("need two different labels to merge")
+	}
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	issues, err := loadLocalIssues(p)
+	if err != nil {
+		return err
+	}
+	issues = append(issues, loadDraftIssues(p).Issues...)
+	relabeled := 0
+	editor := a.localEditor(ctx)
+	for _, item := range issues {
+		labels, changed := replaceLabel(item.Issue.Labels, from, to)
+		if !changed {
+			continue
+		}
+		item.Issue.Labels = labels
+		item.Issue.LastLocalEditBy = editor
+		if err := writeIssue(p, item.Path, item.Issue); err != nil {
+			return err
+		}
+		relabeled++
+	}
+
+	merges, err := loadLabelMerges(p)
+	if err != nil {
+		return err
+	}
+	if err := saveLabelMerges(p, queueLabelMerge(merges, from, to)); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%s %q into %q on %d issue(s) %s\n", t.SuccessText("Merged"), from, to, relabeled, t.MutedText("(run push to sync)"))
+	return nil
+}
+
+// replaceLabel swaps from for to in labels, comparing case-insensitively.
+// It reports whether that changed the labels.
+func replaceLabel(labels []string, from, to string) ([]string, bool) {
+	if !containsFold(labels, from) {
+		return labels, false
+	}
+	result := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if strings.EqualFold(label, from) {
+			continue
+		}
+		result = append(result, label)
+	}
+	if !containsFold(result, to) {
+		result = append(result, to)
+	}
+	return result, !slices.Equal(result, labels)
+}
+
+// applyLabelMerges changes the remote labels for queued merges before any
+// issue is updated. A source label is renamed when the target does not exist
+// yet or only differs in case (keeping it on issues outside the local
+// mirror). Otherwise every remote issue carrying it gets the target first,
+// and the source is only deleted once that worked. The originals are
+// adjusted to match, so the relabeled local issues only push what is left
+// of the change. Merges that fail stay queued.
+func (a *App) applyLabelMerges(ctx context.Context, client ghcli.Provider, p paths.Paths, merges []LabelMerge, cache *LabelCache, labelColors map[string]string, progress *progressReporter) []LabelMerge {
+	t := a.Theme
+	var failed []LabelMerge
+	for _, m := range merges {
+		fromKey, toKey := strings.ToLower(m.From), strings.ToLower(m.To)
+		color, exists := labelColors[fromKey]
+		if !exists {
+			// Never made it to the remote; relabeling the issues is enough
+			progress.Advance()
+			continue
+		}
+		if _, exists := labelColors[toKey]; exists && fromKey != toKey {
+			if err := relabelRemoteIssues(ctx, client, m.From, cachedLabelName(*cache, m.To)); err != nil {
+				progress.Log(fmt.Sprintf("%s moving issues from label %q to %q: %v", t.WarningText("Warning:"), m.From, m.To, err))
+				failed = append(failed, m)
+				progress.Advance()
+				continue
+			}
+			if err := client.DeleteLabel(ctx, m.From); err != nil {
+				progress.Log(fmt.Sprintf("%s deleting label %q: %v", t.WarningText("Warning:"), m.From, err))
+				failed = append(failed, m)
+				progress.Advance()
+				continue
+			}
+			progress.Log(fmt.Sprintf("%s %s %s", t.SuccessText("Deleted label"), m.From, t.MutedText("(merged into "+m.To+")")))
+		} else {
+			if err := client.RenameLabel(ctx, m.From, m.To); err != nil {
+				progress.Log(fmt.Sprintf("%s renaming label %q: %v", t.WarningText("Warning:"), m.From, err))
+				failed = append(failed, m)
+				progress.Advance()
+				continue
+			}
+			progress.Log(fmt.Sprintf("%s %s -> %s", t.SuccessText("Renamed label"), m.From, m.To))
+		}
+		if err := rewriteOriginalLabels(p, m.From, m.To); err != nil {
+			progress.Log(fmt.Sprintf("%s updating originals: %v", t.WarningText("Warning:"), err))
+		}
+		kept := cache.Labels[:0]
+		for _, l := range cache.Labels {
+			if !strings.EqualFold(l.Name, m.From) {
+				kept = append(kept, l)
+			}
+		}
+		cache.Labels = kept
+		delete(labelColors, fromKey)
+		if _, exists := labelColors[toKey]; !exists {
+			labelColors[toKey] = color
+			cache.Labels = append(cache.Labels, LabelEntry{Name: m.To, Color: color})
+		}
+		progress.Advance()
+	}
+	return failed
+}
+
+// relabelRemoteIssues replaces from with to on every remote issue carrying
+// from, open or closed, whether it is in the local mirror or not.
+func relabelRemoteIssues(ctx context.Context, client ghcli.Provider, from, to string) error {
+	result, err := client.ListIssuesWithRelationships(ctx, ghcli.ListIssuesOptions{State: "all", Labels: []string{from}, SkipProjects: true})
+	if err != nil {
+		return err
+	}
+	var updates []ghcli.BatchIssueUpdate
+	for _, iss := range result.Issues {
+		labels, _ := replaceLabel(iss.Labels, from, to)
+		updates = append(updates, ghcli.BatchIssueUpdate{Number: iss.Number.String(), Labels: labels})
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	edited, err := client.BatchEditIssues(ctx, updates)
+	if err != nil {
+		return err
+	}
+	if len(edited.Errors) > 0 {
+		numbers := make([]string, 0, len(edited.Errors))
+		for number := range edited.Errors {
+			numbers = append(numbers, "#"+number)
+		}
+		sort.Strings(numbers)
+		return fmt.Errorf("could not relabel %s", strings.Join(numbers, ", "))
+	}
+	return nil
+}
+
+// cachedLabelName returns name as the remote spells it, according to the
+// label cache.
+func cachedLabelName(cache LabelCache, name string) string {
+	for _, l := range cache.Labels {
+		if strings.EqualFold(l.Name, name) {
+			return l.Name
+		}
+	}
+	return name
+}
+
+// rewriteOriginalLabels replaces from with to in every original, mirroring
+// a change made to the remote label.
+func rewriteOriginalLabels(p paths.Paths, from, to string) error {
+	files, err := listIssueFiles(p, p.OriginalsDir)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if filepath.Ext(path) != ".md" {
+			continue
+		}
+		original, err := readOriginalFile(p, path)
+		if err != nil {
+			continue
+		}
+		labels, changed := replaceLabel(original.Labels, from, to)
+		if !changed {
+			continue
+		}
+		original.Labels = labels
+		if err := writeOriginalIssue(p, original); err != nil {
+			return err
+		}
+	}
+	return nil
+}
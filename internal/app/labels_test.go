@@ -0,0 +1,181 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// labelStubProvider has "enhancment" on #3 and on the closed #9, which is
+// not in the mirror, and records the label changes.
+type labelStubProvider struct {
+	ghcli.Provider
+	calls []string
+}
+
+func (l *labelStubProvider) ListIssuesWithRelationships(ctx context.Context, opts ghcli.ListIssuesOptions) (ghcli.ListIssuesResult, error) {
+	if opts.State != "all" || len(opts.Labels) != 1 || opts.Labels[0] != "enhancment" {
+		return ghcli.ListIssuesResult{}, nil
+	}
+	return ghcli.ListIssuesResult{Issues: []issue.Issue{
+		{Number: "3", State: "open", Labels: []string{"enhancment", "bug-report"}},
+		{Number: "9", State: "closed", Labels: []string{"enhancment"}},
+	}}, nil
+}
+
+func (l *labelStubProvider) BatchEditIssues(ctx context.Context, updates []ghcli.BatchIssueUpdate) (ghcli.BatchUpdateResult, error) {
+	for _, u := range updates {
+		l.calls = append(l.calls, "edit #"+u.Number+" "+strings.Join(u.Labels, ","))
+	}
+	return ghcli.BatchUpdateResult{Errors: map[string]string{}}, nil
+}
+
+func (l *labelStubProvider) DeleteLabel(ctx context.Context, name string) error {
+	l.calls = append(l.calls, "delete "+name)
+	return nil
+}
+
+func (l *labelStubProvider) RenameLabel(ctx context.Context, oldName, newName string) error {
+	l.calls = append(l.calls, "rename "+oldName+" "+newName)
+	return nil
+}
+
+func TestLabelAuditMergesNearDuplicates(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	cache := LabelCache{Labels: []LabelEntry{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "Bug-Report", Color: "ffffff"},
+		{Name: "enhancement", Color: "a2eeef"},
+		{Name: "enhancment", Color: "a2eeef"},
+		{Name: "wontfix", Color: "ffffff"},
+	}}
+	if err := saveLabelCache(p, cache); err != nil {
+		t.Fatalf("label cache: %v", err)
+	}
+	for _, iss := range []issue.Issue{
+		{Number: "1", Title: "A", State: "open", Labels: []string{"bug", "enhancement"}},
+		{Number: "2", Title: "B", State: "open", Labels: []string{"enhancement"}},
+		{Number: "3", Title: "C", State: "open", Labels: []string{"enhancment", "bug-report"}},
+	} {
+		path := issue.PathFor(p.OpenDir, iss.Number, iss.Title)
+		if err := issue.WriteFile(path, iss); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := writeOriginalIssue(p, iss); err != nil {
+			t.Fatalf("original: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.In = strings.NewReader("y\n")
+	if err := application.LabelAudit(context.Background(), LabelAuditOptions{Merge: true}); err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	output := out.String()
+	if !strings.Contains(output, `"enhancment" -> "enhancement"`) {
+		t.Fatalf("expected typo pair:\n%s", output)
+	}
+	if !strings.Contains(output, "never") {
+		t.Fatalf("expected unused wontfix label:\n%s", output)
+	}
+	// "bug" and "Bug-Report" are different labels, not a typo
+	if strings.Contains(output, `"Bug-Report" -> "bug"`) {
+		t.Fatalf("unexpected pair:\n%s", output)
+	}
+
+	file, err := findIssueByNumber(p, "3")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if strings.Join(file.Issue.Labels, ",") != "bug-report,enhancement" {
+		t.Fatalf("unexpected labels after merge: %v", file.Issue.Labels)
+	}
+	merges, err := loadLabelMerges(p)
+	if err != nil || len(merges) != 1 || merges[0].From != "enhancment" {
+		t.Fatalf("expected queued merge, got %v (%v)", merges, err)
+	}
+
+	// Push moves every remote issue to the target label, including those
+	// outside the mirror, before it deletes the source label, which leaves
+	// nothing to push for the issue itself
+	client := &labelStubProvider{}
+	labelColors := map[string]string{}
+	for _, l := range cache.Labels {
+		labelColors[strings.ToLower(l.Name)] = l.Color
+	}
+	progress := newProgressReporter(io.Discard, application.Theme)
+	failed := application.applyLabelMerges(context.Background(), client, p, merges, &cache, labelColors, progress)
+	want := "edit #3 bug-report,enhancement|edit #9 enhancement|delete enhancment"
+	if len(failed) != 0 || strings.Join(client.calls, "|") != want {
+		t.Fatalf("unexpected calls: %v (failed %v)", client.calls, failed)
+	}
+	original, _ := readOriginalIssue(p, "3")
+	change := diffIssue(original, file.Issue)
+	if len(change.AddLabels) != 0 || len(change.RemoveLabels) != 0 {
+		t.Fatalf("unexpected label change: %+v", change)
+	}
+}
+
+func TestLabelMergeCaseOnly(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	cache := LabelCache{Labels: []LabelEntry{{Name: "Bug", Color: "d73a4a"}}}
+	iss := issue.Issue{Number: "1", Title: "A", State: "open", Labels: []string{"Bug"}}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := writeOriginalIssue(p, iss); err != nil {
+		t.Fatalf("original: %v", err)
+	}
+
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	ctx := context.Background()
+	if err := application.LabelMerge(ctx, "Bug", "bug"); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	file, err := findIssueByNumber(p, "1")
+	if err != nil || strings.Join(file.Issue.Labels, ",") != "bug" {
+		t.Fatalf("expected the issue relabeled, got %+v %v", file.Issue.Labels, err)
+	}
+	merges, err := loadLabelMerges(p)
+	if err != nil || len(merges) != 1 || merges[0] != (LabelMerge{From: "Bug", To: "bug"}) {
+		t.Fatalf("expected queued merge, got %v (%v)", merges, err)
+	}
+
+	// The remote label is renamed, never deleted
+	client := &labelStubProvider{}
+	labelColors := map[string]string{"bug": "d73a4a"}
+	progress := newProgressReporter(io.Discard, application.Theme)
+	failed := application.applyLabelMerges(ctx, client, p, merges, &cache, labelColors, progress)
+	if len(failed) != 0 || strings.Join(client.calls, "|") != "rename Bug bug" {
+		t.Fatalf("unexpected calls: %v (failed %v)", client.calls, failed)
+	}
+	if labelColors["bug"] != "d73a4a" || len(cache.Labels) != 1 || cache.Labels[0].Name != "bug" {
+		t.Fatalf("expected the renamed label cached, got %v %+v", labelColors, cache.Labels)
+	}
+	original, _ := readOriginalIssue(p, "1")
+	if change := diffIssue(original, file.Issue); len(change.AddLabels) != 0 || len(change.RemoveLabels) != 0 {
+		t.Fatalf("unexpected label change: %+v", change)
+	}
+}
@@ -0,0 +1,395 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/store"
+)
+
+// linkIndexKinds are the link indexes and their directories. They live
+// in index/, apart from the directories of any layout, so that no layout
+// mistakes their links for issue files.
+var linkIndexKinds = []struct {
+	By  string
+	Dir string
+}{
+	{"label", filepath.Join(paths.IndexDirName, paths.ByLabelDirName)},
+	{"assignee", filepath.Join(paths.IndexDirName, paths.ByAssigneeDirName)},
+	{"milestone", filepath.Join(paths.IndexDirName, paths.ByMilestoneDirName)},
+}
+
+// linkIndexMarker is the .gitignore of every index directory. It keeps the
+// links out of git and tells index directories from anything else.
+const linkIndexMarker = "# Maintained by gh-issue-sync, see indexes in .sync/config.json\n*\n"
+
+// linkIndexValues returns the values of iss an index by by files it under.
+func linkIndexValues(iss issue.Issue, by string) []string {
+	switch by {
+	case "label":
+		return iss.Labels
+	case "assignee":
+		return iss.Assignees
+	case "milestone":
+		if iss.Milestone != "" {
+			return []string{iss.Milestone}
+		}
+	}
+	return nil
+}
+
+// linkIndexDirName turns a label, assignee or milestone into a directory
+// name that is valid on every platform, e.g. area/infra to area-infra.
+func linkIndexDirName(value string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(value))
+	return strings.Trim(name, ". ")
+}
+
+// linkIndexResult is what syncing the link indexes changed.
+type linkIndexResult struct {
+	Issues  int
+	Added   int
+	Removed int
+}
+
+// syncLinkIndexes brings the configured link indexes of p up to date with
+// the issue files, and removes the index directories no longer
+// configured. Only the plain directory store has files to link to.
+func syncLinkIndexes(p paths.Paths, cfg config.Config) (linkIndexResult, error) {
+	var result linkIndexResult
+	s, err := storeFor(p)
+	if err != nil {
+		return result, err
+	}
+	if _, ok := s.(*store.Dir); !ok {
+		if len(cfg.Indexes.By) == 0 {
+			return result, nil
+		}
+		return result, errors.New("indexes need the plain directory storage backend")
+	}
+
+	// Every link wanted, by path, pointing at an issue file
+	want := map[string]string{}
+	var loadErrs []error
+	walkLocalIssues(p, true, func(item IssueFile) {
+		if len(cfg.Indexes.By) == 0 {
+			return
+		}
+		result.Issues++
+		for _, by := range cfg.Indexes.By {
+			dir := filepath.Join(p.IssuesDir, linkIndexDir(by))
+			for _, value := range linkIndexValues(item.Issue, by) {
+				if name := linkIndexDirName(value); name != "" {
+					want[filepath.Join(dir, name, filepath.Base(item.Path))] = item.Path
+				}
+			}
+		}
+	}, func(err ParseError) {
+		loadErrs = append(loadErrs, err)
+	})
+	if len(loadErrs) > 0 {
+		return result, loadErrs[0]
+	}
+
+	if err := dropLegacyLinkIndexes(p); err != nil {
+		return result, err
+	}
+	mode := cfg.Indexes.Links
+	for _, kind := range linkIndexKinds {
+		root := filepath.Join(p.IssuesDir, kind.Dir)
+		configured := slices.Contains(cfg.Indexes.By, kind.By)
+		marker := filepath.Join(root, paths.GitignoreFileName)
+		if data, err := os.ReadFile(marker); err != nil || string(data) != linkIndexMarker {
+			if _, statErr := os.Stat(root); configured && statErr == nil {
+				return result, fmt.Errorf("%s exists and is not an index directory", root)
+			}
+			// Nothing of ours to clean up
+			continue
+		}
+		var stale []string
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || path == marker {
+				return err
+			}
+			if target, ok := want[path]; configured && ok && linkMatches(path, target, mode) {
+				delete(want, path)
+				return nil
+			}
+			stale = append(stale, path)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+		for _, path := range stale {
+			if err := os.Remove(path); err != nil {
+				return result, err
+			}
+			result.Removed++
+		}
+		if !configured {
+			if err := os.RemoveAll(root); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if err := removeEmptyDirs(root); err != nil {
+			return result, err
+		}
+	}
+
+	links := make([]string, 0, len(want))
+	for path := range want {
+		links = append(links, path)
+	}
+	sort.Strings(links)
+	marked := map[string]bool{}
+	for _, path := range links {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return result, err
+		}
+		if root := filepath.Dir(filepath.Dir(path)); !marked[root] {
+			if err := os.WriteFile(filepath.Join(root, paths.GitignoreFileName), []byte(linkIndexMarker), 0o644); err != nil {
+				return result, err
+			}
+			marked[root] = true
+		}
+		if err := makeLink(path, want[path], mode); err != nil {
+			return result, err
+		}
+		result.Added++
+	}
+	return result, nil
+}
+
+// dropLegacyLinkIndexes removes the link indexes that older versions kept
+// right in the issues directory, where by-milestone/ is also the
+// directory of the milestone layout. Only what the index made is removed
+// from there: its marker, its symlinks, and the hard links and copies of
+// issue files found elsewhere.
+func dropLegacyLinkIndexes(p paths.Paths) error {
+	for _, name := range []string{paths.ByLabelDirName, paths.ByAssigneeDirName, paths.ByMilestoneDirName} {
+		root := filepath.Join(p.IssuesDir, name)
+		marker := filepath.Join(root, paths.GitignoreFileName)
+		if data, err := os.ReadFile(marker); err != nil || string(data) != linkIndexMarker {
+			continue
+		}
+		if name != paths.ByMilestoneDirName {
+			if err := os.RemoveAll(root); err != nil {
+				return err
+			}
+			continue
+		}
+		elsewhere := map[string]string{}
+		for _, dir := range p.StateDirs() {
+			if dir.Path == root {
+				continue
+			}
+			files, _ := filepath.Glob(filepath.Join(dir.Path, "*.md"))
+			for _, file := range files {
+				elsewhere[filepath.Base(file)] = file
+			}
+		}
+		if err := os.Remove(marker); err != nil {
+			return err
+		}
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			original, ok := elsewhere[filepath.Base(path)]
+			if entry.Type()&fs.ModeSymlink != 0 || ok && (linkMatches(path, original, config.IndexLinksHardlink) || linkMatches(path, original, config.IndexLinksCopy)) {
+				return os.Remove(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := removeEmptyDirs(root); err != nil {
+			return err
+		}
+		if rest, err := os.ReadDir(root); err == nil && len(rest) == 0 {
+			if err := os.Remove(root); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// inLinkIndex reports whether dir is inside a link index directory, which
+// holds links to issue files rather than issue files.
+func inLinkIndex(p paths.Paths, dir string) bool {
+	for dir != p.IssuesDir && strings.HasPrefix(dir, p.IssuesDir+string(filepath.Separator)) {
+		if data, err := os.ReadFile(filepath.Join(dir, paths.GitignoreFileName)); err == nil && string(data) == linkIndexMarker {
+			return true
+		}
+		dir = filepath.Dir(dir)
+	}
+	return false
+}
+
+func linkIndexDir(by string) string {
+	for _, kind := range linkIndexKinds {
+		if kind.By == by {
+			return kind.Dir
+		}
+	}
+	return ""
+}
+
+// linkMatches reports whether the index entry at path points at target.
+func linkMatches(path, target, mode string) bool {
+	switch mode {
+	case config.IndexLinksHardlink:
+		linkInfo, err := os.Lstat(path)
+		if err != nil {
+			return false
+		}
+		targetInfo, err := os.Stat(target)
+		return err == nil && os.SameFile(linkInfo, targetInfo)
+	case config.IndexLinksCopy:
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			return false
+		}
+		copied, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		original, err := os.ReadFile(target)
+		return err == nil && bytes.Equal(copied, original)
+	}
+	dest, err := os.Readlink(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Dir(path), target)
+	return err == nil && dest == rel
+}
+
+// makeLink creates the index entry at path for target.
+func makeLink(path, target, mode string) error {
+	switch mode {
+	case config.IndexLinksHardlink:
+		return os.Link(target, path)
+	case config.IndexLinksCopy:
+		data, err := os.ReadFile(target)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0o644)
+	}
+	rel, err := filepath.Rel(filepath.Dir(path), target)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(rel, path)
+}
+
+// removeEmptyDirs removes the directories below root left empty.
+func removeEmptyDirs(root string) error {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if rest, err := os.ReadDir(dir); err == nil && len(rest) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// changedIssueDirs records the issues directories whose issue files a
+// command wrote, moved or removed, so Flush updates their link indexes.
+var (
+	changedMu        sync.Mutex
+	changedIssueDirs = map[string]paths.Paths{}
+)
+
+func markIssuesChanged(p paths.Paths) {
+	changedMu.Lock()
+	defer changedMu.Unlock()
+	changedIssueDirs[p.IssuesDir] = p
+}
+
+// takeChangedIssueDirs returns and forgets the issues directories changed
+// since the last call.
+func takeChangedIssueDirs() []paths.Paths {
+	changedMu.Lock()
+	defer changedMu.Unlock()
+	result := make([]paths.Paths, 0, len(changedIssueDirs))
+	for _, p := range changedIssueDirs {
+		result = append(result, p)
+	}
+	changedIssueDirs = map[string]paths.Paths{}
+	return result
+}
+
+// refreshLinkIndexes updates the link indexes of the issues directories a
+// command changed.
+func (a *App) refreshLinkIndexes() {
+	t := a.Theme
+	for _, p := range takeChangedIssueDirs() {
+		cfg, err := config.Load(p.ConfigPath)
+		if err != nil || len(cfg.Indexes.By) == 0 {
+			continue
+		}
+		if _, err := syncLinkIndexes(p, cfg); err != nil {
+			fmt.Fprintf(a.Err, "%s updating the indexes: %v\n", t.WarningText("Warning:"), err)
+		}
+	}
+}
+
+// RebuildIndexes brings the link indexes configured in indexes up to date
+// with the issue files and reports what changed.
+func (a *App) RebuildIndexes(ctx context.Context) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	result, err := syncLinkIndexes(p, cfg)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Indexes.By) == 0 {
+		// Rebuilding still removes the indexes no longer configured
+		fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("No indexes configured"),
+			t.MutedText(fmt.Sprintf("(%d removed); set \"indexes\": {\"by\": [\"label\", \"assignee\", \"milestone\"]} in %s", result.Removed, relPath(a.Root, p.ConfigPath))))
+		return nil
+	}
+	dirs := make([]string, 0, len(cfg.Indexes.By))
+	for _, by := range cfg.Indexes.By {
+		dirs = append(dirs, linkIndexDir(by))
+	}
+	fmt.Fprintf(a.Out, "%s %d issues in %s %s\n", t.SuccessText("Indexed"), result.Issues, strings.Join(dirs, ", "),
+		t.MutedText(fmt.Sprintf("(%d added, %d removed)", result.Added, result.Removed)))
+	return nil
+}
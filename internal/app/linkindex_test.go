@@ -0,0 +1,147 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestLinkIndexes(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Indexes.By = []string{"label", "assignee"}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	crash := issue.Issue{Number: "1", Title: "Crash", State: "open", Labels: []string{"bug", "area/infra"}, Assignees: []string{"alice"}}
+	typo := issue.Issue{Number: "2", Title: "Typo", State: "closed", Labels: []string{"bug"}}
+	crashPath := issue.PathFor(p.OpenDir, crash.Number, crash.Title)
+	typoPath := issue.PathFor(p.ClosedDir, typo.Number, typo.Title)
+	for path, iss := range map[string]issue.Issue{crashPath: crash, typoPath: typo} {
+		if err := issue.WriteFile(path, iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	if err := application.RebuildIndexes(ctx); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if !strings.Contains(out.String(), "Indexed 2 issues in index/by-label, index/by-assignee (4 added, 0 removed)") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+	for link, want := range map[string]string{
+		"index/by-label/bug/1-crash.md":        "../../../open/1-crash.md",
+		"index/by-label/bug/2-typo.md":         "../../../closed/2-typo.md",
+		"index/by-label/area-infra/1-crash.md": "../../../open/1-crash.md",
+		"index/by-assignee/alice/1-crash.md":   "../../../open/1-crash.md",
+	} {
+		if dest, err := os.Readlink(filepath.Join(p.IssuesDir, filepath.FromSlash(link))); err != nil || dest != filepath.FromSlash(want) {
+			t.Errorf("%s: got %q (%v), want %q", link, dest, err, want)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(p.IssuesDir, "index", "by-label", ".gitignore")); err != nil || !strings.Contains(string(data), "*") {
+		t.Fatalf("expected the index to ignore itself in git, got %q (%v)", data, err)
+	}
+
+	// Commands that change issue files update the indexes when they finish
+	typo.Labels = nil
+	if err := writeIssue(p, typoPath, typo); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := application.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(p.IssuesDir, "index", "by-label", "bug", "2-typo.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale link removed, got %v", err)
+	}
+
+	// Indexes no longer configured are removed, other links can be hard
+	cfg.Indexes = config.IndexesConfig{By: []string{"label"}, Links: config.IndexLinksHardlink}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := application.RebuildIndexes(ctx); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(p.IssuesDir, "index", "by-assignee")); !os.IsNotExist(err) {
+		t.Fatalf("expected by-assignee removed, got %v", err)
+	}
+	linked, err := os.Lstat(filepath.Join(p.IssuesDir, "index", "by-label", "bug", "1-crash.md"))
+	if err != nil {
+		t.Fatalf("stat link: %v", err)
+	}
+	if original, err := os.Stat(crashPath); err != nil || !os.SameFile(linked, original) {
+		t.Fatalf("expected a hard link to the issue file (%v)", err)
+	}
+	if items, err := loadLocalIssues(p); err != nil || len(items) != 2 {
+		t.Fatalf("expected the indexes not to be loaded as issues, got %d (%v)", len(items), err)
+	}
+}
+
+func TestLinkIndexesAreNotRouted(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Indexes.By = []string{"milestone"}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	crash := issue.Issue{Number: "1", Title: "Crash", State: "open", Milestone: "v1"}
+	crashPath := issue.PathFor(p.OpenDir, crash.Number, crash.Title)
+	if err := issue.WriteFile(crashPath, crash); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	// An index left in by-milestone/ by an older version
+	legacy := filepath.Join(p.IssuesDir, "by-milestone")
+	if err := os.MkdirAll(filepath.Join(legacy, "v1"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, ".gitignore"), []byte(linkIndexMarker), 0o644); err != nil {
+		t.Fatalf("marker: %v", err)
+	}
+	if err := os.Symlink("../../open/1-crash.md", filepath.Join(legacy, "v1", "1-crash.md")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	if err := application.RebuildIndexes(ctx); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatalf("expected the old index removed, got %v", err)
+	}
+	// Pulls rearrange the issue files every time
+	for range 2 {
+		if moved, err := routeIssues(application.issuePaths()); err != nil || moved != 0 {
+			t.Fatalf("expected nothing moved, got %d %v", moved, err)
+		}
+	}
+	if info, err := os.Lstat(crashPath); err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("expected the issue file kept, got %v %v", info, err)
+	}
+	if dest, err := os.Readlink(filepath.Join(p.IssuesDir, "index", "by-milestone", "v1", "1-crash.md")); err != nil || dest != filepath.FromSlash("../../../open/1-crash.md") {
+		t.Fatalf("expected the index in index/by-milestone, got %q %v", dest, err)
+	}
+}
@@ -0,0 +1,210 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/shlex"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type LintOptions struct {
+	All bool // check every open issue, not only new and changed ones
+	Fix bool // add dependencies named in bodies to blocked_by
+}
+
+// lintIgnorePattern matches <!-- lint-ignore --> and
+// <!-- lint-ignore: vale, markdownlint --> suppression comments.
+var lintIgnorePattern = regexp.MustCompile(`<!--\s*lint-ignore(?::\s*([^>]*?))?\s*-->`)
+
+// Lint runs the configured body checkers and the required-section check on
+// the given issues, or on all new and changed ones, and reports "Depends on
+// #12" phrases missing from blocked_by, which opts.Fix adds. It fails if
+// anything was found, so it can gate CI.
+func (a *App) Lint(ctx context.Context, opts LintOptions, args []string) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	localIssues, err := loadLocalIssues(p)
+	if err != nil {
+		return err
+	}
+	items, err := filterIssuesByArgs(a.Root, withoutDrafts(localIssues), args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		var selected []IssueFile
+		for _, item := range items {
+			if (opts.All && item.State == "open") || (!opts.All && bodyChanged(p, item)) {
+				selected = append(selected, item)
+			}
+		}
+		items = selected
+	}
+
+	findings, err := a.checkBodies(ctx, cfg, items)
+	if err != nil {
+		return err
+	}
+	for i, item := range items {
+		missing := missingDependencies(item.Issue)
+		if len(missing) == 0 {
+			continue
+		}
+		number := item.Issue.Number.String()
+		if !opts.Fix {
+			findings[number] = append(findings[number], fmt.Sprintf("depends on %s, not in blocked_by (lint --fix adds it)", formatRefs(missing)))
+			continue
+		}
+		if err := a.updateLocalIssue(ctx, p, number, func(iss *issue.Issue) {
+			iss.BlockedBy = append(iss.BlockedBy, missing...)
+		}); err != nil {
+			return err
+		}
+		items[i].Issue.BlockedBy = append(items[i].Issue.BlockedBy, missing...)
+		fmt.Fprintf(a.Out, "%s %s %s\n", t.SuccessText("Blocked"), t.FormatIssueHeader(item.State, number, item.Issue.Title),
+			t.MutedText("by "+formatRefs(missing)))
+	}
+	for number, missing := range lintRequiredSections(p, cfg, items) {
+		findings[number] = append(findings[number], "missing required section(s): "+strings.Join(missing, ", "))
+	}
+	if len(findings) == 0 {
+		fmt.Fprintf(a.Out, "%s\n", t.MutedText(fmt.Sprintf("No problems in %d issue(s)", len(items))))
+		return nil
+	}
+	for _, item := range items {
+		lines, ok := findings[item.Issue.Number.String()]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(a.Out, "%s %s\n", t.FormatIssueHeader(item.State, item.Issue.Number.String(), item.Issue.Title),
+			t.MutedText(relPath(a.Root, item.Path)))
+		for _, line := range lines {
+			fmt.Fprintf(a.Out, "  %s\n", line)
+		}
+	}
+	return fmt.Errorf("lint found problems in %d issue(s)", len(findings))
+}
+
+// bodyChanged reports whether item is new or its body changed since the
+// last sync.
+func bodyChanged(p paths.Paths, item IssueFile) bool {
+	if item.Issue.Number.IsLocal() {
+		return true
+	}
+	original, hasOriginal := readOriginalIssue(p, item.Issue.Number.String())
+	return !hasOriginal || issue.PublicBody(original.Body) != issue.PublicBody(item.Issue.Body)
+}
+
+// checkBodies runs the configured checkers on the public body of every
+// item and returns their output by issue number, each line prefixed with
+// the checker's name. A checker that cannot be run is an error.
+func (a *App) checkBodies(ctx context.Context, cfg config.Config, items []IssueFile) (map[string][]string, error) {
+	findings := map[string][]string{}
+	if len(cfg.Checkers) == 0 || len(items) == 0 {
+		return findings, nil
+	}
+	dir, err := os.MkdirTemp("", "gh-issue-sync-lint-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, item := range items {
+		number := item.Issue.Number.String()
+		body := issue.PublicBody(item.Issue.Body)
+		ignored := lintIgnored(body)
+		if ignored["*"] {
+			continue
+		}
+		path := filepath.Join(dir, number+".md")
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			return nil, err
+		}
+		for _, checker := range cfg.Checkers {
+			name := checkerName(checker)
+			if ignored[strings.ToLower(name)] {
+				continue
+			}
+			output, err := runChecker(ctx, checker.Command, path)
+			if err != nil {
+				return nil, fmt.Errorf("checker %s: %w", name, err)
+			}
+			for _, line := range strings.Split(output, "\n") {
+				// Report the issue file rather than the temporary copy
+				line = strings.TrimRight(strings.ReplaceAll(line, path, relPath(a.Root, item.Path)), " \t\r")
+				if strings.TrimSpace(line) != "" {
+					findings[number] = append(findings[number], name+": "+line)
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+// checkerName is the configured name of a checker or its program.
+func checkerName(checker config.Checker) string {
+	if checker.Name != "" {
+		return checker.Name
+	}
+	if parts, err := shlex.Split(checker.Command); err == nil && len(parts) > 0 {
+		return filepath.Base(parts[0])
+	}
+	return "checker"
+}
+
+// lintIgnored returns the lowercased checker names suppressed in body, with
+// "*" for a suppression of all checkers.
+func lintIgnored(body string) map[string]bool {
+	ignored := map[string]bool{}
+	for _, m := range lintIgnorePattern.FindAllStringSubmatch(body, -1) {
+		if strings.TrimSpace(m[1]) == "" {
+			ignored["*"] = true
+			continue
+		}
+		for _, name := range strings.Split(m[1], ",") {
+			ignored[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+	}
+	return ignored
+}
+
+// runChecker runs a checker command on path and returns what it printed
+// if it reported problems by exiting non-zero. A checker that fails without
+// output, or can't be started, is an error.
+var runChecker = func(ctx context.Context, command string, path string) (string, error) {
+	parts, err := shlex.Split(command)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse command %q: %w", command, err)
+	}
+	if len(parts) == 0 {
+		return "", errors.New("empty command")
+	}
+	cmd := exec.CommandContext(ctx, parts[0], append(parts[1:], path)...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && strings.TrimSpace(output.String()) != "" {
+		return output.String(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return "", nil
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestLint(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Checkers = []config.Checker{{Name: "spell", Command: "spellcheck --strict"}, {Command: "/usr/bin/mdlint"}}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	for _, iss := range []issue.Issue{
+		{Number: "T1", Title: "Typo", State: "open", Body: "Fix teh login"},
+		{Number: "T2", Title: "Suppressed", State: "open", Body: "Fix teh login\n<!-- lint-ignore -->"},
+		{Number: "T3", Title: "Only spelling suppressed", State: "open", Body: "Fix teh login\n<!-- lint-ignore: spell -->"},
+		{Number: "T4", Title: "Clean", State: "open", Body: "Fix the login"},
+	} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+	// Unchanged since the last sync, so only checked when asked for
+	synced := issue.Issue{Number: "5", Title: "Synced", State: "open", Body: "Old teh typo"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, synced.Number, synced.Title), synced); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := issue.WriteFile(filepath.Join(p.OriginalsDir, "5.md"), synced); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+
+	// The checkers flag "teh" with the path and line, like vale does
+	previous := runChecker
+	t.Cleanup(func() { runChecker = previous })
+	var runs []string
+	runChecker = func(ctx context.Context, command string, path string) (string, error) {
+		runs = append(runs, command+" "+filepath.Base(path))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(string(data), "teh") {
+			return path + ":1:5 'teh' is misspelled\n", nil
+		}
+		return "", nil
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	if err := application.Lint(ctx, LintOptions{}, nil); err == nil || !strings.Contains(err.Error(), "2 issue(s)") {
+		t.Fatalf("expected problems in 2 issues, got %v", err)
+	}
+	text := out.String()
+	typoPath := relPath(root, issue.PathFor(p.OpenDir, "T1", "Typo"))
+	if !strings.Contains(text, "spell: "+typoPath+":1:5 'teh' is misspelled") ||
+		!strings.Contains(text, "mdlint: "+typoPath+":1:5") {
+		t.Fatalf("expected findings of both checkers for T1, got:\n%s", text)
+	}
+	if strings.Contains(text, "Suppressed") || strings.Contains(text, "spell: "+relPath(root, issue.PathFor(p.OpenDir, "T3", "Only spelling suppressed"))) {
+		t.Fatalf("expected suppressed checkers to be skipped, got:\n%s", text)
+	}
+	if !strings.Contains(text, "Only spelling suppressed") || strings.Contains(text, "Synced") {
+		t.Fatalf("expected T3 with mdlint only and no unchanged issues, got:\n%s", text)
+	}
+	for _, run := range runs {
+		if strings.HasSuffix(run, " 5.md") || strings.HasSuffix(run, " T2.md") {
+			t.Fatalf("expected skipped issues not to be checked, got %v", runs)
+		}
+	}
+
+	out.Reset()
+	if err := application.Lint(ctx, LintOptions{}, []string{"T4"}); err != nil {
+		t.Fatalf("expected a clean issue to pass, got %v", err)
+	}
+	if !strings.Contains(out.String(), "No problems in 1 issue(s)") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	out.Reset()
+	if err := application.Lint(ctx, LintOptions{All: true}, nil); err == nil || !strings.Contains(out.String(), "Synced") {
+		t.Fatalf("expected --all to check unchanged issues, got %v:\n%s", err, out.String())
+	}
+}
+
+func TestLintDependencies(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	deps := issue.Issue{Number: "T1", Title: "Deploy", State: "open", BlockedBy: []issue.IssueRef{"7"},
+		Body: "Depends on #7 and #12.\n\n- [ ] Blocked by: #13, #T1 & #Tabc\n\nSee #20, which is unrelated.\n<!-- local-notes\ndepends on #99\n-->\n"}
+	if got := formatRefs(bodyDependencies(deps)); got != "#7, #12, #13, #Tabc" {
+		t.Fatalf("unexpected dependencies: %s", got)
+	}
+	path := issue.PathFor(p.OpenDir, deps.Number, deps.Title)
+	if err := issue.WriteFile(path, deps); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	if err := application.Lint(ctx, LintOptions{}, nil); err == nil {
+		t.Fatal("expected the missing dependencies reported")
+	}
+	if !strings.Contains(out.String(), "depends on #12, #13, #Tabc, not in blocked_by") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := application.Lint(ctx, LintOptions{Fix: true}, nil); err != nil {
+		t.Fatalf("lint --fix: %v\n%s", err, out.String())
+	}
+	fixed, err := readIssue(p, path)
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	if got := formatRefs(fixed.BlockedBy); got != "#12, #13, #7, #Tabc" {
+		t.Fatalf("expected the dependencies added to blocked_by, got %s", got)
+	}
+	if err := application.Lint(ctx, LintOptions{}, nil); err != nil {
+		t.Fatalf("expected no problems after fixing, got %v", err)
+	}
+}
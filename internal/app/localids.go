@@ -0,0 +1,215 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/localid"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// newLocalNumber returns the number for a new local issue: a random ID, or
+// with local.ids set to sequential the next of T1, T2, ... Sequential IDs
+// continue above every one used by a local issue, a draft or an earlier
+// push, so IDs that came in from another clone through git are not handed
+// out again.
+func (a *App) newLocalNumber(p paths.Paths) (issue.IssueNumber, error) {
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return "", err
+	}
+	if !cfg.Local.Sequential() {
+		id, err := localid.Generate()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate local ID: %w", err)
+		}
+		return issue.IssueNumber("T" + id), nil
+	}
+
+	next := max(cfg.Local.NextLocalID, 1)
+	used, err := usedLocalIDs(p)
+	if err != nil {
+		return "", err
+	}
+	for _, id := range used {
+		if n, ok := sequentialLocalID(id); ok {
+			next = max(next, n+1)
+		}
+	}
+	cfg.Local.NextLocalID = next + 1
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		return "", err
+	}
+	return issue.IssueNumber("T" + strconv.Itoa(next)), nil
+}
+
+// sequentialLocalID returns n for a sequential ID Tn. Random IDs are
+// eight hex digits and can be all decimal, so they never count.
+func sequentialLocalID(id string) (int, bool) {
+	digits, ok := strings.CutPrefix(id, "T")
+	if !ok || digits == "" || len(digits) >= 2*localid.IDLength {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	return n, err == nil && n > 0
+}
+
+// usedLocalIDs lists the local IDs of local issues and drafts and those
+// recorded by earlier pushes.
+func usedLocalIDs(p paths.Paths) ([]string, error) {
+	var used []string
+	result := loadLocalIssuesWithErrors(p)
+	result.Issues = append(result.Issues, loadDraftIssues(p).Issues...)
+	for _, item := range result.Issues {
+		if item.Issue.Number.IsLocal() {
+			used = append(used, item.Issue.Number.String())
+		}
+	}
+	mappings, err := loadLocalIDs(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, mapping := range mappings {
+		used = append(used, mapping.LocalID)
+	}
+	return used, nil
+}
+
+// renumberLocalIDCollisions gives a fresh ID to local issues whose ID is
+// also used by another file. That happens when two clones create
+// sequential IDs and are merged. The first file by path keeps the ID;
+// references to it cannot be told apart and are left alone. It returns
+// whether anything was renamed; a dry run only warns.
+func (a *App) renumberLocalIDCollisions(p paths.Paths, items []IssueFile, dryRun bool) (bool, error) {
+	t := a.Theme
+	groups := map[string][]IssueFile{}
+	for _, item := range items {
+		if item.Issue.Number.IsLocal() {
+			key := item.Issue.Number.String()
+			groups[key] = append(groups[key], item)
+		}
+	}
+	ids := make([]string, 0, len(groups))
+	for id, group := range groups {
+		if len(group) > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	renamed := false
+	for _, id := range ids {
+		group := groups[id]
+		sort.Slice(group, func(i, j int) bool { return group[i].Path < group[j].Path })
+		for _, item := range group[1:] {
+			if dryRun {
+				fmt.Fprintf(a.Err, "%s %s is used by %s and %s; push gives the latter a new ID\n",
+					t.WarningText("Warning:"), id, relPath(a.Root, group[0].Path), relPath(a.Root, item.Path))
+				continue
+			}
+			number, err := a.newLocalNumber(p)
+			if err != nil {
+				return renamed, err
+			}
+			item.Issue.Number = number
+			newPath := issue.PathFor(filepath.Dir(item.Path), number, item.Issue.Title)
+			if err := moveIssue(p, item.Path, newPath); err != nil {
+				return renamed, err
+			}
+			if err := writeIssue(p, newPath, item.Issue); err != nil {
+				return renamed, err
+			}
+			renamed = true
+			fmt.Fprintf(a.Err, "%s %s was also used by %s; renumbered %s to %s (check references to #%s)\n",
+				t.WarningText("Warning:"), id, relPath(a.Root, group[0].Path), relPath(a.Root, newPath), number, id)
+		}
+	}
+	return renamed, nil
+}
+
+// LocalIDMapping records the number a local issue got when push created
+// it. The history is committed with the mirror, so references to T-ids in
+// old commits and notes can still be followed after the issue file was
+// renamed.
+type LocalIDMapping struct {
+	LocalID  string    `json:"local_id"`
+	Number   string    `json:"number"`
+	Title    string    `json:"title"`
+	PushedAt time.Time `json:"pushed_at"`
+}
+
+func loadLocalIDs(p paths.Paths) ([]LocalIDMapping, error) {
+	var mappings []LocalIDMapping
+	data, err := os.ReadFile(p.LocalIDsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", paths.LocalIDsFileName, err)
+	}
+	return mappings, nil
+}
+
+// recordLocalID adds a mapping to the history. It is saved right after
+// each issue is created so an interrupted push keeps what it did.
+func recordLocalID(p paths.Paths, mapping LocalIDMapping) error {
+	mappings, err := loadLocalIDs(p)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(append(mappings, mapping), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(p.LocalIDsPath, data, 0o644)
+}
+
+// ResolveLocalID prints the issue a local ID was pushed as. A local ID
+// that was pushed more than once, e.g. from two clones, lists every
+// issue.
+func (a *App) ResolveLocalID(ctx context.Context, localID string) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	localID = strings.TrimPrefix(localID, "#")
+	mappings, err := loadLocalIDs(p)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, mapping := range mappings {
+		if !strings.EqualFold(mapping.LocalID, localID) {
+			continue
+		}
+		found = true
+		fmt.Fprintf(a.Out, "%s -> %s %s %s\n", mapping.LocalID, t.AccentText("#"+mapping.Number), mapping.Title,
+			t.MutedText("(pushed "+formatRelativeTime(a.Now(), mapping.PushedAt)+")"))
+		if file, err := findIssueByNumber(p, mapping.Number); err == nil {
+			fmt.Fprintf(a.Out, "  %s\n", relPath(a.Root, file.Path))
+		}
+		fmt.Fprintf(a.Out, "  %s\n", t.MutedText(issueURL(cfg, mapping.Number)))
+	}
+	if !found {
+		if _, err := findIssueByNumber(p, localID); err == nil {
+			return fmt.Errorf("%s has not been pushed yet", localID)
+		}
+		return fmt.Errorf("no push recorded for local issue %s", localID)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestResolveLocalID(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	pushed := issue.Issue{Number: "42", Title: "Crash on start", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, pushed.Number, pushed.Title), pushed); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	draft := issue.Issue{Number: "T9f8e7d", Title: "Not pushed", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, draft.Number, draft.Title), draft); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if err := recordLocalID(p, LocalIDMapping{LocalID: "T1a2b3c", Number: "42", Title: "Crash on start", PushedAt: now.Add(-48 * time.Hour)}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := recordLocalID(p, LocalIDMapping{LocalID: "T4d5e6f", Number: "43", Title: "Other", PushedAt: now}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	application.Now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := application.Resolve(ctx, "#T1a2b3c", ResolveOptions{}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	text := out.String()
+	if !strings.Contains(text, "T1a2b3c -> #42 Crash on start (pushed 2 days ago)") {
+		t.Fatalf("expected the mapping, got:\n%s", text)
+	}
+	if !strings.Contains(text, filepath.ToSlash(relPath(root, issue.PathFor(p.OpenDir, pushed.Number, pushed.Title)))) {
+		t.Fatalf("expected the current issue file, got:\n%s", text)
+	}
+	if strings.Contains(text, "#43") {
+		t.Fatalf("expected only the requested local ID, got:\n%s", text)
+	}
+
+	if err := application.Resolve(ctx, "T9f8e7d", ResolveOptions{}); err == nil || !strings.Contains(err.Error(), "not been pushed") {
+		t.Fatalf("expected an unpushed error, got %v", err)
+	}
+	if err := application.Resolve(ctx, "T0000000", ResolveOptions{}); err == nil || !strings.Contains(err.Error(), "no push recorded") {
+		t.Fatalf("expected an unknown ID error, got %v", err)
+	}
+	if err := application.Resolve(ctx, "T1a2b3c", ResolveOptions{Ours: true}); err == nil {
+		t.Fatalf("expected --ours to be refused for a local ID")
+	}
+}
+
+func TestSequentialLocalIDs(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Local.IDs = config.LocalIDsSequential
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+
+	first, err := application.newLocalNumber(p)
+	if err != nil || first != "T1" {
+		t.Fatalf("expected T1, got %q %v", first, err)
+	}
+	// IDs from another clone and earlier pushes are skipped, random ones
+	// that happen to be all digits are not taken for sequential ones
+	for _, iss := range []issue.Issue{
+		{Number: "T3", Title: "From elsewhere", State: "open"},
+		{Number: "T12345678", Title: "Random", State: "open"},
+	} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+	if err := recordLocalID(p, LocalIDMapping{LocalID: "T4", Number: "10", Title: "Pushed"}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	next, err := application.newLocalNumber(p)
+	if err != nil || next != "T5" {
+		t.Fatalf("expected T5, got %q %v", next, err)
+	}
+	loaded, err := config.Load(p.ConfigPath)
+	if err != nil || loaded.Local.NextLocalID != 6 {
+		t.Fatalf("expected the counter at 6 in the state, got %+v %v", loaded.Local, err)
+	}
+
+	// Two clones both created T7
+	a := issue.Issue{Number: "T7", Title: "Alpha", State: "open"}
+	b := issue.Issue{Number: "T7", Title: "Beta", State: "open"}
+	for _, iss := range []issue.Issue{a, b} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+	items, err := loadLocalIssues(p)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	renamed, err := application.renumberLocalIDCollisions(p, items, false)
+	if err != nil || !renamed {
+		t.Fatalf("expected a renumbered issue, got %v %v", renamed, err)
+	}
+	if file, err := findIssueByNumber(p, "T8"); err != nil || file.Issue.Title != "Beta" {
+		t.Fatalf("expected Beta renumbered to T8, got %+v %v", file, err)
+	}
+	if file, err := findIssueByNumber(p, "T7"); err != nil || file.Issue.Title != "Alpha" {
+		t.Fatalf("expected Alpha to keep T7, got %+v %v", file, err)
+	}
+
+	// Random IDs stay the default
+	cfg.Local.IDs = ""
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	random, err := application.newLocalNumber(p)
+	if err != nil || len(random) != 9 {
+		t.Fatalf("expected a random ID, got %q %v", random, err)
+	}
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type LogOptions struct {
+	Refresh bool // Ignore the cached timeline
+}
+
+// TimelineCache stores the fetched activity feed of an issue. It is reused
+// as long as the issue has not been updated since it was fetched.
+type TimelineCache struct {
+	Events         []ghcli.TimelineEvent `json:"events"`
+	IssueUpdatedAt *time.Time            `json:"issue_updated_at,omitempty"`
+	FetchedAt      time.Time             `json:"fetched_at"`
+}
+
+func timelineCachePath(p paths.Paths, number string) string {
+	return filepath.Join(p.TimelineDir, number+".json")
+}
+
+func loadTimelineCache(p paths.Paths, number string) (TimelineCache, bool) {
+	var cache TimelineCache
+	data, err := os.ReadFile(timelineCachePath(p, number))
+	if err != nil {
+		return cache, false
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, false
+	}
+	return cache, true
+}
+
+func saveTimelineCache(p paths.Paths, number string, cache TimelineCache) error {
+	if err := os.MkdirAll(p.TimelineDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(timelineCachePath(p, number), data, 0o644)
+}
+
+// Log shows the activity feed of an issue.
+func (a *App) Log(ctx context.Context, ref string, opts LogOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+
+	file, err := findIssueByRef(a.Root, p, ref)
+	if err != nil {
+		return err
+	}
+	iss := file.Issue
+	if iss.Number.IsLocal() {
+		return fmt.Errorf("issue %s has not been pushed yet and has no history", iss.Number)
+	}
+	number := iss.Number.String()
+
+	cache, ok := loadTimelineCache(p, number)
+	fresh := ok && !opts.Refresh &&
+		(iss.UpdatedAt == nil || (cache.IssueUpdatedAt != nil && !iss.UpdatedAt.After(*cache.IssueUpdatedAt)))
+	if !fresh {
+		client, err := a.newProvider(cfg)
+		if err != nil {
+			return err
+		}
+		events, err := client.GetTimeline(ctx, number)
+		if err != nil {
+			return fmt.Errorf("failed to fetch timeline for #%s: %w", number, err)
+		}
+		cache = TimelineCache{Events: events, IssueUpdatedAt: iss.UpdatedAt, FetchedAt: a.Now().UTC()}
+		if err := saveTimelineCache(p, number, cache); err != nil {
+			fmt.Fprintf(a.Err, "%s saving timeline cache: %v\n", t.WarningText("Warning:"), err)
+		}
+	}
+
+	fmt.Fprintln(a.Out, t.FormatIssueHeader(file.State, number, iss.Title))
+	if iss.CreatedAt != nil {
+		author := iss.Author
+		if author == "" {
+			author = "ghost"
+		}
+		fmt.Fprintf(a.Out, "%s\t%s\t%s\n", t.MutedText(formatRelativeTime(a.Now(), *iss.CreatedAt)), author, "opened the issue")
+	}
+	for _, event := range cache.Events {
+		actor := event.Actor
+		if actor == "" {
+			actor = "ghost"
+		}
+		fmt.Fprintf(a.Out, "%s\t%s\t%s\n", t.MutedText(formatRelativeTime(a.Now(), event.CreatedAt)), actor, a.describeEvent(event))
+	}
+	if len(cache.Events) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No activity"))
+	}
+	return nil
+}
+
+func (a *App) describeEvent(e ghcli.TimelineEvent) string {
+	t := a.Theme
+	switch e.Type {
+	case "labeled":
+		return "added label " + t.AccentText(e.Subject)
+	case "unlabeled":
+		return "removed label " + t.AccentText(e.Subject)
+	case "assigned":
+		return "assigned " + e.Subject
+	case "unassigned":
+		return "unassigned " + e.Subject
+	case "milestoned":
+		return "added to milestone " + t.AccentText(e.Subject)
+	case "demilestoned":
+		return "removed from milestone " + t.AccentText(e.Subject)
+	case "renamed":
+		return fmt.Sprintf("changed the title from %q to %q", e.Previous, e.Subject)
+	case "referenced":
+		return "referenced this in commit " + e.Subject
+	case "cross-referenced":
+		return "mentioned this in " + e.Subject
+	case "closed":
+		if e.Subject != "" {
+			return fmt.Sprintf("closed this as %s", formatStateReason(e.Subject))
+		}
+		return "closed this"
+	case "reopened":
+		return "reopened this"
+	case "commented":
+		return "commented"
+	default:
+		return e.Type
+	}
+}
+
+// formatStateReason turns GraphQL state reasons (NOT_PLANNED) into the form
+// used in issue files (not planned).
+func formatStateReason(reason string) string {
+	return strings.ToLower(strings.ReplaceAll(reason, "_", " "))
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type timelineRunner struct {
+	calls int
+}
+
+func (r *timelineRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	r.calls++
+	return `{"data":{"repository":{"issue":{"timelineItems":{"pageInfo":{"hasNextPage":false,"endCursor":""},"nodes":[
+{"__typename":"LabeledEvent","createdAt":"2026-01-01T10:00:00Z","actor":{"login":"alice"},"label":{"name":"bug"}},
+{"__typename":"CrossReferencedEvent","createdAt":"2026-01-01T11:00:00Z","actor":{"login":"bob"},"source":{"number":9,"repository":{"nameWithOwner":"owner/repo"}}},
+{"__typename":"ClosedEvent","createdAt":"2026-01-01T12:00:00Z","actor":{"login":"alice"},"stateReason":"NOT_PLANNED"}
+]}}}}}`, nil
+}
+
+func TestLogUsesCacheUntilIssueUpdates(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	updated := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	iss := issue.Issue{Number: "3", Title: "Flaky", State: "open", UpdatedAt: &updated}
+	path := issue.PathFor(p.OpenDir, iss.Number, iss.Title)
+	if err := issue.WriteFile(path, iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	runner := &timelineRunner{}
+	var out strings.Builder
+	application := New(root, runner, &out, io.Discard)
+	for i := 0; i < 2; i++ {
+		if err := application.Log(context.Background(), "3", LogOptions{}); err != nil {
+			t.Fatalf("log: %v", err)
+		}
+	}
+	if runner.calls != 1 {
+		t.Fatalf("expected cached timeline on second call, got %d fetches", runner.calls)
+	}
+	for _, want := range []string{"added label", "mentioned this in owner/repo#9", "closed this as not planned"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	later := updated.Add(time.Hour)
+	iss.UpdatedAt = &later
+	if err := issue.WriteFile(path, iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := application.Log(context.Background(), "3", LogOptions{}); err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	if runner.calls != 2 {
+		t.Fatalf("expected refetch after update, got %d fetches", runner.calls)
+	}
+}
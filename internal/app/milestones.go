@@ -0,0 +1,272 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// MilestoneFile is a milestone described in .issues/milestones/<slug>.md.
+type MilestoneFile struct {
+	Milestone issue.Milestone
+	Path      string
+}
+
+// milestonePush is a pending create or update derived from a milestone file.
+// Original is nil when the milestone does not exist on the remote yet.
+type milestonePush struct {
+	File     MilestoneFile
+	Original *MilestoneEntry
+}
+
+func milestoneFromEntry(e MilestoneEntry) issue.Milestone {
+	return issue.NormalizeMilestone(issue.Milestone{
+		Title:       e.Title,
+		State:       e.State,
+		DueOn:       e.DueOn,
+		Description: e.Description,
+	})
+}
+
+func loadMilestoneFiles(p paths.Paths) ([]MilestoneFile, []ParseError) {
+	entries, err := os.ReadDir(p.MilestonesDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []ParseError{{Path: p.MilestonesDir, Err: err}}
+	}
+	var files []MilestoneFile
+	var parseErrors []ParseError
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		path := filepath.Join(p.MilestonesDir, entry.Name())
+		m, err := issue.ParseMilestoneFile(path)
+		if err != nil {
+			parseErrors = append(parseErrors, ParseError{Path: relPath(p.Root, path), Err: err})
+			continue
+		}
+		if m.Title == "" {
+			parseErrors = append(parseErrors, ParseError{Path: relPath(p.Root, path), Err: errors.New("missing title")})
+			continue
+		}
+		files = append(files, MilestoneFile{Milestone: m, Path: path})
+	}
+	return files, parseErrors
+}
+
+// writeMilestoneFiles mirrors pulled milestones into .issues/milestones.
+// previous is the cache from the last sync and serves as the original for
+// detecting local edits, which are kept unless force is set.
+func (a *App) writeMilestoneFiles(p paths.Paths, previous MilestoneCache, remote []MilestoneEntry, force bool) error {
+	t := a.Theme
+	if err := os.MkdirAll(p.MilestonesDir, 0o755); err != nil {
+		return err
+	}
+
+	originals := make(map[string]MilestoneEntry, len(previous.Milestones))
+	for _, e := range previous.Milestones {
+		originals[strings.ToLower(e.Title)] = e
+	}
+
+	seen := make(map[string]struct{}, len(remote))
+	remotePaths := milestonePaths(p.MilestonesDir, remote)
+	for i, entry := range remote {
+		path := remotePaths[i]
+		seen[path] = struct{}{}
+		remoteMilestone := milestoneFromEntry(entry)
+
+		local, err := issue.ParseMilestoneFile(path)
+		if err == nil && !force {
+			if issue.MilestonesEqual(local, remoteMilestone) {
+				continue
+			}
+			original, hasOriginal := originals[strings.ToLower(entry.Title)]
+			if hasOriginal && !issue.MilestonesEqual(local, milestoneFromEntry(original)) {
+				// Local edits pending push
+				if !issue.MilestonesEqual(remoteMilestone, milestoneFromEntry(original)) {
+					fmt.Fprintf(a.Err, "%s milestone %q changed locally and remotely, keeping local file (use --force to overwrite)\n",
+						t.WarningText("Conflict:"), entry.Title)
+				}
+				continue
+			}
+		}
+		if err := issue.WriteMilestoneFile(path, remoteMilestone); err != nil {
+			return err
+		}
+	}
+
+	// Remove files for milestones deleted remotely, unless edited locally
+	files, _ := loadMilestoneFiles(p)
+	for _, file := range files {
+		if _, ok := seen[file.Path]; ok {
+			continue
+		}
+		original, hasOriginal := originals[strings.ToLower(file.Milestone.Title)]
+		if !hasOriginal {
+			// Never synced: a new local milestone waiting for push
+			continue
+		}
+		if !force && !issue.MilestonesEqual(file.Milestone, milestoneFromEntry(original)) {
+			continue
+		}
+		if err := os.Remove(file.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// milestonePaths returns the file of each milestone. Titles with the same
+// slug, like "v1.0" and "v1-0", would share a file, so those get the
+// milestone number appended.
+func milestonePaths(dir string, entries []MilestoneEntry) []string {
+	counts := make(map[string]int, len(entries))
+	for _, e := range entries {
+		counts[issue.MilestonePathFor(dir, e.Title)]++
+	}
+	files := make([]string, len(entries))
+	for i, e := range entries {
+		path := issue.MilestonePathFor(dir, e.Title)
+		if counts[path] > 1 {
+			number := e.Number
+			if number == 0 {
+				number = i + 1
+			}
+			path = strings.TrimSuffix(path, ".md") + "-" + strconv.Itoa(number) + ".md"
+		}
+		files[i] = path
+	}
+	return files
+}
+
+// planMilestonePush compares milestone files against the cache and returns
+// the milestones that need to be created or updated. A file whose title no
+// longer matches a cached milestone but whose filename still does is treated
+// as a rename.
+func planMilestonePush(files []MilestoneFile, cache MilestoneCache) []milestonePush {
+	byTitle := make(map[string]int, len(cache.Milestones))
+	bySlug := make(map[string]int, len(cache.Milestones))
+	for i, e := range cache.Milestones {
+		byTitle[strings.ToLower(e.Title)] = i
+		bySlug[issue.Slugify(e.Title)] = i
+	}
+	// Files of milestones whose slugs collide carry the number
+	for i, e := range cache.Milestones {
+		numbered := issue.Slugify(e.Title) + "-" + strconv.Itoa(e.Number)
+		if _, ok := bySlug[numbered]; !ok && e.Number != 0 {
+			bySlug[numbered] = i
+		}
+	}
+
+	var plan []milestonePush
+	for _, file := range files {
+		idx, ok := byTitle[strings.ToLower(file.Milestone.Title)]
+		if !ok {
+			slug := strings.TrimSuffix(filepath.Base(file.Path), ".md")
+			idx, ok = bySlug[slug]
+		}
+		if !ok {
+			plan = append(plan, milestonePush{File: file})
+			continue
+		}
+		entry := cache.Milestones[idx]
+		if issue.MilestonesEqual(file.Milestone, milestoneFromEntry(entry)) {
+			continue
+		}
+		plan = append(plan, milestonePush{File: file, Original: &entry})
+	}
+	sort.Slice(plan, func(i, j int) bool {
+		return strings.ToLower(plan[i].File.Milestone.Title) < strings.ToLower(plan[j].File.Milestone.Title)
+	})
+	return plan
+}
+
+// pushMilestoneFiles applies a milestone plan and updates the cache so it
+// reflects the new remote state. Milestones changed remotely since the last
+// pull are skipped unless force is set.
+func (a *App) pushMilestoneFiles(ctx context.Context, client ghcli.Provider, plan []milestonePush, cache *MilestoneCache, force bool, progress *progressReporter) {
+	t := a.Theme
+
+	remoteByTitle := make(map[string]ghcli.Milestone)
+	if remote, err := client.ListMilestones(ctx); err == nil {
+		for _, m := range remote {
+			remoteByTitle[strings.ToLower(m.Title)] = m
+		}
+	}
+
+	for _, item := range plan {
+		m := item.File.Milestone
+		payload := ghcli.Milestone{
+			Title:       m.Title,
+			Description: strings.TrimRight(m.Description, "\n"),
+			DueOn:       m.DueOn,
+			State:       m.State,
+		}
+
+		if item.Original == nil {
+			if err := client.CreateMilestone(ctx, payload); err != nil {
+				progress.Log(fmt.Sprintf("%s creating milestone %q: %v", t.WarningText("Warning:"), m.Title, err))
+				progress.Advance()
+				continue
+			}
+			progress.Log(fmt.Sprintf("%s %s", t.SuccessText("Created milestone"), m.Title))
+			cache.Milestones = append(cache.Milestones, MilestoneEntry{
+				Title:       m.Title,
+				Description: m.Description,
+				DueOn:       m.DueOn,
+				State:       m.State,
+			})
+			progress.Advance()
+			continue
+		}
+
+		remote, ok := remoteByTitle[strings.ToLower(item.Original.Title)]
+		if !ok {
+			progress.Log(fmt.Sprintf("%s milestone %q not found on remote", t.WarningText("Warning:"), item.Original.Title))
+			progress.Advance()
+			continue
+		}
+		remoteMilestone := issue.NormalizeMilestone(issue.Milestone{
+			Title:       remote.Title,
+			State:       remote.State,
+			DueOn:       remote.DueOn,
+			Description: remote.Description,
+		})
+		if !force && !issue.MilestonesEqual(remoteMilestone, milestoneFromEntry(*item.Original)) {
+			progress.Log(fmt.Sprintf("%s milestone %q changed remotely, skipping (pull first or use --force)",
+				t.WarningText("Conflict:"), item.Original.Title))
+			progress.Advance()
+			continue
+		}
+		if err := client.UpdateMilestone(ctx, remote.Number, payload); err != nil {
+			progress.Log(fmt.Sprintf("%s updating milestone %q: %v", t.WarningText("Warning:"), m.Title, err))
+			progress.Advance()
+			continue
+		}
+		progress.Log(fmt.Sprintf("%s %s", t.SuccessText("Updated milestone"), m.Title))
+		for i := range cache.Milestones {
+			if strings.EqualFold(cache.Milestones[i].Title, item.Original.Title) {
+				cache.Milestones[i] = MilestoneEntry{
+					Number:      remote.Number,
+					Title:       m.Title,
+					Description: m.Description,
+					DueOn:       m.DueOn,
+					State:       m.State,
+				}
+			}
+		}
+		progress.Advance()
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestWriteMilestoneFilesKeepsLocalEdits(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+
+	previous := MilestoneCache{Milestones: []MilestoneEntry{
+		{Title: "v1.0", State: "open", Description: "Original"},
+		{Title: "Old", State: "open"},
+	}}
+	if err := application.writeMilestoneFiles(p, MilestoneCache{}, previous.Milestones, false); err != nil {
+		t.Fatalf("initial write: %v", err)
+	}
+
+	// Edit v1.0 locally
+	path := issue.MilestonePathFor(p.MilestonesDir, "v1.0")
+	if err := issue.WriteMilestoneFile(path, issue.Milestone{Title: "v1.0", Description: "Edited"}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+
+	// "Old" was deleted remotely, v1.0 is unchanged remotely
+	if err := application.writeMilestoneFiles(p, previous, previous.Milestones[:1], false); err != nil {
+		t.Fatalf("second write: %v", err)
+	}
+	got, err := issue.ParseMilestoneFile(path)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got.Description != "Edited\n" {
+		t.Fatalf("local edit was overwritten: %q", got.Description)
+	}
+	if _, err := os.Stat(filepath.Join(p.MilestonesDir, "old.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected deleted milestone file to be removed, got %v", err)
+	}
+
+	plan := planMilestonePush([]MilestoneFile{{Milestone: got, Path: path}}, previous)
+	if len(plan) != 1 || plan[0].Original == nil || plan[0].Original.Title != "v1.0" {
+		t.Fatalf("expected update plan for v1.0, got %+v", plan)
+	}
+}
+
+func TestPlanMilestonePushDetectsRenameAndCreate(t *testing.T) {
+	cache := MilestoneCache{Milestones: []MilestoneEntry{{Title: "Beta", State: "open"}}}
+	files := []MilestoneFile{
+		{Milestone: issue.Milestone{Title: "Beta 2", State: "open"}, Path: "/x/beta.md"},
+		{Milestone: issue.Milestone{Title: "GA", State: "open"}, Path: "/x/ga.md"},
+	}
+	plan := planMilestonePush(files, cache)
+	if len(plan) != 2 {
+		t.Fatalf("expected 2 planned changes, got %d", len(plan))
+	}
+	if plan[0].File.Milestone.Title != "Beta 2" || plan[0].Original == nil || plan[0].Original.Title != "Beta" {
+		t.Fatalf("expected rename of Beta, got %+v", plan[0])
+	}
+	if plan[1].File.Milestone.Title != "GA" || plan[1].Original != nil {
+		t.Fatalf("expected creation of GA, got %+v", plan[1])
+	}
+}
+
+func TestWriteMilestoneFilesSeparatesCollidingSlugs(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+
+	remote := []MilestoneEntry{
+		{Number: 1, Title: "v1.0", State: "open"},
+		{Number: 2, Title: "v1-0", State: "open"},
+		{Number: 3, Title: "GA", State: "open"},
+	}
+	for range 2 {
+		if err := application.writeMilestoneFiles(p, MilestoneCache{Milestones: remote}, remote, false); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	for name, title := range map[string]string{"v1-0-1.md": "v1.0", "v1-0-2.md": "v1-0", "ga.md": "GA"} {
+		m, err := issue.ParseMilestoneFile(filepath.Join(p.MilestonesDir, name))
+		if err != nil || m.Title != title {
+			t.Fatalf("expected %s to hold %q, got %+v (%v)", name, title, m, err)
+		}
+	}
+
+	// Retitling a numbered file renames that milestone
+	files := []MilestoneFile{{Milestone: issue.Milestone{Title: "v1.0 final", State: "open"}, Path: filepath.Join(p.MilestonesDir, "v1-0-1.md")}}
+	plan := planMilestonePush(files, MilestoneCache{Milestones: remote})
+	if len(plan) != 1 || plan[0].Original == nil || plan[0].Original.Number != 1 {
+		t.Fatalf("expected a rename of #1, got %+v", plan)
+	}
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// notesCipher encrypts local-notes blocks with the age or gpg CLI.
+type notesCipher struct {
+	tool       string
+	recipients []string
+	identity   string
+}
+
+func newNotesCipher(cfg config.Encryption) (notesCipher, error) {
+	c := notesCipher{
+		tool:       cfg.EffectiveTool(),
+		recipients: cfg.Recipients,
+		identity:   expandHome(cfg.Identity),
+	}
+	if c.tool != config.EncryptionAge && c.tool != config.EncryptionGPG {
+		return c, fmt.Errorf("unsupported encryption tool %q (use age or gpg)", c.tool)
+	}
+	return c, nil
+}
+
+func (c notesCipher) encrypt(ctx context.Context, plaintext string) (string, error) {
+	if len(c.recipients) == 0 {
+		return "", errors.New("no encryption recipients configured (set encryption.recipients in .issues/.sync/config.json)")
+	}
+	var args []string
+	switch c.tool {
+	case config.EncryptionGPG:
+		args = []string{"--batch", "--yes", "--armor", "--encrypt"}
+		for _, r := range c.recipients {
+			args = append(args, "--recipient", r)
+		}
+	default:
+		args = []string{"--encrypt", "--armor"}
+		for _, r := range c.recipients {
+			args = append(args, "--recipient", r)
+		}
+	}
+	return pipeCommand(ctx, plaintext, c.tool, args...)
+}
+
+func (c notesCipher) decrypt(ctx context.Context, ciphertext string) (string, error) {
+	switch c.tool {
+	case config.EncryptionGPG:
+		return pipeCommand(ctx, ciphertext, "gpg", "--batch", "--quiet", "--decrypt")
+	default:
+		if c.identity == "" {
+			return "", errors.New("no age identity configured (set encryption.identity in .issues/.sync/config.json)")
+		}
+		return pipeCommand(ctx, ciphertext, "age", "--decrypt", "--identity", c.identity)
+	}
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// pipeCommand runs a command with input on stdin and returns its stdout.
+var pipeCommand = func(ctx context.Context, input string, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s failed: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// EncryptNotes encrypts the plaintext local-notes blocks of the given issues
+// (all issues if none are given) in place.
+func (a *App) EncryptNotes(ctx context.Context, args []string) error {
+	return a.transformNotes(ctx, args, true)
+}
+
+// DecryptNotes decrypts encrypted local-notes blocks in place.
+func (a *App) DecryptNotes(ctx context.Context, args []string) error {
+	return a.transformNotes(ctx, args, false)
+}
+
+func (a *App) transformNotes(ctx context.Context, args []string, encrypt bool) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	cipher, err := newNotesCipher(cfg.Encryption)
+	if err != nil {
+		return err
+	}
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	localIssues, err := loadLocalIssues(p)
+	if err != nil {
+		return err
+	}
+	localIssues = append(localIssues, loadDraftIssues(p).Issues...)
+	items, err := filterIssuesByArgs(a.Root, localIssues, args)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, item := range items {
+		changed := false
+		body, err := issue.MapLocalNotes(item.Issue.Body, func(block issue.NotesBlock) (issue.NotesBlock, error) {
+			if block.Encrypted == encrypt {
+				return block, nil
+			}
+			changed = true
+			if encrypt {
+				out, err := cipher.encrypt(ctx, block.Content+"\n")
+				return issue.NotesBlock{Encrypted: true, Content: out}, err
+			}
+			out, err := cipher.decrypt(ctx, block.Content+"\n")
+			return issue.NotesBlock{Content: out}, err
+		})
+		if err != nil {
+			return fmt.Errorf("issue %s: %w", item.Issue.Number, err)
+		}
+		if !changed {
+			continue
+		}
+		// Re-read the file so fields set by the loader (e.g. draft) are not written back
+		updated, err := readIssue(p, item.Path)
+		if err != nil {
+			return err
+		}
+		updated.Body = body
+		if err := writeIssue(p, item.Path, updated); err != nil {
+			return err
+		}
+		count++
+	}
+
+	verb := "Decrypted"
+	if encrypt {
+		verb = "Encrypted"
+	}
+	if count == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No local notes to change"))
+		return nil
+	}
+	fmt.Fprintf(a.Out, "%s local notes in %d issue(s)\n", t.SuccessText(verb), count)
+	return nil
+}
+
+// ShowNotes prints the local notes of an issue, decrypting them if needed.
+// The file is left untouched.
+func (a *App) ShowNotes(ctx context.Context, number string) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	cipher, err := newNotesCipher(cfg.Encryption)
+	if err != nil {
+		return err
+	}
+	file, err := findIssueByRef(a.Root, p, number)
+	if err != nil {
+		return err
+	}
+
+	var blocks []string
+	if _, err := issue.MapLocalNotes(file.Issue.Body, func(block issue.NotesBlock) (issue.NotesBlock, error) {
+		content := block.Content
+		if block.Encrypted {
+			plain, err := cipher.decrypt(ctx, content+"\n")
+			if err != nil {
+				return block, err
+			}
+			content = plain
+		}
+		blocks = append(blocks, strings.TrimRight(content, "\n"))
+		return block, nil
+	}); err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		fmt.Fprintln(a.Out, a.Theme.MutedText("No local notes"))
+		return nil
+	}
+	fmt.Fprintln(a.Out, strings.Join(blocks, "\n\n"))
+	return nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestEncryptDecryptNotes(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Encryption = config.Encryption{Recipients: []string{"age1example"}, Identity: "/keys.txt"}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "5", Title: "Customer bug", State: "open",
+		Body: "Public.\n\n<!-- local-notes\nReported by ACME Corp\n-->\n"}
+	path := issue.PathFor(p.OpenDir, iss.Number, iss.Title)
+	if err := issue.WriteFile(path, iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	// Fake cipher: "encrypt" by reversing the input
+	prev := pipeCommand
+	pipeCommand = func(ctx context.Context, input string, name string, args ...string) (string, error) {
+		if name != "age" {
+			t.Fatalf("unexpected tool %q", name)
+		}
+		runes := []rune(strings.TrimRight(input, "\n"))
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes) + "\n", nil
+	}
+	t.Cleanup(func() { pipeCommand = prev })
+
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+	if err := application.EncryptNotes(context.Background(), nil); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	encrypted, err := issue.ParseFile(path)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if strings.Contains(encrypted.Body, "ACME") || !strings.Contains(encrypted.Body, "<!-- local-notes:encrypted") {
+		t.Fatalf("notes not encrypted: %q", encrypted.Body)
+	}
+	if issue.StripLocalNotes(encrypted.Body) != "Public.\n" {
+		t.Fatalf("encrypted notes should still be stripped: %q", encrypted.Body)
+	}
+
+	var out strings.Builder
+	application.Out = &out
+	if err := application.ShowNotes(context.Background(), "5"); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "Reported by ACME Corp" {
+		t.Fatalf("unexpected notes: %q", out.String())
+	}
+
+	if err := application.DecryptNotes(context.Background(), []string{"5"}); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	decrypted, err := issue.ParseFile(path)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if decrypted.Body != iss.Body {
+		t.Fatalf("round trip failed: %q", decrypted.Body)
+	}
+}
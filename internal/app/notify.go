@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"runtime"
+	"strings"
+)
+
+// sendNotification shows a desktop notification using the tools that ship
+// with the OS: osascript on macOS, PowerShell on Windows, and notify-send
+// (libnotify) elsewhere.
+func sendNotification(ctx context.Context, title, message string) error {
+	name, args, env := notificationCommand(runtime.GOOS, title, message)
+	_, err := execCommandEnv(ctx, env, name, args...)
+	return err
+}
+
+// notificationCommand returns the command showing a notification and the
+// environment variables it needs. PowerShell reads the title and message
+// from the environment: they come from remote issues, and its quoting has
+// too many cases (such as typographic quotes) to splice them into a script.
+func notificationCommand(goos, title, message string) (string, []string, []string) {
+	switch goos {
+	case "darwin":
+		script := "display notification " + appleScriptString(message) + " with title " + appleScriptString(title)
+		return "osascript", []string{"-e", script}, nil
+	case "windows":
+		script := `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:GH_ISSUE_SYNC_NOTIFY_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:GH_ISSUE_SYNC_NOTIFY_MESSAGE)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('gh-issue-sync').Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+		env := []string{"GH_ISSUE_SYNC_NOTIFY_TITLE=" + title, "GH_ISSUE_SYNC_NOTIFY_MESSAGE=" + message}
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}, env
+	}
+	return "notify-send", []string{"--app-name=gh-issue-sync", title, message}, nil
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// orgPullConcurrency limits how many repositories are pulled at once.
+const orgPullConcurrency = 4
+
+// orgConfig returns the org-mode configuration when the app works on an
+// org-wide .issues directory.
+func (a *App) orgConfig() (config.Org, bool) {
+	if a.IssuesDir != "" {
+		return config.Org{}, false
+	}
+	org, err := config.LoadOrg(paths.OrgConfigPath(a.Root))
+	if err != nil {
+		return config.Org{}, false
+	}
+	return org, true
+}
+
+// repoApp returns an app working on the issues directory of one repository
+// in org mode, writing to out and errOut.
+func (a *App) repoApp(repo string, out, errOut io.Writer) *App {
+	sub := *a
+	sub.IssuesDir = filepath.Join(a.Root, paths.IssuesDirName, repo)
+	sub.Out = out
+	sub.Err = errOut
+	return &sub
+}
+
+// initOrg sets up org mode for opts.Org in the current directory.
+func (a *App) initOrg(ctx context.Context, opts InitOptions) error {
+	t := a.Theme
+	if opts.Provider != "" && opts.Provider != config.ProviderGitHub {
+		return fmt.Errorf("org mode is only supported for GitHub")
+	}
+	root := a.Root
+	if gitRoot := paths.FindGitRoot(root); gitRoot != "" {
+		root = gitRoot
+	}
+	orgPath := paths.OrgConfigPath(root)
+	if _, err := os.Stat(orgPath); err == nil {
+		return fmt.Errorf("org config already exists at %s", orgPath)
+	}
+	if _, err := os.Stat(paths.New(root).ConfigPath); err == nil {
+		return fmt.Errorf("%s already mirrors a single repository", paths.New(root).IssuesDir)
+	}
+
+	org := config.Org{Org: opts.Org, Include: opts.Include, Exclude: opts.Exclude}
+	if err := os.MkdirAll(filepath.Dir(orgPath), 0o755); err != nil {
+		return err
+	}
+	a.Root = root
+	added, err := a.refreshOrgRepos(ctx, &org)
+	if err != nil {
+		return err
+	}
+	if err := config.SaveOrg(orgPath, org); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%s %s %s %s\n", t.SuccessText("Initialized"), t.AccentText(opts.Org),
+		t.MutedText(fmt.Sprintf("with %d repositories in", len(added))), filepath.Dir(orgPath))
+	return nil
+}
+
+// refreshOrgRepos lists the organization's repositories and creates the
+// issues directory for every matching repository that has none yet. It
+// returns the new repositories. Directories of repositories that no longer
+// match are kept, but they are no longer pulled.
+func (a *App) refreshOrgRepos(ctx context.Context, org *config.Org) ([]string, error) {
+	names, err := ghcli.ListOrgRepositories(ctx, a.Runner, org.Org)
+	if err != nil {
+		return nil, fmt.Errorf("listing repositories of %s: %w", org.Org, err)
+	}
+	known := make(map[string]struct{}, len(org.Repos))
+	for _, repo := range org.Repos {
+		known[repo] = struct{}{}
+	}
+	var repos, added []string
+	for _, name := range names {
+		if !org.Matches(name) {
+			continue
+		}
+		repos = append(repos, name)
+		if _, ok := known[name]; ok {
+			continue
+		}
+		p := a.repoApp(name, nil, nil).issuePaths()
+		if err := p.EnsureLayout(); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(p.ConfigPath); errors.Is(err, os.ErrNotExist) {
+			if err := config.Save(p.ConfigPath, config.Default(org.Org, name)); err != nil {
+				return nil, err
+			}
+		}
+		added = append(added, name)
+	}
+	org.Repos = repos
+	return added, nil
+}
+
+// pullOrg pulls every repository of the organization, several at a time.
+// Output is buffered per repository and printed in order once all pulls
+// are done, so it doesn't interleave.
+func (a *App) pullOrg(ctx context.Context, org config.Org, opts PullOptions) error {
+	t := a.Theme
+	if added, err := a.refreshOrgRepos(ctx, &org); err != nil {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), err)
+	} else {
+		for _, name := range added {
+			fmt.Fprintf(a.Out, "%s %s\n", t.SuccessText("Added repository"), name)
+		}
+		if err := config.SaveOrg(paths.OrgConfigPath(a.Root), org); err != nil {
+			return err
+		}
+	}
+
+	type result struct {
+		out bytes.Buffer
+		err error
+	}
+	results := make([]result, len(org.Repos))
+	sem := make(chan struct{}, orgPullConcurrency)
+	var wg sync.WaitGroup
+	for i, repo := range org.Repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			r := &results[i]
+			r.err = a.repoApp(repo, &r.out, &r.out).Pull(ctx, opts, nil)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for i, repo := range org.Repos {
+		fmt.Fprintln(a.Out, t.Bold(org.Org+"/"+repo))
+		a.Out.Write(results[i].out.Bytes())
+		if err := results[i].err; err != nil {
+			fmt.Fprintf(a.Err, "%s %s: %v\n", t.WarningText("Error:"), repo, err)
+			errs = append(errs, fmt.Errorf("%s: %w", repo, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("pull failed for %d of %d repositories", len(errs), len(org.Repos))
+	}
+	return nil
+}
+
+// listOrg lists the matching issues of every repository, grouped by
+// repository. Repositories without matches are left out.
+func (a *App) listOrg(ctx context.Context, org config.Org, opts ListOptions) error {
+	t := a.Theme
+	total := 0
+	for _, repo := range org.Repos {
+		var out bytes.Buffer
+		count, err := a.repoApp(repo, &out, a.Err).listIssues(ctx, opts)
+		if err != nil {
+			fmt.Fprintf(a.Err, "%s %s: %v\n", t.WarningText("Warning:"), repo, err)
+			continue
+		}
+		if count == 0 {
+			continue
+		}
+		if total > 0 {
+			fmt.Fprintln(a.Out)
+		}
+		fmt.Fprintln(a.Out, t.Bold(org.Org+"/"+repo))
+		a.Out.Write(out.Bytes())
+		total += count
+	}
+	if total == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No issues found"))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// orgRunner answers the repository listing of an organization.
+type orgRunner struct {
+	repos []string
+}
+
+func (r *orgRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	if len(args) >= 2 && args[0] == "repo" && args[1] == "list" {
+		return strings.Join(r.repos, "\n") + "\n", nil
+	}
+	return "", errors.New("offline")
+}
+
+func TestInitOrgAndList(t *testing.T) {
+	root := t.TempDir()
+	runner := &orgRunner{repos: []string{"api", "web", "web-legacy", "docs"}}
+	var out bytes.Buffer
+	application := New(root, runner, &out, &out)
+
+	err := application.Init(context.Background(), InitOptions{
+		Org:     "acme",
+		Include: []string{"api", "web*"},
+		Exclude: []string{"*-legacy"},
+	})
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	org, err := config.LoadOrg(paths.OrgConfigPath(root))
+	if err != nil {
+		t.Fatalf("load org: %v", err)
+	}
+	if got := strings.Join(org.Repos, ","); got != "api,web" {
+		t.Fatalf("unexpected repos: %s", got)
+	}
+	for _, repo := range []string{"api", "web"} {
+		p := paths.NewAt(root, filepath.Join(root, paths.IssuesDirName, repo))
+		cfg, err := config.Load(p.ConfigPath)
+		if err != nil {
+			t.Fatalf("config for %s: %v", repo, err)
+		}
+		if cfg.Repository.Owner != "acme" || cfg.Repository.Repo != repo {
+			t.Fatalf("unexpected repository for %s: %+v", repo, cfg.Repository)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, paths.IssuesDirName, "web-legacy")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("excluded repository was set up: %v", err)
+	}
+
+	web := paths.NewAt(root, filepath.Join(root, paths.IssuesDirName, "web"))
+	iss := issue.Issue{Number: issue.IssueNumber("7"), Title: "Broken login", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(web.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	out.Reset()
+	if err := application.List(context.Background(), ListOptions{}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	listing := out.String()
+	if !strings.Contains(listing, "acme/web") || !strings.Contains(listing, "Broken login") {
+		t.Fatalf("expected web issue in listing, got:\n%s", listing)
+	}
+	if strings.Contains(listing, "acme/api") {
+		t.Fatalf("repository without issues should be left out, got:\n%s", listing)
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// Plan is what a push or pull dry run would do, printed with --json so CI
+// bots can post it for review.
+type Plan struct {
+	Command    string       `json:"command"`
+	Repository string       `json:"repository"`
+	Actions    []PlanAction `json:"actions"`
+}
+
+// PlanAction is a single change on the remote (push) or in the local files
+// (pull).
+type PlanAction struct {
+	// Kind is "issue", "label", "milestone", or "comment".
+	Kind string `json:"kind"`
+	// Action is "create", "update", "close", "reopen", "rename", "delete",
+	// or "conflict" for issues pull would skip.
+	Action string `json:"action"`
+	Number string `json:"number,omitempty"`
+	// Title is the issue title, or the label or milestone name.
+	Title   string        `json:"title,omitempty"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is the old and new value of a changed field. Old is null
+// for issues that don't exist yet.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+func newPlan(command string, cfg config.Config) *Plan {
+	return &Plan{Command: command, Repository: repoSlug(cfg), Actions: []PlanAction{}}
+}
+
+func (plan *Plan) add(action PlanAction) {
+	plan.Actions = append(plan.Actions, action)
+}
+
+// addIssue records the changes from old to new. A nil old means the issue
+// is created.
+func (plan *Plan) addIssue(old *issue.Issue, new issue.Issue) {
+	action := PlanAction{Kind: "issue", Number: new.Number.String(), Title: new.Title}
+	if old == nil {
+		action.Action = "create"
+		for _, field := range issue.ComputeChanges(issue.Issue{}, new).Fields() {
+			action.Changes = append(action.Changes, FieldChange{Field: field, New: planFieldValue(new, field)})
+		}
+		plan.add(action)
+		return
+	}
+	action.Action = issueAction(*old, new)
+	action.Changes = fieldChanges(*old, new, issue.ComputeChanges(*old, new).Fields())
+	plan.add(action)
+}
+
+// issueAction names a change by its state transition, if any.
+func issueAction(old, new issue.Issue) string {
+	oldState := issue.Normalize(old).State
+	newState := issue.Normalize(new).State
+	switch {
+	case oldState != "closed" && newState == "closed":
+		return "close"
+	case oldState == "closed" && newState != "closed":
+		return "reopen"
+	}
+	return "update"
+}
+
+func fieldChanges(old, new issue.Issue, fields []string) []FieldChange {
+	changes := make([]FieldChange, 0, len(fields))
+	for _, field := range fields {
+		changes = append(changes, FieldChange{
+			Field: field,
+			Old:   planFieldValue(old, field),
+			New:   planFieldValue(new, field),
+		})
+	}
+	return changes
+}
+
+// planFieldValue returns a field as named by issue.FieldSet.Fields in a
+// JSON-friendly form. Unlike issueFieldValue, the body is included in full
+// (without local notes) and missing values are null.
+func planFieldValue(iss issue.Issue, field string) any {
+	iss = issue.Normalize(iss)
+	optional := func(s string) any {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+	switch field {
+	case "title":
+		return iss.Title
+	case "labels":
+		return nonNilStrings(iss.Labels)
+	case "assignees":
+		return nonNilStrings(iss.Assignees)
+	case "milestone":
+		return optional(iss.Milestone)
+	case "issue_type":
+		return optional(iss.IssueType)
+	case "projects":
+		return nonNilStrings(iss.Projects)
+	case "state":
+		if iss.StateReason != nil && *iss.StateReason != "" {
+			return fmt.Sprintf("%s (%s)", iss.State, *iss.StateReason)
+		}
+		return iss.State
+	case "parent":
+		if iss.Parent == nil {
+			return nil
+		}
+		return "#" + iss.Parent.String()
+	case "blocked_by":
+		return refStrings(iss.BlockedBy)
+	case "blocks":
+		return refStrings(iss.Blocks)
+	case "body":
+		return issue.PublicBody(iss.Body)
+	}
+	return nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// printPlan writes the plan as indented JSON.
+func (a *App) printPlan(plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(a.Out, "%s\n", data)
+	return err
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestPushDryRunJSON(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := saveLabelCache(p, LabelCache{Labels: []LabelEntry{{Name: "bug", Color: "ff0000"}}}); err != nil {
+		t.Fatalf("label cache: %v", err)
+	}
+
+	original := issue.Issue{Number: "1", Title: "Crash on start", State: "open", Body: "Old body\n"}
+	if err := issue.WriteFile(filepath.Join(p.OriginalsDir, "1.md"), original); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+	local := original
+	local.State = "closed"
+	local.Labels = []string{"bug"}
+	if err := issue.WriteFile(issue.PathFor(p.ClosedDir, local.Number, local.Title), local); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	created := issue.Issue{Number: "T1", Title: "New issue", State: "open", Body: "Hello\n"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, created.Number, created.Title), created); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	var out bytes.Buffer
+	runner := &offlineRunner{}
+	application := New(root, runner, &out, io.Discard)
+	if err := application.Push(context.Background(), PushOptions{DryRun: true, JSON: true}, nil); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(out.Bytes(), &plan); err != nil {
+		t.Fatalf("expected JSON plan, got %q: %v", out.String(), err)
+	}
+	if plan.Command != "push" || plan.Repository != "owner/repo" || len(plan.Actions) != 2 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	create := plan.Actions[0]
+	if create.Kind != "issue" || create.Action != "create" || create.Number != "T1" || create.Changes[0].Old != nil {
+		t.Fatalf("unexpected create action: %+v", create)
+	}
+	update := plan.Actions[1]
+	if update.Action != "close" || update.Number != "1" || len(update.Changes) != 2 {
+		t.Fatalf("unexpected update action: %+v", update)
+	}
+	if update.Changes[0].Field != "labels" || update.Changes[1].Field != "state" || update.Changes[1].New != "closed" {
+		t.Fatalf("unexpected changes: %+v", update.Changes)
+	}
+
+	for _, call := range runner.calls {
+		if strings.Contains(call, "mutation") {
+			t.Fatalf("unexpected write to remote: %s", call)
+		}
+	}
+	if err := application.Push(context.Background(), PushOptions{JSON: true}, nil); err == nil {
+		t.Fatalf("expected --json without --dry-run to fail")
+	}
+}
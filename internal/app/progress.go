@@ -4,11 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/signal"
 	"regexp"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 	"unicode/utf8"
 
@@ -131,6 +129,7 @@ func (p *progressReporter) SetPhase(phase string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.phase = phase
+	setPhase(phase)
 	if p.isTTY && p.started {
 		p.renderLocked()
 	}
@@ -538,27 +537,10 @@ func padLeftVisible(s string, width int) string {
 	return strings.Repeat(" ", width-visible) + s
 }
 
-var cursorRestoreOnce sync.Once
 var cursorRestoreMu sync.Mutex
 var cursorRestorers []func()
 
 func registerCursorRestore(fn func()) func() {
-	cursorRestoreOnce.Do(func() {
-		sigs := make(chan os.Signal, 1)
-		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
-		go func() {
-			<-sigs
-			cursorRestoreMu.Lock()
-			restorers := append([]func(){}, cursorRestorers...)
-			cursorRestoreMu.Unlock()
-			for _, r := range restorers {
-				if r != nil {
-					r()
-				}
-			}
-			os.Exit(130)
-		}()
-	})
 	cursorRestoreMu.Lock()
 	cursorRestorers = append(cursorRestorers, fn)
 	idx := len(cursorRestorers) - 1
@@ -572,6 +554,20 @@ func registerCursorRestore(fn func()) func() {
 	}
 }
 
+// RestoreCursor shows the cursor again that running progress bars hid.
+// It is meant for signal handlers, which must not leave the terminal
+// without a cursor when the command stops before the bars are done.
+func RestoreCursor() {
+	cursorRestoreMu.Lock()
+	restorers := append([]func(){}, cursorRestorers...)
+	cursorRestoreMu.Unlock()
+	for _, r := range restorers {
+		if r != nil {
+			r()
+		}
+	}
+}
+
 func truncateVisible(s string, max int, reset string) string {
 	if max <= 0 {
 		return ""
@@ -1,11 +1,14 @@
 package app
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -13,26 +16,66 @@ import (
 	"github.com/mitsuhiko/gh-issue-sync/internal/config"
 	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
-	"github.com/mitsuhiko/gh-issue-sync/internal/lock"
 	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
 )
 
 func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
-	p := paths.New(a.Root)
+	if opts.JSON && !opts.DryRun {
+		return errors.New("--json requires --dry-run")
+	}
+	if opts.Review && opts.DryRun {
+		return errors.New("--review and --dry-run are mutually exclusive")
+	}
+	scoped := len(opts.Label) > 0 || opts.Milestone != "" || opts.Assignee != ""
+	if opts.FromExport != "" && (len(args) > 0 || scoped) {
+		return errors.New("--from-export imports the whole export and cannot be combined with issues, --label, --milestone or --assignee")
+	}
+	if org, ok := a.orgConfig(); ok {
+		if len(args) > 0 {
+			return fmt.Errorf("pulling single issues is not supported in org mode; set %s to the repository's directory", paths.EnvIssuesDir)
+		}
+		if opts.JSON {
+			return fmt.Errorf("--json is not supported in org mode; set %s to the repository's directory", paths.EnvIssuesDir)
+		}
+		if opts.Review {
+			return fmt.Errorf("--review is not supported in org mode; set %s to the repository's directory", paths.EnvIssuesDir)
+		}
+		if opts.FromExport != "" {
+			return fmt.Errorf("--from-export is not supported in org mode; set %s to the repository's directory", paths.EnvIssuesDir)
+		}
+		return a.pullOrg(ctx, org, opts)
+	}
+	p := a.issuePaths()
 	cfg, err := loadConfig(p.ConfigPath)
 	if err != nil {
 		return err
 	}
+	if opts.FromExport != "" && cfg.Repository.Provider == config.ProviderGitLab {
+		return errors.New("--from-export reads GitHub exports only")
+	}
 
 	// Acquire lock
-	lck, err := lock.Acquire(p.SyncDir, lock.DefaultTimeout)
+	lck, err := a.lockMirror(p)
 	if err != nil {
 		return err
 	}
 	defer lck.Release()
+	setPhase("Preparing")
 
-	client := ghcli.NewClient(a.Runner, repoSlug(cfg))
+	client, err := a.newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	if err := a.preflight(ctx, client); err != nil {
+		return err
+	}
+	caps := a.capabilities(ctx, p, &cfg, client, false)
 	t := a.Theme
+	firstPull := cfg.Sync.LastFullPull == nil
+	boardKept := false
+	if !opts.DryRun {
+		boardKept = a.pullBoardEdits(ctx, p, cfg)
+	}
 
 	localIssues, err := loadLocalIssues(p)
 	if err != nil {
@@ -41,6 +84,7 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 
 	var remoteIssues []issue.Issue
 	var labelColors map[string]string
+	var checkpoint *pullCheckpoint
 
 	if len(args) > 0 {
 		// Resolve args: can be issue numbers, local IDs, or paths
@@ -72,6 +116,7 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 			}
 		}
 
+		setPhase("Fetching issues")
 		for _, number := range remoteNumbers {
 			remote, err := client.GetIssue(ctx, number)
 			if err != nil {
@@ -83,12 +128,23 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 		if err := client.EnrichWithRelationshipsBatch(ctx, remoteIssues); err != nil {
 			fmt.Fprintf(a.Err, "%s fetching relationships: %v\n", t.WarningText("Warning:"), err)
 		}
+	} else if opts.FromExport != "" {
+		data, err := os.ReadFile(opts.FromExport)
+		if err != nil {
+			return err
+		}
+		if remoteIssues, err = ghcli.ParseIssuesExport(data); err != nil {
+			return fmt.Errorf("%s: %w", opts.FromExport, err)
+		}
+		fmt.Fprintf(a.Err, "%s %d issue(s) from %s\n", t.MutedText("Importing"), len(remoteIssues), opts.FromExport)
+		labelColors = a.fetchLabelColors(ctx, client)
 	} else {
 		state := "open"
 		if opts.All {
 			state = "all"
 		}
 
+		setPhase("Listing issues")
 		progress := newProgressReporter(a.Err, a.Theme)
 		client.SetProgress(progress.Update)
 
@@ -97,14 +153,14 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 		// We use "all" state for incremental sync to catch issues that were closed
 		var since time.Time
 		isIncremental := false
-		if cfg.Sync.LastFullPull != nil && !opts.All && !opts.Full && len(opts.Label) == 0 {
+		if cfg.Sync.LastFullPull != nil && !opts.All && !opts.Full && !scoped {
 			since = *cfg.Sync.LastFullPull
 			isIncremental = true
 		}
 
 		// Collect issue numbers we need to fetch for closed issues (only for full sync)
 		var toFetch []string
-		if !opts.All && !isIncremental {
+		if !opts.All && !isIncremental && !scoped {
 			// We don't know remote issue numbers yet, so we'll collect all local non-local issues
 			// and filter after we get the open issues
 			for _, local := range localIssues {
@@ -127,16 +183,34 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 		listCh := make(chan listResult, 1)
 		batchCh := make(chan batchResult, 1)
 
-		go func() {
-			listOpts := ghcli.ListIssuesOptions{
-				State:  state,
-				Labels: opts.Label,
+		listOpts := ghcli.ListIssuesOptions{
+			State:        state,
+			Labels:       opts.Label,
+			Milestone:    opts.Milestone,
+			Assignee:     opts.Assignee,
+			SkipProjects: !caps.Projects,
+		}
+		if isIncremental {
+			// For incremental sync, fetch all states to catch closed issues
+			listOpts.State = "all"
+			listOpts.Since = since
+		}
+		// Real pulls checkpoint every page so an interrupted pull resumes
+		// the listing where it stopped
+		if !opts.DryRun {
+			checkpoint, err = openPullCheckpoint(p, listOpts, a.Now().UTC())
+			if err != nil {
+				return err
 			}
-			if isIncremental {
-				// For incremental sync, fetch all states to catch closed issues
-				listOpts.State = "all"
-				listOpts.Since = since
+			if len(checkpoint.issues) > 0 {
+				fmt.Fprintf(a.Err, "%s %d issue(s) listed by an interrupted pull on %s\n",
+					t.MutedText("Resuming after"), len(checkpoint.issues), checkpoint.StartedAt.Local().Format("2006-01-02 15:04"))
 			}
+			listOpts.After = checkpoint.cursor
+			listOpts.OnPage = checkpoint.page
+		}
+
+		go func() {
 			r, e := client.ListIssuesWithRelationships(ctx, listOpts)
 			listCh <- listResult{r, e}
 		}()
@@ -156,8 +230,18 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 			return listRes.err
 		}
 		remoteIssues = listRes.result.Issues
+		if checkpoint != nil {
+			remoteIssues = append(slices.Clone(checkpoint.issues), remoteIssues...)
+		}
 
 		if isIncremental && len(remoteIssues) == 0 {
+			if opts.JSON {
+				return a.printPlan(newPlan("pull", cfg))
+			}
+			if opts.DryRun {
+				fmt.Fprintf(a.Out, "%s\n", t.MutedText("Nothing to pull: no issues updated since last sync"))
+				return nil
+			}
 			// Nothing changed since last sync - fast path
 			// Still update the last pull timestamp
 			now := a.Now().UTC()
@@ -165,6 +249,11 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 			if err := config.Save(p.ConfigPath, cfg); err != nil {
 				return err
 			}
+			if err := removePullCheckpoint(p); err != nil {
+				return err
+			}
+			a.rearrangeIssues(p)
+			a.refreshBoard(p, cfg, boardKept)
 			fmt.Fprintf(a.Out, "%s\n", t.MutedText("Nothing to pull: no issues updated since last sync"))
 			return nil
 		}
@@ -187,21 +276,33 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 		labelColors = a.fetchLabelColors(ctx, client)
 	}
 
+	setPhase("Writing issue files")
 	localIssues, err = loadLocalIssues(p)
 	if err != nil {
 		return err
 	}
 	localByNumber := map[string]IssueFile{}
+	drafts := map[string]struct{}{}
 	for _, item := range localIssues {
 		localByNumber[item.Issue.Number.String()] = item
+		if item.Issue.Draft {
+			drafts[item.Issue.Number.String()] = struct{}{}
+		}
 	}
 
 	var conflicts []string
+	var incoming, applied []pulledIssue
+	var conflicted []issue.Issue
 	unchanged := 0
+	plan := newPlan("pull", cfg)
 	for _, remote := range remoteIssues {
 		remote.State = strings.ToLower(remote.State)
-		remote.SyncedAt = ptrTime(a.Now().UTC())
+		remote.SyncedAt = pulledSyncedAt(remote, a.Now())
+		remote.ClosedBy = closingPullRequest(remote)
 
+		if _, isDraft := drafts[remote.Number.String()]; isDraft {
+			continue
+		}
 		local, hasLocal := localByNumber[remote.Number.String()]
 		original, hasOriginal := readOriginalIssue(p, remote.Number.String())
 		localChanged := false
@@ -215,50 +316,178 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 
 		if hasLocal && localChanged && !opts.Force {
 			conflicts = append(conflicts, remote.Number.String())
+			if opts.DryRun {
+				plan.add(PlanAction{Kind: "issue", Action: "conflict", Number: remote.Number.String(), Title: remote.Title,
+					Changes: fieldChanges(local.Issue, remote, issue.ComputeChanges(local.Issue, remote).Fields())})
+				continue
+			}
+			conflicted = append(conflicted, remote)
+			if hasOriginal {
+				fields := issue.ComputeChanges(original, local.Issue).Overlaps(issue.ComputeChanges(original, remote))
+				if !fields.IsEmpty() {
+					if err := recordConflict(p, "pull", original, local.Issue, remote, fields.Fields(), a.Now().UTC()); err != nil {
+						fmt.Fprintf(a.Err, "%s recording conflict for #%s: %v\n", t.WarningText("Warning:"), remote.Number, err)
+					}
+				}
+			}
 			continue
 		}
 
-		targetDir := p.OpenDir
-		if remote.State == "closed" {
-			targetDir = p.ClosedDir
-		}
-		newPath := issue.PathFor(targetDir, remote.Number, remote.Title)
-		contentChanged := !hasLocal || !issue.EqualIgnoringSyncedAt(local.Issue, remote)
+		newPath := issue.PathFor(issueDir(p, remote.State, remote), remote.Number, remote.Title)
+		contentChanged := !hasLocal || !issue.EqualIgnoringSyncedAt(local.Issue, remote) ||
+			!slices.Equal(local.Issue.ReferencedBy, remote.ReferencedBy) ||
+			!slices.Equal(local.Issue.PullRequests, remote.PullRequests) ||
+			!slices.Equal(local.Issue.Branches, remote.Branches) ||
+			local.Issue.CommentCount != remote.CommentCount || local.Issue.Reactions != remote.Reactions
 		pathChanged := hasLocal && local.Path != newPath
 		if hasOriginal && !contentChanged && !pathChanged {
+			if cfg.Sync.Mtimes && hasLocal && !opts.DryRun {
+				// Files pulled before the setting was turned on catch up
+				if err := touchIssue(p, local.Path, remote); err != nil {
+					fmt.Fprintf(a.Err, "%s setting the time of %s: %v\n", t.WarningText("Warning:"), relPath(a.Root, local.Path), err)
+				}
+			}
 			unchanged++
 			continue
 		}
+		updated := remote
+		if hasLocal {
+			// Private annotations, wiki links, code refs, custom fields,
+			// the local state and the summary survive the rewrite
+			updated.Body = issue.KeepLocalSyntax(remote.Body, local.Issue.Body)
+			updated.CodeRefs = local.Issue.CodeRefs
+			updated.Summary = local.Issue.Summary
+			updated.Fields = local.Issue.Fields
+			updated.LocalState = local.Issue.LocalState
+			if remote.ClosedBy != 0 && local.State != "closed" && cfg.Sync.MergedState != "" {
+				updated.LocalState = cfg.Sync.MergedState
+			}
+		}
 
-		if hasLocal && local.Path != newPath {
-			if err := os.Rename(local.Path, newPath); err != nil {
-				return err
+		if opts.DryRun {
+			if hasLocal {
+				plan.addIssue(&local.Issue, remote)
+				if pathChanged {
+					action := &plan.Actions[len(plan.Actions)-1]
+					action.Changes = append(action.Changes, FieldChange{Field: "file", Old: relPath(a.Root, local.Path), New: relPath(a.Root, newPath)})
+				}
+			} else {
+				plan.addIssue(nil, remote)
+			}
+			if !opts.JSON {
+				a.printPulledIssue(local, hasLocal, updated, newPath, labelColors)
 			}
+			continue
 		}
-		if err := issue.WriteFile(newPath, remote); err != nil {
+
+		change := pulledIssue{local: local, hasLocal: hasLocal, remote: remote, updated: updated, newPath: newPath}
+		if opts.Review {
+			incoming = append(incoming, change)
+			continue
+		}
+		if err := a.applyPulledIssue(p, change); err != nil {
 			return err
 		}
-		if err := writeOriginalIssue(p, remote); err != nil {
+		applied = append(applied, change)
+		a.printPulledIssue(local, hasLocal, updated, newPath, labelColors)
+	}
+
+	skipped := 0
+	if opts.Review && len(incoming) > 0 {
+		var accepted []pulledIssue
+		accepted, skipped, err = a.reviewPulledIssues(p, incoming, labelColors)
+		if err != nil {
 			return err
 		}
-		if !hasLocal {
-			fmt.Fprintln(a.Out, t.FormatIssueHeader("A", remote.Number.String(), remote.Title))
-			continue
+		applied = append(applied, accepted...)
+	}
+	if len(applied) > 0 {
+		if err := saveLastPull(p, a.Now().UTC(), applied); err != nil {
+			fmt.Fprintf(a.Err, "%s saving last pull: %v\n", t.WarningText("Warning:"), err)
+		}
+	}
+	if cfg.Sync.Snapshots > 0 {
+		for _, change := range applied {
+			if err := saveSnapshot(p, change.remote, cfg.Sync.Snapshots); err != nil {
+				fmt.Fprintf(a.Err, "%s saving snapshot of #%s: %v\n", t.WarningText("Warning:"), change.remote.Number, err)
+			}
+		}
+	}
+	if cfg.Sync.Mtimes {
+		for _, change := range applied {
+			if err := touchIssue(p, change.newPath, change.remote); err != nil {
+				fmt.Fprintf(a.Err, "%s setting the time of %s: %v\n", t.WarningText("Warning:"), relPath(a.Root, change.newPath), err)
+			}
+		}
+	}
+	if !opts.DryRun && len(cfg.Webhooks) > 0 {
+		a.postWebhooks(ctx, cfg, a.pullEvents(ctx, cfg, firstPull, applied, conflicted))
+	}
+
+	if opts.DryRun {
+		if opts.JSON {
+			return a.printPlan(plan)
 		}
-		lines := a.formatChangeLines(local.Issue, remote, labelColors)
-		if len(lines) == 0 && pathChanged {
-			lines = append(lines, t.FormatChange("file", fmt.Sprintf("%q", relPath(a.Root, local.Path)), fmt.Sprintf("%q", relPath(a.Root, newPath))))
+		if len(conflicts) > 0 {
+			sort.Strings(conflicts)
+			fmt.Fprintf(a.Err, "%s %s\n", t.WarningText("Conflicts (local changes, would skip):"), strings.Join(conflicts, ", "))
+		}
+		if len(plan.Actions) == 0 {
+			fmt.Fprintf(a.Out, "%s\n", t.MutedText("Nothing to pull"))
+		}
+		return nil
+	}
+
+	a.rearrangeIssues(p)
+	a.refreshBoard(p, cfg, boardKept)
+
+	if cfg.Sync.Comments {
+		setPhase("Pulling comments")
+		items, err := loadLocalIssues(p)
+		if err != nil {
+			return err
 		}
-		fmt.Fprintln(a.Out, t.FormatIssueHeader("U", remote.Number.String(), remote.Title))
-		for _, line := range lines {
-			fmt.Fprintln(a.Out, line)
+		if pulled := a.pullComments(ctx, p, &cfg, client, items); pulled > 0 {
+			noun := "issues"
+			if pulled == 1 {
+				noun = "issue"
+			}
+			fmt.Fprintf(a.Out, "%s\n", t.MutedText(fmt.Sprintf("Pulled comments of %d %s", pulled, noun)))
+			if err := config.Save(p.ConfigPath, cfg); err != nil {
+				return err
+			}
 		}
 	}
 
+	// An interrupted pull has not seen everything, so the next one must
+	// not take it for a full pull
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if len(args) == 0 {
 		now := a.Now().UTC()
-		cfg.Sync.LastFullPull = &now
-		if err := config.Save(p.ConfigPath, cfg); err != nil {
+		// Skipped issues must be fetched again by the next incremental
+		// pull, and so must everything updated since a resumed listing
+		// started or an export was taken. A scoped pull saw only part of
+		// the repository.
+		if skipped == 0 && !scoped {
+			pulledAt := now
+			if checkpoint != nil {
+				pulledAt = checkpoint.StartedAt
+			}
+			cfg.Sync.LastFullPull = &pulledAt
+			if opts.FromExport != "" {
+				cfg.Sync.LastFullPull = nil
+				if exported := exportedAt(remoteIssues); !exported.IsZero() {
+					cfg.Sync.LastFullPull = &exported
+				}
+			}
+			if err := config.Save(p.ConfigPath, cfg); err != nil {
+				return err
+			}
+		}
+		if err := removePullCheckpoint(p); err != nil {
 			return err
 		}
 
@@ -290,23 +519,59 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 			items []ghcli.Project
 			err   error
 		}
+		type teamsResult struct {
+			items []ghcli.Team
+			err   error
+		}
+		type autolinksResult struct {
+			items []ghcli.Autolink
+			err   error
+		}
+		type repoResult struct {
+			repo ghcli.Repository
+			err  error
+		}
 
+		setPhase("Fetching repository metadata")
 		milestonesCh := make(chan milestonesResult, 1)
 		issueTypesCh := make(chan issueTypesResult, 1)
 		projectsCh := make(chan projectsResult, 1)
+		teamsCh := make(chan teamsResult, 1)
+		autolinksCh := make(chan autolinksResult, 1)
+		repoCh := make(chan repoResult, 1)
 
 		go func() {
 			items, err := client.ListMilestones(ctx)
 			milestonesCh <- milestonesResult{items: items, err: err}
 		}()
 		go func() {
+			if !caps.IssueTypes {
+				issueTypesCh <- issueTypesResult{}
+				return
+			}
 			items, err := client.ListIssueTypes(ctx)
 			issueTypesCh <- issueTypesResult{items: items, err: err}
 		}()
 		go func() {
+			if !caps.Projects {
+				projectsCh <- projectsResult{}
+				return
+			}
 			items, err := client.ListProjects(ctx)
 			projectsCh <- projectsResult{items: items, err: err}
 		}()
+		go func() {
+			items, err := client.ListTeams(ctx)
+			teamsCh <- teamsResult{items: items, err: err}
+		}()
+		go func() {
+			items, err := client.ListAutolinks(ctx)
+			autolinksCh <- autolinksResult{items: items, err: err}
+		}()
+		go func() {
+			repo, err := client.GetRepository(ctx)
+			repoCh <- repoResult{repo: repo, err: err}
+		}()
 
 		milestonesRes := <-milestonesCh
 		if milestonesRes.err != nil {
@@ -315,6 +580,7 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 			entries := make([]MilestoneEntry, 0, len(milestonesRes.items))
 			for _, m := range milestonesRes.items {
 				entries = append(entries, MilestoneEntry{
+					Number:      m.Number,
 					Title:       m.Title,
 					Description: m.Description,
 					DueOn:       m.DueOn,
@@ -325,10 +591,14 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 			sort.Slice(entries, func(i, j int) bool {
 				return strings.ToLower(entries[i].Title) < strings.ToLower(entries[j].Title)
 			})
+			previous, _ := loadMilestoneCache(p)
 			msCache := MilestoneCache{Milestones: entries, SyncedAt: now}
 			if err := saveMilestoneCache(p, msCache); err != nil {
 				fmt.Fprintf(a.Err, "%s saving milestone cache: %v\n", t.WarningText("Warning:"), err)
 			}
+			if err := a.writeMilestoneFiles(p, previous, entries, opts.Force); err != nil {
+				fmt.Fprintf(a.Err, "%s writing milestone files: %v\n", t.WarningText("Warning:"), err)
+			}
 		}
 
 		issueTypesRes := <-issueTypesCh
@@ -355,25 +625,14 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 
 		projectsRes := <-projectsCh
 		if projectsRes.err != nil {
-			if errors.Is(projectsRes.err, ghcli.ErrMissingProjectScope) {
-				// Check if any local issues use projects
-				hasProjects := false
-				for _, item := range localIssues {
-					if len(item.Issue.Projects) > 0 {
-						hasProjects = true
-						break
-					}
-				}
-				if hasProjects {
-					fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), projectsRes.err)
-				}
-			}
+			fmt.Fprintf(a.Err, "%s fetching projects: %v\n", t.WarningText("Warning:"), projectsRes.err)
 		} else if len(projectsRes.items) > 0 {
 			entries := make([]ProjectEntry, 0, len(projectsRes.items))
 			for _, proj := range projectsRes.items {
 				entries = append(entries, ProjectEntry{
-					ID:    proj.ID,
-					Title: proj.Title,
+					ID:     proj.ID,
+					Number: proj.Number,
+					Title:  proj.Title,
 				})
 			}
 			// Sort for consistent output
@@ -385,6 +644,70 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 				fmt.Fprintf(a.Err, "%s saving project cache: %v\n", t.WarningText("Warning:"), err)
 			}
 		}
+
+		a.warnUnsupported(cfg, caps, localIssues)
+
+		teamsRes := <-teamsCh
+		if teamsRes.err != nil {
+			fmt.Fprintf(a.Err, "%s fetching teams: %v\n", t.WarningText("Warning:"), teamsRes.err)
+		} else if len(teamsRes.items) > 0 {
+			entries := make([]TeamEntry, 0, len(teamsRes.items))
+			for _, team := range teamsRes.items {
+				entries = append(entries, TeamEntry{Slug: team.Slug, Name: team.Name})
+			}
+			// Sort for consistent output
+			sort.Slice(entries, func(i, j int) bool {
+				return entries[i].Slug < entries[j].Slug
+			})
+			teamCache := TeamCache{Org: cfg.Repository.Owner, Teams: entries, SyncedAt: now}
+			if err := saveTeamCache(p, teamCache); err != nil {
+				fmt.Fprintf(a.Err, "%s saving team cache: %v\n", t.WarningText("Warning:"), err)
+			}
+		}
+
+		autolinksRes := <-autolinksCh
+		if autolinksRes.err != nil {
+			fmt.Fprintf(a.Err, "%s fetching autolinks: %v\n", t.WarningText("Warning:"), autolinksRes.err)
+		} else if len(autolinksRes.items) > 0 {
+			entries := make([]AutolinkEntry, 0, len(autolinksRes.items))
+			for _, autolink := range autolinksRes.items {
+				entries = append(entries, AutolinkEntry{
+					KeyPrefix:      autolink.KeyPrefix,
+					URLTemplate:    autolink.URLTemplate,
+					IsAlphanumeric: autolink.IsAlphanumeric,
+				})
+			}
+			// Sort for consistent output
+			sort.Slice(entries, func(i, j int) bool {
+				return entries[i].KeyPrefix < entries[j].KeyPrefix
+			})
+			autolinkCache := AutolinkCache{Autolinks: entries, SyncedAt: now}
+			if err := saveAutolinkCache(p, autolinkCache); err != nil {
+				fmt.Fprintf(a.Err, "%s saving autolink cache: %v\n", t.WarningText("Warning:"), err)
+			}
+		}
+
+		repoRes := <-repoCh
+		if repoRes.err != nil {
+			fmt.Fprintf(a.Err, "%s fetching repository metadata: %v\n", t.WarningText("Warning:"), repoRes.err)
+		} else {
+			topics := append([]string(nil), repoRes.repo.Topics...)
+			sort.Strings(topics)
+			repoCache := RepoCache{
+				Description:   repoRes.repo.Description,
+				Topics:        topics,
+				DefaultBranch: repoRes.repo.DefaultBranch,
+				Homepage:      repoRes.repo.Homepage,
+				SyncedAt:      now,
+			}
+			// Unchanged metadata is not rewritten, so the committed file only
+			// changes along with the repository
+			if old, err := loadRepoCache(p); err != nil || !sameRepoMetadata(old, repoCache) {
+				if err := saveRepoCache(p, repoCache); err != nil {
+					fmt.Fprintf(a.Err, "%s saving repository metadata: %v\n", t.WarningText("Warning:"), err)
+				}
+			}
+		}
 	}
 
 	if len(conflicts) > 0 {
@@ -401,7 +724,8 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 
 	// Restore locally deleted issues (originals exist but no local file)
 	if len(args) == 0 {
-		if err := a.restoreDeletedIssues(ctx, p, client, labelColors); err != nil {
+		setPhase("Restoring deleted issues")
+		if err := a.restoreDeletedIssues(ctx, p, client, labelColors, cfg.Sync.Mtimes); err != nil {
 			return err
 		}
 	}
@@ -409,16 +733,128 @@ func (a *App) Pull(ctx context.Context, opts PullOptions, args []string) error {
 	return nil
 }
 
+// printPulledIssue prints an issue written by pull, with the changed
+// fields for existing issues.
+// pulledIssue is an incoming remote change that has not been written yet.
+type pulledIssue struct {
+	local    IssueFile
+	hasLocal bool
+	remote   issue.Issue
+	updated  issue.Issue
+	newPath  string
+}
+
+// applyPulledIssue writes an incoming change to the original and the
+// working tree.
+func (a *App) applyPulledIssue(p paths.Paths, change pulledIssue) error {
+	if change.hasLocal && change.local.Path != change.newPath {
+		if err := moveIssue(p, change.local.Path, change.newPath); err != nil {
+			return err
+		}
+	}
+	if err := writeOriginalIssue(p, change.remote); err != nil {
+		return err
+	}
+	clearConflict(p, change.remote.Number.String())
+	return writeIssue(p, change.newPath, change.updated)
+}
+
+// reviewPulledIssues asks per incoming change whether to apply it. Deferred
+// changes are asked about again once all others were seen. It returns the
+// applied changes and the number of changes that were not applied.
+func (a *App) reviewPulledIssues(p paths.Paths, incoming []pulledIssue, labelColors map[string]string) ([]pulledIssue, int, error) {
+	t := a.Theme
+	in := a.In
+	if in == nil {
+		in = os.Stdin
+	}
+	reader := bufio.NewReader(in)
+
+	var accepted []pulledIssue
+	skipped := 0
+	acceptAll := false
+	queue := incoming
+	var deferred []pulledIssue
+	for round := 0; len(queue) > 0; round++ {
+		for idx, change := range queue {
+			a.printPulledIssue(change.local, change.hasLocal, change.updated, change.newPath, labelColors)
+			key := 'a'
+			if !acceptAll {
+				prompt := "[a]ccept [s]kip [d]efer accept a[l]l [q]uit?"
+				if round > 0 {
+					prompt = "[a]ccept [s]kip accept a[l]l [q]uit?"
+				}
+				fmt.Fprintf(a.Out, "%s ", t.AccentText(prompt))
+				var err error
+				key, err = a.readKey(in, reader)
+				fmt.Fprintln(a.Out)
+				if err != nil {
+					if !errors.Is(err, io.EOF) {
+						return nil, 0, err
+					}
+					key = 'q'
+				}
+			}
+			switch key {
+			case 'a', 'l':
+				acceptAll = acceptAll || key == 'l'
+				if err := a.applyPulledIssue(p, change); err != nil {
+					return nil, 0, err
+				}
+				accepted = append(accepted, change)
+			case 'd':
+				if round == 0 {
+					deferred = append(deferred, change)
+				} else {
+					skipped++
+				}
+			case 'q':
+				skipped += len(queue) - idx + len(deferred)
+				fmt.Fprintf(a.Out, "%s %d, %s %d\n", t.SuccessText("Accepted"), len(accepted), t.WarningText("skipped"), skipped)
+				return accepted, skipped, nil
+			default:
+				skipped++
+			}
+		}
+		queue, deferred = deferred, nil
+	}
+	fmt.Fprintf(a.Out, "%s %d, %s %d\n", t.SuccessText("Accepted"), len(accepted), t.WarningText("skipped"), skipped)
+	return accepted, skipped, nil
+}
+
+func (a *App) printPulledIssue(local IssueFile, hasLocal bool, remote issue.Issue, newPath string, labelColors map[string]string) {
+	t := a.Theme
+	if !hasLocal {
+		fmt.Fprintln(a.Out, t.FormatIssueHeader("A", remote.Number.String(), remote.Title))
+		return
+	}
+	lines := a.formatChangeLines(local.Issue, remote, labelColors)
+	if len(lines) == 0 && local.Path != newPath {
+		lines = append(lines, t.FormatChange("file", fmt.Sprintf("%q", relPath(a.Root, local.Path)), fmt.Sprintf("%q", relPath(a.Root, newPath))))
+	}
+	if remote.ClosedBy != 0 && local.State != "closed" {
+		lines = append(lines, "    "+t.Styler().Fg(t.FieldName, "closed_by: ")+t.AccentText(fmt.Sprintf("#%d (merged)", remote.ClosedBy)))
+	}
+	if remote.LocalState != local.Issue.LocalState {
+		oldState := "<none>"
+		if local.Issue.LocalState != "" {
+			oldState = local.Issue.LocalState
+		}
+		lines = append(lines, t.FormatChange("local_state", oldState, remote.LocalState))
+	}
+	fmt.Fprintln(a.Out, t.FormatIssueHeader("U", remote.Number.String(), remote.Title))
+	for _, line := range lines {
+		fmt.Fprintln(a.Out, line)
+	}
+}
+
 // restoreDeletedIssues finds issues that have originals but no local file and restores them
-func (a *App) restoreDeletedIssues(ctx context.Context, p paths.Paths, client *ghcli.Client, labelColors map[string]string) error {
+func (a *App) restoreDeletedIssues(ctx context.Context, p paths.Paths, client ghcli.Provider, labelColors map[string]string, mtimes bool) error {
 	t := a.Theme
 
 	// List all originals
-	entries, err := os.ReadDir(p.OriginalsDir)
+	originals, err := listIssueFiles(p, p.OriginalsDir)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil
-		}
 		return err
 	}
 
@@ -434,11 +870,11 @@ func (a *App) restoreDeletedIssues(ctx context.Context, p paths.Paths, client *g
 
 	// Find orphaned originals (original exists but no local file)
 	var orphaned []string
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+	for _, path := range originals {
+		if filepath.Ext(path) != ".md" {
 			continue
 		}
-		number := strings.TrimSuffix(entry.Name(), ".md")
+		number := strings.TrimSuffix(filepath.Base(path), ".md")
 		// Skip local issues (T-prefixed)
 		if strings.HasPrefix(number, "T") {
 			continue
@@ -464,17 +900,18 @@ func (a *App) restoreDeletedIssues(ctx context.Context, p paths.Paths, client *g
 		}
 
 		remote.State = strings.ToLower(remote.State)
-		remote.SyncedAt = ptrTime(a.Now().UTC())
+		remote.SyncedAt = pulledSyncedAt(remote, a.Now())
 
-		targetDir := p.OpenDir
-		if remote.State == "closed" {
-			targetDir = p.ClosedDir
-		}
-		newPath := issue.PathFor(targetDir, remote.Number, remote.Title)
+		newPath := issue.PathFor(issueDir(p, remote.State, remote), remote.Number, remote.Title)
 
-		if err := issue.WriteFile(newPath, remote); err != nil {
+		if err := writeIssue(p, newPath, remote); err != nil {
 			return err
 		}
+		if mtimes {
+			if err := touchIssue(p, newPath, remote); err != nil {
+				return err
+			}
+		}
 		if err := writeOriginalIssue(p, remote); err != nil {
 			return err
 		}
@@ -487,7 +924,7 @@ func (a *App) restoreDeletedIssues(ctx context.Context, p paths.Paths, client *g
 
 // fetchLabelColors fetches label colors from GitHub, returning a map of name -> hex color.
 // Errors are silently ignored (we'll just use default colors).
-func (a *App) fetchLabelColors(ctx context.Context, client *ghcli.Client) map[string]string {
+func (a *App) fetchLabelColors(ctx context.Context, client ghcli.Provider) map[string]string {
 	colors := make(map[string]string)
 	labels, err := client.ListLabels(ctx)
 	if err != nil {
@@ -498,3 +935,25 @@ func (a *App) fetchLabelColors(ctx context.Context, client *ghcli.Client) map[st
 	}
 	return colors
 }
+
+// exportedAt estimates when an export was taken from the latest
+// updated_at in it, so the next incremental pull fetches everything that
+// changed since. Exports without timestamps leave the next pull a full one.
+func exportedAt(issues []issue.Issue) time.Time {
+	var latest time.Time
+	for _, iss := range issues {
+		if iss.UpdatedAt != nil && iss.UpdatedAt.After(latest) {
+			latest = *iss.UpdatedAt
+		}
+	}
+	return latest
+}
+
+// pulledSyncedAt is the synced_at of a pulled issue: the remote updated_at,
+// so machines pulling the same change write identical files.
+func pulledSyncedAt(remote issue.Issue, now time.Time) *time.Time {
+	if remote.UpdatedAt != nil {
+		return ptrTime(remote.UpdatedAt.UTC())
+	}
+	return ptrTime(now.UTC())
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestReviewPulledIssues(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var incoming []pulledIssue
+	for _, number := range []string{"1", "2", "3"} {
+		remote := issue.Issue{Number: issue.IssueNumber(number), Title: "Issue " + number, State: "open"}
+		incoming = append(incoming, pulledIssue{remote: remote, updated: remote,
+			newPath: issue.PathFor(p.OpenDir, remote.Number, remote.Title)})
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, &out)
+	application.Theme = theme.Plain()
+	// Accept 1, defer 2, skip 3, then accept 2 on the second round
+	application.In = strings.NewReader("a\nd\ns\na\n")
+	accepted, skipped, err := application.reviewPulledIssues(p, incoming, nil)
+	if err != nil {
+		t.Fatalf("review: %v", err)
+	}
+	if len(accepted) != 2 || skipped != 1 {
+		t.Fatalf("accepted %d, skipped %d, want 2 and 1", len(accepted), skipped)
+	}
+	for number, want := range map[string]bool{"1": true, "2": true, "3": false} {
+		_, err := findIssueByNumber(p, number)
+		if got := err == nil; got != want {
+			t.Errorf("issue %s written = %v, want %v", number, got, want)
+		}
+		if _, ok := readOriginalIssue(p, number); ok != want {
+			t.Errorf("original %s written = %v, want %v", number, ok, want)
+		}
+	}
+	if !strings.Contains(out.String(), "Accepted 2, skipped 1") {
+		t.Fatalf("missing summary:\n%s", out.String())
+	}
+}
@@ -2,32 +2,53 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
-	"github.com/mitsuhiko/gh-issue-sync/internal/lock"
 	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
 )
 
 func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
-	p := paths.New(a.Root)
+	if opts.JSON && !opts.DryRun {
+		return errors.New("--json requires --dry-run")
+	}
+	p := a.issuePaths()
 	cfg, err := loadConfig(p.ConfigPath)
 	if err != nil {
 		return err
 	}
+	if err := a.checkWritable(cfg, "push"); err != nil {
+		return err
+	}
 
 	// Acquire lock
-	lck, err := lock.Acquire(p.SyncDir, lock.DefaultTimeout)
+	lck, err := a.lockMirror(p)
 	if err != nil {
 		return err
 	}
 	defer lck.Release()
+	setPhase("Preparing")
 
-	client := ghcli.NewClient(a.Runner, repoSlug(cfg))
+	// A dry run only reads, so it does not need the write token
+	newProvider := a.newWriteProvider
+	if opts.DryRun {
+		newProvider = a.newProvider
+	}
+	client, err := newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	if err := a.preflight(ctx, client); err != nil {
+		return err
+	}
+	caps := a.capabilities(ctx, p, &cfg, client, false)
 	t := a.Theme
 
 	// Load label cache (or fetch from remote if not cached)
@@ -58,6 +79,7 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 			for _, m := range milestones {
 				knownMilestones[strings.ToLower(m.Title)] = struct{}{}
 				milestoneCache.Milestones = append(milestoneCache.Milestones, MilestoneEntry{
+					Number:      m.Number,
 					Title:       m.Title,
 					Description: m.Description,
 					DueOn:       m.DueOn,
@@ -76,7 +98,7 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 	knownIssueTypes := issueTypeByName(issueTypeCache)
 
 	// If no cache, fetch from remote
-	if len(knownIssueTypes) == 0 {
+	if len(knownIssueTypes) == 0 && caps.IssueTypes {
 		issueTypes, err := client.ListIssueTypes(ctx)
 		if err == nil {
 			for _, it := range issueTypes {
@@ -103,17 +125,19 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 	knownProjects := projectByTitle(projectCache)
 
 	// If no cache, fetch from remote
-	if len(knownProjects) == 0 {
+	if len(knownProjects) == 0 && caps.Projects {
 		projects, err := client.ListProjects(ctx)
 		if err == nil {
 			for _, proj := range projects {
 				knownProjects[strings.ToLower(proj.Title)] = ProjectEntry{
-					ID:    proj.ID,
-					Title: proj.Title,
+					ID:     proj.ID,
+					Number: proj.Number,
+					Title:  proj.Title,
 				}
 				projectCache.Projects = append(projectCache.Projects, ProjectEntry{
-					ID:    proj.ID,
-					Title: proj.Title,
+					ID:     proj.ID,
+					Number: proj.Number,
+					Title:  proj.Title,
 				})
 			}
 			projectCache.SyncedAt = a.Now().UTC()
@@ -124,10 +148,26 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 	if err != nil {
 		return err
 	}
+	// Sequential local IDs created in two clones collide after a merge
+	renumbered, err := a.renumberLocalIDCollisions(p, append(localIssues, loadDraftIssues(p).Issues...), opts.DryRun)
+	if err != nil {
+		return err
+	}
+	if renumbered {
+		if localIssues, err = loadLocalIssues(p); err != nil {
+			return err
+		}
+	}
 	filteredIssues, err := filterIssuesByArgs(a.Root, localIssues, args)
 	if err != nil {
 		return err
 	}
+	filteredIssues = withoutDrafts(filteredIssues)
+	// New issues get the defaults of the template they were written from
+	a.applyTemplateDefaults(p, cfg, projectCache.Projects, filteredIssues)
+	// unsynced collects issues with fields the forge doesn't support, to
+	// warn about once at the end.
+	var unsynced []IssueFile
 
 	// Collect all labels and milestones that will be needed
 	neededLabels := make(map[string]struct{})
@@ -158,6 +198,95 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 	}
 	sort.Strings(missingMilestones)
 
+	// Milestones described in .issues/milestones are created or updated with
+	// their details; they don't need to be created from issue references.
+	milestoneFiles, milestoneErrs := loadMilestoneFiles(p)
+	for _, parseErr := range milestoneErrs {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
+	}
+	var milestonePlan []milestonePush
+	if len(args) == 0 {
+		milestonePlan = planMilestonePush(milestoneFiles, milestoneCache)
+	}
+	var planned []string
+	for _, item := range milestonePlan {
+		if item.Original == nil {
+			for _, name := range missingMilestones {
+				if strings.EqualFold(name, item.File.Milestone.Title) {
+					planned = append(planned, name)
+				}
+			}
+		}
+	}
+	missingMilestones = removeStrings(missingMilestones, planned)
+
+	// Queued label merges rename the source label when the target doesn't
+	// exist yet, so the target must not be created separately
+	var labelMerges []LabelMerge
+	if len(args) == 0 {
+		labelMerges, err = loadLabelMerges(p)
+		if err != nil {
+			return err
+		}
+		var renamed []string
+		for _, m := range labelMerges {
+			_, fromExists := labelColors[strings.ToLower(m.From)]
+			_, toExists := labelColors[strings.ToLower(m.To)]
+			if fromExists && !toExists {
+				for _, label := range missingLabels {
+					if strings.EqualFold(label, m.To) {
+						renamed = append(renamed, label)
+					}
+				}
+			}
+		}
+		missingLabels = removeStrings(missingLabels, renamed)
+	}
+
+	// Check missing labels against the label policy before touching anything
+	if len(missingLabels) > 0 && opts.LabelPolicy != LabelPolicyCreate {
+		knownLabels := make([]string, 0, len(labelCache.Labels))
+		for _, l := range labelCache.Labels {
+			knownLabels = append(knownLabels, l.Name)
+		}
+		var rejected []string
+		for _, label := range missingLabels {
+			suggestion := suggestLabel(label, knownLabels)
+			if suggestion == "" && opts.LabelPolicy != LabelPolicyNone {
+				continue
+			}
+			rejected = append(rejected, label)
+			if suggestion != "" {
+				fmt.Fprintf(a.Err, "%s unknown label %q (did you mean %q?)\n", t.WarningText("Warning:"), label, suggestion)
+			} else {
+				fmt.Fprintf(a.Err, "%s unknown label %q\n", t.WarningText("Warning:"), label)
+			}
+		}
+		if len(rejected) > 0 && !opts.DryRun {
+			return fmt.Errorf("refusing to create %d unknown label(s); fix them or re-run with --create-missing-labels", len(rejected))
+		}
+		missingLabels = removeStrings(missingLabels, rejected)
+	}
+
+	// Typed issues must fill in the sections their template requires
+	if !opts.NoLint {
+		problems := lintRequiredSections(p, cfg, filteredIssues)
+		if len(problems) > 0 {
+			numbers := make([]string, 0, len(problems))
+			for number := range problems {
+				numbers = append(numbers, number)
+			}
+			sort.Strings(numbers)
+			for _, number := range numbers {
+				fmt.Fprintf(a.Err, "%s #%s is missing required section(s): %s\n",
+					t.WarningText("Warning:"), number, strings.Join(problems[number], ", "))
+			}
+			if !opts.DryRun {
+				return fmt.Errorf("refusing to push %d issue(s) with missing required sections; fill them in or re-run with --no-lint", len(problems))
+			}
+		}
+	}
+
 	// Count new issues (T-numbered)
 	var newIssues []*IssueFile
 	for i := range filteredIssues {
@@ -190,16 +319,126 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 		})
 	}
 
+	// Warn about mentions of teams that don't exist before anyone gets
+	// notified (or, worse, nobody does)
+	if teamCache, err := loadTeamCache(p); err == nil && len(teamCache.Teams) > 0 {
+		for _, item := range filteredIssues {
+			original, hasOriginal := readOriginalIssue(p, item.Issue.Number.String())
+			if hasOriginal && issue.PublicBody(original.Body) == issue.PublicBody(item.Issue.Body) {
+				continue
+			}
+			for _, warning := range unknownTeamWarnings(teamCache, item.Issue.Body) {
+				fmt.Fprintf(a.Err, "%s #%s: %s\n", t.WarningText("Warning:"), item.Issue.Number, warning)
+			}
+		}
+		for _, comment := range commentsToPost {
+			for _, warning := range unknownTeamWarnings(teamCache, comment.Body) {
+				fmt.Fprintf(a.Err, "%s comment on #%s: %s\n", t.WarningText("Warning:"), comment.IssueNumber, warning)
+			}
+		}
+	}
+
+	// Warn about references to closed issues and numbers that don't exist
+	if !opts.NoLint {
+		for _, warning := range a.referenceWarnings(ctx, p, client, filteredIssues, localIssues) {
+			fmt.Fprintf(a.Err, "%s %s\n", t.WarningText("Warning:"), warning)
+		}
+	}
+
+	// Run the body checkers (spelling, Markdown style) on changed bodies
+	if !opts.NoLint && len(cfg.Checkers) > 0 {
+		var changed []IssueFile
+		for _, item := range filteredIssues {
+			if bodyChanged(p, item) {
+				changed = append(changed, item)
+			}
+		}
+		findings, err := a.checkBodies(ctx, cfg, changed)
+		if err != nil {
+			fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), err)
+		}
+		for _, item := range changed {
+			for _, finding := range findings[item.Issue.Number.String()] {
+				fmt.Fprintf(a.Err, "%s #%s: %s\n", t.WarningText("Warning:"), item.Issue.Number, finding)
+			}
+		}
+	}
+
+	// Guard against accidental mass edits (e.g. a sed across .issues)
+	massChanges := findMassChanges(p, filteredIssues)
+	threshold := cfg.Push.EffectiveMassChangeThreshold()
+	if len(massChanges) > threshold && !opts.AllowMassChanges {
+		fmt.Fprintf(a.Err, "%s push would close or retitle %d issues (threshold %d):\n",
+			t.WarningText("Warning:"), len(massChanges), threshold)
+		for _, mc := range massChanges {
+			fmt.Fprintf(a.Err, "  %s %s\n", t.AccentText("#"+mc.Number), mc.Description(t))
+		}
+		if !opts.DryRun {
+			return fmt.Errorf("refusing to push %d destructive changes; re-run with --allow-mass-changes if this is intended", len(massChanges))
+		}
+	}
+
+	// Closing an issue that still has open blockers or children is usually
+	// a mistake
+	if gated := closeGateWarnings(massChanges, localIssues); len(gated) > 0 {
+		numbers := make([]string, 0, len(gated))
+		for number := range gated {
+			numbers = append(numbers, number)
+		}
+		sort.Strings(numbers)
+		for _, number := range numbers {
+			for _, reason := range gated[number] {
+				fmt.Fprintf(a.Err, "%s closing #%s: %s\n", t.WarningText("Warning:"), number, reason)
+			}
+		}
+		if opts.Strict && !opts.DryRun {
+			return fmt.Errorf("refusing to close %d issue(s) with open dependencies; re-run without --strict to close them anyway", len(gated))
+		}
+	}
+
 	// Handle dry-run: we need to check pending updates for dry-run output
 	if opts.DryRun {
+		plan := newPlan("push", cfg)
+		// Text output is suppressed when the plan is printed as JSON
+		say := func(format string, args ...any) {
+			if !opts.JSON {
+				fmt.Fprintf(a.Out, format, args...)
+			}
+		}
+		for _, m := range labelMerges {
+			if _, exists := labelColors[strings.ToLower(m.From)]; !exists {
+				continue
+			}
+			if _, exists := labelColors[strings.ToLower(m.To)]; exists && !strings.EqualFold(m.From, m.To) {
+				say("%s %s %s\n", t.MutedText("Would delete label"), m.From, t.MutedText("(merged into "+m.To+")"))
+				plan.add(PlanAction{Kind: "label", Action: "delete", Title: m.From,
+					Changes: []FieldChange{{Field: "merged_into", New: m.To}}})
+			} else {
+				say("%s %s -> %s\n", t.MutedText("Would rename label"), m.From, m.To)
+				plan.add(PlanAction{Kind: "label", Action: "rename", Title: m.From,
+					Changes: []FieldChange{{Field: "name", Old: m.From, New: m.To}}})
+			}
+		}
 		for _, label := range missingLabels {
-			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Would create label"), label)
+			say("%s %s\n", t.MutedText("Would create label"), label)
+			plan.add(PlanAction{Kind: "label", Action: "create", Title: label})
 		}
 		for _, milestone := range missingMilestones {
-			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Would create milestone"), milestone)
+			say("%s %s\n", t.MutedText("Would create milestone"), milestone)
+			plan.add(PlanAction{Kind: "milestone", Action: "create", Title: milestone})
+		}
+		for _, item := range milestonePlan {
+			if item.Original == nil {
+				say("%s %s\n", t.MutedText("Would create milestone"), item.File.Milestone.Title)
+				plan.add(PlanAction{Kind: "milestone", Action: "create", Title: item.File.Milestone.Title})
+			} else {
+				say("%s %s\n", t.MutedText("Would update milestone"), item.File.Milestone.Title)
+				plan.add(PlanAction{Kind: "milestone", Action: "update", Title: item.File.Milestone.Title})
+			}
 		}
 		for _, item := range newIssues {
-			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Would create issue"), item.Issue.Title)
+			say("%s %s\n", t.MutedText("Would create issue"), item.Issue.Title)
+			plan.addIssue(nil, item.Issue)
 		}
 		unchanged := 0
 		for i := range filteredIssues {
@@ -213,10 +452,20 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 				unchanged++
 				continue
 			}
-			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Would push issue"), t.AccentText("#"+item.Issue.Number.String()))
+			say("%s %s\n", t.MutedText("Would push issue"), t.AccentText("#"+item.Issue.Number.String()))
+			if hasOriginal {
+				plan.addIssue(&original, item.Issue)
+			} else {
+				plan.addIssue(&issue.Issue{}, item.Issue)
+			}
 		}
 		for _, comment := range commentsToPost {
-			fmt.Fprintf(a.Out, "%s #%s\n", t.MutedText("Would post comment to"), comment.IssueNumber.String())
+			say("%s #%s\n", t.MutedText("Would post comment to"), comment.IssueNumber.String())
+			plan.add(PlanAction{Kind: "comment", Action: "create", Number: comment.IssueNumber.String(),
+				Changes: []FieldChange{{Field: "body", New: comment.Body}}})
+		}
+		if opts.JSON {
+			return a.printPlan(plan)
 		}
 		if unchanged > 0 {
 			noun := "issues"
@@ -231,13 +480,22 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 	// Start progress bar with initial count (labels + milestones + new issues + comments)
 	// We'll add pending updates after creating new issues
 	progress := newProgressReporter(a.Err, t)
-	progress.SetTotal(len(missingLabels) + len(missingMilestones) + len(newIssues) + len(commentsToPost))
+	progress.SetTotal(len(labelMerges) + len(missingLabels) + len(missingMilestones) + len(milestonePlan) + len(newIssues) + len(commentsToPost))
 	progress.SetPhase("Preparing")
 	progress.Start()
 	defer progress.Done()
 
-	// Create missing labels
+	// Apply queued label merges to the remote labels
 	labelCacheUpdated := false
+	if len(labelMerges) > 0 {
+		failed := a.applyLabelMerges(ctx, client, p, labelMerges, &labelCache, labelColors, progress)
+		if err := saveLabelMerges(p, failed); err != nil {
+			progress.Log(fmt.Sprintf("%s saving label merges: %v", t.WarningText("Warning:"), err))
+		}
+		labelCacheUpdated = true
+	}
+
+	// Create missing labels
 	for _, label := range missingLabels {
 		color := randomLabelColor()
 		if err := client.CreateLabel(ctx, label, color); err != nil {
@@ -252,10 +510,17 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 		progress.Advance()
 	}
 
-	// Create missing milestones
+	// Create or update milestones from milestone files
 	milestoneCacheUpdated := false
+	if len(milestonePlan) > 0 {
+		a.pushMilestoneFiles(ctx, client, milestonePlan, &milestoneCache, opts.Force, progress)
+		knownMilestones = milestoneNames(milestoneCache)
+		milestoneCacheUpdated = true
+	}
+
+	// Create missing milestones
 	for _, milestone := range missingMilestones {
-		if err := client.CreateMilestone(ctx, milestone); err != nil {
+		if err := client.CreateMilestone(ctx, ghcli.Milestone{Title: milestone}); err != nil {
 			progress.Log(fmt.Sprintf("%s creating milestone %q: %v", t.WarningText("Warning:"), milestone, err))
 			progress.Advance()
 			continue
@@ -291,7 +556,29 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 	mapping := map[string]string{}
 	createdNumbers := map[string]struct{}{}
 	for _, item := range newIssues {
-		newNumber, err := client.CreateIssue(ctx, item.Issue)
+		payload := item.Issue
+		payload.Body = issue.PublicBody(payload.Body)
+		// The issue type and projects are set on creation, so leave out
+		// the ones the remote does not know rather than failing the push
+		if _, ok := knownIssueTypes[strings.ToLower(payload.IssueType)]; payload.IssueType != "" && (!ok || !caps.IssueTypes) {
+			if caps.IssueTypes {
+				progress.Log(fmt.Sprintf("%s unknown issue type %q for %s",
+					t.WarningText("Warning:"), payload.IssueType, relPath(a.Root, item.Path)))
+			}
+			payload.IssueType = ""
+		}
+		payload.Projects = nil
+		if caps.Projects {
+			for _, title := range item.Issue.Projects {
+				if _, ok := knownProjects[strings.ToLower(title)]; ok {
+					payload.Projects = append(payload.Projects, title)
+				} else {
+					progress.Log(fmt.Sprintf("%s unknown project %q for %s",
+						t.WarningText("Warning:"), title, relPath(a.Root, item.Path)))
+				}
+			}
+		}
+		newNumber, err := client.CreateIssue(ctx, payload)
 		if err != nil {
 			progress.Done()
 			return err
@@ -301,15 +588,16 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 		createdNumbers[newNumber] = struct{}{}
 		item.Issue.Number = issue.IssueNumber(newNumber)
 		item.Issue.SyncedAt = ptrTime(a.Now().UTC())
-		newPath := issue.PathFor(dirForState(p, item.State), item.Issue.Number, item.Issue.Title)
+		item.Issue.LastLocalEditBy = ""
+		newPath := issue.PathFor(issueDir(p, item.State, item.Issue), item.Issue.Number, item.Issue.Title)
 		if item.Path != newPath {
-			if err := os.Rename(item.Path, newPath); err != nil {
+			if err := moveIssue(p, item.Path, newPath); err != nil {
 				progress.Done()
 				return err
 			}
 			item.Path = newPath
 		}
-		if err := issue.WriteFile(item.Path, item.Issue); err != nil {
+		if err := writeIssue(p, item.Path, item.Issue); err != nil {
 			progress.Done()
 			return err
 		}
@@ -317,6 +605,9 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 			progress.Done()
 			return err
 		}
+		if err := recordLocalID(p, LocalIDMapping{LocalID: oldNumber, Number: newNumber, Title: item.Issue.Title, PushedAt: a.Now().UTC()}); err != nil {
+			progress.Log(fmt.Sprintf("%s recording %s as #%s: %v", t.WarningText("Warning:"), oldNumber, newNumber, err))
+		}
 		progress.Log(t.FormatIssueHeader("A", newNumber, item.Issue.Title))
 		progress.Advance()
 	}
@@ -328,10 +619,11 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 			progress.Done()
 			return err
 		}
+		allIssues = append(allIssues, loadDraftIssues(p).Issues...)
 		for i := range allIssues {
 			changed := applyMapping(&allIssues[i].Issue, mapping)
 			if changed {
-				if err := issue.WriteFile(allIssues[i].Path, allIssues[i].Issue); err != nil {
+				if err := writeIssue(p, allIssues[i].Path, allIssues[i].Issue); err != nil {
 					progress.Done()
 					return err
 				}
@@ -351,35 +643,17 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 			return err
 		}
 
-		// Sync relationships and issue type for newly created issues
+		// Sync relationships for newly created issues
 		for number := range createdNumbers {
 			for _, item := range filteredIssues {
 				if item.Issue.Number.String() == number {
-					if err := client.SyncRelationships(ctx, number, item.Issue); err != nil {
+					if len(unsupportedFeatures(cfg, caps, []IssueFile{item})) > 0 {
+						unsynced = append(unsynced, item)
+					}
+					if _, err := client.SyncRelationships(ctx, number, supportedRelationships(item.Issue, caps)); err != nil {
 						progress.Log(fmt.Sprintf("%s syncing relationships for #%s: %v",
 							t.WarningText("Warning:"), number, err))
 					}
-					if item.Issue.IssueType != "" {
-						if it, ok := knownIssueTypes[strings.ToLower(item.Issue.IssueType)]; ok {
-							if err := client.SetIssueType(ctx, number, it.ID); err != nil {
-								progress.Log(fmt.Sprintf("%s setting issue type for #%s: %v",
-									t.WarningText("Warning:"), number, err))
-							}
-						} else {
-							progress.Log(fmt.Sprintf("%s unknown issue type %q for #%s",
-								t.WarningText("Warning:"), item.Issue.IssueType, number))
-						}
-					}
-					if len(item.Issue.Projects) > 0 {
-						projectIDs := make(map[string]string)
-						for _, proj := range knownProjects {
-							projectIDs[strings.ToLower(proj.Title)] = proj.ID
-						}
-						if err := client.SyncProjects(ctx, number, item.Issue.Projects, projectIDs); err != nil {
-							progress.Log(fmt.Sprintf("%s syncing projects for #%s: %v",
-								t.WarningText("Warning:"), number, err))
-						}
-					}
 					break
 				}
 			}
@@ -444,9 +718,10 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 	var conflicts []conflictInfo
 	var batchUpdates []ghcli.BatchIssueUpdate
 	type postBatchWork struct {
-		Item     *IssueFile
-		Original issue.Issue
-		Change   ghcli.IssueChange
+		Item        *IssueFile
+		Original    issue.Issue
+		HasOriginal bool
+		Change      ghcli.IssueChange
 	}
 	var postBatchWorks []postBatchWork
 	var autoMerged []string
@@ -454,6 +729,11 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 	conflictCount := 0
 	for _, pu := range pendingUpdates {
 		numStr := pu.Item.Issue.Number.String()
+		if issue.HasConflictMarkers(pu.Item.Issue.Body) {
+			progress.Log(fmt.Sprintf("%s #%s has unresolved conflict markers, skipping", t.WarningText("Conflict:"), numStr))
+			conflictCount++
+			continue
+		}
 		remote, ok := remoteIssues[numStr]
 		if !ok {
 			progress.Log(fmt.Sprintf("%s issue #%s not found on remote", t.WarningText("Warning:"), numStr))
@@ -466,7 +746,26 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 			mergeResult := issue.ThreeWayMerge(pu.Original, pu.Item.Issue, remote)
 
 			if !mergeResult.OK {
+				if mergeResult.BodyConflict {
+					// Only overlapping body lines conflict: leave the merge
+					// with conflict markers in the file and rebase it onto
+					// the remote version, so resolving and pushing again
+					// works like a git merge.
+					merged := mergeResult.Merged
+					merged.SyncedAt = pu.Item.Issue.SyncedAt
+					if err := writeIssue(p, pu.Item.Path, merged); err != nil {
+						progress.Log(fmt.Sprintf("%s writing conflict markers for #%s: %v", t.WarningText("Warning:"), numStr, err))
+					} else if err := writeOriginalIssue(p, remote); err != nil {
+						progress.Log(fmt.Sprintf("%s updating original for #%s: %v", t.WarningText("Warning:"), numStr, err))
+					} else {
+						progress.Log(fmt.Sprintf("%s #%s body changed on both sides, conflict markers written to %s (resolve and push again)",
+							t.WarningText("Conflict:"), numStr, relPath(p.Root, pu.Item.Path)))
+					}
+				}
 				// Real conflict - fields overlap
+				if err := recordConflict(p, "push", pu.Original, pu.Item.Issue, remote, mergeResult.ConflictingFields.Fields(), a.Now().UTC()); err != nil {
+					progress.Log(fmt.Sprintf("%s recording conflict for #%s: %v", t.WarningText("Warning:"), numStr, err))
+				}
 				conflicts = append(conflicts, conflictInfo{
 					Number: numStr,
 					Fields: mergeResult.ConflictingFields.Fields(),
@@ -484,9 +783,15 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 				}
 				// Update local file with remote changes
 				remote.SyncedAt = ptrTime(a.Now().UTC())
-				if err := issue.WriteFile(pu.Item.Path, remote); err != nil {
+				remote.Body = issue.KeepLocalSyntax(remote.Body, pu.Item.Issue.Body)
+				remote.CodeRefs = pu.Item.Issue.CodeRefs
+				remote.Summary = pu.Item.Issue.Summary
+				remote.Fields = pu.Item.Issue.Fields
+				remote.LocalState = pu.Item.Issue.LocalState
+				if err := writeIssue(p, pu.Item.Path, remote); err != nil {
 					progress.Log(fmt.Sprintf("%s updating local file for #%s: %v", t.WarningText("Warning:"), numStr, err))
 				}
+				clearConflict(p, numStr)
 				unchanged++
 				continue
 			}
@@ -495,6 +800,7 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 			pu.Item.Issue = mergeResult.Merged
 			autoMerged = append(autoMerged, numStr)
 		}
+		clearConflict(p, numStr)
 
 		// Use remote as baseline if no original exists (for state transitions)
 		baseline := pu.Original
@@ -503,59 +809,137 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 		}
 		change := diffIssue(baseline, pu.Item.Issue)
 
-		// Handle state transitions immediately (can't be batched)
-		if change.StateTransition != nil {
-			if *change.StateTransition == "close" {
-				reason := ""
-				if change.StateReason != nil {
-					reason = *change.StateReason
+		// Build batch update for basic fields
+		if update, ok := batchUpdateFor(numStr, change, pu.Item.Issue); ok {
+			batchUpdates = append(batchUpdates, update)
+		}
+
+		postBatchWorks = append(postBatchWorks, postBatchWork{
+			Item:        pu.Item,
+			Original:    pu.Original,
+			HasOriginal: pu.HasOriginal,
+			Change:      change,
+		})
+	}
+
+	// Re-check the remote right before mutating. An issue edited remotely
+	// since the batch fetch above is merged again with the fresh remote
+	// version, so the concurrent edit is kept instead of overwritten. If
+	// that merge conflicts, the issue is skipped.
+	raced := make(map[string]struct{})
+	if !opts.Force && len(postBatchWorks) > 0 {
+		numbers := make([]string, 0, len(postBatchWorks))
+		for _, work := range postBatchWorks {
+			numbers = append(numbers, work.Item.Issue.Number.String())
+		}
+		stale, err := staleIssues(ctx, client, numbers, remoteIssues)
+		if err != nil {
+			progress.Done()
+			return fmt.Errorf("failed to re-check remote issues: %w", err)
+		}
+		var fresh map[string]issue.Issue
+		if len(stale) > 0 {
+			fresh, err = client.GetIssuesBatch(ctx, stale)
+			if err != nil {
+				progress.Done()
+				return fmt.Errorf("failed to fetch remote issues: %w", err)
+			}
+		}
+		for _, numStr := range stale {
+			idx := slices.IndexFunc(postBatchWorks, func(work postBatchWork) bool {
+				return work.Item.Issue.Number.String() == numStr
+			})
+			work := &postBatchWorks[idx]
+			remote, ok := fresh[numStr]
+			if ok {
+				remoteIssues[numStr] = remote
+			}
+			if ok && work.HasOriginal {
+				mergeResult := issue.ThreeWayMerge(work.Original, work.Item.Issue, remote)
+				if mergeResult.OK {
+					work.Item.Issue = mergeResult.Merged
+					work.Change = diffIssue(work.Original, work.Item.Issue)
+					batchUpdates = slices.DeleteFunc(batchUpdates, func(update ghcli.BatchIssueUpdate) bool {
+						return update.Number == numStr
+					})
+					if update, ok := batchUpdateFor(numStr, work.Change, work.Item.Issue); ok {
+						batchUpdates = append(batchUpdates, update)
+					}
+					autoMerged = append(autoMerged, numStr)
+					continue
 				}
-				if err := client.CloseIssue(ctx, numStr, reason); err != nil {
-					progress.Done()
-					return err
-				}
-			} else if *change.StateTransition == "reopen" {
-				if err := client.ReopenIssue(ctx, numStr); err != nil {
-					progress.Done()
-					return err
+				if err := recordConflict(p, "push", work.Original, work.Item.Issue, remote, mergeResult.ConflictingFields.Fields(), a.Now().UTC()); err != nil {
+					progress.Log(fmt.Sprintf("%s recording conflict for #%s: %v", t.WarningText("Warning:"), numStr, err))
 				}
 			}
+			progress.Log(fmt.Sprintf("%s #%s changed remotely during push, skipping (pull and push again)",
+				t.WarningText("Conflict:"), numStr))
+			raced[numStr] = struct{}{}
+			conflictCount++
+		}
+		if len(raced) > 0 {
+			postBatchWorks = slices.DeleteFunc(postBatchWorks, func(work postBatchWork) bool {
+				_, ok := raced[work.Item.Issue.Number.String()]
+				return ok
+			})
+			batchUpdates = slices.DeleteFunc(batchUpdates, func(update ghcli.BatchIssueUpdate) bool {
+				_, ok := raced[update.Number]
+				return ok
+			})
 		}
+	}
 
-		// Build batch update for basic fields
-		if hasEdits(change) {
-			update := ghcli.BatchIssueUpdate{Number: numStr}
-			if change.Title != nil {
-				update.Title = change.Title
-			}
-			if change.Body != nil {
-				update.Body = change.Body
+	// updatedAt the mutations report for each issue, recorded in the
+	// originals below. Issues changed by mutations that cannot report it
+	// are looked up again instead.
+	mutatedAt := make(map[string]time.Time)
+	recordMutation := func(numStr string, updatedAt time.Time) {
+		if updatedAt.After(mutatedAt[numStr]) {
+			mutatedAt[numStr] = updatedAt
+		}
+	}
+	requery := make(map[string]struct{})
+	for number := range createdNumbers {
+		requery[number] = struct{}{}
+	}
+
+	// Handle state transitions first (can't be batched)
+	for _, work := range postBatchWorks {
+		change := work.Change
+		numStr := work.Item.Issue.Number.String()
+		if change.StateTransition == nil {
+			continue
+		}
+		// Someone else may have made the same transition since the last
+		// sync; repeating it would only add noise to the issue's timeline
+		if remote, ok := remoteIssues[numStr]; ok && transitionDone(*change.StateTransition, remote) {
+			state := strings.ToLower(remote.State)
+			if remote.StateReason != nil && *remote.StateReason != "" {
+				state += " (" + strings.ToLower(*remote.StateReason) + ")"
 			}
-			if change.Milestone != nil {
-				update.Milestone = change.Milestone
+			progress.Log(fmt.Sprintf("%s #%s is already %s remotely, skipping the %s",
+				t.MutedText("Note:"), numStr, state, *change.StateTransition))
+			continue
+		}
+		if *change.StateTransition == "close" {
+			reason := ""
+			if change.StateReason != nil {
+				reason = *change.StateReason
 			}
-			if len(change.AddLabels) > 0 || len(change.RemoveLabels) > 0 {
-				if pu.Item.Issue.Labels == nil {
-					update.Labels = []string{}
-				} else {
-					update.Labels = pu.Item.Issue.Labels
-				}
+			updatedAt, err := client.CloseIssue(ctx, numStr, reason)
+			if err != nil {
+				progress.Done()
+				return err
 			}
-			if len(change.AddAssignees) > 0 || len(change.RemoveAssignees) > 0 {
-				if pu.Item.Issue.Assignees == nil {
-					update.Assignees = []string{}
-				} else {
-					update.Assignees = pu.Item.Issue.Assignees
-				}
+			recordMutation(numStr, updatedAt)
+		} else if *change.StateTransition == "reopen" {
+			updatedAt, err := client.ReopenIssue(ctx, numStr)
+			if err != nil {
+				progress.Done()
+				return err
 			}
-			batchUpdates = append(batchUpdates, update)
+			recordMutation(numStr, updatedAt)
 		}
-
-		postBatchWorks = append(postBatchWorks, postBatchWork{
-			Item:     pu.Item,
-			Original: pu.Original,
-			Change:   change,
-		})
 	}
 
 	// Execute batch update
@@ -568,14 +952,20 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 		for num, errMsg := range result.Errors {
 			progress.Log(fmt.Sprintf("%s updating #%s: %s", t.WarningText("Warning:"), num, errMsg))
 		}
+		for num, updatedAt := range result.UpdatedAt {
+			recordMutation(num, updatedAt)
+		}
 	}
 
 	// Handle post-batch work and finalize
 	for _, work := range postBatchWorks {
 		numStr := work.Item.Issue.Number.String()
+		if len(unsupportedFeatures(cfg, caps, []IssueFile{*work.Item})) > 0 {
+			unsynced = append(unsynced, *work.Item)
+		}
 
 		// Sync issue type via GraphQL (if changed)
-		if work.Change.IssueType != nil {
+		if work.Change.IssueType != nil && caps.IssueTypes {
 			issueTypeID := ""
 			if *work.Change.IssueType != "" {
 				if it, ok := knownIssueTypes[strings.ToLower(*work.Change.IssueType)]; ok {
@@ -586,21 +976,27 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 				}
 			}
 			if issueTypeID != "" || *work.Change.IssueType == "" {
-				if err := client.SetIssueType(ctx, numStr, issueTypeID); err != nil {
+				updatedAt, err := client.SetIssueType(ctx, numStr, issueTypeID)
+				if err != nil {
 					progress.Log(fmt.Sprintf("%s setting issue type for #%s: %v",
 						t.WarningText("Warning:"), numStr, err))
 				}
+				recordMutation(numStr, updatedAt)
 			}
 		}
 
 		// Sync parent and blocking relationships via GraphQL
-		if err := client.SyncRelationships(ctx, numStr, work.Item.Issue); err != nil {
+		relinked, err := client.SyncRelationships(ctx, numStr, supportedRelationships(work.Item.Issue, caps))
+		if err != nil {
 			progress.Log(fmt.Sprintf("%s syncing relationships for #%s: %v",
 				t.WarningText("Warning:"), numStr, err))
 		}
+		if relinked {
+			requery[numStr] = struct{}{}
+		}
 
 		// Sync projects via GraphQL (if changed)
-		if len(work.Change.AddProjects) > 0 || len(work.Change.RemoveProjects) > 0 {
+		if (len(work.Change.AddProjects) > 0 || len(work.Change.RemoveProjects) > 0) && caps.Projects {
 			projectIDs := make(map[string]string)
 			for _, proj := range knownProjects {
 				projectIDs[strings.ToLower(proj.Title)] = proj.ID
@@ -609,13 +1005,21 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 				progress.Log(fmt.Sprintf("%s syncing projects for #%s: %v",
 					t.WarningText("Warning:"), numStr, err))
 			}
+			requery[numStr] = struct{}{}
 		}
 
 		work.Item.Issue.SyncedAt = ptrTime(a.Now().UTC())
-		if err := issue.WriteFile(work.Item.Path, work.Item.Issue); err != nil {
+		work.Item.Issue.LastLocalEditBy = ""
+		if err := writeIssue(p, work.Item.Path, work.Item.Issue); err != nil {
 			progress.Done()
 			return err
 		}
+		// Labels edited in the file can move the issue into another view
+		if newPath, err := routeIssue(p, work.Item.Path, work.Item.State, work.Item.Issue); err != nil {
+			progress.Log(fmt.Sprintf("%s moving #%s: %v", t.WarningText("Warning:"), numStr, err))
+		} else {
+			work.Item.Path = newPath
+		}
 		if err := writeOriginalIssue(p, work.Item.Issue); err != nil {
 			progress.Done()
 			return err
@@ -627,6 +1031,33 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 		progress.Advance()
 	}
 
+	// Record the updatedAt our own edits left in the originals, so they
+	// don't look like remote changes to status --remote or the next push's
+	// re-check. It comes from the mutations themselves where they report
+	// it: querying it again would also swallow anyone else's edit made in
+	// the meantime.
+	if len(requery) > 0 {
+		numbers := make([]string, 0, len(requery))
+		for numStr := range requery {
+			numbers = append(numbers, numStr)
+		}
+		sort.Strings(numbers)
+		if updated, err := client.GetUpdatedAt(ctx, numbers); err == nil {
+			for numStr, updatedAt := range updated {
+				recordMutation(numStr, updatedAt)
+			}
+		}
+	}
+	for numStr, updatedAt := range mutatedAt {
+		if original, ok := readOriginalIssue(p, numStr); ok {
+			original.Number = issue.IssueNumber(numStr)
+			original.UpdatedAt = &updatedAt
+			if err := writeOriginalIssue(p, original); err != nil {
+				progress.Log(fmt.Sprintf("%s updating original for #%s: %v", t.WarningText("Warning:"), numStr, err))
+			}
+		}
+	}
+
 	// Advance for conflicts (they were counted but not processed)
 	for i := 0; i < conflictCount; i++ {
 		progress.Advance()
@@ -634,7 +1065,7 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 
 	// Post comments
 	progress.SetPhase("Posting comments")
-	conflictSet := make(map[string]struct{})
+	conflictSet := raced
 	for _, c := range conflicts {
 		conflictSet[c.Number] = struct{}{}
 	}
@@ -669,7 +1100,11 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 			progress.Log(fmt.Sprintf("%s removing comment file %s: %v", t.WarningText("Warning:"), relPath(a.Root, comment.Path), err))
 		}
 
-		progress.Log(fmt.Sprintf("%s #%s", t.SuccessText("Posted comment to"), numStr))
+		if comment.ReplyTo != "" {
+			progress.Log(fmt.Sprintf("%s #%s %s", t.SuccessText("Posted reply to"), numStr, t.MutedText("(comment "+comment.ReplyTo+")")))
+		} else {
+			progress.Log(fmt.Sprintf("%s #%s", t.SuccessText("Posted comment to"), numStr))
+		}
 		progress.Advance()
 	}
 
@@ -708,6 +1143,116 @@ func (a *App) Push(ctx context.Context, opts PushOptions, args []string) error {
 		}
 		fmt.Fprintf(a.Out, "%s\n", t.MutedText(fmt.Sprintf("Nothing to push: %d %s up to date", unchanged, noun)))
 	}
+	a.warnUnsupported(cfg, caps, unsynced)
 
 	return nil
 }
+
+// massChange is a close or retitle that counts towards the mass change guard.
+type massChange struct {
+	Number   string
+	Close    bool
+	OldTitle string
+	NewTitle string
+}
+
+func (mc massChange) Description(t *theme.Theme) string {
+	if mc.Close {
+		return fmt.Sprintf("%s %s", t.ErrorText("close"), mc.OldTitle)
+	}
+	return fmt.Sprintf("%s %q -> %q", t.WarningText("retitle"), mc.OldTitle, mc.NewTitle)
+}
+
+// findMassChanges returns the issues whose push would close them or change
+// their title relative to the last synced original.
+func findMassChanges(p paths.Paths, items []IssueFile) []massChange {
+	var changes []massChange
+	for _, item := range items {
+		if item.Issue.Number.IsLocal() {
+			continue
+		}
+		original, ok := readOriginalIssue(p, item.Issue.Number.String())
+		if !ok {
+			continue
+		}
+		number := item.Issue.Number.String()
+		if original.State != "closed" && item.Issue.State == "closed" {
+			changes = append(changes, massChange{Number: number, Close: true, OldTitle: original.Title})
+		} else if original.Title != item.Issue.Title {
+			changes = append(changes, massChange{Number: number, OldTitle: original.Title, NewTitle: item.Issue.Title})
+		}
+	}
+	return changes
+}
+
+// staleIssues returns the issues whose remote updatedAt moved past the one
+// in fetched, i.e. that were edited after fetched was taken.
+func staleIssues(ctx context.Context, client ghcli.Provider, numbers []string, fetched map[string]issue.Issue) ([]string, error) {
+	var check []string
+	for _, numStr := range numbers {
+		if remote, ok := fetched[numStr]; ok && remote.UpdatedAt != nil {
+			check = append(check, numStr)
+		}
+	}
+	if len(check) == 0 {
+		return nil, nil
+	}
+	current, err := client.GetUpdatedAt(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, numStr := range check {
+		updatedAt, ok := current[numStr]
+		if ok && updatedAt.After(*fetched[numStr].UpdatedAt) {
+			stale = append(stale, numStr)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// transitionDone reports whether remote already is in the state the
+// transition ("close" or "reopen") leads to.
+func transitionDone(transition string, remote issue.Issue) bool {
+	switch transition {
+	case "close":
+		return strings.EqualFold(remote.State, "closed")
+	case "reopen":
+		return strings.EqualFold(remote.State, "open")
+	}
+	return false
+}
+
+// batchUpdateFor builds the batch update for the basic fields of change.
+// It reports false when none of them changed.
+func batchUpdateFor(numStr string, change ghcli.IssueChange, iss issue.Issue) (ghcli.BatchIssueUpdate, bool) {
+	if !hasEdits(change) {
+		return ghcli.BatchIssueUpdate{}, false
+	}
+	update := ghcli.BatchIssueUpdate{Number: numStr}
+	if change.Title != nil {
+		update.Title = change.Title
+	}
+	if change.Body != nil {
+		update.Body = change.Body
+	}
+	if change.Milestone != nil {
+		update.Milestone = change.Milestone
+	}
+	if len(change.AddLabels) > 0 || len(change.RemoveLabels) > 0 {
+		if iss.Labels == nil {
+			update.Labels = []string{}
+		} else {
+			update.Labels = iss.Labels
+		}
+	}
+	if len(change.AddAssignees) > 0 || len(change.RemoveAssignees) > 0 {
+		if iss.Assignees == nil {
+			update.Assignees = []string{}
+		} else {
+			update.Assignees = iss.Assignees
+		}
+	}
+	return update, true
+}
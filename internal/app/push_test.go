@@ -0,0 +1,349 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// offlineRunner fails every command so tests can assert that nothing was
+// sent to the remote.
+type offlineRunner struct {
+	calls []string
+}
+
+func (r *offlineRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	r.calls = append(r.calls, name+" "+strings.Join(args, " "))
+	return "", errors.New("offline")
+}
+
+func TestPushRefusesMassChanges(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Push.MassChangeThreshold = 2
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	for _, num := range []string{"1", "2", "3"} {
+		original := issue.Issue{Number: issue.IssueNumber(num), Title: "Issue " + num, State: "open"}
+		if err := issue.WriteFile(filepath.Join(p.OriginalsDir, num+".md"), original); err != nil {
+			t.Fatalf("write original: %v", err)
+		}
+		local := original
+		local.Title = "oops"
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, local.Number, local.Title), local); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+
+	var errOut strings.Builder
+	runner := &offlineRunner{}
+	application := New(root, runner, io.Discard, &errOut)
+
+	err := application.Push(context.Background(), PushOptions{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "--allow-mass-changes") {
+		t.Fatalf("expected mass change error, got %v", err)
+	}
+	for _, num := range []string{"#1", "#2", "#3"} {
+		if !strings.Contains(errOut.String(), num) {
+			t.Fatalf("expected %s in listed changes: %s", num, errOut.String())
+		}
+	}
+	for _, call := range runner.calls {
+		if strings.Contains(call, "PATCH") || strings.Contains(call, "mutation") {
+			t.Fatalf("unexpected write to remote: %s", call)
+		}
+	}
+}
+
+func TestPushSuggestsLabelForTypo(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := saveLabelCache(p, LabelCache{Labels: []LabelEntry{{Name: "bug", Color: "ff0000"}}}); err != nil {
+		t.Fatalf("label cache: %v", err)
+	}
+	local := issue.Issue{Number: "T1", Title: "New", State: "open", Labels: []string{"bgu"}}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, local.Number, local.Title), local); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	var errOut strings.Builder
+	runner := &offlineRunner{}
+	application := New(root, runner, io.Discard, &errOut)
+
+	err := application.Push(context.Background(), PushOptions{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "--create-missing-labels") {
+		t.Fatalf("expected unknown label error, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), `did you mean "bug"`) {
+		t.Fatalf("expected suggestion, got: %s", errOut.String())
+	}
+	for _, call := range runner.calls {
+		if strings.Contains(call, "label create") || strings.Contains(call, "labels") {
+			t.Fatalf("unexpected label creation: %s", call)
+		}
+	}
+}
+
+// updatedAtRunner answers the updatedAt re-check query.
+type updatedAtRunner struct {
+	response string
+}
+
+func (r *updatedAtRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return r.response, nil
+}
+
+func TestStaleIssuesDetectsRemoteEdits(t *testing.T) {
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fetched := map[string]issue.Issue{
+		"1": {Number: "1", UpdatedAt: &fetchedAt},
+		"2": {Number: "2", UpdatedAt: &fetchedAt},
+		"3": {Number: "3"},
+	}
+	runner := &updatedAtRunner{response: `{"data":{"repository":{
+		"issue0":{"number":1,"updatedAt":"2026-03-01T12:00:00Z"},
+		"issue1":{"number":2,"updatedAt":"2026-03-01T12:00:05Z"}}}}`}
+	client := ghcli.NewClient(runner, "owner/repo")
+
+	stale, err := staleIssues(context.Background(), client, []string{"1", "2", "3"}, fetched)
+	if err != nil {
+		t.Fatalf("stale: %v", err)
+	}
+	if strings.Join(stale, ",") != "2" {
+		t.Fatalf("expected only #2 to be stale, got %v", stale)
+	}
+}
+
+func TestTransitionDoneSkipsRedundantStateChanges(t *testing.T) {
+	tests := []struct {
+		transition string
+		state      string
+		want       bool
+	}{
+		{"close", "CLOSED", true},
+		{"close", "open", false},
+		{"reopen", "OPEN", true},
+		{"reopen", "closed", false},
+	}
+	for _, tt := range tests {
+		if got := transitionDone(tt.transition, issue.Issue{State: tt.state}); got != tt.want {
+			t.Errorf("transitionDone(%q, %q) = %v, want %v", tt.transition, tt.state, got, tt.want)
+		}
+	}
+}
+
+// remoteRunner plays the repository for a push: it answers the issue,
+// updatedAt and ID lookup queries with the queued responses and records
+// the mutations. Everything else fails like offline.
+type remoteRunner struct {
+	issues    []string // Responses to the issue queries, in order
+	updatedAt []string // Responses to the updatedAt queries, in order
+	mutations []string
+}
+
+func (r *remoteRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	var query string
+	for _, arg := range args {
+		if q, ok := strings.CutPrefix(arg, "query="); ok {
+			query = q
+		}
+	}
+	pop := func(queue *[]string) (string, error) {
+		if len(*queue) == 0 {
+			return "", errors.New("unexpected query")
+		}
+		out := (*queue)[0]
+		*queue = (*queue)[1:]
+		return out, nil
+	}
+	switch {
+	case strings.Contains(query, "createIssue"):
+		r.mutations = append(r.mutations, query)
+		return `{"data":{"createIssue":{"issue":{"number":2}}}}`, nil
+	case strings.HasPrefix(query, "query($owner") && strings.Contains(query, "    id\n"):
+		return `{"data":{"repository":{"id":"R_1"}}}`, nil
+	case strings.HasPrefix(query, "mutation"):
+		r.mutations = append(r.mutations, query)
+		return `{"data":{"update0":{"issue":{"number":1,"updatedAt":"2026-03-01T12:10:00Z"}}}}`, nil
+	case strings.Contains(query, "{ id number }"):
+		return `{"data":{"repository":{"issue0":{"id":"I_1","number":1},
+			"milestones":{"nodes":[]},
+			"labels":{"nodes":[{"id":"L_bug","name":"bug"},{"id":"L_ui","name":"ui"}]}}}}`, nil
+	case strings.Contains(query, "{ number updatedAt }"):
+		return pop(&r.updatedAt)
+	case strings.Contains(query, "stateReason"):
+		return pop(&r.issues)
+	}
+	return "", errors.New("offline")
+}
+
+// remoteIssueResponse renders iss as the answer to an issue query.
+func remoteIssueResponse(t *testing.T, iss issue.Issue) string {
+	t.Helper()
+	labels := []map[string]string{}
+	for _, label := range iss.Labels {
+		labels = append(labels, map[string]string{"name": label})
+	}
+	data, err := json.Marshal(map[string]any{"data": map[string]any{"repository": map[string]any{
+		"issue0": map[string]any{
+			"number":    1,
+			"title":     iss.Title,
+			"body":      iss.Body,
+			"state":     strings.ToUpper(iss.State),
+			"updatedAt": iss.UpdatedAt,
+			"labels":    map[string]any{"nodes": labels},
+			"assignees": map[string]any{"nodes": []any{}},
+		},
+	}}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(data)
+}
+
+// newPushTestRepo sets up a mirror of #1 with the given original and local
+// versions.
+func newPushTestRepo(t *testing.T, original, local issue.Issue) (string, paths.Paths) {
+	t.Helper()
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Capabilities = &config.Capabilities{ProbedAt: time.Now().UTC()}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := saveLabelCache(p, LabelCache{Labels: []LabelEntry{{Name: "bug", Color: "ff0000"}, {Name: "ui", Color: "00ff00"}}}); err != nil {
+		t.Fatalf("label cache: %v", err)
+	}
+	if err := writeOriginalIssue(p, original); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, local.Number, local.Title), local); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	return root, p
+}
+
+func TestPushStripsCommentContext(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	original := issue.Issue{Number: "1", Title: "Flaky test", State: "open", Body: "Fails sometimes.\n", UpdatedAt: &updatedAt}
+	// The editor of edit --with-comments never returned
+	local := original
+	local.Body = "Fails sometimes on CI.\n\n" + issue.CommentContextStart + "\n\n> **@bo**\n>\n> Secret logs\n\n" + issue.CommentContextEnd + "\n"
+	root, _ := newPushTestRepo(t, original, local)
+
+	runner := &remoteRunner{
+		issues:    []string{remoteIssueResponse(t, original)},
+		updatedAt: []string{`{"data":{"repository":{"issue0":{"number":1,"updatedAt":"2026-03-01T12:00:00Z"}}}}`},
+	}
+	application := New(root, runner, io.Discard, io.Discard)
+	if err := application.Push(context.Background(), PushOptions{}, nil); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if len(runner.mutations) != 1 {
+		t.Fatalf("expected one mutation, got %v", runner.mutations)
+	}
+	if !strings.Contains(runner.mutations[0], `body: "Fails sometimes on CI.\n"`) || strings.Contains(runner.mutations[0], "Secret logs") {
+		t.Fatalf("expected the comments stripped from the pushed body, got %s", runner.mutations[0])
+	}
+}
+
+func TestPushRemergesRemoteEditsDuringPush(t *testing.T) {
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	original := issue.Issue{Number: "1", Title: "Crash", State: "open", Labels: []string{"bug"},
+		Body: "Steps:\n1. Open\n2. Click\n\nExpected: no crash\n", UpdatedAt: &fetchedAt}
+	local := original
+	local.Body = "Steps:\n1. Open the app\n2. Click\n\nExpected: no crash\n"
+	root, p := newPushTestRepo(t, original, local)
+
+	// Someone labels the issue and edits another part of the body between
+	// the fetch and the mutation
+	editedAt := fetchedAt.Add(time.Minute)
+	remote := original
+	remote.Labels = []string{"bug", "ui"}
+	remote.Body = "Steps:\n1. Open\n2. Click\n\nExpected: a dialog\n"
+	remote.UpdatedAt = &editedAt
+	runner := &remoteRunner{
+		issues:    []string{remoteIssueResponse(t, original), remoteIssueResponse(t, remote)},
+		updatedAt: []string{`{"data":{"repository":{"issue0":{"number":1,"updatedAt":"2026-03-01T12:01:00Z"}}}}`},
+	}
+	var out strings.Builder
+	application := New(root, runner, &out, io.Discard)
+	if err := application.Push(context.Background(), PushOptions{}, nil); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if !strings.Contains(out.String(), "Auto-merged") {
+		t.Fatalf("expected the re-merge reported, got %q", out.String())
+	}
+
+	mergedBody := "Steps:\n1. Open the app\n2. Click\n\nExpected: a dialog\n"
+	if len(runner.mutations) != 1 {
+		t.Fatalf("expected one mutation, got %v", runner.mutations)
+	}
+	mutation := runner.mutations[0]
+	if !strings.Contains(mutation, fmt.Sprintf("body: %q", mergedBody)) || !strings.Contains(mutation, `labelIds: ["L_bug", "L_ui"]`) {
+		t.Fatalf("expected the merged body and labels pushed, got %s", mutation)
+	}
+	updated, ok := readOriginalIssue(p, "1")
+	if !ok || updated.Body != mergedBody || strings.Join(updated.Labels, ",") != "bug,ui" {
+		t.Fatalf("expected the merge recorded in the original, got %+v", updated)
+	}
+	// The original takes the updatedAt the mutation answered with
+	mutatedAt := time.Date(2026, 3, 1, 12, 10, 0, 0, time.UTC)
+	if updated.UpdatedAt == nil || !updated.UpdatedAt.Equal(mutatedAt) {
+		t.Fatalf("expected the mutation's updatedAt in the original, got %v", updated.UpdatedAt)
+	}
+}
+
+func TestPushRecordsUpdatedAtOfCreatedIssues(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	existing := issue.Issue{Number: "1", Title: "Crash", State: "open", UpdatedAt: &updatedAt}
+	root, p := newPushTestRepo(t, existing, existing)
+	draft := issue.Issue{Number: "T1", Title: "Follow-up", State: "open", Body: "More work.\n"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, draft.Number, draft.Title), draft); err != nil {
+		t.Fatalf("write draft: %v", err)
+	}
+
+	// createIssue cannot report updatedAt, so push looks it up afterwards
+	runner := &remoteRunner{
+		updatedAt: []string{`{"data":{"repository":{"issue0":{"number":2,"updatedAt":"2026-03-01T12:20:00Z"}}}}`},
+	}
+	application := New(root, runner, io.Discard, io.Discard)
+	if err := application.Push(context.Background(), PushOptions{}, nil); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if len(runner.mutations) != 1 {
+		t.Fatalf("expected one mutation, got %v", runner.mutations)
+	}
+	created, ok := readOriginalIssue(p, "2")
+	want := time.Date(2026, 3, 1, 12, 20, 0, 0, time.UTC)
+	if !ok || created.UpdatedAt == nil || !created.UpdatedAt.Equal(want) {
+		t.Fatalf("expected the created issue's updatedAt in the original, got %+v", created)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// ErrReadOnly is returned for anything that would change the tracker while
+// the mirror is read-only.
+var ErrReadOnly = errors.New("the mirror is read-only (read_only in .issues/.sync/config.json or --read-only)")
+
+func (a *App) readOnly(cfg config.Config) bool {
+	return a.ReadOnly || cfg.ReadOnly
+}
+
+// checkWritable refuses action on a read-only mirror.
+func (a *App) checkWritable(cfg config.Config, action string) error {
+	if a.readOnly(cfg) {
+		return fmt.Errorf("%s is disabled: %w", action, ErrReadOnly)
+	}
+	return nil
+}
+
+// readOnlyProvider passes reads through and fails every write, so a
+// read-only mirror cannot change the tracker even from a code path that
+// does not check first.
+type readOnlyProvider struct {
+	ghcli.Provider
+}
+
+func (readOnlyProvider) CreateIssue(ctx context.Context, iss issue.Issue) (string, error) {
+	return "", ErrReadOnly
+}
+
+func (readOnlyProvider) BatchEditIssues(ctx context.Context, updates []ghcli.BatchIssueUpdate) (ghcli.BatchUpdateResult, error) {
+	return ghcli.BatchUpdateResult{}, ErrReadOnly
+}
+
+func (readOnlyProvider) CloseIssue(ctx context.Context, number string, reason string) (time.Time, error) {
+	return time.Time{}, ErrReadOnly
+}
+
+func (readOnlyProvider) ReopenIssue(ctx context.Context, number string) (time.Time, error) {
+	return time.Time{}, ErrReadOnly
+}
+
+func (readOnlyProvider) CreateComment(ctx context.Context, issueNumber string, body string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyProvider) SyncRelationships(ctx context.Context, issueNumber string, local issue.Issue) (bool, error) {
+	return false, ErrReadOnly
+}
+
+func (readOnlyProvider) SetIssueType(ctx context.Context, issueNumber string, issueTypeID string) (time.Time, error) {
+	return time.Time{}, ErrReadOnly
+}
+
+func (readOnlyProvider) SyncProjects(ctx context.Context, issueNumber string, localProjects []string, knownProjects map[string]string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyProvider) CreateLabel(ctx context.Context, name, color string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyProvider) RenameLabel(ctx context.Context, oldName, newName string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyProvider) DeleteLabel(ctx context.Context, name string) error {
+	return ErrReadOnly
+}
+
+func (readOnlyProvider) CreateMilestone(ctx context.Context, m ghcli.Milestone) error {
+	return ErrReadOnly
+}
+
+func (readOnlyProvider) UpdateMilestone(ctx context.Context, number int, m ghcli.Milestone) error {
+	return ErrReadOnly
+}
+
+func (readOnlyProvider) MarkNotificationRead(ctx context.Context, id string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestReadOnly(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.ReadOnly = true
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "1", Title: "Open", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	runner := &offlineRunner{}
+	application := New(root, runner, io.Discard, io.Discard)
+	ctx := context.Background()
+	if err := application.Push(ctx, PushOptions{}, nil); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected push refused, got %v", err)
+	}
+	if err := application.Close(ctx, "1", CloseOptions{}); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected close refused, got %v", err)
+	}
+	if err := application.CommentReply(ctx, "1", "123"); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected comment reply refused, got %v", err)
+	}
+	if file, err := findIssueByNumber(p, "1"); err != nil || file.State != "open" {
+		t.Fatalf("expected the issue left open: %+v %v", file, err)
+	}
+	if len(runner.calls) != 0 {
+		t.Fatalf("expected no calls to the tracker, got %v", runner.calls)
+	}
+
+	// The provider refuses writes even where no command checks first
+	client, err := application.newProvider(cfg)
+	if err != nil {
+		t.Fatalf("provider: %v", err)
+	}
+	if _, err := client.CreateIssue(ctx, iss); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected create refused, got %v", err)
+	}
+	if err := client.CreateLabel(ctx, "bug", "d73a4a"); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected label creation refused, got %v", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Fatalf("expected no calls to the tracker, got %v", runner.calls)
+	}
+
+	// --read-only works without the config flag
+	cfg.ReadOnly = false
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	application.ReadOnly = true
+	if err := application.Reopen(ctx, "1"); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected reopen refused, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type TickOptions struct {
+	DryRun bool
+}
+
+// RecurringState remembers the last period an issue was created for, keyed
+// by definition name, so a period is never instantiated twice.
+type RecurringState struct {
+	Definitions map[string]RecurringEntry `json:"definitions"`
+}
+
+type RecurringEntry struct {
+	LastPeriod string `json:"last_period"`
+	LastIssue  string `json:"last_issue,omitempty"`
+}
+
+func loadRecurringState(p paths.Paths) (RecurringState, error) {
+	state := RecurringState{Definitions: map[string]RecurringEntry{}}
+	data, err := os.ReadFile(p.RecurringPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, err
+	}
+	if state.Definitions == nil {
+		state.Definitions = map[string]RecurringEntry{}
+	}
+	return state, nil
+}
+
+func saveRecurringState(p paths.Paths, state RecurringState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(p.RecurringPath, data, 0o644)
+}
+
+func loadRecurringDefinitions(p paths.Paths) ([]issue.Recurring, []ParseError) {
+	entries, err := os.ReadDir(p.RecurringDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []ParseError{{Path: p.RecurringDir, Err: err}}
+	}
+	var defs []issue.Recurring
+	var parseErrors []ParseError
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		path := filepath.Join(p.RecurringDir, entry.Name())
+		def, err := issue.ParseRecurringFile(path)
+		if err != nil {
+			parseErrors = append(parseErrors, ParseError{Path: relPath(p.Root, path), Err: err})
+			continue
+		}
+		if strings.TrimSpace(def.Template.Title) == "" {
+			parseErrors = append(parseErrors, ParseError{Path: relPath(p.Root, path), Err: errors.New("missing title")})
+			continue
+		}
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, parseErrors
+}
+
+// instantiateRecurring builds the issue for one period. Variables in the
+// title and body are expanded with vars, and {{period}} is the start date of
+// the period.
+func instantiateRecurring(def issue.Recurring, number issue.IssueNumber, period string, vars templateVars) issue.Issue {
+	vars = vars.with("period", period)
+	iss := def.Template
+	iss.Number = number
+	iss.Title = strings.TrimSpace(vars.expand(iss.Title))
+	iss.Body = vars.expand(iss.Body)
+	iss.State = "open"
+	iss.StateReason = nil
+	iss.Draft = false
+	iss.SyncedAt = nil
+	return iss
+}
+
+// Tick creates a local issue for every recurring definition whose current
+// period has not been instantiated yet. It is meant to be run from cron or
+// before a push; running it twice in the same period does nothing.
+func (a *App) Tick(ctx context.Context, opts TickOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	vars := a.templateVars(ctx, cfg)
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	defs, parseErrors := loadRecurringDefinitions(p)
+	for _, parseErr := range parseErrors {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
+	}
+	if len(defs) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No recurring issues defined"))
+		return nil
+	}
+
+	state, err := loadRecurringState(p)
+	if err != nil {
+		return err
+	}
+
+	now := a.Now()
+	created := 0
+	for _, def := range defs {
+		entry := state.Definitions[def.Name]
+		period, due := def.Due(now, entry.LastPeriod)
+		if !due {
+			continue
+		}
+		if opts.DryRun {
+			title := instantiateRecurring(def, "", period, vars).Title
+			fmt.Fprintf(a.Out, "%s %s %s\n", t.MutedText("Would create"), title, t.MutedText("("+def.Name+")"))
+			created++
+			continue
+		}
+
+		localNumber, err := a.newLocalNumber(p)
+		if err != nil {
+			return err
+		}
+		iss := instantiateRecurring(def, localNumber, period, vars)
+		path := issue.PathFor(issueDir(p, "open", iss), iss.Number, iss.Title)
+		if err := writeIssue(p, path, iss); err != nil {
+			return err
+		}
+		state.Definitions[def.Name] = RecurringEntry{LastPeriod: period, LastIssue: iss.Number.String()}
+		// Save after every issue so a failure later on can't cause duplicates
+		if err := saveRecurringState(p, state); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.Out, "%s %s\n", t.SuccessText("Created"), relPath(a.Root, path))
+		created++
+	}
+
+	if created == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No recurring issues due"))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestTickCreatesOncePerPeriod(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := os.MkdirAll(p.RecurringDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	def := "---\ntitle: Release checklist {{period}}\nlabels:\n    - release\nevery: weekly\nstart: 2026-01-05\n---\n\n- [ ] Tag\n"
+	if err := os.WriteFile(filepath.Join(p.RecurringDir, "release.md"), []byte(def), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	tick := func(now time.Time) {
+		t.Helper()
+		application.Now = func() time.Time { return now }
+		if err := application.Tick(context.Background(), TickOptions{}); err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+	}
+
+	tick(time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)) // Thursday
+	tick(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)) // Sunday, same week
+	issues, err := loadLocalIssues(p)
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("expected one issue, got %d (%v)", len(issues), err)
+	}
+	if got := issues[0].Issue.Title; got != "Release checklist 2026-10-12" {
+		t.Fatalf("unexpected title: %q", got)
+	}
+	if !issues[0].Issue.Number.IsLocal() || strings.Join(issues[0].Issue.Labels, ",") != "release" {
+		t.Fatalf("unexpected issue: %+v", issues[0].Issue)
+	}
+
+	tick(time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)) // next Monday
+	issues, _ = loadLocalIssues(p)
+	if len(issues) != 2 {
+		t.Fatalf("expected a second issue, got %d", len(issues))
+	}
+	if !strings.Contains(out.String(), "No recurring issues due") {
+		t.Fatalf("expected second tick to be a no-op: %s", out.String())
+	}
+}
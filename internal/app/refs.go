@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// issueReferences returns the distinct same-repository references in a
+// body, skipping code blocks, inline code, and local notes.
+func issueReferences(body string) []string {
+	var refs []string
+	seen := map[string]struct{}{}
+	mapProse(issue.StripLocalNotes(body), func(text string) string {
+		for _, m := range bodyRefPattern.FindAllStringSubmatch(text, -1) {
+			if _, ok := seen[m[1]]; !ok {
+				seen[m[1]] = struct{}{}
+				refs = append(refs, m[1])
+			}
+		}
+		return text
+	})
+	return refs
+}
+
+// mapProse applies fn to the parts of body outside code blocks and inline
+// code.
+func mapProse(body string, fn func(string) string) string {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, loc := range inlineCodePattern.FindAllStringIndex(line, -1) {
+			b.WriteString(fn(line[last:loc[0]]))
+			b.WriteString(line[loc[0]:loc[1]])
+			last = loc[1]
+		}
+		b.WriteString(fn(line[last:]))
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// annotateReferences adds the title of every referenced issue it knows
+// after the reference, e.g. "#123 (Fix login bug)".
+func annotateReferences(body string, titles map[string]string) string {
+	return mapProse(body, func(text string) string {
+		return bodyRefPattern.ReplaceAllStringFunc(text, func(match string) string {
+			ref := match[strings.LastIndex(match, "#")+1:]
+			if title, ok := titles[ref]; ok && title != "" {
+				return match + " (" + title + ")"
+			}
+			return match
+		})
+	})
+}
+
+// referenceWarnings checks the references in the bodies of items that are
+// new or whose body changed since the last sync. References to closed
+// issues and to issues that exist neither locally nor remotely (as an issue
+// or pull request) get a warning. Numbers that can't be checked, e.g.
+// because the tracker is unreachable, are skipped.
+func (a *App) referenceWarnings(ctx context.Context, p paths.Paths, client ghcli.Provider, items, all []IssueFile) []string {
+	local := make(map[string]IssueFile, len(all))
+	for _, item := range all {
+		local[item.Issue.Number.String()] = item
+	}
+	for _, item := range loadDraftIssues(p).Issues {
+		local[item.Issue.Number.String()] = item
+	}
+
+	type reference struct{ source, ref string }
+	var refs []reference
+	var remote []string
+	queued := map[string]struct{}{}
+	for _, item := range items {
+		number := item.Issue.Number.String()
+		if !item.Issue.Number.IsLocal() {
+			original, hasOriginal := readOriginalIssue(p, number)
+			if hasOriginal && issue.PublicBody(original.Body) == issue.PublicBody(item.Issue.Body) {
+				continue
+			}
+		}
+		for _, ref := range issueReferences(item.Issue.Body) {
+			if ref == number {
+				continue
+			}
+			refs = append(refs, reference{number, ref})
+			if _, ok := local[ref]; ok || issue.IssueNumber(ref).IsLocal() {
+				continue
+			}
+			if _, ok := queued[ref]; !ok {
+				queued[ref] = struct{}{}
+				remote = append(remote, ref)
+			}
+		}
+	}
+
+	// Issues that were never pulled are looked up one by one; only
+	// numbers GitHub knows as neither issue nor pull request are missing
+	found := map[string]issue.Issue{}
+	missing := map[string]bool{}
+	for _, ref := range remote {
+		iss, err := client.GetIssue(ctx, ref)
+		if err == nil {
+			found[ref] = iss
+			continue
+		}
+		if ghcli.IsIssueOrPullRequestNotFound(err, ref) {
+			missing[ref] = true
+		}
+	}
+
+	var warnings []string
+	for _, r := range refs {
+		if item, ok := local[r.ref]; ok {
+			if item.State == "closed" {
+				warnings = append(warnings, fmt.Sprintf("#%s: references closed #%s (%s)", r.source, r.ref, item.Issue.Title))
+			}
+			continue
+		}
+		if issue.IssueNumber(r.ref).IsLocal() {
+			warnings = append(warnings, fmt.Sprintf("#%s: references #%s, which is no local issue", r.source, r.ref))
+			continue
+		}
+		if iss, ok := found[r.ref]; ok && iss.State == "closed" {
+			warnings = append(warnings, fmt.Sprintf("#%s: references closed #%s (%s)", r.source, r.ref, iss.Title))
+		} else if missing[r.ref] {
+			warnings = append(warnings, fmt.Sprintf("#%s: references #%s, which does not exist", r.source, r.ref))
+		}
+	}
+	return warnings
+}
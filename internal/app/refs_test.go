@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestIssueReferences(t *testing.T) {
+	body := "Fixes #12 and #T1a2b, see #12 again.\n\n`#13` is code\n```\n#14\n```\nfoo#15 bar/#16 &#17;\n"
+	if refs := issueReferences(body); !slices.Equal(refs, []string{"12", "T1a2b"}) {
+		t.Fatalf("unexpected references: %v", refs)
+	}
+
+	titles := map[string]string{"12": "Fix login bug"}
+	got := annotateReferences("See #12 and #99.\n`#12`\n", titles)
+	if got != "See #12 (Fix login bug) and #99.\n`#12`\n" {
+		t.Fatalf("unexpected annotation: %q", got)
+	}
+}
+
+// refStubProvider knows #50 as a closed issue and #77 as a pull request.
+type refStubProvider struct {
+	ghcli.Provider
+	lookups []string
+}
+
+func (r *refStubProvider) GetIssue(ctx context.Context, number string) (issue.Issue, error) {
+	r.lookups = append(r.lookups, number)
+	switch number {
+	case "50":
+		return issue.Issue{Number: "50", Title: "Old crash", State: "closed"}, nil
+	case "77":
+		return issue.Issue{}, errors.New("issue #77 is a pull request")
+	}
+	return issue.Issue{}, fmt.Errorf("GraphQL: Could not resolve to an issue or pull request with the number of %s. (repository.issue)", number)
+}
+
+func TestReferenceWarnings(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	closed := issue.Issue{Number: "3", Title: "Done", State: "closed"}
+	if err := issue.WriteFile(issue.PathFor(p.ClosedDir, closed.Number, closed.Title), closed); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	// Unchanged bodies are not checked again
+	unchanged := issue.Issue{Number: "4", Title: "Unchanged", State: "open", Body: "See #999"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, unchanged.Number, unchanged.Title), unchanged); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := issue.WriteFile(filepath.Join(p.OriginalsDir, "4.md"), unchanged); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+	draft := issue.Issue{Number: "T1", Title: "New", State: "open", Body: "Follows #3, #50, #77, #99 and #T2."}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, draft.Number, draft.Title), draft); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	all, err := loadLocalIssues(p)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	client := &refStubProvider{}
+	warnings := application.referenceWarnings(context.Background(), p, client, all, all)
+	text := strings.Join(warnings, "\n")
+	for _, want := range []string{
+		"#T1: references closed #3 (Done)",
+		"#T1: references closed #50 (Old crash)",
+		"#T1: references #99, which does not exist",
+		"#T1: references #T2, which is no local issue",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in warnings:\n%s", want, text)
+		}
+	}
+	if len(warnings) != 4 {
+		t.Fatalf("expected 4 warnings, got:\n%s", text)
+	}
+	if slices.Contains(client.lookups, "999") || slices.Contains(client.lookups, "3") {
+		t.Fatalf("expected only unknown numbers of changed issues looked up, got %v", client.lookups)
+	}
+}
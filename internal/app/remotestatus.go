@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// Divergence of a tracked issue from its remote, like git status against
+// an upstream branch.
+type divergence int
+
+const (
+	inSync        divergence = iota
+	ahead                    // changed locally only
+	behind                   // changed remotely only
+	diverged                 // changed on both sides
+	missingRemote            // not found on the remote anymore
+)
+
+// classifyDivergence compares a local issue against its original and the
+// remote's current updatedAt. found is false when the remote has no such
+// issue.
+func classifyDivergence(local, original issue.Issue, remoteUpdatedAt time.Time, found bool) divergence {
+	if !found {
+		return missingRemote
+	}
+	localChanged := !issue.EqualIgnoringSyncedAt(local, original)
+	remoteChanged := original.UpdatedAt == nil || remoteUpdatedAt.After(*original.UpdatedAt)
+	switch {
+	case localChanged && remoteChanged:
+		return diverged
+	case localChanged:
+		return ahead
+	case remoteChanged:
+		return behind
+	}
+	return inSync
+}
+
+// printRemoteStatus fetches the updatedAt of every tracked issue in one
+// batch and reports which issues are ahead of, behind, or diverged from
+// the remote.
+func (a *App) printRemoteStatus(ctx context.Context, p paths.Paths, cfg config.Config, localIssues []IssueFile) error {
+	t := a.Theme
+
+	type tracked struct {
+		item     IssueFile
+		original issue.Issue
+	}
+	var items []tracked
+	var numbers []string
+	for _, item := range localIssues {
+		if item.Issue.Draft || item.Issue.Number.IsLocal() {
+			continue
+		}
+		original, ok := readOriginalIssue(p, item.Issue.Number.String())
+		if !ok {
+			continue
+		}
+		items = append(items, tracked{item: item, original: original})
+		numbers = append(numbers, item.Issue.Number.String())
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	client, err := a.newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	updated, err := client.GetUpdatedAt(ctx, numbers)
+	if err != nil {
+		return fmt.Errorf("failed to check remote: %w", err)
+	}
+
+	groups := make(map[divergence][]IssueFile)
+	for _, it := range items {
+		updatedAt, found := updated[it.item.Issue.Number.String()]
+		state := classifyDivergence(it.item.Issue, it.original, updatedAt, found)
+		groups[state] = append(groups[state], it.item)
+	}
+
+	var summary []string
+	for _, g := range []struct {
+		state divergence
+		name  string
+	}{{behind, "behind"}, {ahead, "ahead"}, {diverged, "diverged"}, {missingRemote, "missing"}, {inSync, "in sync"}} {
+		if n := len(groups[g.state]); n > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", n, g.name))
+		}
+	}
+	fmt.Fprintln(a.Out)
+	fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Remote:"), strings.Join(summary, ", "))
+
+	for _, g := range []struct {
+		state  divergence
+		status string
+		title  string
+	}{
+		{behind, "B", "Behind remote (run pull):"},
+		{diverged, "D", "Diverged (changed locally and remotely):"},
+		{missingRemote, "?", "Missing on remote:"},
+	} {
+		list := groups[g.state]
+		if len(list) == 0 {
+			continue
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Issue.Number.String() < list[j].Issue.Number.String()
+		})
+		fmt.Fprintln(a.Out)
+		fmt.Fprintln(a.Out, t.Bold(g.title))
+		for _, item := range list {
+			fmt.Fprintln(a.Out, t.FormatIssueHeader(g.status, item.Issue.Number.String(), item.Issue.Title))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+func TestClassifyDivergence(t *testing.T) {
+	synced := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	later := synced.Add(time.Hour)
+	original := issue.Issue{Number: "1", Title: "A", State: "open", UpdatedAt: &synced}
+	edited := original
+	edited.Title = "A, edited"
+
+	tests := []struct {
+		name    string
+		local   issue.Issue
+		updated time.Time
+		found   bool
+		want    divergence
+	}{
+		{"in sync", original, synced, true, inSync},
+		{"ahead", edited, synced, true, ahead},
+		{"behind", original, later, true, behind},
+		{"diverged", edited, later, true, diverged},
+		{"missing", original, time.Time{}, false, missingRemote},
+	}
+	for _, tt := range tests {
+		if got := classifyDivergence(tt.local, original, tt.updated, tt.found); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
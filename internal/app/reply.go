@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+)
+
+// replyMarkerPattern matches the threading marker at the top of a pending
+// reply. GitHub and GitLab hide HTML comments when rendering, so the marker
+// is posted as-is and keeps the thread recoverable from the comment body.
+var replyMarkerPattern = regexp.MustCompile(`(?m)^<!--\s*in-reply-to:\s*(\S+)\s*-->\s*$`)
+
+// replyTo returns the comment ID a pending comment replies to, if any.
+func replyTo(body string) string {
+	m := replyMarkerPattern.FindStringSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// renderReplyTemplate builds the editor template for a reply: the threading
+// marker followed by the parent comment as a Markdown quote.
+func renderReplyTemplate(parent ghcli.Comment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!-- in-reply-to: %s -->\n", parent.ID)
+	author := parent.Author
+	if author == "" {
+		author = "ghost"
+	}
+	if parent.URL != "" {
+		fmt.Fprintf(&b, "> [@%s wrote](%s):\n", author, parent.URL)
+	} else {
+		fmt.Fprintf(&b, "> @%s wrote:\n", author)
+	}
+	b.WriteString(">\n")
+	for _, line := range strings.Split(strings.TrimRight(parent.Body, "\n"), "\n") {
+		if line == "" {
+			b.WriteString(">\n")
+		} else {
+			b.WriteString("> " + line + "\n")
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// CommentReply opens the editor on a pending comment that quotes and replies
+// to an existing comment. The reply is posted on the next push.
+func (a *App) CommentReply(ctx context.Context, number, commentID string) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if err := a.checkWritable(cfg, "commenting"); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	file, err := findIssueByNumber(p, number)
+	if err != nil {
+		return err
+	}
+	if file.Issue.Number.IsLocal() {
+		return fmt.Errorf("issue %s has not been pushed yet and has no comments", file.Issue.Number)
+	}
+	if existing, ok := findPendingCommentForIssue(p, file.Issue.Number, file.State); ok {
+		return fmt.Errorf("issue #%s already has a pending comment: %s", file.Issue.Number, relPath(a.Root, existing.Path))
+	}
+
+	client, err := a.newProvider(cfg)
+	if err != nil {
+		return err
+	}
+	parent, err := client.GetComment(ctx, file.Issue.Number.String(), commentID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch comment %s: %w", commentID, err)
+	}
+
+	template := renderReplyTemplate(parent)
+	path := filepath.Join(filepath.Dir(file.Path), file.Issue.Number.String()+".comment.md")
+	if err := os.WriteFile(path, []byte(template), 0o644); err != nil {
+		return err
+	}
+	if err := openEditor(ctx, p, "comment", path); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(content)) == strings.TrimSpace(template) {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		fmt.Fprintln(a.Out, t.MutedText("Reply is empty, discarded"))
+		return nil
+	}
+
+	fmt.Fprintf(a.Out, "%s #%s %s\n", t.SuccessText("Saved reply to"), file.Issue.Number, t.MutedText("(run push to post)"))
+	return nil
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type commentRunner struct {
+	calls [][]string
+}
+
+func (r *commentRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	r.calls = append(r.calls, append([]string{name}, args...))
+	return `{"id": 991, "body": "Does this break the CLI?\n\nIt looks like it.", "html_url": "https://github.com/owner/repo/issues/7#issuecomment-991", "created_at": "2026-01-02T03:04:05Z", "user": {"login": "alice"}}`, nil
+}
+
+func TestCommentReplyQuotesParent(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "7", Title: "Broken", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	var template string
+	prev := runInteractiveCommand
+	runInteractiveCommand = func(ctx context.Context, command string, args ...string) error {
+		path := args[len(args)-1]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read template: %v", err)
+		}
+		template = string(data)
+		return os.WriteFile(path, append(data, []byte("No, it is fine.\n")...), 0o644)
+	}
+	t.Cleanup(func() { runInteractiveCommand = prev })
+	t.Setenv("EDITOR", "true")
+
+	runner := &commentRunner{}
+	application := New(root, runner, io.Discard, io.Discard)
+	if err := application.CommentReply(context.Background(), "7", "991"); err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if len(runner.calls) != 1 || runner.calls[0][2] != "repos/owner/repo/issues/comments/991" {
+		t.Fatalf("unexpected calls: %v", runner.calls)
+	}
+	if !strings.Contains(template, "> [@alice wrote](https://github.com/owner/repo/issues/7#issuecomment-991):") ||
+		!strings.Contains(template, "> It looks like it.") {
+		t.Fatalf("parent not quoted:\n%s", template)
+	}
+
+	comment, ok := findPendingCommentForIssue(p, "7", "open")
+	if !ok {
+		t.Fatalf("expected pending comment")
+	}
+	if comment.ReplyTo != "991" {
+		t.Fatalf("expected reply target 991, got %q", comment.ReplyTo)
+	}
+	if !strings.HasSuffix(comment.Body, "No, it is fine.") {
+		t.Fatalf("unexpected body: %q", comment.Body)
+	}
+}
+
+func TestCommentReplyDiscardsUnchangedTemplate(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "7", Title: "Broken", State: "open"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	prev := runInteractiveCommand
+	runInteractiveCommand = func(ctx context.Context, command string, args ...string) error { return nil }
+	t.Cleanup(func() { runInteractiveCommand = prev })
+	t.Setenv("EDITOR", "true")
+
+	application := New(root, &commentRunner{}, io.Discard, io.Discard)
+	if err := application.CommentReply(context.Background(), "7", "991"); err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if _, ok := findPendingCommentForIssue(p, "7", "open"); ok {
+		t.Fatalf("expected empty reply to be discarded")
+	}
+}
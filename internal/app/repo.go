@@ -0,0 +1,51 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// RepoCache stores the repository metadata synced on pull, so the mirror
+// describes itself when it is checked in or shared.
+type RepoCache struct {
+	Description   string    `json:"description,omitempty"`
+	Topics        []string  `json:"topics,omitempty"`
+	DefaultBranch string    `json:"default_branch,omitempty"`
+	Homepage      string    `json:"homepage,omitempty"`
+	SyncedAt      time.Time `json:"synced_at"`
+}
+
+func loadRepoCache(p paths.Paths) (RepoCache, error) {
+	var cache RepoCache
+	data, err := os.ReadFile(p.RepoPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return cache, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, err
+	}
+	return cache, nil
+}
+
+// sameRepoMetadata reports whether a and b differ at most in SyncedAt.
+func sameRepoMetadata(a, b RepoCache) bool {
+	return a.Description == b.Description && slices.Equal(a.Topics, b.Topics) &&
+		a.DefaultBranch == b.DefaultBranch && a.Homepage == b.Homepage
+}
+
+func saveRepoCache(p paths.Paths, cache RepoCache) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(p.RepoPath, data, 0o644)
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestStatusShowsRepoMetadata(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	cache := RepoCache{
+		Description:   "Sync issues to Markdown files",
+		Topics:        []string{"cli", "github"},
+		DefaultBranch: "main",
+		Homepage:      "https://example.com",
+		SyncedAt:      time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if err := saveRepoCache(p, cache); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	if err := application.Status(context.Background(), StatusOptions{}); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for _, want := range []string{"Sync issues to Markdown files", "cli, github", "main", "https://example.com"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in status output, got:\n%s", want, out.String())
+		}
+	}
+}
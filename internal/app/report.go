@@ -0,0 +1,227 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+)
+
+type ReportOptions struct {
+	By       string // "assignee" (default) or "label"
+	State    string // "open" (default), "closed" or "all"
+	Field    string // front matter field holding estimates (default: estimate)
+	Markdown bool   // print Markdown for pasting into a document
+}
+
+// reportLane is one swimlane of a report: an assignee or label with its
+// issues grouped by milestone.
+type reportLane struct {
+	name     string
+	items    []IssueFile
+	estimate float64
+}
+
+// Report prints a swimlane report: every assignee (or label) with their
+// issues grouped by milestone, the total of their estimates and their
+// oldest issue. Issues in several lanes split their estimate evenly, as in
+// plan.
+func (a *App) Report(ctx context.Context, opts ReportOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	field := opts.Field
+	if field == "" {
+		field = defaultEstimateField
+	}
+	state := opts.State
+	if state == "" {
+		state = "open"
+	}
+	by := opts.By
+	if by == "" {
+		by = "assignee"
+	}
+
+	result := loadLocalIssuesWithErrors(p)
+	for _, parseErr := range result.Errors {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
+	}
+	lanes := map[string]*reportLane{}
+	for _, item := range result.Issues {
+		if state != "all" && item.State != state {
+			continue
+		}
+		names := item.Issue.Assignees
+		if by == "label" {
+			names = withoutPriorityLabels(p.Format, item.Issue.Labels)
+		}
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		estimate, _ := issueEstimate(item.Issue, field)
+		for _, name := range names {
+			key := strings.ToLower(name)
+			lane, ok := lanes[key]
+			if !ok {
+				lane = &reportLane{name: name}
+				lanes[key] = lane
+			}
+			lane.items = append(lane.items, item)
+			lane.estimate += estimate / float64(len(names))
+		}
+	}
+	if len(lanes) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No issues found"))
+		return nil
+	}
+
+	ordered := make([]*reportLane, 0, len(lanes))
+	for _, lane := range lanes {
+		ordered = append(ordered, lane)
+	}
+	// Lanes by name, with the issues nobody has last
+	sort.Slice(ordered, func(i, j int) bool {
+		if (ordered[i].name == "") != (ordered[j].name == "") {
+			return ordered[j].name == ""
+		}
+		return strings.ToLower(ordered[i].name) < strings.ToLower(ordered[j].name)
+	})
+	for i, lane := range ordered {
+		if i > 0 {
+			fmt.Fprintln(a.Out)
+		}
+		if opts.Markdown {
+			a.printReportLaneMarkdown(cfg, lane, by, state, field)
+		} else {
+			a.printReportLane(lane, by, state, field)
+		}
+	}
+	return nil
+}
+
+func (a *App) printReportLane(lane *reportLane, by, state, field string) {
+	t := a.Theme
+	fmt.Fprintf(a.Out, "%s %s\n", t.Bold(reportLaneTitle(lane.name, by)), t.MutedText(a.reportLaneSummary(lane, state)))
+	for _, group := range groupByMilestone(lane.items) {
+		fmt.Fprintf(a.Out, "  %s\n", t.AccentText(reportMilestoneTitle(group.milestone)))
+		for _, item := range group.items {
+			line := "    " + padRight(reportIssueNumber(item), 8) + item.Issue.Title
+			if estimate, ok := issueEstimate(item.Issue, field); ok {
+				line += " " + t.MutedText("("+formatEstimate(estimate)+")")
+			}
+			fmt.Fprintln(a.Out, line)
+		}
+	}
+}
+
+func (a *App) printReportLaneMarkdown(cfg config.Config, lane *reportLane, by, state, field string) {
+	fmt.Fprintf(a.Out, "## %s\n\n", reportLaneTitle(lane.name, by))
+	fmt.Fprintf(a.Out, "%s\n", a.reportLaneSummary(lane, state))
+	for _, group := range groupByMilestone(lane.items) {
+		fmt.Fprintf(a.Out, "\n**%s**\n\n", reportMilestoneTitle(group.milestone))
+		for _, item := range group.items {
+			number := reportIssueNumber(item)
+			if !item.Issue.Number.IsLocal() {
+				number = "[" + number + "](" + issueURL(cfg, item.Issue.Number.String()) + ")"
+			}
+			line := "- " + number + " " + item.Issue.Title
+			if estimate, ok := issueEstimate(item.Issue, field); ok {
+				line += " (" + formatEstimate(estimate) + ")"
+			}
+			fmt.Fprintln(a.Out, line)
+		}
+	}
+}
+
+// reportLaneSummary is the issue count, estimate total and oldest issue of
+// a lane.
+func (a *App) reportLaneSummary(lane *reportLane, state string) string {
+	noun := "issue"
+	if len(lane.items) != 1 {
+		noun += "s"
+	}
+	if state != "all" {
+		noun = state + " " + noun
+	}
+	parts := []string{strconv.Itoa(len(lane.items)) + " " + noun}
+	if lane.estimate > 0 {
+		parts = append(parts, "estimate "+formatEstimate(lane.estimate))
+	}
+	var oldest *IssueFile
+	for i, item := range lane.items {
+		created := item.Issue.CreatedAt
+		if created != nil && (oldest == nil || created.Before(*oldest.Issue.CreatedAt)) {
+			oldest = &lane.items[i]
+		}
+	}
+	if oldest != nil {
+		parts = append(parts, fmt.Sprintf("oldest %s opened %s", reportIssueNumber(*oldest),
+			formatRelativeTime(a.Now(), *oldest.Issue.CreatedAt)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+type milestoneGroup struct {
+	milestone string
+	items     []IssueFile
+}
+
+// groupByMilestone groups items by milestone, ordered by name with the
+// issues without a milestone last, and each group by issue number.
+func groupByMilestone(items []IssueFile) []milestoneGroup {
+	var groups []milestoneGroup
+	index := map[string]int{}
+	for _, item := range items {
+		key := strings.ToLower(item.Issue.Milestone)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, milestoneGroup{milestone: item.Issue.Milestone})
+		}
+		groups[i].items = append(groups[i].items, item)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i].milestone == "") != (groups[j].milestone == "") {
+			return groups[j].milestone == ""
+		}
+		return strings.ToLower(groups[i].milestone) < strings.ToLower(groups[j].milestone)
+	})
+	for _, group := range groups {
+		sort.SliceStable(group.items, func(i, j int) bool {
+			return lessIssueNumber(group.items[i].Issue.Number.String(), group.items[j].Issue.Number.String())
+		})
+	}
+	return groups
+}
+
+func reportLaneTitle(name, by string) string {
+	if by != "label" {
+		return assigneeLabel(name)
+	}
+	if name == "" {
+		return "(no label)"
+	}
+	return name
+}
+
+func reportMilestoneTitle(milestone string) string {
+	if milestone == "" {
+		return "(no milestone)"
+	}
+	return milestone
+}
+
+func reportIssueNumber(item IssueFile) string {
+	if item.Issue.Number.IsLocal() {
+		return item.Issue.Number.String()
+	}
+	return "#" + item.Issue.Number.String()
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestReport(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	old, recent := now.AddDate(0, 0, -70), now.AddDate(0, 0, -3)
+	pairing := issue.Issue{Number: "2", Title: "Pairing", State: "open", Milestone: "v2.0", Assignees: []string{"alice", "bob"}, CreatedAt: &recent}
+	pairing.SetField("estimate", "4")
+	login := issue.Issue{Number: "1", Title: "Login", State: "open", Milestone: "v2.0", Assignees: []string{"alice"}, CreatedAt: &old}
+	login.SetField("estimate", "3")
+	for _, iss := range []issue.Issue{
+		login,
+		pairing,
+		{Number: "3", Title: "Docs", State: "open", Assignees: []string{"alice"}, CreatedAt: &recent},
+		{Number: "4", Title: "Nobody's", State: "open", Milestone: "v1.0"},
+	} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+	closed := issue.Issue{Number: "5", Title: "Done", State: "closed", Assignees: []string{"alice"}}
+	if err := issue.WriteFile(issue.PathFor(p.ClosedDir, closed.Number, closed.Title), closed); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	var out strings.Builder
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	application.Now = func() time.Time { return now }
+	if err := application.Report(context.Background(), ReportOptions{}); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	want := `@alice 3 open issues, estimate 5, oldest #1 opened 2 months ago
+  v2.0
+    #1      Login (3)
+    #2      Pairing (4)
+  (no milestone)
+    #3      Docs
+
+@bob 1 open issue, estimate 2, oldest #2 opened 3 days ago
+  v2.0
+    #2      Pairing (4)
+
+(unassigned) 1 open issue
+  v1.0
+    #4      Nobody's
+`
+	if out.String() != want {
+		t.Fatalf("unexpected report:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := application.Report(context.Background(), ReportOptions{Markdown: true, State: "all"}); err != nil {
+		t.Fatalf("report --markdown: %v", err)
+	}
+	for _, want := range []string{
+		"## @alice\n\n4 issues, estimate 5",
+		"**v2.0**\n\n- [#1](https://github.com/owner/repo/issues/1) Login (3)\n",
+		"- [#5](https://github.com/owner/repo/issues/5) Done\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in Markdown report:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// repoTemplate is an issue template of the repository in
+// .github/ISSUE_TEMPLATE, a Markdown template or an issue form. Issues
+// opened on the web from a template get its labels, assignees and
+// projects; push gives them to new issues written from one.
+type repoTemplate struct {
+	Name      string
+	Type      string
+	Labels    []string
+	Assignees []string
+	Projects  []string // OWNER/NUMBER
+	// Headings are the section headings an issue written from the template
+	// has: the headings of a Markdown template or the field labels of a
+	// form, which GitHub renders as ### headings.
+	Headings []string
+}
+
+type repoTemplateFile struct {
+	Name      string       `yaml:"name"`
+	Type      string       `yaml:"type"`
+	Labels    templateList `yaml:"labels"`
+	Assignees templateList `yaml:"assignees"`
+	Projects  templateList `yaml:"projects"`
+	Body      []struct {
+		Type       string `yaml:"type"`
+		Attributes struct {
+			Label string `yaml:"label"`
+		} `yaml:"attributes"`
+	} `yaml:"body"`
+}
+
+// templateList is a list in a template's front matter, which GitHub also
+// accepts as a comma separated string.
+type templateList []string
+
+func (l *templateList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = nil
+		for _, item := range strings.Split(node.Value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*l = append(*l, item)
+			}
+		}
+		return nil
+	}
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
+
+// repoTemplatesDir is where GitHub looks for issue templates.
+func repoTemplatesDir(root string) string {
+	return filepath.Join(root, ".github", "ISSUE_TEMPLATE")
+}
+
+// loadRepoTemplates reads the issue templates of the repository at root.
+// Templates that fail to parse are returned as errors and left out.
+func loadRepoTemplates(root string) ([]repoTemplate, []error) {
+	dir := repoTemplatesDir(root)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	var templates []repoTemplate
+	var errs []error
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		if entry.IsDir() || strings.HasPrefix(strings.ToLower(name), "config.") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var file repoTemplateFile
+		var headings []string
+		switch ext {
+		case ".md":
+			front, body, ok := splitTemplateFrontMatter(data)
+			if !ok {
+				continue
+			}
+			err = yaml.Unmarshal(front, &file)
+			headings = markdownHeadings(string(body))
+		case ".yml", ".yaml":
+			err = yaml.Unmarshal(data, &file)
+			for _, field := range file.Body {
+				if field.Type != "markdown" && field.Attributes.Label != "" {
+					headings = append(headings, field.Attributes.Label)
+				}
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Join(".github", "ISSUE_TEMPLATE", name), err))
+			continue
+		}
+		if file.Name == "" {
+			file.Name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		templates = append(templates, repoTemplate{
+			Name:      file.Name,
+			Type:      file.Type,
+			Labels:    file.Labels,
+			Assignees: file.Assignees,
+			Projects:  file.Projects,
+			Headings:  headings,
+		})
+	}
+	return templates, errs
+}
+
+// splitTemplateFrontMatter splits a Markdown template into its front
+// matter and body. Templates without front matter are not offered by
+// GitHub.
+func splitTemplateFrontMatter(data []byte) (front, body []byte, ok bool) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	rest, ok := bytes.CutPrefix(data, []byte("---\n"))
+	if !ok {
+		return nil, nil, false
+	}
+	front, body, ok = bytes.Cut(rest, []byte("\n---"))
+	if !ok {
+		return nil, nil, false
+	}
+	_, body, _ = bytes.Cut(body, []byte("\n"))
+	return front, body, true
+}
+
+// markdownHeadings returns the headings of body outside code fences.
+func markdownHeadings(body string) []string {
+	var headings []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil && !inFence {
+			headings = append(headings, m[2])
+		}
+	}
+	return headings
+}
+
+// matchRepoTemplate finds the template iss was written from: the one for
+// its issue type, or else the one with the most headings that all appear
+// in the body.
+func matchRepoTemplate(templates []repoTemplate, iss issue.Issue) (repoTemplate, bool) {
+	if iss.IssueType != "" {
+		for _, tmpl := range templates {
+			if strings.EqualFold(tmpl.Type, iss.IssueType) {
+				return tmpl, true
+			}
+		}
+	}
+	present := map[string]bool{}
+	for _, heading := range markdownHeadings(iss.Body) {
+		present[strings.ToLower(heading)] = true
+	}
+	var best repoTemplate
+	found := false
+	for _, tmpl := range templates {
+		if len(tmpl.Headings) == 0 || (found && len(tmpl.Headings) <= len(best.Headings)) {
+			continue
+		}
+		matches := true
+		for _, heading := range tmpl.Headings {
+			if !present[strings.ToLower(heading)] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			best, found = tmpl, true
+		}
+	}
+	return best, found
+}
+
+// applyTemplateDefaults gives the new issues among items the labels,
+// assignees and projects of the repository template they were written
+// from and of the local template for their issue type, so they match
+// issues opened on the web.
+func (a *App) applyTemplateDefaults(p paths.Paths, cfg config.Config, projects []ProjectEntry, items []IssueFile) {
+	t := a.Theme
+	templates, errs := loadRepoTemplates(a.Root)
+	for _, err := range errs {
+		fmt.Fprintf(a.Err, "%s issue template %v\n", t.WarningText("Warning:"), err)
+	}
+	for i := range items {
+		iss := &items[i].Issue
+		if !iss.Number.IsLocal() {
+			continue
+		}
+		var name string
+		var labels, assignees, projectTitles []string
+		if tmpl, ok := matchRepoTemplate(templates, *iss); ok {
+			name = tmpl.Name
+			labels = append(labels, tmpl.Labels...)
+			assignees = append(assignees, tmpl.Assignees...)
+			for _, ref := range tmpl.Projects {
+				title, ok := projectForRef(cfg, projects, ref)
+				if !ok {
+					fmt.Fprintf(a.Err, "%s issue template %q: unknown project %s\n", t.WarningText("Warning:"), tmpl.Name, ref)
+					continue
+				}
+				projectTitles = append(projectTitles, title)
+			}
+		}
+		if iss.IssueType != "" {
+			if tmpl, ok, err := loadTemplate(p, iss.IssueType); err == nil && ok {
+				if name == "" {
+					name = relPath(a.Root, templatePath(p, iss.IssueType))
+				}
+				labels = append(labels, tmpl.Labels...)
+				assignees = append(assignees, tmpl.Assignees...)
+				projectTitles = append(projectTitles, tmpl.Projects...)
+			}
+		}
+		added := addMissingFold(&iss.Labels, labels) + addMissingFold(&iss.Assignees, assignees) +
+			addMissingFold(&iss.Projects, projectTitles)
+		if added > 0 {
+			fmt.Fprintf(a.Err, "%s %s to #%s\n", t.MutedText("Applied template defaults of"), name, iss.Number)
+		}
+	}
+}
+
+// projectForRef resolves a template's OWNER/NUMBER project reference to
+// the title of a project of the repository owner.
+func projectForRef(cfg config.Config, projects []ProjectEntry, ref string) (string, bool) {
+	owner, number, ok := strings.Cut(ref, "/")
+	n, err := strconv.Atoi(number)
+	if !ok || err != nil || !strings.EqualFold(owner, cfg.Repository.Owner) {
+		return "", false
+	}
+	for _, project := range projects {
+		if project.Number == n {
+			return project.Title, true
+		}
+	}
+	return "", false
+}
+
+// addMissingFold appends the values not yet in list, ignoring case, and
+// returns how many it added.
+func addMissingFold(list *[]string, values []string) int {
+	added := 0
+	for _, value := range values {
+		if !containsFold(*list, value) {
+			*list = append(*list, value)
+			added++
+		}
+	}
+	return added
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// reviewHeaderPattern matches the section headers of the review buffer.
+var reviewHeaderPattern = regexp.MustCompile(`^<!-- pending comment #(\S+): (.+) -->$`)
+
+const reviewPreamble = `<!-- Review pending comments before push. Each section below is one comment.
+     Edit the text freely, clear a section (or delete it with its header) to
+     discard that comment. Headers must be kept for comments you want to keep. -->
+`
+
+type reviewSection struct {
+	Number string
+	Path   string // relative to the repository root
+	Body   string
+}
+
+// renderReviewBuffer joins pending comments into a single editable buffer.
+func renderReviewBuffer(root string, comments []PendingComment) string {
+	var b strings.Builder
+	b.WriteString(reviewPreamble)
+	for _, c := range comments {
+		fmt.Fprintf(&b, "\n<!-- pending comment #%s: %s -->\n", c.IssueNumber, relPath(root, c.Path))
+		b.WriteString(c.Body)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// parseReviewBuffer splits an edited review buffer back into sections.
+// Text before the first header is ignored.
+func parseReviewBuffer(content string) []reviewSection {
+	var sections []reviewSection
+	var current *reviewSection
+	var body []string
+	flush := func() {
+		if current != nil {
+			current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+			sections = append(sections, *current)
+		}
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if m := reviewHeaderPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			flush()
+			current = &reviewSection{Number: m[1], Path: m[2]}
+			body = nil
+			continue
+		}
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+	flush()
+	return sections
+}
+
+// CommentReview opens all pending comments in one editor buffer. Edited
+// sections are written back, cleared or removed sections are discarded.
+func (a *App) CommentReview(ctx context.Context) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	pending := loadAllPendingComments(p)
+	if len(pending) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No pending comments"))
+		return nil
+	}
+	comments := make([]PendingComment, 0, len(pending))
+	for _, c := range pending {
+		comments = append(comments, c)
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].IssueNumber.String() < comments[j].IssueNumber.String()
+	})
+
+	tmp, err := os.CreateTemp("", "gh-issue-sync-comments-*.md")
+	if err != nil {
+		return err
+	}
+	tempPath := tmp.Name()
+	defer os.Remove(tempPath)
+	if _, err := tmp.WriteString(renderReviewBuffer(a.Root, comments)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := openEditor(ctx, p, "comment", tempPath); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(tempPath)
+	if err != nil {
+		return err
+	}
+
+	edited := make(map[string]string)
+	for _, section := range parseReviewBuffer(string(content)) {
+		edited[section.Path] = section.Body
+	}
+
+	updated, discarded := 0, 0
+	for _, c := range comments {
+		rel := relPath(a.Root, c.Path)
+		body, ok := edited[rel]
+		if !ok || body == "" {
+			if err := deletePendingComment(c); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.Out, "%s #%s %s\n", t.WarningText("Discarded comment on"), c.IssueNumber, t.MutedText(rel))
+			discarded++
+			continue
+		}
+		if body == c.Body {
+			continue
+		}
+		if err := os.WriteFile(c.Path, []byte(body+"\n"), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.Out, "%s #%s %s\n", t.SuccessText("Updated comment on"), c.IssueNumber, t.MutedText(rel))
+		updated++
+	}
+
+	if updated == 0 && discarded == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No changes to pending comments"))
+	}
+	return nil
+}
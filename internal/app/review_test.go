@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestCommentReviewEditsAndDiscards(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	keep := filepath.Join(p.OpenDir, "1.comment.md")
+	drop := filepath.Join(p.OpenDir, "2.comment.md")
+	if err := os.WriteFile(keep, []byte("Half-writen note\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(drop, []byte("TODO finish this\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	prev := runInteractiveCommand
+	runInteractiveCommand = func(ctx context.Context, command string, args ...string) error {
+		path := args[len(args)-1]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read buffer: %v", err)
+		}
+		content := strings.Replace(string(data), "Half-writen note", "Finished note", 1)
+		content = strings.Replace(content, "TODO finish this", "", 1)
+		return os.WriteFile(path, []byte(content), 0o644)
+	}
+	t.Cleanup(func() { runInteractiveCommand = prev })
+	t.Setenv("EDITOR", "true")
+
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+	if err := application.CommentReview(context.Background()); err != nil {
+		t.Fatalf("review: %v", err)
+	}
+
+	data, err := os.ReadFile(keep)
+	if err != nil {
+		t.Fatalf("read kept comment: %v", err)
+	}
+	if string(data) != "Finished note\n" {
+		t.Fatalf("unexpected kept comment: %q", data)
+	}
+	if _, err := os.Stat(drop); !os.IsNotExist(err) {
+		t.Fatalf("expected cleared comment to be removed")
+	}
+}
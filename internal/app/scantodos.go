@@ -0,0 +1,268 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+type ScanTodosOptions struct {
+	DryRun bool
+}
+
+// todoComment is a TODO or FIXME comment found in the source tree.
+type todoComment struct {
+	Text string
+	Path string // Slash separated, relative to the checkout
+	Line int
+}
+
+// todoCommentPattern matches TODO and FIXME after a comment marker, with an
+// optional "(owner)" and colon: "// TODO(jane): retry on 503".
+var todoCommentPattern = regexp.MustCompile(`(?://|#|/\*|<!--|--|;|^\s*\*)\s*(?:TODO|FIXME)\b(?:\([^)]*\))?:?\s*(.*?)\s*(?:\*/|-->)?\s*$`)
+
+// maxScanFileSize skips generated and vendored blobs.
+const maxScanFileSize = 1 << 20
+
+// ScanTodos walks the source tree for TODO and FIXME comments. New comments
+// become drafts with the todo label and a code_refs entry, so triage can
+// promote them. Issues whose comment moved get their reference updated, and
+// issues whose comment disappeared are reported as candidates for closing.
+func (a *App) ScanTodos(ctx context.Context, opts ScanTodosOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	todoLabel := cfg.Todo.EffectiveLabel()
+
+	top, head, ok := gitCheckout(ctx, a.Root)
+	if !ok {
+		top = a.Root
+	}
+	comments, err := a.findTodoComments(ctx, p.IssuesDir, top, ok)
+	if err != nil {
+		return err
+	}
+
+	if !opts.DryRun {
+		lck, err := a.lockMirror(p)
+		if err != nil {
+			return err
+		}
+		defer lck.Release()
+	}
+
+	items, err := loadLocalIssues(p)
+	if err != nil {
+		return err
+	}
+	items = append(items, loadDraftIssues(p).Issues...)
+
+	// Comments are matched to issues by their text in the same file, or by
+	// the exact location for issues that were retitled.
+	byText := map[string]int{}
+	byLocation := map[string]int{}
+	for i, item := range items {
+		for _, raw := range item.Issue.CodeRefs {
+			ref, err := parseCodeRef(raw)
+			if err != nil || ref.URL != "" {
+				continue
+			}
+			byText[ref.Path+"\x00"+item.Issue.Title] = i
+			byLocation[ref.location()] = i
+		}
+	}
+
+	verb := func(done, planned string) string {
+		if opts.DryRun {
+			return planned
+		}
+		return done
+	}
+	matched := map[int]bool{}
+	seen := map[string]bool{}
+	created, updated := 0, 0
+	for _, comment := range comments {
+		key := comment.Path + "\x00" + comment.Text
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ref := codeRef{Path: comment.Path, Line: comment.Line, Commit: head}
+
+		idx, found := byText[key]
+		if !found {
+			idx, found = byLocation[ref.location()]
+		}
+		if found {
+			matched[idx] = true
+			item := &items[idx]
+			if !moveCodeRef(&item.Issue, ref) {
+				continue
+			}
+			updated++
+			fmt.Fprintf(a.Out, "%s #%s %s %s\n", t.SuccessText(verb("Updated", "Would update")), item.Issue.Number,
+				item.Issue.Title, t.MutedText("("+ref.location()+")"))
+			if !opts.DryRun {
+				if err := writeIssue(p, item.Path, item.Issue); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		localNumber, err := a.newLocalNumber(p)
+		if err != nil {
+			return err
+		}
+		draft := issue.Issue{
+			Number:   localNumber,
+			Title:    comment.Text,
+			Labels:   []string{todoLabel},
+			State:    "open",
+			Draft:    true,
+			CodeRefs: []string{ref.String()},
+			Body:     fmt.Sprintf("Found by scan-todos in `%s`.\n", ref.location()),
+		}
+		created++
+		fmt.Fprintf(a.Out, "%s %s %s %s\n", t.SuccessText(verb("Created", "Would create")), draft.Number, draft.Title,
+			t.MutedText("("+ref.location()+")"))
+		if opts.DryRun {
+			continue
+		}
+		if err := os.MkdirAll(p.DraftsDir, 0o755); err != nil {
+			return err
+		}
+		if err := writeIssue(p, issue.PathFor(p.DraftsDir, draft.Number, draft.Title), draft); err != nil {
+			return err
+		}
+	}
+
+	gone := 0
+	for i, item := range items {
+		if matched[i] || item.State != "open" || !containsFold(item.Issue.Labels, todoLabel) {
+			continue
+		}
+		for _, raw := range item.Issue.CodeRefs {
+			ref, err := parseCodeRef(raw)
+			if err != nil || ref.URL != "" {
+				continue
+			}
+			gone++
+			fmt.Fprintf(a.Out, "%s #%s %s %s\n", t.WarningText("Gone"), item.Issue.Number, item.Issue.Title,
+				t.MutedText(fmt.Sprintf("(comment no longer in %s, close with: gh-issue-sync close %s)", ref.Path, item.Issue.Number)))
+			break
+		}
+	}
+
+	fmt.Fprintln(a.Out, t.MutedText(fmt.Sprintf("%d comments, %d created, %d updated, %d gone", len(seen), created, updated, gone)))
+	return nil
+}
+
+// moveCodeRef replaces the reference to the file of ref with ref. It
+// reports false if the issue already points at the same line.
+func moveCodeRef(iss *issue.Issue, ref codeRef) bool {
+	for i, raw := range iss.CodeRefs {
+		existing, err := parseCodeRef(raw)
+		if err != nil || existing.URL != "" || existing.Path != ref.Path {
+			continue
+		}
+		if existing.Line == ref.Line && existing.EndLine == 0 {
+			return false
+		}
+		iss.CodeRefs[i] = ref.String()
+		return true
+	}
+	iss.CodeRefs = append(iss.CodeRefs, ref.String())
+	return true
+}
+
+// findTodoComments returns the TODO and FIXME comments below top, sorted
+// by path and line. In a git checkout only tracked files are scanned.
+func (a *App) findTodoComments(ctx context.Context, issuesDir, top string, inGit bool) ([]todoComment, error) {
+	var files []string
+	if inGit {
+		out, err := execCommand(ctx, "git", "-C", top, "ls-files", "-z")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		for _, name := range strings.Split(out, "\x00") {
+			if name != "" {
+				files = append(files, name)
+			}
+		}
+	} else {
+		err := filepath.WalkDir(top, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != top && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(top, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var comments []todoComment
+	for _, name := range files {
+		path := filepath.Join(top, filepath.FromSlash(name))
+		if rel, err := filepath.Rel(issuesDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			continue
+		}
+		found, err := scanTodoFile(path, name)
+		if err != nil {
+			fmt.Fprintf(a.Err, "%s %s: %v\n", a.Theme.WarningText("Warning:"), name, err)
+			continue
+		}
+		comments = append(comments, found...)
+	}
+	return comments, nil
+}
+
+// scanTodoFile returns the TODO and FIXME comments of one file. Binary and
+// oversized files are skipped.
+func scanTodoFile(path, name string) ([]todoComment, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxScanFileSize {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) != -1 {
+		return nil, nil
+	}
+	var comments []todoComment
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScanFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		match := todoCommentPattern.FindStringSubmatch(scanner.Text())
+		if match == nil || match[1] == "" {
+			continue
+		}
+		comments = append(comments, todoComment{Text: match[1], Path: name, Line: line})
+	}
+	return comments, scanner.Err()
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestScanTodos(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	previous := execCommand
+	execCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		return "", errors.New("not a git repository")
+	}
+	t.Cleanup(func() { execCommand = previous })
+
+	source := filepath.Join(root, "src", "client.go")
+	if err := os.MkdirAll(filepath.Dir(source), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	write(source, "package client\n\n// TODO(jane): retry on 503\nfunc Get() {}\n")
+	write(filepath.Join(root, "deploy.sh"), "#!/bin/sh\n# FIXME handle missing env\n# TODO\n")
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	scan := func() {
+		t.Helper()
+		out.Reset()
+		if err := application.ScanTodos(context.Background(), ScanTodosOptions{}); err != nil {
+			t.Fatalf("scan-todos: %v", err)
+		}
+	}
+
+	scan()
+	drafts := loadDraftIssues(p).Issues
+	refs := map[string][]string{}
+	for _, item := range drafts {
+		if !reflect.DeepEqual(item.Issue.Labels, []string{config.DefaultTodoLabel}) {
+			t.Fatalf("unexpected labels: %+v", item.Issue)
+		}
+		refs[item.Issue.Title] = item.Issue.CodeRefs
+	}
+	want := map[string][]string{
+		"retry on 503":       {"src/client.go:3"},
+		"handle missing env": {"deploy.sh:2"},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Fatalf("code refs = %v, want %v", refs, want)
+	}
+
+	// Moving a comment updates the reference instead of creating a draft
+	write(source, "package client\n\nimport \"net/http\"\n\n// TODO(jane): retry on 503\nfunc Get() {}\n")
+	scan()
+	if !strings.Contains(out.String(), "0 created, 1 updated, 0 gone") {
+		t.Fatalf("expected one update:\n%s", out.String())
+	}
+
+	// A removed comment is reported, but the issue stays open
+	write(source, "package client\n")
+	scan()
+	if !strings.Contains(out.String(), "Gone") || !strings.Contains(out.String(), "comment no longer in src/client.go") {
+		t.Fatalf("expected the removed comment to be reported:\n%s", out.String())
+	}
+	if got := len(loadDraftIssues(p).Issues); got != 2 {
+		t.Fatalf("expected 2 drafts to remain, got %d", got)
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/search"
+)
+
+// slaWarning is the part of an SLA window left when a breach counts as
+// imminent.
+const slaWarning = 4 // a quarter of the window
+
+// slaIssue is an open issue still waiting for the response an SLA rule
+// requires.
+type slaIssue struct {
+	Item     IssueFile
+	Rule     config.SLARule
+	Deadline time.Time
+	Breached bool
+}
+
+// slaIssues returns the unanswered open issues whose SLA deadline passed or
+// is within the last quarter of its window, earliest deadline first. An
+// issue matching several rules is reported for the earliest deadline.
+// Issues without a created_at (never pulled) are ignored.
+func slaIssues(p paths.Paths, cfg config.Config, now time.Time) ([]slaIssue, error) {
+	if len(cfg.SLA) == 0 {
+		return nil, nil
+	}
+	windows := make([]time.Duration, len(cfg.SLA))
+	queries := make([]search.Query, len(cfg.SLA))
+	for i, rule := range cfg.SLA {
+		window, err := rule.Window()
+		if err != nil {
+			return nil, err
+		}
+		windows[i] = window
+		queries[i] = search.Parse(rule.Query)
+		if err := queries[i].Err; err != nil {
+			return nil, fmt.Errorf("SLA query %q: %w", rule.Query, err)
+		}
+	}
+
+	var result []slaIssue
+	walkLocalIssues(p, false, func(item IssueFile) {
+		iss := item.Issue
+		if iss.State != "open" || iss.CreatedAt == nil || iss.CommentCount > 0 || iss.Number.IsLocal() {
+			return
+		}
+		data := searchDataFor(p.Format, item)
+		var found *slaIssue
+		for i, rule := range cfg.SLA {
+			if !queries[i].Match(data) {
+				continue
+			}
+			deadline := iss.CreatedAt.Add(windows[i])
+			if deadline.Sub(now) > windows[i]/slaWarning {
+				continue
+			}
+			if found == nil || deadline.Before(found.Deadline) {
+				found = &slaIssue{Item: item, Rule: rule, Deadline: deadline, Breached: !now.Before(deadline)}
+			}
+		}
+		if found != nil {
+			result = append(result, *found)
+		}
+	}, func(ParseError) {})
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Deadline.Before(result[j].Deadline)
+	})
+	return result, nil
+}
+
+// formatSLA describes how far an issue is from or past its deadline.
+func (a *App) formatSLA(entry slaIssue, now time.Time) string {
+	t := a.Theme
+	if entry.Breached {
+		return t.ErrorText("breached " + formatRelativeTime(now, entry.Deadline))
+	}
+	return t.WarningText("due in " + slaRemaining(entry, now))
+}
+
+// slaRemaining is the time left until the deadline, e.g. "3 hours".
+func slaRemaining(entry slaIssue, now time.Time) string {
+	remaining := strings.TrimSuffix(formatRelativeTime(now, entry.Deadline), " ago")
+	if remaining == "just now" {
+		return "less than a minute"
+	}
+	return remaining
+}
+
+// printSLA lists the issues breaching or about to breach an SLA rule.
+func (a *App) printSLA(p paths.Paths, cfg config.Config) error {
+	now := a.Now()
+	entries, err := slaIssues(p, cfg, now)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	t := a.Theme
+	fmt.Fprintln(a.Out)
+	fmt.Fprintln(a.Out, t.Bold("SLA:"))
+	for _, entry := range entries {
+		fmt.Fprintln(a.Out, t.FormatIssueHeader("!", entry.Item.Issue.Number.String(), entry.Item.Issue.Title))
+		fmt.Fprintf(a.Out, "    %s %s\n", a.formatSLA(entry, now), t.MutedText(fmt.Sprintf("(%s within %s)", entry.Rule.Query, entry.Rule.RespondWithin)))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestSLA(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.SLA = []config.SLARule{
+		{Query: "label:incident", RespondWithin: "24h"},
+		{Query: "label:security", RespondWithin: "2d"},
+	}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		created := now.Add(-d)
+		return &created
+	}
+	issues := []issue.Issue{
+		{Number: "1", Title: "Outage", State: "open", Labels: []string{"incident"}, CreatedAt: ago(30 * time.Hour)},
+		{Number: "2", Title: "Slow logins", State: "open", Labels: []string{"incident"}, CreatedAt: ago(20 * time.Hour)},
+		{Number: "3", Title: "Fresh", State: "open", Labels: []string{"incident"}, CreatedAt: ago(time.Hour)},
+		{Number: "4", Title: "Answered", State: "open", Labels: []string{"incident"}, CreatedAt: ago(30 * time.Hour), CommentCount: 1},
+		{Number: "5", Title: "Closed", State: "closed", Labels: []string{"incident"}, CreatedAt: ago(30 * time.Hour)},
+		{Number: "6", Title: "Leaked key", State: "open", Labels: []string{"security"}, CreatedAt: ago(30 * time.Hour)},
+	}
+	for _, iss := range issues {
+		dir := p.OpenDir
+		if iss.State == "closed" {
+			dir = p.ClosedDir
+		}
+		if err := issue.WriteFile(issue.PathFor(dir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, &out)
+	application.Theme = theme.Plain()
+	application.Now = func() time.Time { return now }
+	if err := application.Status(context.Background(), StatusOptions{}); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	// Without originals every issue also shows up as modified
+	_, output, ok := strings.Cut(out.String(), "SLA:")
+	if !ok {
+		t.Fatalf("expected an SLA section:\n%s", out.String())
+	}
+	for _, want := range []string{
+		"#1: Outage",
+		"breached 6 hours ago (label:incident within 24h)",
+		"#2: Slow logins",
+		"due in 4 hours (label:incident within 24h)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"Fresh", "Answered", "Closed", "Leaked key"} {
+		if strings.Contains(output, unwanted) {
+			t.Fatalf("did not expect %q in output:\n%s", unwanted, output)
+		}
+	}
+	if strings.Index(output, "#1: Outage") > strings.Index(output, "#2: Slow logins") {
+		t.Fatalf("expected earliest deadline first:\n%s", output)
+	}
+
+	var notified []string
+	oldExec := execCommandEnv
+	execCommandEnv = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		notified = append(notified, strings.Join(args, " "))
+		return "", nil
+	}
+	t.Cleanup(func() { execCommandEnv = oldExec })
+	warned := map[string]bool{}
+	loaded, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	application.notifySLA(context.Background(), p, loaded, warned)
+	application.notifySLA(context.Background(), p, loaded, warned)
+	if len(notified) != 1 || !strings.Contains(notified[0], "#2 Slow logins") || !strings.Contains(notified[0], "SLA due in 4 hours") {
+		t.Fatalf("unexpected notifications: %q", notified)
+	}
+}
+
+func TestSLARuleWindow(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"24h": 24 * time.Hour,
+		"90m": 90 * time.Minute,
+		"3d":  72 * time.Hour,
+	} {
+		got, err := config.SLARule{RespondWithin: value}.Window()
+		if err != nil || got != want {
+			t.Fatalf("Window(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "soon", "-1h", "1.5d"} {
+		if _, err := (config.SLARule{RespondWithin: value}).Window(); err == nil {
+			t.Fatalf("expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// snapshotTimeFormat names snapshot files after the remote update time of
+// the version they hold.
+const snapshotTimeFormat = "20060102T150405Z"
+
+// snapshot is a past remote version of an issue kept by pull.
+type snapshot struct {
+	At   time.Time
+	Path string
+}
+
+func snapshotDir(p paths.Paths, number string) string {
+	return filepath.Join(p.SnapshotsDir, number)
+}
+
+// saveSnapshot stores the remote version iss and deletes all but the keep
+// newest snapshots of the issue.
+func saveSnapshot(p paths.Paths, iss issue.Issue, keep int) error {
+	number := iss.Number.String()
+	at := iss.UpdatedAt
+	if at == nil {
+		at = iss.SyncedAt
+	}
+	if at == nil {
+		return nil
+	}
+	dir := snapshotDir(p, number)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, at.UTC().Format(snapshotTimeFormat)+".md")
+	if err := issue.WriteFile(path, remoteOnly(iss)); err != nil {
+		return err
+	}
+	snapshots, err := listSnapshots(p, number)
+	if err != nil {
+		return err
+	}
+	for len(snapshots) > keep {
+		if err := os.Remove(snapshots[0].Path); err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// listSnapshots returns the snapshots of an issue, oldest first.
+func listSnapshots(p paths.Paths, number string) ([]snapshot, error) {
+	entries, err := os.ReadDir(snapshotDir(p, number))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapshots []snapshot
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".md")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		at, err := time.Parse(snapshotTimeFormat, name)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot{At: at, Path: filepath.Join(snapshotDir(p, number), entry.Name())})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].At.Before(snapshots[j].At) })
+	return snapshots, nil
+}
+
+// snapshotAt returns the newest snapshot of an issue taken at or before the
+// given time: the remote version as it was then.
+func snapshotAt(p paths.Paths, number string, at time.Time) (issue.Issue, snapshot, error) {
+	snapshots, err := listSnapshots(p, number)
+	if err != nil {
+		return issue.Issue{}, snapshot{}, err
+	}
+	if len(snapshots) == 0 {
+		return issue.Issue{}, snapshot{}, fmt.Errorf("no snapshots of #%s (set sync.snapshots in .issues/.sync/config.json and pull)", number)
+	}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].At.After(at) {
+			continue
+		}
+		parsed, err := issue.ParseFile(snapshots[i].Path)
+		if err != nil {
+			return issue.Issue{}, snapshot{}, err
+		}
+		return parsed, snapshots[i], nil
+	}
+	return issue.Issue{}, snapshot{}, fmt.Errorf("no snapshot of #%s at or before %s (the oldest is from %s)",
+		number, at.Format(time.RFC3339), snapshots[0].At.Format(time.RFC3339))
+}
+
+// parseSnapshotTime parses the argument of diff --at: a date, which stands
+// for the end of that day, a snapshot name as listed by --snapshots, or an
+// RFC 3339 time.
+func parseSnapshotTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if day, err := time.Parse("2006-01-02", value); err == nil {
+		return day.Add(24*time.Hour - time.Second), nil
+	}
+	for _, layout := range []string{snapshotTimeFormat, time.RFC3339} {
+		if at, err := time.Parse(layout, value); err == nil {
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD or a snapshot from --snapshots)", value)
+}
+
+// Snapshots lists the kept remote versions of an issue, newest first.
+func (a *App) Snapshots(number string) error {
+	p := a.issuePaths()
+	t := a.Theme
+	file, err := findIssueByNumber(p, number)
+	if err != nil {
+		return err
+	}
+	number = file.Issue.Number.String()
+	snapshots, err := listSnapshots(p, number)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText(fmt.Sprintf("No snapshots of #%s", number)))
+		return nil
+	}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		parsed, err := issue.ParseFile(snapshots[i].Path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.Out, "%s  %s  %s\n", snapshots[i].At.Format(snapshotTimeFormat),
+			t.MutedText(padRight(formatRelativeTime(a.Now(), snapshots[i].At), 16)), parsed.Title)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestDiffAtSnapshot(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	// Three pulled versions, of which the two newest are kept
+	versions := []struct {
+		updated time.Time
+		body    string
+	}{
+		{time.Date(2024, 4, 20, 9, 0, 0, 0, time.UTC), "First draft"},
+		{time.Date(2024, 5, 1, 17, 30, 0, 0, time.UTC), "Steps to reproduce"},
+		{time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC), "Steps to reproduce and a workaround"},
+	}
+	for _, v := range versions {
+		updated := v.updated
+		remote := issue.Issue{Number: "12", Title: "Crash", State: "open", Body: v.body, UpdatedAt: &updated,
+			CodeRefs: []string{"main.go:1"}}
+		if err := saveSnapshot(p, remote, 2); err != nil {
+			t.Fatalf("snapshot: %v", err)
+		}
+	}
+	snapshots, err := listSnapshots(p, "12")
+	if err != nil || len(snapshots) != 2 || !snapshots[0].At.Equal(versions[1].updated) {
+		t.Fatalf("expected the two newest snapshots, got %v %v", snapshots, err)
+	}
+	if kept, err := issue.ParseFile(snapshots[0].Path); err != nil || len(kept.CodeRefs) != 0 {
+		t.Fatalf("expected a snapshot without local fields, got %+v %v", kept, err)
+	}
+
+	local := issue.Issue{Number: "12", Title: "Crash", State: "open", Body: "Steps to reproduce and a fix"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, local.Number, local.Title), local); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	application.Now = func() time.Time { return time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	// A date means the end of that day, so the version updated on May 1st
+	if err := application.Diff(ctx, "12", DiffOptions{At: "2024-05-01"}); err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if !strings.Contains(out.String(), "body: changed (18 chars -> 28 chars)") {
+		t.Fatalf("expected a body diff against the May snapshot, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "workaround") {
+		t.Fatalf("expected the June snapshot not to be used, got:\n%s", out.String())
+	}
+	if err := application.Diff(ctx, "12", DiffOptions{At: "2024-04-30"}); err == nil || !strings.Contains(err.Error(), "oldest") {
+		t.Fatalf("expected no snapshot before the oldest, got %v", err)
+	}
+	if err := application.Diff(ctx, "12", DiffOptions{At: "yesterday"}); err == nil {
+		t.Fatal("expected an invalid time to fail")
+	}
+
+	out.Reset()
+	if err := application.Snapshots("12"); err != nil {
+		t.Fatalf("snapshots: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "20240602T080000Z") || !strings.HasPrefix(lines[1], "20240501T173000Z") {
+		t.Fatalf("expected snapshots newest first, got:\n%s", out.String())
+	}
+}
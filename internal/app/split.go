@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+type SplitOptions struct {
+	Replace bool // Replace the task items with references to the new issues
+	DryRun  bool
+}
+
+// Split turns the unchecked task-list items of an issue into local child
+// issues. The children get the issue as parent and inherit its labels.
+func (a *App) Split(ctx context.Context, number string, opts SplitOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	file, err := findIssueByNumber(p, number)
+	if err != nil {
+		return err
+	}
+
+	var tasks []issue.Task
+	for _, task := range issue.ParseTasks(file.Issue.Body) {
+		if task.Checked || task.IsIssueReference() {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No open task-list items to split"))
+		return nil
+	}
+
+	parent := issue.IssueRef(file.Issue.Number.String())
+	replacements := make(map[int]string, len(tasks))
+	editor := a.localEditor(ctx)
+	for _, task := range tasks {
+		if opts.DryRun {
+			fmt.Fprintf(a.Out, "%s %s\n", t.MutedText("Would create issue"), task.Text)
+			continue
+		}
+		localNumber, err := a.newLocalNumber(p)
+		if err != nil {
+			return err
+		}
+		child := issue.Issue{
+			Number:          localNumber,
+			Title:           task.Text,
+			Labels:          append([]string(nil), file.Issue.Labels...),
+			State:           "open",
+			Parent:          &parent,
+			LastLocalEditBy: editor,
+		}
+		path := issue.PathFor(issueDir(p, "open", child), child.Number, child.Title)
+		if err := writeIssue(p, path, child); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.Out, "%s %s\n", t.SuccessText("Created"), relPath(a.Root, path))
+		replacements[task.Line] = task.Prefix + "#" + child.Number.String()
+	}
+
+	if opts.Replace && len(replacements) > 0 {
+		file.Issue.Body = issue.ReplaceTaskLines(file.Issue.Body, replacements)
+		file.Issue.LastLocalEditBy = editor
+		if err := writeIssue(p, file.Path, file.Issue); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.Out, "%s %s\n", t.SuccessText("Updated"), relPath(a.Root, file.Path))
+	}
+	return nil
+}
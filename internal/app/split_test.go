@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestSplit(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	epic := issue.Issue{
+		Number: "10",
+		Title:  "Epic",
+		State:  "open",
+		Labels: []string{"area:auth"},
+		Body:   "Plan:\n- [ ] Add login form\n- [x] Write spec\n- [ ] #12\n",
+	}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, epic.Number, epic.Title), epic); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+	if err := application.Split(context.Background(), "10", SplitOptions{Replace: true}); err != nil {
+		t.Fatalf("split: %v", err)
+	}
+
+	issues, err := loadLocalIssues(p)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var child *issue.Issue
+	for i := range issues {
+		if issues[i].Issue.Number.IsLocal() {
+			if child != nil {
+				t.Fatalf("expected exactly one child issue")
+			}
+			child = &issues[i].Issue
+		}
+	}
+	if child == nil {
+		t.Fatalf("expected a child issue to be created")
+	}
+	if child.Title != "Add login form" || child.Parent == nil || child.Parent.String() != "10" {
+		t.Fatalf("unexpected child: %+v", child)
+	}
+	if len(child.Labels) != 1 || child.Labels[0] != "area:auth" {
+		t.Fatalf("expected inherited labels, got %v", child.Labels)
+	}
+
+	updated, err := findIssueByNumber(p, "10")
+	if err != nil {
+		t.Fatalf("find epic: %v", err)
+	}
+	if !strings.Contains(updated.Issue.Body, "- [ ] #"+child.Number.String()+"\n- [x] Write spec") {
+		t.Fatalf("expected task to be replaced by reference, got %q", updated.Issue.Body)
+	}
+}
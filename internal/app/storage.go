@@ -1,17 +1,24 @@
 package app
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/lock"
 	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/store"
 )
 
 type IssueFile struct {
@@ -40,6 +47,7 @@ type MilestoneCache struct {
 
 // MilestoneEntry represents a single milestone
 type MilestoneEntry struct {
+	Number      int     `json:"number,omitempty"`
 	Title       string  `json:"title"`
 	Description string  `json:"description,omitempty"`
 	DueOn       *string `json:"due_on,omitempty"`
@@ -67,8 +75,9 @@ type ProjectCache struct {
 
 // ProjectEntry represents a single project
 type ProjectEntry struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
+	ID     string `json:"id"`
+	Number int    `json:"number,omitempty"`
+	Title  string `json:"title"`
 }
 
 // ParseError represents an error parsing a specific issue file
@@ -87,6 +96,406 @@ type LoadResult struct {
 	Errors []ParseError
 }
 
+// storeSet holds the open issue store of each issues directory an App
+// works on, so every step of a command sees the same pending writes.
+type storeSet struct {
+	mu   sync.Mutex
+	open map[string]store.Store
+}
+
+func newStoreSet() *storeSet {
+	return &storeSet{open: map[string]store.Store{}}
+}
+
+// get returns the store of p, opening it with storage on first use.
+func (s *storeSet) get(p paths.Paths, storage config.StorageConfig) (store.Store, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.open[p.IssuesDir]; ok {
+		return st, nil
+	}
+	st, err := openStore(context.Background(), p, storage)
+	if err != nil {
+		return nil, err
+	}
+	s.open[p.IssuesDir] = st
+	return st, nil
+}
+
+// commit commits the pending writes of the store of issuesDir, if it is
+// open, and closes it, so the next use reads what other processes
+// committed since. A store that fails to commit stays open.
+func (s *storeSet) commit(ctx context.Context, issuesDir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.open[issuesDir]
+	if !ok {
+		return nil
+	}
+	if err := st.Commit(ctx); err != nil {
+		return fmt.Errorf("saving issues in %s: %w", issuesDir, err)
+	}
+	delete(s.open, issuesDir)
+	return nil
+}
+
+// commitAll commits and closes every open store.
+func (s *storeSet) commitAll(ctx context.Context) error {
+	s.mu.Lock()
+	dirs := make([]string, 0, len(s.open))
+	for dir := range s.open {
+		dirs = append(dirs, dir)
+	}
+	s.mu.Unlock()
+	sort.Strings(dirs)
+	var errs []error
+	for _, dir := range dirs {
+		if err := s.commit(ctx, dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withStore returns p reading and writing its issue files through the
+// store its storage config selects, opened by the App on first use.
+func (a *App) withStore(p paths.Paths, storage config.StorageConfig) paths.Paths {
+	p.OpenStore = func() (store.Store, error) {
+		return a.stores.get(p, storage)
+	}
+	p.HashedOriginals = storage.Originals == config.OriginalsHashed
+	return p
+}
+
+// storeFor returns the store holding the issue files of p. Paths the App
+// did not set up, e.g. before init, use the plain directory store.
+func storeFor(p paths.Paths) (store.Store, error) {
+	if p.OpenStore == nil {
+		return store.NewDir(p.IssuesDir), nil
+	}
+	return p.OpenStore()
+}
+
+func openStore(ctx context.Context, p paths.Paths, storage config.StorageConfig) (store.Store, error) {
+	switch storage.Backend {
+	case store.BackendDir:
+		return store.NewDir(p.IssuesDir), nil
+	case store.BackendSQLite:
+		path := storage.Path
+		if path == "" {
+			path = "issues.db"
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(p.IssuesDir, path)
+		}
+		return store.OpenSQLite(ctx, path, pipeCommand)
+	case store.BackendGit:
+		branch := storage.Branch
+		if branch == "" {
+			branch = "issues"
+		}
+		repo := paths.FindGitRoot(p.IssuesDir)
+		if repo == "" {
+			return nil, fmt.Errorf("the git storage backend needs %s to be inside a git repository", p.IssuesDir)
+		}
+		return store.OpenGitBranch(ctx, repo, branch, pipeCommand)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q in config", storage.Backend)
+}
+
+// Flush commits the pending writes of every open issue store and updates
+// the link indexes of the issues directories that changed. It is called
+// once a command finished; the commands that take the sync lock commit
+// their writes before releasing it, see lockMirror.
+func (a *App) Flush(ctx context.Context) error {
+	// Before committing the stores, which updating the indexes reads
+	a.refreshLinkIndexes()
+	var errs []error
+	if err := a.stores.commitAll(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := saveIndexes(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// mirrorLock is the sync lock of an issues directory held by a command.
+type mirrorLock struct {
+	app *App
+	p   paths.Paths
+	lck *lock.Lock
+}
+
+// lockMirror takes the sync lock of p. The issue store is committed and
+// reopened first, so the command sees what other processes committed
+// while it waited.
+func (a *App) lockMirror(p paths.Paths) (*mirrorLock, error) {
+	lck, err := lock.Acquire(p.SyncDir, lock.DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.stores.commit(context.Background(), p.IssuesDir); err != nil {
+		lck.Release()
+		return nil, err
+	}
+	return &mirrorLock{app: a, p: p, lck: lck}, nil
+}
+
+// Release commits the writes made under the lock and releases it, so
+// another process never interleaves its writes with them. The commit
+// outlives an interrupt of the command; if it fails, Flush tries again.
+func (l *mirrorLock) Release() {
+	if err := l.app.stores.commit(context.Background(), l.p.IssuesDir); err != nil {
+		fmt.Fprintf(l.app.Err, "%s %v\n", l.app.Theme.WarningText("Warning:"), err)
+	}
+	l.lck.Release()
+}
+
+// storeName maps a path in the issues directory to its name in the store.
+func storeName(p paths.Paths, path string) (string, error) {
+	rel, err := filepath.Rel(p.IssuesDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of %s", path, p.IssuesDir)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// readIssueData returns the raw content of the issue file at path. Paths
+// outside of the issues directory are read from disk.
+func readIssueData(p paths.Paths, path string) ([]byte, error) {
+	name, err := storeName(p, path)
+	if err != nil {
+		return os.ReadFile(path)
+	}
+	s, err := storeFor(p)
+	if err != nil {
+		return nil, err
+	}
+	return s.ReadFile(name)
+}
+
+// readIssue parses the issue file at path from the store. Plain files go
+// through the issue index.
+func readIssue(p paths.Paths, path string) (issue.Issue, error) {
+	name, err := storeName(p, path)
+	if err != nil {
+		return p.Format.ParseFile(path)
+	}
+	s, err := storeFor(p)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	if _, ok := s.(*store.Dir); ok {
+		return indexedIssue(p, name, path)
+	}
+	data, err := s.ReadFile(name)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	return p.Format.ParseNamed(path, data)
+}
+
+// readIssueHeader is readIssue without the body, see issue.ParseHeader.
+func readIssueHeader(p paths.Paths, path string) (issue.Issue, error) {
+	name, err := storeName(p, path)
+	if err != nil {
+		return p.Format.ReadHeaderFile(path)
+	}
+	s, err := storeFor(p)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	if _, ok := s.(*store.Dir); ok {
+		return indexedHeader(p, name, path)
+	}
+	data, err := s.ReadFile(name)
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	return p.Format.ParseHeaderNamed(path, bytes.NewReader(data))
+}
+
+// writeIssue renders an issue to path in the store.
+func writeIssue(p paths.Paths, path string, iss issue.Issue) error {
+	s, err := storeFor(p)
+	if err != nil {
+		return err
+	}
+	name, err := storeName(p, path)
+	if err != nil {
+		return err
+	}
+	content, err := p.Format.Render(iss)
+	if err != nil {
+		return err
+	}
+	if err := s.WriteFile(name, []byte(content)); err != nil {
+		return err
+	}
+	markIssuesChanged(p)
+	if _, ok := s.(*store.Dir); ok {
+		// Index what was written so the next read doesn't parse it again
+		info, statErr := os.Stat(path)
+		parsed, parseErr := p.Format.ParseNamed(path, []byte(content))
+		if statErr == nil && parseErr == nil {
+			updateIndex(p, name, info, parsed)
+		}
+	}
+	return nil
+}
+
+// moveIssue renames an issue file in the store.
+func moveIssue(p paths.Paths, oldPath, newPath string) error {
+	if oldPath == newPath {
+		return nil
+	}
+	s, err := storeFor(p)
+	if err != nil {
+		return err
+	}
+	oldName, err := storeName(p, oldPath)
+	if err != nil {
+		return err
+	}
+	newName, err := storeName(p, newPath)
+	if err != nil {
+		return err
+	}
+	markIssuesChanged(p)
+	if _, ok := s.(*store.Dir); ok {
+		forgetIndexed(p, oldName)
+		// The directory of a view is only created once it holds an issue
+		if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
+			return err
+		}
+		return os.Rename(oldPath, newPath)
+	}
+	data, err := s.ReadFile(oldName)
+	if err != nil {
+		return err
+	}
+	if err := s.WriteFile(newName, data); err != nil {
+		return err
+	}
+	return s.Remove(oldName)
+}
+
+// touchIssue sets the modification time of the issue file at path to when
+// iss was last updated, see config.SyncConfig.Mtimes. Stores other than
+// the plain directory keep no file times.
+func touchIssue(p paths.Paths, path string, iss issue.Issue) error {
+	if iss.UpdatedAt == nil {
+		return nil
+	}
+	s, err := storeFor(p)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.(*store.Dir); !ok {
+		return nil
+	}
+	name, err := storeName(p, path)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.ModTime().Equal(*iss.UpdatedAt) {
+		return nil
+	}
+	if err := os.Chtimes(path, time.Time{}, *iss.UpdatedAt); err != nil {
+		return err
+	}
+	// The index knows the file by its old time
+	forgetIndexed(p, name)
+	return nil
+}
+
+// removeIssue deletes an issue file from the store.
+func removeIssue(p paths.Paths, path string) error {
+	s, err := storeFor(p)
+	if err != nil {
+		return err
+	}
+	name, err := storeName(p, path)
+	if err != nil {
+		return err
+	}
+	markIssuesChanged(p)
+	if _, ok := s.(*store.Dir); ok {
+		forgetIndexed(p, name)
+	}
+	return s.Remove(name)
+}
+
+// listIssueFiles returns the paths of the files directly in dir.
+func listIssueFiles(p paths.Paths, dir string) ([]string, error) {
+	s, err := storeFor(p)
+	if err != nil {
+		return nil, err
+	}
+	name, err := storeName(p, dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.List(name)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = append(result, filepath.Join(p.IssuesDir, filepath.FromSlash(name)))
+	}
+	return result, nil
+}
+
+// editIssue opens an issue file in the editor. Stores other than the plain
+// directory get a copy in p.RecoveryDir that is written back afterwards and
+// kept if the editor fails, see recoveryBufferFor.
+func editIssue(ctx context.Context, p paths.Paths, command, path string) error {
+	s, err := storeFor(p)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.(*store.Dir); ok {
+		return openEditor(ctx, p, command, path)
+	}
+	name, err := storeName(p, path)
+	if err != nil {
+		return err
+	}
+	buffer := recoveryBufferFor(p, path)
+	if _, err := os.Stat(buffer); errors.Is(err, os.ErrNotExist) {
+		data, err := s.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(p.RecoveryDir, 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(buffer, data, 0o644); err != nil {
+			return err
+		}
+	}
+	if err := openEditor(ctx, p, command, buffer); err != nil {
+		return keptBufferError(p, buffer, err)
+	}
+	edited, err := os.ReadFile(buffer)
+	if err != nil {
+		return err
+	}
+	if err := s.WriteFile(name, edited); err != nil {
+		return err
+	}
+	return os.Remove(buffer)
+}
+
+// recoveryBufferFor returns where the editor buffer of an issue file is kept
+// while it is edited. Issue files in the plain directory store are edited in
+// place and never have one.
+func recoveryBufferFor(p paths.Paths, path string) string {
+	return filepath.Join(p.RecoveryDir, filepath.Base(path))
+}
+
 func loadLocalIssues(p paths.Paths) ([]IssueFile, error) {
 	result := loadLocalIssuesWithErrors(p)
 	if len(result.Errors) > 0 {
@@ -97,39 +506,158 @@ func loadLocalIssues(p paths.Paths) ([]IssueFile, error) {
 
 func loadLocalIssuesWithErrors(p paths.Paths) LoadResult {
 	result := LoadResult{}
-	for _, dir := range []struct {
-		Path  string
-		State string
-	}{{p.OpenDir, "open"}, {p.ClosedDir, "closed"}} {
-		entries, err := os.ReadDir(dir.Path)
+	walkLocalIssues(p, false, func(item IssueFile) {
+		result.Issues = append(result.Issues, item)
+	}, func(err ParseError) {
+		result.Errors = append(result.Errors, err)
+	})
+	return result
+}
+
+// walkLocalIssues calls fn with every open and closed issue as soon as it
+// is parsed, so callers that keep only some of them don't hold the whole
+// mirror in memory. With headersOnly the bodies are neither read nor
+// kept; such issues must not be written back. A directory that can't be
+// read is reported to onError and ends the walk.
+func walkLocalIssues(p paths.Paths, headersOnly bool, fn func(IssueFile), onError func(ParseError)) {
+	dirs, err := issueDirs(p, p.StateDirs())
+	if err != nil {
+		onError(ParseError{Path: p.IssuesDir, Err: err})
+		return
+	}
+	for _, dir := range dirs {
+		if !walkIssueDir(p, dir.Path, dir.State, headersOnly, fn, onError) {
+			return
+		}
+	}
+}
+
+// issueDirs expands the nested directories among dirs into their
+// subdirectories, returning the directories that hold issue files.
+func issueDirs(p paths.Paths, dirs []paths.StateDir) ([]paths.StateDir, error) {
+	var result []paths.StateDir
+	for _, dir := range dirs {
+		if !dir.Nested {
+			result = append(result, dir)
+			continue
+		}
+		s, err := storeFor(p)
+		if err != nil {
+			return nil, err
+		}
+		name, err := storeName(p, dir.Path)
+		if err != nil {
+			return nil, err
+		}
+		subdirs, err := s.Dirs(name)
 		if err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				continue
-			}
-			// Directory read errors are fatal
-			result.Errors = append(result.Errors, ParseError{Path: dir.Path, Err: err})
-			return result
-		}
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-			if filepath.Ext(entry.Name()) != ".md" {
-				continue
-			}
-			// Skip comment files (e.g., 42.comment.md)
-			if strings.HasSuffix(entry.Name(), ".comment.md") {
-				continue
-			}
-			path := filepath.Join(dir.Path, entry.Name())
-			relPath := filepath.Join(filepath.Base(filepath.Dir(dir.Path)), filepath.Base(dir.Path), entry.Name())
-			parsed, err := issue.ParseFile(path)
-			if err != nil {
-				result.Errors = append(result.Errors, ParseError{Path: relPath, Err: err})
-				continue
-			}
-			parsed.State = dir.State
-			result.Issues = append(result.Issues, IssueFile{Issue: parsed, Path: path, State: dir.State})
+			return nil, err
+		}
+		for _, subdir := range subdirs {
+			result = append(result, paths.StateDir{Path: filepath.Join(p.IssuesDir, filepath.FromSlash(subdir)), State: dir.State})
+		}
+	}
+	return result, nil
+}
+
+// loadDraftIssues loads the notes-style issues in .issues/drafts. They are
+// never part of a pull or push.
+func loadDraftIssues(p paths.Paths) LoadResult {
+	result := LoadResult{}
+	loadIssueDir(&result, p, p.DraftsDir, "open")
+	for i := range result.Issues {
+		result.Issues[i].Issue.Draft = true
+	}
+	return result
+}
+
+// loadIssueDir appends the issues in dir to result. It returns false if the
+// directory could not be read, which callers treat as fatal.
+func loadIssueDir(result *LoadResult, p paths.Paths, dir, state string) bool {
+	return walkIssueDir(p, dir, state, false, func(item IssueFile) {
+		result.Issues = append(result.Issues, item)
+	}, func(err ParseError) {
+		result.Errors = append(result.Errors, err)
+	})
+}
+
+func walkIssueDir(p paths.Paths, dir, state string, headersOnly bool, fn func(IssueFile), onError func(ParseError)) bool {
+	read := readIssue
+	if headersOnly {
+		read = readIssueHeader
+	}
+	if inLinkIndex(p, dir) {
+		return true
+	}
+	files, err := listIssueFiles(p, dir)
+	if err != nil {
+		onError(ParseError{Path: dir, Err: err})
+		return false
+	}
+	var issuePaths []string
+	for _, path := range files {
+		if filepath.Ext(path) != ".md" {
+			continue
+		}
+		// Skip comment files (e.g., 42.comment.md)
+		if strings.HasSuffix(path, ".comment.md") {
+			continue
+		}
+		// Symlinks are links to issue files, such as those of an index
+		if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		issuePaths = append(issuePaths, path)
+	}
+
+	// Parse up to GOMAXPROCS files at a time. Results are handed out in
+	// file order, so errors are reported the same way on every run, and a
+	// worker only starts on a file once an earlier result was consumed.
+	type parseResult struct {
+		issue issue.Issue
+		err   error
+	}
+	results := make([]chan parseResult, len(issuePaths))
+	for i := range results {
+		results[i] = make(chan parseResult, 1)
+	}
+	slots := make(chan struct{}, runtime.GOMAXPROCS(0))
+	go func() {
+		for i, path := range issuePaths {
+			slots <- struct{}{}
+			go func() {
+				parsed, err := read(p, path)
+				results[i] <- parseResult{issue: parsed, err: err}
+			}()
+		}
+	}()
+
+	for i, path := range issuePaths {
+		result := <-results[i]
+		<-slots
+		if result.err != nil {
+			relPath := filepath.Join(filepath.Base(filepath.Dir(dir)), filepath.Base(dir), filepath.Base(path))
+			onError(ParseError{Path: relPath, Err: result.err})
+			continue
+		}
+		if state != "" {
+			result.issue.State = state
+		} else if result.issue.State != "closed" {
+			// The front matter records the state in the flat and
+			// milestone layouts
+			result.issue.State = "open"
+		}
+		fn(IssueFile{Issue: result.issue, Path: path, State: result.issue.State})
+	}
+	return true
+}
+
+// withoutDrafts drops issues flagged with draft: true.
+func withoutDrafts(items []IssueFile) []IssueFile {
+	var result []IssueFile
+	for _, item := range items {
+		if !item.Issue.Draft {
+			result = append(result, item)
 		}
 	}
 	return result
@@ -145,6 +673,11 @@ func findIssueByNumber(p paths.Paths, number string) (IssueFile, error) {
 			return item, nil
 		}
 	}
+	for _, item := range loadDraftIssues(p).Issues {
+		if item.Issue.Number.String() == number {
+			return item, nil
+		}
+	}
 	return IssueFile{}, fmt.Errorf("issue %s not found", number)
 }
 
@@ -158,7 +691,7 @@ func findIssueByRef(root string, p paths.Paths, ref string) (IssueFile, error) {
 		if !filepath.IsAbs(path) {
 			path = filepath.Join(root, path)
 		}
-		parsed, err := issue.ParseFile(path)
+		parsed, err := readIssue(p, path)
 		if err != nil {
 			return IssueFile{}, fmt.Errorf("failed to parse %s: %w", ref, err)
 		}
@@ -168,6 +701,9 @@ func findIssueByRef(root string, p paths.Paths, ref string) (IssueFile, error) {
 			state = "closed"
 		}
 		parsed.State = state
+		if filepath.Dir(filepath.Clean(path)) == p.DraftsDir {
+			parsed.Draft = true
+		}
 		return IssueFile{Issue: parsed, Path: path, State: state}, nil
 	}
 
@@ -177,16 +713,46 @@ func findIssueByRef(root string, p paths.Paths, ref string) (IssueFile, error) {
 
 func readOriginalIssue(p paths.Paths, number string) (issue.Issue, bool) {
 	path := filepath.Join(p.OriginalsDir, fmt.Sprintf("%s.md", number))
-	parsed, err := issue.ParseFile(path)
+	parsed, err := readOriginalFile(p, path)
 	if err != nil {
 		return issue.Issue{}, false
 	}
 	return parsed, true
 }
 
+// readOriginalFile parses the original at path, loading its body from the
+// blob it references if it is hashed.
+func readOriginalFile(p paths.Paths, path string) (issue.Issue, error) {
+	parsed, err := readIssue(p, path)
+	if err != nil {
+		return parsed, err
+	}
+	return withBlobBody(p, parsed)
+}
+
 func writeOriginalIssue(p paths.Paths, item issue.Issue) error {
+	item = remoteOnly(item)
+	if originalsHashed(p) && item.Body != "" {
+		ref, err := writeBlob(p, item.Body)
+		if err != nil {
+			return err
+		}
+		item.Body = ref
+	}
 	path := filepath.Join(p.OriginalsDir, fmt.Sprintf("%s.md", item.Number))
-	return issue.WriteFile(path, item)
+	return writeIssue(p, path, item)
+}
+
+// remoteOnly strips what never leaves the local mirror from item: local
+// notes and the other local-only fields.
+func remoteOnly(item issue.Issue) issue.Issue {
+	item.Body = issue.PublicBody(item.Body)
+	item.CodeRefs = nil
+	item.Summary = ""
+	item.Fields = nil
+	item.LocalState = ""
+	item.LastLocalEditBy = ""
+	return item
 }
 
 func loadLabelCache(p paths.Paths) (LabelCache, error) {
@@ -0,0 +1,251 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestSQLiteStorageBackend(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Storage.Backend = "sqlite"
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	ctx := context.Background()
+	application := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+	p = application.issuePaths()
+	if err := application.NewIssue(ctx, "Crash on start", NewOptions{}); err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	local, err := loadLocalIssues(p)
+	if err != nil || len(local) != 1 {
+		t.Fatalf("expected one issue before flush, got %+v (%v)", local, err)
+	}
+	if err := application.Close(ctx, local[0].Issue.Number.String(), CloseOptions{}); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	// Writes under the sync lock are committed before it is released, so
+	// another process sees them right away
+	other := New(root, ghcli.ExecRunner{}, io.Discard, io.Discard)
+	if local, err := loadLocalIssues(other.issuePaths()); err != nil || len(local) != 1 || local[0].State != "closed" {
+		t.Fatalf("expected the close committed with the lock, got %+v (%v)", local, err)
+	}
+	if err := application.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	for _, dir := range []string{p.OpenDir, p.ClosedDir} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("read %s: %v", dir, err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("expected no files in %s, got %d", dir, len(entries))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(p.IssuesDir, "issues.db")); err != nil {
+		t.Fatalf("expected database: %v", err)
+	}
+
+	// A fresh load reads the issue back from the database
+	local, err = loadLocalIssues(application.issuePaths())
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if len(local) != 1 || local[0].State != "closed" || local[0].Issue.Title != "Crash on start" {
+		t.Fatalf("unexpected issues after reload: %+v", local)
+	}
+	if err := application.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+}
+
+func TestListReadsBodiesOnlyForOutput(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	for _, iss := range []issue.Issue{
+		{Number: "1", Title: "Epic", State: "open", Labels: []string{"bug"}, Body: "- [x] one\n- [ ] two\n"},
+		{Number: "2", Title: "Other", State: "open"},
+	} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	var headers []IssueFile
+	walkLocalIssues(p, true, func(item IssueFile) {
+		headers = append(headers, item)
+	}, func(err ParseError) {
+		t.Fatalf("walk: %v", err)
+	})
+	if len(headers) != 2 || headers[0].Issue.Number != "1" || headers[0].Issue.Body != "" || headers[0].Issue.Labels[0] != "bug" {
+		t.Fatalf("unexpected headers: %+v", headers)
+	}
+
+	var out bytes.Buffer
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	if err := application.List(context.Background(), ListOptions{Label: []string{"bug"}}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out.String(), "Epic") || strings.Contains(out.String(), "Other") {
+		t.Fatalf("unexpected list output: %q", out.String())
+	}
+	if !strings.Contains(out.String(), "tasks 1/2") {
+		t.Fatalf("expected task progress from the body: %q", out.String())
+	}
+}
+
+func TestLoadLocalIssuesReportsErrorsInOrder(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	var wantIssues, wantErrors []string
+	for i := 1; i <= 40; i++ {
+		number := issue.IssueNumber(fmt.Sprintf("%03d", i))
+		path := issue.PathFor(p.OpenDir, number, "Issue")
+		if i%3 == 0 {
+			if err := os.WriteFile(path, []byte("no front matter\n"), 0o644); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			wantErrors = append(wantErrors, filepath.Base(path))
+			continue
+		}
+		if err := issue.WriteFile(path, issue.Issue{Number: number, Title: "Issue", State: "open"}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		wantIssues = append(wantIssues, number.String())
+	}
+
+	for run := 0; run < 5; run++ {
+		result := loadLocalIssuesWithErrors(p)
+		var gotIssues, gotErrors []string
+		for _, item := range result.Issues {
+			gotIssues = append(gotIssues, item.Issue.Number.String())
+		}
+		for _, parseErr := range result.Errors {
+			gotErrors = append(gotErrors, filepath.Base(parseErr.Path))
+		}
+		if strings.Join(gotIssues, ",") != strings.Join(wantIssues, ",") {
+			t.Fatalf("run %d: issues %v, want %v", run, gotIssues, wantIssues)
+		}
+		if strings.Join(gotErrors, ",") != strings.Join(wantErrors, ",") {
+			t.Fatalf("run %d: errors %v, want %v", run, gotErrors, wantErrors)
+		}
+	}
+}
+
+func TestTouchIssue(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	updatedAt := time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC)
+	iss := issue.Issue{Number: "5", Title: "Slow start", State: "open", UpdatedAt: &updatedAt}
+	path := issue.PathFor(p.OpenDir, iss.Number, iss.Title)
+	if err := writeIssue(p, path, iss); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := touchIssue(p, path, iss); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.ModTime().Equal(updatedAt) {
+		t.Fatalf("expected the file time to be %v, got %v (%v)", updatedAt, info.ModTime(), err)
+	}
+	if read, err := readIssue(p, path); err != nil || read.Title != "Slow start" {
+		t.Fatalf("expected the issue to read back, got %+v (%v)", read, err)
+	}
+
+	// Without updated_at the time is left alone
+	iss.UpdatedAt = nil
+	if err := touchIssue(p, path, iss); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(updatedAt) {
+		t.Fatalf("expected the file time unchanged, got %v (%v)", info.ModTime(), err)
+	}
+}
+
+func TestIssueFormatIsPerRepository(t *testing.T) {
+	newRepo := func(prefix string) paths.Paths {
+		root := t.TempDir()
+		p := paths.New(root)
+		if err := p.EnsureLayout(); err != nil {
+			t.Fatalf("layout: %v", err)
+		}
+		cfg := config.Default("owner", "repo")
+		cfg.Priority.LabelPrefix = prefix
+		if err := config.Save(p.ConfigPath, cfg); err != nil {
+			t.Fatalf("config: %v", err)
+		}
+		return New(root, ghcli.ExecRunner{}, io.Discard, io.Discard).issuePaths()
+	}
+	// Mirrors pulled side by side, as in org mode, keep their own format
+	prioritized, plain := newRepo("priority/"), newRepo("")
+	iss := issue.Issue{Number: "1", Title: "Outage", State: "open", Labels: []string{"bug", "priority/P1"}}
+	done := make(chan error, 2)
+	for _, p := range []paths.Paths{prioritized, plain} {
+		go func() {
+			done <- writeIssue(p, issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss)
+		}()
+	}
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		p    paths.Paths
+		want string
+	}{
+		{prioritized, "labels:\n    - bug\npriority: P1\n"},
+		{plain, "labels:\n    - bug\n    - priority/P1\n"},
+	} {
+		data, err := os.ReadFile(issue.PathFor(tc.p.OpenDir, iss.Number, iss.Title))
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if !strings.Contains(string(data), tc.want) {
+			t.Fatalf("expected %q in:\n%s", tc.want, data)
+		}
+		parsed, err := readIssue(tc.p, issue.PathFor(tc.p.OpenDir, iss.Number, iss.Title))
+		if err != nil || strings.Join(parsed.Labels, ",") != "bug,priority/P1" {
+			t.Fatalf("expected the labels read back, got %v (%v)", parsed.Labels, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/shlex"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+type SummarizeOptions struct {
+	Clear bool // remove the stored summary
+}
+
+// Summarize sends the thread of an issue to the configured summarize
+// command and stores what it prints as info.summary, which view shows
+// first. The summary is local only and never pushed.
+func (a *App) Summarize(ctx context.Context, ref string, opts SummarizeOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	file, err := findIssueByRef(a.Root, p, ref)
+	if err != nil {
+		return err
+	}
+	number := file.Issue.Number.String()
+
+	summary := ""
+	comments := 0
+	if !opts.Clear {
+		command := strings.TrimSpace(cfg.Summarize.Command)
+		if command == "" {
+			return errors.New("no summarize command configured (set summarize.command in .issues/.sync/config.json)")
+		}
+		parts, err := shlex.Split(command)
+		if err != nil {
+			return fmt.Errorf("failed to parse summarize.command %q: %w", command, err)
+		}
+		cache, _ := loadCommentCache(p, number)
+		comments = len(cache.Comments)
+		out, err := pipeCommand(ctx, summaryInput(file.Issue, cache), parts[0], parts[1:]...)
+		if err != nil {
+			return fmt.Errorf("summarize command: %w", err)
+		}
+		summary = strings.TrimSpace(out)
+		if summary == "" {
+			return errors.New("summarize command printed nothing")
+		}
+	}
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+	current, err := readIssue(p, file.Path)
+	if err != nil {
+		return err
+	}
+	// Not a change to push, so the last local editor stays as it is
+	current.Summary = summary
+	if err := writeIssue(p, file.Path, current); err != nil {
+		return err
+	}
+	if opts.Clear {
+		fmt.Fprintf(a.Out, "%s summary of #%s\n", t.SuccessText("Cleared"), number)
+		return nil
+	}
+	fmt.Fprintf(a.Out, "%s #%s %s\n", t.SuccessText("Summarized"), number,
+		t.MutedText(fmt.Sprintf("(body and %d comment(s))", comments)))
+	return nil
+}
+
+// summaryInput renders the thread of iss as Markdown for the summarize
+// command: the title, the public body and the pulled comments.
+func summaryInput(iss issue.Issue, cache CommentCache) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", iss.Title)
+	if iss.Author != "" {
+		fmt.Fprintf(&b, "Opened by @%s\n\n", iss.Author)
+	}
+	if body := strings.TrimSpace(issue.PublicBody(iss.Body)); body != "" {
+		b.WriteString(body)
+		b.WriteString("\n\n")
+	}
+	for _, comment := range cache.Comments {
+		author := comment.Author
+		if author == "" {
+			author = "ghost"
+		}
+		header := "@" + author
+		if comment.CreatedAt != nil {
+			header += " on " + comment.CreatedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(&b, "## Comment by %s\n\n%s\n\n", header, strings.TrimSpace(comment.Body))
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
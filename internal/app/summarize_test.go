@@ -0,0 +1,95 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestSummarize(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Summarize.Command = "llm -s 'Summarize this issue'"
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "9", Title: "Login loops", State: "open", Author: "ana",
+		Body: "Login redirects forever.\n\n<!-- local-notes\nprivate\n-->"}
+	path := issue.PathFor(p.OpenDir, iss.Number, iss.Title)
+	if err := issue.WriteFile(path, iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	if err := saveCommentCache(p, "9", CommentCache{Comments: []ghcli.Comment{
+		{ID: "c1", Author: "bo", Body: "Only with SSO enabled."},
+	}}); err != nil {
+		t.Fatalf("comments: %v", err)
+	}
+
+	var input string
+	prev := pipeCommand
+	pipeCommand = func(ctx context.Context, in string, name string, args ...string) (string, error) {
+		if name != "llm" || strings.Join(args, "|") != "-s|Summarize this issue" {
+			t.Fatalf("unexpected command %s %v", name, args)
+		}
+		input = in
+		return "Redirect loop on login.\nOnly affects SSO.\n", nil
+	}
+	t.Cleanup(func() { pipeCommand = prev })
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	if err := application.Summarize(ctx, "9", SummarizeOptions{}); err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	want := "# Login loops\n\nOpened by @ana\n\nLogin redirects forever.\n\n## Comment by @bo\n\nOnly with SSO enabled.\n"
+	if input != want {
+		t.Fatalf("unexpected input:\n%q\nwant:\n%q", input, want)
+	}
+	updated, err := issue.ParseFile(path)
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	if updated.Summary != "Redirect loop on login.\nOnly affects SSO." || updated.LastLocalEditBy != "" {
+		t.Fatalf("expected the summary stored without a local edit, got %+v", updated)
+	}
+	if issue.PublicBody(updated.Body) != issue.PublicBody(iss.Body) {
+		t.Fatalf("expected the body unchanged, got %q", updated.Body)
+	}
+
+	out.Reset()
+	if err := application.View(ctx, "9", ViewOptions{}); err != nil {
+		t.Fatalf("view: %v", err)
+	}
+	if !strings.Contains(out.String(), "summary:\n\tRedirect loop on login.\n\tOnly affects SSO.\n") {
+		t.Fatalf("expected view to show the summary, got:\n%s", out.String())
+	}
+
+	// The summary never ends up in the originals push compares against
+	if err := writeOriginalIssue(p, updated); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+	if original, ok := readOriginalIssue(p, "9"); !ok || original.Summary != "" {
+		t.Fatalf("expected an original without summary, got %+v", original)
+	}
+
+	if err := application.Summarize(ctx, "9", SummarizeOptions{Clear: true}); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if cleared, err := issue.ParseFile(path); err != nil || cleared.Summary != "" {
+		t.Fatalf("expected the summary cleared, got %q %v", cleared.Summary, err)
+	}
+}
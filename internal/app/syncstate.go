@@ -0,0 +1,262 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/lock"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// syncGitignore keeps the per-machine state and the caches pull rebuilds
+// out of git, so only config.json, the per-issue originals and conflicts,
+// the queued label merges, recurring state and local ID history are
+// committed.
+var syncGitignore = strings.Join([]string{
+	"# Per-machine sync state and caches, see gh-issue-sync sync-state repair",
+	paths.StateFileName,
+	paths.AuditFileName,
+	lock.LockFileName,
+	paths.IndexFileName,
+	paths.LastPullFileName,
+	paths.CheckpointFileName,
+	paths.LabelsFileName,
+	paths.MilestonesFileName,
+	paths.IssueTypesFileName,
+	paths.ProjectsFileName,
+	paths.TeamsFileName,
+	paths.AutolinksFileName,
+	paths.TimelineDirName + "/",
+	paths.CommentsDirName + "/",
+	paths.SnapshotsDirName + "/",
+	paths.RecoveryDirName + "/",
+}, "\n") + "\n"
+
+// syncCaches are the files in .sync that can be deleted when broken; pull
+// writes them again.
+var syncCaches = []string{
+	paths.IndexFileName,
+	paths.LastPullFileName,
+	paths.LabelsFileName,
+	paths.MilestonesFileName,
+	paths.IssueTypesFileName,
+	paths.ProjectsFileName,
+	paths.TeamsFileName,
+	paths.AutolinksFileName,
+	paths.RepoFileName,
+}
+
+// writeSyncGitignore writes .sync/.gitignore and reports whether it
+// changed.
+func writeSyncGitignore(p paths.Paths) (bool, error) {
+	if data, err := os.ReadFile(p.GitignorePath); err == nil && string(data) == syncGitignore {
+		return false, nil
+	}
+	if err := os.MkdirAll(p.SyncDir, 0o755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(p.GitignorePath, []byte(syncGitignore), 0o644)
+}
+
+// RepairSyncState makes the sync state of a mirror committed to git
+// consistent again after a merge: it moves per-machine state out of
+// config.json, resolves git conflicts in originals by keeping the side
+// with the newer updated_at, and deletes conflicted caches. Conflicts in
+// other files are reported for resolving by hand.
+func (a *App) RepairSyncState(ctx context.Context) error {
+	p := a.issuePaths()
+	t := a.Theme
+	if data, err := os.ReadFile(p.ConfigPath); err == nil && hasGitConflict(data) {
+		return fmt.Errorf("%s has git conflict markers; resolve them by hand and run sync-state repair again", relPath(a.Root, p.ConfigPath))
+	}
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	repaired := 0
+	report := func(format string, args ...any) {
+		fmt.Fprintf(a.Out, "%s %s\n", t.SuccessText("Repaired"), fmt.Sprintf(format, args...))
+		repaired++
+	}
+
+	if changed, err := writeSyncGitignore(p); err != nil {
+		return err
+	} else if changed {
+		report("%s", relPath(a.Root, p.GitignorePath))
+	}
+	if legacy, err := hasLegacyState(p.ConfigPath); err == nil && legacy {
+		if err := config.Save(p.ConfigPath, cfg); err != nil {
+			return err
+		}
+		report("moved last_full_pull and capabilities to %s", relPath(a.Root, p.StatePath))
+	}
+
+	originals, err := listIssueFiles(p, p.OriginalsDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	refetch := false
+	for _, path := range originals {
+		data, err := readIssueData(p, path)
+		if err != nil || !hasGitConflict(data) {
+			continue
+		}
+		number := strings.TrimSuffix(filepath.Base(path), ".md")
+		if kept, ok := newerSide(p.Format, data); ok {
+			kept.Number = issue.IssueNumber(number)
+			if kept, err = withBlobBody(p, kept); err != nil {
+				return err
+			}
+			if err := writeOriginalIssue(p, kept); err != nil {
+				return err
+			}
+			report("original of #%s (kept the version updated %s)", number, kept.UpdatedAt.UTC().Format("2006-01-02 15:04"))
+			continue
+		}
+		if err := removeIssue(p, path); err != nil {
+			return err
+		}
+		refetch = true
+		report("original of #%s (removed, the next pull fetches it again)", number)
+	}
+
+	for _, name := range syncCaches {
+		path := filepath.Join(p.SyncDir, name)
+		if data, err := os.ReadFile(path); err == nil && hasGitConflict(data) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			report("%s (removed, the next pull writes it again)", relPath(a.Root, path))
+		}
+	}
+	if entries, err := os.ReadDir(p.TimelineDir); err == nil {
+		for _, entry := range entries {
+			path := filepath.Join(p.TimelineDir, entry.Name())
+			if data, err := os.ReadFile(path); err == nil && hasGitConflict(data) {
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+				report("%s (removed)", relPath(a.Root, path))
+			}
+		}
+	}
+
+	if refetch && cfg.Sync.LastFullPull != nil {
+		cfg.Sync.LastFullPull = nil
+		if err := config.Save(p.ConfigPath, cfg); err != nil {
+			return err
+		}
+	}
+
+	var manual []string
+	for _, path := range []string{p.LabelMergesPath, p.RecurringPath, p.LocalIDsPath} {
+		if data, err := os.ReadFile(path); err == nil && hasGitConflict(data) {
+			manual = append(manual, relPath(a.Root, path))
+		}
+	}
+	if entries, err := os.ReadDir(p.ConflictsDir); err == nil {
+		for _, entry := range entries {
+			path := filepath.Join(p.ConflictsDir, entry.Name())
+			if data, err := os.ReadFile(path); err == nil && hasGitConflict(data) {
+				manual = append(manual, relPath(a.Root, path))
+			}
+		}
+	}
+
+	// Files committed before the .gitignore existed keep being tracked
+	if out, err := a.Runner.Run(ctx, "git", "-C", a.Root, "ls-files", "--cached", "--ignored", "--exclude-standard", "--", p.SyncDir); err == nil {
+		if tracked := strings.Fields(out); len(tracked) > 0 {
+			fmt.Fprintf(a.Out, "%s %s\n", t.WarningText("Still tracked by git:"), strings.Join(tracked, ", "))
+			fmt.Fprintf(a.Out, "  %s\n", t.MutedText("untrack them with: git rm --cached "+strings.Join(tracked, " ")))
+		}
+	}
+
+	if len(manual) > 0 {
+		return fmt.Errorf("resolve the git conflicts in %s by hand", strings.Join(manual, ", "))
+	}
+	if repaired == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("Sync state is consistent"))
+	}
+	return nil
+}
+
+// hasLegacyState reports whether the config at path still carries the
+// state that now lives in state.json.
+func hasLegacyState(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Contains(data, []byte(`"last_full_pull"`)) || bytes.Contains(data, []byte(`"capabilities"`)), nil
+}
+
+// hasGitConflict reports whether data contains git's conflict markers.
+func hasGitConflict(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("<<<<<<< ")) || bytes.Contains(data, []byte("\n<<<<<<< "))
+}
+
+// gitConflictSides reconstructs both versions of a file with git conflict
+// markers. The merge base of diff3-style conflicts is dropped.
+func gitConflictSides(data []byte) (ours, theirs []byte) {
+	const (
+		common = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+	state := common
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		switch {
+		case bytes.HasPrefix(line, []byte("<<<<<<< ")):
+			state = inOurs
+			continue
+		case state == inOurs && bytes.HasPrefix(line, []byte("||||||| ")):
+			state = inBase
+			continue
+		case state != common && bytes.Equal(bytes.TrimRight(line, "\r\n"), []byte("=======")):
+			state = inTheirs
+			continue
+		case state == inTheirs && bytes.HasPrefix(line, []byte(">>>>>>> ")):
+			state = common
+			continue
+		}
+		if state == common || state == inOurs {
+			ours = append(ours, line...)
+		}
+		if state == common || state == inTheirs {
+			theirs = append(theirs, line...)
+		}
+	}
+	return ours, theirs
+}
+
+// newerSide parses both sides of a conflicted original and returns the one
+// with the later updated_at, which is the more recent remote state.
+func newerSide(format issue.Format, data []byte) (issue.Issue, bool) {
+	oursData, theirsData := gitConflictSides(data)
+	ours, oursErr := format.Parse(oursData)
+	theirs, theirsErr := format.Parse(theirsData)
+	switch {
+	case oursErr != nil || ours.UpdatedAt == nil:
+		return theirs, theirsErr == nil && theirs.UpdatedAt != nil
+	case theirsErr != nil || theirs.UpdatedAt == nil:
+		return ours, true
+	case theirs.UpdatedAt.After(*ours.UpdatedAt):
+		return theirs, true
+	}
+	return ours, true
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestConfigStateIsKeptApart(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	pulled := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	cfg.Sync.LastFullPull = &pulled
+	cfg.Capabilities = &config.Capabilities{Projects: true, ProbedAt: pulled}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	data, err := os.ReadFile(p.ConfigPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if strings.Contains(string(data), "last_full_pull") || strings.Contains(string(data), "capabilities") {
+		t.Fatalf("expected no per-machine state in config.json:\n%s", data)
+	}
+	loaded, err := config.Load(p.ConfigPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Sync.LastFullPull == nil || !loaded.Sync.LastFullPull.Equal(pulled) || loaded.Capabilities == nil || !loaded.Capabilities.Projects {
+		t.Fatalf("expected the state from state.json, got %+v", loaded)
+	}
+}
+
+func TestRepairSyncState(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	legacy := `{
+  "repository": {"owner": "owner", "repo": "repo"},
+  "sync": {"last_full_pull": "2026-10-16T09:00:00Z"}
+}
+`
+	if err := os.WriteFile(p.ConfigPath, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	older := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	ours, err := issue.Render(issue.Issue{Number: "3", Title: "Old title", State: "open", UpdatedAt: &older})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	theirs, err := issue.Render(issue.Issue{Number: "3", Title: "New title", State: "open", UpdatedAt: &newer})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	conflicted := "<<<<<<< HEAD\n" + ours + "=======\n" + theirs + ">>>>>>> origin/main\n"
+	originalPath := filepath.Join(p.OriginalsDir, "3.md")
+	if err := os.WriteFile(originalPath, []byte(conflicted), 0o644); err != nil {
+		t.Fatalf("original: %v", err)
+	}
+	if err := os.WriteFile(p.LabelsPath, []byte("<<<<<<< HEAD\n{}\n=======\n[]\n>>>>>>> other\n"), 0o644); err != nil {
+		t.Fatalf("labels: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, &out)
+	application.Theme = theme.Plain()
+	if err := application.RepairSyncState(context.Background()); err != nil {
+		t.Fatalf("repair: %v\n%s", err, out.String())
+	}
+
+	if data, err := os.ReadFile(p.GitignorePath); err != nil || !strings.Contains(string(data), "state.json") {
+		t.Fatalf("expected .sync/.gitignore, got %q (%v)", data, err)
+	}
+	if data, _ := os.ReadFile(p.ConfigPath); strings.Contains(string(data), "last_full_pull") {
+		t.Fatalf("expected last_full_pull moved out of config.json:\n%s", data)
+	}
+	if cfg, err := config.Load(p.ConfigPath); err != nil || cfg.Sync.LastFullPull == nil {
+		t.Fatalf("expected last_full_pull in state.json, got %+v (%v)", cfg.Sync, err)
+	}
+	original, err := issue.ParseFile(originalPath)
+	if err != nil {
+		t.Fatalf("parse original: %v", err)
+	}
+	if original.Title != "New title" {
+		t.Fatalf("expected the newer side, got %q", original.Title)
+	}
+	if _, err := os.Stat(p.LabelsPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the conflicted label cache to be removed, got %v", err)
+	}
+
+	out.Reset()
+	if err := application.RepairSyncState(context.Background()); err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	if !strings.Contains(out.String(), "Sync state is consistent") {
+		t.Fatalf("expected nothing left to repair:\n%s", out.String())
+	}
+
+	if err := os.WriteFile(p.LabelMergesPath, []byte("<<<<<<< HEAD\n[]\n=======\n{}\n>>>>>>> other\n"), 0o644); err != nil {
+		t.Fatalf("label merges: %v", err)
+	}
+	if err := application.RepairSyncState(context.Background()); err == nil || !strings.Contains(err.Error(), "label_merges.json") {
+		t.Fatalf("expected a conflict to resolve by hand, got %v", err)
+	}
+}
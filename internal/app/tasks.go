@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+type TasksOptions struct {
+	Toggle []int // 1-based task indices to toggle
+}
+
+// Tasks lists the task-list items of an issue, toggling the given items first.
+func (a *App) Tasks(ctx context.Context, number string, opts TasksOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	if len(opts.Toggle) > 0 {
+		lck, err := a.lockMirror(p)
+		if err != nil {
+			return err
+		}
+		defer lck.Release()
+	}
+
+	file, err := findIssueByNumber(p, number)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.Toggle) > 0 {
+		body := file.Issue.Body
+		for _, idx := range opts.Toggle {
+			tasks := issue.ParseTasks(body)
+			if idx < 1 || idx > len(tasks) {
+				return fmt.Errorf("task %d does not exist (issue has %d tasks)", idx, len(tasks))
+			}
+			task := tasks[idx-1]
+			body = issue.SetTaskChecked(body, task, !task.Checked)
+		}
+		file.Issue.Body = body
+		file.Issue.LastLocalEditBy = a.localEditor(ctx)
+		if err := writeIssue(p, file.Path, file.Issue); err != nil {
+			return err
+		}
+	}
+
+	tasks := issue.ParseTasks(file.Issue.Body)
+	if len(tasks) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No task-list items"))
+		return nil
+	}
+	done, total := issue.TaskProgress(file.Issue.Body)
+	fmt.Fprintf(a.Out, "%s %s\n", t.FormatIssueHeader(file.State, file.Issue.Number.String(), file.Issue.Title),
+		t.MutedText(fmt.Sprintf("(%d/%d done)", done, total)))
+	for i, task := range tasks {
+		mark := t.MutedText("[ ]")
+		text := task.Text
+		if task.Checked {
+			mark = t.SuccessText("[x]")
+			text = t.MutedText(text)
+		}
+		fmt.Fprintf(a.Out, "%3d. %s %s\n", i+1, mark, text)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestTasksToggle(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "3", Title: "Checklist", State: "open", Body: "- [ ] a\n- [ ] b\n"}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+
+	var out strings.Builder
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	if err := application.Tasks(context.Background(), "3", TasksOptions{Toggle: []int{2}}); err != nil {
+		t.Fatalf("tasks: %v", err)
+	}
+	if !strings.Contains(out.String(), "(1/2 done)") {
+		t.Fatalf("expected progress in output: %s", out.String())
+	}
+	updated, err := findIssueByNumber(p, "3")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if updated.Issue.Body != "- [ ] a\n- [x] b\n" {
+		t.Fatalf("unexpected body: %q", updated.Issue.Body)
+	}
+	if err := application.Tasks(context.Background(), "3", TasksOptions{Toggle: []int{5}}); err == nil {
+		t.Fatalf("expected error for out of range task")
+	}
+}
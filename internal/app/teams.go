@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// TeamCache stores the organization teams synced on pull.
+type TeamCache struct {
+	Org      string      `json:"org"`
+	Teams    []TeamEntry `json:"teams"`
+	SyncedAt time.Time   `json:"synced_at"`
+}
+
+// TeamEntry represents a single team.
+type TeamEntry struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+func loadTeamCache(p paths.Paths) (TeamCache, error) {
+	var cache TeamCache
+	data, err := os.ReadFile(p.TeamsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return cache, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, err
+	}
+	return cache, nil
+}
+
+func saveTeamCache(p paths.Paths, cache TeamCache) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(p.TeamsPath, data, 0o644)
+}
+
+// teamMentionPattern matches @org/team mentions that are not part of an
+// email address or a path.
+var teamMentionPattern = regexp.MustCompile(`(?:^|[^\w@/.])@([A-Za-z0-9][A-Za-z0-9-]*)/([A-Za-z0-9][A-Za-z0-9_.-]*[A-Za-z0-9_-])`)
+
+type teamMention struct {
+	Org  string
+	Slug string
+}
+
+func (m teamMention) String() string {
+	return "@" + m.Org + "/" + m.Slug
+}
+
+// teamMentions returns the distinct team mentions in a body, skipping code
+// blocks, inline code, and local notes.
+func teamMentions(body string) []teamMention {
+	body = issue.StripLocalNotes(body)
+	var mentions []teamMention
+	seen := map[string]struct{}{}
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		line = inlineCodePattern.ReplaceAllString(line, "")
+		for _, m := range teamMentionPattern.FindAllStringSubmatch(line, -1) {
+			mention := teamMention{Org: m[1], Slug: m[2]}
+			key := strings.ToLower(mention.String())
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions
+}
+
+var inlineCodePattern = regexp.MustCompile("`[^`]*`")
+
+// unknownTeamWarnings checks the team mentions in body against the cache and
+// returns a warning for every mention of an unknown team of the cached org.
+// Mentions of other organizations cannot be checked and are ignored.
+func unknownTeamWarnings(cache TeamCache, body string) []string {
+	if len(cache.Teams) == 0 {
+		return nil
+	}
+	slugs := make([]string, 0, len(cache.Teams))
+	known := make(map[string]struct{}, len(cache.Teams))
+	for _, team := range cache.Teams {
+		slugs = append(slugs, team.Slug)
+		known[strings.ToLower(team.Slug)] = struct{}{}
+	}
+	var warnings []string
+	for _, mention := range teamMentions(body) {
+		if !strings.EqualFold(mention.Org, cache.Org) {
+			continue
+		}
+		if _, ok := known[strings.ToLower(mention.Slug)]; ok {
+			continue
+		}
+		if suggestion := suggestLabel(mention.Slug, slugs); suggestion != "" {
+			warnings = append(warnings, fmt.Sprintf("unknown team %s (did you mean @%s/%s?)", mention, cache.Org, suggestion))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("unknown team %s", mention))
+		}
+	}
+	return warnings
+}
+
+// printTeamMentions lists the teams mentioned in an issue body. With expand
+// set, the members of each known team are fetched so it is clear who gets
+// notified.
+func (a *App) printTeamMentions(ctx context.Context, p paths.Paths, body string, expand bool) {
+	t := a.Theme
+	mentions := teamMentions(body)
+	if len(mentions) == 0 {
+		return
+	}
+	cache, _ := loadTeamCache(p)
+	known := make(map[string]struct{}, len(cache.Teams))
+	for _, team := range cache.Teams {
+		known[strings.ToLower(team.Slug)] = struct{}{}
+	}
+	isUnknown := func(m teamMention) bool {
+		if len(cache.Teams) == 0 || !strings.EqualFold(m.Org, cache.Org) {
+			return false
+		}
+		_, ok := known[strings.ToLower(m.Slug)]
+		return !ok
+	}
+
+	if !expand {
+		names := make([]string, 0, len(mentions))
+		for _, m := range mentions {
+			if isUnknown(m) {
+				names = append(names, t.WarningText(m.String()+" (unknown)"))
+			} else {
+				names = append(names, m.String())
+			}
+		}
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("teams:"), strings.Join(names, ", "))
+		return
+	}
+
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), err)
+		return
+	}
+	client, err := a.newProvider(cfg)
+	if err != nil {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), err)
+		return
+	}
+	for _, m := range mentions {
+		label := t.MutedText("team " + m.String() + ":")
+		if isUnknown(m) {
+			fmt.Fprintf(a.Out, "%s\t%s\n", label, t.WarningText("unknown team"))
+			continue
+		}
+		if !strings.EqualFold(m.Org, cfg.Repository.Owner) {
+			fmt.Fprintf(a.Out, "%s\t%s\n", label, t.MutedText("(other organization)"))
+			continue
+		}
+		members, err := client.ListTeamMembers(ctx, m.Slug)
+		if err != nil {
+			fmt.Fprintf(a.Out, "%s\t%s\n", label, t.WarningText(fmt.Sprintf("could not list members: %v", err)))
+			continue
+		}
+		fmt.Fprintf(a.Out, "%s\t%s\n", label, strings.Join(members, ", "))
+	}
+}
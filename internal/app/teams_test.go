@@ -0,0 +1,35 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeamMentions(t *testing.T) {
+	body := "cc @acme/backend and @acme/frontend.\n" +
+		"Mail ops@acme/backend is not a mention, nor is `@acme/code`.\n" +
+		"```\n@acme/in-fence\n```\n" +
+		"Again @acme/backend\n"
+	mentions := teamMentions(body)
+	var got []string
+	for _, m := range mentions {
+		got = append(got, m.String())
+	}
+	if strings.Join(got, ",") != "@acme/backend,@acme/frontend" {
+		t.Fatalf("unexpected mentions: %v", got)
+	}
+}
+
+func TestUnknownTeamWarnings(t *testing.T) {
+	cache := TeamCache{Org: "acme", Teams: []TeamEntry{{Slug: "backend"}, {Slug: "frontend"}}}
+	warnings := unknownTeamWarnings(cache, "cc @acme/backedn @acme/frontend @other/whatever @acme/security")
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0], "did you mean @acme/backend?") {
+		t.Fatalf("expected suggestion, got %q", warnings[0])
+	}
+	if warnings[1] != "unknown team @acme/security" {
+		t.Fatalf("unexpected warning: %q", warnings[1])
+	}
+}
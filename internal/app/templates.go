@@ -0,0 +1,201 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// templatePath returns the template file for an issue type, e.g.
+// .issues/templates/bug.md for "Bug".
+func templatePath(p paths.Paths, issueType string) string {
+	return filepath.Join(p.TemplatesDir, issue.Slugify(issueType)+".md")
+}
+
+// loadTemplate reads the template for an issue type. Templates use the issue
+// file format; the title is ignored and the labels and body seed new issues.
+// ok is false when the type has no template.
+func loadTemplate(p paths.Paths, issueType string) (tmpl issue.Issue, ok bool, err error) {
+	tmpl, err = p.Format.ParseFile(templatePath(p, issueType))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return issue.Issue{}, false, nil
+		}
+		return issue.Issue{}, false, fmt.Errorf("template for %s: %w", issueType, err)
+	}
+	return tmpl, true, nil
+}
+
+// applyTemplate seeds iss from the template for its issue type, expanding
+// the variables in the body with vars.
+func applyTemplate(p paths.Paths, iss *issue.Issue, vars templateVars) error {
+	tmpl, ok, err := loadTemplate(p, iss.IssueType)
+	if err != nil || !ok {
+		return err
+	}
+	for _, label := range tmpl.Labels {
+		if !containsFold(iss.Labels, label) {
+			iss.Labels = append(iss.Labels, label)
+		}
+	}
+	if strings.TrimSpace(iss.Body) == "" {
+		iss.Body = vars.expand(tmpl.Body)
+	}
+	return nil
+}
+
+var templateVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// templateVars looks up the value of a {{name}} variable.
+type templateVars func(name string) (string, bool)
+
+// expand replaces the known variables in text. Unknown ones are left as they
+// are, so text that just happens to use braces survives.
+func (vars templateVars) expand(text string) string {
+	if vars == nil {
+		return text
+	}
+	return templateVarPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := templateVarPattern.FindStringSubmatch(match)[1]
+		if value, ok := vars(name); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// with returns vars extended by one variable that takes precedence.
+func (vars templateVars) with(name, value string) templateVars {
+	return func(n string) (string, bool) {
+		if n == name {
+			return value, true
+		}
+		if vars == nil {
+			return "", false
+		}
+		return vars(n)
+	}
+}
+
+// templateVars returns the variables for templates and recurring issues:
+// the custom ones from the config, then {{date}}, {{repo}}, {{branch}} (the
+// current git branch), and {{user}} (the forge login, or git's user.name).
+// branch and user are looked up on first use.
+func (a *App) templateVars(ctx context.Context, cfg config.Config) templateVars {
+	cache := map[string]string{}
+	return func(name string) (string, bool) {
+		if value, ok := cfg.Variables[name]; ok {
+			return value, true
+		}
+		if value, ok := cache[name]; ok {
+			return value, value != ""
+		}
+		var value string
+		switch name {
+		case "date":
+			value = a.Now().Format("2006-01-02")
+		case "repo":
+			value = cfg.Repository.Owner + "/" + cfg.Repository.Repo
+		case "branch":
+			if out, err := execCommand(ctx, "git", "-C", a.Root, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+				value = strings.TrimSpace(out)
+			}
+		case "user":
+			if client, err := a.newProvider(cfg); err == nil {
+				if status, err := client.CheckAuth(ctx); err == nil {
+					value = status.User
+				}
+			}
+			if value == "" {
+				if out, err := execCommand(ctx, "git", "-C", a.Root, "config", "user.name"); err == nil {
+					value = strings.TrimSpace(out)
+				}
+			}
+		default:
+			return "", false
+		}
+		cache[name] = value
+		return value, value != ""
+	}
+}
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// missingSections returns the required sections that are absent from body or
+// hold nothing but template comments.
+func missingSections(body string, required []string) []string {
+	body = htmlCommentPattern.ReplaceAllString(issue.StripLocalNotes(body), "")
+	type section struct {
+		level   int
+		content strings.Builder
+	}
+	sections := map[string]*section{}
+	var stack []*section
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil && !inFence {
+			s := &section{level: len(m[1])}
+			for len(stack) > 0 && stack[len(stack)-1].level >= s.level {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, s)
+			name := strings.ToLower(m[2])
+			if _, exists := sections[name]; !exists {
+				sections[name] = s
+			}
+			continue
+		}
+		// Content counts towards the enclosing sections, so a section
+		// filled in through subsections is not empty
+		for _, s := range stack {
+			s.content.WriteString(line)
+		}
+	}
+
+	var missing []string
+	for _, name := range required {
+		s, ok := sections[strings.ToLower(strings.TrimSpace(name))]
+		if !ok || strings.TrimSpace(s.content.String()) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// lintRequiredSections checks that issues with a configured type contain the
+// required sections. Only issues that are new or whose body or type changed
+// since the last sync are checked, so old issues don't block a push.
+func lintRequiredSections(p paths.Paths, cfg config.Config, items []IssueFile) map[string][]string {
+	problems := map[string][]string{}
+	for _, item := range items {
+		tc, ok := cfg.TemplateFor(item.Issue.IssueType)
+		if !ok || len(tc.RequiredSections) == 0 {
+			continue
+		}
+		if !item.Issue.Number.IsLocal() {
+			original, hasOriginal := readOriginalIssue(p, item.Issue.Number.String())
+			if hasOriginal && original.IssueType == item.Issue.IssueType &&
+				issue.PublicBody(original.Body) == issue.PublicBody(item.Issue.Body) {
+				continue
+			}
+		}
+		if missing := missingSections(item.Issue.Body, tc.RequiredSections); len(missing) > 0 {
+			problems[item.Issue.Number.String()] = missing
+		}
+	}
+	return problems
+}
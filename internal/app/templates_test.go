@@ -0,0 +1,214 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+const bugTemplate = `---
+title: ""
+labels:
+    - bug
+---
+
+## Steps to reproduce
+
+<!-- What did you do? -->
+
+## Expected behavior
+
+<!-- What should have happened? -->
+`
+
+func setupTemplates(t *testing.T) (string, paths.Paths) {
+	t.Helper()
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Templates = map[string]config.TemplateConfig{
+		"Bug": {RequiredSections: []string{"Steps to reproduce", "Expected behavior"}},
+	}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := os.MkdirAll(p.TemplatesDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(p.TemplatesDir, "bug.md"), []byte(bugTemplate), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	return root, p
+}
+
+func TestNewWithTypeUsesTemplate(t *testing.T) {
+	root, p := setupTemplates(t)
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	if err := application.NewIssue(context.Background(), "Crash on start", NewOptions{Type: "Bug", Labels: []string{"ui"}}); err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	issues, err := loadLocalIssues(p)
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("expected one issue, got %d (%v)", len(issues), err)
+	}
+	created := issues[0].Issue
+	if created.IssueType != "Bug" || !strings.Contains(created.Body, "## Steps to reproduce") {
+		t.Fatalf("template not applied: %+v", created)
+	}
+	if strings.Join(created.Labels, ",") != "bug,ui" {
+		t.Fatalf("unexpected labels: %v", created.Labels)
+	}
+}
+
+func TestTemplateVariables(t *testing.T) {
+	root, p := setupTemplates(t)
+	cfg, err := config.Load(p.ConfigPath)
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	cfg.Variables = map[string]string{"team": "infra"}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	task := "---\ntitle: \"\"\n---\n\nOpened {{date}} in {{repo}} on {{branch}} by {{user}} for {{ team }}, not {{unknown}}.\n"
+	if err := os.WriteFile(filepath.Join(p.TemplatesDir, "task.md"), []byte(task), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	previous := execCommand
+	execCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		switch strings.Join(args[2:], " ") {
+		case "rev-parse --abbrev-ref HEAD":
+			return "fix-login\n", nil
+		case "config user.name":
+			return "Jane Doe\n", nil
+		}
+		return "", errors.New("unexpected command")
+	}
+	t.Cleanup(func() { execCommand = previous })
+
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	application.Now = func() time.Time { return time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC) }
+	if err := application.NewIssue(context.Background(), "Rotate keys", NewOptions{Type: "Task"}); err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	issues, err := loadLocalIssues(p)
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("expected one issue, got %d (%v)", len(issues), err)
+	}
+	want := "Opened 2026-03-02 in owner/repo on fix-login by Jane Doe for infra, not {{unknown}}.\n"
+	if issues[0].Issue.Body != want {
+		t.Fatalf("body = %q, want %q", issues[0].Issue.Body, want)
+	}
+
+	def := issue.Recurring{Template: issue.Issue{Title: "{{team}} sync {{period}}"}}
+	if got := instantiateRecurring(def, "T1", "2026-03-02", application.templateVars(context.Background(), cfg)).Title; got != "infra sync 2026-03-02" {
+		t.Fatalf("recurring title = %q", got)
+	}
+}
+
+func TestMissingSections(t *testing.T) {
+	body := "## Steps to reproduce\n\n<!-- What did you do? -->\n\n## Expected behavior\n\n### On Linux\n\nIt works.\n"
+	missing := missingSections(body, []string{"Steps to reproduce", "expected behavior", "Logs"})
+	if strings.Join(missing, ",") != "Steps to reproduce,Logs" {
+		t.Fatalf("unexpected missing sections: %v", missing)
+	}
+}
+
+func TestPushRefusesMissingSections(t *testing.T) {
+	root, p := setupTemplates(t)
+	iss := issue.Issue{
+		Number:    "T1",
+		Title:     "Crash on start",
+		IssueType: "Bug",
+		State:     "open",
+		Body:      "## Steps to reproduce\n\nRun it.\n",
+	}
+	if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var errOut bytes.Buffer
+	runner := &offlineRunner{}
+	application := New(root, runner, io.Discard, &errOut)
+	err := application.Push(context.Background(), PushOptions{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "--no-lint") {
+		t.Fatalf("expected lint error, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "#T1 is missing required section(s): Expected behavior") {
+		t.Fatalf("unexpected warnings: %s", errOut.String())
+	}
+	for _, call := range runner.calls {
+		if strings.Contains(call, "createIssue") {
+			t.Fatalf("unexpected issue creation: %s", call)
+		}
+	}
+}
+
+func TestApplyTemplateDefaults(t *testing.T) {
+	root, p := setupTemplates(t)
+	dir := repoTemplatesDir(root)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{
+		"crash.md": "---\nname: Crash report\nabout: Something crashed\nlabels: crash, triage\nassignees:\n  - alice\nprojects: [\"owner/3\"]\n---\n\n## Stack trace\n\n## Version\n",
+		"feature.yml": `name: Feature request
+labels: [enhancement]
+body:
+  - type: markdown
+    attributes:
+      value: Thanks!
+  - type: textarea
+    attributes:
+      label: Use case
+`,
+		"config.yml": "blank_issues_enabled: false\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write template: %v", err)
+		}
+	}
+
+	items := []IssueFile{
+		{Issue: issue.Issue{Number: "T1", Title: "Boom", Body: "## Stack trace\n\npanic\n\n## Version\n\n1.2\n"}},
+		{Issue: issue.Issue{Number: "T2", Title: "Dark mode", Body: "### Use case\n\nNight owls\n"}},
+		{Issue: issue.Issue{Number: "T3", Title: "Typed", IssueType: "Bug", Labels: []string{"BUG"}}},
+		{Issue: issue.Issue{Number: "4", Title: "Already pushed", Body: "### Use case\n"}},
+	}
+	var errOut bytes.Buffer
+	application := New(root, &offlineRunner{}, io.Discard, &errOut)
+	application.Theme = theme.Plain()
+	application.applyTemplateDefaults(p, config.Default("owner", "repo"), []ProjectEntry{{ID: "P", Number: 3, Title: "Roadmap"}}, items)
+
+	crash := items[0].Issue
+	if strings.Join(crash.Labels, ",") != "crash,triage" || strings.Join(crash.Assignees, ",") != "alice" || strings.Join(crash.Projects, ",") != "Roadmap" {
+		t.Fatalf("unexpected crash defaults: %+v", crash)
+	}
+	if strings.Join(items[1].Issue.Labels, ",") != "enhancement" {
+		t.Fatalf("expected the form's labels, got %v", items[1].Issue.Labels)
+	}
+	if strings.Join(items[2].Issue.Labels, ",") != "BUG" {
+		t.Fatalf("expected the local template's label only once, got %v", items[2].Issue.Labels)
+	}
+	if len(items[3].Issue.Labels) != 0 {
+		t.Fatalf("expected pushed issues to be left alone, got %v", items[3].Issue.Labels)
+	}
+	if !strings.Contains(errOut.String(), "Applied template defaults of Crash report to #T1") {
+		t.Fatalf("unexpected output: %s", errOut.String())
+	}
+}
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// commentPullConcurrency is how many issues pull fetches comments for at
+// once.
+const commentPullConcurrency = 8
+
+// CommentCache stores the pulled comments of an issue in the order they
+// were written.
+type CommentCache struct {
+	Comments []ghcli.Comment `json:"comments"`
+}
+
+func commentCachePath(p paths.Paths, number string) string {
+	return filepath.Join(p.CommentsDir, number+".json")
+}
+
+func loadCommentCache(p paths.Paths, number string) (CommentCache, bool) {
+	var cache CommentCache
+	data, err := os.ReadFile(commentCachePath(p, number))
+	if err != nil {
+		return cache, false
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, false
+	}
+	return cache, true
+}
+
+func saveCommentCache(p paths.Paths, number string, cache CommentCache) error {
+	if err := os.MkdirAll(p.CommentsDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(commentCachePath(p, number), data, 0o644)
+}
+
+// mergeComments adds fetched comments to the cached ones, replacing edited
+// comments by ID.
+func mergeComments(cached, fetched []ghcli.Comment) []ghcli.Comment {
+	merged := slices.Clone(cached)
+	for _, comment := range fetched {
+		i := slices.IndexFunc(merged, func(c ghcli.Comment) bool { return c.ID == comment.ID })
+		if i >= 0 {
+			merged[i] = comment
+		} else {
+			merged = append(merged, comment)
+		}
+	}
+	slices.SortStableFunc(merged, func(a, b ghcli.Comment) int {
+		if a.CreatedAt == nil || b.CreatedAt == nil {
+			return 0
+		}
+		return a.CreatedAt.Compare(*b.CreatedAt)
+	})
+	return merged
+}
+
+// newestCommentUpdate is the cursor the next pull of comments starts from.
+func newestCommentUpdate(comments []ghcli.Comment) *time.Time {
+	var newest *time.Time
+	for _, comment := range comments {
+		if comment.UpdatedAt != nil && (newest == nil || comment.UpdatedAt.After(*newest)) {
+			newest = comment.UpdatedAt
+		}
+	}
+	return newest
+}
+
+// pullComments brings the comment caches of items up to date. Only issues
+// updated since their comments were last pulled are fetched, and of those
+// only the comments updated since the newest one seen, several issues at a
+// time. When the merged thread does not add up to the issue's comment
+// count, comments were deleted and the thread is fetched again in full.
+// The new cursors are stored in cfg; failures are warnings so one broken
+// thread does not fail the pull.
+func (a *App) pullComments(ctx context.Context, p paths.Paths, cfg *config.Config, client ghcli.Provider, items []IssueFile) int {
+	t := a.Theme
+	type job struct {
+		number string
+		item   IssueFile
+		cursor config.CommentCursor
+		cache  CommentCache
+		err    error
+	}
+	var jobs []*job
+	for _, item := range items {
+		iss := item.Issue
+		if iss.Number.IsLocal() {
+			continue
+		}
+		number := iss.Number.String()
+		cursor, seen := cfg.Sync.CommentCursors[number]
+		if !seen && iss.CommentCount == 0 {
+			continue
+		}
+		if seen && iss.UpdatedAt != nil && cursor.IssueUpdatedAt != nil && !iss.UpdatedAt.After(*cursor.IssueUpdatedAt) {
+			continue
+		}
+		jobs = append(jobs, &job{number: number, item: item, cursor: cursor})
+	}
+
+	sem := make(chan struct{}, commentPullConcurrency)
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			cache, _ := loadCommentCache(p, j.number)
+			since := j.cursor.Since
+			if len(cache.Comments) == 0 {
+				since = nil
+			}
+			fetched, err := client.ListComments(ctx, j.number, since)
+			if err != nil {
+				j.err = err
+				return
+			}
+			cache.Comments = mergeComments(cache.Comments, fetched)
+			if since != nil && len(cache.Comments) != j.item.Issue.CommentCount {
+				if cache.Comments, err = client.ListComments(ctx, j.number, nil); err != nil {
+					j.err = err
+					return
+				}
+			}
+			j.cache = cache
+		}()
+	}
+	wg.Wait()
+
+	pulled := 0
+	for _, j := range jobs {
+		if j.err == nil {
+			j.err = saveCommentCache(p, j.number, j.cache)
+		}
+		if j.err != nil {
+			// The pull reports the interrupt itself
+			if ctx.Err() != nil {
+				continue
+			}
+			fmt.Fprintf(a.Err, "%s pulling comments of #%s: %v\n", t.WarningText("Warning:"), j.number, j.err)
+			continue
+		}
+		if cfg.Sync.CommentCursors == nil {
+			cfg.Sync.CommentCursors = make(map[string]config.CommentCursor)
+		}
+		cfg.Sync.CommentCursors[j.number] = config.CommentCursor{
+			Since:          newestCommentUpdate(j.cache.Comments),
+			IssueUpdatedAt: j.item.Issue.UpdatedAt,
+		}
+		pulled++
+	}
+	return pulled
+}
+
+// printComments prints the pulled comments of an issue.
+func (a *App) printComments(p paths.Paths, number string) {
+	t := a.Theme
+	cache, ok := loadCommentCache(p, number)
+	if !ok {
+		return
+	}
+	for _, comment := range cache.Comments {
+		author := comment.Author
+		if author == "" {
+			author = "ghost"
+		}
+		header := author
+		if comment.CreatedAt != nil {
+			header += " " + formatRelativeTime(a.Now(), *comment.CreatedAt)
+		}
+		fmt.Fprintln(a.Out)
+		fmt.Fprintln(a.Out, t.MutedText("--- "+header+" ---"))
+		if rendered, err := renderMarkdown(comment.Body); err != nil {
+			fmt.Fprintln(a.Out, comment.Body)
+		} else {
+			fmt.Fprint(a.Out, rendered)
+		}
+	}
+}
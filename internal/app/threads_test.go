@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// commentsRunner serves the comments of issue 1 and records the endpoints
+// asked for.
+type commentsRunner struct {
+	mu        sync.Mutex
+	endpoints []string
+	comments  []string
+}
+
+func (r *commentsRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints = append(r.endpoints, args[1])
+	if !strings.Contains(args[1], "issues/1/comments") {
+		return "", nil
+	}
+	return strings.Join(r.comments, "\n"), nil
+}
+
+func TestPullComments(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	runner := &commentsRunner{comments: []string{
+		`{"id":10,"body":"First","user":{"login":"alice"},"created_at":"2026-10-01T00:00:00Z","updated_at":"2026-10-01T00:00:00Z"}`,
+		`{"id":11,"body":"Second","user":{"login":"bob"},"created_at":"2026-10-02T00:00:00Z","updated_at":"2026-10-02T00:00:00Z"}`,
+	}}
+	application := New(root, runner, io.Discard, io.Discard)
+	client := ghcli.NewClient(runner, "owner/repo")
+
+	updated := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	items := []IssueFile{
+		{Issue: issue.Issue{Number: "1", CommentCount: 2, UpdatedAt: &updated}},
+		{Issue: issue.Issue{Number: "2", UpdatedAt: &updated}},
+	}
+	if pulled := application.pullComments(context.Background(), p, &cfg, client, items); pulled != 1 {
+		t.Fatalf("expected comments of one issue pulled, got %d (%v)", pulled, runner.endpoints)
+	}
+	cache, ok := loadCommentCache(p, "1")
+	if !ok || len(cache.Comments) != 2 || cache.Comments[1].Author != "bob" {
+		t.Fatalf("unexpected cache: %+v", cache)
+	}
+	if cursor := cfg.Sync.CommentCursors["1"]; cursor.Since == nil || !cursor.Since.Equal(updated) {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+
+	// Unchanged issues are not fetched again
+	runner.endpoints = nil
+	if pulled := application.pullComments(context.Background(), p, &cfg, client, items); pulled != 0 || len(runner.endpoints) != 0 {
+		t.Fatalf("expected nothing fetched, got %v", runner.endpoints)
+	}
+
+	// An update fetches only the comments since the cursor and merges them
+	edited := updated.Add(time.Hour)
+	items[0].Issue.UpdatedAt = &edited
+	items[0].Issue.CommentCount = 3
+	runner.comments = []string{
+		`{"id":11,"body":"Second, edited","user":{"login":"bob"},"created_at":"2026-10-02T00:00:00Z","updated_at":"2026-10-02T01:00:00Z"}`,
+		`{"id":12,"body":"Third","user":{"login":"carol"},"created_at":"2026-10-02T01:00:00Z","updated_at":"2026-10-02T01:00:00Z"}`,
+	}
+	runner.endpoints = nil
+	if pulled := application.pullComments(context.Background(), p, &cfg, client, items); pulled != 1 {
+		t.Fatalf("expected comments of one issue pulled, got %d", pulled)
+	}
+	if len(runner.endpoints) != 1 || !strings.Contains(runner.endpoints[0], "since=2026-10-02T00:00:00Z") {
+		t.Fatalf("expected one incremental fetch, got %v", runner.endpoints)
+	}
+	cache, _ = loadCommentCache(p, "1")
+	if len(cache.Comments) != 3 || cache.Comments[1].Body != "Second, edited" || cache.Comments[2].Author != "carol" {
+		t.Fatalf("unexpected merged cache: %+v", cache)
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/shlex"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+type TranslateOptions struct {
+	To         string // target language, e.g. en
+	NoComments bool   // only translate the body
+}
+
+// translationHeader starts the local-notes block holding a translation, so
+// translating again into the same language replaces it.
+func translationHeader(to string) string {
+	return "Translation (" + to + "):"
+}
+
+// Translate pipes the body and the pulled comments of an issue through the
+// configured translation command and stores the result in a local-notes
+// block, which is never pushed.
+func (a *App) Translate(ctx context.Context, number string, opts TranslateOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+	if strings.TrimSpace(cfg.Translate.Command) == "" {
+		return errors.New("no translation command configured (set translate.command in .issues/.sync/config.json)")
+	}
+	to := strings.TrimSpace(opts.To)
+	if to == "" {
+		to = "en"
+	}
+	file, err := findIssueByRef(a.Root, p, number)
+	if err != nil {
+		return err
+	}
+	number = file.Issue.Number.String()
+
+	var parts []string
+	title, err := a.translateText(ctx, cfg.Translate.Command, to, file.Issue.Title)
+	if err != nil {
+		return err
+	}
+	parts = append(parts, "# "+title)
+	if body := strings.TrimSpace(issue.PublicBody(file.Issue.Body)); body != "" {
+		translated, err := a.translateText(ctx, cfg.Translate.Command, to, body)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, translated)
+	}
+	comments := 0
+	if cache, ok := loadCommentCache(p, number); ok && !opts.NoComments {
+		for _, comment := range cache.Comments {
+			if strings.TrimSpace(comment.Body) == "" {
+				continue
+			}
+			translated, err := a.translateText(ctx, cfg.Translate.Command, to, comment.Body)
+			if err != nil {
+				return err
+			}
+			author := comment.Author
+			if author == "" {
+				author = "ghost"
+			}
+			header := "@" + author
+			if comment.CreatedAt != nil {
+				header += ", " + comment.CreatedAt.Format("2006-01-02")
+			}
+			parts = append(parts, header+":\n"+translated)
+			comments++
+		}
+	}
+	content := translationHeader(to) + "\n\n" + strings.Join(parts, "\n\n")
+	// Notes must not close the HTML comment they live in
+	content = strings.ReplaceAll(content, "-->", "-- >")
+
+	if err := a.updateLocalIssue(ctx, p, number, func(iss *issue.Issue) {
+		iss.Body = withTranslation(iss.Body, to, content)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%s #%s into %s %s\n", t.SuccessText("Translated"), number, to,
+		t.MutedText(fmt.Sprintf("(body and %d comment(s), in the local notes)", comments)))
+	return nil
+}
+
+// withTranslation replaces the local-notes block holding the translation
+// into to, or appends a new block.
+func withTranslation(body, to, content string) string {
+	replaced := false
+	updated, _ := issue.MapLocalNotes(body, func(block issue.NotesBlock) (issue.NotesBlock, error) {
+		if !replaced && !block.Encrypted && strings.HasPrefix(strings.TrimSpace(block.Content), translationHeader(to)) {
+			replaced = true
+			return issue.NotesBlock{Content: content}, nil
+		}
+		return block, nil
+	})
+	if replaced {
+		return updated
+	}
+	block := issue.NotesBlock{Content: content}.Render()
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return block + "\n"
+	}
+	return body + "\n\n" + block + "\n"
+}
+
+// translateText runs the translation command on text.
+func (a *App) translateText(ctx context.Context, command, to, text string) (string, error) {
+	parts, err := shlex.Split(command)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse translate.command %q: %w", command, err)
+	}
+	if len(parts) == 0 {
+		return "", errors.New("empty translate.command")
+	}
+	placeholder := false
+	for i, part := range parts {
+		if strings.Contains(part, "{to}") {
+			parts[i] = strings.ReplaceAll(part, "{to}", to)
+			placeholder = true
+		}
+	}
+	if !placeholder {
+		parts = append(parts, to)
+	}
+	out, err := pipeCommand(ctx, text, parts[0], parts[1:]...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
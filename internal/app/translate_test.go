@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestTranslate(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	iss := issue.Issue{Number: "7", Title: "Absturz beim Start", State: "open",
+		Body: "Die App stürzt ab.\n\n<!-- local-notes\nasked for logs\n-->"}
+	path := issue.PathFor(p.OpenDir, iss.Number, iss.Title)
+	if err := issue.WriteFile(path, iss); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	createdAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	if err := saveCommentCache(p, "7", CommentCache{Comments: []ghcli.Comment{
+		{ID: "c1", Author: "hans", Body: "Bei mir auch.", CreatedAt: &createdAt},
+	}}); err != nil {
+		t.Fatalf("comments: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	if err := application.Translate(ctx, "7", TranslateOptions{To: "en"}); err == nil || !strings.Contains(err.Error(), "translate.command") {
+		t.Fatalf("expected an error without a command, got %v", err)
+	}
+
+	cfg.Translate.Command = "translate --target {to}"
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	dictionary := map[string]string{
+		"Absturz beim Start": "Crash on start",
+		"Die App stürzt ab.": "The app crashes.",
+		"Bei mir auch.":      "Same for me.",
+	}
+	prev := pipeCommand
+	pipeCommand = func(ctx context.Context, input string, name string, args ...string) (string, error) {
+		if name != "translate" || strings.Join(args, " ") != "--target en" {
+			t.Fatalf("unexpected command %s %v", name, args)
+		}
+		if strings.Contains(input, "asked for logs") {
+			t.Fatalf("expected local notes not to be translated, got %q", input)
+		}
+		return dictionary[input] + "\n", nil
+	}
+	t.Cleanup(func() { pipeCommand = prev })
+
+	if err := application.Translate(ctx, "7", TranslateOptions{To: "en"}); err != nil {
+		t.Fatalf("translate: %v", err)
+	}
+	// Translating again replaces the translation instead of adding another
+	if err := application.Translate(ctx, "7", TranslateOptions{To: "en"}); err != nil {
+		t.Fatalf("translate again: %v", err)
+	}
+	updated, err := issue.ParseFile(path)
+	if err != nil {
+		t.Fatalf("read issue: %v", err)
+	}
+	notes := issue.LocalNotes(updated.Body)
+	want := "Translation (en):\n\n# Crash on start\n\nThe app crashes.\n\n@hans, 2026-03-02:\nSame for me."
+	if strings.Count(updated.Body, "Translation (en)") != 1 || !strings.Contains(notes, want) {
+		t.Fatalf("expected one translation in the local notes, got:\n%s", updated.Body)
+	}
+	if !strings.Contains(notes, "asked for logs") {
+		t.Fatalf("expected existing notes to be kept, got:\n%s", updated.Body)
+	}
+	if issue.PublicBody(updated.Body) != issue.PublicBody(iss.Body) {
+		t.Fatalf("expected the public body unchanged, got %q", issue.PublicBody(updated.Body))
+	}
+}
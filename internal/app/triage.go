@@ -0,0 +1,385 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/search"
+)
+
+// defaultTriageQuery selects issues nobody has looked at yet.
+const defaultTriageQuery = "no:label no:milestone"
+
+type TriageOptions struct {
+	Query string
+}
+
+// Triage walks through untriaged issues one at a time and applies single-key
+// actions to the local files. Nothing is sent to GitHub until the next push.
+func (a *App) Triage(ctx context.Context, opts TriageOptions) error {
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+
+	query := opts.Query
+	if strings.TrimSpace(query) == "" {
+		query = defaultTriageQuery
+	}
+	q := search.Parse(query)
+	if q.Err != nil {
+		return q.Err
+	}
+	state := q.State
+	if state == "" {
+		state = "open"
+	}
+	q.State = ""
+
+	result := loadLocalIssuesWithErrors(p)
+	for _, parseErr := range result.Errors {
+		fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), parseErr)
+	}
+	var queue []IssueFile
+	for _, item := range result.Issues {
+		if item.State != state {
+			continue
+		}
+		if !q.Match(searchDataFor(p.Format, item)) {
+			continue
+		}
+		queue = append(queue, item)
+	}
+	if strings.TrimSpace(opts.Query) == "" {
+		// The default queue also holds the drafts captured with todo.
+		drafts := loadDraftIssues(p)
+		todoLabel := cfg.Todo.EffectiveLabel()
+		for _, item := range drafts.Issues {
+			if containsFold(item.Issue.Labels, todoLabel) {
+				queue = append(queue, item)
+			}
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		iLocal := queue[i].Issue.Number.IsLocal()
+		jLocal := queue[j].Issue.Number.IsLocal()
+		if iLocal != jLocal {
+			return !iLocal
+		}
+		return queue[i].Issue.Number.String() < queue[j].Issue.Number.String()
+	})
+
+	if len(queue) == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("Nothing to triage"))
+		return nil
+	}
+
+	in := a.In
+	if in == nil {
+		in = os.Stdin
+	}
+	reader := bufio.NewReader(in)
+	changed := 0
+
+	for idx, item := range queue {
+		a.printTriageIssue(item, idx+1, len(queue))
+		number := item.Issue.Number.String()
+
+	actions:
+		for {
+			prompt := "[l]abel [m]ilestone [a]ssign [c]lose [o]pen [s]kip [q]uit?"
+			if item.Issue.Draft {
+				prompt = "[p]romote [l]abel [m]ilestone [a]ssign [s]kip [q]uit?"
+			}
+			fmt.Fprintf(a.Out, "%s ", t.AccentText(prompt))
+			key, err := a.readKey(in, reader)
+			fmt.Fprintln(a.Out)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break actions
+				}
+				return err
+			}
+
+			switch key {
+			case 'l':
+				input, err := a.promptLine(reader, "Labels (comma separated, prefix with - to remove): ")
+				if err != nil {
+					return err
+				}
+				if input == "" {
+					continue
+				}
+				if err := a.updateLocalIssue(ctx, p, number, func(iss *issue.Issue) {
+					iss.Labels = applyListEdit(iss.Labels, input)
+				}); err != nil {
+					return err
+				}
+				changed++
+			case 'm':
+				input, err := a.promptLine(reader, "Milestone (- to clear): ")
+				if err != nil {
+					return err
+				}
+				if input == "" {
+					continue
+				}
+				if err := a.updateLocalIssue(ctx, p, number, func(iss *issue.Issue) {
+					if input == "-" {
+						iss.Milestone = ""
+					} else {
+						iss.Milestone = input
+					}
+				}); err != nil {
+					return err
+				}
+				changed++
+			case 'a':
+				input, err := a.promptLine(reader, "Assignees (comma separated, prefix with - to remove): ")
+				if err != nil {
+					return err
+				}
+				if input == "" {
+					continue
+				}
+				if err := a.updateLocalIssue(ctx, p, number, func(iss *issue.Issue) {
+					iss.Assignees = applyListEdit(iss.Assignees, strings.ReplaceAll(input, "@", ""))
+				}); err != nil {
+					return err
+				}
+				changed++
+			case 'c':
+				if item.Issue.Draft || item.Issue.Number.IsLocal() {
+					fmt.Fprintln(a.Out, t.MutedText("Local drafts can't be closed; promote or delete them"))
+					continue
+				}
+				reason, err := a.promptLine(reader, "Close reason ([c]ompleted, [n]ot planned): ")
+				if err != nil {
+					return err
+				}
+				closeOpts := CloseOptions{}
+				switch strings.ToLower(reason) {
+				case "n", "not_planned", "not planned":
+					closeOpts.Reason = "not_planned"
+				case "c", "completed":
+					closeOpts.Reason = "completed"
+				}
+				if err := a.Close(ctx, number, closeOpts); err != nil {
+					return err
+				}
+				changed++
+				fmt.Fprintf(a.Out, "%s #%s\n", t.SuccessText("Closed"), number)
+				break actions
+			case 'p':
+				if !item.Issue.Draft {
+					fmt.Fprintln(a.Out, t.MutedText("Only drafts can be promoted"))
+					continue
+				}
+				if err := a.Promote(ctx, number); err != nil {
+					return err
+				}
+				changed++
+				break actions
+			case 'o':
+				if item.Issue.Number.IsLocal() {
+					fmt.Fprintln(a.Out, t.MutedText("Local issue has no remote page yet"))
+					continue
+				}
+				if err := openBrowser(ctx, issueURL(cfg, number)); err != nil {
+					fmt.Fprintf(a.Err, "%s opening browser: %v\n", t.WarningText("Warning:"), err)
+				}
+			case 's', 'n', ' ', '\r', '\n':
+				break actions
+			case 'q', 3, 4: // 3 and 4 are Ctrl-C and Ctrl-D in raw mode
+				a.printTriageSummary(changed)
+				return nil
+			default:
+				fmt.Fprintln(a.Out, t.MutedText("Unknown action"))
+			}
+		}
+	}
+
+	a.printTriageSummary(changed)
+	return nil
+}
+
+func (a *App) printTriageIssue(item IssueFile, pos, total int) {
+	t := a.Theme
+	iss := item.Issue
+	fmt.Fprintln(a.Out)
+	status := item.State
+	if iss.Draft {
+		status = "draft"
+	}
+	fmt.Fprintf(a.Out, "%s %s\n", t.MutedText(fmt.Sprintf("[%d/%d]", pos, total)), t.FormatIssueHeader(status, iss.Number.String(), iss.Title))
+	if iss.Author != "" {
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("author:"), iss.Author)
+	}
+	if len(iss.Labels) > 0 {
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("labels:"), strings.Join(iss.Labels, ", "))
+	}
+	if len(iss.Assignees) > 0 {
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("assignees:"), strings.Join(iss.Assignees, ", "))
+	}
+	if iss.Milestone != "" {
+		fmt.Fprintf(a.Out, "%s\t%s\n", t.MutedText("milestone:"), iss.Milestone)
+	}
+	fmt.Fprintln(a.Out, "--")
+	if strings.TrimSpace(iss.Body) != "" {
+		rendered, err := renderMarkdown(iss.Body)
+		if err != nil {
+			fmt.Fprintln(a.Out, iss.Body)
+		} else {
+			fmt.Fprint(a.Out, rendered)
+		}
+	}
+}
+
+func (a *App) printTriageSummary(changed int) {
+	t := a.Theme
+	if changed == 0 {
+		fmt.Fprintln(a.Out, t.MutedText("No changes made"))
+		return
+	}
+	fmt.Fprintf(a.Out, "%s %d change(s) locally %s\n", t.SuccessText("Made"), changed, t.MutedText("(run push to sync)"))
+}
+
+// readKey reads a single keypress. On a terminal the key is read in raw mode
+// so no Enter is needed; otherwise the first character of the next line is
+// used, which keeps scripted input working.
+func (a *App) readKey(in io.Reader, reader *bufio.Reader) (rune, error) {
+	if f, ok := in.(*os.File); ok && term.IsTerminal(f.Fd()) && reader.Buffered() == 0 {
+		state, err := term.MakeRaw(f.Fd())
+		if err == nil {
+			defer term.Restore(f.Fd(), state)
+			var buf [1]byte
+			if _, err := f.Read(buf[:]); err != nil {
+				return 0, err
+			}
+			fmt.Fprintf(a.Out, "%c", buf[0])
+			return toLowerKey(rune(buf[0])), nil
+		}
+	}
+	line, err := reader.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" {
+		if err != nil {
+			return 0, err
+		}
+		return '\n', nil
+	}
+	return toLowerKey([]rune(line)[0]), nil
+}
+
+func toLowerKey(r rune) rune {
+	if r >= 'A' && r <= 'Z' {
+		return r + ('a' - 'A')
+	}
+	return r
+}
+
+func (a *App) promptLine(reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Fprint(a.Out, a.Theme.MutedText(prompt))
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// updateLocalIssue applies fn to the issue file under the sync lock.
+func (a *App) updateLocalIssue(ctx context.Context, p paths.Paths, number string, fn func(*issue.Issue)) error {
+	lck, err := a.lockMirror(p)
+	if err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	file, err := findIssueByNumber(p, number)
+	if err != nil {
+		return err
+	}
+	fn(&file.Issue)
+	file.Issue.LastLocalEditBy = a.localEditor(ctx)
+	if err := writeIssue(p, file.Path, file.Issue); err != nil {
+		return err
+	}
+	// A label change can move the issue into another view
+	_, err = routeIssue(p, file.Path, file.State, file.Issue)
+	return err
+}
+
+// applyListEdit adds the comma separated entries in input to values, removing
+// entries prefixed with "-". Comparison is case-insensitive.
+func applyListEdit(values []string, input string) []string {
+	result := append([]string(nil), values...)
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, "-") {
+			name := strings.TrimSpace(part[1:])
+			kept := result[:0]
+			for _, v := range result {
+				if !strings.EqualFold(v, name) {
+					kept = append(kept, v)
+				}
+			}
+			result = kept
+			continue
+		}
+		if !containsFold(result, part) {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// issueURL returns the web URL of a remote issue.
+func issueURL(cfg config.Config, number string) string {
+	if cfg.Repository.Provider == config.ProviderGitLab {
+		host := cfg.Repository.Host
+		if host == "" {
+			host = "gitlab.com"
+		}
+		return fmt.Sprintf("https://%s/%s/-/issues/%s", host, repoSlug(cfg), number)
+	}
+	return fmt.Sprintf("https://github.com/%s/issues/%s", repoSlug(cfg), number)
+}
+
+var openBrowser = func(ctx context.Context, url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := execCommand(ctx, "open", url)
+		return err
+	case "windows":
+		_, err := execCommand(ctx, "rundll32", "url.dll,FileProtocolHandler", url)
+		return err
+	default:
+		_, err := execCommand(ctx, "xdg-open", url)
+		return err
+	}
+}
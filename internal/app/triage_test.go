@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+func TestApplyListEdit(t *testing.T) {
+	tests := []struct {
+		values []string
+		input  string
+		want   []string
+	}{
+		{nil, "bug, ui", []string{"bug", "ui"}},
+		{[]string{"bug"}, "BUG,ui", []string{"bug", "ui"}},
+		{[]string{"bug", "ui"}, "-Bug", []string{"ui"}},
+		{[]string{"bug"}, " , ", []string{"bug"}},
+	}
+	for _, tt := range tests {
+		got := applyListEdit(tt.values, tt.input)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("applyListEdit(%v, %q) = %v, want %v", tt.values, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTriage(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	for _, iss := range []issue.Issue{
+		{Number: "1", Title: "Needs triage", State: "open"},
+		{Number: "2", Title: "Already triaged", State: "open", Labels: []string{"bug"}},
+		{Number: "3", Title: "Also untriaged", State: "open"},
+	} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+
+	var out strings.Builder
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	application.In = strings.NewReader("l\nbug, ui\nm\nv1.0\ns\nc\nn\n")
+
+	if err := application.Triage(context.Background(), TriageOptions{}); err != nil {
+		t.Fatalf("triage: %v", err)
+	}
+	if strings.Contains(out.String(), "Already triaged") {
+		t.Fatalf("labelled issue should not be queued: %s", out.String())
+	}
+
+	first, err := findIssueByNumber(p, "1")
+	if err != nil {
+		t.Fatalf("find #1: %v", err)
+	}
+	if !reflect.DeepEqual(first.Issue.Labels, []string{"bug", "ui"}) || first.Issue.Milestone != "v1.0" {
+		t.Fatalf("unexpected #1 after triage: %+v", first.Issue)
+	}
+
+	third, err := findIssueByNumber(p, "3")
+	if err != nil {
+		t.Fatalf("find #3: %v", err)
+	}
+	if third.State != "closed" || third.Issue.StateReason == nil || *third.Issue.StateReason != "not_planned" {
+		t.Fatalf("expected #3 closed as not_planned, got %+v", third)
+	}
+}
+
+func TestTodoCaptureAndTriagePromote(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var out strings.Builder
+	application := New(root, ghcli.ExecRunner{}, &out, io.Discard)
+	if err := application.Todo(context.Background(), "fix  flaky auth test"); err != nil {
+		t.Fatalf("todo: %v", err)
+	}
+	drafts := loadDraftIssues(p).Issues
+	if len(drafts) != 1 || drafts[0].Issue.Title != "fix flaky auth test" ||
+		!reflect.DeepEqual(drafts[0].Issue.Labels, []string{config.DefaultTodoLabel}) {
+		t.Fatalf("unexpected drafts after todo: %+v", drafts)
+	}
+	number := drafts[0].Issue.Number.String()
+
+	// Closing is not offered for drafts, which were never pushed
+	application.In = strings.NewReader("c\nq\n")
+	if err := application.Triage(context.Background(), TriageOptions{}); err != nil {
+		t.Fatalf("triage: %v", err)
+	}
+	if drafts := loadDraftIssues(p).Issues; len(drafts) != 1 || drafts[0].State != "open" ||
+		!strings.Contains(out.String(), "Local drafts can't be closed") {
+		t.Fatalf("expected the draft left open, got %+v:\n%s", drafts, out.String())
+	}
+
+	application.In = strings.NewReader("p\n")
+	if err := application.Triage(context.Background(), TriageOptions{}); err != nil {
+		t.Fatalf("triage: %v", err)
+	}
+	if len(loadDraftIssues(p).Issues) != 0 {
+		t.Fatalf("draft should have been promoted")
+	}
+	promoted, err := findIssueByNumber(p, number)
+	if err != nil {
+		t.Fatalf("find %s: %v", number, err)
+	}
+	if promoted.Issue.Draft || promoted.State != "open" || len(promoted.Issue.Labels) != 0 {
+		t.Fatalf("unexpected issue after promote: %+v", promoted)
+	}
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+// recordedLayout returns the layout routeIssues last arranged the issue
+// files by, or the empty string if it never recorded one.
+func recordedLayout(p paths.Paths) string {
+	data, err := os.ReadFile(p.LayoutPath)
+	if err != nil {
+		return ""
+	}
+	if layout := strings.TrimSpace(string(data)); paths.ValidLayout(layout) {
+		return layout
+	}
+	return ""
+}
+
+// recordedViews returns the names of the views routeIssues last arranged
+// the issue files by.
+func recordedViews(p paths.Paths) []string {
+	data, err := os.ReadFile(p.ViewsPath)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(string(data), "\n") {
+		name = strings.TrimSpace(name)
+		if name == "" || paths.ReservedName(name) || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// routeIssues moves every issue file that isn't where its state, labels
+// and milestone call for, so changing the layout or the views takes
+// effect on the next pull rather than only for issues that change. Only
+// the directories of the layout and of the one the files were arranged by
+// before are searched, so a directory that merely shares a name with one
+// of another layout is left alone. Issues in the directory of a view no
+// longer configured are moved back as well, as long as it was recorded;
+// other directories of the issues directory belong to the user. A file is
+// never moved onto an existing one. It returns how many files moved.
+func routeIssues(p paths.Paths) (int, error) {
+	if err := dropLegacyLinkIndexes(p); err != nil {
+		return 0, err
+	}
+	bases := []string{p.IssuesDir}
+	var views []string
+	for _, view := range p.Views {
+		bases = append(bases, view.Dir)
+		views = append(views, view.Name)
+	}
+	recordedNames := recordedViews(p)
+	for _, name := range recordedNames {
+		if !slices.Contains(views, name) {
+			bases = append(bases, filepath.Join(p.IssuesDir, name))
+		}
+	}
+	layout := p.Layout
+	if layout == "" {
+		layout = paths.LayoutState
+	}
+	// Mirrors from before the layout was recorded are arranged by state,
+	// the only layout back then
+	recorded := recordedLayout(p)
+	previous := recorded
+	if previous == "" {
+		previous = paths.LayoutState
+	}
+	searched := []string{layout}
+	if previous != layout {
+		searched = append(searched, previous)
+	}
+	var candidates []paths.StateDir
+	for _, base := range bases {
+		for _, layout := range searched {
+			candidates = append(candidates, paths.LayoutDirs(base, layout)...)
+		}
+	}
+	dirs, err := issueDirs(p, candidates)
+	if err != nil {
+		return 0, err
+	}
+
+	var misplaced []IssueFile
+	for _, dir := range dirs {
+		walkIssueDir(p, dir.Path, dir.State, true, func(item IssueFile) {
+			// Only issue files themselves move, never links to them
+			if info, err := os.Lstat(item.Path); err == nil && !info.Mode().IsRegular() {
+				return
+			}
+			if filepath.Dir(item.Path) != issueDir(p, item.State, item.Issue) {
+				misplaced = append(misplaced, item)
+			}
+		}, func(ParseError) {})
+	}
+	moved := 0
+	var blocked []error
+	for _, item := range misplaced {
+		newPath := filepath.Join(issueDir(p, item.State, item.Issue), filepath.Base(item.Path))
+		if _, err := readIssueData(p, newPath); !errors.Is(err, fs.ErrNotExist) {
+			blocked = append(blocked, fmt.Errorf("not moving %s onto the existing %s", relPath(p.Root, item.Path), relPath(p.Root, newPath)))
+			continue
+		}
+		if err := moveIssue(p, item.Path, newPath); err != nil {
+			return moved, err
+		}
+		moved++
+		if p.Layout != paths.LayoutFlat && p.Layout != paths.LayoutMilestone {
+			continue
+		}
+		// The front matter records the state from now on, and a file moved
+		// by hand out of open/ or closed/ may not say so yet
+		full, err := readIssue(p, newPath)
+		if err != nil {
+			return moved, err
+		}
+		if full.State != item.State {
+			full.State = item.State
+			if err := writeIssue(p, newPath, full); err != nil {
+				return moved, err
+			}
+		}
+	}
+	if len(blocked) > 0 {
+		// The layout and views are recorded once everything is in place,
+		// so the next run searches the same directories again
+		return moved, errors.Join(blocked...)
+	}
+	if recorded != layout {
+		if err := os.WriteFile(p.LayoutPath, []byte(layout+"\n"), 0o644); err != nil {
+			return moved, err
+		}
+	}
+	if !slices.Equal(recordedNames, views) {
+		data := ""
+		for _, name := range views {
+			data += name + "\n"
+		}
+		if err := os.WriteFile(p.ViewsPath, []byte(data), 0o644); err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// rearrangeIssues runs routeIssues, reporting what moved.
+func (a *App) rearrangeIssues(p paths.Paths) {
+	t := a.Theme
+	moved, err := routeIssues(p)
+	if err != nil {
+		fmt.Fprintf(a.Err, "%s rearranging issue files: %v\n", t.WarningText("Warning:"), err)
+	}
+	if moved > 0 {
+		fmt.Fprintf(a.Out, "%s\n", t.MutedText(fmt.Sprintf("Moved %d issue file(s) to match the layout and views", moved)))
+	}
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestLabelViews(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	cfg.Views = []config.View{{Name: "frontend", Label: "frontend"}, {Name: "backend", Label: "backend"}}
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	application.Theme = theme.Plain()
+	p = application.issuePaths()
+	if len(p.Views) != 2 || p.Views[0].Dir != filepath.Join(p.IssuesDir, "frontend") {
+		t.Fatalf("expected the configured views, got %+v", p.Views)
+	}
+
+	// The first matching view wins, labels match case-insensitively
+	if dir := issueDir(p, "closed", issue.Issue{Labels: []string{"Backend", "frontend"}}); dir != filepath.Join(p.Views[0].Dir, "closed") {
+		t.Fatalf("expected the frontend view, got %s", dir)
+	}
+	if dir := issueDir(p, "open", issue.Issue{Labels: []string{"bug"}}); dir != p.OpenDir {
+		t.Fatalf("expected the open directory, got %s", dir)
+	}
+
+	// Issues written before the views existed, and one in a view since removed
+	write := func(dir string, iss issue.Issue) {
+		path := issue.PathFor(dir, iss.Number, iss.Title)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := issue.WriteFile(path, iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+	write(p.OpenDir, issue.Issue{Number: "1", Title: "Button color", State: "open", Labels: []string{"frontend"}})
+	write(p.OpenDir, issue.Issue{Number: "2", Title: "Crash", State: "open", Labels: []string{"bug"}})
+	write(filepath.Join(p.IssuesDir, "docs", "closed"), issue.Issue{Number: "3", Title: "Typo", State: "closed", Labels: []string{"docs"}})
+	if err := os.WriteFile(p.ViewsPath, []byte("docs\nfrontend\n"), 0o644); err != nil {
+		t.Fatalf("record views: %v", err)
+	}
+	// A directory of the user's that was never a view is left alone
+	archived := issue.PathFor(filepath.Join(p.IssuesDir, "archive", "closed"), "2", "Crash")
+	write(filepath.Dir(archived), issue.Issue{Number: "2", Title: "Crash", State: "closed"})
+	moved, err := routeIssues(p)
+	if err != nil || moved != 2 {
+		t.Fatalf("expected two files moved, got %d %v", moved, err)
+	}
+	if _, err := os.Stat(archived); err != nil {
+		t.Fatalf("expected %s kept: %v", archived, err)
+	}
+	if data, err := os.ReadFile(p.ViewsPath); err != nil || string(data) != "frontend\nbackend\n" {
+		t.Fatalf("expected the views recorded, got %q %v", data, err)
+	}
+	for _, path := range []string{
+		issue.PathFor(filepath.Join(p.Views[0].Dir, "open"), "1", "Button color"),
+		issue.PathFor(p.OpenDir, "2", "Crash"),
+		issue.PathFor(p.ClosedDir, "3", "Typo"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s: %v", path, err)
+		}
+	}
+	items, err := loadLocalIssues(p)
+	if err != nil || len(items) != 3 {
+		t.Fatalf("expected all issues loaded, got %d %v", len(items), err)
+	}
+
+	// Closing keeps the issue in its view, relabeling moves it
+	ctx := context.Background()
+	if err := application.Close(ctx, "1", CloseOptions{}); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := application.updateLocalIssue(ctx, p, "1", func(iss *issue.Issue) { iss.Labels = []string{"backend"} }); err != nil {
+		t.Fatalf("relabel: %v", err)
+	}
+	file, err := findIssueByNumber(p, "1")
+	if err != nil || file.State != "closed" || file.Path != issue.PathFor(filepath.Join(p.Views[1].Dir, "closed"), "1", "Button color") {
+		t.Fatalf("expected #1 in the closed backend view, got %+v %v", file, err)
+	}
+
+	// A file is never moved onto another one
+	stray := issue.PathFor(filepath.Join(p.Views[1].Dir, "open"), "2", "Crash")
+	write(filepath.Dir(stray), issue.Issue{Number: "2", Title: "Crash", State: "open", Labels: []string{"bug"}, Body: "Stray copy"})
+	moved, err = routeIssues(p)
+	if moved != 0 || err == nil || !strings.Contains(err.Error(), "onto the existing") {
+		t.Fatalf("expected the move refused, got %d %v", moved, err)
+	}
+	if kept, err := issue.ParseFile(stray); err != nil || strings.TrimSpace(kept.Body) != "Stray copy" {
+		t.Fatalf("expected the stray file kept, got %+v %v", kept, err)
+	}
+}
+
+func TestViewConfigValidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	for _, views := range [][]config.View{
+		{{Name: "open", Label: "frontend"}},
+		{{Name: "a/b", Label: "frontend"}},
+		{{Name: "frontend"}},
+		{{Name: "web", Label: "frontend"}, {Name: "web", Label: "css"}},
+	} {
+		cfg := config.Default("owner", "repo")
+		cfg.Views = views
+		if err := config.Save(path, cfg); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := config.Load(path); err == nil || !strings.Contains(err.Error(), "view") {
+			t.Errorf("expected %+v to be rejected, got %v", views, err)
+		}
+	}
+}
+
+func TestIssueLayouts(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	cfg := config.Default("owner", "repo")
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	// #2 was moved into closed/ by hand, so only its directory says so
+	for _, iss := range []issue.Issue{
+		{Number: "1", Title: "Crash", State: "open", Milestone: "v1.0"},
+		{Number: "2", Title: "Typo", State: "open"},
+	} {
+		dir := p.OpenDir
+		if iss.Number == "2" {
+			dir = p.ClosedDir
+		}
+		if err := issue.WriteFile(issue.PathFor(dir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	application.Theme = theme.Plain()
+	ctx := context.Background()
+	cfg.Layout = paths.LayoutFlat
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	p = application.issuePaths()
+	if moved, err := routeIssues(p); err != nil || moved != 2 {
+		t.Fatalf("expected two files moved, got %d %v", moved, err)
+	}
+	flat := filepath.Join(p.IssuesDir, "all")
+	typo, err := issue.ParseFile(issue.PathFor(flat, "2", "Typo"))
+	if err != nil || typo.State != "closed" {
+		t.Fatalf("expected #2 in all/ recording its state, got %+v %v", typo, err)
+	}
+	if err := application.Close(ctx, "1", CloseOptions{}); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	file, err := findIssueByNumber(p, "1")
+	if err != nil || file.State != "closed" || file.Path != issue.PathFor(flat, "1", "Crash") {
+		t.Fatalf("expected #1 closed in place, got %+v %v", file, err)
+	}
+
+	cfg.Layout = paths.LayoutMilestone
+	if err := config.Save(p.ConfigPath, cfg); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	p = application.issuePaths()
+	if moved, err := routeIssues(p); err != nil || moved != 2 {
+		t.Fatalf("expected two files moved, got %d %v", moved, err)
+	}
+	items, err := loadLocalIssues(p)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("expected both issues loaded, got %d %v", len(items), err)
+	}
+	for _, item := range items {
+		want := filepath.Join(p.IssuesDir, "by-milestone", "no-milestone")
+		if item.Issue.Number == "1" {
+			want = filepath.Join(p.IssuesDir, "by-milestone", "v1-0")
+		}
+		if filepath.Dir(item.Path) != want || item.State != "closed" {
+			t.Errorf("expected #%s closed in %s, got %s %s", item.Issue.Number, want, item.Path, item.State)
+		}
+	}
+
+	// A milestone change moves the file
+	if err := application.updateLocalIssue(ctx, p, "2", func(iss *issue.Issue) { iss.Milestone = "v1.0" }); err != nil {
+		t.Fatalf("set milestone: %v", err)
+	}
+	if file, err := findIssueByNumber(p, "2"); err != nil || filepath.Base(filepath.Dir(file.Path)) != "v1-0" {
+		t.Fatalf("expected #2 moved to v1-0, got %+v %v", file, err)
+	}
+}
+
+func TestRouteIssuesSearchesOnlyArrangedLayouts(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	crash := issue.Issue{Number: "1", Title: "Crash", State: "open"}
+	crashPath := issue.PathFor(p.OpenDir, crash.Number, crash.Title)
+	if err := issue.WriteFile(crashPath, crash); err != nil {
+		t.Fatalf("write issue: %v", err)
+	}
+	// Directories of other layouts the files were never arranged by, and a
+	// link to an issue file
+	foreign := []string{
+		issue.PathFor(filepath.Join(p.IssuesDir, "all"), "2", "Notes"),
+		issue.PathFor(filepath.Join(p.IssuesDir, "by-milestone", "v1"), "3", "Plan"),
+	}
+	for _, path := range foreign {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := issue.WriteFile(path, issue.Issue{Number: "2", Title: "Notes", State: "open"}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	link := filepath.Join(p.ClosedDir, "1-crash.md")
+	if err := os.Symlink("../open/1-crash.md", link); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	if moved, err := routeIssues(p); err != nil || moved != 0 {
+		t.Fatalf("expected nothing moved, got %d %v", moved, err)
+	}
+	for _, path := range append(foreign, crashPath, link) {
+		if _, err := os.Lstat(path); err != nil {
+			t.Fatalf("expected %s kept: %v", path, err)
+		}
+	}
+	if info, err := os.Lstat(crashPath); err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("expected the issue file untouched, got %v", err)
+	}
+	if data, err := os.ReadFile(p.LayoutPath); err != nil || string(data) != "state\n" {
+		t.Fatalf("expected the layout recorded, got %q %v", data, err)
+	}
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/search"
+)
+
+type WatchOptions struct {
+	Interval time.Duration // Overrides watch.interval when set
+	Notify   []string      // Overrides watch.notify when set
+}
+
+// Watch pulls periodically until ctx is canceled. Issues the pull created
+// or updated that match one of the notify queries raise a desktop
+// notification.
+func (a *App) Watch(ctx context.Context, opts WatchOptions) error {
+	if _, ok := a.orgConfig(); ok {
+		return fmt.Errorf("watch is not supported in org mode; set %s to the repository's directory", paths.EnvIssuesDir)
+	}
+	p := a.issuePaths()
+	cfg, err := loadConfig(p.ConfigPath)
+	if err != nil {
+		return err
+	}
+	t := a.Theme
+
+	interval := opts.Interval
+	if interval <= 0 {
+		if interval, err = cfg.Watch.EffectiveInterval(); err != nil {
+			return err
+		}
+	}
+	filters := opts.Notify
+	if len(filters) == 0 {
+		filters = cfg.Watch.Notify
+	}
+	var queries []search.Query
+	for _, filter := range filters {
+		q := search.Parse(filter)
+		if q.Err != nil {
+			return fmt.Errorf("filter %q: %w", filter, q.Err)
+		}
+		queries = append(queries, q)
+	}
+	if strings.Contains(strings.Join(filters, " "), "@me") {
+		client, err := a.newProvider(cfg)
+		if err != nil {
+			return err
+		}
+		status, err := client.CheckAuth(ctx)
+		if err != nil {
+			return authError(status.CLI, err)
+		}
+		if status.User == "" {
+			return errors.New("cannot resolve @me: the logged in user is unknown")
+		}
+		for i := range queries {
+			resolveMe(&queries[i], status.User)
+		}
+	}
+
+	// Only changes pulled from now on are notified
+	var seen time.Time
+	if record, err := loadLastPull(p); err == nil {
+		seen = record.PulledAt
+	}
+	// Issues whose imminent SLA breach was already notified
+	warned := map[string]bool{}
+	notice := fmt.Sprintf("Watching %s every %s", repoSlug(cfg), interval)
+	if len(filters) > 0 {
+		notice += fmt.Sprintf(", notifying for %s", strings.Join(filters, " or "))
+	}
+	fmt.Fprintln(a.Out, t.MutedText(notice))
+
+	for {
+		if err := a.Pull(ctx, PullOptions{}, nil); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintf(a.Err, "%s %v\n", t.WarningText("Warning:"), err)
+		} else if record, err := loadLastPull(p); err == nil && record.PulledAt.After(seen) {
+			seen = record.PulledAt
+			a.notifyPulled(ctx, p, record, queries)
+		}
+		a.notifySLA(ctx, p, cfg, warned)
+		// Watching never finishes, so keep the indexes current as it goes
+		a.refreshLinkIndexes()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// notifyPulled sends a notification for every issue of record that
+// matches one of queries.
+func (a *App) notifyPulled(ctx context.Context, p paths.Paths, record LastPull, queries []search.Query) {
+	if len(queries) == 0 {
+		return
+	}
+	for _, action := range record.Actions {
+		file, err := findIssueByNumber(p, action.Number)
+		if err != nil {
+			continue
+		}
+		data := searchDataFor(p.Format, file)
+		matched := false
+		for i := range queries {
+			if queries[i].Match(data) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		title := fmt.Sprintf("#%s %s", action.Number, file.Issue.Title)
+		if err := sendNotification(ctx, title, pulledSummary(action)); err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Fprintf(a.Err, "%s sending notification for #%s: %v\n", a.Theme.WarningText("Warning:"), action.Number, err)
+		}
+	}
+}
+
+// notifySLA sends a notification for every issue about to breach an SLA
+// rule, once per issue.
+func (a *App) notifySLA(ctx context.Context, p paths.Paths, cfg config.Config, warned map[string]bool) {
+	now := a.Now()
+	entries, err := slaIssues(p, cfg, now)
+	if err != nil {
+		fmt.Fprintf(a.Err, "%s %v\n", a.Theme.WarningText("Warning:"), err)
+		return
+	}
+	for _, entry := range entries {
+		number := entry.Item.Issue.Number.String()
+		if entry.Breached || warned[number] {
+			continue
+		}
+		warned[number] = true
+		title := fmt.Sprintf("#%s %s", number, entry.Item.Issue.Title)
+		message := fmt.Sprintf("SLA due in %s (%s)", slaRemaining(entry, now), entry.Rule.Query)
+		if err := sendNotification(ctx, title, message); err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Fprintf(a.Err, "%s sending notification for #%s: %v\n", a.Theme.WarningText("Warning:"), number, err)
+		}
+	}
+}
+
+// pulledSummary describes a pulled change in a few words.
+func pulledSummary(action PlanAction) string {
+	switch action.Action {
+	case "create":
+		return "New issue"
+	case "close":
+		return "Closed"
+	case "reopen":
+		return "Reopened"
+	}
+	fields := make([]string, 0, len(action.Changes))
+	for _, change := range action.Changes {
+		fields = append(fields, change.Field)
+	}
+	if len(fields) == 0 {
+		return "Updated"
+	}
+	return "Updated " + strings.Join(fields, ", ")
+}
+
+// resolveMe replaces @me in the user qualifiers of q with login.
+func resolveMe(q *search.Query, login string) {
+	for _, values := range [][]string{q.Assignees, q.Authors, q.Mentions} {
+		for i, value := range values {
+			if strings.EqualFold(value, "@me") {
+				values[i] = login
+			}
+		}
+	}
+}
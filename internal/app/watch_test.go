@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/search"
+)
+
+func TestNotificationCommand(t *testing.T) {
+	name, args, env := notificationCommand("linux", "#7 Token expiry", "New issue")
+	if name != "notify-send" || !reflect.DeepEqual(args, []string{"--app-name=gh-issue-sync", "#7 Token expiry", "New issue"}) || env != nil {
+		t.Fatalf("linux: %s %v %v", name, args, env)
+	}
+	name, args, _ = notificationCommand("darwin", `#7 "quoted"`, "New issue")
+	if name != "osascript" || args[1] != `display notification "New issue" with title "#7 \"quoted\""` {
+		t.Fatalf("darwin: %s %v", name, args)
+	}
+	// The title never becomes part of the script, whatever quotes it has
+	title := "#7 it's ‘quoted’'); Remove-Item -Recurse ~ #"
+	name, args, env = notificationCommand("windows", title, "New issue")
+	if name != "powershell" || strings.Contains(args[len(args)-1], "Remove-Item") ||
+		!reflect.DeepEqual(env, []string{"GH_ISSUE_SYNC_NOTIFY_TITLE=" + title, "GH_ISSUE_SYNC_NOTIFY_MESSAGE=New issue"}) {
+		t.Fatalf("windows: %s %v %v", name, args, env)
+	}
+}
+
+func TestNotifyPulled(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	for _, iss := range []issue.Issue{
+		{Number: "7", Title: "Token expiry", State: "open", Assignees: []string{"alice"}},
+		{Number: "8", Title: "Outage", State: "open", Labels: []string{"incident"}},
+		{Number: "9", Title: "Typo", State: "open"},
+	} {
+		if err := issue.WriteFile(issue.PathFor(p.OpenDir, iss.Number, iss.Title), iss); err != nil {
+			t.Fatalf("write issue: %v", err)
+		}
+	}
+
+	var sent []string
+	previous := execCommandEnv
+	execCommandEnv = func(ctx context.Context, env []string, name string, args ...string) (string, error) {
+		sent = append(sent, strings.Join(args, " "))
+		return "", nil
+	}
+	t.Cleanup(func() { execCommandEnv = previous })
+
+	queries := []search.Query{search.Parse("assignee:@me"), search.Parse("label:incident")}
+	resolveMe(&queries[0], "alice")
+	record := LastPull{Actions: []PlanAction{
+		{Kind: "issue", Action: "update", Number: "7", Changes: []FieldChange{{Field: "labels"}, {Field: "comments"}}},
+		{Kind: "issue", Action: "create", Number: "8"},
+		{Kind: "issue", Action: "close", Number: "9"},
+	}}
+	application := New(root, &offlineRunner{}, io.Discard, io.Discard)
+	application.notifyPulled(context.Background(), p, record, queries)
+
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 notifications, got %v", sent)
+	}
+	if !strings.Contains(sent[0], "#7 Token expiry") || !strings.Contains(sent[0], "Updated labels, comments") {
+		t.Errorf("unexpected notification: %s", sent[0])
+	}
+	if !strings.Contains(sent[1], "#8 Outage") || !strings.Contains(sent[1], "New issue") {
+		t.Errorf("unexpected notification: %s", sent[1])
+	}
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookEvent is something pull saw that webhooks can subscribe to.
+type webhookEvent struct {
+	Name  string // One of the config.Event* constants
+	Issue issue.Issue
+}
+
+var webhookDescriptions = map[string]string{
+	config.EventNewIssue:     "New issue",
+	config.EventConflict:     "Conflict",
+	config.EventAssignedToMe: "Assigned to me",
+}
+
+// pullEvents returns the events of a pull. New issues are not reported on
+// the first pull, which would announce the whole repository.
+func (a *App) pullEvents(ctx context.Context, cfg config.Config, firstPull bool, applied []pulledIssue, conflicts []issue.Issue) []webhookEvent {
+	var events []webhookEvent
+	me := ""
+	for _, hook := range cfg.Webhooks {
+		if hook.Wants(config.EventAssignedToMe) {
+			if client, err := a.newProvider(cfg); err == nil {
+				if status, err := client.CheckAuth(ctx); err == nil {
+					me = status.User
+				}
+			}
+			break
+		}
+	}
+	for _, change := range applied {
+		if !change.hasLocal && !firstPull {
+			events = append(events, webhookEvent{Name: config.EventNewIssue, Issue: change.updated})
+		}
+		if me != "" && containsFold(change.updated.Assignees, me) && !(change.hasLocal && containsFold(change.local.Issue.Assignees, me)) {
+			events = append(events, webhookEvent{Name: config.EventAssignedToMe, Issue: change.updated})
+		}
+	}
+	for _, iss := range conflicts {
+		events = append(events, webhookEvent{Name: config.EventConflict, Issue: iss})
+	}
+	return events
+}
+
+// postWebhooks sends every event to the webhooks that want it. Failures
+// are reported as warnings and never fail the pull.
+func (a *App) postWebhooks(ctx context.Context, cfg config.Config, events []webhookEvent) {
+	if len(cfg.Webhooks) == 0 || len(events) == 0 {
+		return
+	}
+	vars := a.templateVars(ctx, cfg)
+	for _, event := range events {
+		number := event.Issue.Number.String()
+		eventVars := vars.
+			with("event", event.Name).
+			with("description", webhookDescriptions[event.Name]).
+			with("number", number).
+			with("title", event.Issue.Title).
+			with("url", issueURL(cfg, number))
+		for _, hook := range cfg.Webhooks {
+			if !hook.Wants(event.Name) {
+				continue
+			}
+			if err := postWebhook(ctx, hook.URL, eventVars.expand(hook.EffectiveTemplate())); err != nil {
+				fmt.Fprintf(a.Err, "%s webhook for #%s: %v\n", a.Theme.WarningText("Warning:"), number, err)
+			}
+		}
+	}
+}
+
+func postWebhook(ctx context.Context, url, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
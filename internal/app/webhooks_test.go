@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+func TestPullWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], payload.Text)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cfg := config.Default("owner", "repo")
+	cfg.Webhooks = []config.Webhook{
+		{URL: server.URL + "/all"},
+		{URL: server.URL + "/conflicts", Events: []string{config.EventConflict}, Template: "{{event}} on #{{number}}"},
+	}
+	added := issue.Issue{Number: "8", Title: "Outage", State: "open"}
+	updated := issue.Issue{Number: "7", Title: "Token expiry", State: "open"}
+	applied := []pulledIssue{
+		{remote: added, updated: added},
+		{local: IssueFile{Issue: updated}, hasLocal: true, remote: updated, updated: updated},
+	}
+	conflicts := []issue.Issue{{Number: "9", Title: "Typo", State: "open"}}
+
+	application := New(t.TempDir(), &offlineRunner{}, io.Discard, io.Discard)
+	if events := application.pullEvents(context.Background(), cfg, true, applied, nil); len(events) != 0 {
+		t.Fatalf("the first pull should not announce issues: %+v", events)
+	}
+	events := application.pullEvents(context.Background(), cfg, false, applied, conflicts)
+	application.postWebhooks(context.Background(), cfg, events)
+
+	want := map[string][]string{
+		"/all": {
+			"New issue in owner/repo: #8 Outage https://github.com/owner/repo/issues/8",
+			"Conflict in owner/repo: #9 Typo https://github.com/owner/repo/issues/9",
+		},
+		"/conflicts": {"conflict on #9"},
+	}
+	if !reflect.DeepEqual(received, want) {
+		t.Fatalf("received %v, want %v", received, want)
+	}
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+)
+
+type WhatsNewOptions struct {
+	JSON bool
+}
+
+// LastPull records the issues written by the most recent pull that changed
+// anything, so whatsnew can show them again after the output scrolled away.
+type LastPull struct {
+	PulledAt time.Time    `json:"pulled_at"`
+	Actions  []PlanAction `json:"actions"`
+}
+
+// pulledAction describes an applied pull change like a pull plan does. The
+// body is summarized and new comments are recorded as a "comments" change
+// from the old to the new count.
+func pulledAction(change pulledIssue) PlanAction {
+	updated := change.updated
+	action := PlanAction{Kind: "issue", Action: "create", Number: updated.Number.String(), Title: updated.Title}
+	if !change.hasLocal {
+		return action
+	}
+	old := change.local.Issue
+	action.Action = issueAction(old, updated)
+	action.Changes = fieldChanges(old, updated, issue.ComputeChanges(old, updated).Fields())
+	for i := range action.Changes {
+		if action.Changes[i].Field == "body" {
+			action.Changes[i].Old = formatBodySummary(issue.PublicBody(old.Body))
+			action.Changes[i].New = formatBodySummary(issue.PublicBody(updated.Body))
+		}
+	}
+	if updated.CommentCount > old.CommentCount {
+		action.Changes = append(action.Changes, FieldChange{Field: "comments", Old: old.CommentCount, New: updated.CommentCount})
+	}
+	return action
+}
+
+func saveLastPull(p paths.Paths, now time.Time, applied []pulledIssue) error {
+	record := LastPull{PulledAt: now, Actions: make([]PlanAction, 0, len(applied))}
+	for _, change := range applied {
+		record.Actions = append(record.Actions, pulledAction(change))
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(p.LastPullPath, data, 0o644)
+}
+
+func loadLastPull(p paths.Paths) (LastPull, error) {
+	var record LastPull
+	data, err := os.ReadFile(p.LastPullPath)
+	if err != nil {
+		return record, err
+	}
+	err = json.Unmarshal(data, &record)
+	return record, err
+}
+
+// WhatsNew shows the changes applied by the last pull that changed anything.
+func (a *App) WhatsNew(opts WhatsNewOptions) error {
+	p := a.issuePaths()
+	if _, err := loadConfig(p.ConfigPath); err != nil {
+		return err
+	}
+	t := a.Theme
+
+	record, err := loadLastPull(p)
+	if errors.Is(err, os.ErrNotExist) {
+		if opts.JSON {
+			_, err := fmt.Fprintln(a.Out, "null")
+			return err
+		}
+		fmt.Fprintln(a.Out, t.MutedText("No changes pulled yet"))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", relPath(a.Root, p.LastPullPath), err)
+	}
+	if opts.JSON {
+		data, err := json.MarshalIndent(record, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(a.Out, "%s\n", data)
+		return err
+	}
+
+	labelColors := map[string]string{}
+	if cache, err := loadLabelCache(p); err == nil {
+		labelColors = labelCacheToColorMap(cache)
+	}
+	fmt.Fprintln(a.Out, t.MutedText(fmt.Sprintf("Pulled %s (%s), %d issue(s) changed",
+		record.PulledAt.Local().Format("2006-01-02 15:04"), formatRelativeTime(a.Now(), record.PulledAt), len(record.Actions))))
+	for _, action := range record.Actions {
+		status := "U"
+		if action.Action == "create" {
+			status = "A"
+		}
+		fmt.Fprintln(a.Out, t.FormatIssueHeader(status, action.Number, action.Title))
+		for _, change := range action.Changes {
+			fmt.Fprintln(a.Out, a.formatFieldChange(change, labelColors))
+		}
+	}
+	return nil
+}
+
+// formatFieldChange renders a recorded change the way pull prints it.
+func (a *App) formatFieldChange(change FieldChange, labelColors map[string]string) string {
+	t := a.Theme
+	field := t.Styler().Fg(t.FieldName, change.Field+": ")
+	switch change.Field {
+	case "labels":
+		added, removed := diffLabelColors(labelsToTheme(anyStrings(change.Old), labelColors), labelsToTheme(anyStrings(change.New), labelColors))
+		return "    " + field + t.FormatLabelChange(added, removed)
+	case "body":
+		return "    " + field + t.MutedText(fmt.Sprintf("changed (%v -> %v)", change.Old, change.New))
+	case "comments":
+		oldCount, _ := change.Old.(float64)
+		newCount, _ := change.New.(float64)
+		return "    " + field + t.AccentText(fmt.Sprintf("+%d", int(newCount-oldCount))) + t.MutedText(fmt.Sprintf(" (%d total)", int(newCount)))
+	}
+	return t.FormatChange(change.Field, formatAnyValue(change.Old), formatAnyValue(change.New))
+}
+
+// anyStrings converts a JSON decoded list of strings.
+func anyStrings(value any) []string {
+	items, _ := value.([]any)
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+func formatAnyValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []any:
+		return formatStringList(anyStrings(v))
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/config"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
+	"github.com/mitsuhiko/gh-issue-sync/internal/theme"
+)
+
+func TestWhatsNew(t *testing.T) {
+	root := t.TempDir()
+	p := paths.New(root)
+	if err := p.EnsureLayout(); err != nil {
+		t.Fatalf("layout: %v", err)
+	}
+	if err := config.Save(p.ConfigPath, config.Default("owner", "repo")); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+
+	var out bytes.Buffer
+	application := New(root, &offlineRunner{}, &out, &out)
+	application.Theme = theme.Plain()
+	if err := application.WhatsNew(WhatsNewOptions{}); err != nil {
+		t.Fatalf("whatsnew: %v", err)
+	}
+	if !strings.Contains(out.String(), "No changes pulled yet") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	old := issue.Issue{Number: "7", Title: "Token expiry", State: "open", Labels: []string{"bug"}, CommentCount: 2}
+	updated := old
+	updated.State = "closed"
+	updated.Labels = []string{"bug", "auth"}
+	updated.CommentCount = 5
+	added := issue.Issue{Number: "8", Title: "Rotate keys", State: "open"}
+	pulledAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	applied := []pulledIssue{
+		{local: IssueFile{Issue: old}, hasLocal: true, remote: updated, updated: updated},
+		{remote: added, updated: added},
+	}
+	if err := saveLastPull(p, pulledAt, applied); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out.Reset()
+	application.Now = func() time.Time { return pulledAt.Add(2 * time.Hour) }
+	if err := application.WhatsNew(WhatsNewOptions{}); err != nil {
+		t.Fatalf("whatsnew: %v", err)
+	}
+	for _, want := range []string{
+		"(2 hours ago), 2 issue(s) changed",
+		"U Issue #7: Token expiry",
+		`state: "open" -> "closed"`,
+		"labels: + auth",
+		"comments: +3 (5 total)",
+		"A Issue #8: Rotate keys",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := application.WhatsNew(WhatsNewOptions{JSON: true}); err != nil {
+		t.Fatalf("whatsnew --json: %v", err)
+	}
+	if !strings.Contains(out.String(), `"action": "close"`) {
+		t.Fatalf("expected a close action:\n%s", out.String())
+	}
+}
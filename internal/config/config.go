@@ -2,23 +2,458 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/paths"
 )
 
 type Config struct {
 	Repository RepoConfig `json:"repository"`
 	Sync       SyncConfig `json:"sync,omitempty"`
+	Push       PushConfig `json:"push,omitempty"`
+	Encryption Encryption `json:"encryption,omitempty"`
+	// Vault writes issue files so .issues can be opened as an Obsidian or
+	// Foam vault.
+	Vault bool `json:"vault,omitempty"`
+	// Templates configures the templates in .issues/templates by issue type.
+	Templates map[string]TemplateConfig `json:"templates,omitempty"`
+	// Variables are custom {{name}} variables for templates and recurring
+	// issues. They take precedence over the built-in ones.
+	Variables map[string]string `json:"variables,omitempty"`
+	// LabelRules infer labels from the files a pull request or commit
+	// changes, for new --from-pr and --from-commit.
+	LabelRules []LabelRule `json:"label_rules,omitempty"`
+	// Capabilities caches which optional forge features are available. It
+	// is kept in state.json, see State.
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+	// Storage selects where issue files and originals are kept.
+	Storage StorageConfig `json:"storage,omitzero"`
+	// Editor overrides the editor detected from the environment.
+	Editor EditorConfig `json:"editor,omitzero"`
+	// Todo configures the drafts captured with todo.
+	Todo TodoConfig `json:"todo,omitzero"`
+	// Watch configures the polling and notifications of watch.
+	Watch WatchConfig `json:"watch,omitzero"`
+	// Webhooks are posted to when pull (or watch) sees one of their events.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	// Priority maps the priority front matter field to labels.
+	Priority PriorityConfig `json:"priority,omitzero"`
+	// SLA rules flag open issues that go without a response for too long.
+	SLA []SLARule `json:"sla,omitempty"`
+	// ReadOnly disables everything that writes to the tracker: push, close,
+	// reopen, comments, and label and milestone changes.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// Auth selects the tokens reads and writes use.
+	Auth AuthConfig `json:"auth,omitzero"`
+	// Local configures the IDs of issues that were not pushed yet.
+	Local LocalConfig `json:"local,omitzero"`
+	// Checkers are external commands such as vale or markdownlint that
+	// lint and push run on changed issue bodies.
+	Checkers []Checker `json:"checkers,omitempty"`
+	// Translate configures the command translate runs.
+	Translate TranslateConfig `json:"translate,omitzero"`
+	// Summarize configures the command summarize runs.
+	Summarize SummarizeConfig `json:"summarize,omitzero"`
+	// Layout arranges the issue files: "state" (the default) in open/ and
+	// closed/, "flat" all in all/, or "milestone" in
+	// by-milestone/<milestone>/. The last two record the state only in the
+	// front matter, so closing an issue doesn't rename its file.
+	Layout string `json:"layout,omitempty"`
+	// Views route the issues with a label into a subdirectory of .issues,
+	// e.g. .issues/frontend for label:frontend. The first matching view
+	// wins; issues matching none stay in .issues/open and .issues/closed.
+	Views []View `json:"views,omitempty"`
+	// Indexes configures the directories of links to issue files by
+	// label, assignee or milestone.
+	Indexes IndexesConfig `json:"indexes,omitzero"`
+	// Board configures the kanban board written by board and pull.
+	Board BoardConfig `json:"board,omitzero"`
+}
+
+// BoardConfig configures BOARD.md, a kanban board of the open issues
+// grouped into columns by a label prefix.
+type BoardConfig struct {
+	// LabelPrefix selects the labels that name the column of an issue,
+	// e.g. "status/" for "status/In progress". Empty disables the board.
+	LabelPrefix string `json:"label_prefix,omitempty"`
+	// Columns are the columns in order, shown even when empty. Columns of
+	// other labels with the prefix follow.
+	Columns []string `json:"columns,omitempty"`
+	// Project limits the board to the issues of a project, by title.
+	Project string `json:"project,omitempty"`
+	// Path is where the board is written, relative to the directory
+	// holding .issues (default BOARD.md).
+	Path string `json:"path,omitempty"`
+	// Apply makes pull apply edits to the board as local changes before
+	// writing it again, instead of leaving an edited board alone.
+	Apply bool `json:"apply,omitempty"`
+}
+
+// Ways index entries can point at issue files, see IndexesConfig.Links.
+const (
+	IndexLinksSymlink  = "symlink"
+	IndexLinksHardlink = "hardlink"
+	IndexLinksCopy     = "copy"
+)
+
+// IndexesConfig configures the link indexes, e.g. .issues/index/by-label/bug/
+// holding a link to every issue labelled bug. They are rebuilt whenever a
+// command changed issue files.
+type IndexesConfig struct {
+	// By lists what to index: label, assignee and/or milestone.
+	By []string `json:"by,omitempty"`
+	// Links is how entries point at issue files: symlink (the default),
+	// hardlink where symlinks need privileges, as on Windows, or copy.
+	Links string `json:"links,omitempty"`
+}
+
+// View is a label view, see Config.Views.
+type View struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// TranslateConfig names the command that translates issue text, e.g. a
+// script calling a translation API. It gets the text on stdin and prints
+// the translation. {to} in the command stands for the target language,
+// which is appended as the last argument otherwise.
+type TranslateConfig struct {
+	Command string `json:"command,omitempty"`
+}
+
+// SummarizeConfig names the command that summarizes an issue thread, e.g. a
+// script sending it to a language model. It gets the thread as Markdown on
+// stdin and prints the summary.
+type SummarizeConfig struct {
+	Command string `json:"command,omitempty"`
+}
+
+// Checker is an external command that checks an issue body. It is run with
+// the path of a Markdown file holding the body appended to Command and
+// reports problems by printing them and exiting non-zero.
+type Checker struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+// Local ID styles for LocalConfig.IDs.
+const (
+	LocalIDsRandom     = "random"
+	LocalIDsSequential = "sequential"
+)
+
+// LocalConfig selects how new local issues are numbered: random IDs like
+// T1a2b3c4d (the default) that never collide, or sequential IDs T1, T2, ...
+// that are easier to type and say.
+type LocalConfig struct {
+	IDs string `json:"ids,omitempty"`
+	// NextLocalID is the next sequential ID to hand out. It is kept in
+	// state.json, see State.
+	NextLocalID int `json:"-"`
+}
+
+// Sequential reports whether new local issues get sequential IDs.
+func (c LocalConfig) Sequential() bool {
+	return c.IDs == LocalIDsSequential
+}
+
+// AuthConfig names environment variables holding the token gh (or glab)
+// runs with, so pulls can use a broad read token while pushes require a
+// write token that is provided explicitly. Without a name the logged in
+// account is used.
+type AuthConfig struct {
+	ReadTokenEnv  string `json:"read_token_env,omitempty"`
+	WriteTokenEnv string `json:"write_token_env,omitempty"`
+}
+
+// SLARule requires a response to the issues matching Query within a time
+// after they were opened. An issue counts as responded to once it has a
+// comment.
+type SLARule struct {
+	// Query is a search query such as "label:incident".
+	Query string `json:"query"`
+	// RespondWithin is a duration such as "24h" or "3d".
+	RespondWithin string `json:"respond_within"`
+}
+
+// Window parses RespondWithin. Besides Go durations it accepts whole days
+// such as "3d".
+func (r SLARule) Window() (time.Duration, error) {
+	window, err := time.ParseDuration(r.RespondWithin)
+	if days, ok := strings.CutSuffix(r.RespondWithin, "d"); ok && err != nil {
+		if n, atoiErr := strconv.Atoi(days); atoiErr == nil {
+			window, err = time.Duration(n)*24*time.Hour, nil
+		}
+	}
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("invalid sla.respond_within %q for %q", r.RespondWithin, r.Query)
+	}
+	return window, nil
+}
+
+// PriorityConfig enables the priority front matter field.
+type PriorityConfig struct {
+	// LabelPrefix selects the labels that carry the priority, e.g.
+	// "priority/" for "priority/P1". Empty disables the field.
+	LabelPrefix string `json:"label_prefix,omitempty"`
+	// Levels are the priorities from most to least urgent, used for
+	// sorting and colors (default P0 to P3).
+	Levels []string `json:"levels,omitempty"`
+}
+
+// Webhook events.
+const (
+	EventNewIssue     = "new_issue"
+	EventConflict     = "conflict"
+	EventAssignedToMe = "assigned_to_me"
+)
+
+// DefaultWebhookTemplate is the message of webhooks without a template.
+const DefaultWebhookTemplate = "{{description}} in {{repo}}: #{{number}} {{title}} {{url}}"
+
+// Webhook is a Slack-compatible incoming webhook. Messages are posted as
+// {"text": "..."}.
+type Webhook struct {
+	URL string `json:"url"`
+	// Events selects the events to post; all of them if empty.
+	Events []string `json:"events,omitempty"`
+	// Template is the message text. It can use {{event}}, {{description}},
+	// {{number}}, {{title}}, {{url}}, {{repo}}, and the custom variables.
+	Template string `json:"template,omitempty"`
+}
+
+// Wants reports whether the webhook subscribed to event.
+func (w Webhook) Wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// EffectiveTemplate returns the configured template or
+// DefaultWebhookTemplate.
+func (w Webhook) EffectiveTemplate() string {
+	if w.Template != "" {
+		return w.Template
+	}
+	return DefaultWebhookTemplate
+}
+
+// DefaultWatchInterval is the time between pulls in watch.
+const DefaultWatchInterval = 5 * time.Minute
+
+// WatchConfig configures watch.
+type WatchConfig struct {
+	// Interval is a duration such as "2m"; DefaultWatchInterval if empty.
+	Interval string `json:"interval,omitempty"`
+	// Notify are search queries such as "assignee:@me" or
+	// "label:incident". New or updated issues matching any of them raise a
+	// desktop notification.
+	Notify []string `json:"notify,omitempty"`
+}
+
+// EffectiveInterval returns the configured interval or
+// DefaultWatchInterval.
+func (c WatchConfig) EffectiveInterval() (time.Duration, error) {
+	if c.Interval == "" {
+		return DefaultWatchInterval, nil
+	}
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid watch.interval %q", c.Interval)
+	}
+	return interval, nil
+}
+
+// DefaultTodoLabel marks the drafts captured with todo.
+const DefaultTodoLabel = "todo"
+
+// TodoConfig configures quick capture.
+type TodoConfig struct {
+	// Label marks captured drafts until they are promoted; DefaultTodoLabel
+	// if empty.
+	Label string `json:"label,omitempty"`
+}
+
+// EffectiveLabel returns the configured label or DefaultTodoLabel.
+func (c TodoConfig) EffectiveLabel() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return DefaultTodoLabel
+}
+
+// EditorConfig selects the editor for issue and comment buffers. Commands
+// may contain arguments, e.g. "code --wait".
+type EditorConfig struct {
+	// Command is used by every command that opens an editor.
+	Command string `json:"command,omitempty"`
+	// Commands overrides Command for single commands, keyed by command
+	// name ("new", "edit", "comment").
+	Commands map[string]string `json:"commands,omitempty"`
+}
+
+// For returns the configured editor for a command, or "" to fall back to
+// $VISUAL, $EDITOR, and git's core.editor.
+func (e EditorConfig) For(command string) string {
+	if editor := strings.TrimSpace(e.Commands[command]); editor != "" {
+		return editor
+	}
+	return strings.TrimSpace(e.Command)
 }
 
+// StorageConfig selects the storage backend for issue files and their
+// originals. Config, caches, and pending comments always stay in .issues.
+type StorageConfig struct {
+	// Backend is "" for plain files in .issues, "sqlite", or "git".
+	Backend string `json:"backend,omitempty"`
+	// Path is the SQLite database, relative to .issues (default issues.db).
+	Path string `json:"path,omitempty"`
+	// Branch is the branch the git backend commits to (default issues).
+	Branch string `json:"branch,omitempty"`
+	// Originals is OriginalsFull (default) to keep a complete copy of each
+	// pulled issue, or OriginalsHashed to keep the bodies as deduplicated
+	// blobs in .sync/blobs. gc migrates existing originals.
+	Originals string `json:"originals,omitempty"`
+}
+
+// Formats of the originals in .sync/originals.
+const (
+	OriginalsFull   = "full"
+	OriginalsHashed = "hashed"
+)
+
+// Supported issue tracker providers.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
 type RepoConfig struct {
 	Owner string `json:"owner"`
 	Repo  string `json:"repo"`
+	// Provider selects the issue tracker backend. Empty means GitHub.
+	Provider string `json:"provider,omitempty"`
+	// Host is the forge hostname for self-managed instances (GitLab only).
+	Host string `json:"host,omitempty"`
 }
 
 type SyncConfig struct {
+	// LastFullPull is kept in state.json, see State.
 	LastFullPull *time.Time `json:"last_full_pull,omitempty"`
+	// MergedState is the local_state pull gives issues it sees closed by a
+	// merged pull request, e.g. "done-pending-release". Empty leaves them
+	// plainly closed.
+	MergedState string `json:"merged_state,omitempty"`
+	// Comments makes pull keep the comments of every issue in
+	// .sync/comments.
+	Comments bool `json:"comments,omitempty"`
+	// Snapshots is the number of past remote versions of each issue pull
+	// keeps in .sync/snapshots for diff --at. 0 keeps none.
+	Snapshots int `json:"snapshots,omitempty"`
+	// Mtimes makes pull set the modification time of every issue file to
+	// when the issue was last updated, so files sort by activity.
+	Mtimes bool `json:"mtimes,omitempty"`
+	// CommentCursors are kept in state.json, see State.
+	CommentCursors map[string]CommentCursor `json:"-"`
+}
+
+// CommentCursor records how far the comments of an issue have been pulled.
+// Since is the newest comment update seen, so the next pull only asks for
+// comments updated after it; the comments are only fetched again once the
+// issue was updated after IssueUpdatedAt.
+type CommentCursor struct {
+	Since          *time.Time `json:"since,omitempty"`
+	IssueUpdatedAt *time.Time `json:"issue_updated_at,omitempty"`
+}
+
+// DefaultMassChangeThreshold is the number of closes or retitles a single
+// push may perform before --allow-mass-changes is required.
+const DefaultMassChangeThreshold = 10
+
+type PushConfig struct {
+	// MassChangeThreshold overrides DefaultMassChangeThreshold when set.
+	MassChangeThreshold int `json:"mass_change_threshold,omitempty"`
+}
+
+// EffectiveMassChangeThreshold returns the configured threshold or the default.
+func (c PushConfig) EffectiveMassChangeThreshold() int {
+	if c.MassChangeThreshold > 0 {
+		return c.MassChangeThreshold
+	}
+	return DefaultMassChangeThreshold
+}
+
+// Capabilities is the cached result of probing the forge for optional
+// features, refreshed by auth status or once it is older than a week.
+type Capabilities struct {
+	IssueTypes bool      `json:"issue_types"`
+	Projects   bool      `json:"projects"`
+	SubIssues  bool      `json:"sub_issues"`
+	ProbedAt   time.Time `json:"probed_at"`
+}
+
+// Supported tools for encrypting local notes.
+const (
+	EncryptionAge = "age"
+	EncryptionGPG = "gpg"
+)
+
+// Encryption configures how local-notes blocks are encrypted so .issues can
+// be committed to a shared repository.
+type Encryption struct {
+	// Tool is "age" (default) or "gpg".
+	Tool string `json:"tool,omitempty"`
+	// Recipients are age public keys or GPG key IDs/emails.
+	Recipients []string `json:"recipients,omitempty"`
+	// Identity is the age identity file used for decryption. GPG uses the
+	// default keyring instead.
+	Identity string `json:"identity,omitempty"`
+}
+
+// EffectiveTool returns the configured tool or age.
+func (e Encryption) EffectiveTool() string {
+	if e.Tool == "" {
+		return EncryptionAge
+	}
+	return e.Tool
+}
+
+// LabelRule adds labels when a changed file matches one of the patterns.
+// Patterns are globs against the path relative to the repository, where **
+// matches any number of directories; a pattern without a slash matches the
+// file name in any directory.
+type LabelRule struct {
+	Paths  []string `json:"paths"`
+	Labels []string `json:"labels"`
+}
+
+// TemplateConfig holds the rules for issues of one type.
+type TemplateConfig struct {
+	// RequiredSections are Markdown headings that must be present and
+	// filled in before push creates or updates an issue of this type.
+	RequiredSections []string `json:"required_sections,omitempty"`
+}
+
+// TemplateFor returns the template configuration for an issue type,
+// matching the type name case-insensitively.
+func (c Config) TemplateFor(issueType string) (TemplateConfig, bool) {
+	if issueType == "" {
+		return TemplateConfig{}, false
+	}
+	if tc, ok := c.Templates[issueType]; ok {
+		return tc, true
+	}
+	for name, tc := range c.Templates {
+		if strings.EqualFold(name, issueType) {
+			return tc, true
+		}
+	}
+	return TemplateConfig{}, false
 }
 
 func Default(owner, repo string) Config {
@@ -27,6 +462,25 @@ func Default(owner, repo string) Config {
 	}
 }
 
+// State is the sync state that changes on every pull. It is kept per
+// machine in state.json next to the config (and ignored by git), so mirrors
+// committed from several machines don't conflict on it. Load and Save move
+// it in and out of Config.
+type State struct {
+	LastFullPull   *time.Time               `json:"last_full_pull,omitempty"`
+	Capabilities   *Capabilities            `json:"capabilities,omitempty"`
+	CommentCursors map[string]CommentCursor `json:"comment_cursors,omitempty"`
+	NextLocalID    int                      `json:"next_local_id,omitempty"`
+}
+
+// StatePath returns the state.json belonging to the config at path.
+func StatePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), paths.StateFileName)
+}
+
+// Load reads the config at path and the state next to it. Configs written
+// before the state moved out still carry it and are used as they are
+// until the next Save.
 func Load(path string) (Config, error) {
 	var cfg Config
 	data, err := os.ReadFile(path)
@@ -36,14 +490,91 @@ func Load(path string) (Config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return cfg, fmt.Errorf("failed to parse config: %w", err)
 	}
+	if !paths.ValidLayout(cfg.Layout) {
+		return cfg, fmt.Errorf("unknown layout %q in config: must be %s, %s or %s", cfg.Layout, paths.LayoutState, paths.LayoutFlat, paths.LayoutMilestone)
+	}
+	if err := validateViews(cfg.Views); err != nil {
+		return cfg, err
+	}
+	if err := validateIndexes(cfg); err != nil {
+		return cfg, err
+	}
+	data, err = os.ReadFile(StatePath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return cfg, fmt.Errorf("failed to parse %s: %w", paths.StateFileName, err)
+	}
+	cfg.Sync.LastFullPull = state.LastFullPull
+	cfg.Capabilities = state.Capabilities
+	cfg.Sync.CommentCursors = state.CommentCursors
+	cfg.Local.NextLocalID = state.NextLocalID
 	return cfg, nil
 }
 
+// validateViews checks that every view names a label and a directory of
+// its own.
+func validateViews(views []View) error {
+	seen := map[string]bool{}
+	for _, view := range views {
+		name := view.Name
+		if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") || paths.ReservedName(name) {
+			return fmt.Errorf("invalid view name %q in config: must be a plain directory name not used by the issues directory itself, such as open or drafts", name)
+		}
+		if strings.TrimSpace(view.Label) == "" {
+			return fmt.Errorf("view %q in config has no label", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("view %q is configured twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// validateIndexes checks the link indexes against the known kinds and the
+// layout.
+func validateIndexes(cfg Config) error {
+	for _, by := range cfg.Indexes.By {
+		switch by {
+		case "label", "assignee":
+		case "milestone":
+			if cfg.Layout == paths.LayoutMilestone {
+				return errors.New("indexes by milestone in config: the milestone layout already arranges issues by milestone")
+			}
+		default:
+			return fmt.Errorf("unknown index %q in config: must be label, assignee or milestone", by)
+		}
+	}
+	switch cfg.Indexes.Links {
+	case "", IndexLinksSymlink, IndexLinksHardlink, IndexLinksCopy:
+		return nil
+	}
+	return fmt.Errorf("unknown index links %q in config: must be %s, %s or %s", cfg.Indexes.Links, IndexLinksSymlink, IndexLinksHardlink, IndexLinksCopy)
+}
+
+// Save writes cfg to path and its State to state.json.
 func Save(path string, cfg Config) error {
+	state := State{LastFullPull: cfg.Sync.LastFullPull, Capabilities: cfg.Capabilities, CommentCursors: cfg.Sync.CommentCursors, NextLocalID: cfg.Local.NextLocalID}
+	cfg.Sync.LastFullPull = nil
+	cfg.Capabilities = nil
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
 	}
 	data = append(data, '\n')
-	return os.WriteFile(path, data, 0o644)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(StatePath(path), data, 0o644)
 }
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+)
+
+// Org configures org mode, where .issues/<repo>/ mirrors every matching
+// repository of an organization. Each of those directories is a regular
+// issues directory with its own config.
+type Org struct {
+	Org string `json:"org"`
+	// Include and Exclude are glob patterns matched against repository
+	// names. An empty Include matches every repository.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	// Repos are the repositories mirrored so far, refreshed on every pull.
+	Repos []string `json:"repos"`
+}
+
+// Matches reports whether a repository name passes the include and
+// exclude globs.
+func (o Org) Matches(repo string) bool {
+	included := len(o.Include) == 0
+	for _, pattern := range o.Include {
+		if ok, _ := path.Match(pattern, repo); ok {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, pattern := range o.Exclude {
+		if ok, _ := path.Match(pattern, repo); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func LoadOrg(path string) (Org, error) {
+	var org Org
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return org, err
+	}
+	if err := json.Unmarshal(data, &org); err != nil {
+		return org, fmt.Errorf("failed to parse org config: %w", err)
+	}
+	return org, nil
+}
+
+func SaveOrg(path string, org Org) error {
+	data, err := json.MarshalIndent(org, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return os.WriteFile(path, data, 0o644)
+}
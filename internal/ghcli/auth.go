@@ -0,0 +1,103 @@
+package ghcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrCLINotFound is returned when the forge CLI is not installed.
+	ErrCLINotFound = errors.New("CLI not found")
+	// ErrNotLoggedIn is returned when the forge CLI has no valid credentials.
+	ErrNotLoggedIn = errors.New("not logged in")
+)
+
+// AuthStatus describes the credentials the forge CLI uses.
+type AuthStatus struct {
+	CLI  string // "gh" or "glab"
+	User string
+	// Scopes granted to the token, or nil when the token type does not
+	// report them (e.g. fine-grained tokens).
+	Scopes []string
+	// Missing lists required scopes the token lacks; sync won't work.
+	Missing []string
+	// Recommended lists scopes the token lacks that some features need.
+	Recommended []string
+}
+
+// RefreshHint returns the command that grants the given scopes.
+func (s AuthStatus) RefreshHint(scopes []string) string {
+	if s.CLI == "glab" {
+		return "glab auth login"
+	}
+	return "gh auth refresh -s " + strings.Join(scopes, ",")
+}
+
+// ClassifyAuthError maps a failed CLI invocation to ErrCLINotFound or
+// ErrNotLoggedIn where possible.
+func ClassifyAuthError(cli string, err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%w: %s is not installed", ErrCLINotFound, cli)
+	}
+	msg := strings.ToLower(err.Error())
+	for _, needle := range []string{"auth login", "not logged", "bad credentials", "401", "gh_token", "unauthorized"} {
+		if strings.Contains(msg, needle) {
+			return fmt.Errorf("%w: %v", ErrNotLoggedIn, err)
+		}
+	}
+	return err
+}
+
+// CheckAuth verifies that gh is installed and logged in, and that the
+// token has the repo scope. The project scope is only needed for syncing
+// projects and is reported as recommended.
+func (c *Client) CheckAuth(ctx context.Context) (AuthStatus, error) {
+	status := AuthStatus{CLI: "gh"}
+	out, err := c.runner.Run(ctx, "gh", "api", "user", "-i")
+	if err != nil {
+		return status, ClassifyAuthError("gh", err)
+	}
+
+	headers, body, _ := strings.Cut(strings.ReplaceAll(out, "\r\n", "\n"), "\n\n")
+	for _, line := range strings.Split(headers, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "x-oauth-scopes") {
+			continue
+		}
+		status.Scopes = []string{}
+		for _, scope := range strings.Split(value, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				status.Scopes = append(status.Scopes, scope)
+			}
+		}
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(body)), &user); err == nil {
+		status.User = user.Login
+	}
+
+	if status.Scopes != nil {
+		if !hasScope(status.Scopes, "repo") {
+			status.Missing = append(status.Missing, "repo")
+		}
+		if !hasScope(status.Scopes, "project") {
+			status.Recommended = append(status.Recommended, "project")
+		}
+	}
+	return status, nil
+}
+
+func hasScope(scopes []string, want string) bool {
+	for _, scope := range scopes {
+		if strings.EqualFold(scope, want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package ghcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Capabilities records which optional features the forge, repository and
+// token support. Commands consult it to hide or warn about features that
+// are unavailable instead of each handling the resulting API errors.
+type Capabilities struct {
+	// IssueTypes is true when the repository has issue types configured
+	// (organization repositories only).
+	IssueTypes bool
+	// Projects is true when the token can read Projects v2.
+	Projects bool
+	// SubIssues is true when the API exposes sub-issues.
+	SubIssues bool
+}
+
+// ProbeCapabilities runs one small query per feature. A failed probe marks
+// the feature unsupported rather than failing, except when the CLI itself
+// cannot be run.
+func (c *Client) ProbeCapabilities(ctx context.Context) (Capabilities, error) {
+	owner, repo := splitRepo(c.repo)
+	if owner == "" || repo == "" {
+		return Capabilities{}, fmt.Errorf("invalid repository format")
+	}
+	var caps Capabilities
+
+	var types struct {
+		Repository struct {
+			IssueTypes *struct {
+				TotalCount int `json:"totalCount"`
+			} `json:"issueTypes"`
+		} `json:"repository"`
+	}
+	ok, err := c.probe(ctx, `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    issueTypes(first: 1) { totalCount }
+  }
+}`, owner, repo, &types)
+	if err != nil {
+		return caps, err
+	}
+	caps.IssueTypes = ok && types.Repository.IssueTypes != nil && types.Repository.IssueTypes.TotalCount > 0
+
+	var projects struct{}
+	if caps.Projects, err = c.probe(ctx, `query {
+  viewer {
+    projectsV2(first: 1) { totalCount }
+  }
+}`, "", "", &projects); err != nil {
+		return caps, err
+	}
+
+	var subIssues struct{}
+	if caps.SubIssues, err = c.probe(ctx, `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    issues(first: 1) { nodes { subIssuesSummary { total } } }
+  }
+}`, owner, repo, &subIssues); err != nil {
+		return caps, err
+	}
+	return caps, nil
+}
+
+// probe runs a GraphQL query and reports whether it succeeded without
+// errors. Only a failure to run gh at all is returned as an error.
+func (c *Client) probe(ctx context.Context, query, owner, repo string, data any) (bool, error) {
+	args := []string{"api", "graphql", "-f", fmt.Sprintf("query=%s", query)}
+	if owner != "" {
+		args = append(args, "-F", fmt.Sprintf("owner=%s", owner), "-F", fmt.Sprintf("repo=%s", repo))
+	}
+	out, err := c.runner.Run(ctx, "gh", args...)
+	if err != nil {
+		classified := ClassifyAuthError("gh", err)
+		if errors.Is(classified, ErrCLINotFound) || errors.Is(classified, ErrNotLoggedIn) {
+			return false, classified
+		}
+		return false, nil
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil || len(resp.Errors) > 0 {
+		return false, nil
+	}
+	return json.Unmarshal(resp.Data, data) == nil, nil
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -20,6 +19,7 @@ type Client struct {
 	runner   Runner
 	repo     string
 	progress func(ProgressEvent)
+	stats    *QueryStats
 }
 
 func NewClient(runner Runner, repo string) *Client {
@@ -45,33 +45,18 @@ func (c *Client) SetProgress(fn func(ProgressEvent)) {
 	c.progress = fn
 }
 
+// SetStats makes the client add the cost of its batched GraphQL calls to
+// stats.
+func (c *Client) SetStats(stats *QueryStats) {
+	c.stats = stats
+}
+
 func (c *Client) reportProgress(event ProgressEvent) {
 	if c.progress != nil {
 		c.progress(event)
 	}
 }
 
-// HasProjectScope checks if the current GitHub token has the 'project' scope.
-func (c *Client) HasProjectScope(ctx context.Context) (bool, error) {
-	// Make a simple API call and check the X-Oauth-Scopes header
-	out, err := c.runner.Run(ctx, "gh", "api", "user", "-i")
-	if err != nil {
-		return false, err
-	}
-
-	// Parse headers from the response
-	lines := strings.Split(out, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(strings.ToLower(line), "x-oauth-scopes:") {
-			scopes := strings.ToLower(line[len("x-oauth-scopes:"):])
-			// Check for 'project' scope (which implies read:project)
-			return strings.Contains(scopes, "project"), nil
-		}
-	}
-
-	return false, nil
-}
-
 func (c *Client) withRepo(args []string) []string {
 	if c.repo == "" {
 		return args
@@ -190,9 +175,20 @@ type ListIssuesResult struct {
 
 // ListIssuesOptions configures the ListIssuesWithRelationships query.
 type ListIssuesOptions struct {
-	State  string    // "open", "closed", or "all"
-	Labels []string  // Filter by labels
-	Since  time.Time // Only fetch issues updated after this time (zero means no filter)
+	State     string    // "open", "closed", or "all"
+	Labels    []string  // Filter by labels
+	Milestone string    // Filter by milestone title
+	Assignee  string    // Filter by assignee login
+	Since     time.Time // Only fetch issues updated after this time (zero means no filter)
+	// SkipProjects leaves out project items, e.g. when the token lacks
+	// the project scope.
+	SkipProjects bool
+	// After resumes an interrupted listing after the page whose cursor
+	// was passed to OnPage.
+	After string
+	// OnPage is called after every page with the cursor to resume after
+	// it and the issues of the page. An error stops the listing.
+	OnPage func(cursor string, issues []issue.Issue) error
 }
 
 // ListIssuesWithRelationships fetches issues with their relationships and label colors
@@ -226,10 +222,24 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 		stateArg = fmt.Sprintf(", states: [%s]", stateFilter)
 	}
 
-	// Build since filter for incremental sync
-	sinceArg := ""
+	// Build the filter for incremental sync and scoped pulls
+	var filters []string
 	if !opts.Since.IsZero() {
-		sinceArg = fmt.Sprintf(", filterBy: {since: %q}", opts.Since.Format(time.RFC3339))
+		filters = append(filters, fmt.Sprintf("since: %q", opts.Since.Format(time.RFC3339)))
+	}
+	if opts.Assignee != "" {
+		filters = append(filters, fmt.Sprintf("assignee: %q", opts.Assignee))
+	}
+	if opts.Milestone != "" {
+		number, err := c.milestoneNumber(ctx, opts.Milestone)
+		if err != nil {
+			return ListIssuesResult{}, err
+		}
+		filters = append(filters, fmt.Sprintf("milestoneNumber: %q", number))
+	}
+	filterArg := ""
+	if len(filters) > 0 {
+		filterArg = fmt.Sprintf(", filterBy: {%s}", strings.Join(filters, ", "))
 	}
 
 	result := ListIssuesResult{
@@ -238,10 +248,13 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 
 	// Paginate through issues, fetching labels on first page
 	var cursor *string
+	if opts.After != "" {
+		cursor = &opts.After
+	}
 	firstPage := true
 	page := 0
 	totalCount := 0
-	includeProjectItems := true
+	includeProjectItems := !opts.SkipProjects
 	for {
 		page++
 		cursorArg := "null"
@@ -253,6 +266,7 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 		labelsFragment := ""
 		if firstPage {
 			labelsFragment = `labels(first: 100) {
+      ` + pageInfoFields + `
       nodes {
         name
         color
@@ -262,7 +276,7 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 
 		projectItemsFragment := ""
 		if includeProjectItems {
-			projectItemsFragment = "projectItems(first: 20) { nodes { project { title } } }"
+			projectItemsFragment = "projectItems(first: 20) { " + pageInfoFields + " nodes { project { title } } }"
 		}
 
 		query := fmt.Sprintf(`query($owner: String!, $repo: String!) {
@@ -283,18 +297,23 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
         createdAt
         updatedAt
         author { login }
-        labels(first: 100) { nodes { name } }
+        labels(first: 100) { `+pageInfoFields+` nodes { name } }
         assignees(first: 100) { nodes { login } }
         milestone { title }
         issueType { name }
         %s
         parent { number }
+        subIssuesSummary { total completed }
+        comments { totalCount }
+        reactions { totalCount }
+        `+crossReferencesFragment+`
+        `+developmentFragment+`
         blockedBy(first: 100) { nodes { number } }
         blocking(first: 100) { nodes { number } }
       }
     }
   }
-}`, labelsFragment, stateArg, labelFilter, sinceArg, cursorArg, projectItemsFragment)
+}`, labelsFragment, stateArg, labelFilter, filterArg, cursorArg, projectItemsFragment)
 
 		args := []string{"api", "graphql",
 			"-f", fmt.Sprintf("query=%s", query),
@@ -322,7 +341,8 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 			Data struct {
 				Repository struct {
 					Labels struct {
-						Nodes []struct {
+						PageInfo pageInfo `json:"pageInfo"`
+						Nodes    []struct {
 							Name  string `json:"name"`
 							Color string `json:"color"`
 						} `json:"nodes"`
@@ -345,7 +365,8 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 								Login string `json:"login"`
 							} `json:"author"`
 							Labels struct {
-								Nodes []struct {
+								PageInfo pageInfo `json:"pageInfo"`
+								Nodes    []struct {
 									Name string `json:"name"`
 								} `json:"nodes"`
 							} `json:"labels"`
@@ -361,7 +382,8 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 								Name string `json:"name"`
 							} `json:"issueType"`
 							ProjectItems *struct {
-								Nodes []struct {
+								PageInfo pageInfo `json:"pageInfo"`
+								Nodes    []struct {
 									Project struct {
 										Title string `json:"title"`
 									} `json:"project"`
@@ -370,7 +392,13 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 							Parent *struct {
 								Number int `json:"number"`
 							} `json:"parent"`
-							BlockedBy struct {
+							SubIssuesSummary *issue.SubIssueSummary      `json:"subIssuesSummary"`
+							CrossReferences  *graphqlCrossReferences     `json:"crossReferences"`
+							ClosingPRs       *graphqlClosingPullRequests `json:"closedByPullRequestsReferences"`
+							LinkedBranches   *graphqlLinkedBranches      `json:"linkedBranches"`
+							Comments         graphqlTotalCount           `json:"comments"`
+							Reactions        graphqlTotalCount           `json:"reactions"`
+							BlockedBy        struct {
 								Nodes []struct {
 									Number int `json:"number"`
 								} `json:"nodes"`
@@ -401,12 +429,28 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 		}
 
 		totalCount = resp.Data.Repository.Issues.TotalCount
+		pageStart := len(result.Issues)
 
 		// Parse labels from first page
 		if firstPage {
 			for _, l := range resp.Data.Repository.Labels.Nodes {
 				result.LabelColors[l.Name] = l.Color
 			}
+			if labels := resp.Data.Repository.Labels.PageInfo; labels.HasNextPage {
+				more, err := c.connectionNodes(ctx, labelsPageQuery, []string{"-F", fmt.Sprintf("owner=%s", owner), "-F", fmt.Sprintf("repo=%s", repo)}, labels.EndCursor, "repository", "labels")
+				if err != nil {
+					return ListIssuesResult{}, fmt.Errorf("failed to fetch labels: %w", err)
+				}
+				for _, raw := range more {
+					var l struct {
+						Name  string `json:"name"`
+						Color string `json:"color"`
+					}
+					if json.Unmarshal(raw, &l) == nil {
+						result.LabelColors[l.Name] = l.Color
+					}
+				}
+			}
 			firstPage = false
 		}
 
@@ -415,6 +459,13 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 			for _, l := range node.Labels.Nodes {
 				issLabels = append(issLabels, l.Name)
 			}
+			if node.Labels.PageInfo.HasNextPage {
+				more, err := c.moreIssueLabels(ctx, node.Number, node.Labels.PageInfo.EndCursor)
+				if err != nil {
+					return ListIssuesResult{}, fmt.Errorf("failed to fetch labels of #%d: %w", node.Number, err)
+				}
+				issLabels = append(issLabels, more...)
+			}
 			assignees := make([]string, 0, len(node.Assignees.Nodes))
 			for _, a := range node.Assignees.Nodes {
 				assignees = append(assignees, a.Login)
@@ -433,6 +484,13 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 				for _, pi := range node.ProjectItems.Nodes {
 					projects = append(projects, pi.Project.Title)
 				}
+				if node.ProjectItems.PageInfo.HasNextPage {
+					more, err := c.moreIssueProjects(ctx, node.Number, node.ProjectItems.PageInfo.EndCursor)
+					if err != nil {
+						return ListIssuesResult{}, fmt.Errorf("failed to fetch projects of #%d: %w", node.Number, err)
+					}
+					projects = append(projects, more...)
+				}
 			}
 
 			author := ""
@@ -470,6 +528,14 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 				ref := issue.IssueRef(strconv.Itoa(node.Parent.Number))
 				iss.Parent = &ref
 			}
+			if node.SubIssuesSummary != nil && node.SubIssuesSummary.Total > 0 {
+				iss.SubIssues = node.SubIssuesSummary
+			}
+			iss.ReferencedBy = node.CrossReferences.refs(c.repo)
+			iss.PullRequests = node.ClosingPRs.pullRequests()
+			iss.Branches = node.LinkedBranches.names()
+			iss.CommentCount = node.Comments.TotalCount
+			iss.Reactions = node.Reactions.TotalCount
 			for _, b := range node.BlockedBy.Nodes {
 				iss.BlockedBy = append(iss.BlockedBy, issue.IssueRef(strconv.Itoa(b.Number)))
 			}
@@ -487,6 +553,11 @@ func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ListIssue
 			PageIssues: len(resp.Data.Repository.Issues.Nodes),
 			Total:      totalCount,
 		})
+		if opts.OnPage != nil {
+			if err := opts.OnPage(resp.Data.Repository.Issues.PageInfo.EndCursor, result.Issues[pageStart:]); err != nil {
+				return ListIssuesResult{}, err
+			}
+		}
 
 		if !resp.Data.Repository.Issues.PageInfo.HasNextPage {
 			break
@@ -515,6 +586,12 @@ func (c *Client) EnrichWithRelationships(ctx context.Context, iss *issue.Issue)
 	iss.Blocks = rels.Blocks
 	iss.IssueType = rels.IssueType
 	iss.Projects = rels.Projects
+	iss.SubIssues = rels.SubIssues
+	iss.ReferencedBy = rels.ReferencedBy
+	iss.PullRequests = rels.PullRequests
+	iss.Branches = rels.Branches
+	iss.CommentCount = rels.Comments
+	iss.Reactions = rels.Reactions
 	return nil
 }
 
@@ -549,6 +626,12 @@ func (c *Client) EnrichWithRelationshipsBatch(ctx context.Context, issues []issu
 			issues[i].Blocks = rel.Blocks
 			issues[i].IssueType = rel.IssueType
 			issues[i].Projects = rel.Projects
+			issues[i].SubIssues = rel.SubIssues
+			issues[i].ReferencedBy = rel.ReferencedBy
+			issues[i].PullRequests = rel.PullRequests
+			issues[i].Branches = rel.Branches
+			issues[i].CommentCount = rel.Comments
+			issues[i].Reactions = rel.Reactions
 		}
 	}
 
@@ -571,6 +654,14 @@ func (c *Client) GetIssue(ctx context.Context, number string) (issue.Issue, erro
 // batchQueryChunkSize is the maximum number of issues to query in a single GraphQL call.
 const batchQueryChunkSize = 20
 
+// batchQueryMaxAliases is the most issues GetIssuesBatch queries in a
+// single GraphQL call when their estimated cost allows it.
+const batchQueryMaxAliases = 100
+
+// issueQueryBytes is about the size of one issue of GetIssuesBatch in the
+// query document.
+const issueQueryBytes = 600
+
 // GetIssuesBatch fetches multiple issues in a single GraphQL call.
 // Returns a map of issue number -> issue. Issues that don't exist are not included.
 func (c *Client) GetIssuesBatch(ctx context.Context, numbers []string) (map[string]issue.Issue, error) {
@@ -578,25 +669,99 @@ func (c *Client) GetIssuesBatch(ctx context.Context, numbers []string) (map[stri
 		return map[string]issue.Issue{}, nil
 	}
 
-	// Process in chunks to avoid GitHub's resource limits
+	// Process in chunks sized to stay within GitHub's resource limits
 	results := make(map[string]issue.Issue)
-	for i := 0; i < len(numbers); i += batchQueryChunkSize {
-		end := i + batchQueryChunkSize
-		if end > len(numbers) {
-			end = len(numbers)
+	chunks := chunkByCost(numbers, batchQueryMaxAliases, func(string) aliasCost {
+		return aliasCost{Nodes: issueQueryNodes, Bytes: issueQueryBytes}
+	})
+	for _, chunk := range chunks {
+		if err := c.getIssuesBatchSplitting(ctx, chunk, results); err != nil {
+			return nil, err
 		}
-		chunk := numbers[i:end]
+	}
 
-		chunkResults, err := c.getIssuesBatchChunk(ctx, chunk)
+	return results, nil
+}
+
+// getIssuesBatchSplitting fetches a chunk of issues into results, halving
+// the chunk for as long as GitHub finds the query too expensive.
+func (c *Client) getIssuesBatchSplitting(ctx context.Context, numbers []string, results map[string]issue.Issue) error {
+	chunkResults, err := c.getIssuesBatchChunk(ctx, numbers)
+	if isQueryCostError(err) && len(numbers) > 1 {
+		c.stats.add(QueryCost{Splits: 1})
+		half := len(numbers) / 2
+		if err := c.getIssuesBatchSplitting(ctx, numbers[:half], results); err != nil {
+			return err
+		}
+		return c.getIssuesBatchSplitting(ctx, numbers[half:], results)
+	}
+	if err != nil {
+		return err
+	}
+	for k, v := range chunkResults {
+		results[k] = v
+	}
+	return nil
+}
+
+// GetUpdatedAt returns the last update time of each issue. It is much
+// cheaper than GetIssuesBatch and used to re-check issues right before
+// editing them.
+func (c *Client) GetUpdatedAt(ctx context.Context, numbers []string) (map[string]time.Time, error) {
+	owner, repo := splitRepo(c.repo)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format")
+	}
+	results := make(map[string]time.Time, len(numbers))
+	for i := 0; i < len(numbers); i += batchQueryChunkSize {
+		end := min(i+batchQueryChunkSize, len(numbers))
+		var issueQueries []string
+		for j, num := range numbers[i:end] {
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				continue
+			}
+			issueQueries = append(issueQueries, fmt.Sprintf("issue%d: issue(number: %d) { number updatedAt }", j, n))
+		}
+		if len(issueQueries) == 0 {
+			continue
+		}
+		query := fmt.Sprintf(`query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    %s
+  }
+}`, strings.Join(issueQueries, "\n    "))
+		out, err := c.runner.Run(ctx, "gh", "api", "graphql",
+			"-f", fmt.Sprintf("query=%s", query),
+			"-F", fmt.Sprintf("owner=%s", owner),
+			"-F", fmt.Sprintf("repo=%s", repo),
+		)
 		if err != nil {
 			return nil, err
 		}
-
-		for k, v := range chunkResults {
-			results[k] = v
+		var resp struct {
+			Data struct {
+				Repository map[string]*struct {
+					Number    int       `json:"number"`
+					UpdatedAt time.Time `json:"updatedAt"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal([]byte(out), &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		}
+		if len(resp.Errors) > 0 {
+			return nil, fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+		}
+		for _, item := range resp.Data.Repository {
+			if item != nil {
+				results[strconv.Itoa(item.Number)] = item.UpdatedAt
+			}
 		}
 	}
-
 	return results, nil
 }
 
@@ -617,7 +782,7 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 		var issueQueries []string
 		projectItemsFragment := ""
 		if withProjects {
-			projectItemsFragment = "projectItems(first: 20) { nodes { project { title } } }"
+			projectItemsFragment = "projectItems(first: 20) { " + pageInfoFields + " nodes { project { title } } }"
 		}
 
 		for i, num := range numbers {
@@ -634,7 +799,7 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
       createdAt
       updatedAt
       author { login }
-      labels(first: 100) { nodes { name } }
+      labels(first: 100) { `+pageInfoFields+` nodes { name } }
       assignees(first: 100) { nodes { login } }
       milestone { title }
       issueType { name }
@@ -659,6 +824,7 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
   repository(owner: $owner, name: $repo) {
     %s
   }
+  rateLimit { cost }
 }`, strings.Join(issueQueries, "\n    "))
 
 		args := []string{"api", "graphql",
@@ -667,6 +833,7 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 			"-F", fmt.Sprintf("repo=%s", repo),
 		}
 
+		c.stats.add(QueryCost{Queries: 1, Nodes: len(issueQueries) * issueQueryNodes})
 		var err error
 		out, err = c.runner.Run(ctx, "gh", args...)
 		if err != nil {
@@ -682,6 +849,9 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 	var resp struct {
 		Data struct {
 			Repository map[string]json.RawMessage `json:"repository"`
+			RateLimit  *struct {
+				Cost int `json:"cost"`
+			} `json:"rateLimit"`
 		} `json:"data"`
 		Errors []struct {
 			Message string `json:"message"`
@@ -699,12 +869,14 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
   repository(owner: $owner, name: $repo) {
     %s
   }
+  rateLimit { cost }
 }`, strings.Join(issueQueries, "\n    "))
 			args := []string{"api", "graphql",
 				"-f", fmt.Sprintf("query=%s", query),
 				"-F", fmt.Sprintf("owner=%s", owner),
 				"-F", fmt.Sprintf("repo=%s", repo),
 			}
+			c.stats.add(QueryCost{Queries: 1, Nodes: len(issueQueries) * issueQueryNodes})
 			out, err := c.runner.Run(ctx, "gh", args...)
 			if err != nil {
 				return nil, err
@@ -720,6 +892,10 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 		}
 	}
 
+	if resp.Data.RateLimit != nil {
+		c.stats.add(QueryCost{Points: resp.Data.RateLimit.Cost})
+	}
+
 	results := make(map[string]issue.Issue)
 
 	for alias, rawIssue := range resp.Data.Repository {
@@ -742,7 +918,8 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 				Login string `json:"login"`
 			} `json:"author"`
 			Labels struct {
-				Nodes []struct {
+				PageInfo pageInfo `json:"pageInfo"`
+				Nodes    []struct {
 					Name string `json:"name"`
 				} `json:"nodes"`
 			} `json:"labels"`
@@ -758,7 +935,8 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 				Name string `json:"name"`
 			} `json:"issueType"`
 			ProjectItems *struct {
-				Nodes []struct {
+				PageInfo pageInfo `json:"pageInfo"`
+				Nodes    []struct {
 					Project struct {
 						Title string `json:"title"`
 					} `json:"project"`
@@ -786,6 +964,13 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 		for _, l := range issueData.Labels.Nodes {
 			labels = append(labels, l.Name)
 		}
+		if issueData.Labels.PageInfo.HasNextPage {
+			more, err := c.moreIssueLabels(ctx, issueData.Number, issueData.Labels.PageInfo.EndCursor)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch labels of #%d: %w", issueData.Number, err)
+			}
+			labels = append(labels, more...)
+		}
 		assignees := make([]string, 0, len(issueData.Assignees.Nodes))
 		for _, a := range issueData.Assignees.Nodes {
 			assignees = append(assignees, a.Login)
@@ -803,6 +988,13 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 			for _, pi := range issueData.ProjectItems.Nodes {
 				projects = append(projects, pi.Project.Title)
 			}
+			if issueData.ProjectItems.PageInfo.HasNextPage {
+				more, err := c.moreIssueProjects(ctx, issueData.Number, issueData.ProjectItems.PageInfo.EndCursor)
+				if err != nil {
+					return nil, fmt.Errorf("failed to fetch projects of #%d: %w", issueData.Number, err)
+				}
+				projects = append(projects, more...)
+			}
 		}
 
 		author := ""
@@ -853,22 +1045,98 @@ func (c *Client) getIssuesBatchChunk(ctx context.Context, numbers []string) (map
 	return results, nil
 }
 
-func (c *Client) CreateIssue(ctx context.Context, issue issue.Issue) (string, error) {
-	args := []string{"issue", "create", "--title", issue.Title, "--body", issue.Body}
-	for _, label := range issue.Labels {
-		args = append(args, "--label", label)
+// CreateIssue creates an issue with a single createIssue mutation, so the
+// issue type and projects are set along with the labels, assignees and
+// milestone. The IDs of all of them are looked up in one query first.
+func (c *Client) CreateIssue(ctx context.Context, iss issue.Issue) (string, error) {
+	owner, repo := splitRepo(c.repo)
+	if owner == "" || repo == "" {
+		return "", fmt.Errorf("invalid repository format")
 	}
-	for _, assignee := range issue.Assignees {
-		args = append(args, "--assignee", assignee)
+	ids, err := c.fetchCreateLookups(ctx, owner, repo, iss)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch IDs: %w", err)
 	}
-	if issue.Milestone != "" {
-		args = append(args, "--milestone", issue.Milestone)
+
+	inputParts := []string{
+		fmt.Sprintf("repositoryId: %q", ids.RepositoryID),
+		fmt.Sprintf("title: %q", iss.Title),
+		fmt.Sprintf("body: %q", iss.Body),
 	}
-	out, err := c.runner.Run(ctx, "gh", c.withRepo(args)...)
+	quoted := func(kind string, names []string, lookup map[string]string) ([]string, error) {
+		result := make([]string, 0, len(names))
+		for _, name := range names {
+			id, ok := lookup[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("%s %q not found", kind, name)
+			}
+			result = append(result, fmt.Sprintf("%q", id))
+		}
+		return result, nil
+	}
+	labelIDs, err := quoted("label", iss.Labels, ids.LabelIDs)
+	if err != nil {
+		return "", err
+	}
+	if len(labelIDs) > 0 {
+		inputParts = append(inputParts, fmt.Sprintf("labelIds: [%s]", strings.Join(labelIDs, ", ")))
+	}
+	assigneeIDs, err := quoted("user", iss.Assignees, ids.UserIDs)
+	if err != nil {
+		return "", err
+	}
+	if len(assigneeIDs) > 0 {
+		inputParts = append(inputParts, fmt.Sprintf("assigneeIds: [%s]", strings.Join(assigneeIDs, ", ")))
+	}
+	projectIDs, err := quoted("project", iss.Projects, ids.ProjectIDs)
+	if err != nil {
+		return "", err
+	}
+	if len(projectIDs) > 0 {
+		inputParts = append(inputParts, fmt.Sprintf("projectV2Ids: [%s]", strings.Join(projectIDs, ", ")))
+	}
+	if iss.Milestone != "" {
+		id, ok := ids.MilestoneIDs[strings.ToLower(iss.Milestone)]
+		if !ok {
+			return "", fmt.Errorf("milestone %q not found", iss.Milestone)
+		}
+		inputParts = append(inputParts, fmt.Sprintf("milestoneId: %q", id))
+	}
+	if iss.IssueType != "" {
+		id, ok := ids.IssueTypeIDs[strings.ToLower(iss.IssueType)]
+		if !ok {
+			return "", fmt.Errorf("issue type %q not found", iss.IssueType)
+		}
+		inputParts = append(inputParts, fmt.Sprintf("issueTypeId: %q", id))
+	}
+
+	query := fmt.Sprintf("mutation {\n  createIssue(input: {%s}) { issue { number } }\n}", strings.Join(inputParts, ", "))
+	out, err := c.runner.Run(ctx, "gh", "api", "graphql", "-f", fmt.Sprintf("query=%s", query))
 	if err != nil {
 		return "", err
 	}
-	return parseIssueNumber(out)
+	var resp struct {
+		Data struct {
+			CreateIssue *struct {
+				Issue struct {
+					Number int `json:"number"`
+				} `json:"issue"`
+			} `json:"createIssue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return "", fmt.Errorf("failed to parse GraphQL response: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return "", fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+	}
+	if resp.Data.CreateIssue == nil || resp.Data.CreateIssue.Issue.Number == 0 {
+		return "", fmt.Errorf("unable to parse issue number from response: %q", strings.TrimSpace(out))
+	}
+	return strconv.Itoa(resp.Data.CreateIssue.Issue.Number), nil
 }
 
 func (c *Client) EditIssue(ctx context.Context, number string, change IssueChange) error {
@@ -902,17 +1170,33 @@ func (c *Client) EditIssue(ctx context.Context, number string, change IssueChang
 	return err
 }
 
-func (c *Client) CloseIssue(ctx context.Context, number string, reason string) error {
+// CloseIssue closes an issue and returns its updatedAt after the change,
+// or the zero time if the response did not include one.
+func (c *Client) CloseIssue(ctx context.Context, number string, reason string) (time.Time, error) {
 	args := []string{"api", fmt.Sprintf("repos/%s/issues/%s", c.repo, number), "--method", "PATCH", "-f", "state=closed"}
 	if reason != "" {
 		normalized, ok := normalizeCloseReason(reason)
 		if !ok {
-			return fmt.Errorf("unsupported close reason %q (expected completed or not_planned)", reason)
+			return time.Time{}, fmt.Errorf("unsupported close reason %q (expected completed or not_planned)", reason)
 		}
 		args = append(args, "-f", "state_reason="+normalized)
 	}
-	_, err := c.runner.Run(ctx, "gh", c.withRepo(args)...)
-	return err
+	out, err := c.runner.Run(ctx, "gh", c.withRepo(args)...)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return restUpdatedAt(out), nil
+}
+
+// restUpdatedAt extracts updated_at from a REST issue response.
+func restUpdatedAt(out string) time.Time {
+	var resp struct {
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return time.Time{}
+	}
+	return resp.UpdatedAt
 }
 
 func canonicalStateReason(reason string) string {
@@ -952,20 +1236,15 @@ func normalizeCloseReason(reason string) (string, bool) {
 	}
 }
 
-func (c *Client) ReopenIssue(ctx context.Context, number string) error {
-	args := []string{"issue", "reopen", number}
-	_, err := c.runner.Run(ctx, "gh", c.withRepo(args)...)
-	return err
-}
-
-var issueNumberPattern = regexp.MustCompile(`(?i)(?:/issues/|#)(\d+)`)
-
-func parseIssueNumber(output string) (string, error) {
-	match := issueNumberPattern.FindStringSubmatch(output)
-	if len(match) < 2 {
-		return "", fmt.Errorf("unable to parse issue number from output: %q", strings.TrimSpace(output))
+// ReopenIssue reopens an issue and returns its updatedAt after the change,
+// or the zero time if the response did not include one.
+func (c *Client) ReopenIssue(ctx context.Context, number string) (time.Time, error) {
+	args := []string{"api", fmt.Sprintf("repos/%s/issues/%s", c.repo, number), "--method", "PATCH", "-f", "state=open"}
+	out, err := c.runner.Run(ctx, "gh", c.withRepo(args)...)
+	if err != nil {
+		return time.Time{}, err
 	}
-	return match[1], nil
+	return restUpdatedAt(out), nil
 }
 
 // ListLabels fetches all labels from the repository with their colors.
@@ -1001,15 +1280,75 @@ func (c *Client) CreateLabel(ctx context.Context, name, color string) error {
 	return err
 }
 
+// RenameLabel renames a label. Issues carrying it keep it under the new name.
+func (c *Client) RenameLabel(ctx context.Context, oldName, newName string) error {
+	args := []string{"label", "edit", oldName, "--name", newName}
+	_, err := c.runner.Run(ctx, "gh", c.withRepo(args)...)
+	return err
+}
+
+// DeleteLabel deletes a label, removing it from every issue.
+func (c *Client) DeleteLabel(ctx context.Context, name string) error {
+	args := []string{"label", "delete", name, "--yes"}
+	_, err := c.runner.Run(ctx, "gh", c.withRepo(args)...)
+	return err
+}
+
+// Repository is the descriptive metadata of a repository.
+type Repository struct {
+	Description   string   `json:"description"`
+	Topics        []string `json:"topics"`
+	DefaultBranch string   `json:"default_branch"`
+	Homepage      string   `json:"homepage"`
+}
+
+// GetRepository fetches the repository's description, topics, default
+// branch and homepage.
+func (c *Client) GetRepository(ctx context.Context) (Repository, error) {
+	endpoint := fmt.Sprintf("repos/%s", c.repo)
+	out, err := c.runner.Run(ctx, "gh", "api", endpoint, "-q", "{description, topics, default_branch, homepage}")
+	if err != nil {
+		return Repository{}, err
+	}
+	var repo Repository
+	if err := json.Unmarshal([]byte(out), &repo); err != nil {
+		return Repository{}, fmt.Errorf("failed to parse repository JSON: %w", err)
+	}
+	return repo, nil
+}
+
 // Milestone represents a GitHub milestone.
 type Milestone struct {
+	Number      int     `json:"number"`
 	Title       string  `json:"title"`
 	Description string  `json:"description"`
 	DueOn       *string `json:"due_on"` // ISO 8601 format
 	State       string  `json:"state"`  // open or closed
 }
 
-// ListMilestones fetches all milestones from the repository.
+// milestoneNumber resolves a milestone title to the number the issues
+// filter takes.
+func (c *Client) milestoneNumber(ctx context.Context, title string) (string, error) {
+	milestones, err := c.ListMilestones(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range milestones {
+		if m.Title == title {
+			return strconv.Itoa(m.Number), nil
+		}
+	}
+	for _, m := range milestones {
+		if strings.EqualFold(m.Title, title) {
+			return strconv.Itoa(m.Number), nil
+		}
+	}
+	return "", fmt.Errorf("unknown milestone %q", title)
+}
+
+// ListMilestones fetches all milestones from the repository. A failure for
+// either state fails the whole listing: callers delete local milestone files
+// that are missing from the result, so a partial list must not look complete.
 func (c *Client) ListMilestones(ctx context.Context) ([]Milestone, error) {
 	// Use gh api to get milestones (gh doesn't have a built-in milestone list command)
 	// We need to fetch both open and closed milestones
@@ -1027,8 +1366,7 @@ func (c *Client) ListMilestones(ctx context.Context) ([]Milestone, error) {
 		args := []string{"api", endpoint, "--paginate", "-q", ".[]"}
 		out, err := c.runner.Run(ctx, "gh", args...)
 		if err != nil {
-			// If there are no milestones, gh api might return an error or empty
-			continue
+			return nil, fmt.Errorf("listing %s milestones: %w", state, err)
 		}
 		if strings.TrimSpace(out) == "" {
 			continue
@@ -1041,6 +1379,7 @@ func (c *Client) ListMilestones(ctx context.Context) ([]Milestone, error) {
 				continue
 			}
 			var m struct {
+				Number      int     `json:"number"`
 				Title       string  `json:"title"`
 				Description string  `json:"description"`
 				DueOn       *string `json:"due_on"`
@@ -1050,6 +1389,7 @@ func (c *Client) ListMilestones(ctx context.Context) ([]Milestone, error) {
 				continue
 			}
 			allMilestones = append(allMilestones, Milestone{
+				Number:      m.Number,
 				Title:       m.Title,
 				Description: m.Description,
 				DueOn:       m.DueOn,
@@ -1061,19 +1401,62 @@ func (c *Client) ListMilestones(ctx context.Context) ([]Milestone, error) {
 	return allMilestones, nil
 }
 
-// CreateMilestone creates a new milestone with the given title.
-func (c *Client) CreateMilestone(ctx context.Context, title string) error {
+// CreateMilestone creates a new milestone. Only the title is required;
+// description, due date and state are sent when set.
+func (c *Client) CreateMilestone(ctx context.Context, m Milestone) error {
 	owner, repo := splitRepo(c.repo)
 	if owner == "" || repo == "" {
 		return fmt.Errorf("invalid repository format")
 	}
 
 	endpoint := fmt.Sprintf("repos/%s/%s/milestones", owner, repo)
-	args := []string{"api", endpoint, "-X", "POST", "-f", "title=" + title}
+	args := []string{"api", endpoint, "-X", "POST", "-f", "title=" + m.Title}
+	if m.Description != "" {
+		args = append(args, "-f", "description="+m.Description)
+	}
+	if m.DueOn != nil && *m.DueOn != "" {
+		args = append(args, "-f", "due_on="+milestoneDueOn(*m.DueOn))
+	}
+	if m.State != "" {
+		args = append(args, "-f", "state="+m.State)
+	}
 	_, err := c.runner.Run(ctx, "gh", args...)
 	return err
 }
 
+// UpdateMilestone replaces the title, description, due date and state of an
+// existing milestone.
+func (c *Client) UpdateMilestone(ctx context.Context, number int, m Milestone) error {
+	owner, repo := splitRepo(c.repo)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("invalid repository format")
+	}
+
+	endpoint := fmt.Sprintf("repos/%s/%s/milestones/%d", owner, repo, number)
+	args := []string{"api", endpoint, "-X", "PATCH",
+		"-f", "title=" + m.Title,
+		"-f", "description=" + m.Description,
+	}
+	if m.DueOn != nil && *m.DueOn != "" {
+		args = append(args, "-f", "due_on="+milestoneDueOn(*m.DueOn))
+	} else {
+		args = append(args, "-F", "due_on=null")
+	}
+	if m.State != "" {
+		args = append(args, "-f", "state="+m.State)
+	}
+	_, err := c.runner.Run(ctx, "gh", args...)
+	return err
+}
+
+// milestoneDueOn converts a date (YYYY-MM-DD) into the timestamp GitHub expects.
+func milestoneDueOn(due string) string {
+	if len(due) == len("2006-01-02") {
+		return due + "T00:00:00Z"
+	}
+	return due
+}
+
 // IssueChange captures the edits we need to apply to an issue.
 type IssueChange struct {
 	Title           *string
@@ -1176,11 +1559,12 @@ func (c *Client) ListIssueTypes(ctx context.Context) ([]IssueType, error) {
 }
 
 // SetIssueType sets or clears the issue type for an issue.
-// If issueTypeID is empty, the issue type is cleared.
-func (c *Client) SetIssueType(ctx context.Context, issueNumber string, issueTypeID string) error {
+// If issueTypeID is empty, the issue type is cleared. It returns the
+// issue's updatedAt after the change.
+func (c *Client) SetIssueType(ctx context.Context, issueNumber string, issueTypeID string) (time.Time, error) {
 	issueNodeID, err := c.GetIssueNodeID(ctx, issueNumber)
 	if err != nil {
-		return fmt.Errorf("failed to get issue node ID: %w", err)
+		return time.Time{}, fmt.Errorf("failed to get issue node ID: %w", err)
 	}
 
 	var mutation string
@@ -1190,7 +1574,7 @@ func (c *Client) SetIssueType(ctx context.Context, issueNumber string, issueType
 		// Clear issue type by setting to null
 		mutation = `mutation($issueId: ID!) {
   updateIssue(input: {id: $issueId, issueTypeId: null}) {
-    issue { id }
+    issue { id updatedAt }
   }
 }`
 		args = []string{"api", "graphql",
@@ -1200,7 +1584,7 @@ func (c *Client) SetIssueType(ctx context.Context, issueNumber string, issueType
 	} else {
 		mutation = `mutation($issueId: ID!, $issueTypeId: ID!) {
   updateIssue(input: {id: $issueId, issueTypeId: $issueTypeId}) {
-    issue { id }
+    issue { id updatedAt }
   }
 }`
 		args = []string{"api", "graphql",
@@ -1212,29 +1596,37 @@ func (c *Client) SetIssueType(ctx context.Context, issueNumber string, issueType
 
 	out, err := c.runner.Run(ctx, "gh", args...)
 	if err != nil {
-		return err
+		return time.Time{}, err
 	}
 
 	var resp struct {
+		Data struct {
+			UpdateIssue struct {
+				Issue struct {
+					UpdatedAt time.Time `json:"updatedAt"`
+				} `json:"issue"`
+			} `json:"updateIssue"`
+		} `json:"data"`
 		Errors []struct {
 			Message string `json:"message"`
 		} `json:"errors"`
 	}
 	if err := json.Unmarshal([]byte(out), &resp); err != nil {
-		return fmt.Errorf("failed to parse GraphQL response: %w", err)
+		return time.Time{}, fmt.Errorf("failed to parse GraphQL response: %w", err)
 	}
 
 	if len(resp.Errors) > 0 {
-		return fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+		return time.Time{}, fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
 	}
 
-	return nil
+	return resp.Data.UpdateIssue.Issue.UpdatedAt, nil
 }
 
 // Project represents a GitHub Project V2.
 type Project struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
 }
 
 // ListProjects fetches all projects accessible from the repository.
@@ -1248,131 +1640,64 @@ func (c *Client) ListProjects(ctx context.Context) ([]Project, error) {
 
 	// Try to get projects from the repository owner (org or user)
 	// First try as organization
-	query := `query($owner: String!) {
+	query := `query($owner: String!, $cursor: String) {
   organization(login: $owner) {
-    projectsV2(first: 100) {
+    projectsV2(first: 100, after: $cursor) {
+      ` + pageInfoFields + `
       nodes {
         id
+        number
         title
       }
     }
   }
 }`
 
-	args := []string{"api", "graphql",
-		"-f", fmt.Sprintf("query=%s", query),
-		"-F", fmt.Sprintf("owner=%s", owner),
+	nodes, err := c.connectionNodes(ctx, query, []string{"-F", fmt.Sprintf("owner=%s", owner)}, "", "organization", "projectsV2")
+	if isProjectScopeError(err) {
+		return nil, ErrMissingProjectScope
 	}
-
-	out, err := c.runner.Run(ctx, "gh", args...)
 	if err != nil {
 		// Try as user instead
 		return c.listUserProjects(ctx, owner)
 	}
-
-	var resp struct {
-		Data struct {
-			Organization struct {
-				ProjectsV2 struct {
-					Nodes []struct {
-						ID    string `json:"id"`
-						Title string `json:"title"`
-					} `json:"nodes"`
-				} `json:"projectsV2"`
-			} `json:"organization"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-		} `json:"errors"`
-	}
-
-	if err := json.Unmarshal([]byte(out), &resp); err != nil {
-		return nil, nil
-	}
-
-	// Check for scope errors
-	for _, e := range resp.Errors {
-		if e.Type == "INSUFFICIENT_SCOPES" {
-			return nil, ErrMissingProjectScope
-		}
-	}
-
-	if len(resp.Errors) > 0 {
-		// Try as user
-		return c.listUserProjects(ctx, owner)
-	}
-
-	var projects []Project
-	for _, p := range resp.Data.Organization.ProjectsV2.Nodes {
-		projects = append(projects, Project{
-			ID:    p.ID,
-			Title: p.Title,
-		})
-	}
-
-	return projects, nil
+	return decodeProjects(nodes), nil
 }
 
 func (c *Client) listUserProjects(ctx context.Context, login string) ([]Project, error) {
-	query := `query($login: String!) {
+	query := `query($login: String!, $cursor: String) {
   user(login: $login) {
-    projectsV2(first: 100) {
+    projectsV2(first: 100, after: $cursor) {
+      ` + pageInfoFields + `
       nodes {
         id
+        number
         title
       }
     }
   }
 }`
 
-	args := []string{"api", "graphql",
-		"-f", fmt.Sprintf("query=%s", query),
-		"-F", fmt.Sprintf("login=%s", login),
+	nodes, err := c.connectionNodes(ctx, query, []string{"-F", fmt.Sprintf("login=%s", login)}, "", "user", "projectsV2")
+	if isProjectScopeError(err) {
+		return nil, ErrMissingProjectScope
 	}
-
-	out, err := c.runner.Run(ctx, "gh", args...)
 	if err != nil {
 		return nil, nil
 	}
+	return decodeProjects(nodes), nil
+}
 
-	var resp struct {
-		Data struct {
-			User struct {
-				ProjectsV2 struct {
-					Nodes []struct {
-						ID    string `json:"id"`
-						Title string `json:"title"`
-					} `json:"nodes"`
-				} `json:"projectsV2"`
-			} `json:"user"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-		} `json:"errors"`
-	}
-
-	if err := json.Unmarshal([]byte(out), &resp); err != nil {
-		return nil, nil
-	}
-
-	// Check for scope errors
-	for _, e := range resp.Errors {
-		if e.Type == "INSUFFICIENT_SCOPES" {
-			return nil, ErrMissingProjectScope
-		}
-	}
-
+// decodeProjects parses project nodes, skipping any that don't parse.
+func decodeProjects(nodes []json.RawMessage) []Project {
 	var projects []Project
-	for _, p := range resp.Data.User.ProjectsV2.Nodes {
-		projects = append(projects, Project{
-			ID:    p.ID,
-			Title: p.Title,
-		})
+	for _, raw := range nodes {
+		var p Project
+		if json.Unmarshal(raw, &p) == nil {
+			projects = append(projects, p)
+		}
 	}
-
-	return projects, nil
+	return projects
 }
 
 // AddToProject adds an issue to a project.
@@ -1436,10 +1761,11 @@ func (c *Client) RemoveFromProject(ctx context.Context, issueNumber string, proj
 	}
 
 	// First, we need to find the project item ID for this issue in this project
-	query := `query($issueId: ID!) {
+	query := `query($issueId: ID!, $cursor: String) {
   node(id: $issueId) {
     ... on Issue {
-      projectItems(first: 100) {
+      projectItems(first: 100, after: $cursor) {
+        ` + pageInfoFields + `
         nodes {
           id
           project { id }
@@ -1449,42 +1775,23 @@ func (c *Client) RemoveFromProject(ctx context.Context, issueNumber string, proj
   }
 }`
 
-	args := []string{"api", "graphql",
-		"-f", fmt.Sprintf("query=%s", query),
-		"-f", fmt.Sprintf("issueId=%s", issueNodeID),
-	}
-
-	out, err := c.runner.Run(ctx, "gh", args...)
+	items, err := c.connectionNodes(ctx, query, []string{"-f", fmt.Sprintf("issueId=%s", issueNodeID)}, "", "node", "projectItems")
 	if err != nil {
 		return err
 	}
 
-	var queryResp struct {
-		Data struct {
-			Node struct {
-				ProjectItems struct {
-					Nodes []struct {
-						ID      string `json:"id"`
-						Project struct {
-							ID string `json:"id"`
-						} `json:"project"`
-					} `json:"nodes"`
-				} `json:"projectItems"`
-			} `json:"node"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-		} `json:"errors"`
-	}
-
-	if err := json.Unmarshal([]byte(out), &queryResp); err != nil {
-		return fmt.Errorf("failed to parse GraphQL response: %w", err)
-	}
-
 	// Find the item ID for this project
 	var itemID string
-	for _, item := range queryResp.Data.Node.ProjectItems.Nodes {
+	for _, raw := range items {
+		var item struct {
+			ID      string `json:"id"`
+			Project struct {
+				ID string `json:"id"`
+			} `json:"project"`
+		}
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return fmt.Errorf("failed to parse GraphQL response: %w", err)
+		}
 		if item.Project.ID == projectID {
 			itemID = item.ID
 			break
@@ -1503,13 +1810,13 @@ func (c *Client) RemoveFromProject(ctx context.Context, issueNumber string, proj
   }
 }`
 
-	args = []string{"api", "graphql",
+	args := []string{"api", "graphql",
 		"-f", fmt.Sprintf("query=%s", mutation),
 		"-f", fmt.Sprintf("projectId=%s", projectID),
 		"-f", fmt.Sprintf("itemId=%s", itemID),
 	}
 
-	out, err = c.runner.Run(ctx, "gh", args...)
+	out, err := c.runner.Run(ctx, "gh", args...)
 	if err != nil {
 		if strings.Contains(err.Error(), "INSUFFICIENT_SCOPES") {
 			return fmt.Errorf("missing 'project' scope - run 'gh auth refresh -s project' to enable")
@@ -1551,10 +1858,11 @@ func (c *Client) SyncProjects(ctx context.Context, issueNumber string, localProj
 		return fmt.Errorf("failed to get issue node ID: %w", err)
 	}
 
-	query := `query($issueId: ID!) {
+	query := `query($issueId: ID!, $cursor: String) {
   node(id: $issueId) {
     ... on Issue {
-      projectItems(first: 100) {
+      projectItems(first: 100, after: $cursor) {
+        ` + pageInfoFields + `
         nodes {
           project {
             id
@@ -1566,49 +1874,23 @@ func (c *Client) SyncProjects(ctx context.Context, issueNumber string, localProj
   }
 }`
 
-	args := []string{"api", "graphql",
-		"-f", fmt.Sprintf("query=%s", query),
-		"-f", fmt.Sprintf("issueId=%s", issueNodeID),
-	}
-
-	out, err := c.runner.Run(ctx, "gh", args...)
+	items, err := c.connectionNodes(ctx, query, []string{"-f", fmt.Sprintf("issueId=%s", issueNodeID)}, "", "node", "projectItems")
 	if err != nil {
-		return nil // Graceful fallback
-	}
-
-	var resp struct {
-		Data struct {
-			Node struct {
-				ProjectItems struct {
-					Nodes []struct {
-						Project struct {
-							ID    string `json:"id"`
-							Title string `json:"title"`
-						} `json:"project"`
-					} `json:"nodes"`
-				} `json:"projectItems"`
-			} `json:"node"`
-		} `json:"data"`
-		Errors []struct {
-			Type string `json:"type"`
-		} `json:"errors"`
-	}
-
-	if err := json.Unmarshal([]byte(out), &resp); err != nil {
-		return nil
-	}
-
-	// Check for scope errors
-	for _, e := range resp.Errors {
-		if e.Type == "INSUFFICIENT_SCOPES" {
-			return nil
-		}
+		return nil // Graceful fallback, also without the project scope
 	}
 
 	// Build sets for comparison
 	remoteProjects := make(map[string]string) // title -> id
-	for _, item := range resp.Data.Node.ProjectItems.Nodes {
-		remoteProjects[item.Project.Title] = item.Project.ID
+	for _, raw := range items {
+		var item struct {
+			Project struct {
+				ID    string `json:"id"`
+				Title string `json:"title"`
+			} `json:"project"`
+		}
+		if json.Unmarshal(raw, &item) == nil {
+			remoteProjects[item.Project.Title] = item.Project.ID
+		}
 	}
 
 	localSet := make(map[string]struct{})
@@ -1646,3 +1928,300 @@ func (c *Client) CreateComment(ctx context.Context, issueNumber string, body str
 	_, err := c.runner.Run(ctx, "gh", c.withRepo(args)...)
 	return err
 }
+
+// Comment is a single issue comment.
+type Comment struct {
+	ID        string     `json:"id"`
+	Author    string     `json:"author,omitempty"`
+	Body      string     `json:"body"`
+	URL       string     `json:"url,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type apiComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      *apiUser  `json:"user"`
+}
+
+func (c apiComment) toComment() Comment {
+	comment := Comment{
+		ID:        strconv.FormatInt(c.ID, 10),
+		Body:      c.Body,
+		URL:       c.HTMLURL,
+		CreatedAt: &c.CreatedAt,
+		UpdatedAt: &c.UpdatedAt,
+	}
+	if c.User != nil {
+		comment.Author = c.User.Login
+	}
+	return comment
+}
+
+// GetComment fetches a single issue comment by its ID. GitHub comment IDs are
+// unique per repository, so issueNumber is not needed for the lookup.
+func (c *Client) GetComment(ctx context.Context, issueNumber, commentID string) (Comment, error) {
+	owner, repo := splitRepo(c.repo)
+	if owner == "" || repo == "" {
+		return Comment{}, fmt.Errorf("invalid repository format")
+	}
+	endpoint := fmt.Sprintf("repos/%s/%s/issues/comments/%s", owner, repo, commentID)
+	out, err := c.runner.Run(ctx, "gh", "api", endpoint)
+	if err != nil {
+		return Comment{}, err
+	}
+	var payload apiComment
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		return Comment{}, fmt.Errorf("failed to parse comment: %w", err)
+	}
+	return payload.toComment(), nil
+}
+
+// ListComments fetches the comments of an issue in the order they were
+// written. With since, only comments updated at or after it are returned,
+// which is how pull fetches new and edited comments of long threads.
+func (c *Client) ListComments(ctx context.Context, issueNumber string, since *time.Time) ([]Comment, error) {
+	endpoint := fmt.Sprintf("repos/%s/issues/%s/comments?per_page=100", c.repo, issueNumber)
+	if since != nil {
+		endpoint += "&since=" + since.UTC().Format(time.RFC3339)
+	}
+	out, err := c.runner.Run(ctx, "gh", "api", endpoint, "--paginate", "-q", ".[]")
+	if err != nil {
+		return nil, err
+	}
+	// Output is newline-delimited JSON objects
+	var comments []Comment
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var payload apiComment
+		if err := json.Unmarshal([]byte(line), &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse comment: %w", err)
+		}
+		comments = append(comments, payload.toComment())
+	}
+	return comments, nil
+}
+
+// PullRequest is a pull request (a merge request on GitLab) with the files
+// it changes.
+type PullRequest struct {
+	Number string
+	Title  string
+	URL    string
+	Author string
+	Files  []ChangedFile
+}
+
+// ChangedFile is a file changed by a pull request or commit.
+type ChangedFile struct {
+	Path      string
+	Additions int
+	Deletions int
+}
+
+// GetPullRequest fetches a pull request and its changed files.
+func (c *Client) GetPullRequest(ctx context.Context, number string) (PullRequest, error) {
+	out, err := c.runner.Run(ctx, "gh", "api", fmt.Sprintf("repos/%s/pulls/%s", c.repo, number))
+	if err != nil {
+		return PullRequest{}, err
+	}
+	var payload struct {
+		Title   string   `json:"title"`
+		HTMLURL string   `json:"html_url"`
+		User    *apiUser `json:"user"`
+	}
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		return PullRequest{}, fmt.Errorf("failed to parse pull request: %w", err)
+	}
+	pr := PullRequest{Number: number, Title: payload.Title, URL: payload.HTMLURL}
+	if payload.User != nil {
+		pr.Author = payload.User.Login
+	}
+
+	endpoint := fmt.Sprintf("repos/%s/pulls/%s/files", c.repo, number)
+	out, err = c.runner.Run(ctx, "gh", "api", endpoint, "--paginate", "-q", ".[] | {filename, additions, deletions}")
+	if err != nil {
+		return PullRequest{}, err
+	}
+	// Output is newline-delimited JSON objects
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var f struct {
+			Filename  string `json:"filename"`
+			Additions int    `json:"additions"`
+			Deletions int    `json:"deletions"`
+		}
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			return PullRequest{}, fmt.Errorf("failed to parse file JSON %q: %w", line, err)
+		}
+		pr.Files = append(pr.Files, ChangedFile{Path: f.Filename, Additions: f.Additions, Deletions: f.Deletions})
+	}
+	return pr, nil
+}
+
+// Team is an organization team that can be mentioned as @org/slug.
+type Team struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// ListTeams fetches the teams of the organization that owns the repository.
+// Returns an empty list (not an error) for user-owned repositories or when
+// the token cannot read the organization's teams.
+func (c *Client) ListTeams(ctx context.Context) ([]Team, error) {
+	owner, _ := splitRepo(c.repo)
+	if owner == "" {
+		return nil, fmt.Errorf("invalid repository format")
+	}
+	endpoint := fmt.Sprintf("orgs/%s/teams", owner)
+	out, err := c.runner.Run(ctx, "gh", "api", endpoint, "--paginate", "-q", ".[] | {slug, name}")
+	if err != nil {
+		return nil, nil
+	}
+	var teams []Team
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var team Team
+		if err := json.Unmarshal([]byte(line), &team); err != nil {
+			return nil, fmt.Errorf("failed to parse team JSON %q: %w", line, err)
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+// Autolink is a custom reference configured for the repository, e.g.
+// JIRA- linking to https://jira.example.com/browse/JIRA-<num>.
+type Autolink struct {
+	KeyPrefix      string `json:"key_prefix"`
+	URLTemplate    string `json:"url_template"`
+	IsAlphanumeric bool   `json:"is_alphanumeric"`
+}
+
+// ListAutolinks fetches the autolink references of the repository. Reading
+// them requires admin access, so an error yields an empty list like for
+// teams.
+func (c *Client) ListAutolinks(ctx context.Context) ([]Autolink, error) {
+	if c.repo == "" {
+		return nil, fmt.Errorf("invalid repository format")
+	}
+	endpoint := fmt.Sprintf("repos/%s/autolinks", c.repo)
+	out, err := c.runner.Run(ctx, "gh", "api", endpoint, "--paginate", "-q", ".[] | {key_prefix, url_template, is_alphanumeric}")
+	if err != nil {
+		return nil, nil
+	}
+	var autolinks []Autolink
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var autolink Autolink
+		if err := json.Unmarshal([]byte(line), &autolink); err != nil {
+			return nil, fmt.Errorf("failed to parse autolink JSON %q: %w", line, err)
+		}
+		autolinks = append(autolinks, autolink)
+	}
+	return autolinks, nil
+}
+
+// ListTeamMembers returns the logins of the members of an organization team.
+func (c *Client) ListTeamMembers(ctx context.Context, slug string) ([]string, error) {
+	owner, _ := splitRepo(c.repo)
+	if owner == "" {
+		return nil, fmt.Errorf("invalid repository format")
+	}
+	endpoint := fmt.Sprintf("orgs/%s/teams/%s/members", owner, slug)
+	out, err := c.runner.Run(ctx, "gh", "api", endpoint, "--paginate", "-q", ".[].login")
+	if err != nil {
+		return nil, err
+	}
+	var members []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			members = append(members, line)
+		}
+	}
+	return members, nil
+}
+
+// Notification is a GitHub notification thread for the repository.
+type Notification struct {
+	ID          string
+	Reason      string // e.g. "mention", "assign", "subscribed"
+	Unread      bool
+	UpdatedAt   time.Time
+	SubjectType string // "Issue", "PullRequest", ...
+	Title       string
+	Number      string // Issue or pull request number, if any
+}
+
+// ListNotifications fetches the user's notifications for the repository.
+// Only unread notifications are returned unless all is set.
+func (c *Client) ListNotifications(ctx context.Context, all bool) ([]Notification, error) {
+	endpoint := fmt.Sprintf("repos/%s/notifications", c.repo)
+	if all {
+		endpoint += "?all=true"
+	}
+	out, err := c.runner.Run(ctx, "gh", "api", endpoint, "--paginate",
+		"-q", ".[] | {id, reason, unread, updated_at, subject: {title: .subject.title, type: .subject.type, url: .subject.url}}")
+	if err != nil {
+		return nil, err
+	}
+	var notifications []Notification
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var raw struct {
+			ID        string    `json:"id"`
+			Reason    string    `json:"reason"`
+			Unread    bool      `json:"unread"`
+			UpdatedAt time.Time `json:"updated_at"`
+			Subject   struct {
+				Title string `json:"title"`
+				Type  string `json:"type"`
+				URL   string `json:"url"`
+			} `json:"subject"`
+		}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse notification JSON %q: %w", line, err)
+		}
+		n := Notification{
+			ID:          raw.ID,
+			Reason:      raw.Reason,
+			Unread:      raw.Unread,
+			UpdatedAt:   raw.UpdatedAt,
+			SubjectType: raw.Subject.Type,
+			Title:       raw.Subject.Title,
+		}
+		// Subject URLs look like https://api.github.com/repos/o/r/issues/42
+		if idx := strings.LastIndex(raw.Subject.URL, "/"); idx >= 0 {
+			if _, err := strconv.Atoi(raw.Subject.URL[idx+1:]); err == nil {
+				n.Number = raw.Subject.URL[idx+1:]
+			}
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead marks a notification thread as read.
+func (c *Client) MarkNotificationRead(ctx context.Context, id string) error {
+	_, err := c.runner.Run(ctx, "gh", "api", "--method", "PATCH", "notifications/threads/"+id)
+	return err
+}
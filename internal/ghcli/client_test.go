@@ -2,8 +2,14 @@ package ghcli
 
 import (
 	"context"
+	"errors"
+	"os/exec"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
 )
 
 type recordingRunner struct {
@@ -70,7 +76,7 @@ func TestCloseIssueReasonNormalization(t *testing.T) {
 			runner := &recordingRunner{}
 			client := NewClient(runner, "octo/repo")
 
-			if err := client.CloseIssue(context.Background(), "929", tc.reason); err != nil {
+			if _, err := client.CloseIssue(context.Background(), "929", tc.reason); err != nil {
 				t.Fatalf("close issue: %v", err)
 			}
 
@@ -89,8 +95,246 @@ func TestCloseIssueRejectsNonCanonicalReason(t *testing.T) {
 	runner := &recordingRunner{}
 	client := NewClient(runner, "octo/repo")
 
-	err := client.CloseIssue(context.Background(), "929", "not planned")
+	_, err := client.CloseIssue(context.Background(), "929", "not planned")
 	if err == nil {
 		t.Fatalf("expected error")
 	}
 }
+
+type fixedRunner struct {
+	out string
+	err error
+}
+
+func (r fixedRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	return r.out, r.err
+}
+
+func TestReopenIssueReturnsUpdatedAt(t *testing.T) {
+	client := NewClient(fixedRunner{out: `{"number":929,"state":"open","updated_at":"2026-03-01T12:10:00Z"}`}, "octo/repo")
+	updatedAt, err := client.ReopenIssue(context.Background(), "929")
+	if err != nil {
+		t.Fatalf("reopen issue: %v", err)
+	}
+	if want := time.Date(2026, 3, 1, 12, 10, 0, 0, time.UTC); !updatedAt.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, updatedAt)
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	out := "HTTP/2.0 200 OK\r\nContent-Type: application/json\r\nX-Oauth-Scopes: gist, read:org, repo\r\n\r\n{\"login\":\"octocat\"}"
+	status, err := NewClient(fixedRunner{out: out}, "octo/repo").CheckAuth(context.Background())
+	if err != nil {
+		t.Fatalf("check auth: %v", err)
+	}
+	if status.User != "octocat" || !reflect.DeepEqual(status.Scopes, []string{"gist", "read:org", "repo"}) {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if len(status.Missing) != 0 || !reflect.DeepEqual(status.Recommended, []string{"project"}) {
+		t.Fatalf("unexpected missing scopes: %+v", status)
+	}
+
+	_, err = NewClient(fixedRunner{err: errors.New("gh api user failed: To get started with GitHub CLI, please run:  gh auth login")}, "octo/repo").CheckAuth(context.Background())
+	if !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("expected ErrNotLoggedIn, got %v", err)
+	}
+	_, err = NewClient(fixedRunner{err: &exec.Error{Name: "gh", Err: exec.ErrNotFound}}, "octo/repo").CheckAuth(context.Background())
+	if !errors.Is(err, ErrCLINotFound) {
+		t.Fatalf("expected ErrCLINotFound, got %v", err)
+	}
+}
+
+// probeRunner answers the capability probes by matching the query text.
+type probeRunner struct {
+	responses map[string]string
+}
+
+func (r probeRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	query := strings.Join(args, " ")
+	for needle, out := range r.responses {
+		if strings.Contains(query, needle) {
+			return out, nil
+		}
+	}
+	return "", errors.New("gh: unexpected query")
+}
+
+func TestProbeCapabilities(t *testing.T) {
+	runner := probeRunner{responses: map[string]string{
+		"issueTypes":       `{"data":{"repository":{"issueTypes":{"totalCount":3}}}}`,
+		"projectsV2":       `{"data":null,"errors":[{"type":"INSUFFICIENT_SCOPES","message":"Your token has not been granted the required scopes"}]}`,
+		"subIssuesSummary": `{"data":{"repository":{"issues":{"nodes":[]}}}}`,
+	}}
+	caps, err := NewClient(runner, "octo/repo").ProbeCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if caps != (Capabilities{IssueTypes: true, Projects: false, SubIssues: true}) {
+		t.Fatalf("unexpected capabilities: %+v", caps)
+	}
+
+	runner.responses["issueTypes"] = `{"data":{"repository":{"issueTypes":null}}}`
+	caps, err = NewClient(runner, "octo/repo").ProbeCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if caps.IssueTypes {
+		t.Fatalf("expected issue types unavailable for a personal repo")
+	}
+
+	_, err = NewClient(fixedRunner{err: &exec.Error{Name: "gh", Err: exec.ErrNotFound}}, "octo/repo").ProbeCapabilities(context.Background())
+	if !errors.Is(err, ErrCLINotFound) {
+		t.Fatalf("expected ErrCLINotFound, got %v", err)
+	}
+}
+
+type pullRequestRunner struct{}
+
+func (pullRequestRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	if strings.HasSuffix(args[1], "/files") {
+		return "{\"filename\":\"docs/a.md\",\"additions\":3,\"deletions\":1}\n{\"filename\":\"main.go\",\"additions\":10,\"deletions\":0}\n", nil
+	}
+	return `{"title":"Fix login","html_url":"https://github.com/octo/repo/pull/12","user":{"login":"octocat"}}`, nil
+}
+
+func TestGetPullRequest(t *testing.T) {
+	pr, err := NewClient(pullRequestRunner{}, "octo/repo").GetPullRequest(context.Background(), "12")
+	if err != nil {
+		t.Fatalf("get pull request: %v", err)
+	}
+	want := PullRequest{
+		Number: "12",
+		Title:  "Fix login",
+		URL:    "https://github.com/octo/repo/pull/12",
+		Author: "octocat",
+		Files:  []ChangedFile{{Path: "docs/a.md", Additions: 3, Deletions: 1}, {Path: "main.go", Additions: 10}},
+	}
+	if !reflect.DeepEqual(pr, want) {
+		t.Fatalf("got %+v, want %+v", pr, want)
+	}
+}
+
+func TestListIssuesResumesAfterCursor(t *testing.T) {
+	runner := probeRunner{responses: map[string]string{
+		`after: "c1"`: `{"data":{"repository":{"issues":{"totalCount":3,"pageInfo":{"hasNextPage":false,"endCursor":"c2"},"nodes":[{"number":3,"title":"Third","state":"OPEN"}]}}}}`,
+	}}
+	var cursors []string
+	result, err := NewClient(runner, "octo/repo").ListIssuesWithRelationships(context.Background(), ListIssuesOptions{
+		State: "all",
+		After: "c1",
+		OnPage: func(cursor string, issues []issue.Issue) error {
+			cursors = append(cursors, cursor)
+			if len(issues) != 1 || issues[0].Number != "3" {
+				t.Fatalf("unexpected page issues: %+v", issues)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("list issues: %v", err)
+	}
+	if len(result.Issues) != 1 || !reflect.DeepEqual(cursors, []string{"c2"}) {
+		t.Fatalf("got %d issue(s) and cursors %v", len(result.Issues), cursors)
+	}
+}
+
+// filterRunner knows one milestone and records the issues query.
+type filterRunner struct {
+	query *string
+}
+
+func (r filterRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	if strings.Contains(args[1], "milestones?state=open") {
+		return `{"number":4,"title":"v2.0"}`, nil
+	}
+	if strings.Contains(args[1], "milestones") {
+		return "", nil
+	}
+	*r.query = strings.Join(args, " ")
+	return `{"data":{"repository":{"issues":{"totalCount":0,"pageInfo":{"hasNextPage":false},"nodes":[]}}}}`, nil
+}
+
+func TestListIssuesFilters(t *testing.T) {
+	var query string
+	client := NewClient(filterRunner{query: &query}, "octo/repo")
+	_, err := client.ListIssuesWithRelationships(context.Background(), ListIssuesOptions{State: "open", Milestone: "v2.0", Assignee: "alice"})
+	if err != nil {
+		t.Fatalf("list issues: %v", err)
+	}
+	if !strings.Contains(query, `filterBy: {assignee: "alice", milestoneNumber: "4"}`) {
+		t.Fatalf("expected the filters in the query:\n%s", query)
+	}
+	_, err = client.ListIssuesWithRelationships(context.Background(), ListIssuesOptions{State: "open", Milestone: "v3.0"})
+	if err == nil || !strings.Contains(err.Error(), `unknown milestone "v3.0"`) {
+		t.Fatalf("expected an unknown milestone error, got %v", err)
+	}
+}
+
+// closedMilestonesFailRunner lists open milestones but fails the closed ones.
+type closedMilestonesFailRunner struct{}
+
+func (closedMilestonesFailRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	if strings.Contains(args[1], "milestones?state=open") {
+		return `{"number":4,"title":"v2.0","state":"open"}`, nil
+	}
+	return "", errors.New("HTTP 502: Bad Gateway")
+}
+
+func TestListMilestonesFailsOnPartialListing(t *testing.T) {
+	client := NewClient(closedMilestonesFailRunner{}, "octo/repo")
+	milestones, err := client.ListMilestones(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "listing closed milestones") {
+		t.Fatalf("expected the closed listing to fail, got %v (%d milestones)", err, len(milestones))
+	}
+}
+
+// createRunner answers the ID lookup of CreateIssue and records the
+// mutation.
+type createRunner struct {
+	mutation *string
+}
+
+func (r createRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	query := strings.Join(args, " ")
+	if strings.Contains(query, "createIssue") {
+		*r.mutation = query
+		return `{"data":{"createIssue":{"issue":{"number":42}}}}`, nil
+	}
+	return `{"data":{
+		"repository":{"id":"R_1","label0":{"id":"L_bug","name":"bug"},
+			"milestones":{"nodes":[{"id":"M_1","title":"v1"}]},
+			"issueTypes":{"nodes":[{"id":"IT_bug","name":"Bug"}]}},
+		"user0":{"id":"U_alice","login":"alice"},
+		"repositoryOwner":{"projectsV2":{"nodes":[{"id":"P_1","title":"Roadmap"}]}}}}`, nil
+}
+
+func TestCreateIssueSetsAllFields(t *testing.T) {
+	var mutation string
+	client := NewClient(createRunner{mutation: &mutation}, "octo/repo")
+	number, err := client.CreateIssue(context.Background(), issue.Issue{
+		Title:     "Crash",
+		Body:      "Boom",
+		Labels:    []string{"Bug"},
+		Assignees: []string{"alice"},
+		Milestone: "v1",
+		IssueType: "bug",
+		Projects:  []string{"Roadmap"},
+	})
+	if err != nil {
+		t.Fatalf("create issue: %v", err)
+	}
+	if number != "42" {
+		t.Fatalf("expected #42, got %q", number)
+	}
+	for _, want := range []string{`repositoryId: "R_1"`, `labelIds: ["L_bug"]`, `assigneeIds: ["U_alice"]`,
+		`projectV2Ids: ["P_1"]`, `milestoneId: "M_1"`, `issueTypeId: "IT_bug"`} {
+		if !strings.Contains(mutation, want) {
+			t.Fatalf("expected %s in the mutation:\n%s", want, mutation)
+		}
+	}
+
+	_, err = client.CreateIssue(context.Background(), issue.Issue{Title: "Crash", Labels: []string{"missing"}})
+	if err == nil || !strings.Contains(err.Error(), `label "missing" not found`) {
+		t.Fatalf("expected an unknown label error, got %v", err)
+	}
+}
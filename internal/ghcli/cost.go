@@ -0,0 +1,110 @@
+package ghcli
+
+import (
+	"strings"
+	"sync"
+)
+
+// Budgets for batched GraphQL documents. GitHub rejects queries that may
+// return more than 500,000 nodes, but large batches time out or hit the
+// resource limits long before that, and gh passes the document as a single
+// argument, which Linux caps at 128 KiB.
+const (
+	maxQueryNodes = 10000
+	maxQueryBytes = 96 << 10
+)
+
+// issueQueryNodes is the number of nodes one issue of GetIssuesBatch may
+// return: the issue, 100 labels, 100 assignees, 20 project items and 100
+// issues each blocking it and blocked by it.
+const issueQueryNodes = 1 + 100 + 100 + 20 + 100 + 100
+
+// aliasCost estimates what one alias of a batched document asks of GitHub.
+type aliasCost struct {
+	Nodes int // nodes it may return
+	Bytes int // size it adds to the document
+}
+
+// chunkByCost splits items into batches of at most maxAliases whose
+// estimated cost stays within the node and size budgets. An item over
+// budget on its own still gets a batch.
+func chunkByCost[T any](items []T, maxAliases int, cost func(T) aliasCost) [][]T {
+	var chunks [][]T
+	start, nodes, size := 0, 0, 0
+	for i, item := range items {
+		c := cost(item)
+		if i > start && (i-start >= maxAliases || nodes+c.Nodes > maxQueryNodes || size+c.Bytes > maxQueryBytes) {
+			chunks = append(chunks, items[start:i])
+			start, nodes, size = i, 0, 0
+		}
+		nodes += c.Nodes
+		size += c.Bytes
+	}
+	if start < len(items) {
+		chunks = append(chunks, items[start:])
+	}
+	return chunks
+}
+
+// isQueryCostError reports whether err says a GraphQL document was too
+// expensive for GitHub to run, so a smaller batch may succeed.
+func isQueryCostError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return isQueryCostErrorText(err.Error())
+}
+
+func isQueryCostErrorText(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, text := range []string{
+		"max_node_limit_exceeded",
+		"resource limits for this query exceeded",
+		"query has complexity",
+		"something went wrong while executing your query",
+		"timedout",
+		"argument list too long",
+	} {
+		if strings.Contains(msg, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryCost sums up the batched GraphQL calls of a client.
+type QueryCost struct {
+	Queries int // documents sent
+	Points  int // rate limit points GitHub charged, where reported
+	Nodes   int // nodes the documents could return, estimated
+	Splits  int // batches split in half after a cost error
+}
+
+// QueryStats collects the QueryCost of the clients sharing it. A nil
+// QueryStats collects nothing.
+type QueryStats struct {
+	mu    sync.Mutex
+	total QueryCost
+}
+
+func (s *QueryStats) add(cost QueryCost) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total.Queries += cost.Queries
+	s.total.Points += cost.Points
+	s.total.Nodes += cost.Nodes
+	s.total.Splits += cost.Splits
+}
+
+// Total returns what was collected so far.
+func (s *QueryStats) Total() QueryCost {
+	if s == nil {
+		return QueryCost{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
@@ -0,0 +1,99 @@
+package ghcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestChunkByCost(t *testing.T) {
+	sizes := []int{10, 10, 50, 90, 10}
+	cost := func(size int) aliasCost { return aliasCost{Nodes: size * 100, Bytes: size} }
+	got := chunkByCost(sizes, 3, cost)
+	// 10+10+50 fit the node budget, 90 more would not
+	want := "[[10 10 50] [90 10]]"
+	if fmt.Sprint(got) != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+	if got := chunkByCost([]int{10, 10, 10, 10}, 3, cost); len(got) != 2 || len(got[0]) != 3 {
+		t.Fatalf("expected the alias cap to split, got %v", got)
+	}
+	if got := chunkByCost([]int{500, 1}, 3, cost); len(got) != 2 || got[0][0] != 500 {
+		t.Fatalf("expected an expensive item on its own, got %v", got)
+	}
+}
+
+// costLimitRunner answers batched issue queries, failing those with more
+// than limit issues the way GitHub does when a query costs too much.
+type costLimitRunner struct {
+	limit   int
+	queries int
+}
+
+var issueAliasPattern = regexp.MustCompile(`issue\d+: issue\(number: (\d+)\)`)
+
+func (r *costLimitRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	r.queries++
+	var query string
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-f" && strings.HasPrefix(args[i+1], "query=") {
+			query = args[i+1]
+		}
+	}
+	matches := issueAliasPattern.FindAllStringSubmatch(query, -1)
+	if len(matches) > r.limit {
+		return "", errors.New("gh: Resource limits for this query exceeded.")
+	}
+	var aliases []string
+	for i, m := range matches {
+		aliases = append(aliases, fmt.Sprintf(`"issue%d":{"number":%s,"title":"Issue %s","state":"OPEN","labels":{"nodes":[]},"assignees":{"nodes":[]}}`, i, m[1], m[1]))
+	}
+	return fmt.Sprintf(`{"data":{"repository":{%s},"rateLimit":{"cost":1}}}`, strings.Join(aliases, ",")), nil
+}
+
+func TestGetIssuesBatchSplitsOnCostError(t *testing.T) {
+	runner := &costLimitRunner{limit: 4}
+	client := NewClient(runner, "octo/repo")
+	stats := &QueryStats{}
+	client.SetStats(stats)
+
+	numbers := make([]string, 10)
+	for i := range numbers {
+		numbers[i] = fmt.Sprint(i + 1)
+	}
+	issues, err := client.GetIssuesBatch(context.Background(), numbers)
+	if err != nil {
+		t.Fatalf("GetIssuesBatch: %v", err)
+	}
+	if len(issues) != 10 || issues["7"].Title != "Issue 7" {
+		t.Fatalf("expected all 10 issues, got %d", len(issues))
+	}
+	// 10 fails, both halves of 5 fail, and the four chunks of 2 or 3 pass
+	total := stats.Total()
+	if total.Splits != 3 || total.Queries != 7 || runner.queries != 7 {
+		t.Fatalf("unexpected stats %+v after %d queries", total, runner.queries)
+	}
+	if total.Points != 4 || total.Nodes != (10+5+5+2+3+2+3)*issueQueryNodes {
+		t.Fatalf("unexpected cost %+v", total)
+	}
+
+	var nilStats *QueryStats
+	nilStats.add(QueryCost{Queries: 1})
+	if nilStats.Total() != (QueryCost{}) {
+		t.Fatal("expected a nil QueryStats to collect nothing")
+	}
+}
+
+func TestBatchUpdateCostCountsBody(t *testing.T) {
+	short, long := "short", strings.Repeat("x", 200<<10)
+	if c := batchUpdateCost(BatchIssueUpdate{Number: "1", Body: &short}); c.Bytes >= maxQueryBytes {
+		t.Fatalf("expected a short body within budget, got %+v", c)
+	}
+	updates := []BatchIssueUpdate{{Number: "1", Body: &short}, {Number: "2", Body: &long}, {Number: "3", Body: &short}}
+	if chunks := chunkByCost(updates, batchChunkSize, batchUpdateCost); len(chunks) != 3 {
+		t.Fatalf("expected a long body to get a mutation of its own, got %d chunks", len(chunks))
+	}
+}
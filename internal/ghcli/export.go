@@ -0,0 +1,156 @@
+package ghcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// exportIssue is an issue in a bulk dump of the REST API, such as
+// `gh api --paginate repos/OWNER/REPO/issues?state=all`, or in the
+// issues_*.json files of a GitHub migration archive. The archive refers
+// to users and labels by URL instead of embedding them.
+type exportIssue struct {
+	URL         string          `json:"url"`
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	StateReason *string         `json:"state_reason"`
+	User        exportName      `json:"user"`
+	Labels      []exportName    `json:"labels"`
+	Assignees   []exportName    `json:"assignees"`
+	Milestone   json.RawMessage `json:"milestone"`
+	Comments    json.RawMessage `json:"comments"`
+	Reactions   *struct {
+		TotalCount int `json:"total_count"`
+	} `json:"reactions"`
+	CreatedAt   string          `json:"created_at"`
+	UpdatedAt   string          `json:"updated_at"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+// exportName is a user or label: an object with a login or name, or the
+// URL of one.
+type exportName string
+
+func (n *exportName) UnmarshalJSON(data []byte) error {
+	var ref string
+	if err := json.Unmarshal(data, &ref); err == nil {
+		name, err := url.PathUnescape(path.Base(ref))
+		if err != nil {
+			name = path.Base(ref)
+		}
+		*n = exportName(name)
+		return nil
+	}
+	var obj struct {
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*n = exportName(obj.Login + obj.Name)
+	return nil
+}
+
+func (e exportIssue) toIssue() issue.Issue {
+	number := strconv.Itoa(e.Number)
+	if e.Number == 0 {
+		number = path.Base(e.URL)
+	}
+	iss := issue.Issue{
+		Number:      issue.IssueNumber(number),
+		Title:       e.Title,
+		Body:        e.Body,
+		State:       strings.ToLower(e.State),
+		StateReason: canonicalStateReasonPtr(e.StateReason),
+		Labels:      make([]string, 0, len(e.Labels)),
+		Assignees:   make([]string, 0, len(e.Assignees)),
+		Author:      string(e.User),
+	}
+	for _, label := range e.Labels {
+		iss.Labels = append(iss.Labels, string(label))
+	}
+	for _, assignee := range e.Assignees {
+		iss.Assignees = append(iss.Assignees, string(assignee))
+	}
+	// The archive links milestones by URL, which has no title
+	var milestone apiMilestone
+	if json.Unmarshal(e.Milestone, &milestone) == nil {
+		iss.Milestone = milestone.Title
+	}
+	// The REST API counts comments, the archive keeps them elsewhere
+	json.Unmarshal(e.Comments, &iss.CommentCount)
+	if e.Reactions != nil {
+		iss.Reactions = e.Reactions.TotalCount
+	}
+	if t, err := time.Parse(time.RFC3339, e.CreatedAt); err == nil {
+		iss.CreatedAt = &t
+	}
+	if t, err := time.Parse(time.RFC3339, e.UpdatedAt); err == nil {
+		iss.UpdatedAt = &t
+	}
+	return iss
+}
+
+// ParseIssuesExport reads the issues of a bulk dump. It accepts the
+// concatenated pages `gh api --paginate` prints, one array of pages
+// (--slurp), a single array, or one issue per line. Pull requests, which
+// the REST API lists as issues, are left out.
+func ParseIssuesExport(data []byte) ([]issue.Issue, error) {
+	var issues []issue.Issue
+	var add func(raw json.RawMessage) error
+	add = func(raw json.RawMessage) error {
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 && raw[0] == '[' {
+			var items []json.RawMessage
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return err
+			}
+			for _, item := range items {
+				if err := add(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		var item exportIssue
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return err
+		}
+		if len(item.PullRequest) > 0 && string(item.PullRequest) != "null" {
+			return nil
+		}
+		iss := item.toIssue()
+		if _, err := strconv.Atoi(iss.Number.String()); err != nil {
+			return fmt.Errorf("issue %q has no number", item.Title)
+		}
+		issues = append(issues, iss)
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return issues, nil
+			}
+			return nil, fmt.Errorf("failed to parse export: %w", err)
+		}
+		if err := add(raw); err != nil {
+			return nil, fmt.Errorf("failed to parse export: %w", err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ghcli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIssuesExport(t *testing.T) {
+	// Two pages as printed by gh api --paginate, with a pull request
+	paginated := `[{"number":1,"title":"Crash on start","body":"Boom","state":"open","user":{"login":"alice"},"labels":[{"name":"bug","color":"d73a4a"}],"assignees":[{"login":"bob"}],"milestone":{"title":"v1"},"comments":2,"reactions":{"total_count":5},"created_at":"2026-01-02T03:04:05Z","updated_at":"2026-02-03T04:05:06Z"},
+{"number":2,"title":"Add login","state":"open","pull_request":{"url":"https://api.github.com/repos/o/r/pulls/2"}}]
+[{"number":3,"title":"Old","state":"closed","state_reason":"not_planned","labels":[],"assignees":[]}]
+`
+	issues, err := ParseIssuesExport([]byte(paginated))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected the pull request left out, got %+v", issues)
+	}
+	first := issues[0]
+	if first.Number != "1" || first.Author != "alice" || !reflect.DeepEqual(first.Labels, []string{"bug"}) ||
+		!reflect.DeepEqual(first.Assignees, []string{"bob"}) || first.Milestone != "v1" || first.CommentCount != 2 || first.Reactions != 5 ||
+		first.UpdatedAt == nil || first.UpdatedAt.Month() != 2 {
+		t.Fatalf("unexpected issue: %+v", first)
+	}
+	if issues[1].State != "closed" || issues[1].StateReason == nil || *issues[1].StateReason != "not_planned" {
+		t.Fatalf("unexpected closed issue: %+v", issues[1])
+	}
+
+	// Migration archives link users and labels and have no number field
+	archive := `[{"url":"https://github.com/o/r/issues/7","title":"From the archive","state":"open","user":"https://github.com/carol","labels":["https://github.com/o/r/labels/good%20first%20issue"],"milestone":"https://github.com/o/r/milestones/1"}]`
+	issues, err = ParseIssuesExport([]byte(archive))
+	if err != nil {
+		t.Fatalf("parse archive: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Number != "7" || issues[0].Author != "carol" ||
+		!reflect.DeepEqual(issues[0].Labels, []string{"good first issue"}) || issues[0].Milestone != "" {
+		t.Fatalf("unexpected archive issue: %+v", issues)
+	}
+
+	if _, err := ParseIssuesExport([]byte(`{"title":"No number"}`)); err == nil {
+		t.Fatal("expected an error for an issue without a number")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
 )
@@ -17,6 +18,144 @@ type IssueRelationships struct {
 	Blocks    []issue.IssueRef
 	IssueType string
 	Projects  []string
+	SubIssues *issue.SubIssueSummary
+	// ReferencedBy lists cross-references, see issue.Issue.ReferencedBy.
+	ReferencedBy []string
+	// PullRequests and Branches are the development links of the issue.
+	PullRequests []issue.LinkedPullRequest
+	Branches     []string
+	Comments     int
+	Reactions    int
+}
+
+// crossReferencesFragment selects the issues and pull requests that
+// mention an issue.
+const crossReferencesFragment = `crossReferences: timelineItems(itemTypes: [CROSS_REFERENCED_EVENT], last: 50) {
+        nodes {
+          ... on CrossReferencedEvent {
+            source {
+              ... on Issue { number repository { nameWithOwner } }
+              ... on PullRequest { number repository { nameWithOwner } }
+            }
+          }
+        }
+      }`
+
+// graphqlCrossReferences is the response shape of crossReferencesFragment.
+type graphqlCrossReferences struct {
+	Nodes []struct {
+		Source *struct {
+			Number     int `json:"number"`
+			Repository struct {
+				NameWithOwner string `json:"nameWithOwner"`
+			} `json:"repository"`
+		} `json:"source"`
+	} `json:"nodes"`
+}
+
+// refs returns the distinct referencing issues, using bare numbers for
+// references from repo itself.
+func (x *graphqlCrossReferences) refs(repo string) []string {
+	if x == nil {
+		return nil
+	}
+	var refs []string
+	seen := map[string]struct{}{}
+	for _, node := range x.Nodes {
+		if node.Source == nil || node.Source.Number == 0 {
+			continue
+		}
+		ref := strconv.Itoa(node.Source.Number)
+		if !strings.EqualFold(node.Source.Repository.NameWithOwner, repo) {
+			ref = node.Source.Repository.NameWithOwner + "#" + ref
+		}
+		if _, ok := seen[ref]; ok {
+			continue
+		}
+		seen[ref] = struct{}{}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// developmentFragment selects the pull requests that close an issue and
+// the branches linked from its development section.
+const developmentFragment = `closedByPullRequestsReferences(first: 10, includeClosedPrs: true) {
+        nodes {
+          number
+          state
+          isDraft
+          headRefName
+          statusCheckRollup { state }
+        }
+      }
+      linkedBranches(first: 10) { nodes { ref { name } } }`
+
+// graphqlClosingPullRequests is the response shape of the pull requests in
+// developmentFragment.
+type graphqlClosingPullRequests struct {
+	Nodes []struct {
+		Number            int    `json:"number"`
+		State             string `json:"state"`
+		IsDraft           bool   `json:"isDraft"`
+		HeadRefName       string `json:"headRefName"`
+		StatusCheckRollup *struct {
+			State string `json:"state"`
+		} `json:"statusCheckRollup"`
+	} `json:"nodes"`
+}
+
+// pullRequests converts the response, reporting open drafts as "draft".
+func (x *graphqlClosingPullRequests) pullRequests() []issue.LinkedPullRequest {
+	if x == nil {
+		return nil
+	}
+	var prs []issue.LinkedPullRequest
+	for _, node := range x.Nodes {
+		pr := issue.LinkedPullRequest{
+			Number: node.Number,
+			State:  strings.ToLower(node.State),
+			Branch: node.HeadRefName,
+		}
+		if pr.State == "open" && node.IsDraft {
+			pr.State = "draft"
+		}
+		if node.StatusCheckRollup != nil {
+			pr.Checks = strings.ToLower(node.StatusCheckRollup.State)
+		}
+		prs = append(prs, pr)
+	}
+	return prs
+}
+
+// graphqlLinkedBranches is the response shape of the branches in
+// developmentFragment.
+type graphqlLinkedBranches struct {
+	Nodes []struct {
+		Ref *struct {
+			Name string `json:"name"`
+		} `json:"ref"`
+	} `json:"nodes"`
+}
+
+// names returns the names of branches that still exist.
+func (x *graphqlLinkedBranches) names() []string {
+	if x == nil {
+		return nil
+	}
+	var names []string
+	for _, node := range x.Nodes {
+		if node.Ref != nil && node.Ref.Name != "" {
+			names = append(names, node.Ref.Name)
+		}
+	}
+	return names
+}
+
+// graphqlTotalCount is the response shape of a connection queried for its
+// size only.
+type graphqlTotalCount struct {
+	TotalCount int `json:"totalCount"`
 }
 
 // graphqlIssue represents the GraphQL response structure for an issue.
@@ -27,7 +166,8 @@ type graphqlIssue struct {
 		Name string `json:"name"`
 	} `json:"issueType"`
 	ProjectItems *struct {
-		Nodes []struct {
+		PageInfo pageInfo `json:"pageInfo"`
+		Nodes    []struct {
 			Project struct {
 				Title string `json:"title"`
 			} `json:"project"`
@@ -37,7 +177,13 @@ type graphqlIssue struct {
 		Number int    `json:"number"`
 		ID     string `json:"id"`
 	} `json:"parent"`
-	BlockedBy struct {
+	SubIssuesSummary *issue.SubIssueSummary      `json:"subIssuesSummary"`
+	CrossReferences  *graphqlCrossReferences     `json:"crossReferences"`
+	ClosingPRs       *graphqlClosingPullRequests `json:"closedByPullRequestsReferences"`
+	LinkedBranches   *graphqlLinkedBranches      `json:"linkedBranches"`
+	Comments         graphqlTotalCount           `json:"comments"`
+	Reactions        graphqlTotalCount           `json:"reactions"`
+	BlockedBy        struct {
 		Nodes []struct {
 			Number int    `json:"number"`
 			ID     string `json:"id"`
@@ -130,6 +276,7 @@ func (c *Client) getIssueRelationshipsBatchChunk(ctx context.Context, numbers []
 		if withProjects {
 			projectItemsFragment = `
       projectItems(first: 20) {
+        ` + pageInfoFields + `
         nodes {
           project { title }
         }
@@ -149,6 +296,14 @@ func (c *Client) getIssueRelationshipsBatchChunk(ctx context.Context, numbers []
         number
         id
       }
+      subIssuesSummary {
+        total
+        completed
+      }
+      comments { totalCount }
+      reactions { totalCount }
+      `+crossReferencesFragment+`
+      `+developmentFragment+`
       blockedBy(first: 100) {
         nodes {
           number
@@ -253,11 +408,26 @@ func (c *Client) getIssueRelationshipsBatchChunk(ctx context.Context, numbers []
 			for _, node := range issueData.ProjectItems.Nodes {
 				rels.Projects = append(rels.Projects, node.Project.Title)
 			}
+			if issueData.ProjectItems.PageInfo.HasNextPage {
+				more, err := c.moreIssueProjects(ctx, issueData.Number, issueData.ProjectItems.PageInfo.EndCursor)
+				if err != nil {
+					return nil, fmt.Errorf("failed to fetch projects of #%d: %w", issueData.Number, err)
+				}
+				rels.Projects = append(rels.Projects, more...)
+			}
 		}
 		if issueData.Parent != nil {
 			ref := issue.IssueRef(strconv.Itoa(issueData.Parent.Number))
 			rels.Parent = &ref
 		}
+		if issueData.SubIssuesSummary != nil && issueData.SubIssuesSummary.Total > 0 {
+			rels.SubIssues = issueData.SubIssuesSummary
+		}
+		rels.ReferencedBy = issueData.CrossReferences.refs(c.repo)
+		rels.PullRequests = issueData.ClosingPRs.pullRequests()
+		rels.Branches = issueData.LinkedBranches.names()
+		rels.Comments = issueData.Comments.TotalCount
+		rels.Reactions = issueData.Reactions.TotalCount
 		for _, node := range issueData.BlockedBy.Nodes {
 			rels.BlockedBy = append(rels.BlockedBy, issue.IssueRef(strconv.Itoa(node.Number)))
 		}
@@ -516,13 +686,14 @@ mutation($issueId: ID!, $blockingId: ID!) {
 
 // SyncRelationships syncs the parent and blocking relationships for an issue.
 // It compares the desired state (from local issue) with the current remote state
-// and makes the necessary mutations.
-func (c *Client) SyncRelationships(ctx context.Context, issueNumber string, local issue.Issue) error {
+// and makes the necessary mutations. It reports whether it changed anything.
+func (c *Client) SyncRelationships(ctx context.Context, issueNumber string, local issue.Issue) (bool, error) {
 	// Get current remote relationships
 	remote, _, err := c.GetIssueRelationships(ctx, issueNumber)
 	if err != nil {
-		return fmt.Errorf("failed to get remote relationships: %w", err)
+		return false, fmt.Errorf("failed to get remote relationships: %w", err)
 	}
+	changed := false
 
 	// Sync parent
 	localParent := ""
@@ -536,8 +707,9 @@ func (c *Client) SyncRelationships(ctx context.Context, issueNumber string, loca
 
 	if localParent != remoteParent {
 		if err := c.SetParent(ctx, issueNumber, localParent); err != nil {
-			return fmt.Errorf("failed to set parent: %w", err)
+			return changed, fmt.Errorf("failed to set parent: %w", err)
 		}
+		changed = true
 	}
 
 	// Sync blocked_by
@@ -556,8 +728,9 @@ func (c *Client) SyncRelationships(ctx context.Context, issueNumber string, loca
 	for ref := range localBlockedBy {
 		if _, ok := remoteBlockedBy[ref]; !ok {
 			if err := c.AddBlockedBy(ctx, issueNumber, ref); err != nil {
-				return fmt.Errorf("failed to add blocked_by %s: %w", ref, err)
+				return changed, fmt.Errorf("failed to add blocked_by %s: %w", ref, err)
 			}
+			changed = true
 		}
 	}
 
@@ -565,8 +738,9 @@ func (c *Client) SyncRelationships(ctx context.Context, issueNumber string, loca
 	for ref := range remoteBlockedBy {
 		if _, ok := localBlockedBy[ref]; !ok {
 			if err := c.RemoveBlockedBy(ctx, issueNumber, ref); err != nil {
-				return fmt.Errorf("failed to remove blocked_by %s: %w", ref, err)
+				return changed, fmt.Errorf("failed to remove blocked_by %s: %w", ref, err)
 			}
+			changed = true
 		}
 	}
 
@@ -592,8 +766,9 @@ func (c *Client) SyncRelationships(ctx context.Context, issueNumber string, loca
 	for ref := range localBlocks {
 		if _, ok := remoteBlocks[ref]; !ok {
 			if err := c.AddBlockedBy(ctx, ref, issueNumber); err != nil {
-				return fmt.Errorf("failed to add blocks %s: %w", ref, err)
+				return changed, fmt.Errorf("failed to add blocks %s: %w", ref, err)
 			}
+			changed = true
 		}
 	}
 
@@ -601,12 +776,13 @@ func (c *Client) SyncRelationships(ctx context.Context, issueNumber string, loca
 	for ref := range remoteBlocks {
 		if _, ok := localBlocks[ref]; !ok {
 			if err := c.RemoveBlockedBy(ctx, ref, issueNumber); err != nil {
-				return fmt.Errorf("failed to remove blocks %s: %w", ref, err)
+				return changed, fmt.Errorf("failed to remove blocks %s: %w", ref, err)
 			}
+			changed = true
 		}
 	}
 
-	return nil
+	return changed, nil
 }
 
 // splitRepo splits "owner/repo" into owner and repo parts.
@@ -633,8 +809,9 @@ type BatchIssueUpdate struct {
 
 // BatchUpdateResult contains the result of a batch update operation.
 type BatchUpdateResult struct {
-	Updated []string          // Issue numbers that were updated
-	Errors  map[string]string // Issue number -> error message
+	Updated   []string             // Issue numbers that were updated
+	Errors    map[string]string    // Issue number -> error message
+	UpdatedAt map[string]time.Time // Issue number -> updatedAt after the update
 }
 
 // batchChunkSize is the maximum number of issues to update in a single GraphQL call.
@@ -647,39 +824,68 @@ const batchChunkSize = 20
 // State changes, relationships, issue types, and projects must be handled separately.
 func (c *Client) BatchEditIssues(ctx context.Context, updates []BatchIssueUpdate) (BatchUpdateResult, error) {
 	result := BatchUpdateResult{
-		Errors: make(map[string]string),
+		Errors:    make(map[string]string),
+		UpdatedAt: make(map[string]time.Time),
 	}
 
 	if len(updates) == 0 {
 		return result, nil
 	}
 
-	// Process updates in chunks to avoid GitHub's resource limits
-	for i := 0; i < len(updates); i += batchChunkSize {
-		end := i + batchChunkSize
-		if end > len(updates) {
-			end = len(updates)
-		}
-		chunk := updates[i:end]
-
-		chunkResult, err := c.batchEditIssuesChunk(ctx, chunk)
-		if err != nil {
+	// Process updates in chunks sized to stay within GitHub's resource
+	// limits; long bodies make for large documents
+	for _, chunk := range chunkByCost(updates, batchChunkSize, batchUpdateCost) {
+		if err := c.batchEditIssuesSplitting(ctx, chunk, &result); err != nil {
 			return result, err
 		}
-
-		result.Updated = append(result.Updated, chunkResult.Updated...)
-		for k, v := range chunkResult.Errors {
-			result.Errors[k] = v
-		}
 	}
 
 	return result, nil
 }
 
+// batchUpdateCost estimates the cost of the mutation of u.
+func batchUpdateCost(u BatchIssueUpdate) aliasCost {
+	size := 200 + 40*(len(u.Labels)+len(u.Assignees))
+	if u.Title != nil {
+		size += len(strconv.Quote(*u.Title))
+	}
+	if u.Body != nil {
+		size += len(strconv.Quote(*u.Body))
+	}
+	return aliasCost{Nodes: 1, Bytes: size}
+}
+
+// batchEditIssuesSplitting applies a chunk of updates, halving the chunk
+// for as long as GitHub finds it too expensive. Updates set whole fields,
+// so repeating those of a failed chunk is safe.
+func (c *Client) batchEditIssuesSplitting(ctx context.Context, updates []BatchIssueUpdate, result *BatchUpdateResult) error {
+	chunkResult, err := c.batchEditIssuesChunk(ctx, updates)
+	if isQueryCostError(err) && len(updates) > 1 {
+		c.stats.add(QueryCost{Splits: 1})
+		half := len(updates) / 2
+		if err := c.batchEditIssuesSplitting(ctx, updates[:half], result); err != nil {
+			return err
+		}
+		return c.batchEditIssuesSplitting(ctx, updates[half:], result)
+	}
+	if err != nil {
+		return err
+	}
+	result.Updated = append(result.Updated, chunkResult.Updated...)
+	for k, v := range chunkResult.Errors {
+		result.Errors[k] = v
+	}
+	for k, v := range chunkResult.UpdatedAt {
+		result.UpdatedAt[k] = v
+	}
+	return nil
+}
+
 // batchEditIssuesChunk processes a single chunk of batch updates.
 func (c *Client) batchEditIssuesChunk(ctx context.Context, updates []BatchIssueUpdate) (BatchUpdateResult, error) {
 	result := BatchUpdateResult{
-		Errors: make(map[string]string),
+		Errors:    make(map[string]string),
+		UpdatedAt: make(map[string]time.Time),
 	}
 
 	if len(updates) == 0 {
@@ -777,7 +983,7 @@ func (c *Client) batchEditIssuesChunk(ctx context.Context, updates []BatchIssueU
 			inputParts = append(inputParts, fmt.Sprintf("assigneeIds: [%s]", strings.Join(assigneeIDs, ", ")))
 		}
 
-		mutations = append(mutations, fmt.Sprintf(`  update%d: updateIssue(input: {%s}) { issue { number } }`,
+		mutations = append(mutations, fmt.Sprintf(`  update%d: updateIssue(input: {%s}) { issue { number updatedAt } }`,
 			i, strings.Join(inputParts, ", ")))
 	}
 
@@ -788,6 +994,7 @@ func (c *Client) batchEditIssuesChunk(ctx context.Context, updates []BatchIssueU
 	query := fmt.Sprintf("mutation {\n%s\n}", strings.Join(mutations, "\n"))
 
 	args := []string{"api", "graphql", "-f", fmt.Sprintf("query=%s", query)}
+	c.stats.add(QueryCost{Queries: 1, Nodes: len(mutations)})
 	out, err := c.runner.Run(ctx, "gh", args...)
 	if err != nil {
 		return result, fmt.Errorf("batch update failed: %w", err)
@@ -807,6 +1014,9 @@ func (c *Client) batchEditIssuesChunk(ctx context.Context, updates []BatchIssueU
 
 	// Map errors to issue numbers
 	for _, e := range resp.Errors {
+		if len(e.Path) == 0 && isQueryCostErrorText(e.Message) {
+			return result, fmt.Errorf("batch update failed: %s", e.Message)
+		}
 		if len(e.Path) > 0 {
 			// Path is like ["update0"]
 			alias := e.Path[0]
@@ -822,8 +1032,16 @@ func (c *Client) batchEditIssuesChunk(ctx context.Context, updates []BatchIssueU
 	for i, u := range updates {
 		alias := fmt.Sprintf("update%d", i)
 		if _, hasError := result.Errors[u.Number]; !hasError {
-			if _, inData := resp.Data[alias]; inData {
+			if data, inData := resp.Data[alias]; inData {
 				result.Updated = append(result.Updated, u.Number)
+				var updated struct {
+					Issue struct {
+						UpdatedAt time.Time `json:"updatedAt"`
+					} `json:"issue"`
+				}
+				if json.Unmarshal(data, &updated) == nil && !updated.Issue.UpdatedAt.IsZero() {
+					result.UpdatedAt[u.Number] = updated.Issue.UpdatedAt
+				}
 			}
 		}
 	}
@@ -831,6 +1049,138 @@ func (c *Client) batchEditIssuesChunk(ctx context.Context, updates []BatchIssueU
 	return result, nil
 }
 
+// createLookups holds the IDs a createIssue mutation needs. All maps are
+// keyed by lowercased name.
+type createLookups struct {
+	RepositoryID string
+	LabelIDs     map[string]string
+	UserIDs      map[string]string
+	MilestoneIDs map[string]string
+	IssueTypeIDs map[string]string
+	ProjectIDs   map[string]string
+}
+
+// fetchCreateLookups fetches the repository ID and the IDs of the labels,
+// assignees, milestone, issue type and projects of iss in a single query.
+// Issue types and projects are only queried when iss uses them, so tokens
+// without the project scope can still create issues.
+func (c *Client) fetchCreateLookups(ctx context.Context, owner, repo string, iss issue.Issue) (createLookups, error) {
+	lookups := createLookups{
+		LabelIDs:     make(map[string]string),
+		UserIDs:      make(map[string]string),
+		MilestoneIDs: make(map[string]string),
+		IssueTypeIDs: make(map[string]string),
+		ProjectIDs:   make(map[string]string),
+	}
+
+	repoParts := []string{"id"}
+	for i, label := range iss.Labels {
+		repoParts = append(repoParts, fmt.Sprintf("label%d: label(name: %q) { id name }", i, label))
+	}
+	if iss.Milestone != "" {
+		repoParts = append(repoParts, fmt.Sprintf("milestones(first: 100, states: [OPEN, CLOSED], query: %q) { nodes { id title } }", iss.Milestone))
+	}
+	if iss.IssueType != "" {
+		repoParts = append(repoParts, "issueTypes(first: 100) { nodes { id name } }")
+	}
+	var rootParts []string
+	for i, login := range iss.Assignees {
+		rootParts = append(rootParts, fmt.Sprintf("user%d: user(login: %q) { id login }", i, login))
+	}
+	if len(iss.Projects) > 0 {
+		rootParts = append(rootParts, "repositoryOwner(login: $owner) { ... on ProjectV2Owner { projectsV2(first: 100) { "+pageInfoFields+" nodes { id title } } } }")
+	}
+	query := fmt.Sprintf(`query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    %s
+  }
+  %s
+}`, strings.Join(repoParts, "\n    "), strings.Join(rootParts, "\n  "))
+
+	out, err := c.runner.Run(ctx, "gh", "api", "graphql",
+		"-f", fmt.Sprintf("query=%s", query),
+		"-F", fmt.Sprintf("owner=%s", owner),
+		"-F", fmt.Sprintf("repo=%s", repo),
+	)
+	if err != nil {
+		return lookups, err
+	}
+	var resp struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return lookups, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return lookups, fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+	}
+
+	type node struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Title string `json:"title"`
+		Login string `json:"login"`
+	}
+	type nodes struct {
+		Nodes []node `json:"nodes"`
+	}
+	var repoData map[string]json.RawMessage
+	if err := json.Unmarshal(resp.Data["repository"], &repoData); err != nil {
+		return lookups, fmt.Errorf("failed to parse repository: %w", err)
+	}
+	json.Unmarshal(repoData["id"], &lookups.RepositoryID)
+	for key, val := range repoData {
+		var n node
+		switch {
+		case strings.HasPrefix(key, "label"):
+			if json.Unmarshal(val, &n) == nil && n.ID != "" {
+				lookups.LabelIDs[strings.ToLower(n.Name)] = n.ID
+			}
+		case key == "milestones" || key == "issueTypes":
+			var list nodes
+			json.Unmarshal(val, &list)
+			for _, n := range list.Nodes {
+				if key == "milestones" {
+					lookups.MilestoneIDs[strings.ToLower(n.Title)] = n.ID
+				} else {
+					lookups.IssueTypeIDs[strings.ToLower(n.Name)] = n.ID
+				}
+			}
+		}
+	}
+	for key, val := range resp.Data {
+		var n node
+		switch {
+		case strings.HasPrefix(key, "user"):
+			if json.Unmarshal(val, &n) == nil && n.ID != "" {
+				lookups.UserIDs[strings.ToLower(n.Login)] = n.ID
+			}
+		case key == "repositoryOwner":
+			var projects struct {
+				ProjectsV2 connectionPage `json:"projectsV2"`
+			}
+			json.Unmarshal(val, &projects)
+			all, err := c.allNodes(ctx, projects.ProjectsV2, ownerProjectsPageQuery, []string{"-F", fmt.Sprintf("owner=%s", owner)}, "repositoryOwner", "projectsV2")
+			if err != nil {
+				return lookups, fmt.Errorf("failed to fetch projects: %w", err)
+			}
+			for _, raw := range all {
+				var project node
+				if json.Unmarshal(raw, &project) == nil {
+					lookups.ProjectIDs[strings.ToLower(project.Title)] = project.ID
+				}
+			}
+		}
+	}
+	if lookups.RepositoryID == "" {
+		return lookups, fmt.Errorf("repository %s/%s not found", owner, repo)
+	}
+	return lookups, nil
+}
+
 // batchLookups holds the ID mappings needed for batch updates.
 type batchLookups struct {
 	IssueIDs     map[string]string // issue number -> node ID
@@ -874,13 +1224,16 @@ func (c *Client) fetchBatchLookups(ctx context.Context, owner, repo string, issu
   repository(owner: $owner, name: $repo) {
     %s
     milestones(first: 100, states: [OPEN, CLOSED]) {
+      `+pageInfoFields+`
       nodes { id title }
     }
     labels(first: 100) {
+      `+pageInfoFields+`
       nodes { id name }
     }
   }
   %s
+  rateLimit { cost }
 }`, strings.Join(issueQueries, "\n    "), strings.Join(userQueries, "\n  "))
 
 	args := []string{"api", "graphql",
@@ -889,6 +1242,7 @@ func (c *Client) fetchBatchLookups(ctx context.Context, owner, repo string, issu
 		"-F", fmt.Sprintf("repo=%s", repo),
 	}
 
+	c.stats.add(QueryCost{Queries: 1, Nodes: len(issueQueries) + len(userQueries) + 200})
 	out, err := c.runner.Run(ctx, "gh", args...)
 	if err != nil {
 		return lookups, err
@@ -914,6 +1268,12 @@ func (c *Client) fetchBatchLookups(ctx context.Context, owner, repo string, issu
 	if err := json.Unmarshal(rawResp.Data, &data); err != nil {
 		return lookups, fmt.Errorf("failed to parse data: %w", err)
 	}
+	var rateLimit struct {
+		Cost int `json:"cost"`
+	}
+	if err := json.Unmarshal(data["rateLimit"], &rateLimit); err == nil {
+		c.stats.add(QueryCost{Points: rateLimit.Cost})
+	}
 
 	// Parse repository data
 	if repoData, ok := data["repository"]; ok {
@@ -932,32 +1292,34 @@ func (c *Client) fetchBatchLookups(ctx context.Context, owner, repo string, issu
 				}
 			}
 
-			// Parse milestones
-			if milestonesData, ok := repoMap["milestones"]; ok {
-				var milestones struct {
-					Nodes []struct {
+			// Parse milestones and labels, fetching the pages after the
+			// first for repositories with more than 100
+			pageArgs := []string{"-F", fmt.Sprintf("owner=%s", owner), "-F", fmt.Sprintf("repo=%s", repo)}
+			for _, list := range []struct {
+				key   string
+				query string
+			}{{"milestones", milestonesPageQuery}, {"labels", labelsPageQuery}} {
+				var page connectionPage
+				if err := json.Unmarshal(repoMap[list.key], &page); err != nil {
+					continue
+				}
+				nodes, err := c.allNodes(ctx, page, list.query, pageArgs, "repository", list.key)
+				if err != nil {
+					return lookups, fmt.Errorf("failed to fetch %s: %w", list.key, err)
+				}
+				for _, raw := range nodes {
+					var node struct {
 						ID    string `json:"id"`
+						Name  string `json:"name"`
 						Title string `json:"title"`
-					} `json:"nodes"`
-				}
-				if err := json.Unmarshal(milestonesData, &milestones); err == nil {
-					for _, m := range milestones.Nodes {
-						lookups.MilestoneIDs[m.Title] = m.ID
 					}
-				}
-			}
-
-			// Parse labels
-			if labelsData, ok := repoMap["labels"]; ok {
-				var labels struct {
-					Nodes []struct {
-						ID   string `json:"id"`
-						Name string `json:"name"`
-					} `json:"nodes"`
-				}
-				if err := json.Unmarshal(labelsData, &labels); err == nil {
-					for _, l := range labels.Nodes {
-						lookups.LabelIDs[l.Name] = l.ID
+					if json.Unmarshal(raw, &node) != nil {
+						continue
+					}
+					if list.key == "milestones" {
+						lookups.MilestoneIDs[node.Title] = node.ID
+					} else {
+						lookups.LabelIDs[node.Name] = node.ID
 					}
 				}
 			}
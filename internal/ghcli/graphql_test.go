@@ -3,8 +3,11 @@ package ghcli
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
 )
 
 type scopeFallbackRunner struct {
@@ -53,3 +56,33 @@ func TestGetIssueRelationshipsBatchFallsBackWithoutProjectsOnScopeError(t *testi
 		t.Fatalf("expected relationships for issue 281")
 	}
 }
+
+func TestGetIssueRelationshipsBatchDevelopment(t *testing.T) {
+	runner := fixedRunner{out: `{"data":{"repository":{"issue0":{"id":"I_1","number":7,
+		"closedByPullRequestsReferences":{"nodes":[
+			{"number":12,"state":"OPEN","isDraft":true,"headRefName":"fix-7","statusCheckRollup":{"state":"FAILURE"}},
+			{"number":15,"state":"MERGED","isDraft":false,"headRefName":"fix-7-again","statusCheckRollup":null}]},
+		"linkedBranches":{"nodes":[{"ref":{"name":"7-token-expiry"}},{"ref":null}]},
+		"comments":{"totalCount":3},
+		"blockedBy":{"nodes":[]},"blocking":{"nodes":[]}}}}}`}
+	client := NewClient(runner, "octo/repo")
+
+	rels, err := client.GetIssueRelationshipsBatch(context.Background(), []string{"7"})
+	if err != nil {
+		t.Fatalf("GetIssueRelationshipsBatch failed: %v", err)
+	}
+	got := rels["7"]
+	want := []issue.LinkedPullRequest{
+		{Number: 12, State: "draft", Branch: "fix-7", Checks: "failure"},
+		{Number: 15, State: "merged", Branch: "fix-7-again"},
+	}
+	if !reflect.DeepEqual(got.PullRequests, want) {
+		t.Fatalf("pull requests = %+v, want %+v", got.PullRequests, want)
+	}
+	if !reflect.DeepEqual(got.Branches, []string{"7-token-expiry"}) {
+		t.Fatalf("branches = %v", got.Branches)
+	}
+	if got.Comments != 3 {
+		t.Fatalf("comments = %d, want 3", got.Comments)
+	}
+}
@@ -0,0 +1,26 @@
+package ghcli
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// ListOrgRepositories returns the names of the non-archived repositories of
+// an organization, sorted. It is used by org mode and does not need a
+// repository-bound client.
+func ListOrgRepositories(ctx context.Context, runner Runner, org string) ([]string, error) {
+	args := []string{"repo", "list", org, "--limit", "1000", "--no-archived", "--json", "name", "-q", ".[].name"}
+	out, err := runner.Run(ctx, "gh", args...)
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			repos = append(repos, line)
+		}
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
@@ -0,0 +1,188 @@
+package ghcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// pageInfoFields selects what is needed to fetch the pages after the first
+// of a connection.
+const pageInfoFields = "pageInfo { hasNextPage endCursor }"
+
+// pageInfo is the pageInfo of a GraphQL connection.
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// connectionPage is one page of a GraphQL connection.
+type connectionPage struct {
+	PageInfo pageInfo          `json:"pageInfo"`
+	Nodes    []json.RawMessage `json:"nodes"`
+}
+
+// Queries for the pages after the first of the collections fetched as
+// part of larger queries. Each takes $owner, all but the one for projects
+// $repo, and those for the collections of an issue $number. Assignees (10
+// per issue) and issue types (25 per organization) always fit the first
+// page and are not paged.
+const (
+	labelsPageQuery = `query($owner: String!, $repo: String!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    labels(first: 100, after: $cursor) {
+      ` + pageInfoFields + `
+      nodes { id name color }
+    }
+  }
+}`
+	milestonesPageQuery = `query($owner: String!, $repo: String!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    milestones(first: 100, after: $cursor, states: [OPEN, CLOSED]) {
+      ` + pageInfoFields + `
+      nodes { id title }
+    }
+  }
+}`
+	ownerProjectsPageQuery = `query($owner: String!, $cursor: String) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectsV2(first: 100, after: $cursor) {
+        ` + pageInfoFields + `
+        nodes { id title }
+      }
+    }
+  }
+}`
+	issueLabelsPageQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    issue(number: $number) {
+      labels(first: 100, after: $cursor) {
+        ` + pageInfoFields + `
+        nodes { name }
+      }
+    }
+  }
+}`
+	issueProjectItemsPageQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    issue(number: $number) {
+      projectItems(first: 100, after: $cursor) {
+        ` + pageInfoFields + `
+        nodes { project { title } }
+      }
+    }
+  }
+}`
+)
+
+// connectionNodes fetches the nodes of the connection at path in the data
+// of query page by page, starting after cursor, or at the first page if it
+// is empty. query declares a $cursor: String variable for the after
+// argument of the connection and selects its pageInfoFields; args pass its
+// other variables to gh api graphql. A null object on the path has no
+// nodes.
+func (c *Client) connectionNodes(ctx context.Context, query string, args []string, cursor string, path ...string) ([]json.RawMessage, error) {
+	var nodes []json.RawMessage
+	for {
+		callArgs := append([]string{"api", "graphql", "-f", fmt.Sprintf("query=%s", query)}, args...)
+		if cursor != "" {
+			callArgs = append(callArgs, "-f", fmt.Sprintf("cursor=%s", cursor))
+		}
+		out, err := c.runner.Run(ctx, "gh", callArgs...)
+		if err != nil {
+			return nil, err
+		}
+		var resp struct {
+			Data   json.RawMessage `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal([]byte(out), &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		}
+		if len(resp.Errors) > 0 {
+			if resp.Errors[0].Type != "" {
+				return nil, fmt.Errorf("GraphQL error: %s: %s", resp.Errors[0].Type, resp.Errors[0].Message)
+			}
+			return nil, fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+		}
+		raw := resp.Data
+		for _, key := range path {
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &fields); err != nil {
+				return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+			}
+			if raw = fields[key]; raw == nil || string(raw) == "null" {
+				return nodes, nil
+			}
+		}
+		var page connectionPage
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		}
+		nodes = append(nodes, page.Nodes...)
+		if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == "" || page.PageInfo.EndCursor == cursor {
+			return nodes, nil
+		}
+		cursor = page.PageInfo.EndCursor
+	}
+}
+
+// allNodes returns the nodes of page, the first page of a connection
+// fetched as part of a larger query, followed by those of the pages after
+// it, which query fetches as described for connectionNodes.
+func (c *Client) allNodes(ctx context.Context, page connectionPage, query string, args []string, path ...string) ([]json.RawMessage, error) {
+	if !page.PageInfo.HasNextPage {
+		return page.Nodes, nil
+	}
+	rest, err := c.connectionNodes(ctx, query, args, page.PageInfo.EndCursor, path...)
+	if err != nil {
+		return nil, err
+	}
+	return append(page.Nodes, rest...), nil
+}
+
+// moreIssueLabels returns the labels of issue number on the pages after
+// cursor.
+func (c *Client) moreIssueLabels(ctx context.Context, number int, cursor string) ([]string, error) {
+	return c.moreIssueNames(ctx, issueLabelsPageQuery, "labels", number, cursor, func(node issueNameNode) string {
+		return node.Name
+	})
+}
+
+// moreIssueProjects returns the project titles of issue number on the
+// pages of project items after cursor.
+func (c *Client) moreIssueProjects(ctx context.Context, number int, cursor string) ([]string, error) {
+	return c.moreIssueNames(ctx, issueProjectItemsPageQuery, "projectItems", number, cursor, func(node issueNameNode) string {
+		return node.Project.Title
+	})
+}
+
+// issueNameNode is a node of the issue collections moreIssueNames reads.
+type issueNameNode struct {
+	Name    string `json:"name"`
+	Project struct {
+		Title string `json:"title"`
+	} `json:"project"`
+}
+
+func (c *Client) moreIssueNames(ctx context.Context, query, field string, number int, cursor string, name func(issueNameNode) string) ([]string, error) {
+	owner, repo := splitRepo(c.repo)
+	args := []string{"-F", fmt.Sprintf("owner=%s", owner), "-F", fmt.Sprintf("repo=%s", repo), "-F", fmt.Sprintf("number=%d", number)}
+	nodes, err := c.connectionNodes(ctx, query, args, cursor, "repository", "issue", field)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(nodes))
+	for _, raw := range nodes {
+		var node issueNameNode
+		if err := json.Unmarshal(raw, &node); err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		}
+		names = append(names, name(node))
+	}
+	return names, nil
+}
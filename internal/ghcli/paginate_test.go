@@ -0,0 +1,84 @@
+package ghcli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pagingRunner answers the first page of each collection as part of a
+// larger query, and the pages after it through the page queries.
+type pagingRunner struct {
+	cursors []string
+}
+
+func (r *pagingRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	var query, cursor string
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-f" && strings.HasPrefix(args[i+1], "query=") {
+			query = strings.TrimPrefix(args[i+1], "query=")
+		}
+		if args[i] == "-f" && strings.HasPrefix(args[i+1], "cursor=") {
+			cursor = strings.TrimPrefix(args[i+1], "cursor=")
+		}
+	}
+	r.cursors = append(r.cursors, cursor)
+	switch query {
+	case labelsPageQuery:
+		if cursor == "L1" {
+			return `{"data":{"repository":{"labels":{"pageInfo":{"hasNextPage":true,"endCursor":"L2"},"nodes":[{"id":"LA_2","name":"second"}]}}}}`, nil
+		}
+		return `{"data":{"repository":{"labels":{"pageInfo":{"hasNextPage":false,"endCursor":"L3"},"nodes":[{"id":"LA_3","name":"third"}]}}}}`, nil
+	case milestonesPageQuery:
+		return `{"data":{"repository":{"milestones":{"pageInfo":{"hasNextPage":false},"nodes":[{"id":"MI_2","title":"v2"}]}}}}`, nil
+	case issueLabelsPageQuery:
+		return `{"data":{"repository":{"issue":{"labels":{"pageInfo":{"hasNextPage":false},"nodes":[{"name":"late"}]}}}}}`, nil
+	}
+	if strings.Contains(query, "milestones(first: 100") {
+		return `{"data":{"repository":{
+  "issue0":{"id":"I_1","number":1},
+  "milestones":{"pageInfo":{"hasNextPage":true,"endCursor":"M1"},"nodes":[{"id":"MI_1","title":"v1"}]},
+  "labels":{"pageInfo":{"hasNextPage":true,"endCursor":"L1"},"nodes":[{"id":"LA_1","name":"first"}]}
+}}}`, nil
+	}
+	return `{"data":{"repository":{"issue0":{"number":1,"title":"Crash","state":"OPEN",
+  "labels":{"pageInfo":{"hasNextPage":true,"endCursor":"IL1"},"nodes":[{"name":"bug"}]},
+  "assignees":{"nodes":[]}}}}}`, nil
+}
+
+func TestBatchLookupsFetchAllPages(t *testing.T) {
+	runner := &pagingRunner{}
+	client := NewClient(runner, "octo/repo")
+	lookups, err := client.fetchBatchLookups(context.Background(), "octo", "repo", []string{"1"}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("fetchBatchLookups: %v", err)
+	}
+	for _, name := range []string{"first", "second", "third"} {
+		if lookups.LabelIDs[name] == "" {
+			t.Errorf("expected label %q, got %v", name, lookups.LabelIDs)
+		}
+	}
+	if lookups.MilestoneIDs["v1"] != "MI_1" || lookups.MilestoneIDs["v2"] != "MI_2" {
+		t.Errorf("expected both milestones, got %v", lookups.MilestoneIDs)
+	}
+	if got := fmt.Sprint(runner.cursors); got != "[ M1 L1 L2]" {
+		t.Errorf("unexpected cursors %s", got)
+	}
+}
+
+func TestGetIssuesBatchFetchesMoreLabels(t *testing.T) {
+	runner := &pagingRunner{}
+	client := NewClient(runner, "octo/repo")
+	issues, err := client.GetIssuesBatch(context.Background(), []string{"1"})
+	if err != nil {
+		t.Fatalf("GetIssuesBatch: %v", err)
+	}
+	if labels := issues["1"].Labels; !slices.Equal(labels, []string{"bug", "late"}) {
+		t.Fatalf("expected the labels of both pages, got %v", labels)
+	}
+	if runner.cursors[1] != "IL1" {
+		t.Fatalf("expected the second page after IL1, got %v", runner.cursors)
+	}
+}
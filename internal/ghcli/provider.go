@@ -0,0 +1,62 @@
+package ghcli
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// ErrNotSupported is returned by providers for operations the backing forge
+// has no equivalent for (e.g. issue types or projects on GitLab).
+var ErrNotSupported = errors.New("not supported by this provider")
+
+// Provider is the set of operations the sync engine needs from an issue
+// tracker. Client implements it for GitHub; other forges provide their own
+// implementation and return ErrNotSupported (or empty results) for features
+// they lack.
+type Provider interface {
+	SetProgress(fn func(ProgressEvent))
+	ProbeCapabilities(ctx context.Context) (Capabilities, error)
+	CheckAuth(ctx context.Context) (AuthStatus, error)
+
+	ListIssuesWithRelationships(ctx context.Context, opts ListIssuesOptions) (ListIssuesResult, error)
+	GetIssue(ctx context.Context, number string) (issue.Issue, error)
+	GetIssuesBatch(ctx context.Context, numbers []string) (map[string]issue.Issue, error)
+	GetUpdatedAt(ctx context.Context, numbers []string) (map[string]time.Time, error)
+	EnrichWithRelationships(ctx context.Context, iss *issue.Issue) error
+	EnrichWithRelationshipsBatch(ctx context.Context, issues []issue.Issue) error
+
+	CreateIssue(ctx context.Context, iss issue.Issue) (string, error)
+	BatchEditIssues(ctx context.Context, updates []BatchIssueUpdate) (BatchUpdateResult, error)
+	CloseIssue(ctx context.Context, number string, reason string) (time.Time, error)
+	ReopenIssue(ctx context.Context, number string) (time.Time, error)
+	CreateComment(ctx context.Context, issueNumber string, body string) error
+	GetComment(ctx context.Context, issueNumber, commentID string) (Comment, error)
+	ListComments(ctx context.Context, issueNumber string, since *time.Time) ([]Comment, error)
+	GetPullRequest(ctx context.Context, number string) (PullRequest, error)
+
+	SyncRelationships(ctx context.Context, issueNumber string, local issue.Issue) (bool, error)
+	SetIssueType(ctx context.Context, issueNumber string, issueTypeID string) (time.Time, error)
+	SyncProjects(ctx context.Context, issueNumber string, localProjects []string, knownProjects map[string]string) error
+
+	GetRepository(ctx context.Context) (Repository, error)
+	ListLabels(ctx context.Context) ([]Label, error)
+	CreateLabel(ctx context.Context, name, color string) error
+	RenameLabel(ctx context.Context, oldName, newName string) error
+	DeleteLabel(ctx context.Context, name string) error
+	ListMilestones(ctx context.Context) ([]Milestone, error)
+	CreateMilestone(ctx context.Context, m Milestone) error
+	UpdateMilestone(ctx context.Context, number int, m Milestone) error
+	ListIssueTypes(ctx context.Context) ([]IssueType, error)
+	ListProjects(ctx context.Context) ([]Project, error)
+	ListTeams(ctx context.Context) ([]Team, error)
+	ListTeamMembers(ctx context.Context, slug string) ([]string, error)
+	ListAutolinks(ctx context.Context) ([]Autolink, error)
+	ListNotifications(ctx context.Context, all bool) ([]Notification, error)
+	MarkNotificationRead(ctx context.Context, id string) error
+	GetTimeline(ctx context.Context, number string) ([]TimelineEvent, error)
+}
+
+var _ Provider = (*Client)(nil)
@@ -4,18 +4,54 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
+	"time"
 )
 
 type Runner interface {
 	Run(ctx context.Context, name string, args ...string) (string, error)
 }
 
-type ExecRunner struct{}
+// EnvRunner is a Runner that can run its commands with additional
+// environment variables, such as a GH_TOKEN for one kind of operation.
+type EnvRunner interface {
+	Runner
+	WithEnv(env ...string) Runner
+}
+
+type ExecRunner struct {
+	// Env holds KEY=value pairs added to the environment of every command.
+	Env []string
+	// Timeout limits how long one command may run. Zero means no limit.
+	Timeout time.Duration
+}
+
+// commandWaitDelay is how long a cancelled command gets to close its
+// output after it was killed, in case it left children holding on to it.
+const commandWaitDelay = 5 * time.Second
+
+func (r ExecRunner) WithEnv(env ...string) Runner {
+	return ExecRunner{Env: append(slices.Clone(r.Env), env...), Timeout: r.Timeout}
+}
 
-func (ExecRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
+// Run runs the command and returns its output. The command is killed when
+// ctx is cancelled or its Timeout passes; the error then wraps ctx.Err()
+// or says that it timed out.
+func (r ExecRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	callCtx := ctx
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(callCtx, name, args...)
+	cmd.WaitDelay = commandWaitDelay
+	if len(r.Env) > 0 {
+		cmd.Env = append(os.Environ(), r.Env...)
+	}
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -23,6 +59,12 @@ func (ExecRunner) Run(ctx context.Context, name string, args ...string) (string,
 	if err := cmd.Run(); err != nil {
 		// Build a short command summary (don't include long arguments like --body)
 		cmdSummary := formatCommandSummary(name, args)
+		if ctx.Err() != nil {
+			return stdout.String(), fmt.Errorf("%s: %w", cmdSummary, ctx.Err())
+		}
+		if callCtx.Err() != nil {
+			return stdout.String(), fmt.Errorf("%s timed out after %s", cmdSummary, r.Timeout)
+		}
 		stderrText := strings.TrimSpace(stderr.String())
 		if stderrText != "" {
 			return stdout.String(), fmt.Errorf("%s failed: %s", cmdSummary, stderrText)
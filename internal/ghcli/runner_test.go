@@ -0,0 +1,35 @@
+package ghcli
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecRunnerTimeoutAndCancel(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	runner := ExecRunner{Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	_, err := runner.Run(context.Background(), "sleep", "10")
+	if err == nil || !strings.Contains(err.Error(), "sleep 10 timed out after 50ms") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if errors.Is(err, context.Canceled) || time.Since(start) > 5*time.Second {
+		t.Fatalf("expected the command to be killed on time, got %v after %s", err, time.Since(start))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = ExecRunner{}.WithEnv("A=b").Run(ctx, "sleep", "10")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the command to be cancelled, got %v", err)
+	}
+	if r := (ExecRunner{Timeout: time.Minute}).WithEnv("A=b").(ExecRunner); r.Timeout != time.Minute {
+		t.Fatal("expected WithEnv to keep the timeout")
+	}
+}
@@ -1,6 +1,9 @@
 package ghcli
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 func isProjectScopeError(err error) bool {
 	if err == nil {
@@ -25,3 +28,22 @@ func isProjectScopeErrorText(msg string) bool {
 	}
 	return false
 }
+
+// IsIssueNotFound reports whether err is GitHub saying that issue number
+// does not exist in the repository, which is also what a deleted or
+// transferred issue looks like. Only that answer counts: gh missing, a
+// repository that can't be resolved, or an SSO error are other errors.
+func IsIssueNotFound(err error, number string) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "could not resolve to an issue") &&
+		strings.Contains(msg, fmt.Sprintf("with the number of %s.", number))
+}
+
+// IsIssueOrPullRequestNotFound reports whether err is GitHub saying that
+// number names neither an issue nor a pull request, see IsIssueNotFound.
+func IsIssueOrPullRequestNotFound(err error, number string) bool {
+	return IsIssueNotFound(err, number) && strings.Contains(strings.ToLower(err.Error()), "issue or pull request")
+}
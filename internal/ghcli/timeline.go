@@ -0,0 +1,209 @@
+package ghcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TimelineEvent is a single entry of an issue's activity feed.
+type TimelineEvent struct {
+	Type      string    `json:"type"` // labeled, assigned, closed, commented, ...
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Subject is the label, assignee, milestone, new title, or referencing
+	// issue/commit the event is about, depending on Type.
+	Subject string `json:"subject,omitempty"`
+	// Previous is the old value for renames.
+	Previous string `json:"previous,omitempty"`
+}
+
+const timelineQuery = `
+query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    issue(number: $number) {
+      timelineItems(first: 100, after: $cursor, itemTypes: [
+        LABELED_EVENT, UNLABELED_EVENT, ASSIGNED_EVENT, UNASSIGNED_EVENT,
+        REFERENCED_EVENT, CROSS_REFERENCED_EVENT, CLOSED_EVENT, REOPENED_EVENT,
+        RENAMED_TITLE_EVENT, MILESTONED_EVENT, DEMILESTONED_EVENT, ISSUE_COMMENT
+      ]) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          __typename
+          ... on LabeledEvent { createdAt actor { login } label { name } }
+          ... on UnlabeledEvent { createdAt actor { login } label { name } }
+          ... on AssignedEvent { createdAt actor { login } assignee { ... on Actor { login } } }
+          ... on UnassignedEvent { createdAt actor { login } assignee { ... on Actor { login } } }
+          ... on ReferencedEvent { createdAt actor { login } commit { abbreviatedOid } }
+          ... on CrossReferencedEvent {
+            createdAt actor { login }
+            source {
+              ... on Issue { number repository { nameWithOwner } }
+              ... on PullRequest { number repository { nameWithOwner } }
+            }
+          }
+          ... on ClosedEvent { createdAt actor { login } stateReason }
+          ... on ReopenedEvent { createdAt actor { login } }
+          ... on RenamedTitleEvent { createdAt actor { login } previousTitle currentTitle }
+          ... on MilestonedEvent { createdAt actor { login } milestoneTitle }
+          ... on DemilestonedEvent { createdAt actor { login } milestoneTitle }
+          ... on IssueComment { createdAt author { login } }
+        }
+      }
+    }
+  }
+}`
+
+type timelineNode struct {
+	Typename  string    `json:"__typename"`
+	CreatedAt time.Time `json:"createdAt"`
+	Actor     *apiUser  `json:"actor"`
+	Author    *apiUser  `json:"author"`
+	Label     *struct {
+		Name string `json:"name"`
+	} `json:"label"`
+	Assignee *apiUser `json:"assignee"`
+	Commit   *struct {
+		AbbreviatedOid string `json:"abbreviatedOid"`
+	} `json:"commit"`
+	Source *struct {
+		Number     int `json:"number"`
+		Repository struct {
+			NameWithOwner string `json:"nameWithOwner"`
+		} `json:"repository"`
+	} `json:"source"`
+	StateReason    string `json:"stateReason"`
+	PreviousTitle  string `json:"previousTitle"`
+	CurrentTitle   string `json:"currentTitle"`
+	MilestoneTitle string `json:"milestoneTitle"`
+}
+
+// GetTimeline fetches the activity feed of an issue: label, assignment,
+// milestone and title changes, references, state changes, and comments.
+func (c *Client) GetTimeline(ctx context.Context, number string) ([]TimelineEvent, error) {
+	owner, repo := splitRepo(c.repo)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository format")
+	}
+	num, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue number: %s", number)
+	}
+
+	var events []TimelineEvent
+	cursor := ""
+	for {
+		args := []string{"api", "graphql",
+			"-f", fmt.Sprintf("query=%s", timelineQuery),
+			"-F", fmt.Sprintf("owner=%s", owner),
+			"-F", fmt.Sprintf("repo=%s", repo),
+			"-F", fmt.Sprintf("number=%d", num),
+		}
+		if cursor != "" {
+			args = append(args, "-f", fmt.Sprintf("cursor=%s", cursor))
+		}
+		out, err := c.runner.Run(ctx, "gh", args...)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			Data struct {
+				Repository struct {
+					Issue *struct {
+						TimelineItems struct {
+							PageInfo struct {
+								HasNextPage bool   `json:"hasNextPage"`
+								EndCursor   string `json:"endCursor"`
+							} `json:"pageInfo"`
+							Nodes []timelineNode `json:"nodes"`
+						} `json:"timelineItems"`
+					} `json:"issue"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal([]byte(out), &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		}
+		if len(resp.Errors) > 0 {
+			return nil, fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+		}
+		if resp.Data.Repository.Issue == nil {
+			return nil, fmt.Errorf("issue %s not found", number)
+		}
+
+		items := resp.Data.Repository.Issue.TimelineItems
+		for _, node := range items.Nodes {
+			if event, ok := node.event(); ok {
+				events = append(events, event)
+			}
+		}
+		if !items.PageInfo.HasNextPage {
+			break
+		}
+		cursor = items.PageInfo.EndCursor
+	}
+	return events, nil
+}
+
+func (n timelineNode) event() (TimelineEvent, bool) {
+	event := TimelineEvent{CreatedAt: n.CreatedAt}
+	if n.Actor != nil {
+		event.Actor = n.Actor.Login
+	} else if n.Author != nil {
+		event.Actor = n.Author.Login
+	}
+	switch n.Typename {
+	case "LabeledEvent", "UnlabeledEvent":
+		event.Type = "labeled"
+		if n.Typename == "UnlabeledEvent" {
+			event.Type = "unlabeled"
+		}
+		if n.Label != nil {
+			event.Subject = n.Label.Name
+		}
+	case "AssignedEvent", "UnassignedEvent":
+		event.Type = "assigned"
+		if n.Typename == "UnassignedEvent" {
+			event.Type = "unassigned"
+		}
+		if n.Assignee != nil {
+			event.Subject = n.Assignee.Login
+		}
+	case "ReferencedEvent":
+		event.Type = "referenced"
+		if n.Commit != nil {
+			event.Subject = n.Commit.AbbreviatedOid
+		}
+	case "CrossReferencedEvent":
+		event.Type = "cross-referenced"
+		if n.Source != nil && n.Source.Number != 0 {
+			event.Subject = fmt.Sprintf("%s#%d", n.Source.Repository.NameWithOwner, n.Source.Number)
+		}
+	case "ClosedEvent":
+		event.Type = "closed"
+		event.Subject = n.StateReason
+	case "ReopenedEvent":
+		event.Type = "reopened"
+	case "RenamedTitleEvent":
+		event.Type = "renamed"
+		event.Subject = n.CurrentTitle
+		event.Previous = n.PreviousTitle
+	case "MilestonedEvent", "DemilestonedEvent":
+		event.Type = "milestoned"
+		if n.Typename == "DemilestonedEvent" {
+			event.Type = "demilestoned"
+		}
+		event.Subject = n.MilestoneTitle
+	case "IssueComment":
+		event.Type = "commented"
+	default:
+		return event, false
+	}
+	return event, true
+}
@@ -0,0 +1,840 @@
+// Package gitlab implements the sync provider for GitLab using the glab CLI.
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+// pageSize is the number of items requested per REST page (GitLab's maximum).
+const pageSize = 100
+
+// Client talks to the GitLab REST API through `glab api`.
+type Client struct {
+	runner   ghcli.Runner
+	project  string // full project path, e.g. "group/subgroup/project"
+	host     string // optional GitLab hostname for self-managed instances
+	progress func(ghcli.ProgressEvent)
+
+	// lookupMu guards the ID lookups cached for the life of the client,
+	// so a push resolves each milestone and user once rather than once per
+	// issue.
+	lookupMu     sync.Mutex
+	milestoneIDs map[string]string // title -> ID; nil until loaded
+	userIDs      map[string]int    // login -> ID
+}
+
+var _ ghcli.Provider = (*Client)(nil)
+
+// NewClient creates a GitLab client for the given project path.
+// If host is empty, glab's default host is used.
+func NewClient(runner ghcli.Runner, project, host string) *Client {
+	return &Client{runner: runner, project: project, host: host}
+}
+
+func (c *Client) SetProgress(fn func(ghcli.ProgressEvent)) {
+	c.progress = fn
+}
+
+func (c *Client) reportProgress(event ghcli.ProgressEvent) {
+	if c.progress != nil {
+		c.progress(event)
+	}
+}
+
+// ProbeCapabilities reports no optional features: GitLab's issue types
+// are fixed, it has no Projects v2 equivalent, and child issues are not
+// mapped to sub-issues.
+func (c *Client) ProbeCapabilities(ctx context.Context) (ghcli.Capabilities, error) {
+	return ghcli.Capabilities{}, nil
+}
+
+// CheckAuth verifies that glab is installed and logged in. Scopes are
+// only known for personal access tokens, which need the api scope.
+func (c *Client) CheckAuth(ctx context.Context) (ghcli.AuthStatus, error) {
+	status := ghcli.AuthStatus{CLI: "glab"}
+	out, err := c.api(ctx, "GET", "user")
+	if err != nil {
+		return status, ghcli.ClassifyAuthError("glab", err)
+	}
+	var user apiUser
+	if err := json.Unmarshal([]byte(out), &user); err == nil {
+		status.User = user.Username
+	}
+	if out, err := c.api(ctx, "GET", "personal_access_tokens/self"); err == nil {
+		var token struct {
+			Scopes []string `json:"scopes"`
+		}
+		if err := json.Unmarshal([]byte(out), &token); err == nil && token.Scopes != nil {
+			status.Scopes = token.Scopes
+			if !slices.Contains(token.Scopes, "api") {
+				status.Missing = append(status.Missing, "api")
+			}
+		}
+	}
+	return status, nil
+}
+
+func (c *Client) projectEndpoint(suffix string) string {
+	return "projects/" + url.PathEscape(c.project) + suffix
+}
+
+// api runs `glab api` against the given endpoint.
+func (c *Client) api(ctx context.Context, method, endpoint string, fields ...string) (string, error) {
+	args := []string{"api", endpoint}
+	if method != "" && method != "GET" {
+		args = append(args, "--method", method)
+	}
+	for _, f := range fields {
+		args = append(args, "-f", f)
+	}
+	if c.host != "" {
+		args = append(args, "--hostname", c.host)
+	}
+	return c.runner.Run(ctx, "glab", args...)
+}
+
+type apiUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+type apiMilestone struct {
+	ID          int     `json:"id"`
+	GroupID     int     `json:"group_id"` // Set for group milestones
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	DueDate     *string `json:"due_date"`
+	State       string  `json:"state"`
+}
+
+type apiIssue struct {
+	IID         int           `json:"iid"`
+	Title       string        `json:"title"`
+	Description *string       `json:"description"`
+	State       string        `json:"state"`
+	Labels      []string      `json:"labels"`
+	Assignees   []apiUser     `json:"assignees"`
+	Milestone   *apiMilestone `json:"milestone"`
+	Author      *apiUser      `json:"author"`
+	CreatedAt   string        `json:"created_at"`
+	UpdatedAt   string        `json:"updated_at"`
+	// UserNotesCount leaves out system notes such as label changes
+	UserNotesCount int `json:"user_notes_count"`
+	Upvotes        int `json:"upvotes"`
+}
+
+func (a apiIssue) toIssue() issue.Issue {
+	assignees := make([]string, 0, len(a.Assignees))
+	for _, u := range a.Assignees {
+		assignees = append(assignees, u.Username)
+	}
+	iss := issue.Issue{
+		Number:    issue.IssueNumber(strconv.Itoa(a.IID)),
+		Title:     a.Title,
+		Labels:    append([]string(nil), a.Labels...),
+		Assignees: assignees,
+		State:     normalizeState(a.State),
+	}
+	if a.Description != nil {
+		iss.Body = *a.Description
+	}
+	if a.Milestone != nil {
+		iss.Milestone = a.Milestone.Title
+	}
+	if a.Author != nil {
+		iss.Author = a.Author.Username
+	}
+	if t, err := time.Parse(time.RFC3339, a.CreatedAt); err == nil {
+		iss.CreatedAt = &t
+	}
+	if t, err := time.Parse(time.RFC3339, a.UpdatedAt); err == nil {
+		iss.UpdatedAt = &t
+	}
+	iss.CommentCount = a.UserNotesCount
+	iss.Reactions = a.Upvotes
+	return iss
+}
+
+// normalizeState maps GitLab states ("opened", "closed") to ours.
+func normalizeState(state string) string {
+	switch strings.ToLower(state) {
+	case "opened", "open", "active":
+		return "open"
+	case "closed":
+		return "closed"
+	default:
+		return strings.ToLower(state)
+	}
+}
+
+// getAllPages fetches every page of a list endpoint.
+func getAllPages[T any](ctx context.Context, c *Client, endpoint string) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		sep := "?"
+		if strings.Contains(endpoint, "?") {
+			sep = "&"
+		}
+		out, err := c.api(ctx, "GET", fmt.Sprintf("%s%sper_page=%d&page=%d", endpoint, sep, pageSize, page))
+		if err != nil {
+			return nil, err
+		}
+		items, err := decodeArrays[T](out)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// decodeArrays decodes one or more concatenated JSON arrays into a single
+// slice.
+func decodeArrays[T any](out string) ([]T, error) {
+	var items []T
+	dec := json.NewDecoder(bytes.NewReader([]byte(out)))
+	for {
+		var page []T
+		if err := dec.Decode(&page); err != nil {
+			if errors.Is(err, io.EOF) {
+				return items, nil
+			}
+			return nil, fmt.Errorf("failed to parse GitLab response: %w", err)
+		}
+		items = append(items, page...)
+	}
+}
+
+func (c *Client) ListIssuesWithRelationships(ctx context.Context, opts ghcli.ListIssuesOptions) (ghcli.ListIssuesResult, error) {
+	result := ghcli.ListIssuesResult{LabelColors: make(map[string]string)}
+
+	query := url.Values{}
+	switch opts.State {
+	case "closed":
+		query.Set("state", "closed")
+	case "all":
+		query.Set("state", "all")
+	default:
+		query.Set("state", "opened")
+	}
+	if len(opts.Labels) > 0 {
+		query.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	if opts.Milestone != "" {
+		query.Set("milestone", opts.Milestone)
+	}
+	if opts.Assignee != "" {
+		query.Set("assignee_username", opts.Assignee)
+	}
+	if !opts.Since.IsZero() {
+		query.Set("updated_after", opts.Since.UTC().Format(time.RFC3339))
+	}
+	query.Set("per_page", strconv.Itoa(pageSize))
+	query.Set("order_by", "created_at")
+	query.Set("sort", "asc")
+
+	// The cursor of a page is its number
+	first := 1
+	if opts.After != "" {
+		after, err := strconv.Atoi(opts.After)
+		if err != nil {
+			return ghcli.ListIssuesResult{}, fmt.Errorf("invalid page cursor %q", opts.After)
+		}
+		first = after + 1
+	}
+	for page := first; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		c.reportProgress(ghcli.ProgressEvent{
+			Stage:  ghcli.ProgressListIssuesPageStart,
+			Page:   page,
+			Issues: len(result.Issues),
+		})
+		out, err := c.api(ctx, "GET", c.projectEndpoint("/issues?"+query.Encode()))
+		if err != nil {
+			return ghcli.ListIssuesResult{}, err
+		}
+		items, err := decodeArrays[apiIssue](out)
+		if err != nil {
+			return ghcli.ListIssuesResult{}, err
+		}
+		pageStart := len(result.Issues)
+		for _, item := range items {
+			result.Issues = append(result.Issues, item.toIssue())
+		}
+		c.reportProgress(ghcli.ProgressEvent{
+			Stage:      ghcli.ProgressListIssuesPageDone,
+			Page:       page,
+			Issues:     len(result.Issues),
+			PageIssues: len(items),
+		})
+		if opts.OnPage != nil {
+			if err := opts.OnPage(strconv.Itoa(page), result.Issues[pageStart:]); err != nil {
+				return ghcli.ListIssuesResult{}, err
+			}
+		}
+		if len(items) < pageSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) GetIssue(ctx context.Context, number string) (issue.Issue, error) {
+	out, err := c.api(ctx, "GET", c.projectEndpoint("/issues/"+number))
+	if err != nil {
+		return issue.Issue{}, err
+	}
+	var payload apiIssue
+	if err := json.Unmarshal([]byte(out), &payload); err != nil {
+		return issue.Issue{}, fmt.Errorf("failed to parse GitLab response: %w", err)
+	}
+	return payload.toIssue(), nil
+}
+
+func (c *Client) GetIssuesBatch(ctx context.Context, numbers []string) (map[string]issue.Issue, error) {
+	results := make(map[string]issue.Issue)
+	for i := 0; i < len(numbers); i += pageSize {
+		end := i + pageSize
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+		query := url.Values{}
+		for _, n := range numbers[i:end] {
+			query.Add("iids[]", n)
+		}
+		query.Set("per_page", strconv.Itoa(pageSize))
+		query.Set("scope", "all")
+		out, err := c.api(ctx, "GET", c.projectEndpoint("/issues?"+query.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		items, err := decodeArrays[apiIssue](out)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			iss := item.toIssue()
+			results[iss.Number.String()] = iss
+		}
+	}
+	return results, nil
+}
+
+// GetUpdatedAt returns the last update time of each issue.
+func (c *Client) GetUpdatedAt(ctx context.Context, numbers []string) (map[string]time.Time, error) {
+	issues, err := c.GetIssuesBatch(ctx, numbers)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]time.Time, len(issues))
+	for number, iss := range issues {
+		if iss.UpdatedAt != nil {
+			results[number] = *iss.UpdatedAt
+		}
+	}
+	return results, nil
+}
+
+// EnrichWithRelationships is a no-op: GitLab issue links and epics do not map
+// onto parent/blocked_by, so relationships are left untouched.
+func (c *Client) EnrichWithRelationships(ctx context.Context, iss *issue.Issue) error {
+	return nil
+}
+
+// EnrichWithRelationshipsBatch is a no-op, see EnrichWithRelationships.
+func (c *Client) EnrichWithRelationshipsBatch(ctx context.Context, issues []issue.Issue) error {
+	return nil
+}
+
+func (c *Client) CreateIssue(ctx context.Context, iss issue.Issue) (string, error) {
+	fields := []string{"title=" + iss.Title, "description=" + iss.Body}
+	if len(iss.Labels) > 0 {
+		fields = append(fields, "labels="+strings.Join(iss.Labels, ","))
+	}
+	if iss.Milestone != "" {
+		id, err := c.milestoneID(ctx, iss.Milestone)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, "milestone_id="+id)
+	}
+	assigneeFields, err := c.assigneeFields(ctx, iss.Assignees)
+	if err != nil {
+		return "", err
+	}
+	fields = append(fields, assigneeFields...)
+
+	out, err := c.api(ctx, "POST", c.projectEndpoint("/issues"), fields...)
+	if err != nil {
+		return "", err
+	}
+	var created apiIssue
+	if err := json.Unmarshal([]byte(out), &created); err != nil {
+		return "", fmt.Errorf("failed to parse GitLab response: %w", err)
+	}
+	if created.IID == 0 {
+		return "", fmt.Errorf("unable to parse issue number from GitLab response")
+	}
+	return strconv.Itoa(created.IID), nil
+}
+
+// BatchEditIssues applies updates one issue at a time; GitLab has no batch
+// mutation endpoint.
+func (c *Client) BatchEditIssues(ctx context.Context, updates []ghcli.BatchIssueUpdate) (ghcli.BatchUpdateResult, error) {
+	result := ghcli.BatchUpdateResult{Errors: make(map[string]string), UpdatedAt: make(map[string]time.Time)}
+	for _, u := range updates {
+		var fields []string
+		if u.Title != nil {
+			fields = append(fields, "title="+*u.Title)
+		}
+		if u.Body != nil {
+			fields = append(fields, "description="+*u.Body)
+		}
+		if u.ClearMilestone || (u.Milestone != nil && *u.Milestone == "") {
+			fields = append(fields, "milestone_id=0")
+		} else if u.Milestone != nil {
+			id, err := c.milestoneID(ctx, *u.Milestone)
+			if err != nil {
+				result.Errors[u.Number] = err.Error()
+				continue
+			}
+			fields = append(fields, "milestone_id="+id)
+		}
+		if u.Labels != nil || u.ClearLabels {
+			fields = append(fields, "labels="+strings.Join(u.Labels, ","))
+		}
+		if u.Assignees != nil || u.ClearAssignees {
+			if len(u.Assignees) == 0 {
+				fields = append(fields, "assignee_ids=0")
+			} else {
+				assigneeFields, err := c.assigneeFields(ctx, u.Assignees)
+				if err != nil {
+					result.Errors[u.Number] = err.Error()
+					continue
+				}
+				fields = append(fields, assigneeFields...)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		out, err := c.api(ctx, "PUT", c.projectEndpoint("/issues/"+u.Number), fields...)
+		if err != nil {
+			result.Errors[u.Number] = err.Error()
+			continue
+		}
+		result.Updated = append(result.Updated, u.Number)
+		if updatedAt := issueUpdatedAt(out); !updatedAt.IsZero() {
+			result.UpdatedAt[u.Number] = updatedAt
+		}
+	}
+	return result, nil
+}
+
+// CloseIssue closes an issue. GitLab has no close reason, so reason is ignored.
+func (c *Client) CloseIssue(ctx context.Context, number string, reason string) (time.Time, error) {
+	out, err := c.api(ctx, "PUT", c.projectEndpoint("/issues/"+number), "state_event=close")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return issueUpdatedAt(out), nil
+}
+
+func (c *Client) ReopenIssue(ctx context.Context, number string) (time.Time, error) {
+	out, err := c.api(ctx, "PUT", c.projectEndpoint("/issues/"+number), "state_event=reopen")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return issueUpdatedAt(out), nil
+}
+
+// issueUpdatedAt extracts updated_at from an issue response, or returns the
+// zero time if it has none.
+func issueUpdatedAt(out string) time.Time {
+	var resp struct {
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return time.Time{}
+	}
+	return resp.UpdatedAt
+}
+
+func (c *Client) CreateComment(ctx context.Context, issueNumber string, body string) error {
+	_, err := c.api(ctx, "POST", c.projectEndpoint("/issues/"+issueNumber+"/notes"), "body="+body)
+	return err
+}
+
+// GetComment fetches a note on an issue.
+func (c *Client) GetComment(ctx context.Context, issueNumber, commentID string) (ghcli.Comment, error) {
+	out, err := c.api(ctx, "GET", c.projectEndpoint("/issues/"+issueNumber+"/notes/"+commentID))
+	if err != nil {
+		return ghcli.Comment{}, err
+	}
+	var note struct {
+		ID        int       `json:"id"`
+		Body      string    `json:"body"`
+		Author    *apiUser  `json:"author"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	if err := json.Unmarshal([]byte(out), &note); err != nil {
+		return ghcli.Comment{}, fmt.Errorf("failed to parse GitLab response: %w", err)
+	}
+	comment := ghcli.Comment{
+		ID:        strconv.Itoa(note.ID),
+		Body:      note.Body,
+		CreatedAt: &note.CreatedAt,
+	}
+	if note.Author != nil {
+		comment.Author = note.Author.Username
+	}
+	return comment, nil
+}
+
+// ListComments fetches the notes of an issue, leaving out the system notes
+// GitLab records for changes. The notes API cannot filter by update time,
+// so since is applied to the full list.
+func (c *Client) ListComments(ctx context.Context, issueNumber string, since *time.Time) ([]ghcli.Comment, error) {
+	notes, err := getAllPages[struct {
+		ID        int       `json:"id"`
+		Body      string    `json:"body"`
+		System    bool      `json:"system"`
+		Author    *apiUser  `json:"author"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}](ctx, c, c.projectEndpoint("/issues/"+issueNumber+"/notes"))
+	if err != nil {
+		return nil, err
+	}
+	var comments []ghcli.Comment
+	for _, note := range notes {
+		if note.System || (since != nil && note.UpdatedAt.Before(*since)) {
+			continue
+		}
+		comment := ghcli.Comment{
+			ID:        strconv.Itoa(note.ID),
+			Body:      note.Body,
+			CreatedAt: &note.CreatedAt,
+			UpdatedAt: &note.UpdatedAt,
+		}
+		if note.Author != nil {
+			comment.Author = note.Author.Username
+		}
+		comments = append(comments, comment)
+	}
+	slices.SortStableFunc(comments, func(a, b ghcli.Comment) int {
+		return a.CreatedAt.Compare(*b.CreatedAt)
+	})
+	return comments, nil
+}
+
+// GetPullRequest fetches a merge request and its changed files. GitLab only
+// returns the diffs, so additions and deletions are counted from them.
+func (c *Client) GetPullRequest(ctx context.Context, number string) (ghcli.PullRequest, error) {
+	out, err := c.api(ctx, "GET", c.projectEndpoint("/merge_requests/"+number+"/changes"))
+	if err != nil {
+		return ghcli.PullRequest{}, err
+	}
+	var mr struct {
+		Title   string   `json:"title"`
+		WebURL  string   `json:"web_url"`
+		Author  *apiUser `json:"author"`
+		Changes []struct {
+			NewPath string `json:"new_path"`
+			Diff    string `json:"diff"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(out), &mr); err != nil {
+		return ghcli.PullRequest{}, fmt.Errorf("failed to parse GitLab response: %w", err)
+	}
+	pr := ghcli.PullRequest{Number: number, Title: mr.Title, URL: mr.WebURL}
+	if mr.Author != nil {
+		pr.Author = mr.Author.Username
+	}
+	for _, change := range mr.Changes {
+		file := ghcli.ChangedFile{Path: change.NewPath}
+		for _, line := range strings.Split(change.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			case strings.HasPrefix(line, "+"):
+				file.Additions++
+			case strings.HasPrefix(line, "-"):
+				file.Deletions++
+			}
+		}
+		pr.Files = append(pr.Files, file)
+	}
+	return pr, nil
+}
+
+// SyncRelationships only fails when the local issue actually uses
+// relationships, so plain edits do not produce warnings.
+func (c *Client) SyncRelationships(ctx context.Context, issueNumber string, local issue.Issue) (bool, error) {
+	if local.Parent != nil || len(local.BlockedBy) > 0 || len(local.Blocks) > 0 {
+		return false, fmt.Errorf("relationships: %w", ghcli.ErrNotSupported)
+	}
+	return false, nil
+}
+
+func (c *Client) SetIssueType(ctx context.Context, issueNumber string, issueTypeID string) (time.Time, error) {
+	return time.Time{}, fmt.Errorf("issue types: %w", ghcli.ErrNotSupported)
+}
+
+func (c *Client) SyncProjects(ctx context.Context, issueNumber string, localProjects []string, knownProjects map[string]string) error {
+	if len(localProjects) > 0 {
+		return fmt.Errorf("projects: %w", ghcli.ErrNotSupported)
+	}
+	return nil
+}
+
+func (c *Client) ListLabels(ctx context.Context) ([]ghcli.Label, error) {
+	items, err := getAllPages[struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}](ctx, c, c.projectEndpoint("/labels"))
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]ghcli.Label, 0, len(items))
+	for _, l := range items {
+		labels = append(labels, ghcli.Label{Name: l.Name, Color: strings.TrimPrefix(l.Color, "#")})
+	}
+	return labels, nil
+}
+
+func (c *Client) CreateLabel(ctx context.Context, name, color string) error {
+	_, err := c.api(ctx, "POST", c.projectEndpoint("/labels"), "name="+name, "color=#"+color)
+	return err
+}
+
+func (c *Client) RenameLabel(ctx context.Context, oldName, newName string) error {
+	_, err := c.api(ctx, "PUT", c.projectEndpoint("/labels/"+url.PathEscape(oldName)), "new_name="+newName)
+	return err
+}
+
+func (c *Client) DeleteLabel(ctx context.Context, name string) error {
+	_, err := c.api(ctx, "DELETE", c.projectEndpoint("/labels/"+url.PathEscape(name)))
+	return err
+}
+
+type apiProject struct {
+	Description   string   `json:"description"`
+	Topics        []string `json:"topics"`
+	DefaultBranch string   `json:"default_branch"`
+	WebURL        string   `json:"web_url"`
+}
+
+// GetRepository fetches the project's metadata. GitLab projects have no
+// homepage, so the project's web URL is used instead.
+func (c *Client) GetRepository(ctx context.Context) (ghcli.Repository, error) {
+	out, err := c.api(ctx, "GET", c.projectEndpoint(""))
+	if err != nil {
+		return ghcli.Repository{}, err
+	}
+	var project apiProject
+	if err := json.Unmarshal([]byte(out), &project); err != nil {
+		return ghcli.Repository{}, fmt.Errorf("failed to parse project JSON: %w", err)
+	}
+	return ghcli.Repository{
+		Description:   project.Description,
+		Topics:        project.Topics,
+		DefaultBranch: project.DefaultBranch,
+		Homepage:      project.WebURL,
+	}, nil
+}
+
+func (c *Client) listMilestones(ctx context.Context) ([]apiMilestone, error) {
+	return getAllPages[apiMilestone](ctx, c, c.projectEndpoint("/milestones"))
+}
+
+func (c *Client) ListMilestones(ctx context.Context) ([]ghcli.Milestone, error) {
+	items, err := c.listMilestones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	milestones := make([]ghcli.Milestone, 0, len(items))
+	for _, m := range items {
+		milestones = append(milestones, ghcli.Milestone{
+			Number:      m.ID,
+			Title:       m.Title,
+			Description: m.Description,
+			DueOn:       m.DueDate,
+			State:       normalizeState(m.State),
+		})
+	}
+	return milestones, nil
+}
+
+func (c *Client) CreateMilestone(ctx context.Context, m ghcli.Milestone) error {
+	fields := []string{"title=" + m.Title}
+	if m.Description != "" {
+		fields = append(fields, "description="+m.Description)
+	}
+	if m.DueOn != nil && *m.DueOn != "" {
+		fields = append(fields, "due_date="+dateOnly(*m.DueOn))
+	}
+	out, err := c.api(ctx, "POST", c.projectEndpoint("/milestones"), fields...)
+	if err != nil {
+		return err
+	}
+	c.forgetMilestones()
+	if m.State == "closed" {
+		var created apiMilestone
+		if err := json.Unmarshal([]byte(out), &created); err != nil || created.ID == 0 {
+			return fmt.Errorf("unable to parse milestone ID from GitLab response")
+		}
+		_, err = c.api(ctx, "PUT", c.projectEndpoint("/milestones/"+strconv.Itoa(created.ID)), "state_event=close")
+		return err
+	}
+	return nil
+}
+
+// UpdateMilestone updates a milestone by its GitLab ID (reported as Number by
+// ListMilestones).
+func (c *Client) UpdateMilestone(ctx context.Context, number int, m ghcli.Milestone) error {
+	fields := []string{"title=" + m.Title, "description=" + m.Description}
+	if m.DueOn != nil && *m.DueOn != "" {
+		fields = append(fields, "due_date="+dateOnly(*m.DueOn))
+	} else {
+		fields = append(fields, "due_date=")
+	}
+	switch m.State {
+	case "closed":
+		fields = append(fields, "state_event=close")
+	case "open":
+		fields = append(fields, "state_event=activate")
+	}
+	_, err := c.api(ctx, "PUT", c.projectEndpoint("/milestones/"+strconv.Itoa(number)), fields...)
+	c.forgetMilestones()
+	return err
+}
+
+func dateOnly(value string) string {
+	if len(value) > len("2006-01-02") {
+		return value[:len("2006-01-02")]
+	}
+	return value
+}
+
+// ListIssueTypes returns no types; GitLab's issue types are fixed and not
+// exposed as assignable metadata.
+func (c *Client) ListIssueTypes(ctx context.Context) ([]ghcli.IssueType, error) {
+	return nil, nil
+}
+
+// ListProjects returns no projects; GitLab has no Projects v2 equivalent.
+func (c *Client) ListProjects(ctx context.Context) ([]ghcli.Project, error) {
+	return nil, nil
+}
+
+// ListTeams returns no teams; GitLab mentions groups rather than teams.
+func (c *Client) ListTeams(ctx context.Context) ([]ghcli.Team, error) {
+	return nil, nil
+}
+
+// ListAutolinks returns no autolinks; GitLab links external issues
+// through integrations instead.
+func (c *Client) ListAutolinks(ctx context.Context) ([]ghcli.Autolink, error) {
+	return nil, nil
+}
+
+// ListTeamMembers is not supported on GitLab.
+func (c *Client) ListTeamMembers(ctx context.Context, slug string) ([]string, error) {
+	return nil, ghcli.ErrNotSupported
+}
+
+// ListNotifications is not supported on GitLab (todos work differently).
+func (c *Client) ListNotifications(ctx context.Context, all bool) ([]ghcli.Notification, error) {
+	return nil, ghcli.ErrNotSupported
+}
+
+// MarkNotificationRead is not supported on GitLab.
+func (c *Client) MarkNotificationRead(ctx context.Context, id string) error {
+	return ghcli.ErrNotSupported
+}
+
+// GetTimeline is not supported on GitLab.
+func (c *Client) GetTimeline(ctx context.Context, number string) ([]ghcli.TimelineEvent, error) {
+	return nil, ghcli.ErrNotSupported
+}
+
+// milestoneID resolves a milestone title to its ID. Issues can use the
+// milestones of the project and of its ancestor groups; a project milestone
+// wins over a group milestone of the same title. All of them are listed
+// once and cached.
+func (c *Client) milestoneID(ctx context.Context, title string) (string, error) {
+	c.lookupMu.Lock()
+	defer c.lookupMu.Unlock()
+	if c.milestoneIDs == nil {
+		items, err := getAllPages[apiMilestone](ctx, c, c.projectEndpoint("/milestones?include_ancestors=true"))
+		if err != nil {
+			return "", err
+		}
+		ids := make(map[string]string, len(items))
+		for _, m := range items {
+			if _, ok := ids[m.Title]; ok && m.GroupID != 0 {
+				continue
+			}
+			ids[m.Title] = strconv.Itoa(m.ID)
+		}
+		c.milestoneIDs = ids
+	}
+	if id, ok := c.milestoneIDs[title]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("milestone %q not found", title)
+}
+
+// forgetMilestones drops the cached milestone IDs after milestones changed.
+func (c *Client) forgetMilestones() {
+	c.lookupMu.Lock()
+	c.milestoneIDs = nil
+	c.lookupMu.Unlock()
+}
+
+// assigneeFields returns the assignee_ids fields for logins, looking up
+// each user once for the life of the client.
+func (c *Client) assigneeFields(ctx context.Context, logins []string) ([]string, error) {
+	c.lookupMu.Lock()
+	defer c.lookupMu.Unlock()
+	var fields []string
+	for _, login := range logins {
+		id, ok := c.userIDs[login]
+		if !ok {
+			out, err := c.api(ctx, "GET", "users?username="+url.QueryEscape(login))
+			if err != nil {
+				return nil, err
+			}
+			var users []apiUser
+			if err := json.Unmarshal([]byte(out), &users); err != nil {
+				return nil, fmt.Errorf("failed to parse GitLab response: %w", err)
+			}
+			if len(users) == 0 {
+				return nil, fmt.Errorf("user %q not found", login)
+			}
+			id = users[0].ID
+			if c.userIDs == nil {
+				c.userIDs = make(map[string]int)
+			}
+			c.userIDs[login] = id
+		}
+		fields = append(fields, "assignee_ids[]="+strconv.Itoa(id))
+	}
+	return fields, nil
+}
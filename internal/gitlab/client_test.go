@@ -0,0 +1,160 @@
+package gitlab
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/ghcli"
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+)
+
+type recordingRunner struct {
+	calls  [][]string
+	output string
+}
+
+func (r *recordingRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	r.calls = append(r.calls, append([]string{name}, args...))
+	return r.output, nil
+}
+
+func TestListIssuesMapsGitLabFields(t *testing.T) {
+	runner := &recordingRunner{output: `[{
+		"iid": 7,
+		"title": "Broken login",
+		"description": "Steps to reproduce",
+		"state": "opened",
+		"labels": ["bug"],
+		"assignees": [{"id": 1, "username": "alice"}],
+		"milestone": {"id": 3, "title": "v1.0"},
+		"author": {"id": 2, "username": "bob"},
+		"created_at": "2024-01-02T03:04:05Z",
+		"updated_at": "2024-01-03T03:04:05Z"
+	}]`}
+	client := NewClient(runner, "group/sub/project", "gitlab.example.com")
+
+	result, err := client.ListIssuesWithRelationships(context.Background(), ghcli.ListIssuesOptions{State: "all"})
+	if err != nil {
+		t.Fatalf("list issues: %v", err)
+	}
+	if len(result.Issues) != 1 {
+		t.Fatalf("expected 1 issue, got %d", len(result.Issues))
+	}
+	got := result.Issues[0]
+	if got.Number != "7" || got.State != "open" || got.Body != "Steps to reproduce" {
+		t.Fatalf("unexpected issue: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Assignees, []string{"alice"}) || got.Milestone != "v1.0" || got.Author != "bob" {
+		t.Fatalf("unexpected metadata: %+v", got)
+	}
+	if got.CreatedAt == nil || got.UpdatedAt == nil {
+		t.Fatalf("expected timestamps to be parsed")
+	}
+
+	call := runner.calls[0]
+	if call[0] != "glab" || !strings.HasPrefix(call[2], "projects/group%2Fsub%2Fproject/issues?") {
+		t.Fatalf("unexpected invocation: %v", call)
+	}
+	if !strings.Contains(call[2], "state=all") {
+		t.Fatalf("expected state=all in %q", call[2])
+	}
+
+	runner.calls = nil
+	if _, err := client.ListIssuesWithRelationships(context.Background(), ghcli.ListIssuesOptions{State: "open", Milestone: "v1.0", Assignee: "alice"}); err != nil {
+		t.Fatalf("list issues: %v", err)
+	}
+	if !strings.Contains(runner.calls[0][2], "milestone=v1.0") || !strings.Contains(runner.calls[0][2], "assignee_username=alice") {
+		t.Fatalf("expected milestone and assignee filters in %q", runner.calls[0][2])
+	}
+	if call[len(call)-2] != "--hostname" || call[len(call)-1] != "gitlab.example.com" {
+		t.Fatalf("expected --hostname flag, got %v", call)
+	}
+}
+
+func TestSyncRelationshipsOnlyFailsWhenUsed(t *testing.T) {
+	client := NewClient(&recordingRunner{}, "group/project", "")
+	ctx := context.Background()
+
+	if _, err := client.SyncRelationships(ctx, "1", issue.Issue{Number: "1"}); err != nil {
+		t.Fatalf("expected no error for issue without relationships, got %v", err)
+	}
+	parent := issue.IssueRef("2")
+	if _, err := client.SyncRelationships(ctx, "1", issue.Issue{Number: "1", Parent: &parent}); err == nil {
+		t.Fatalf("expected not supported error for issue with parent")
+	}
+}
+
+func TestGetPullRequestCountsDiffLines(t *testing.T) {
+	runner := &recordingRunner{output: `{
+		"title": "Fix login",
+		"web_url": "https://gitlab.com/group/project/-/merge_requests/3",
+		"author": {"id": 1, "username": "jane"},
+		"changes": [{"new_path": "src/login.go", "diff": "--- a/src/login.go\n+++ b/src/login.go\n@@ -1,2 +1,3 @@\n-old\n+new\n+more\n same\n"}]
+	}`}
+	pr, err := NewClient(runner, "group/project", "").GetPullRequest(context.Background(), "3")
+	if err != nil {
+		t.Fatalf("get merge request: %v", err)
+	}
+	if pr.Title != "Fix login" || pr.Author != "jane" || len(pr.Files) != 1 {
+		t.Fatalf("unexpected merge request: %+v", pr)
+	}
+	if f := pr.Files[0]; f.Path != "src/login.go" || f.Additions != 2 || f.Deletions != 1 {
+		t.Fatalf("unexpected file: %+v", f)
+	}
+	if got := strings.Join(runner.calls[0], " "); !strings.Contains(got, "merge_requests/3/changes") {
+		t.Fatalf("unexpected call: %s", got)
+	}
+}
+
+// lookupRunner answers the milestone and user lookups of an issue edit and
+// records every call.
+type lookupRunner struct {
+	calls []string
+}
+
+func (r *lookupRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	endpoint := args[1]
+	r.calls = append(r.calls, strings.Join(args[1:], " "))
+	switch {
+	case strings.Contains(endpoint, "/milestones?"):
+		return `[{"id": 5, "title": "v1.0", "group_id": 9}, {"id": 3, "title": "v1.0"}, {"id": 8, "title": "Q3", "group_id": 9}]`, nil
+	case strings.HasPrefix(endpoint, "users?"):
+		return `[{"id": 11, "username": "alice"}]`, nil
+	}
+	return `{}`, nil
+}
+
+func TestBatchEditIssuesCachesLookups(t *testing.T) {
+	runner := &lookupRunner{}
+	client := NewClient(runner, "group/project", "")
+	milestone := "v1.0"
+	var updates []ghcli.BatchIssueUpdate
+	for _, number := range []string{"1", "2", "3"} {
+		updates = append(updates, ghcli.BatchIssueUpdate{Number: number, Milestone: &milestone, Assignees: []string{"alice"}})
+	}
+	groupMilestone := "Q3"
+	updates = append(updates, ghcli.BatchIssueUpdate{Number: "4", Milestone: &groupMilestone})
+
+	result, err := client.BatchEditIssues(context.Background(), updates)
+	if err != nil || len(result.Errors) > 0 {
+		t.Fatalf("batch edit: %v %v", err, result.Errors)
+	}
+	lookups := 0
+	for _, call := range runner.calls {
+		if strings.Contains(call, "/milestones?") || strings.HasPrefix(call, "users?") {
+			lookups++
+		}
+	}
+	if lookups != 2 || len(runner.calls) != 6 {
+		t.Fatalf("expected one milestone and one user lookup for four edits, got %v", runner.calls)
+	}
+	if !strings.Contains(runner.calls[0], "include_ancestors=true&per_page=") {
+		t.Fatalf("expected group milestones in the lookup, got %q", runner.calls[0])
+	}
+	// The project milestone wins over the group one of the same title
+	if !strings.Contains(runner.calls[2], "milestone_id=3") || !strings.Contains(runner.calls[5], "milestone_id=8") {
+		t.Fatalf("unexpected milestone IDs in %v", runner.calls)
+	}
+}
@@ -0,0 +1,27 @@
+package issue
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The markers around the comments edit --with-comments appends to a body
+// while it is edited.
+const (
+	CommentContextStart = "<!-- gh-issue-sync:comments (read-only, removed when the editor closes) -->"
+	CommentContextEnd   = "<!-- /gh-issue-sync:comments -->"
+)
+
+// commentContextPattern matches an appended comment block along with the
+// blank lines around it.
+var commentContextPattern = regexp.MustCompile(`(?s)\n*<!-- gh-issue-sync:comments[^>]*-->.*?<!-- /gh-issue-sync:comments -->\n*`)
+
+// StripCommentContext removes the comments edit --with-comments appended,
+// whatever was typed into them. A block left behind by an editor that
+// never returned must not reach the remote, so PublicBody strips it too.
+func StripCommentContext(body string) string {
+	if !strings.Contains(body, "<!-- gh-issue-sync:comments") {
+		return body
+	}
+	return normalizeBody(strings.TrimRight(commentContextPattern.ReplaceAllString(body, "\n\n"), "\n"))
+}
@@ -1,9 +1,11 @@
 package issue
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -20,49 +22,142 @@ type IssueNumber string
 type IssueRef string
 
 type Issue struct {
-	Number      IssueNumber
-	Title       string
-	Labels      []string
-	Assignees   []string
-	Milestone   string
-	IssueType   string
-	Projects    []string
-	State       string
-	StateReason *string
-	Parent      *IssueRef
-	BlockedBy   []IssueRef
-	Blocks      []IssueRef
-	SyncedAt    *time.Time
-	Body        string
+	Number          IssueNumber
+	Title           string
+	Draft           bool // Never pushed; see promote
+	Labels          []string
+	Assignees       []string
+	Milestone       string
+	IssueType       string
+	Projects        []string
+	State           string
+	StateReason     *string
+	Parent          *IssueRef
+	BlockedBy       []IssueRef
+	Blocks          []IssueRef
+	CodeRefs        []string // "path:line@sha" or permalinks; local only, never pushed
+	LocalState      string   // Local workflow state such as done-pending-release; never pushed
+	LastLocalEditBy string   // git user.name behind the unpushed local change; never pushed
+	SyncedAt        *time.Time
+	Body            string
 
 	// Informational fields (read-only, not synced back to GitHub)
 	Author    string
 	CreatedAt *time.Time
 	UpdatedAt *time.Time
+	SubIssues *SubIssueSummary
+	// ReferencedBy lists issues and pull requests that mention this issue,
+	// as "42" for the same repository or "owner/repo#42" otherwise.
+	ReferencedBy []string
+	// PullRequests and Branches are the development links of the issue:
+	// pull requests that close it and branches created for it.
+	PullRequests []LinkedPullRequest
+	Branches     []string
+	// ClosedBy is the merged pull request that closed the issue, if any.
+	ClosedBy int
+	// CommentCount is the number of comments on the remote issue.
+	CommentCount int
+	// Reactions is the number of reactions to the remote issue (upvotes on
+	// GitLab).
+	Reactions int
+	// Summary is the thread summary written by summarize. It is local only
+	// and kept across pulls.
+	Summary string
+	// Fields are front matter keys gh-issue-sync does not know, such as
+	// custom fields added by hand. Like code refs they are local only: kept
+	// across pulls and never pushed. Nodes keep their formatting.
+	Fields map[string]yaml.Node
+}
+
+// LinkedPullRequest is a pull request that closes an issue when merged.
+type LinkedPullRequest struct {
+	Number int    `yaml:"number"`
+	State  string `yaml:"state"` // open, draft, closed, or merged
+	Branch string `yaml:"branch,omitempty"`
+	Checks string `yaml:"checks,omitempty"` // Combined status of the head commit, e.g. success
+}
+
+// SubIssueSummary is the completion rollup of an issue's sub-issues.
+type SubIssueSummary struct {
+	Total     int `yaml:"total"`
+	Completed int `yaml:"completed"`
 }
 
 // InfoSection contains read-only informational fields that are synced from
 // GitHub but never written back. These are for display/filtering only.
 type InfoSection struct {
-	Author    string     `yaml:"author,omitempty"`
-	CreatedAt *time.Time `yaml:"created_at,omitempty"`
-	UpdatedAt *time.Time `yaml:"updated_at,omitempty"`
+	Author       string              `yaml:"author,omitempty"`
+	CreatedAt    *time.Time          `yaml:"created_at,omitempty"`
+	UpdatedAt    *time.Time          `yaml:"updated_at,omitempty"`
+	SubIssues    *SubIssueSummary    `yaml:"sub_issues,omitempty"`
+	ReferencedBy []string            `yaml:"referenced_by,omitempty"`
+	PullRequests []LinkedPullRequest `yaml:"pull_requests,omitempty"`
+	Branches     []string            `yaml:"branches,omitempty"`
+	ClosedBy     int                 `yaml:"closed_by,omitempty"`
+	Comments     int                 `yaml:"comments,omitempty"`
+	Reactions    int                 `yaml:"reactions,omitempty"`
+	Summary      string              `yaml:"summary,omitempty"`
 }
 
 type FrontMatter struct {
-	Title       string       `yaml:"title"`
-	Labels      []string     `yaml:"labels,omitempty"`
-	Assignees   []string     `yaml:"assignees,omitempty"`
-	Milestone   string       `yaml:"milestone,omitempty"`
-	IssueType   string       `yaml:"type,omitempty"`
-	Projects    []string     `yaml:"projects,omitempty"`
-	State       string       `yaml:"state,omitempty"`
-	StateReason *string      `yaml:"state_reason"`
-	Parent      *IssueRef    `yaml:"parent,omitempty"`
-	BlockedBy   []IssueRef   `yaml:"blocked_by,omitempty"`
-	Blocks      []IssueRef   `yaml:"blocks,omitempty"`
-	SyncedAt    *time.Time   `yaml:"synced_at,omitempty"`
-	Info        *InfoSection `yaml:"info,omitempty"`
+	Title           string       `yaml:"title"`
+	Draft           bool         `yaml:"draft,omitempty"`
+	Labels          []string     `yaml:"labels,omitempty"`
+	Priority        string       `yaml:"priority,omitempty"`
+	Assignees       []string     `yaml:"assignees,omitempty"`
+	Milestone       string       `yaml:"milestone,omitempty"`
+	IssueType       string       `yaml:"type,omitempty"`
+	Projects        []string     `yaml:"projects,omitempty"`
+	State           string       `yaml:"state,omitempty"`
+	StateReason     *string      `yaml:"state_reason"`
+	Parent          *IssueRef    `yaml:"parent,omitempty"`
+	BlockedBy       []IssueRef   `yaml:"blocked_by,omitempty"`
+	Blocks          []IssueRef   `yaml:"blocks,omitempty"`
+	CodeRefs        []string     `yaml:"code_refs,omitempty"`
+	LocalState      string       `yaml:"local_state,omitempty"`
+	LastLocalEditBy string       `yaml:"last_local_edit_by,omitempty"`
+	SyncedAt        *time.Time   `yaml:"synced_at,omitempty"`
+	Info            *InfoSection `yaml:"info,omitempty"`
+
+	// Derived keys for Obsidian and Foam, only written in vault mode
+	Aliases []string `yaml:"aliases,omitempty"`
+	Tags    []string `yaml:"tags,omitempty"`
+
+	// Fields collects all other keys, see Issue.Fields.
+	Fields map[string]yaml.Node `yaml:",inline"`
+}
+
+// FieldValue returns the custom field name as text: the value of a scalar
+// or the comma separated values of a list.
+func (i Issue) FieldValue(name string) (string, bool) {
+	node, ok := i.Fields[name]
+	if !ok {
+		return "", false
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, node.Tag != "!!null"
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			values = append(values, item.Value)
+		}
+		return strings.Join(values, ", "), len(values) > 0
+	}
+	return "", false
+}
+
+// SetField sets the custom field name to a scalar value, or removes it if
+// value is empty.
+func (i *Issue) SetField(name, value string) {
+	if value == "" {
+		delete(i.Fields, name)
+		return
+	}
+	if i.Fields == nil {
+		i.Fields = make(map[string]yaml.Node)
+	}
+	i.Fields[name] = yaml.Node{Kind: yaml.ScalarNode, Value: value}
 }
 
 func (n IssueNumber) String() string {
@@ -118,73 +213,213 @@ func numberFromFilename(path string) IssueNumber {
 	return IssueNumber(base[:idx])
 }
 
+// ParseFile reads the issue file at path in the plain format, see
+// Format.ParseFile.
 func ParseFile(path string) (Issue, error) {
+	return Format{}.ParseFile(path)
+}
+
+// ParseFile reads the issue file at path, taking the issue number from its
+// name.
+func (f Format) ParseFile(path string) (Issue, error) {
 	data, err := osReadFile(path)
 	if err != nil {
 		return Issue{}, err
 	}
-	issue, err := Parse(data)
+	return f.ParseNamed(path, data)
+}
+
+// ParseNamed parses an issue file in the plain format, see
+// Format.ParseNamed.
+func ParseNamed(name string, data []byte) (Issue, error) {
+	return Format{}.ParseNamed(name, data)
+}
+
+// ParseNamed parses the content of an issue file, taking the issue number
+// from its name as ParseFile does.
+func (f Format) ParseNamed(name string, data []byte) (Issue, error) {
+	issue, err := f.Parse(data)
 	if err != nil {
 		return Issue{}, err
 	}
-	issue.Number = numberFromFilename(path)
+	issue.Number = numberFromFilename(name)
 	return issue, nil
 }
 
+// ReadHeaderFile parses only the front matter of the issue file at path,
+// see Format.ParseHeader.
+func (f Format) ReadHeaderFile(path string) (Issue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Issue{}, err
+	}
+	defer file.Close()
+	return f.ParseHeaderNamed(path, file)
+}
+
+// ParseHeaderNamed is ParseHeader taking the issue number from name.
+func (f Format) ParseHeaderNamed(name string, r io.Reader) (Issue, error) {
+	issue, err := f.ParseHeader(r)
+	if err != nil {
+		return Issue{}, err
+	}
+	issue.Number = numberFromFilename(name)
+	return issue, nil
+}
+
+// ParseHeader parses only the front matter of an issue file in the plain
+// format, see Format.ParseHeader.
+func ParseHeader(r io.Reader) (Issue, error) {
+	return Format{}.ParseHeader(r)
+}
+
+// ParseHeader parses only the front matter of an issue file, leaving Body
+// empty. It stops reading at the closing delimiter, so listing a large
+// mirror neither reads nor keeps the bodies.
+func (f Format) ParseHeader(r io.Reader) (Issue, error) {
+	br := bufio.NewReader(r)
+	opening, err := br.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return Issue{}, err
+	}
+	opening = bytes.TrimPrefix(opening, []byte("\xef\xbb\xbf"))
+	if !bytes.Equal(opening, append(frontMatterDelimiter, '\n')) {
+		return Issue{}, errors.New("missing front matter")
+	}
+	var front bytes.Buffer
+	for {
+		line, err := br.ReadBytes('\n')
+		if bytes.Equal(bytes.TrimSuffix(line, []byte("\n")), frontMatterDelimiter) {
+			break
+		}
+		if err == io.EOF {
+			return Issue{}, errors.New("unterminated front matter")
+		}
+		if err != nil {
+			return Issue{}, err
+		}
+		front.Write(line)
+	}
+	return f.fromFrontMatter(bytes.TrimSuffix(front.Bytes(), []byte("\n")), nil)
+}
+
+// Parse parses an issue file in the plain format, see Format.Parse.
 func Parse(data []byte) (Issue, error) {
+	return Format{}.Parse(data)
+}
+
+// Parse parses the content of an issue file.
+func (f Format) Parse(data []byte) (Issue, error) {
 	frontMatter, body, err := splitFrontMatter(data)
 	if err != nil {
 		return Issue{}, err
 	}
+	return f.fromFrontMatter(frontMatter, body)
+}
+
+func (f Format) fromFrontMatter(frontMatter, body []byte) (Issue, error) {
 	var fm FrontMatter
 	if err := yaml.Unmarshal(frontMatter, &fm); err != nil {
 		return Issue{}, err
 	}
 	issue := Issue{
-		Title:       fm.Title,
-		Labels:      fm.Labels,
-		Assignees:   fm.Assignees,
-		Milestone:   fm.Milestone,
-		IssueType:   fm.IssueType,
-		Projects:    fm.Projects,
-		State:       fm.State,
-		StateReason: fm.StateReason,
-		Parent:      fm.Parent,
-		BlockedBy:   fm.BlockedBy,
-		Blocks:      fm.Blocks,
-		SyncedAt:    fm.SyncedAt,
-		Body:        normalizeBody(string(body)),
+		Title:           fm.Title,
+		Draft:           fm.Draft,
+		Labels:          fm.Labels,
+		Assignees:       fm.Assignees,
+		Milestone:       fm.Milestone,
+		IssueType:       fm.IssueType,
+		Projects:        fm.Projects,
+		State:           fm.State,
+		StateReason:     fm.StateReason,
+		Parent:          fm.Parent,
+		BlockedBy:       fm.BlockedBy,
+		Blocks:          fm.Blocks,
+		CodeRefs:        fm.CodeRefs,
+		LocalState:      fm.LocalState,
+		LastLocalEditBy: fm.LastLocalEditBy,
+		SyncedAt:        fm.SyncedAt,
+		Body:            normalizeBody(string(body)),
+	}
+	if fm.Priority != "" {
+		issue.Labels = f.WithPriority(issue.Labels, fm.Priority)
 	}
 	if fm.Info != nil {
 		issue.Author = fm.Info.Author
 		issue.CreatedAt = fm.Info.CreatedAt
 		issue.UpdatedAt = fm.Info.UpdatedAt
+		issue.SubIssues = fm.Info.SubIssues
+		issue.ReferencedBy = fm.Info.ReferencedBy
+		issue.PullRequests = fm.Info.PullRequests
+		issue.Branches = fm.Info.Branches
+		issue.ClosedBy = fm.Info.ClosedBy
+		issue.CommentCount = fm.Info.Comments
+		issue.Reactions = fm.Info.Reactions
+		issue.Summary = fm.Info.Summary
+	}
+	if len(fm.Fields) > 0 {
+		issue.Fields = fm.Fields
 	}
 	return issue, nil
 }
 
+// Render renders an issue file in the plain format, see Format.Render.
 func Render(issue Issue) (string, error) {
+	return Format{}.Render(issue)
+}
+
+// Render renders issue as the content of its file.
+func (f Format) Render(issue Issue) (string, error) {
 	fm := FrontMatter{
-		Title:       issue.Title,
-		Labels:      sortedStrings(issue.Labels),
-		Assignees:   sortedStrings(issue.Assignees),
-		Milestone:   issue.Milestone,
-		IssueType:   issue.IssueType,
-		Projects:    sortedStrings(issue.Projects),
-		State:       issue.State,
-		StateReason: issue.StateReason,
-		Parent:      issue.Parent,
-		BlockedBy:   sortedRefs(issue.BlockedBy),
-		Blocks:      sortedRefs(issue.Blocks),
-		SyncedAt:    issue.SyncedAt,
-	}
-	if issue.Author != "" || issue.CreatedAt != nil || issue.UpdatedAt != nil {
+		Title:           issue.Title,
+		Draft:           issue.Draft,
+		Labels:          sortedStrings(issue.Labels),
+		Assignees:       sortedStrings(issue.Assignees),
+		Milestone:       issue.Milestone,
+		IssueType:       issue.IssueType,
+		Projects:        sortedStrings(issue.Projects),
+		State:           issue.State,
+		StateReason:     issue.StateReason,
+		Parent:          issue.Parent,
+		BlockedBy:       sortedRefs(issue.BlockedBy),
+		Blocks:          sortedRefs(issue.Blocks),
+		CodeRefs:        issue.CodeRefs,
+		LocalState:      issue.LocalState,
+		LastLocalEditBy: issue.LastLocalEditBy,
+		SyncedAt:        issue.SyncedAt,
+		Fields:          issue.Fields,
+	}
+	// The field holds a single priority; with several priority labels all
+	// of them stay in the list, so none is lost on the next write
+	if labels, priority := f.splitPriority(issue.Labels); priority != "" && len(labels) == len(issue.Labels)-1 {
+		fm.Labels, fm.Priority = sortedStrings(labels), priority
+	}
+	if issue.SubIssues != nil && issue.SubIssues.Total == 0 {
+		issue.SubIssues = nil
+	}
+	if issue.Author != "" || issue.CreatedAt != nil || issue.UpdatedAt != nil || issue.SubIssues != nil ||
+		len(issue.ReferencedBy) > 0 || len(issue.PullRequests) > 0 || len(issue.Branches) > 0 || issue.ClosedBy != 0 ||
+		issue.CommentCount != 0 || issue.Reactions != 0 || issue.Summary != "" {
 		fm.Info = &InfoSection{
-			Author:    issue.Author,
-			CreatedAt: issue.CreatedAt,
-			UpdatedAt: issue.UpdatedAt,
+			Author:       issue.Author,
+			CreatedAt:    issue.CreatedAt,
+			UpdatedAt:    issue.UpdatedAt,
+			SubIssues:    issue.SubIssues,
+			ReferencedBy: issue.ReferencedBy,
+			PullRequests: issue.PullRequests,
+			Branches:     issue.Branches,
+			ClosedBy:     issue.ClosedBy,
+			Comments:     issue.CommentCount,
+			Reactions:    issue.Reactions,
+			Summary:      issue.Summary,
 		}
 	}
+	body := normalizeBody(issue.Body)
+	if f.Vault {
+		fm.Aliases = VaultAliases(issue)
+		fm.Tags = VaultTags(issue.Labels)
+		body = VaultLinks(body)
+	}
 	payload, err := yaml.Marshal(&fm)
 	if err != nil {
 		return "", err
@@ -196,12 +431,18 @@ func Render(issue Issue) (string, error) {
 	buf.Write(frontMatterDelimiter)
 	buf.WriteByte('\n')
 	buf.WriteByte('\n')
-	buf.WriteString(normalizeBody(issue.Body))
+	buf.WriteString(body)
 	return buf.String(), nil
 }
 
+// WriteFile writes an issue file in the plain format, see Format.WriteFile.
 func WriteFile(path string, issue Issue) error {
-	content, err := Render(issue)
+	return Format{}.WriteFile(path, issue)
+}
+
+// WriteFile renders issue to path.
+func (f Format) WriteFile(path string, issue Issue) error {
+	content, err := f.Render(issue)
 	if err != nil {
 		return err
 	}
@@ -271,6 +512,9 @@ func equalIssues(a, b Issue, ignoreStateReason bool) bool {
 	if a.Title != b.Title {
 		return false
 	}
+	if a.Draft != b.Draft {
+		return false
+	}
 	if !stringSlicesEqual(a.Labels, b.Labels) {
 		return false
 	}
@@ -301,7 +545,7 @@ func equalIssues(a, b Issue, ignoreStateReason bool) bool {
 	if !refSlicesEqual(a.Blocks, b.Blocks) {
 		return false
 	}
-	if a.Body != b.Body {
+	if PublicBody(a.Body) != PublicBody(b.Body) {
 		return false
 	}
 	return true
@@ -542,16 +786,20 @@ func ComputeChanges(base, changed Issue) FieldSet {
 		Parent:    normalizeOptionalRef(base.Parent) != normalizeOptionalRef(changed.Parent),
 		BlockedBy: !refSlicesEqual(base.BlockedBy, changed.BlockedBy),
 		Blocks:    !refSlicesEqual(base.Blocks, changed.Blocks),
-		Body:      base.Body != changed.Body,
+		Body:      PublicBody(base.Body) != PublicBody(changed.Body),
 	}
 }
 
 // MergeResult represents the outcome of a three-way merge.
 type MergeResult struct {
-	// Merged contains the merged issue (only valid if OK is true).
+	// Merged contains the merged issue. It is valid if OK is true, or if
+	// BodyConflict is set, in which case the body has conflict markers.
 	Merged Issue
 	// OK is true if the merge succeeded without conflicts.
 	OK bool
+	// BodyConflict is true if the body is the only conflicting field and
+	// both sides changed overlapping lines.
+	BodyConflict bool
 	// ConflictingFields lists the fields that conflict (only if OK is false).
 	ConflictingFields FieldSet
 	// LocalChanges lists fields changed locally.
@@ -562,7 +810,8 @@ type MergeResult struct {
 
 // ThreeWayMerge attempts to merge local and remote changes against a common base.
 // If changes don't overlap, it returns a merged issue. Otherwise, it returns
-// information about which fields conflict.
+// information about which fields conflict. A body changed on both sides is
+// merged line by line and only conflicts if the changed lines overlap.
 func ThreeWayMerge(base, local, remote Issue) MergeResult {
 	localChanges := ComputeChanges(base, local)
 	remoteChanges := ComputeChanges(base, remote)
@@ -573,7 +822,23 @@ func ThreeWayMerge(base, local, remote Issue) MergeResult {
 		RemoteChanges: remoteChanges,
 	}
 
-	if !conflicts.IsEmpty() {
+	var mergedBody string
+	bodyMerged := false
+	if conflicts.Body {
+		var ok bool
+		// Merge the public text and restore local notes and wiki links
+		// afterwards, so local-only syntax never causes a conflict.
+		mergedBody, ok = MergeText(PublicBody(base.Body), PublicBody(local.Body), PublicBody(remote.Body))
+		mergedBody = KeepLocalSyntax(mergedBody, local.Body)
+		if ok {
+			conflicts.Body = false
+			bodyMerged = true
+		} else {
+			result.BodyConflict = conflicts == FieldSet{Body: true}
+		}
+	}
+
+	if !conflicts.IsEmpty() && !result.BodyConflict {
 		result.ConflictingFields = conflicts
 		return result
 	}
@@ -611,11 +876,25 @@ func ThreeWayMerge(base, local, remote Issue) MergeResult {
 	if localChanges.Blocks {
 		merged.Blocks = local.Blocks
 	}
-	if localChanges.Body {
+	if bodyMerged || result.BodyConflict {
+		merged.Body = mergedBody
+	} else if localChanges.Body {
 		merged.Body = local.Body
+	} else {
+		merged.Body = KeepLocalSyntax(merged.Body, local.Body)
 	}
 
+	merged.CodeRefs = local.CodeRefs
+	merged.Fields = local.Fields
+	merged.LocalState = local.LocalState
+	merged.Summary = local.Summary
+	merged.LastLocalEditBy = local.LastLocalEditBy
+
 	result.Merged = merged
+	if result.BodyConflict {
+		result.ConflictingFields = conflicts
+		return result
+	}
 	result.OK = true
 	return result
 }
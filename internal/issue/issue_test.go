@@ -1,8 +1,13 @@
 package issue
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
@@ -84,6 +89,28 @@ Body
 	}
 }
 
+func TestParseHeaderStopsAtDelimiter(t *testing.T) {
+	header := "---\ntitle: Test\nlabels: [bug]\nstate: open\n---\n"
+	full, err := Parse([]byte(header + "\nBody\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// Reading past the header would hit the failing reader
+	failing := io.MultiReader(strings.NewReader(header), iotest.ErrReader(errors.New("read past the header")))
+	parsed, err := ParseHeader(failing)
+	if err != nil {
+		t.Fatalf("ParseHeader failed: %v", err)
+	}
+	full.Body = ""
+	if !reflect.DeepEqual(parsed, full) {
+		t.Fatalf("header mismatch:\n%#v\n%#v", parsed, full)
+	}
+
+	if _, err := ParseHeader(strings.NewReader("---\ntitle: Test\n")); err == nil {
+		t.Fatalf("expected unterminated front matter error")
+	}
+}
+
 func TestSlugify(t *testing.T) {
 	cases := map[string]string{
 		"Fix login bug":          "fix-login-bug",
@@ -276,6 +303,75 @@ func TestThreeWayMerge_Conflict(t *testing.T) {
 	}
 }
 
+func TestThreeWayMerge_Body(t *testing.T) {
+	base := Issue{Title: "T", State: "open", Body: "Intro\n\nSteps:\n1. run\n\nExpected: works\n"}
+	local := base
+	local.Body = "Intro, edited locally\n\nSteps:\n1. run\n\nExpected: works\n"
+	remote := base
+	remote.Body = "Intro\n\nSteps:\n1. run\n\nExpected: works without crashing\n"
+
+	result := ThreeWayMerge(base, local, remote)
+	if !result.OK {
+		t.Fatalf("expected body to merge, got conflicts %v", result.ConflictingFields.Fields())
+	}
+	want := "Intro, edited locally\n\nSteps:\n1. run\n\nExpected: works without crashing\n"
+	if result.Merged.Body != want {
+		t.Fatalf("unexpected merged body:\n%s", result.Merged.Body)
+	}
+
+	remote.Body = "Intro from upstream\n\nSteps:\n1. run\n\nExpected: works\n"
+	result = ThreeWayMerge(base, local, remote)
+	if result.OK || !result.BodyConflict {
+		t.Fatalf("expected body conflict, got %+v", result)
+	}
+	if !HasConflictMarkers(result.Merged.Body) {
+		t.Fatalf("expected conflict markers, got:\n%s", result.Merged.Body)
+	}
+	if !strings.Contains(result.Merged.Body, "<<<<<<< local\nIntro, edited locally\n=======\nIntro from upstream\n>>>>>>> remote\n") {
+		t.Fatalf("unexpected conflict hunk:\n%s", result.Merged.Body)
+	}
+
+	remote.Title = "Changed upstream"
+	local.Title = "Changed locally"
+	result = ThreeWayMerge(base, local, remote)
+	if result.OK || result.BodyConflict {
+		t.Fatalf("title conflict should not be reported as a body conflict")
+	}
+}
+
+func TestMergeText(t *testing.T) {
+	tests := []struct {
+		name, base, local, remote, want string
+		ok                              bool
+	}{
+		{"both append apart", "a\nb\nc\n", "x\na\nb\nc\n", "a\nb\nc\ny\n", "x\na\nb\nc\ny\n", true},
+		{"same change", "a\nb\n", "a\nB\n", "a\nB\n", "a\nB\n", true},
+		{"local delete", "a\nb\nc\n", "a\nc\n", "a\nb\nc\nd\n", "a\nc\nd\n", true},
+		{"overlap", "a\nb\nc\n", "a\nL\nc\n", "a\nR\nc\n", "a\n<<<<<<< local\nL\n=======\nR\n>>>>>>> remote\nc\n", false},
+	}
+	for _, tt := range tests {
+		got, ok := MergeText(tt.base, tt.local, tt.remote)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: got %q (ok=%v), want %q (ok=%v)", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMergeTextHugeBody(t *testing.T) {
+	// Pasted logs make for bodies of thousands of lines
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	base := strings.Join(lines, "\n") + "\n"
+	local := strings.Replace(base, "line 10\n", "line ten\n", 1)
+	remote := strings.Replace(base, "line 15000\n", "line fifteen thousand\n", 1)
+	want := strings.Replace(local, "line 15000\n", "line fifteen thousand\n", 1)
+	if got, ok := MergeText(base, local, remote); !ok || got != want {
+		t.Fatalf("expected a clean merge of both edits (ok=%v)", ok)
+	}
+}
+
 func TestThreeWayMerge_NoLocalChanges(t *testing.T) {
 	base := Issue{
 		Title:  "Original title",
@@ -313,3 +409,370 @@ func TestThreeWayMerge_NoLocalChanges(t *testing.T) {
 		t.Errorf("expected merged to have remote labels, got %v", result.Merged.Labels)
 	}
 }
+
+func TestLocalOnlyFields(t *testing.T) {
+	base := Issue{Title: "Token expiry", State: "open", Body: "Body"}
+	local := base
+	local.CodeRefs = []string{"src/auth.go:42@abc123"}
+	local.LocalState = "done-pending-release"
+	if !EqualIgnoringSyncedAt(base, local) {
+		t.Fatalf("code refs and local state should not count as a local change")
+	}
+
+	content, err := Render(local)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	parsed, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(parsed.CodeRefs) != 1 || parsed.CodeRefs[0] != "src/auth.go:42@abc123" || parsed.LocalState != local.LocalState {
+		t.Fatalf("local fields did not round-trip: %+v", parsed)
+	}
+
+	remote := base
+	remote.Title = "Token expiry on refresh"
+	result := ThreeWayMerge(base, local, remote)
+	if !result.OK || len(result.Merged.CodeRefs) != 1 || result.Merged.LocalState != local.LocalState {
+		t.Fatalf("merge should keep the local fields: %+v", result.Merged)
+	}
+}
+
+func TestCustomFields(t *testing.T) {
+	content := "---\ntitle: Token expiry\nstate: open\neffort: 3\ndue: 2026-11-01\nteams: [auth, core]\n---\n\nBody\n"
+	parsed, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if value, ok := parsed.FieldValue("effort"); !ok || value != "3" {
+		t.Fatalf("unexpected effort: %q %v", value, ok)
+	}
+	if value, ok := parsed.FieldValue("teams"); !ok || value != "auth, core" {
+		t.Fatalf("unexpected teams: %q %v", value, ok)
+	}
+	if _, ok := parsed.FieldValue("missing"); ok {
+		t.Fatal("expected no value for a missing field")
+	}
+	if !EqualIgnoringSyncedAt(parsed, Issue{Title: "Token expiry", State: "open", Body: "Body"}) {
+		t.Fatal("custom fields should not count as a local change")
+	}
+
+	rendered, err := Render(parsed)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{"effort: 3\n", "due: 2026-11-01\n", "teams: [auth, core]\n"} {
+		if !strings.Contains(rendered, want) {
+			t.Fatalf("expected %q kept as written:\n%s", want, rendered)
+		}
+	}
+
+	parsed.SetField("effort", "5")
+	parsed.SetField("estimate", "0.5")
+	parsed.SetField("due", "")
+	rendered, err = Render(parsed)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(rendered, "effort: 5\n") || !strings.Contains(rendered, "estimate: 0.5\n") || strings.Contains(rendered, "due:") {
+		t.Fatalf("unexpected fields after SetField:\n%s", rendered)
+	}
+}
+
+func TestMilestoneRoundTrip(t *testing.T) {
+	due := "2025-03-01T08:00:00Z"
+	m := Milestone{Title: "v1.0", DueOn: &due, Description: "First release"}
+
+	rendered, err := RenderMilestone(m)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if !strings.Contains(rendered, "due_on: \"2025-03-01\"") {
+		t.Fatalf("expected due date trimmed to a date, got:\n%s", rendered)
+	}
+
+	parsed, err := ParseMilestone([]byte(rendered))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if parsed.State != "open" {
+		t.Fatalf("expected default state open, got %q", parsed.State)
+	}
+	if !MilestonesEqual(parsed, m) {
+		t.Fatalf("round trip mismatch: %+v vs %+v", parsed, m)
+	}
+}
+
+func TestSubIssueSummaryRoundTrip(t *testing.T) {
+	iss := Issue{Title: "Epic", State: "open", SubIssues: &SubIssueSummary{Total: 7, Completed: 3}}
+	rendered, err := Render(iss)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if !strings.Contains(rendered, "sub_issues:") {
+		t.Fatalf("expected sub_issues in info section:\n%s", rendered)
+	}
+	parsed, err := Parse([]byte(rendered))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if parsed.SubIssues == nil || parsed.SubIssues.Total != 7 || parsed.SubIssues.Completed != 3 {
+		t.Fatalf("unexpected sub-issue summary: %+v", parsed.SubIssues)
+	}
+	if !EqualIgnoringSyncedAt(iss, Issue{Title: "Epic", State: "open"}) {
+		t.Fatalf("sub-issue summary must not count as a local change")
+	}
+}
+
+func TestParseTasks(t *testing.T) {
+	body := "Intro\n- [ ] First task\n  * [x] Done task\n```\n- [ ] in code\n```\n- [ ] #42\n- [ ]\n"
+	tasks := ParseTasks(body)
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %d: %+v", len(tasks), tasks)
+	}
+	if tasks[0].Line != 1 || tasks[0].Text != "First task" || tasks[0].Checked {
+		t.Fatalf("unexpected first task: %+v", tasks[0])
+	}
+	if !tasks[1].Checked || tasks[1].Prefix != "  * [x] " {
+		t.Fatalf("unexpected second task: %+v", tasks[1])
+	}
+	if !tasks[2].IsIssueReference() {
+		t.Fatalf("expected #42 to be an issue reference")
+	}
+
+	replaced := ReplaceTaskLines(body, map[int]string{1: "- [ ] #T1"})
+	if !strings.Contains(replaced, "- [ ] #T1\n  * [x] Done task") {
+		t.Fatalf("unexpected replacement result: %q", replaced)
+	}
+}
+
+func TestSetTaskChecked(t *testing.T) {
+	body := "- [ ] One\n  - [x] Two\n"
+	tasks := ParseTasks(body)
+	body = SetTaskChecked(body, tasks[0], true)
+	body = SetTaskChecked(body, tasks[1], false)
+	if body != "- [x] One\n  - [ ] Two\n" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+	if done, total := TaskProgress(body); done != 1 || total != 2 {
+		t.Fatalf("expected 1/2, got %d/%d", done, total)
+	}
+}
+
+func TestLocalNotes(t *testing.T) {
+	body := "Public text.\n\n<!-- local-notes\ncheck with bob first\n-->\n\nMore public text.\n"
+	if got := StripLocalNotes(body); got != "Public text.\n\nMore public text.\n" {
+		t.Fatalf("unexpected stripped body: %q", got)
+	}
+	notes := LocalNotes(body)
+	if notes != "<!-- local-notes\ncheck with bob first\n-->" {
+		t.Fatalf("unexpected notes: %q", notes)
+	}
+
+	original := Issue{Number: "1", Title: "A", State: "open", Body: "Public text.\n\nMore public text.\n"}
+	local := original
+	local.Body = body
+	if !EqualIgnoringSyncedAt(original, local) {
+		t.Fatalf("local notes should not count as a change")
+	}
+
+	remote := original
+	remote.Body = "Rewritten upstream.\n"
+	merged := ThreeWayMerge(original, local, remote)
+	if !merged.OK || !strings.Contains(merged.Merged.Body, "check with bob first") {
+		t.Fatalf("expected notes to survive merge, got %+v", merged)
+	}
+	if WithLocalNotes(merged.Merged.Body, notes) != merged.Merged.Body {
+		t.Fatalf("notes should not be appended twice")
+	}
+}
+
+func TestWikiLinks(t *testing.T) {
+	local := "Blocked on [[#12]] and [[T1a2b]].\nSee also #7 and [[#12]].\n"
+	if got := strings.Join(WikiLinks(local), ","); got != "12,T1a2b" {
+		t.Fatalf("unexpected links: %s", got)
+	}
+	public := PublicBody(local)
+	if public != "Blocked on #12 and #T1a2b.\nSee also #7 and #12.\n" {
+		t.Fatalf("unexpected public body: %q", public)
+	}
+
+	original := Issue{Number: "3", Title: "A", State: "open", Body: public}
+	withLinks := original
+	withLinks.Body = local
+	if !EqualIgnoringSyncedAt(original, withLinks) {
+		t.Fatalf("wiki links should not count as a change")
+	}
+
+	remote := "Blocked on #12 (still). See #7, #120 and https://x/#12.\n"
+	want := "Blocked on [[#12]] (still). See #7, #120 and https://x/#12.\n"
+	if got := WithWikiLinks(remote, local); got != want {
+		t.Fatalf("unexpected restored body: %q", got)
+	}
+	if got := WithWikiLinks(want, local); got != want {
+		t.Fatalf("restoring twice should be a no-op: %q", got)
+	}
+}
+
+func TestPriorityLabels(t *testing.T) {
+	format := Format{PriorityPrefix: "priority/"}
+
+	iss := Issue{Title: "Outage", State: "open", Labels: []string{"priority/P1", "bug"}}
+	rendered, err := format.Render(iss)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(rendered, "labels:\n    - bug\npriority: P1\n") {
+		t.Fatalf("expected the priority field instead of the label:\n%s", rendered)
+	}
+	if format.Priority(iss) != "P1" || format.PriorityRank("p1") != 1 || format.PriorityRank("P9") != -1 {
+		t.Fatalf("unexpected priority lookup")
+	}
+
+	// Two priorities don't fit the field, so both stay labels
+	iss.Labels = []string{"priority/P2", "bug", "priority/P1"}
+	rendered, err = format.Render(iss)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(rendered, "priority: ") {
+		t.Fatalf("expected no priority field for two priority labels:\n%s", rendered)
+	}
+	parsed, err := format.Parse([]byte(rendered))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(parsed.Labels, []string{"bug", "priority/P1", "priority/P2"}) {
+		t.Fatalf("expected both priority labels kept, got %v", parsed.Labels)
+	}
+
+	// The field wins over priority labels left in the list
+	parsed, err = format.Parse([]byte("---\ntitle: Outage\nlabels: [bug, priority/P3]\npriority: P0\nstate: open\n---\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(parsed.Labels, []string{"bug", "priority/P0"}) {
+		t.Fatalf("labels = %v", parsed.Labels)
+	}
+}
+
+func TestVaultMode(t *testing.T) {
+	format := Format{Vault: true}
+
+	iss := Issue{
+		Number: "42",
+		Title:  "Fix login",
+		State:  "open",
+		Labels: []string{"good first issue", "area: ui"},
+		Body:   "Needs #12 and [[#T1a2b]].\n\n```\n#7 stays\n```\n",
+	}
+	rendered, err := format.Render(iss)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{"aliases:\n    - \"42\"\n    - Fix login", "tags:\n    - area-ui\n    - good-first-issue", "Needs [[12]] and [[T1a2b]].", "#7 stays"} {
+		if !strings.Contains(rendered, want) {
+			t.Fatalf("expected %q in:\n%s", want, rendered)
+		}
+	}
+
+	parsed, err := format.Parse([]byte(rendered))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	parsed.Number = iss.Number
+	if !EqualIgnoringSyncedAt(iss, parsed) {
+		t.Fatalf("vault keys and links should not count as changes:\n%s", rendered)
+	}
+	if PublicBody(parsed.Body) != "Needs #12 and #T1a2b.\n\n```\n#7 stays\n```\n" {
+		t.Fatalf("unexpected public body: %q", PublicBody(parsed.Body))
+	}
+}
+
+func TestRecurringPeriodStart(t *testing.T) {
+	r, err := ParseRecurring([]byte("---\ntitle: Report\nevery: monthly\nstart: 2026-01-31\n---\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	cases := map[string]string{
+		"2026-02-27": "2026-01-31",
+		"2026-02-28": "2026-02-28", // clamped to the end of February
+		"2026-03-30": "2026-02-28",
+		"2026-03-31": "2026-03-31",
+	}
+	for now, want := range cases {
+		day, _ := time.ParseInLocation("2006-01-02", now, time.Local)
+		if got := r.PeriodStart(day.Add(10 * time.Hour)).Format("2006-01-02"); got != want {
+			t.Fatalf("period start for %s: got %s, want %s", now, got, want)
+		}
+	}
+	if _, due := r.Due(time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local), ""); due {
+		t.Fatalf("should not be due before start")
+	}
+	if _, err := ParseRecurring([]byte("---\ntitle: Report\nevery: hourly\n---\n")); err == nil {
+		t.Fatalf("expected error for unsupported interval")
+	}
+}
+
+func benchIssue() Issue {
+	syncedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	parent := IssueRef("7")
+	return Issue{
+		Number:    "42",
+		Title:     "Crash when opening large files",
+		Labels:    []string{"bug", "p1", "ui"},
+		Assignees: []string{"alice", "bob"},
+		Milestone: "v1.0",
+		State:     "open",
+		Parent:    &parent,
+		BlockedBy: []IssueRef{"11", "12"},
+		SyncedAt:  &syncedAt,
+		Author:    "carol",
+		CreatedAt: &syncedAt,
+		Body:      strings.Repeat("The editor crashes when a file is larger than the buffer limit.\n\n", 200),
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	content, err := Render(benchIssue())
+	if err != nil {
+		b.Fatal(err)
+	}
+	data := []byte(content)
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		if _, err := Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseHeader(b *testing.B) {
+	content, err := Render(benchIssue())
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, err := ParseHeader(strings.NewReader(content)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	item := benchIssue()
+	for b.Loop() {
+		if _, err := Render(item); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEqualIgnoringSyncedAt(b *testing.B) {
+	left, right := benchIssue(), benchIssue()
+	right.Body += "One more line.\n"
+	for b.Loop() {
+		EqualIgnoringSyncedAt(left, right)
+	}
+}
@@ -0,0 +1,90 @@
+package issue
+
+import (
+	"regexp"
+	"strings"
+)
+
+// localNotesPattern matches private annotation blocks in an issue body:
+//
+//	<!-- local-notes
+//	anything here stays on this machine
+//	-->
+//
+// Such blocks are stripped before a body is sent to the remote and carried
+// over when a pull rewrites the file.
+var localNotesPattern = regexp.MustCompile(`(?s)\n*<!--\s*local-notes\b.*?-->[ \t]*\n?`)
+
+// StripLocalNotes removes all local-notes blocks from body.
+func StripLocalNotes(body string) string {
+	if !strings.Contains(body, "local-notes") {
+		return body
+	}
+	stripped := localNotesPattern.ReplaceAllString(body, "\n")
+	return normalizeBody(strings.TrimRight(stripped, "\n"))
+}
+
+// LocalNotes returns the local-notes blocks in body, separated by blank lines.
+func LocalNotes(body string) string {
+	matches := localNotesPattern.FindAllString(body, -1)
+	blocks := make([]string, 0, len(matches))
+	for _, m := range matches {
+		blocks = append(blocks, strings.TrimSpace(m))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// WithLocalNotes appends notes (as returned by LocalNotes) to body unless
+// body already carries local notes.
+func WithLocalNotes(body, notes string) string {
+	if notes == "" || LocalNotes(body) != "" {
+		return body
+	}
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return notes + "\n"
+	}
+	return body + "\n\n" + notes + "\n"
+}
+
+// notesBlockPattern matches a single local-notes block and captures the
+// encrypted marker and the content.
+var notesBlockPattern = regexp.MustCompile(`(?s)<!--\s*local-notes(:encrypted)?[ \t]*\n?(.*?)\n?[ \t]*-->`)
+
+// NotesBlock is the content of a single local-notes block.
+type NotesBlock struct {
+	Encrypted bool
+	Content   string
+}
+
+// Render formats the block as it appears in an issue body.
+func (b NotesBlock) Render() string {
+	marker := "local-notes"
+	if b.Encrypted {
+		marker += ":encrypted"
+	}
+	return "<!-- " + marker + "\n" + strings.TrimRight(b.Content, "\n") + "\n-->"
+}
+
+// MapLocalNotes replaces every local-notes block in body with the result of
+// fn. It stops at the first error.
+func MapLocalNotes(body string, fn func(NotesBlock) (NotesBlock, error)) (string, error) {
+	matches := notesBlockPattern.FindAllStringSubmatchIndex(body, -1)
+	if len(matches) == 0 {
+		return body, nil
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		block := NotesBlock{Encrypted: m[2] >= 0, Content: body[m[4]:m[5]]}
+		mapped, err := fn(block)
+		if err != nil {
+			return body, err
+		}
+		b.WriteString(body[last:m[0]])
+		b.WriteString(mapped.Render())
+		last = m[1]
+	}
+	b.WriteString(body[last:])
+	return b.String(), nil
+}
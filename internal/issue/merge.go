@@ -0,0 +1,126 @@
+package issue
+
+import (
+	"strings"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/textdiff"
+)
+
+// Conflict markers written by MergeText when both sides changed the same
+// lines.
+const (
+	conflictMarkerLocal  = "<<<<<<< local"
+	conflictMarkerSep    = "======="
+	conflictMarkerRemote = ">>>>>>> remote"
+)
+
+// MergeText performs a line-based three-way merge (diff3) of local and
+// remote against base. Hunks changed on only one side are taken from that
+// side. It returns false when both sides changed overlapping hunks
+// differently; the returned text then contains conflict markers around
+// those hunks.
+func MergeText(base, local, remote string) (string, bool) {
+	baseLines := splitLines(base)
+	localLines := splitLines(local)
+	remoteLines := splitLines(remote)
+	toLocal := matchLines(baseLines, localLines)
+	toRemote := matchLines(baseLines, remoteLines)
+
+	var out strings.Builder
+	clean := true
+	i, j, k := 0, 0, 0
+	for i < len(baseLines) || j < len(localLines) || k < len(remoteLines) {
+		// Find the next base line kept by both sides
+		b := i
+		for b < len(baseLines) && (toLocal[b] < 0 || toRemote[b] < 0) {
+			b++
+		}
+		nextLocal, nextRemote := len(localLines), len(remoteLines)
+		if b < len(baseLines) {
+			nextLocal, nextRemote = toLocal[b], toRemote[b]
+		}
+		if b == i && nextLocal == j && nextRemote == k {
+			writeLines(&out, baseLines[i:i+1])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		baseChunk := baseLines[i:b]
+		localChunk := localLines[j:nextLocal]
+		remoteChunk := remoteLines[k:nextRemote]
+		switch {
+		case linesEqual(localChunk, baseChunk):
+			writeLines(&out, remoteChunk)
+		case linesEqual(remoteChunk, baseChunk), linesEqual(localChunk, remoteChunk):
+			writeLines(&out, localChunk)
+		default:
+			clean = false
+			out.WriteString(conflictMarkerLocal + "\n")
+			writeLines(&out, localChunk)
+			out.WriteString(conflictMarkerSep + "\n")
+			writeLines(&out, remoteChunk)
+			out.WriteString(conflictMarkerRemote + "\n")
+		}
+		i, j, k = b, nextLocal, nextRemote
+	}
+	merged := out.String()
+	if clean && local != "" && !strings.HasSuffix(local, "\n") {
+		merged = strings.TrimSuffix(merged, "\n")
+	}
+	return merged, clean
+}
+
+// HasConflictMarkers reports whether text contains a conflict left behind
+// by MergeText.
+func HasConflictMarkers(text string) bool {
+	hasLocal, hasRemote := false, false
+	for _, line := range strings.Split(text, "\n") {
+		switch strings.TrimRight(line, "\r") {
+		case conflictMarkerLocal:
+			hasLocal = true
+		case conflictMarkerRemote:
+			hasRemote = true
+		}
+	}
+	return hasLocal && hasRemote
+}
+
+// splitLines splits text into lines without their trailing newlines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// writeLines writes lines, each followed by a newline.
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+}
+
+func linesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// maxMergeEdits bounds the edit distance MergeText searches for between
+// base and each side. Sides that differ by more match no base line, so
+// their changes conflict unless the other side left the body alone.
+const maxMergeEdits = 1000
+
+// matchLines returns, for each line of a, the index of the matching line
+// in b or -1.
+func matchLines(a, b []string) []int {
+	match, _ := textdiff.Match(a, b, maxMergeEdits)
+	return match
+}
@@ -0,0 +1,126 @@
+package issue
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Milestone is a milestone described by a file in .issues/milestones/.
+// The description is stored as the Markdown body.
+type Milestone struct {
+	Title       string
+	State       string  // "open" or "closed"
+	DueOn       *string // Date in YYYY-MM-DD form
+	Description string
+}
+
+type milestoneFrontMatter struct {
+	Title string  `yaml:"title"`
+	State string  `yaml:"state,omitempty"`
+	DueOn *string `yaml:"due_on,omitempty"`
+}
+
+// ParseMilestoneFile reads a milestone file from disk.
+func ParseMilestoneFile(path string) (Milestone, error) {
+	data, err := osReadFile(path)
+	if err != nil {
+		return Milestone{}, err
+	}
+	return ParseMilestone(data)
+}
+
+// ParseMilestone parses milestone file contents.
+func ParseMilestone(data []byte) (Milestone, error) {
+	frontMatter, body, err := splitFrontMatter(data)
+	if err != nil {
+		return Milestone{}, err
+	}
+	var fm milestoneFrontMatter
+	if err := yaml.Unmarshal(frontMatter, &fm); err != nil {
+		return Milestone{}, err
+	}
+	m := Milestone{
+		Title:       fm.Title,
+		State:       fm.State,
+		DueOn:       fm.DueOn,
+		Description: normalizeBody(string(body)),
+	}
+	return NormalizeMilestone(m), nil
+}
+
+// RenderMilestone renders a milestone as a Markdown file with front matter.
+func RenderMilestone(m Milestone) (string, error) {
+	m = NormalizeMilestone(m)
+	fm := milestoneFrontMatter{
+		Title: m.Title,
+		State: m.State,
+		DueOn: m.DueOn,
+	}
+	payload, err := yaml.Marshal(&fm)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	buf.Write(frontMatterDelimiter)
+	buf.WriteByte('\n')
+	buf.Write(payload)
+	buf.Write(frontMatterDelimiter)
+	buf.WriteByte('\n')
+	buf.WriteByte('\n')
+	buf.WriteString(m.Description)
+	return buf.String(), nil
+}
+
+// WriteMilestoneFile renders a milestone and writes it to path.
+func WriteMilestoneFile(path string, m Milestone) error {
+	content, err := RenderMilestone(m)
+	if err != nil {
+		return err
+	}
+	return osWriteFile(path, []byte(content), 0o644)
+}
+
+// MilestonePathFor returns the file path for a milestone title in dir.
+func MilestonePathFor(dir, title string) string {
+	slug := Slugify(title)
+	if slug == "" {
+		slug = "milestone"
+	}
+	return filepath.Join(dir, slug+".md")
+}
+
+// NormalizeMilestone trims the due date to its date portion, defaults the
+// state to open and normalizes the description.
+func NormalizeMilestone(m Milestone) Milestone {
+	m.Title = strings.TrimSpace(m.Title)
+	m.State = strings.ToLower(strings.TrimSpace(m.State))
+	if m.State == "" {
+		m.State = "open"
+	}
+	if m.DueOn != nil {
+		due := strings.TrimSpace(*m.DueOn)
+		if len(due) > 10 {
+			due = due[:10]
+		}
+		if due == "" {
+			m.DueOn = nil
+		} else {
+			m.DueOn = &due
+		}
+	}
+	m.Description = normalizeBody(m.Description)
+	return m
+}
+
+// MilestonesEqual reports whether two milestones have the same content.
+func MilestonesEqual(a, b Milestone) bool {
+	a = NormalizeMilestone(a)
+	b = NormalizeMilestone(b)
+	return a.Title == b.Title &&
+		a.State == b.State &&
+		normalizeOptional(a.DueOn) == normalizeOptional(b.DueOn) &&
+		a.Description == b.Description
+}
@@ -0,0 +1,100 @@
+package issue
+
+import "strings"
+
+// DefaultPriorityLevels are the priorities from most to least urgent when
+// none are configured.
+var DefaultPriorityLevels = []string{"P0", "P1", "P2", "P3"}
+
+// Format holds the settings of a repository that change how its issue
+// files are written and read. The zero value is the plain format.
+type Format struct {
+	// PriorityPrefix makes labels starting with it show up as the priority
+	// front matter field: with "priority/", the label "priority/P1" is
+	// written as "priority: P1" and parsed back into the label. In memory
+	// the priority stays a label, so sync semantics are unaffected. Empty
+	// disables the field.
+	PriorityPrefix string
+	// PriorityLevels are ordered from most to least urgent;
+	// DefaultPriorityLevels when empty.
+	PriorityLevels []string
+	// Vault writes files that work as an Obsidian or Foam vault: aliases
+	// and tags in the front matter and wiki links in the body. The extra
+	// front matter keys are derived on every write and ignored when
+	// parsing, and wiki links are expanded before anything is compared or
+	// pushed, so sync semantics are unaffected.
+	Vault bool
+}
+
+// Levels returns the configured priority levels, most urgent first.
+func (f Format) Levels() []string {
+	if len(f.PriorityLevels) == 0 {
+		return DefaultPriorityLevels
+	}
+	return f.PriorityLevels
+}
+
+// PriorityLabel returns the label of a priority, or "" when the priority
+// field is disabled.
+func (f Format) PriorityLabel(priority string) string {
+	if f.PriorityPrefix == "" || priority == "" {
+		return ""
+	}
+	return f.PriorityPrefix + priority
+}
+
+// Priority returns the priority of iss, or "" if it has none.
+func (f Format) Priority(iss Issue) string {
+	_, priority := f.splitPriority(iss.Labels)
+	return priority
+}
+
+// PriorityRank returns the position of priority among the levels, 0 being
+// the most urgent, or -1 for unknown priorities.
+func (f Format) PriorityRank(priority string) int {
+	for i, level := range f.Levels() {
+		if strings.EqualFold(level, priority) {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsPriorityLabel reports whether label carries a priority.
+func (f Format) IsPriorityLabel(label string) bool {
+	return f.PriorityPrefix != "" && len(label) > len(f.PriorityPrefix) &&
+		strings.EqualFold(label[:len(f.PriorityPrefix)], f.PriorityPrefix)
+}
+
+// WithPriority returns labels with the priority label replaced by the one
+// for priority. An empty priority removes it.
+func (f Format) WithPriority(labels []string, priority string) []string {
+	if f.PriorityPrefix == "" {
+		return labels
+	}
+	rest, _ := f.splitPriority(labels)
+	if priority != "" {
+		rest = append(rest, f.PriorityLabel(priority))
+	}
+	return rest
+}
+
+// splitPriority returns labels without priority labels, and the priority
+// of the first one.
+func (f Format) splitPriority(labels []string) ([]string, string) {
+	if f.PriorityPrefix == "" {
+		return labels, ""
+	}
+	var rest []string
+	priority := ""
+	for _, label := range labels {
+		if !f.IsPriorityLabel(label) {
+			rest = append(rest, label)
+			continue
+		}
+		if priority == "" {
+			priority = label[len(f.PriorityPrefix):]
+		}
+	}
+	return rest, priority
+}
@@ -0,0 +1,128 @@
+package issue
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported recurrence intervals.
+const (
+	EveryDay   = "daily"
+	EveryWeek  = "weekly"
+	EveryMonth = "monthly"
+)
+
+// Recurring is a definition in .issues/recurring/ from which a new local
+// issue is created once per period. The file uses the issue format plus an
+// "every" interval and an optional "start" date that anchors the weekday or
+// day of the month.
+type Recurring struct {
+	Name     string // File name without extension; the dedupe key
+	Every    string
+	Start    *time.Time
+	Template Issue
+}
+
+type recurringFrontMatter struct {
+	Every string `yaml:"every"`
+	Start string `yaml:"start,omitempty"`
+}
+
+// ParseRecurringFile reads a recurring issue definition from disk.
+func ParseRecurringFile(path string) (Recurring, error) {
+	data, err := osReadFile(path)
+	if err != nil {
+		return Recurring{}, err
+	}
+	r, err := ParseRecurring(data)
+	if err != nil {
+		return Recurring{}, err
+	}
+	r.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return r, nil
+}
+
+// ParseRecurring parses the contents of a recurring issue definition.
+func ParseRecurring(data []byte) (Recurring, error) {
+	frontMatter, _, err := splitFrontMatter(data)
+	if err != nil {
+		return Recurring{}, err
+	}
+	var fm recurringFrontMatter
+	if err := yaml.Unmarshal(frontMatter, &fm); err != nil {
+		return Recurring{}, err
+	}
+	template, err := Parse(data)
+	if err != nil {
+		return Recurring{}, err
+	}
+	r := Recurring{Every: strings.ToLower(strings.TrimSpace(fm.Every)), Template: template}
+	switch r.Every {
+	case EveryDay, EveryWeek, EveryMonth:
+	case "":
+		return Recurring{}, fmt.Errorf("missing every (daily, weekly or monthly)")
+	default:
+		return Recurring{}, fmt.Errorf("unsupported every %q (use daily, weekly or monthly)", fm.Every)
+	}
+	if fm.Start != "" {
+		start, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(fm.Start), time.Local)
+		if err != nil {
+			return Recurring{}, fmt.Errorf("invalid start %q: %w", fm.Start, err)
+		}
+		r.Start = &start
+	}
+	return r, nil
+}
+
+// PeriodStart returns the start of the period containing now. Weekly
+// periods begin on the weekday of Start (Monday by default) and monthly
+// periods on its day of the month (the 1st by default), clamped to the
+// length of the month.
+func (r Recurring) PeriodStart(now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch r.Every {
+	case EveryWeek:
+		weekday := time.Monday
+		if r.Start != nil {
+			weekday = r.Start.Weekday()
+		}
+		offset := (int(day.Weekday()) - int(weekday) + 7) % 7
+		return day.AddDate(0, 0, -offset)
+	case EveryMonth:
+		anchor := 1
+		if r.Start != nil {
+			anchor = r.Start.Day()
+		}
+		start := monthDay(day.Year(), day.Month(), anchor, day.Location())
+		if start.After(day) {
+			prev := day.AddDate(0, 0, -day.Day()) // last day of previous month
+			start = monthDay(prev.Year(), prev.Month(), anchor, day.Location())
+		}
+		return start
+	default:
+		return day
+	}
+}
+
+// monthDay returns the given day of a month, clamped to its last day.
+func monthDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// Due reports whether a new issue should be created at now, given the start
+// of the last period an issue was created for (empty if never).
+func (r Recurring) Due(now time.Time, lastPeriod string) (period string, due bool) {
+	if r.Start != nil && now.Before(*r.Start) {
+		return "", false
+	}
+	period = r.PeriodStart(now).Format("2006-01-02")
+	return period, period > lastPeriod
+}
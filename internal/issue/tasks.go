@@ -0,0 +1,95 @@
+package issue
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Task is a Markdown task-list item ("- [ ] text") in an issue body.
+type Task struct {
+	Line    int    // zero-based line index in the body
+	Prefix  string // everything before the text, e.g. "  - [ ] "
+	Checked bool
+	Text    string
+}
+
+var taskPattern = regexp.MustCompile(`^(\s*[-*+]\s+\[([ xX])\]\s+)(.*)$`)
+
+// ParseTasks returns the task-list items in body, skipping fenced code blocks.
+func ParseTasks(body string) []Task {
+	var tasks []Task
+	inFence := false
+	for i, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		match := taskPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		text := strings.TrimSpace(match[3])
+		if text == "" {
+			continue
+		}
+		tasks = append(tasks, Task{
+			Line:    i,
+			Prefix:  match[1],
+			Checked: match[2] != " ",
+			Text:    text,
+		})
+	}
+	return tasks
+}
+
+// issueRefPattern matches task text that is only an issue reference, such as
+// "#123" or "#T1a2b3c4d".
+var issueRefPattern = regexp.MustCompile(`^#(\d+|T[a-zA-Z0-9]+)$`)
+
+// IsIssueReference reports whether the task text is just a reference to
+// another issue, as produced when a task is converted into an issue.
+func (t Task) IsIssueReference() bool {
+	return issueRefPattern.MatchString(t.Text)
+}
+
+// ReplaceTaskLines returns body with the given lines (keyed by zero-based
+// line index) replaced.
+func ReplaceTaskLines(body string, replacements map[int]string) string {
+	lines := strings.Split(body, "\n")
+	for idx, line := range replacements {
+		if idx >= 0 && idx < len(lines) {
+			lines[idx] = line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TaskProgress returns the number of checked tasks and the total number of
+// tasks in body.
+func TaskProgress(body string) (done, total int) {
+	for _, task := range ParseTasks(body) {
+		total++
+		if task.Checked {
+			done++
+		}
+	}
+	return done, total
+}
+
+// SetTaskChecked returns body with the given task checked or unchecked.
+func SetTaskChecked(body string, task Task, checked bool) string {
+	mark := " "
+	if checked {
+		mark = "x"
+	}
+	open := strings.Index(task.Prefix, "[")
+	if open == -1 {
+		return body
+	}
+	prefix := task.Prefix[:open+1] + mark + task.Prefix[open+2:]
+	return ReplaceTaskLines(body, map[int]string{task.Line: prefix + task.Text})
+}
@@ -0,0 +1,60 @@
+package issue
+
+import (
+	"regexp"
+	"strings"
+)
+
+// vaultTagInvalid matches runs of characters Obsidian does not allow in tags.
+var vaultTagInvalid = regexp.MustCompile(`[^\p{L}\p{N}_/-]+`)
+
+// VaultTags maps labels to tag names: "good first issue" becomes
+// "good-first-issue".
+func VaultTags(labels []string) []string {
+	var tags []string
+	seen := map[string]struct{}{}
+	for _, label := range sortedStrings(labels) {
+		tag := strings.Trim(vaultTagInvalid.ReplaceAllString(label, "-"), "-/")
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// VaultAliases returns the names an issue can be linked by: its number
+// (which makes [[123]] resolve to the file) and its title.
+func VaultAliases(issue Issue) []string {
+	var aliases []string
+	if issue.Number != "" {
+		aliases = append(aliases, issue.Number.String())
+	}
+	if title := strings.TrimSpace(issue.Title); title != "" {
+		aliases = append(aliases, title)
+	}
+	return aliases
+}
+
+// VaultLinks turns references in body into [[123]] links, which resolve
+// through the aliases. Fenced code blocks are left alone.
+func VaultLinks(body string) string {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		line = wikiLinkPattern.ReplaceAllString(line, "[[$1]]")
+		lines[i] = plainRefPattern.ReplaceAllString(line, "$1[[$2]]")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,78 @@
+package issue
+
+import (
+	"regexp"
+	"strings"
+)
+
+// wikiLinkPattern matches wiki-style links between issue files such as
+// [[#123]], [[T1a2b3c]] or [[#T1a2b3c]].
+var wikiLinkPattern = regexp.MustCompile(`\[\[#?(\d+|T[a-zA-Z0-9]+)\]\]`)
+
+// plainRefPattern matches plain same-repository references like #123 that
+// are not already part of a wiki link, a URL or an HTML entity.
+var plainRefPattern = regexp.MustCompile(`(^|[^\w/&\[])#(\d+|T[a-zA-Z0-9]+)\b`)
+
+// WikiLinks returns the distinct issue numbers linked wiki-style from body,
+// in order of first appearance.
+func WikiLinks(body string) []string {
+	var refs []string
+	seen := map[string]struct{}{}
+	for _, m := range wikiLinkPattern.FindAllStringSubmatch(body, -1) {
+		if _, ok := seen[m[1]]; ok {
+			continue
+		}
+		seen[m[1]] = struct{}{}
+		refs = append(refs, m[1])
+	}
+	return refs
+}
+
+// ExpandWikiLinks rewrites wiki links to plain #123 references, which is
+// what the remote understands.
+func ExpandWikiLinks(body string) string {
+	if !strings.Contains(body, "[[") {
+		return body
+	}
+	return wikiLinkPattern.ReplaceAllString(body, "#$1")
+}
+
+// WithWikiLinks turns plain references in body back into wiki links for
+// every issue that local links wiki-style. It undoes ExpandWikiLinks when a
+// pull rewrites a file.
+func WithWikiLinks(body, local string) string {
+	refs := WikiLinks(local)
+	if len(refs) == 0 {
+		return body
+	}
+	linked := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		linked[ref] = struct{}{}
+	}
+	matches := plainRefPattern.FindAllStringSubmatchIndex(body, -1)
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		ref := body[m[4]:m[5]]
+		if _, ok := linked[ref]; !ok {
+			continue
+		}
+		b.WriteString(body[last:m[3]])
+		b.WriteString("[[#" + ref + "]]")
+		last = m[1]
+	}
+	b.WriteString(body[last:])
+	return b.String()
+}
+
+// PublicBody returns body as it is sent to the remote: local notes and
+// edit comment blocks are stripped and wiki links expanded.
+func PublicBody(body string) string {
+	return ExpandWikiLinks(StripLocalNotes(StripCommentContext(body)))
+}
+
+// KeepLocalSyntax carries the local-only parts of local (wiki links and
+// local notes) over to body, a freshly pulled remote body.
+func KeepLocalSyntax(body, local string) string {
+	return WithLocalNotes(WithWikiLinks(body, local), LocalNotes(local))
+}
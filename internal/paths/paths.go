@@ -3,43 +3,214 @@ package paths
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
+	"github.com/mitsuhiko/gh-issue-sync/internal/store"
 )
 
 const EnvIssuesDir = "GH_ISSUE_SYNC_DIR"
 
 const (
-	IssuesDirName      = ".issues"
-	SyncDirName        = ".sync"
-	OriginalsDirName   = "originals"
-	OpenDirName        = "open"
-	ClosedDirName      = "closed"
-	ConfigFileName     = "config.json"
-	LabelsFileName     = "labels.json"
-	MilestonesFileName = "milestones.json"
-	IssueTypesFileName = "issue_types.json"
-	ProjectsFileName   = "projects.json"
+	IssuesDirName       = ".issues"
+	SyncDirName         = ".sync"
+	OriginalsDirName    = "originals"
+	BlobsDirName        = "blobs"
+	TimelineDirName     = "timeline"
+	CommentsDirName     = "comments"
+	SnapshotsDirName    = "snapshots"
+	OpenDirName         = "open"
+	ClosedDirName       = "closed"
+	AllDirName          = "all"
+	ByMilestoneDirName  = "by-milestone"
+	NoMilestoneDirName  = "no-milestone"
+	ByLabelDirName      = "by-label"
+	ByAssigneeDirName   = "by-assignee"
+	IndexDirName        = "index"
+	MilestonesDirName   = "milestones"
+	DraftsDirName       = "drafts"
+	TemplatesDirName    = "templates"
+	RecurringDirName    = "recurring"
+	ConflictsDirName    = "conflicts"
+	RecoveryDirName     = "drafts"
+	ConfigFileName      = "config.json"
+	LabelsFileName      = "labels.json"
+	MilestonesFileName  = "milestones.json"
+	IssueTypesFileName  = "issue_types.json"
+	ProjectsFileName    = "projects.json"
+	TeamsFileName       = "teams.json"
+	AutolinksFileName   = "autolinks.json"
+	RecurringFileName   = "recurring.json"
+	LabelMergesFileName = "label_merges.json"
+	LocalIDsFileName    = "local_ids.json"
+	OrgFileName         = "org.json"
+	RepoFileName        = "repo.json"
+	IndexFileName       = "index.json"
+	LastPullFileName    = "last_pull.json"
+	CheckpointFileName  = "pull_checkpoint.jsonl"
+	StateFileName       = "state.json"
+	AuditFileName       = "audit.jsonl"
+	LayoutFileName      = "layout"
+	ViewsFileName       = "views"
+	GitignoreFileName   = ".gitignore"
 )
 
 type Paths struct {
-	Root           string
-	IssuesDir      string
-	SyncDir        string
-	OriginalsDir   string
-	OpenDir        string
-	ClosedDir      string
-	ConfigPath     string
-	LabelsPath     string
-	MilestonesPath string
-	IssueTypesPath string
-	ProjectsPath   string
+	Root            string
+	IssuesDir       string
+	SyncDir         string
+	OriginalsDir    string
+	BlobsDir        string
+	TimelineDir     string
+	CommentsDir     string
+	SnapshotsDir    string
+	OpenDir         string
+	ClosedDir       string
+	MilestonesDir   string
+	DraftsDir       string
+	TemplatesDir    string
+	RecurringDir    string
+	ConflictsDir    string
+	RecoveryDir     string
+	ConfigPath      string
+	LabelsPath      string
+	MilestonesPath  string
+	IssueTypesPath  string
+	ProjectsPath    string
+	TeamsPath       string
+	AutolinksPath   string
+	RecurringPath   string
+	LabelMergesPath string
+	LocalIDsPath    string
+	RepoPath        string
+	IndexPath       string
+	LastPullPath    string
+	CheckpointPath  string
+	StatePath       string
+	AuditPath       string
+	LayoutPath      string
+	ViewsPath       string
+	GitignorePath   string
+	NewIssuePath    string
+	// Layout is how issue files are arranged, see WithLayout.
+	Layout string
+	// Views are the label views configured in config.json, see WithViews.
+	Views []View
+	// OpenStore returns the store holding the issue files. Without it
+	// they are plain files in IssuesDir.
+	OpenStore func() (store.Store, error)
+	// HashedOriginals keeps the bodies of originals as blobs, as
+	// storage.originals in config.json asks.
+	HashedOriginals bool
+	// Format is how the issue files are written and read, as the priority
+	// and vault settings in config.json ask.
+	Format issue.Format
+}
+
+// Layouts of the issue files, selected by the layout setting in
+// config.json.
+const (
+	// LayoutState keeps issues in open/ and closed/ by state. It is the
+	// default.
+	LayoutState = "state"
+	// LayoutFlat keeps all issues in all/, with the state only in the
+	// front matter, so closing an issue doesn't rename its file.
+	LayoutFlat = "flat"
+	// LayoutMilestone keeps issues in by-milestone/<milestone>/, with the
+	// state only in the front matter.
+	LayoutMilestone = "milestone"
+)
+
+// View is a subdirectory of the issues directory that holds the issues
+// with a label, e.g. .issues/frontend for label:frontend. Its issues are
+// arranged by the same layout as those of the issues directory.
+type View struct {
+	Name  string
+	Label string
+	Dir   string
+}
+
+// StateDir is a directory holding issues. State is the state of all of
+// them, or empty when the front matter records it. The issues of a nested
+// directory are in its subdirectories instead.
+type StateDir struct {
+	Path   string
+	State  string
+	Nested bool
+}
+
+// ReservedName reports whether name is used by the layout of the issues
+// directory and can't name a view.
+func ReservedName(name string) bool {
+	switch name {
+	case SyncDirName, OpenDirName, ClosedDirName, AllDirName, ByMilestoneDirName, ByLabelDirName, ByAssigneeDirName, IndexDirName, MilestonesDirName, DraftsDirName, TemplatesDirName, RecurringDirName:
+		return true
+	}
+	return false
+}
+
+// ValidLayout reports whether layout names a known layout. The empty
+// string stands for LayoutState.
+func ValidLayout(layout string) bool {
+	switch layout {
+	case "", LayoutState, LayoutFlat, LayoutMilestone:
+		return true
+	}
+	return false
+}
+
+// WithLayout returns p arranging issue files by layout.
+func (p Paths) WithLayout(layout string) Paths {
+	p.Layout = layout
+	return p
+}
+
+// WithViews returns p with the given label views, filling in their
+// directories from their names.
+func (p Paths) WithViews(views []View) Paths {
+	p.Views = nil
+	for _, view := range views {
+		view.Dir = filepath.Join(p.IssuesDir, view.Name)
+		p.Views = append(p.Views, view)
+	}
+	return p
+}
+
+// StateDirs returns the directories holding issues in the layout of p,
+// those of the issues directory first and then those of every view.
+func (p Paths) StateDirs() []StateDir {
+	dirs := LayoutDirs(p.IssuesDir, p.Layout)
+	for _, view := range p.Views {
+		dirs = append(dirs, LayoutDirs(view.Dir, p.Layout)...)
+	}
+	return dirs
+}
+
+// LayoutDirs returns the directories holding issues in base, the issues
+// directory or the directory of a view, when arranged by layout.
+func LayoutDirs(base, layout string) []StateDir {
+	switch layout {
+	case LayoutFlat:
+		return []StateDir{{Path: filepath.Join(base, AllDirName)}}
+	case LayoutMilestone:
+		return []StateDir{{Path: filepath.Join(base, ByMilestoneDirName), Nested: true}}
+	}
+	return []StateDir{{Path: filepath.Join(base, OpenDirName), State: "open"}, {Path: filepath.Join(base, ClosedDirName), State: "closed"}}
 }
 
 func New(root string) Paths {
-	issuesDir := filepath.Join(root, IssuesDirName)
+	return NewAt(root, filepath.Join(root, IssuesDirName))
+}
+
+// NewAt returns the layout of an issues directory that is not root/.issues,
+// such as the per-repository directories in org mode. root is still used to
+// resolve and display relative paths.
+func NewAt(root, issuesDir string) Paths {
 	syncDir := filepath.Join(issuesDir, SyncDirName)
 	originalsDir := filepath.Join(syncDir, OriginalsDirName)
 	openDir := filepath.Join(issuesDir, OpenDirName)
 	closedDir := filepath.Join(issuesDir, ClosedDirName)
+	milestonesDir := filepath.Join(issuesDir, MilestonesDirName)
+	draftsDir := filepath.Join(issuesDir, DraftsDirName)
 	configPath := filepath.Join(syncDir, ConfigFileName)
 	labelsPath := filepath.Join(syncDir, LabelsFileName)
 	milestonesPath := filepath.Join(syncDir, MilestonesFileName)
@@ -48,17 +219,42 @@ func New(root string) Paths {
 	projectsPath := filepath.Join(syncDir, ProjectsFileName)
 
 	return Paths{
-		Root:           root,
-		IssuesDir:      issuesDir,
-		SyncDir:        syncDir,
-		OriginalsDir:   originalsDir,
-		OpenDir:        openDir,
-		ClosedDir:      closedDir,
-		ConfigPath:     configPath,
-		LabelsPath:     labelsPath,
-		MilestonesPath: milestonesPath,
-		IssueTypesPath: issueTypesPath,
-		ProjectsPath:   projectsPath,
+		Root:            root,
+		IssuesDir:       issuesDir,
+		SyncDir:         syncDir,
+		OriginalsDir:    originalsDir,
+		BlobsDir:        filepath.Join(syncDir, BlobsDirName),
+		TimelineDir:     filepath.Join(syncDir, TimelineDirName),
+		CommentsDir:     filepath.Join(syncDir, CommentsDirName),
+		SnapshotsDir:    filepath.Join(syncDir, SnapshotsDirName),
+		OpenDir:         openDir,
+		ClosedDir:       closedDir,
+		MilestonesDir:   milestonesDir,
+		DraftsDir:       draftsDir,
+		TemplatesDir:    filepath.Join(issuesDir, TemplatesDirName),
+		RecurringDir:    filepath.Join(issuesDir, RecurringDirName),
+		ConflictsDir:    filepath.Join(syncDir, ConflictsDirName),
+		RecoveryDir:     filepath.Join(syncDir, RecoveryDirName),
+		ConfigPath:      configPath,
+		LabelsPath:      labelsPath,
+		MilestonesPath:  milestonesPath,
+		IssueTypesPath:  issueTypesPath,
+		ProjectsPath:    projectsPath,
+		TeamsPath:       filepath.Join(syncDir, TeamsFileName),
+		AutolinksPath:   filepath.Join(syncDir, AutolinksFileName),
+		RecurringPath:   filepath.Join(syncDir, RecurringFileName),
+		LabelMergesPath: filepath.Join(syncDir, LabelMergesFileName),
+		LocalIDsPath:    filepath.Join(syncDir, LocalIDsFileName),
+		RepoPath:        filepath.Join(syncDir, RepoFileName),
+		IndexPath:       filepath.Join(syncDir, IndexFileName),
+		LastPullPath:    filepath.Join(syncDir, LastPullFileName),
+		CheckpointPath:  filepath.Join(syncDir, CheckpointFileName),
+		StatePath:       filepath.Join(syncDir, StateFileName),
+		AuditPath:       filepath.Join(syncDir, AuditFileName),
+		LayoutPath:      filepath.Join(syncDir, LayoutFileName),
+		ViewsPath:       filepath.Join(syncDir, ViewsFileName),
+		GitignorePath:   filepath.Join(syncDir, GitignoreFileName),
+		NewIssuePath:    filepath.Join(syncDir, RecoveryDirName, "new.md"),
 	}
 }
 
@@ -71,6 +267,13 @@ func (p Paths) EnsureLayout() error {
 	return nil
 }
 
+// OrgConfigPath returns the path of the org-mode configuration in root's
+// .issues directory. It only exists when the directory mirrors a whole
+// organization, with one issues directory per repository.
+func OrgConfigPath(root string) string {
+	return filepath.Join(root, IssuesDirName, OrgFileName)
+}
+
 // FindIssuesDir searches for an existing .issues directory.
 // It first checks the GH_ISSUE_SYNC_DIR environment variable,
 // then walks upward from startDir until it finds .issues or hits a .git root.
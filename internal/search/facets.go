@@ -0,0 +1,121 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Facet counts the issues per value of a field.
+type Facet struct {
+	Field  string
+	Values []FacetValue // most frequent first
+	// None counts the issues without a value.
+	None int
+}
+
+// FacetValue is a value of a facet and how many issues have it.
+type FacetValue struct {
+	Value string
+	Count int
+}
+
+// facetAliases maps the accepted facet names to the field they count.
+var facetAliases = map[string]string{
+	"label": "labels", "labels": "labels",
+	"assignee": "assignees", "assignees": "assignees",
+	"project": "projects", "projects": "projects",
+	"milestone": "milestone", "author": "author", "state": "state",
+	"type": "type", "priority": "priority",
+}
+
+// ParseFacets parses a comma separated list of facet names. Besides
+// labels, assignees, projects, milestone, author, state, type and
+// priority, any custom front matter field can be a facet.
+func ParseFacets(list string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid facet list %q", list)
+		}
+		if field, ok := facetAliases[strings.ToLower(name)]; ok {
+			name = field
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// Facets counts issues per value of each field. Issues count once for
+// every value of a list field such as labels, so the counts of a facet
+// can add up to more than the number of issues. Values are compared
+// ignoring case and shown as first seen.
+func Facets(issues []IssueData, fields []string) []Facet {
+	facets := make([]Facet, 0, len(fields))
+	for _, field := range fields {
+		facet := Facet{Field: field}
+		index := map[string]int{}
+		for _, iss := range issues {
+			values := iss.facetValues(field)
+			if len(values) == 0 {
+				facet.None++
+				continue
+			}
+			seen := map[string]bool{}
+			for _, value := range values {
+				key := strings.ToLower(value)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if i, ok := index[key]; ok {
+					facet.Values[i].Count++
+					continue
+				}
+				index[key] = len(facet.Values)
+				facet.Values = append(facet.Values, FacetValue{Value: value, Count: 1})
+			}
+		}
+		sort.SliceStable(facet.Values, func(i, j int) bool {
+			a, b := facet.Values[i], facet.Values[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return strings.ToLower(a.Value) < strings.ToLower(b.Value)
+		})
+		facets = append(facets, facet)
+	}
+	return facets
+}
+
+func (d IssueData) facetValues(field string) []string {
+	var values []string
+	switch field {
+	case "labels":
+		values = d.Labels
+	case "assignees":
+		values = d.Assignees
+	case "projects":
+		values = d.Projects
+	case "milestone":
+		values = []string{d.Milestone}
+	case "author":
+		values = []string{d.Author}
+	case "state":
+		values = []string{d.State}
+	case "type":
+		values = []string{d.IssueType}
+	case "priority":
+		values = []string{d.Priority}
+	default:
+		values = []string{d.Fields[field]}
+	}
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -2,8 +2,14 @@
 package search
 
 import (
+	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
 
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
 )
@@ -14,23 +20,41 @@ type Query struct {
 	Text string
 
 	// Qualifiers
-	State       string   // "open" or "closed"
-	Labels      []string // label:X
-	NoLabel     bool     // no:label
-	Assignees   []string // assignee:X
-	NoAssignee  bool     // no:assignee
-	Authors     []string // author:X
-	Milestones  []string // milestone:X
-	NoMilestone bool     // no:milestone
-	Mentions    []string // mentions:X
-	Types       []string // type:X
-	NoType      bool     // no:type
-	Projects    []string // project:X
-	NoProject   bool     // no:project
+	State       string           // "open" or "closed"
+	Labels      []string         // label:X
+	NoLabel     bool             // no:label
+	Assignees   []string         // assignee:X
+	NoAssignee  bool             // no:assignee
+	Authors     []string         // author:X
+	Milestones  []string         // milestone:X
+	NoMilestone bool             // no:milestone
+	Mentions    []string         // mentions:X
+	Types       []string         // type:X
+	NoType      bool             // no:type
+	Projects    []string         // project:X
+	NoProject   bool             // no:project
+	Priorities  []string         // priority:X
+	NoPriority  bool             // no:priority
+	Fuzzy       []string         // fuzzy:X or ~X, matched loosely against the title
+	Regexes     []*regexp.Regexp // regex:X, matched against title and body
+
+	// Err is the first problem with the query, such as an invalid regex.
+	Err error
+	// regexTime is shared by copies of the query, see RegexTimeout.
+	regexTime *atomic.Int64
 
 	// Sort
-	SortField string // "created", "updated", "comments" (default: "created")
+	SortField string // "created", "updated", "comments", "reactions", "priority" (default: "created")
 	SortAsc   bool   // true for ascending, false for descending (default: false = desc)
+	// SortKeys is a multi-key sort from ParseSortExpr. It takes precedence
+	// over SortField and SortAsc.
+	SortKeys []SortKey
+}
+
+// SortKey is one key of a sort expression.
+type SortKey struct {
+	Field string
+	Asc   bool
 }
 
 // Parse parses a GitHub-style search query string.
@@ -38,6 +62,8 @@ type Query struct {
 //   - "error no:assignee sort:created-asc"
 //   - "label:bug label:urgent is:open"
 //   - "fix login author:alice"
+//   - "~logn fuzzy:'tokn expiry'"
+//   - `regex:"panic in .*Handler"`
 func Parse(query string) Query {
 	q := Query{
 		SortField: "created",
@@ -48,6 +74,10 @@ func Parse(query string) Query {
 	tokens := tokenize(query)
 
 	for _, tok := range tokens {
+		if term, ok := strings.CutPrefix(tok, "~"); ok && term != "" {
+			q.Fuzzy = append(q.Fuzzy, strings.Trim(term, "\"'"))
+			continue
+		}
 		// Handle qualifier:value syntax
 		if idx := strings.Index(tok, ":"); idx > 0 {
 			qualifier := strings.ToLower(tok[:idx])
@@ -85,6 +115,12 @@ func Parse(query string) Query {
 				q.Types = append(q.Types, value)
 			case "project":
 				q.Projects = append(q.Projects, value)
+			case "priority":
+				q.Priorities = append(q.Priorities, value)
+			case "fuzzy":
+				q.Fuzzy = append(q.Fuzzy, value)
+			case "regex":
+				q.addRegex(value)
 			case "no":
 				switch strings.ToLower(value) {
 				case "label":
@@ -97,6 +133,8 @@ func Parse(query string) Query {
 					q.NoType = true
 				case "project":
 					q.NoProject = true
+				case "priority":
+					q.NoPriority = true
 				}
 			case "sort":
 				parseSortValue(&q, value)
@@ -116,26 +154,72 @@ func Parse(query string) Query {
 
 // parseSortValue parses sort values like "created-asc", "updated-desc", "comments"
 func parseSortValue(q *Query, value string) {
+	if field, asc, ok := ParseSort(value); ok {
+		q.SortField, q.SortAsc = field, asc
+	}
+}
+
+// ParseSort parses a sort value like "created-asc", "reactions-desc" or
+// "comments" into its field and direction. Without a suffix the order is
+// descending.
+func ParseSort(value string) (field string, asc bool, ok bool) {
 	value = strings.ToLower(value)
 
 	// Check for -asc or -desc suffix
 	if strings.HasSuffix(value, "-asc") {
-		q.SortAsc = true
+		asc = true
 		value = strings.TrimSuffix(value, "-asc")
-	} else if strings.HasSuffix(value, "-desc") {
-		q.SortAsc = false
+	} else {
 		value = strings.TrimSuffix(value, "-desc")
 	}
 
 	// Map sort field
 	switch value {
-	case "created":
-		q.SortField = "created"
-	case "updated":
-		q.SortField = "updated"
-	case "comments":
-		q.SortField = "comments"
+	case "created", "updated", "comments", "reactions", "priority":
+		return value, asc, true
 	}
+	return "", false, false
+}
+
+// ParseSortExpr parses a sort expression: comma separated keys, each a
+// field followed by an optional asc or desc, such as "priority desc,
+// updated asc". The "field-asc" form of sort: is accepted too. Besides
+// created, updated, comments, reactions and priority, keys can sort by
+// number, title, state, author, milestone and type, or by any custom
+// front matter field. Keys without a direction sort descending like
+// sort: does.
+func ParseSortExpr(expr string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(expr, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, fmt.Errorf("invalid sort key %q: expected a field and an optional asc or desc", strings.TrimSpace(part))
+		}
+		key := SortKey{Field: words[0]}
+		if field, asc, ok := ParseSort(words[0]); ok {
+			key = SortKey{Field: field, Asc: asc}
+		} else if lower := strings.ToLower(key.Field); sortStringFields[lower] {
+			key.Field = lower
+		}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+				key.Asc = true
+			case "desc":
+				key.Asc = false
+			default:
+				return nil, fmt.Errorf("invalid sort direction %q: expected asc or desc", words[1])
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// sortStringFields are the built-in fields sort expressions compare as
+// text, or as numbers where both values are.
+var sortStringFields = map[string]bool{
+	"number": true, "title": true, "state": true, "author": true, "milestone": true, "type": true,
 }
 
 // tokenize splits the query into tokens, respecting quoted strings
@@ -190,9 +274,17 @@ type IssueData struct {
 	Milestone string
 	IssueType string
 	Projects  []string
+	Priority  string
 	SyncedAt  *int64 // Unix timestamp, nil if not synced
 	CreatedAt *int64 // Unix timestamp from GitHub
 	UpdatedAt *int64 // Unix timestamp from GitHub
+	// PriorityRank is higher for more urgent priorities, nil without one.
+	PriorityRank *int64
+	// Comments and Reactions are the pulled counts, nil for local issues.
+	Comments  *int64
+	Reactions *int64
+	// Fields are the custom front matter fields as text.
+	Fields map[string]string
 }
 
 // Match returns true if the issue matches the query.
@@ -259,6 +351,15 @@ func (q *Query) Match(iss IssueData) bool {
 		}
 	}
 
+	// Priority filters; an issue has one priority, so "priority:P0
+	// priority:P1" matches either
+	if q.NoPriority && iss.Priority != "" {
+		return false
+	}
+	if len(q.Priorities) > 0 && !containsIgnoreCase(q.Priorities, iss.Priority) {
+		return false
+	}
+
 	// Mentions filter (search for @username in body)
 	for _, mention := range q.Mentions {
 		searchMention := "@" + mention
@@ -267,6 +368,20 @@ func (q *Query) Match(iss IssueData) bool {
 		}
 	}
 
+	// Regex search (in title and body)
+	for _, re := range q.Regexes {
+		if !q.matchRegex(re, iss.Title) && !q.matchRegex(re, iss.Body) {
+			return false
+		}
+	}
+
+	// Fuzzy title search
+	for _, term := range q.Fuzzy {
+		if !FuzzyMatch(iss.Title, term) {
+			return false
+		}
+	}
+
 	// Free text search (in title and body)
 	if q.Text != "" {
 		textLower := strings.ToLower(q.Text)
@@ -280,47 +395,254 @@ func (q *Query) Match(iss IssueData) bool {
 	return true
 }
 
-// Sort sorts issues according to the query's sort specification.
+// NeedsBody reports whether matching looks at issue bodies.
+func (q Query) NeedsBody() bool {
+	return q.Text != "" || len(q.Mentions) > 0 || len(q.Regexes) > 0
+}
+
+// MaxRegexLength bounds the length of a regex: pattern.
+const MaxRegexLength = 1000
+
+// RegexTimeout bounds the total time the regex: qualifiers of a query may
+// spend matching. Go regexps run in linear time, so no single issue can
+// hang a search, but a heavy pattern over a large mirror still adds up;
+// once the budget is spent, remaining issues do not match and
+// RegexTimedOut reports it.
+var RegexTimeout = 10 * time.Second
+
+func (q *Query) addRegex(pattern string) {
+	if len(pattern) > MaxRegexLength {
+		if q.Err == nil {
+			q.Err = fmt.Errorf("regex: pattern is longer than %d characters", MaxRegexLength)
+		}
+		return
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		if q.Err == nil {
+			q.Err = fmt.Errorf("invalid regex: %w", err)
+		}
+		return
+	}
+	if q.regexTime == nil {
+		q.regexTime = new(atomic.Int64)
+	}
+	q.Regexes = append(q.Regexes, re)
+}
+
+func (q *Query) matchRegex(re *regexp.Regexp, text string) bool {
+	if q.RegexTimedOut() {
+		return false
+	}
+	start := time.Now()
+	matched := re.MatchString(text)
+	q.regexTime.Add(int64(time.Since(start)))
+	return matched
+}
+
+// RegexTimedOut reports whether the regex: qualifiers used up RegexTimeout,
+// so results may be missing.
+func (q Query) RegexTimedOut() bool {
+	return q.regexTime != nil && time.Duration(q.regexTime.Load()) > RegexTimeout
+}
+
+// Sort sorts issues according to the query's sort specification. Issues
+// without a value for a key, like local issues without timestamps, go to
+// the end in either direction. Sorting by reactions weighs the
+// most-demanded issues first and breaks ties by the number of comments.
 func (q *Query) Sort(issues []IssueData) {
+	keys := q.SortKeys
+	if len(keys) == 0 {
+		keys = []SortKey{{Field: q.SortField, Asc: q.SortAsc}}
+	}
 	sort.SliceStable(issues, func(i, j int) bool {
-		// Select timestamp based on sort field
-		var ti, tj *int64
-		switch q.SortField {
-		case "created":
-			ti, tj = issues[i].CreatedAt, issues[j].CreatedAt
-		case "updated":
-			ti, tj = issues[i].UpdatedAt, issues[j].UpdatedAt
-		default:
-			// Default to created for unknown sort fields
-			ti, tj = issues[i].CreatedAt, issues[j].CreatedAt
+		for _, key := range keys {
+			cmp := compareSortKey(issues[i], issues[j], key)
+			if cmp == 0 && key.Field == "reactions" {
+				cmp = compareSortKey(issues[i], issues[j], SortKey{Field: "comments", Asc: key.Asc})
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
 		}
+		return false
+	})
+}
 
-		// Issues without timestamps (local issues) always go to the end
-		if ti == nil && tj == nil {
-			return false
+// compareSortKey orders a and b by key, putting missing values last.
+func compareSortKey(a, b IssueData, key SortKey) int {
+	va, oka := a.sortValue(key.Field)
+	vb, okb := b.sortValue(key.Field)
+	switch {
+	case !oka && !okb:
+		return 0
+	case !oka:
+		return 1
+	case !okb:
+		return -1
+	}
+	cmp := va.compare(vb)
+	if !key.Asc {
+		cmp = -cmp
+	}
+	return cmp
+}
+
+// sortValue is a number or, if the value is not numeric, text.
+type sortValue struct {
+	num     float64
+	text    string
+	numeric bool
+}
+
+func numberValue(n *int64) (sortValue, bool) {
+	if n == nil {
+		return sortValue{}, false
+	}
+	return sortValue{num: float64(*n), numeric: true}, true
+}
+
+func textValue(text string) (sortValue, bool) {
+	if text == "" {
+		return sortValue{}, false
+	}
+	if num, err := strconv.ParseFloat(text, 64); err == nil {
+		return sortValue{num: num, text: text, numeric: true}, true
+	}
+	return sortValue{text: strings.ToLower(text)}, true
+}
+
+func (v sortValue) compare(other sortValue) int {
+	if v.numeric && other.numeric {
+		switch {
+		case v.num < other.num:
+			return -1
+		case v.num > other.num:
+			return 1
 		}
-		if ti == nil {
-			return false // i goes after j
+		return 0
+	}
+	return strings.Compare(strings.ToLower(v.text), strings.ToLower(other.text))
+}
+
+func (d IssueData) sortValue(field string) (sortValue, bool) {
+	switch field {
+	case "", "created":
+		return numberValue(d.CreatedAt)
+	case "updated":
+		return numberValue(d.UpdatedAt)
+	case "priority":
+		return numberValue(d.PriorityRank)
+	case "comments":
+		return numberValue(d.Comments)
+	case "reactions":
+		return numberValue(d.Reactions)
+	case "number":
+		// Local issues have no number yet
+		if d.Number.IsLocal() {
+			return sortValue{}, false
 		}
-		if tj == nil {
-			return true // i goes before j
+		return textValue(d.Number.String())
+	case "title":
+		return textValue(d.Title)
+	case "state":
+		return textValue(d.State)
+	case "author":
+		return textValue(d.Author)
+	case "milestone":
+		return textValue(d.Milestone)
+	case "type":
+		return textValue(d.IssueType)
+	}
+	return textValue(d.Fields[field])
+}
+
+// FuzzyMatch reports whether term loosely matches title, for finding an
+// issue whose name is only half remembered. It matches if the term's
+// characters appear in the title in order ("lgnbug" in "Login bug"), or if
+// every word of the term is within a small edit distance of a word of the
+// title ("tokn expiry" in "Token expiry on refresh"). Longer words allow
+// more typos.
+func FuzzyMatch(title, term string) bool {
+	title, term = strings.ToLower(title), strings.ToLower(strings.TrimSpace(term))
+	if term == "" {
+		return true
+	}
+	if isSubsequence(strings.ReplaceAll(term, " ", ""), title) {
+		return true
+	}
+	titleWords := strings.FieldsFunc(title, isWordSeparator)
+	for _, word := range strings.FieldsFunc(term, isWordSeparator) {
+		allowed := fuzzyTypos(word)
+		found := false
+		for _, candidate := range titleWords {
+			if editDistance([]rune(word), []rune(candidate)) <= allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
 		}
+	}
+	return true
+}
 
-		// Both have timestamps, compare
-		var cmp int
-		if *ti < *tj {
-			cmp = -1
-		} else if *ti > *tj {
-			cmp = 1
-		} else {
-			cmp = 0
+// fuzzyTypos is how many edits a fuzzy term word may be away from a title
+// word.
+func fuzzyTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// isSubsequence reports whether the characters of sub appear in s in order.
+func isSubsequence(sub, s string) bool {
+	rest := []rune(sub)
+	for _, r := range s {
+		if len(rest) == 0 {
+			break
 		}
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
 
-		if q.SortAsc {
-			return cmp < 0
+// editDistance is the Levenshtein distance counting a swap of two adjacent
+// characters as a single edit.
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
 		}
-		return cmp > 0
-	})
+	}
+	return d[len(a)][len(b)]
 }
 
 func containsIgnoreCase(slice []string, target string) bool {
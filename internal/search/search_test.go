@@ -1,7 +1,9 @@
 package search
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mitsuhiko/gh-issue-sync/internal/issue"
 )
@@ -410,6 +412,159 @@ func TestSort(t *testing.T) {
 	})
 }
 
+func TestPriority(t *testing.T) {
+	high, low := int64(4), int64(2)
+	issues := []IssueData{
+		{Number: "1", Priority: "P2", PriorityRank: &low},
+		{Number: "2"},
+		{Number: "3", Priority: "P0", PriorityRank: &high},
+	}
+
+	q := Parse("priority:p0 priority:P2 sort:priority")
+	if !q.Match(issues[0]) || q.Match(issues[1]) || !q.Match(issues[2]) {
+		t.Fatalf("priority qualifier should match P0 or P2")
+	}
+	if q := Parse("no:priority"); !q.Match(issues[1]) || q.Match(issues[0]) {
+		t.Fatalf("no:priority should only match issues without one")
+	}
+
+	sorted := append([]IssueData(nil), issues...)
+	q.Sort(sorted)
+	if sorted[0].Number != "3" || sorted[1].Number != "1" || sorted[2].Number != "2" {
+		t.Errorf("unexpected order: %v %v %v", sorted[0].Number, sorted[1].Number, sorted[2].Number)
+	}
+}
+
+func TestSortReactions(t *testing.T) {
+	count := func(n int64) *int64 { return &n }
+	issues := []IssueData{
+		{Number: "1", Reactions: count(3), Comments: count(1)},
+		{Number: "T1"},
+		{Number: "2", Reactions: count(10), Comments: count(0)},
+		{Number: "3", Reactions: count(3), Comments: count(8)},
+	}
+
+	field, asc, ok := ParseSort("reactions-desc")
+	if !ok || field != "reactions" || asc {
+		t.Fatalf("unexpected sort: %q %v %v", field, asc, ok)
+	}
+	if _, _, ok := ParseSort("votes"); ok {
+		t.Fatal("expected an unknown sort field to be rejected")
+	}
+
+	q := Parse("sort:reactions")
+	q.Sort(issues)
+	// Ties in reactions go to the more discussed issue
+	if issues[0].Number != "2" || issues[1].Number != "3" || issues[2].Number != "1" || issues[3].Number != "T1" {
+		t.Errorf("unexpected order: %v %v %v %v", issues[0].Number, issues[1].Number, issues[2].Number, issues[3].Number)
+	}
+
+	q = Parse("sort:comments-desc")
+	q.Sort(issues)
+	if issues[0].Number != "3" || issues[1].Number != "1" || issues[2].Number != "2" {
+		t.Errorf("unexpected order: %v %v %v", issues[0].Number, issues[1].Number, issues[2].Number)
+	}
+}
+
+func TestSortExpr(t *testing.T) {
+	keys, err := ParseSortExpr("priority desc, updated asc, Effort, reactions-asc")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []SortKey{{"priority", false}, {"updated", true}, {"Effort", false}, {"reactions", true}}
+	if len(keys) != len(want) {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("unexpected keys: %+v", keys)
+		}
+	}
+	for _, bad := range []string{"", "priority up", "title asc extra", "updated,,created"} {
+		if _, err := ParseSortExpr(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+
+	high, low := int64(4), int64(2)
+	u1, u2, u3 := int64(100), int64(200), int64(300)
+	issues := []IssueData{
+		{Number: "1", PriorityRank: &low, UpdatedAt: &u1, Fields: map[string]string{"effort": "10"}},
+		{Number: "2", PriorityRank: &high, UpdatedAt: &u3, Fields: map[string]string{"effort": "2"}},
+		{Number: "3", PriorityRank: &high, UpdatedAt: &u2},
+		{Number: "T1"},
+	}
+	q := Query{SortKeys: []SortKey{{Field: "priority"}, {Field: "updated", Asc: true}}}
+	q.Sort(issues)
+	if issues[0].Number != "3" || issues[1].Number != "2" || issues[2].Number != "1" || issues[3].Number != "T1" {
+		t.Errorf("unexpected order: %v %v %v %v", issues[0].Number, issues[1].Number, issues[2].Number, issues[3].Number)
+	}
+
+	// Custom fields compare numerically; issues without them go last
+	q = Query{SortKeys: []SortKey{{Field: "effort", Asc: true}}}
+	q.Sort(issues)
+	if issues[0].Number != "2" || issues[1].Number != "1" {
+		t.Errorf("unexpected order: %v %v", issues[0].Number, issues[1].Number)
+	}
+}
+
+func TestFuzzy(t *testing.T) {
+	q := Parse(`~logn fuzzy:"tokn expiry" label:bug`)
+	if !slicesEqual(q.Fuzzy, []string{"logn", "tokn expiry"}) || q.Text != "" {
+		t.Fatalf("unexpected query: %+v", q)
+	}
+
+	for _, tc := range []struct {
+		title, term string
+		want        bool
+	}{
+		{"Login fails after token expiry", "logn", true},
+		{"Login fails after token expiry", "tokn expiry", true},
+		{"Login fails after token expiry", "lgnfails", true},
+		{"Login fails after token expiry", "expiry tokne", true},
+		{"Login fails after token expiry", "logout", false},
+		{"Crash on start", "memory", false},
+		{"Crash on start", "crahs", true},
+	} {
+		if got := FuzzyMatch(tc.title, tc.term); got != tc.want {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, want %v", tc.title, tc.term, got, tc.want)
+		}
+	}
+
+	q = Parse("~crahs")
+	if !q.Match(IssueData{Title: "Crash on start"}) || q.Match(IssueData{Title: "Slow start", Body: "crahs"}) {
+		t.Fatal("fuzzy terms should only match titles")
+	}
+}
+
+func TestRegex(t *testing.T) {
+	q := Parse(`regex:"panic in .*Handler" label:bug`)
+	if q.Err != nil || len(q.Regexes) != 1 || q.Text != "" || !q.NeedsBody() {
+		t.Fatalf("unexpected query: %+v", q)
+	}
+	trace := IssueData{Title: "Crash on login", Labels: []string{"bug"}, Body: "panic in (*AuthHandler).ServeHTTP"}
+	if !q.Match(trace) {
+		t.Fatal("expected the body to match")
+	}
+	if q.Match(IssueData{Title: "Handler panic", Labels: []string{"bug"}}) {
+		t.Fatal("expected no match")
+	}
+
+	if q := Parse(`regex:"panic (in"`); q.Err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+	if q := Parse("regex:" + strings.Repeat("a", MaxRegexLength+1)); q.Err == nil {
+		t.Fatal("expected an error for an overlong pattern")
+	}
+
+	defer func(timeout time.Duration) { RegexTimeout = timeout }(RegexTimeout)
+	RegexTimeout = -1
+	q = Parse(`regex:panic`)
+	if q.Match(trace) || !q.RegexTimedOut() {
+		t.Fatal("expected the search to stop once the budget is spent")
+	}
+}
+
 func slicesEqual(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -421,3 +576,63 @@ func slicesEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func TestNeedsBody(t *testing.T) {
+	for query, want := range map[string]bool{
+		"label:bug is:open": false,
+		"crash label:bug":   true,
+		"mentions:alice":    true,
+		"sort:updated-desc": false,
+	} {
+		if got := Parse(query).NeedsBody(); got != want {
+			t.Errorf("NeedsBody(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestFacets(t *testing.T) {
+	fields, err := ParseFacets("label, Milestone,team")
+	if err != nil || !slicesEqual(fields, []string{"labels", "milestone", "team"}) {
+		t.Fatalf("ParseFacets = %v, %v", fields, err)
+	}
+	if _, err := ParseFacets("labels,,milestone"); err == nil {
+		t.Fatal("expected an error for an empty facet")
+	}
+
+	issues := []IssueData{
+		{Number: "1", Labels: []string{"bug", "ui"}, Milestone: "v1", Fields: map[string]string{"team": "core"}},
+		{Number: "2", Labels: []string{"Bug"}, Milestone: "v2"},
+		{Number: "3", Labels: []string{"bug", "BUG"}, Milestone: "v1"},
+		{Number: "4"},
+	}
+	facets := Facets(issues, fields)
+	if len(facets) != 3 {
+		t.Fatalf("expected three facets, got %+v", facets)
+	}
+	labels := facets[0]
+	if len(labels.Values) != 2 || labels.Values[0] != (FacetValue{"bug", 3}) || labels.Values[1] != (FacetValue{"ui", 1}) || labels.None != 1 {
+		t.Fatalf("unexpected label facet: %+v", labels)
+	}
+	milestones := facets[1]
+	if len(milestones.Values) != 2 || milestones.Values[0] != (FacetValue{"v1", 2}) || milestones.None != 1 {
+		t.Fatalf("unexpected milestone facet: %+v", milestones)
+	}
+	if team := facets[2]; len(team.Values) != 1 || team.Values[0] != (FacetValue{"core", 1}) || team.None != 3 {
+		t.Fatalf("unexpected custom field facet: %+v", team)
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	q := Parse(`crash label:bug -label:wontfix assignee:alice "large files"`)
+	data := IssueData{
+		Number:    "42",
+		Title:     "Crash when opening large files",
+		Body:      strings.Repeat("The editor crashes when a file is larger than the buffer limit.\n", 200),
+		State:     "open",
+		Labels:    []string{"bug", "ui"},
+		Assignees: []string{"alice"},
+	}
+	for b.Loop() {
+		q.Match(data)
+	}
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Dir stores the mirror as plain files in the issues directory. It is the
+// default backend.
+type Dir struct {
+	root string
+}
+
+// NewDir returns a store rooted at the issues directory.
+func NewDir(root string) *Dir {
+	return &Dir{root: root}
+}
+
+func (d *Dir) path(name string) string {
+	return filepath.Join(d.root, filepath.FromSlash(name))
+}
+
+func (d *Dir) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(d.path(name))
+}
+
+func (d *Dir) WriteFile(name string, data []byte) error {
+	path := d.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (d *Dir) Remove(name string) error {
+	return os.Remove(d.path(name))
+}
+
+func (d *Dir) List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(d.path(dir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (d *Dir) Dirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(d.path(dir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Commit does nothing; writes go to disk right away.
+func (d *Dir) Commit(ctx context.Context) error {
+	return nil
+}
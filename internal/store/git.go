@@ -0,0 +1,139 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitBranch stores the mirror on a branch of a git repository, separate
+// from the checked-out tree (like a gh-pages branch). Each Commit adds one
+// commit to the branch.
+type GitBranch struct {
+	memory
+	repo   string
+	branch string
+	run    Runner
+	now    func() time.Time
+	// head is the commit the loaded files came from, empty for a new branch.
+	head string
+}
+
+// OpenGitBranch loads the files on branch in the repository at repo. The
+// branch is created on the first Commit if it doesn't exist.
+func OpenGitBranch(ctx context.Context, repo, branch string, run Runner) (*GitBranch, error) {
+	g := &GitBranch{memory: newMemory(), repo: repo, branch: branch, run: run, now: time.Now}
+	if _, err := g.git(ctx, "", "rev-parse", "--git-dir"); err != nil {
+		return nil, err
+	}
+	head, err := g.git(ctx, "", "rev-parse", "--verify", "--quiet", g.ref()+"^{commit}")
+	if err != nil {
+		// The branch doesn't exist yet
+		return g, nil
+	}
+	g.head = strings.TrimSpace(head)
+
+	tree, err := g.git(ctx, "", "ls-tree", "-r", "-z", "--full-tree", g.head)
+	if err != nil {
+		return nil, err
+	}
+	var names, objects []string
+	for _, entry := range strings.Split(tree, "\x00") {
+		meta, name, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		names = append(names, name)
+		objects = append(objects, fields[2])
+	}
+	if len(objects) == 0 {
+		return g, nil
+	}
+
+	out, err := g.git(ctx, strings.Join(objects, "\n")+"\n", "cat-file", "--batch")
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(strings.NewReader(out))
+	for _, name := range names {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("reading %s from %s: %w", name, g.branch, err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("reading %s from %s: unexpected %q", name, g.branch, header)
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("reading %s from %s: %w", name, g.branch, err)
+		}
+		data := make([]byte, size+1) // content plus trailing newline
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("reading %s from %s: %w", name, g.branch, err)
+		}
+		g.files[name] = data[:size]
+	}
+	return g, nil
+}
+
+// Commit writes the changed files as one commit with git fast-import.
+// It fails rather than overwrite the branch if it moved since it was
+// loaded.
+func (g *GitBranch) Commit(ctx context.Context) error {
+	names := g.pending()
+	if len(names) == 0 {
+		return nil
+	}
+	var stream strings.Builder
+	message := "Update issue mirror\n"
+	fmt.Fprintf(&stream, "commit %s\n", g.ref())
+	fmt.Fprintf(&stream, "committer gh-issue-sync <gh-issue-sync@localhost> %d +0000\n", g.now().Unix())
+	fmt.Fprintf(&stream, "data %d\n%s", len(message), message)
+	if g.head != "" {
+		fmt.Fprintf(&stream, "from %s\n", g.head)
+	}
+	for _, name := range names {
+		if g.changed[name] {
+			data := g.files[name]
+			fmt.Fprintf(&stream, "M 100644 inline %s\ndata %d\n", fastImportPath(name), len(data))
+			stream.Write(data)
+			stream.WriteString("\n")
+		} else {
+			fmt.Fprintf(&stream, "D %s\n", fastImportPath(name))
+		}
+	}
+	stream.WriteString("done\n")
+
+	if _, err := g.git(ctx, stream.String(), "fast-import", "--quiet", "--done", "--date-format=raw"); err != nil {
+		return err
+	}
+	head, err := g.git(ctx, "", "rev-parse", "--verify", g.ref())
+	if err != nil {
+		return err
+	}
+	g.head = strings.TrimSpace(head)
+	g.committed()
+	return nil
+}
+
+func (g *GitBranch) ref() string {
+	return "refs/heads/" + g.branch
+}
+
+func (g *GitBranch) git(ctx context.Context, input string, args ...string) (string, error) {
+	return g.run(ctx, input, "git", append([]string{"-C", g.repo}, args...)...)
+}
+
+// fastImportPath C-quotes a path for fast-import if it needs it.
+func fastImportPath(name string) string {
+	if !strings.ContainsAny(name, "\"\n\\") {
+		return name
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(name) + `"`
+}
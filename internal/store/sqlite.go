@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const sqliteSchema = "CREATE TABLE IF NOT EXISTS files (name TEXT PRIMARY KEY, data TEXT NOT NULL);\n"
+
+// SQLite stores the mirror in a single SQLite database, using the sqlite3
+// command line shell.
+type SQLite struct {
+	memory
+	path string
+	run  Runner
+}
+
+// OpenSQLite opens (or creates) the database at path and loads its files.
+func OpenSQLite(ctx context.Context, path string, run Runner) (*SQLite, error) {
+	s := &SQLite{memory: newMemory(), path: path, run: run}
+	out, err := run(ctx, sqliteSchema+".mode json\nSELECT name, data FROM files;\n", "sqlite3", "-batch", path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(out) == "" {
+		return s, nil
+	}
+	var rows []struct {
+		Name string `json:"name"`
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	for _, row := range rows {
+		s.files[row.Name] = []byte(row.Data)
+	}
+	return s, nil
+}
+
+// Commit writes the changed files in one transaction.
+func (s *SQLite) Commit(ctx context.Context) error {
+	names := s.pending()
+	if len(names) == 0 {
+		return nil
+	}
+	var script strings.Builder
+	script.WriteString(sqliteSchema)
+	script.WriteString("BEGIN;\n")
+	for _, name := range names {
+		if s.changed[name] {
+			fmt.Fprintf(&script, "INSERT OR REPLACE INTO files (name, data) VALUES (%s, %s);\n",
+				sqlQuote(name), sqlQuote(string(s.files[name])))
+		} else {
+			fmt.Fprintf(&script, "DELETE FROM files WHERE name = %s;\n", sqlQuote(name))
+		}
+	}
+	script.WriteString("COMMIT;\n")
+	if _, err := s.run(ctx, script.String(), "sqlite3", "-batch", "-bail", s.path); err != nil {
+		return err
+	}
+	s.committed()
+	return nil
+}
+
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
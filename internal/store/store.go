@@ -0,0 +1,128 @@
+// Package store abstracts where the issue files of a mirror are kept.
+//
+// Files are addressed by slash-separated names relative to the issues
+// directory, e.g. "open/42-crash.md" or ".sync/originals/42.md". The
+// directory store writes through to disk; the SQLite and git branch stores
+// load the whole mirror when opened and write changes back on Commit, so a
+// mirror with thousands of issues is a single file or ref instead of
+// thousands of files.
+package store
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Supported storage backends.
+const (
+	BackendDir    = ""
+	BackendSQLite = "sqlite"
+	BackendGit    = "git"
+)
+
+// Store holds the issue files of a mirror.
+type Store interface {
+	// ReadFile returns the content of a file, or an error matching
+	// fs.ErrNotExist.
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	// Remove deletes a file. Removing a missing file is an error matching
+	// fs.ErrNotExist.
+	Remove(name string) error
+	// List returns the names of the files directly in dir, sorted.
+	List(dir string) ([]string, error)
+	// Dirs returns the names of the directories directly in dir that hold
+	// files, sorted.
+	Dirs(dir string) ([]string, error)
+	// Commit makes the changes since the last commit durable.
+	Commit(ctx context.Context) error
+}
+
+// Runner runs a command with input on stdin and returns its stdout.
+type Runner func(ctx context.Context, input string, name string, args ...string) (string, error)
+
+// memory is the in-memory view of the stores that load the whole mirror
+// when opened.
+type memory struct {
+	files map[string][]byte
+	// changed maps written files to true and removed files to false.
+	changed map[string]bool
+}
+
+func newMemory() memory {
+	return memory{files: make(map[string][]byte), changed: make(map[string]bool)}
+}
+
+func (m *memory) ReadFile(name string) ([]byte, error) {
+	data, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *memory) WriteFile(name string, data []byte) error {
+	m.files[name] = append([]byte(nil), data...)
+	m.changed[name] = true
+	return nil
+}
+
+func (m *memory) Remove(name string) error {
+	if _, ok := m.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	m.changed[name] = false
+	return nil
+}
+
+func (m *memory) List(dir string) ([]string, error) {
+	dir = strings.TrimSuffix(dir, "/")
+	var names []string
+	for name := range m.files {
+		if path.Dir(name) == dir {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *memory) Dirs(dir string) ([]string, error) {
+	prefix := ""
+	if dir = strings.TrimSuffix(dir, "/"); dir != "" {
+		prefix = dir + "/"
+	}
+	seen := map[string]bool{}
+	var names []string
+	for name := range m.files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if sub, _, nested := strings.Cut(rest, "/"); nested && !seen[sub] {
+			seen[sub] = true
+			names = append(names, prefix+sub)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// pending returns the names changed since the last commit, sorted.
+func (m *memory) pending() []string {
+	names := make([]string, 0, len(m.changed))
+	for name := range m.changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// committed forgets the pending changes after a successful commit.
+func (m *memory) committed() {
+	m.changed = make(map[string]bool)
+}
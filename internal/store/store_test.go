@@ -0,0 +1,127 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func execRunner(ctx context.Context, input string, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %v: %s", name, err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// exercise writes, lists, removes, and commits files, then reopens the
+// store and checks that the changes persisted.
+func exercise(t *testing.T, open func() (Store, error)) {
+	t.Helper()
+	ctx := context.Background()
+	s, err := open()
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	files := map[string]string{
+		"open/1-crash.md":        "---\ntitle: Crash\n---\n\nIt's broken.\n",
+		"open/2-slow.md":         "---\ntitle: Slow\n---\n",
+		"closed/3-done.md":       "done",
+		".sync/originals/1.md":   "original",
+		"open/4-removed-soon.md": "bye",
+	}
+	for name, data := range files {
+		if err := s.WriteFile(name, []byte(data)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := s.Remove("open/4-removed-soon.md"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := s.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if err := s.Remove("open/2-slow.md"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := s.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	s, err = open()
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	names, err := s.List("open")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"open/1-crash.md"}) {
+		t.Fatalf("unexpected files: %v", names)
+	}
+	data, err := s.ReadFile("open/1-crash.md")
+	if err != nil || string(data) != files["open/1-crash.md"] {
+		t.Fatalf("unexpected content %q: %v", data, err)
+	}
+	if _, err := s.ReadFile("open/2-slow.md"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected removed file to be gone, got %v", err)
+	}
+	if names, _ := s.List(".sync/originals"); len(names) != 1 {
+		t.Fatalf("unexpected originals: %v", names)
+	}
+	if dirs, err := s.Dirs(""); err != nil || !reflect.DeepEqual(dirs, []string{".sync", "closed", "open"}) {
+		t.Fatalf("unexpected directories: %v %v", dirs, err)
+	}
+	if dirs, err := s.Dirs(".sync"); err != nil || !reflect.DeepEqual(dirs, []string{".sync/originals"}) {
+		t.Fatalf("unexpected directories: %v %v", dirs, err)
+	}
+}
+
+func TestDir(t *testing.T) {
+	root := t.TempDir()
+	exercise(t, func() (Store, error) { return NewDir(root), nil })
+}
+
+func TestSQLite(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	path := filepath.Join(t.TempDir(), "issues.db")
+	exercise(t, func() (Store, error) { return OpenSQLite(context.Background(), path, execRunner) })
+}
+
+func TestGitBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	if _, err := execRunner(context.Background(), "", "git", "init", "--quiet", repo); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+	exercise(t, func() (Store, error) { return OpenGitBranch(context.Background(), repo, "issues", execRunner) })
+
+	log, err := execRunner(context.Background(), "", "git", "-C", repo, "rev-list", "--count", "refs/heads/issues")
+	if err != nil || strings.TrimSpace(log) != "2" {
+		t.Fatalf("expected two commits on the branch, got %q: %v", log, err)
+	}
+}
+
+func TestFastImportPath(t *testing.T) {
+	if got := fastImportPath("open/1-plain.md"); got != "open/1-plain.md" {
+		t.Fatalf("unexpected quoting: %s", got)
+	}
+	if got := fastImportPath(`open/1-"odd".md`); got != `"open/1-\"odd\".md"` {
+		t.Fatalf("unexpected quoting: %s", got)
+	}
+}
@@ -393,6 +393,14 @@ func (s *Styler) FgUnderline(c Color, text string) string {
 	return s.fgAnsi(c) + underline + text + underlineOff + fgReset
 }
 
+// Link returns text as an OSC 8 hyperlink to url.
+func (s *Styler) Link(url, text string) string {
+	if s.mode == ColorModeNone {
+		return text
+	}
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
 // Reset returns the ANSI reset sequence.
 func (s *Styler) Reset() string {
 	if s.mode == ColorModeNone {
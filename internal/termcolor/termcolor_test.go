@@ -160,6 +160,9 @@ func TestStylerNoColor(t *testing.T) {
 	if s.BgHex("#ff0000", "test") != "test" {
 		t.Errorf("BgHex with ColorModeNone should return plain text")
 	}
+	if s.Link("https://example.com", "test") != "test" {
+		t.Errorf("Link with ColorModeNone should return plain text")
+	}
 }
 
 func TestDetectColorModeNoColor(t *testing.T) {
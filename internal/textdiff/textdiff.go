@@ -0,0 +1,165 @@
+// Package textdiff computes shortest edit scripts between token lists,
+// such as the words or lines of two issue bodies.
+package textdiff
+
+// Kind is what an Op does with its token.
+type Kind int
+
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// Op is a single step of an edit script.
+type Op struct {
+	Kind Kind
+	Text string
+}
+
+// Myers returns the shortest edit script turning a into b, or false if it
+// needs more than maxEdits insertions and deletions. It takes O((n+m)d)
+// time and O(d²) memory for d edits, instead of the O(n*m) of a full LCS
+// table. Within a run of changes, deletions come before insertions.
+func Myers(a, b []string, maxEdits int) ([]Op, bool) {
+	// Common prefix and suffix don't need the search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(midA), len(midB)
+
+	ops := make([]Op, 0, len(a)+len(b)-prefix-suffix)
+	for _, token := range a[:prefix] {
+		ops = append(ops, Op{Kind: Equal, Text: token})
+	}
+
+	// Forward pass; trace[d] holds the furthest x on diagonals -d..d
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	final := -1
+	for d := 0; d <= n+m; d++ {
+		if d > maxEdits {
+			return nil, false
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && midA[x] == midB[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				final = d
+				break
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		if final >= 0 {
+			break
+		}
+	}
+
+	// Walk the trace back from the end, collecting operations in reverse
+	var middle []Op
+	x, y := n, m
+	for d := final; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			middle = append(middle, Op{Kind: Equal, Text: midA[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			middle = append(middle, Op{Kind: Insert, Text: midB[y-1]})
+		} else {
+			middle = append(middle, Op{Kind: Delete, Text: midA[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		middle = append(middle, Op{Kind: Equal, Text: midA[x-1]})
+		x--
+		y--
+	}
+	for left, right := 0, len(middle)-1; left < right; left, right = left+1, right-1 {
+		middle[left], middle[right] = middle[right], middle[left]
+	}
+
+	// Order each run of changes as deletions, then insertions
+	for i := 0; i < len(middle); {
+		if middle[i].Kind == Equal {
+			ops = append(ops, middle[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(middle) && middle[j].Kind != Equal {
+			j++
+		}
+		for _, op := range middle[i:j] {
+			if op.Kind == Delete {
+				ops = append(ops, op)
+			}
+		}
+		for _, op := range middle[i:j] {
+			if op.Kind == Insert {
+				ops = append(ops, op)
+			}
+		}
+		i = j
+	}
+
+	for _, token := range a[len(a)-suffix:] {
+		ops = append(ops, Op{Kind: Equal, Text: token})
+	}
+	return ops, true
+}
+
+// Match returns, for each token of a, the index of the token of b it is
+// kept as in the shortest edit script, or -1 if it is deleted. Past
+// maxEdits it reports false and no token matches.
+func Match(a, b []string, maxEdits int) ([]int, bool) {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+	ops, ok := Myers(a, b, maxEdits)
+	if !ok {
+		return match, false
+	}
+	i, j := 0, 0
+	for _, op := range ops {
+		switch op.Kind {
+		case Equal:
+			match[i] = j
+			i++
+			j++
+		case Delete:
+			i++
+		case Insert:
+			j++
+		}
+	}
+	return match, true
+}
@@ -0,0 +1,35 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMyers(t *testing.T) {
+	ops, ok := Myers(strings.Fields("a b c d"), strings.Fields("a x c d e"), 10)
+	if !ok {
+		t.Fatalf("expected a script within the budget")
+	}
+	var got []string
+	for _, op := range ops {
+		got = append(got, [...]string{"=", "-", "+"}[op.Kind]+op.Text)
+	}
+	if want := []string{"=a", "-b", "+x", "=c", "=d", "+e"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if _, ok := Myers(strings.Fields("a b c"), strings.Fields("x y z"), 5); ok {
+		t.Fatalf("expected six edits to exceed a budget of five")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	match, ok := Match(strings.Fields("a b c d"), strings.Fields("b c x d"), 10)
+	if !ok || !reflect.DeepEqual(match, []int{-1, 0, 1, 3}) {
+		t.Fatalf("unexpected match %v (ok=%v)", match, ok)
+	}
+	match, ok = Match(strings.Fields("a b"), strings.Fields("x y"), 1)
+	if ok || !reflect.DeepEqual(match, []int{-1, -1}) {
+		t.Fatalf("expected nothing matched past the budget, got %v (ok=%v)", match, ok)
+	}
+}
@@ -66,6 +66,13 @@ func Default() *Theme {
 	}
 }
 
+// Plain returns the default theme with colors disabled, e.g. for CI logs.
+func Plain() *Theme {
+	t := Default()
+	t.styler = termcolor.NewStyler(termcolor.ColorModeNone)
+	return t
+}
+
 // Styler returns the underlying termcolor Styler.
 func (t *Theme) Styler() *termcolor.Styler {
 	return t.styler
@@ -220,3 +227,8 @@ func (t *Theme) Strikethrough(text string) string {
 func (t *Theme) Underline(text string) string {
 	return t.styler.Underline(text)
 }
+
+// Link returns text as a clickable hyperlink to url.
+func (t *Theme) Link(url, text string) string {
+	return t.styler.Link(url, text)
+}
@@ -161,3 +161,10 @@ func TestConvenienceMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestPlainTheme(t *testing.T) {
+	th := Plain()
+	if got := th.FormatStatus("A"); strings.Contains(got, "\x1b[") {
+		t.Errorf("Plain().FormatStatus has escape codes: %q", got)
+	}
+}